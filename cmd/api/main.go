@@ -2,23 +2,28 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/straye-as/relation-api/docs"
 	"github.com/straye-as/relation-api/internal/auth"
+	"github.com/straye-as/relation-api/internal/brreg"
 	"github.com/straye-as/relation-api/internal/config"
 	"github.com/straye-as/relation-api/internal/database"
 	"github.com/straye-as/relation-api/internal/datawarehouse"
+	"github.com/straye-as/relation-api/internal/domain"
 	"github.com/straye-as/relation-api/internal/http/handler"
 	"github.com/straye-as/relation-api/internal/http/middleware"
 	"github.com/straye-as/relation-api/internal/http/router"
 	"github.com/straye-as/relation-api/internal/jobs"
 	"github.com/straye-as/relation-api/internal/logger"
+	"github.com/straye-as/relation-api/internal/mail"
 	"github.com/straye-as/relation-api/internal/repository"
 	"github.com/straye-as/relation-api/internal/service"
 	"github.com/straye-as/relation-api/internal/storage"
@@ -134,6 +139,12 @@ func run() error {
 		)
 	}
 
+	// Initialize Enhetsregisteret client (optional - public API used for customer enrichment)
+	brregClient, err := brreg.NewClient(&cfg.Brreg, log)
+	if err != nil {
+		log.Warn("Enhetsregisteret client could not be created, continuing without it", zap.Error(err))
+	}
+
 	// Initialize repositories
 	customerRepo := repository.NewCustomerRepository(db)
 	contactRepo := repository.NewContactRepository(db)
@@ -154,6 +165,18 @@ func run() error {
 	numberSequenceRepo := repository.NewNumberSequenceRepository(db)
 	supplierRepo := repository.NewSupplierRepository(db)
 	assignmentRepo := repository.NewAssignmentRepository(db)
+	importRepo := repository.NewImportRepository(db)
+	erpReconciliationRepo := repository.NewERPReconciliationRepository(db)
+	customerTierRuleRepo := repository.NewCustomerTierRuleRepository(db)
+	calendarFeedTokenRepo := repository.NewCalendarFeedTokenRepository(db)
+	ingestedEmailRepo := repository.NewIngestedEmailRepository(db)
+	dealPipelineRepo := repository.NewDealPipelineRepository(db)
+	salesTargetRepo := repository.NewSalesTargetRepository(db)
+	winRateRepo := repository.NewWinRateRepository(db)
+	inquiryIntakeRepo := repository.NewInquiryIntakeRepository(db)
+	inquiryAssignmentRuleRepo := repository.NewInquiryAssignmentRuleRepository(db)
+	projectTaskRepo := repository.NewProjectTaskRepository(db)
+	resourceCapacityRepo := repository.NewResourceCapacityRepository(db)
 
 	// Initialize services
 	// Company service first (other services may depend on it)
@@ -167,6 +190,10 @@ func run() error {
 	if dwClient != nil {
 		customerService.SetDataWarehouseClient(&customerDWAdapter{client: dwClient})
 	}
+	// Inject Enhetsregisteret client into customer service for org number enrichment
+	if brregClient != nil {
+		customerService.SetOrganizationRegistry(&customerRegistryAdapter{client: brregClient}, time.Duration(cfg.Brreg.BulkDelayMs)*time.Millisecond)
+	}
 	contactService := service.NewContactService(contactRepo, customerRepo, activityRepo, log)
 	fileService := service.NewFileService(fileRepo, offerRepo, customerRepo, projectRepo, supplierRepo, activityRepo, fileStorage, log)
 	projectService := service.NewProjectServiceWithDeps(projectRepo, offerRepo, customerRepo, activityRepo, fileService, log, db)
@@ -176,15 +203,67 @@ func run() error {
 		offerService.SetDataWarehouseClient(dwClient)
 	}
 	inquiryService := service.NewInquiryService(offerRepo, customerRepo, activityRepo, userRepo, companyService, log, db)
+	// New inquiries are assigned by the companies' assignment rules
+	inquiryAssignmentService := service.NewInquiryAssignmentService(inquiryAssignmentRuleRepo, userRepo, companyService, log)
+	inquiryService.SetAssignmentService(inquiryAssignmentService)
+	inquirySLAService := service.NewInquirySLAService(offerRepo, notificationRepo, companyService, log)
+	projectScheduleService := service.NewProjectScheduleService(projectRepo, projectTaskRepo, offerRepo, userRepo, activityRepo, notificationRepo, log)
+	resourceCapacityService := service.NewResourceCapacityService(resourceCapacityRepo, offerRepo, projectRepo, userRepo, log)
 	dealService := service.NewDealService(dealRepo, dealStageHistoryRepo, customerRepo, projectRepo, activityRepo, offerRepo, budgetItemRepo, notificationRepo, log, db)
+	// Inject pipeline repository so deals follow their company's configured stages
+	dealService.SetPipelineRepository(dealPipelineRepo)
+	dealPipelineService := service.NewDealPipelineService(dealPipelineRepo, log)
+	salesTargetService := service.NewSalesTargetService(salesTargetRepo, dealRepo, log)
+	winRateForecastService := service.NewWinRateForecastService(winRateRepo, log)
 	dashboardService := service.NewDashboardService(customerRepo, projectRepo, offerRepo, activityRepo, notificationRepo, supplierRepo, log)
 	permissionService := service.NewPermissionService(userRoleRepo, userPermissionRepo, activityRepo, log)
 	auditLogService := service.NewAuditLogService(auditLogRepo, log)
 	budgetItemService := service.NewBudgetItemService(budgetItemRepo, offerRepo, projectRepo, log)
 	notificationService := service.NewNotificationService(notificationRepo, log)
 	activityService := service.NewActivityService(activityRepo, notificationService, log)
+	activityService.SetRecurrenceHorizonDays(cfg.Activities.RecurrenceHorizonDays)
 	supplierService := service.NewSupplierServiceWithDeps(supplierRepo, fileService, activityRepo, log)
 	assignmentService := service.NewAssignmentService(assignmentRepo, offerRepo, activityRepo, log)
+	postalCodeService := service.NewPostalCodeService(customerRepo, supplierRepo, log)
+	importService := service.NewImportService(importRepo, customerRepo, offerRepo, customerService, offerService, log)
+	exportService := service.NewExportService(offerService, customerService, projectService, dealService, supplierService, activityService, log)
+	erpReconciliationService := service.NewERPReconciliationService(erpReconciliationRepo, customerRepo, activityRepo, customerService, cfg.DataWarehouse.CustomerReconciliationAutoCreate, log)
+	if dwClient != nil {
+		erpReconciliationService.SetDataWarehouseClient(&customerDWAdapter{client: dwClient})
+	}
+	customerTierService := service.NewCustomerTierService(customerTierRuleRepo, customerRepo, activityRepo, log)
+	creditExposureService := service.NewCreditExposureService(customerRepo, userRoleRepo, notificationRepo, activityRepo, companyService, log)
+	calendarFeedService := service.NewCalendarFeedService(calendarFeedTokenRepo, activityRepo, offerRepo, userRepo, log)
+	emailIngestionService := service.NewEmailIngestionService(ingestedEmailRepo, offerRepo, contactRepo, customerRepo, supplierRepo, activityRepo, fileService, log)
+	// Outgoing mail is disabled when no SMTP host is configured
+	mailSender := mail.NewSender(mail.Config{
+		Host:     cfg.Mail.SMTPHost,
+		Port:     cfg.Mail.SMTPPort,
+		Username: cfg.Mail.Username,
+		Password: cfg.Mail.Password,
+		From:     cfg.Mail.From,
+		FromName: cfg.Mail.FromName,
+		Timeout:  cfg.Mail.TimeoutDuration(),
+	})
+	inquiryIntakeService := service.NewInquiryIntakeService(inquiryIntakeRepo, inquiryService, customerService, fileService, mailSender, service.InquiryIntakeOptions{
+		ChallengeTTL:   cfg.Intake.ChallengeTTLDuration(),
+		MaxAttachments: cfg.Intake.MaxAttachments,
+	}, log)
+	// Include unpaid invoices from the data warehouse in credit exposure when configured
+	if dwClient != nil && cfg.DataWarehouse.CreditExposureIncludeUnpaid {
+		creditExposureService.SetUnpaidAmountSource(dwClient)
+	}
+	offerService.SetCreditExposureService(creditExposureService)
+	// Warn when an accepted order's manager is already over capacity
+	offerService.SetResourceCapacityService(resourceCapacityService)
+	offerService.SetContactRepository(contactRepo)
+	// Offers in progress and sent offers go stale after the configured days without activity
+	offerStaleThresholds := repository.OfferStaleThresholds{
+		domain.OfferPhaseInProgress: cfg.Staleness.OfferInProgressDays,
+		domain.OfferPhaseSent:       cfg.Staleness.OfferSentDays,
+	}
+	offerService.SetStaleThresholds(offerStaleThresholds)
+	staleDigestService := service.NewStaleDigestService(dealRepo, offerRepo, userRoleRepo, notificationRepo, offerStaleThresholds, log)
 	// Inject data warehouse client into assignment service for DW sync functionality
 	if dwClient != nil {
 		assignmentService.SetDataWarehouseClient(dwClient)
@@ -214,6 +293,22 @@ func run() error {
 	activityHandler := handler.NewActivityHandler(activityService, log)
 	supplierHandler := handler.NewSupplierHandler(supplierService, log)
 	assignmentHandler := handler.NewAssignmentHandler(assignmentService, log)
+	postalCodeHandler := handler.NewPostalCodeHandler(postalCodeService, log)
+	importHandler := handler.NewImportHandler(importService, auditLogService, cfg.Storage.MaxUploadSizeMB, log)
+	exportHandler := handler.NewExportHandler(exportService, auditLogService, log)
+	erpReconciliationHandler := handler.NewERPReconciliationHandler(erpReconciliationService, log)
+	customerTierHandler := handler.NewCustomerTierHandler(customerTierService, log)
+	creditExposureHandler := handler.NewCreditExposureHandler(creditExposureService, log)
+	calendarHandler := handler.NewCalendarHandler(calendarFeedService, log)
+	emailHandler := handler.NewEmailHandler(emailIngestionService, log)
+	dealPipelineHandler := handler.NewDealPipelineHandler(dealPipelineService, log)
+	salesTargetHandler := handler.NewSalesTargetHandler(salesTargetService, log)
+	forecastHandler := handler.NewForecastHandler(winRateForecastService, log)
+	inquiryIntakeHandler := handler.NewInquiryIntakeHandler(inquiryIntakeService, cfg.Intake.MaxUploadSizeMB, rateLimiter.ClientIP, log)
+	inquiryAssignmentHandler := handler.NewInquiryAssignmentHandler(inquiryAssignmentService, log)
+	inquirySLAHandler := handler.NewInquirySLAHandler(inquirySLAService, log)
+	projectScheduleHandler := handler.NewProjectScheduleHandler(projectScheduleService, log)
+	resourceCapacityHandler := handler.NewResourceCapacityHandler(resourceCapacityService, log)
 
 	// Setup router
 	rt := router.NewRouter(
@@ -241,13 +336,28 @@ func run() error {
 		activityHandler,
 		supplierHandler,
 		assignmentHandler,
+		postalCodeHandler,
+		importHandler,
+		exportHandler,
+		erpReconciliationHandler,
+		customerTierHandler,
+		creditExposureHandler,
+		calendarHandler,
+		emailHandler,
+		dealPipelineHandler,
+		salesTargetHandler,
+		forecastHandler,
+		inquiryIntakeHandler,
+		inquiryAssignmentHandler,
+		inquirySLAHandler,
+		projectScheduleHandler,
+		resourceCapacityHandler,
 	)
 
-	// Initialize and start scheduler for background jobs
-	var scheduler *jobs.Scheduler
-	if cfg.DataWarehouse.Enabled && cfg.DataWarehouse.PeriodicSyncEnabled && dwClient != nil {
-		scheduler = jobs.NewScheduler(log)
+	// Initialize scheduler for background jobs
+	scheduler := jobs.NewScheduler(log)
 
+	if cfg.DataWarehouse.Enabled && cfg.DataWarehouse.PeriodicSyncEnabled && dwClient != nil {
 		// Register the data warehouse sync job
 		// runStartupSync=true will sync stale offers and assignments (null or > 1 hour old) immediately
 		// forceSync=true will sync ALL offers regardless of last sync time (always enabled for fresh data on startup)
@@ -263,8 +373,7 @@ func run() error {
 		); err != nil {
 			log.Error("Failed to register DW sync job", zap.Error(err))
 		} else {
-			scheduler.Start()
-			log.Info("Scheduler started with DW sync job (offers + assignments)",
+			log.Info("DW sync job registered (offers + assignments)",
 				zap.String("cron_expr", cfg.DataWarehouse.PeriodicSyncCron),
 				zap.Duration("timeout", cfg.DataWarehouse.PeriodicSyncTimeoutDuration()),
 				zap.Bool("force_sync_on_startup", true),
@@ -278,6 +387,114 @@ func run() error {
 		)
 	}
 
+	if cfg.DataWarehouse.Enabled && cfg.DataWarehouse.CustomerReconciliationEnabled && dwClient != nil {
+		if err := jobs.RegisterERPReconciliationJob(
+			scheduler,
+			erpReconciliationService,
+			log,
+			cfg.DataWarehouse.CustomerReconciliationCron,
+			cfg.DataWarehouse.CustomerReconciliationTimeoutDuration(),
+		); err != nil {
+			log.Error("Failed to register ERP customer reconciliation job", zap.Error(err))
+		} else {
+			log.Info("ERP customer reconciliation job registered",
+				zap.String("cron_expr", cfg.DataWarehouse.CustomerReconciliationCron),
+				zap.Bool("auto_create", cfg.DataWarehouse.CustomerReconciliationAutoCreate),
+			)
+		}
+	}
+
+	if cfg.DataQuality.PostalCodeCheckEnabled {
+		if err := jobs.RegisterPostalCodeCheckJob(
+			scheduler,
+			postalCodeService,
+			log,
+			cfg.DataQuality.PostalCodeCheckCron,
+			cfg.DataQuality.PostalCodeCheckTimeoutDuration(),
+		); err != nil {
+			log.Error("Failed to register postal code check job", zap.Error(err))
+		}
+	}
+
+	if cfg.DataQuality.TierRecalculationEnabled {
+		if err := jobs.RegisterCustomerTierJob(
+			scheduler,
+			customerTierService,
+			log,
+			cfg.DataQuality.TierRecalculationCron,
+			cfg.DataQuality.TierRecalculationTimeoutDuration(),
+		); err != nil {
+			log.Error("Failed to register customer tier recalculation job", zap.Error(err))
+		}
+	}
+
+	if cfg.DataQuality.ContactRetentionEnabled {
+		if err := jobs.RegisterContactRetentionJob(
+			scheduler,
+			contactService,
+			log,
+			cfg.DataQuality.ContactRetentionCron,
+			cfg.DataQuality.ContactRetentionTimeoutDuration(),
+			cfg.DataQuality.ContactRetentionYears,
+		); err != nil {
+			log.Error("Failed to register contact retention review job", zap.Error(err))
+		}
+	}
+
+	if cfg.Activities.RecurrenceExpansionEnabled {
+		if err := jobs.RegisterActivityRecurrenceJob(
+			scheduler,
+			activityService,
+			log,
+			cfg.Activities.RecurrenceExpansionCron,
+			cfg.Activities.RecurrenceExpansionTimeoutDuration(),
+		); err != nil {
+			log.Error("Failed to register activity recurrence expansion job", zap.Error(err))
+		}
+	}
+
+	if cfg.Email.MailboxEnabled {
+		if err := jobs.RegisterEmailIngestionJob(
+			scheduler,
+			emailIngestionService,
+			log,
+			cfg.Email.MailboxCron,
+			cfg.Email.MailboxTimeoutDuration(),
+			cfg.Email.MailboxPath,
+		); err != nil {
+			log.Error("Failed to register email mailbox ingestion job", zap.Error(err))
+		}
+	}
+
+	if cfg.Staleness.DigestEnabled {
+		if err := jobs.RegisterStaleDigestJob(
+			scheduler,
+			staleDigestService,
+			log,
+			cfg.Staleness.DigestCron,
+			cfg.Staleness.DigestTimeoutDuration(),
+		); err != nil {
+			log.Error("Failed to register stale digest job", zap.Error(err))
+		}
+	}
+
+	if cfg.InquirySLA.WarningEnabled {
+		if err := jobs.RegisterInquirySLAJob(
+			scheduler,
+			inquirySLAService,
+			log,
+			cfg.InquirySLA.WarningCron,
+			cfg.InquirySLA.WarningTimeoutDuration(),
+		); err != nil {
+			log.Error("Failed to register inquiry SLA warning job", zap.Error(err))
+		}
+	}
+
+	if len(scheduler.GetJobNames()) > 0 {
+		scheduler.Start()
+		log.Info("Scheduler started", zap.Strings("jobs", scheduler.GetJobNames()))
+	}
+
 	// Create HTTP server
 	srv := &http.Server{
 		Addr:         fmt.Sprintf(":%d", cfg.App.Port),
@@ -303,12 +520,10 @@ func run() error {
 	case sig := <-shutdown:
 		log.Info("Shutdown signal received", zap.String("signal", sig.String()))
 
-		// Stop scheduler if running
-		if scheduler != nil {
-			ctx := scheduler.Stop()
-			<-ctx.Done()
-			log.Info("Scheduler stopped")
-		}
+		// Stop scheduler (waits for running jobs to complete)
+		ctx := scheduler.Stop()
+		<-ctx.Done()
+		log.Info("Scheduler stopped")
 
 		// Graceful shutdown with timeout
 		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
@@ -352,9 +567,49 @@ func (a *customerDWAdapter) GetERPCustomers(ctx context.Context) ([]service.Data
 	result := make([]service.DataWarehouseERPCustomer, len(erpCustomers))
 	for i, c := range erpCustomers {
 		result[i] = service.DataWarehouseERPCustomer{
+			Firmanr:            c.Firmanr,
+			CustomerNumber:     c.CustomerNumber,
 			OrganizationNumber: c.OrganizationNumber,
 			Name:               c.Name,
+			Address:            c.Address,
+			PostalCode:         c.PostalCode,
+			City:               c.City,
 		}
 	}
 	return result, nil
 }
+
+// customerRegistryAdapter adapts the brreg.Client to the service.OrganizationRegistry interface
+type customerRegistryAdapter struct {
+	client *brreg.Client
+}
+
+func (a *customerRegistryAdapter) LookupOrganization(ctx context.Context, orgNumber string) (*service.RegistryOrganization, error) {
+	entity, err := a.client.GetEntity(ctx, orgNumber)
+	if err != nil {
+		if errors.Is(err, brreg.ErrNotFound) {
+			return nil, service.ErrOrganizationNotInRegistry
+		}
+		return nil, err
+	}
+
+	org := &service.RegistryOrganization{
+		OrganizationNumber: entity.OrganizationNumber,
+		Name:               entity.Name,
+		Website:            entity.Website,
+		Bankrupt:           entity.Bankrupt,
+		UnderLiquidation:   entity.UnderLiquidation || entity.ForcedLiquidation,
+		Deleted:            entity.IsDeleted(),
+	}
+	if entity.IndustryCode != nil {
+		org.IndustryCode = entity.IndustryCode.Code
+	}
+	if address := entity.PreferredAddress(); address != nil {
+		org.Address = strings.Join(address.Lines, ", ")
+		org.PostalCode = address.PostalCode
+		org.City = address.City
+		org.Municipality = address.Municipality
+		org.County = brreg.CountyForMunicipalityNumber(address.MunicipalityNumber)
+	}
+	return org, nil
+}
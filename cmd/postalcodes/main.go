@@ -0,0 +1,58 @@
+// Command postalcodes refreshes the bundled Norwegian postal code register.
+//
+// Download the full register (Postnummerregister-ansi.txt) from Posten/Bring and run:
+//
+//	go run ./cmd/postalcodes -in Postnummerregister-ansi.txt
+//
+// The file is validated and written as UTF-8 to internal/postal/data, where it is embedded
+// into the API binary on the next build.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/straye-as/relation-api/internal/postal"
+)
+
+func main() {
+	if err := run(); err != nil {
+		fmt.Fprintf(os.Stderr, "Postal code refresh error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	in := flag.String("in", "", "path to the postal code register from Posten (tab-separated, ANSI or UTF-8)")
+	out := flag.String("out", "internal/postal/data/postnummerregister.txt", "path of the bundled register to write")
+	flag.Parse()
+
+	if *in == "" {
+		return fmt.Errorf("usage: postalcodes -in <Postnummerregister-ansi.txt> [-out <path>]")
+	}
+
+	source, err := os.Open(*in)
+	if err != nil {
+		return fmt.Errorf("failed to open register: %w", err)
+	}
+	defer source.Close()
+
+	register, err := postal.Parse(source)
+	if err != nil {
+		return fmt.Errorf("failed to parse register: %w", err)
+	}
+
+	target, err := os.Create(*out)
+	if err != nil {
+		return fmt.Errorf("failed to create output file: %w", err)
+	}
+	defer target.Close()
+
+	if _, err := register.WriteTo(target); err != nil {
+		return fmt.Errorf("failed to write register: %w", err)
+	}
+
+	fmt.Printf("Wrote %d postal codes to %s\n", register.Len(), *out)
+	return nil
+}
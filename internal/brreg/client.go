@@ -0,0 +1,149 @@
+// Package brreg provides read-only access to Enhetsregisteret, the Norwegian register of
+// legal entities operated by Brønnøysundregistrene. It is used to enrich customers with
+// official name, address and industry data, and to detect bankrupt or deleted companies.
+package brreg
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/straye-as/relation-api/internal/config"
+	"github.com/straye-as/relation-api/internal/postal"
+	"go.uber.org/zap"
+)
+
+const defaultTimeout = 10 * time.Second
+
+// ErrNotFound is returned when the organization number is not registered in Enhetsregisteret
+var ErrNotFound = errors.New("organization not found in Enhetsregisteret")
+
+// CountyForMunicipalityNumber returns the county name for a four-digit municipality number.
+// Enhetsregisteret does not return the county directly, so it is derived from the municipality.
+// Returns an empty string if the county is unknown.
+func CountyForMunicipalityNumber(municipalityNumber string) string {
+	return postal.CountyForMunicipalityNumber(municipalityNumber)
+}
+
+// Address is an address as returned by Enhetsregisteret
+type Address struct {
+	Country            string   `json:"land"`
+	CountryCode        string   `json:"landkode"`
+	PostalCode         string   `json:"postnummer"`
+	City               string   `json:"poststed"`
+	Lines              []string `json:"adresse"`
+	Municipality       string   `json:"kommune"`
+	MunicipalityNumber string   `json:"kommunenummer"`
+}
+
+// Code is a coded value with description (organization form, industry code)
+type Code struct {
+	Code        string `json:"kode"`
+	Description string `json:"beskrivelse"`
+}
+
+// Entity represents a legal entity (enhet) from Enhetsregisteret
+type Entity struct {
+	OrganizationNumber string   `json:"organisasjonsnummer"`
+	Name               string   `json:"navn"`
+	OrganizationForm   *Code    `json:"organisasjonsform,omitempty"`
+	IndustryCode       *Code    `json:"naeringskode1,omitempty"`
+	BusinessAddress    *Address `json:"forretningsadresse,omitempty"`
+	PostalAddress      *Address `json:"postadresse,omitempty"`
+	Website            string   `json:"hjemmeside,omitempty"`
+	Bankrupt           bool     `json:"konkurs"`
+	UnderLiquidation   bool     `json:"underAvvikling"`
+	ForcedLiquidation  bool     `json:"underTvangsavviklingEllerTvangsopplosning"`
+	DeletedDate        string   `json:"slettedato,omitempty"` // YYYY-MM-DD, only set for deleted entities
+
+	gone bool // set when the API answered 410 Gone
+}
+
+// IsDeleted returns true if the entity has been deleted from the register
+func (e *Entity) IsDeleted() bool {
+	return e.DeletedDate != "" || e.gone
+}
+
+// PreferredAddress returns the business address, falling back to the postal address
+func (e *Entity) PreferredAddress() *Address {
+	if e.BusinessAddress != nil {
+		return e.BusinessAddress
+	}
+	return e.PostalAddress
+}
+
+// Client provides lookups against the Enhetsregisteret open data API
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	logger     *zap.Logger
+}
+
+// NewClient creates a new Enhetsregisteret client.
+// Returns nil (without error) if the configuration is nil or the integration is disabled.
+func NewClient(cfg *config.BrregConfig, logger *zap.Logger) (*Client, error) {
+	if cfg == nil || !cfg.Enabled {
+		return nil, nil
+	}
+	if cfg.BaseURL == "" {
+		return nil, fmt.Errorf("brreg base URL is required")
+	}
+
+	timeout := cfg.TimeoutDuration()
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+
+	return &Client{
+		baseURL:    strings.TrimRight(cfg.BaseURL, "/"),
+		httpClient: &http.Client{Timeout: timeout},
+		logger:     logger,
+	}, nil
+}
+
+// GetEntity looks up a legal entity by organization number.
+// Deleted entities are returned with IsDeleted() reporting true. Returns ErrNotFound if the
+// organization number has never been registered.
+func (c *Client) GetEntity(ctx context.Context, orgNumber string) (*Entity, error) {
+	url := fmt.Sprintf("%s/enheter/%s", c.baseURL, orgNumber)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create brreg request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("brreg lookup %s: %w", orgNumber, err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusGone:
+		// 410 Gone is returned for deleted entities, with organisasjonsnummer and slettedato in the body
+	case http.StatusNotFound:
+		return nil, ErrNotFound
+	default:
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("brreg lookup %s: unexpected status %d: %s", orgNumber, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var entity Entity
+	if err := json.NewDecoder(resp.Body).Decode(&entity); err != nil {
+		return nil, fmt.Errorf("decode brreg response for %s: %w", orgNumber, err)
+	}
+
+	entity.gone = resp.StatusCode == http.StatusGone
+
+	c.logger.Debug("brreg lookup completed",
+		zap.String("org_number", orgNumber),
+		zap.Int("status", resp.StatusCode),
+	)
+
+	return &entity, nil
+}
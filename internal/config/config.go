@@ -18,6 +18,14 @@ type Config struct {
 	App           AppConfig
 	Database      DatabaseConfig
 	DataWarehouse DataWarehouseConfig
+	Brreg         BrregConfig
+	DataQuality   DataQualityConfig
+	Activities    ActivitiesConfig
+	Email         EmailConfig
+	Mail          MailConfig
+	Intake        IntakeConfig
+	InquirySLA    InquirySLAConfig
+	Staleness     StalenessConfig
 	AzureAd       AzureAdConfig
 	ApiKey        ApiKeyConfig
 	Storage       StorageConfig
@@ -76,6 +84,138 @@ type DataWarehouseConfig struct {
 	PeriodicSyncTimeout int
 	// ForceSyncOnStartup forces a full sync on startup regardless of last sync time
 	ForceSyncOnStartup bool
+	// CustomerReconciliationEnabled controls whether the ERP customer reconciliation job runs
+	CustomerReconciliationEnabled bool
+	// CustomerReconciliationCron is the cron expression for the ERP customer reconciliation job
+	// Default: "0 30 5 * * *" (every day at 05:30)
+	CustomerReconciliationCron string
+	// CustomerReconciliationTimeout is the timeout for the ERP customer reconciliation job (seconds)
+	CustomerReconciliationTimeout int
+	// CustomerReconciliationAutoCreate creates customers missing in the CRM without approval.
+	// When false, missing customers are queued for approval.
+	CustomerReconciliationAutoCreate bool
+	// CreditExposureIncludeUnpaid adds unpaid invoices from the ERP to customer credit exposure
+	CreditExposureIncludeUnpaid bool
+}
+
+// BrregConfig holds configuration for the Enhetsregisteret (Brønnøysundregistrene) open data API
+// The API is public and requires no credentials
+type BrregConfig struct {
+	// Enabled controls whether customer enrichment from Enhetsregisteret is available
+	Enabled bool
+	// BaseURL is the base URL of the Enhetsregisteret API
+	BaseURL string
+	// Timeout is the HTTP timeout for registry lookups (seconds)
+	Timeout int
+	// BulkDelayMs is the delay between lookups during bulk enrichment (milliseconds)
+	BulkDelayMs int
+}
+
+// DataQualityConfig holds configuration for scheduled data quality checks
+type DataQualityConfig struct {
+	// PostalCodeCheckEnabled controls whether the postal code/city consistency check runs
+	PostalCodeCheckEnabled bool
+	// PostalCodeCheckCron is the cron expression for the postal code check
+	// Default: "0 0 5 * * 1" (Mondays at 05:00)
+	PostalCodeCheckCron string
+	// PostalCodeCheckTimeout is the timeout for the postal code check (seconds)
+	PostalCodeCheckTimeout int
+	// TierRecalculationEnabled controls whether customer tiers are recalculated nightly
+	TierRecalculationEnabled bool
+	// TierRecalculationCron is the cron expression for the customer tier recalculation
+	// Default: "0 0 3 * * *" (every day at 03:00)
+	TierRecalculationCron string
+	// TierRecalculationTimeout is the timeout for the customer tier recalculation (seconds)
+	TierRecalculationTimeout int
+	// ContactRetentionEnabled controls whether inactive contacts are flagged for GDPR retention review
+	ContactRetentionEnabled bool
+	// ContactRetentionCron is the cron expression for the contact retention review
+	// Default: "0 0 4 * * 1" (Mondays at 04:00)
+	ContactRetentionCron string
+	// ContactRetentionTimeout is the timeout for the contact retention review (seconds)
+	ContactRetentionTimeout int
+	// ContactRetentionYears is how many years a contact can be inactive before it is flagged for review
+	ContactRetentionYears int
+}
+
+// ActivitiesConfig holds configuration for recurring activities
+type ActivitiesConfig struct {
+	// RecurrenceExpansionEnabled controls whether occurrences of recurring activities are generated nightly
+	RecurrenceExpansionEnabled bool
+	// RecurrenceExpansionCron is the cron expression for the recurrence expansion
+	// Default: "0 0 2 * * *" (every day at 02:00)
+	RecurrenceExpansionCron string
+	// RecurrenceExpansionTimeout is the timeout for the recurrence expansion (seconds)
+	RecurrenceExpansionTimeout int
+	// RecurrenceHorizonDays is how many days ahead occurrences of recurring activities are generated
+	RecurrenceHorizonDays int
+}
+
+// EmailConfig holds configuration for email ingestion
+type EmailConfig struct {
+	// MailboxEnabled controls whether emails dropped in the maildir are ingested periodically
+	MailboxEnabled bool
+	// MailboxPath is the maildir folder emails are read from (new/) and moved to when ingested (cur/)
+	MailboxPath string
+	// MailboxCron is the cron expression for the mailbox ingestion
+	// Default: "0 */5 * * * *" (every 5 minutes)
+	MailboxCron string
+	// MailboxTimeout is the timeout for the mailbox ingestion (seconds)
+	MailboxTimeout int
+}
+
+// MailConfig holds configuration for outgoing email
+// Outgoing email is disabled when SMTPHost is empty
+type MailConfig struct {
+	SMTPHost string
+	SMTPPort int
+	Username string
+	Password string // Loaded from secrets or environment
+	// From is the sender address, FromName the display name shown to recipients
+	From     string
+	FromName string
+	// Timeout is the timeout for delivering one message (seconds)
+	Timeout int
+}
+
+// IntakeConfig holds configuration for inquiries submitted through public website forms
+type IntakeConfig struct {
+	// RequestsPerMinute is the per-IP limit for public inquiry submissions, on top of the global limit
+	RequestsPerMinute int
+	// MaxAttachments is the maximum number of files attached to one submission
+	MaxAttachments int
+	// MaxUploadSizeMB is the maximum size of a submission including attachments
+	MaxUploadSizeMB int64
+	// ChallengeTTL is how long a proof-of-work challenge can be solved and submitted (seconds)
+	ChallengeTTL int
+}
+
+// InquirySLAConfig holds configuration for inquiry response SLA warnings
+// The SLA itself and the business hours it is measured in are configured per company
+type InquirySLAConfig struct {
+	// WarningEnabled controls whether responsible users are warned before an inquiry breaches its SLA
+	WarningEnabled bool
+	// WarningCron is the cron expression for the SLA warning check
+	// Default: "0 */15 * * * *" (every 15 minutes)
+	WarningCron string
+	// WarningTimeout is the timeout for the SLA warning check (seconds)
+	WarningTimeout int
+}
+
+// StalenessConfig holds configuration for stale deal and offer detection
+// Stale thresholds for deals are configured per pipeline stage
+type StalenessConfig struct {
+	// OfferInProgressDays is how many days an in-progress offer can go without activity before it is stale (0 disables)
+	OfferInProgressDays int
+	// OfferSentDays is how many days a sent offer can go without activity before it is stale (0 disables)
+	OfferSentDays int
+	// DigestEnabled controls whether the weekly stale item digest is sent
+	DigestEnabled bool
+	// DigestCron is the cron expression for the stale item digest
+	// Default: "0 0 7 * * 1" (Mondays at 07:00)
+	DigestCron string
+	// DigestTimeout is the timeout for the stale item digest (seconds)
+	DigestTimeout int
 }
 
 type AzureAdConfig struct {
@@ -175,6 +315,10 @@ type RateLimitConfig struct {
 	WhitelistIPs []string
 	// WhitelistPaths is a list of paths that bypass rate limiting (e.g., /health)
 	WhitelistPaths []string
+	// TrustedProxies lists the IPs or CIDRs of reverse proxies whose X-Forwarded-For and X-Real-IP
+	// headers are trusted; other clients are identified by their connection address.
+	// Defaults to the loopback and private networks when unset.
+	TrustedProxies []string
 }
 
 // ConnectionString builds PostgreSQL connection string
@@ -220,6 +364,61 @@ func (d *DataWarehouseConfig) PeriodicSyncTimeoutDuration() time.Duration {
 	return time.Duration(d.PeriodicSyncTimeout) * time.Second
 }
 
+// CustomerReconciliationTimeoutDuration returns the ERP customer reconciliation timeout as duration
+func (d *DataWarehouseConfig) CustomerReconciliationTimeoutDuration() time.Duration {
+	return time.Duration(d.CustomerReconciliationTimeout) * time.Second
+}
+
+// TimeoutDuration returns the registry lookup timeout as duration
+func (b *BrregConfig) TimeoutDuration() time.Duration {
+	return time.Duration(b.Timeout) * time.Second
+}
+
+// PostalCodeCheckTimeoutDuration returns the postal code check timeout as duration
+func (d *DataQualityConfig) PostalCodeCheckTimeoutDuration() time.Duration {
+	return time.Duration(d.PostalCodeCheckTimeout) * time.Second
+}
+
+// TierRecalculationTimeoutDuration returns the customer tier recalculation timeout as duration
+func (d *DataQualityConfig) TierRecalculationTimeoutDuration() time.Duration {
+	return time.Duration(d.TierRecalculationTimeout) * time.Second
+}
+
+// ContactRetentionTimeoutDuration returns the contact retention review timeout as duration
+func (d *DataQualityConfig) ContactRetentionTimeoutDuration() time.Duration {
+	return time.Duration(d.ContactRetentionTimeout) * time.Second
+}
+
+// RecurrenceExpansionTimeoutDuration returns the recurrence expansion timeout as duration
+func (a *ActivitiesConfig) RecurrenceExpansionTimeoutDuration() time.Duration {
+	return time.Duration(a.RecurrenceExpansionTimeout) * time.Second
+}
+
+// MailboxTimeoutDuration returns the mailbox ingestion timeout as duration
+func (e *EmailConfig) MailboxTimeoutDuration() time.Duration {
+	return time.Duration(e.MailboxTimeout) * time.Second
+}
+
+// DigestTimeoutDuration returns the stale item digest timeout as duration
+func (s *StalenessConfig) DigestTimeoutDuration() time.Duration {
+	return time.Duration(s.DigestTimeout) * time.Second
+}
+
+// WarningTimeoutDuration returns the inquiry SLA warning check timeout as duration
+func (i *InquirySLAConfig) WarningTimeoutDuration() time.Duration {
+	return time.Duration(i.WarningTimeout) * time.Second
+}
+
+// TimeoutDuration returns the mail delivery timeout as duration
+func (m *MailConfig) TimeoutDuration() time.Duration {
+	return time.Duration(m.Timeout) * time.Second
+}
+
+// ChallengeTTLDuration returns the proof-of-work challenge lifetime as duration
+func (i *IntakeConfig) ChallengeTTLDuration() time.Duration {
+	return time.Duration(i.ChallengeTTL) * time.Second
+}
+
 // Load loads configuration from file and environment variables
 // This is a basic load that doesn't fetch secrets from vault
 // Use LoadWithSecrets for full secret resolution
@@ -431,6 +630,11 @@ func LoadWithSecrets(ctx context.Context, logger *zap.Logger) (*Config, error) {
 		cfg.ApiKey.Value = apiKey
 	}
 
+	// SMTP password for outgoing mail
+	if password, err := provider.GetSecretOrEnv(ctx, "SMTP-PASSWORD", "MAIL_PASSWORD"); err == nil && password != "" {
+		cfg.Mail.Password = password
+	}
+
 	// Storage connection string (for cloud storage)
 	if connStr, err := provider.GetSecretOrEnv(ctx, "STORAGE-CONNECTION-STRING-RELATION", "STORAGE_CLOUDCONNECTIONSTRING"); err == nil && connStr != "" {
 		cfg.Storage.CloudConnectionString = connStr
@@ -545,6 +749,64 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("dataWarehouse.periodicSyncEnabled", false)       // Disabled by default
 	v.SetDefault("dataWarehouse.periodicSyncCron", "0 15 * * * *") // At minute 15 of every hour (with seconds field)
 	v.SetDefault("dataWarehouse.periodicSyncTimeout", 300)         // 5 minutes timeout for sync job
+	v.SetDefault("dataWarehouse.customerReconciliationEnabled", true)
+	v.SetDefault("dataWarehouse.customerReconciliationCron", "0 30 5 * * *") // Every day at 05:30 (with seconds field)
+	v.SetDefault("dataWarehouse.customerReconciliationTimeout", 600)         // 10 minutes
+	v.SetDefault("dataWarehouse.customerReconciliationAutoCreate", false)    // Queue missing customers for approval
+	v.SetDefault("dataWarehouse.creditExposureIncludeUnpaid", false)         // Exposure from order reserve and sent offers only
+
+	// Enhetsregisteret defaults (public API, no credentials)
+	v.SetDefault("brreg.enabled", true)
+	v.SetDefault("brreg.baseURL", "https://data.brreg.no/enhetsregisteret/api")
+	v.SetDefault("brreg.timeout", 10)      // 10 seconds per lookup
+	v.SetDefault("brreg.bulkDelayMs", 100) // Be polite to the public API during bulk runs
+
+	// Data quality check defaults
+	v.SetDefault("dataQuality.postalCodeCheckEnabled", true)
+	v.SetDefault("dataQuality.postalCodeCheckCron", "0 0 5 * * 1") // Mondays at 05:00 (with seconds field)
+	v.SetDefault("dataQuality.postalCodeCheckTimeout", 120)        // 2 minutes
+	v.SetDefault("dataQuality.tierRecalculationEnabled", true)
+	v.SetDefault("dataQuality.tierRecalculationCron", "0 0 3 * * *") // Every day at 03:00 (with seconds field)
+	v.SetDefault("dataQuality.tierRecalculationTimeout", 600)        // 10 minutes
+	v.SetDefault("dataQuality.contactRetentionEnabled", true)
+	v.SetDefault("dataQuality.contactRetentionCron", "0 0 4 * * 1") // Mondays at 04:00 (with seconds field)
+	v.SetDefault("dataQuality.contactRetentionTimeout", 300)        // 5 minutes
+	v.SetDefault("dataQuality.contactRetentionYears", 3)            // Flag contacts inactive for 3 years
+
+	// Activities defaults
+	v.SetDefault("activities.recurrenceExpansionEnabled", true)
+	v.SetDefault("activities.recurrenceExpansionCron", "0 0 2 * * *") // Every day at 02:00 (with seconds field)
+	v.SetDefault("activities.recurrenceExpansionTimeout", 300)        // 5 minutes
+	v.SetDefault("activities.recurrenceHorizonDays", 180)             // Generate occurrences half a year ahead
+
+	// Email ingestion defaults
+	v.SetDefault("email.mailboxEnabled", false)
+	v.SetDefault("email.mailboxPath", "./mail/inbox")
+	v.SetDefault("email.mailboxCron", "0 */5 * * * *") // Every 5 minutes (with seconds field)
+	v.SetDefault("email.mailboxTimeout", 300)          // 5 minutes
+
+	// Outgoing mail defaults
+	v.SetDefault("mail.smtpPort", 587)
+	v.SetDefault("mail.fromName", "Straye")
+	v.SetDefault("mail.timeout", 30) // 30 seconds
+
+	// Public inquiry intake defaults
+	v.SetDefault("intake.requestsPerMinute", 5)
+	v.SetDefault("intake.maxAttachments", 5)
+	v.SetDefault("intake.maxUploadSizeMB", 20)
+	v.SetDefault("intake.challengeTTL", 600) // 10 minutes
+
+	// Inquiry SLA defaults
+	v.SetDefault("inquirySla.warningEnabled", true)
+	v.SetDefault("inquirySla.warningCron", "0 */15 * * * *") // Every 15 minutes (with seconds field)
+	v.SetDefault("inquirySla.warningTimeout", 120)           // 2 minutes
+
+	// Staleness defaults
+	v.SetDefault("staleness.offerInProgressDays", 21)
+	v.SetDefault("staleness.offerSentDays", 30)
+	v.SetDefault("staleness.digestEnabled", true)
+	v.SetDefault("staleness.digestCron", "0 0 7 * * 1") // Mondays at 07:00 (with seconds field)
+	v.SetDefault("staleness.digestTimeout", 300)        // 5 minutes
 
 	// Secrets defaults
 	v.SetDefault("secrets.source", "auto")
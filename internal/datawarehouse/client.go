@@ -34,6 +34,10 @@ const (
 	EmployeeCostMin  = 5000
 	EmployeeCostMax  = 5999
 	OtherCostMin     = 6000
+
+	// Accounts receivable (kundefordringer) range; the balance is the unpaid amount
+	ReceivableAccountMin = 1500
+	ReceivableAccountMax = 1599
 )
 
 // CompanyMapping maps Straye company identifiers to data warehouse table name prefixes.
@@ -663,24 +667,34 @@ func truncateQuery(query string, maxLen int) string {
 
 // ERPCustomer represents a customer record from the ERP data warehouse.
 type ERPCustomer struct {
+	Firmanr            int    `json:"firmanr"`        // Company the customer is registered in
+	CustomerNumber     string `json:"customerNumber"` // Kundenr - ERP customer number within the company
 	OrganizationNumber string `json:"organizationNumber"`
 	Name               string `json:"name"`
+	Address            string `json:"address"`
+	PostalCode         string `json:"postalCode"`
+	City               string `json:"city"`
 }
 
 // GetERPCustomers retrieves all customers from the ERP data warehouse.
 // Uses the table dbo.Kunde which contains customers across all companies.
-// Returns customers with their organization numbers and names for matching.
+// Returns customers with their organization numbers, names and postal addresses for matching.
 func (c *Client) GetERPCustomers(ctx context.Context) ([]ERPCustomer, error) {
 	if c == nil || c.db == nil {
 		return nil, fmt.Errorf("data warehouse client not initialized")
 	}
 
 	// Query all customers from dbo.Kunde
-	// Columns: Firmanr, KundeId, Kundenr, Kundenavn, Organisasjonsnr
+	// Columns: Firmanr, KundeId, Kundenr, Kundenavn, Organisasjonsnr, Adresse, Postnr, Poststed
 	query := `
 		SELECT
+			Firmanr,
+			ISNULL(CAST(Kundenr AS NVARCHAR(50)), '') as Kundenr,
 			ISNULL(Organisasjonsnr, '') as Organisasjonsnr,
-			ISNULL(Kundenavn, '') as Kundenavn
+			ISNULL(Kundenavn, '') as Kundenavn,
+			ISNULL(Adresse, '') as Adresse,
+			ISNULL(Postnr, '') as Postnr,
+			ISNULL(Poststed, '') as Poststed
 		FROM dbo.Kunde
 	`
 
@@ -694,8 +708,13 @@ func (c *Client) GetERPCustomers(ctx context.Context) ([]ERPCustomer, error) {
 	customers := make([]ERPCustomer, 0, len(rows))
 	for _, row := range rows {
 		customer := ERPCustomer{
+			Firmanr:            int(parseInt64(row["Firmanr"])),
+			CustomerNumber:     parseString(row["Kundenr"]),
 			OrganizationNumber: parseString(row["Organisasjonsnr"]),
 			Name:               parseString(row["Kundenavn"]),
+			Address:            parseString(row["Adresse"]),
+			PostalCode:         parseString(row["Postnr"]),
+			City:               parseString(row["Poststed"]),
 		}
 		customers = append(customers, customer)
 	}
@@ -707,6 +726,53 @@ func (c *Client) GetERPCustomers(ctx context.Context) ([]ERPCustomer, error) {
 	return customers, nil
 }
 
+// GetCustomerUnpaidAmount returns the unpaid balance of a customer across all companies.
+// Sums the accounts receivable balance (accounts 1500-1599) in each company's general ledger
+// for the ERP customer numbers registered with the organization number in dbo.Kunde.
+func (c *Client) GetCustomerUnpaidAmount(ctx context.Context, orgNumber string) (float64, error) {
+	if c == nil || c.db == nil {
+		return 0, fmt.Errorf("data warehouse client not initialized")
+	}
+
+	total := 0.0
+	for companyID := range CompanyMapping {
+		firmanr, err := GetFirmanr(companyID)
+		if err != nil {
+			continue
+		}
+		tableName, err := GetGeneralLedgerTableName(companyID)
+		if err != nil {
+			continue
+		}
+
+		query := fmt.Sprintf(`
+			SELECT COALESCE(SUM(gl.PostedAmountDomestic), 0) as unpaid
+			FROM %s gl
+			INNER JOIN dbo.Kunde k ON CAST(k.Kundenr AS NVARCHAR(50)) = CAST(gl.CustomerNo AS NVARCHAR(50))
+			WHERE k.Firmanr = @p1
+			  AND k.Organisasjonsnr = @p2
+			  AND gl.AccountNo >= @p3
+			  AND gl.AccountNo <= @p4
+		`, tableName)
+
+		row, err := c.QueryRow(ctx, query, firmanr, orgNumber, ReceivableAccountMin, ReceivableAccountMax)
+		if err != nil {
+			return 0, fmt.Errorf("query unpaid amount for %s: %w", companyID, err)
+		}
+		if row == nil {
+			continue
+		}
+
+		unpaid, err := parseFloat64(row["unpaid"])
+		if err != nil {
+			return 0, fmt.Errorf("parse unpaid amount for %s: %w", companyID, err)
+		}
+		total += unpaid
+	}
+
+	return total, nil
+}
+
 // ERPAssignment represents an assignment (work order) from the ERP data warehouse.
 // Assignments belong to projects and contain work order details.
 // Data is sourced from the dbo.Arbeidsordre view which contains all companies.
@@ -41,6 +41,18 @@ type CustomerDTO struct {
 	CreatedByName string `json:"createdByName,omitempty"`
 	UpdatedByID   string `json:"updatedById,omitempty"`
 	UpdatedByName string `json:"updatedByName,omitempty"`
+	// Enhetsregisteret fields; bankrupt, deleted and under_liquidation customers are flagged
+	IndustryCode      string                 `json:"industryCode,omitempty"` // NACE code
+	RegistryStatus    CustomerRegistryStatus `json:"registryStatus,omitempty"`
+	RegistryFlagged   bool                   `json:"registryFlagged"`
+	RegistryCheckedAt *string                `json:"registryCheckedAt,omitempty"` // ISO 8601
+	// Tier override; a locked tier is not changed by the nightly recalculation
+	TierLocked       bool    `json:"tierLocked"`
+	TierLockedAt     *string `json:"tierLockedAt,omitempty"` // ISO 8601
+	TierLockedByName string  `json:"tierLockedByName,omitempty"`
+	TierCalculatedAt *string `json:"tierCalculatedAt,omitempty"` // ISO 8601
+	// Customer group; set on subsidiaries
+	ParentCustomerID *uuid.UUID `json:"parentCustomerId,omitempty"`
 }
 
 // CustomerWithDetailsDTO includes customer data with related entities and statistics
@@ -110,6 +122,10 @@ type ContactDTO struct {
 	PreferredContactMethod string                   `json:"preferredContactMethod,omitempty"`
 	Notes                  string                   `json:"notes,omitempty"`
 	IsActive               bool                     `json:"isActive"`
+	AnonymizedAt           *string                  `json:"anonymizedAt,omitempty"`        // ISO 8601, set when personal data has been scrubbed
+	RetentionFlaggedAt     *string                  `json:"retentionFlaggedAt,omitempty"`  // ISO 8601, set when flagged for retention review
+	RetentionReviewedAt    *string                  `json:"retentionReviewedAt,omitempty"` // ISO 8601, last time a flagged contact was kept
+	MergedIntoID           *uuid.UUID               `json:"mergedIntoId,omitempty"`        // Surviving contact this duplicate was merged into
 	Relationships          []ContactRelationshipDTO `json:"relationships,omitempty"`
 	CreatedAt              string                   `json:"createdAt"` // ISO 8601
 	UpdatedAt              string                   `json:"updatedAt"` // ISO 8601
@@ -127,6 +143,8 @@ type ContactRelationshipDTO struct {
 	EntityID   uuid.UUID         `json:"entityId"`
 	Role       string            `json:"role,omitempty"`
 	IsPrimary  bool              `json:"isPrimary"`
+	BuyingRole *BuyingRole       `json:"buyingRole,omitempty" enums:"decision_maker,economic_buyer,technical_evaluator,influencer,blocker"`
+	Sentiment  *ContactSentiment `json:"sentiment,omitempty" enums:"positive,neutral,negative"`
 	CreatedAt  string            `json:"createdAt"`
 }
 
@@ -151,8 +169,14 @@ type DealDTO struct {
 	LostReason         string              `json:"lostReason,omitempty"`
 	LossReasonCategory *LossReasonCategory `json:"lossReasonCategory,omitempty"`
 	OfferID            *uuid.UUID          `json:"offerId,omitempty"`
+	PipelineID         *uuid.UUID          `json:"pipelineId,omitempty"`
+	ProbabilityAtClose *int                `json:"probabilityAtClose,omitempty"` // Probability entered before the deal was won or lost
 	CreatedAt          string              `json:"createdAt"`
 	UpdatedAt          string              `json:"updatedAt"`
+	// Staleness - computed from stage history and activities
+	DaysInStage           int  `json:"daysInStage"`
+	DaysSinceLastActivity *int `json:"daysSinceLastActivity,omitempty"` // Nil when the deal has no activities
+	IsStale               bool `json:"isStale"`                         // No stage change or activity within the stage's threshold
 }
 
 type DealStageHistoryDTO struct {
@@ -207,6 +231,9 @@ type OfferDTO struct {
 	StartDate               *string  `json:"startDate,omitempty"`               // ISO 8601 - When work started
 	EndDate                 *string  `json:"endDate,omitempty"`                 // ISO 8601 - Planned end date
 	EstimatedCompletionDate *string  `json:"estimatedCompletionDate,omitempty"` // ISO 8601 - Current estimate for completion
+	// Inquiry response SLA (draft phase)
+	InquirySLADueAt    *string `json:"inquirySlaDueAt,omitempty"`    // ISO 8601 - When the customer must have had a response
+	InquiryRespondedAt *string `json:"inquiryRespondedAt,omitempty"` // ISO 8601 - When the inquiry was converted to an offer
 	// User tracking fields
 	CreatedByID   string `json:"createdById,omitempty"`
 	CreatedByName string `json:"createdByName,omitempty"`
@@ -220,9 +247,13 @@ type OfferDTO struct {
 	DWNetResult       float64 `json:"dwNetResult"`              // Net result from data warehouse
 	DWTotalFixedPrice float64 `json:"dwTotalFixedPrice"`        // Sum of FixedPriceAmount from synced assignments
 	DWLastSyncedAt    *string `json:"dwLastSyncedAt,omitempty"` // ISO 8601 - Last sync timestamp
-	// Validation warnings - computed at DTO mapping time
-	// Possible values: value.not.equals.dwTotalFixedPrice, missing.dwTotalFixedPrice
-	Warnings []OfferWarning `json:"warnings,omitempty" enums:"value.not.equals.dwTotalFixedPrice,missing.dwTotalFixedPrice"` // Warning codes for data discrepancies
+	// Staleness - computed from the phase change and activities
+	DaysInPhase           int  `json:"daysInPhase"`
+	DaysSinceLastActivity *int `json:"daysSinceLastActivity,omitempty"` // Nil when the offer has no activities
+	IsStale               bool `json:"isStale"`                         // No phase change or activity within the phase's threshold
+	// Validation warnings - computed at DTO mapping time, missing.decisionMaker only when sending
+	// Possible values: value.not.equals.dwTotalFixedPrice, missing.dwTotalFixedPrice, missing.decisionMaker
+	Warnings []OfferWarning `json:"warnings,omitempty" enums:"value.not.equals.dwTotalFixedPrice,missing.dwTotalFixedPrice,missing.decisionMaker"` // Warning codes for data discrepancies
 }
 
 type OfferItemDTO struct {
@@ -251,8 +282,12 @@ type BudgetItemDTO struct {
 	PricePerItem    *float64         `json:"pricePerItem,omitempty"`
 	Description     string           `json:"description,omitempty"`
 	DisplayOrder    int              `json:"displayOrder"`
-	CreatedAt       string           `json:"createdAt"`
-	UpdatedAt       string           `json:"updatedAt"`
+	// CostCategory is where the item's actual costs are booked in the data warehouse
+	CostCategory BudgetCostCategory `json:"costCategory,omitempty" enums:"material,employee,other"`
+	// SourceItemID is the offer item a project budget item was rebudgeted from
+	SourceItemID *uuid.UUID `json:"sourceItemId,omitempty"`
+	CreatedAt    string     `json:"createdAt"`
+	UpdatedAt    string     `json:"updatedAt"`
 }
 
 type BudgetSummaryDTO struct {
@@ -369,14 +404,32 @@ type CompanyDetailDTO struct {
 	IsActive                    bool    `json:"isActive"`
 	DefaultOfferResponsibleID   *string `json:"defaultOfferResponsibleId,omitempty"`
 	DefaultProjectResponsibleID *string `json:"defaultProjectResponsibleId,omitempty"`
-	CreatedAt                   string  `json:"createdAt"`
-	UpdatedAt                   string  `json:"updatedAt"`
+	// CreditLimitEnforcement is warn or block for orders over the customer credit limit
+	CreditLimitEnforcement CreditLimitEnforcement `json:"creditLimitEnforcement"`
+	// InquirySLAHours is the business hours the company has to respond to an inquiry
+	InquirySLAHours int `json:"inquirySlaHours"`
+	// InquirySLAWarningHours is the business hours before the SLA deadline the responsible user is warned
+	InquirySLAWarningHours int `json:"inquirySlaWarningHours"`
+	// BusinessDayStart and BusinessDayEnd are the business hours (HH:MM, Europe/Oslo)
+	BusinessDayStart string `json:"businessDayStart"`
+	BusinessDayEnd   string `json:"businessDayEnd"`
+	CreatedAt        string `json:"createdAt"`
+	UpdatedAt        string `json:"updatedAt"`
 }
 
 // UpdateCompanyRequest contains the data for updating company settings
 type UpdateCompanyRequest struct {
 	DefaultOfferResponsibleID   *string `json:"defaultOfferResponsibleId,omitempty" validate:"omitempty,max=100"`
 	DefaultProjectResponsibleID *string `json:"defaultProjectResponsibleId,omitempty" validate:"omitempty,max=100"`
+	// CreditLimitEnforcement is warn or block for orders over the customer credit limit
+	CreditLimitEnforcement *CreditLimitEnforcement `json:"creditLimitEnforcement,omitempty" validate:"omitempty,oneof=warn block"`
+	// InquirySLAHours is the business hours the company has to respond to an inquiry
+	InquirySLAHours *int `json:"inquirySlaHours,omitempty" validate:"omitempty,min=1,max=400"`
+	// InquirySLAWarningHours is the business hours before the SLA deadline the responsible user is warned
+	InquirySLAWarningHours *int `json:"inquirySlaWarningHours,omitempty" validate:"omitempty,min=0,max=400"`
+	// BusinessDayStart and BusinessDayEnd are the business hours (HH:MM, Europe/Oslo)
+	BusinessDayStart *string `json:"businessDayStart,omitempty" validate:"omitempty,len=5"`
+	BusinessDayEnd   *string `json:"businessDayEnd,omitempty" validate:"omitempty,len=5"`
 }
 
 // PermissionDTO represents a single permission
@@ -449,6 +502,28 @@ type DateRangeFilter struct {
 	ToDate   *time.Time `json:"toDate,omitempty"`   // End of date range (23:59:59)
 }
 
+// IntercompanyView selects how dashboard metrics treat offers to internal customers (Straye group companies)
+type IntercompanyView string
+
+const (
+	// IntercompanyViewAll counts offers to internal and external customers together (default)
+	IntercompanyViewAll IntercompanyView = "all"
+	// IntercompanyViewConsolidated eliminates offers to internal customers, giving consolidated group figures
+	IntercompanyViewConsolidated IntercompanyView = "consolidated"
+	// IntercompanyViewSplit counts all offers and reports internal and external figures separately
+	IntercompanyViewSplit IntercompanyView = "split"
+)
+
+// IsValid checks if the IntercompanyView value is valid
+func (v IntercompanyView) IsValid() bool {
+	switch v {
+	case IntercompanyViewAll, IntercompanyViewConsolidated, IntercompanyViewSplit:
+		return true
+	default:
+		return false
+	}
+}
+
 type DisciplineStats struct {
 	Name         string  `json:"name"`
 	TotalValue   float64 `json:"totalValue"`
@@ -556,6 +631,54 @@ type DashboardMetrics struct {
 
 	// Top Customers (limit 5)
 	TopCustomers []TopCustomerDTO `json:"topCustomers"` // Ranked by won offer count and value (order + completed phases)
+
+	// View indicates how offers to internal customers are treated: "all" (default), "consolidated" or "split"
+	View IntercompanyView `json:"view"`
+	// Intercompany holds internal and external figures separately, only set for the "split" view
+	Intercompany *IntercompanySplitDTO `json:"intercompany,omitempty"`
+}
+
+// IntercompanyFiguresDTO holds the key dashboard figures for either internal or external customers
+type IntercompanyFiguresDTO struct {
+	OfferCount           int     `json:"offerCount"`           // Count of offers excluding drafts and expired
+	OfferReserve         float64 `json:"offerReserve"`         // Total value of active offers - best per project
+	WeightedOfferReserve float64 `json:"weightedOfferReserve"` // Sum of (value * probability/100) for active offers
+	WonCount             int     `json:"wonCount"`             // Offers in order or completed phases
+	WonValue             float64 `json:"wonValue"`             // Value of offers in order or completed phases
+	ActiveOrderCount     int     `json:"activeOrderCount"`     // Count of offers in order phase
+	OrderValue           float64 `json:"orderValue"`           // Total value of offers in order phase
+	OrderReserve         float64 `json:"orderReserve"`         // Sum of (value - invoiced) for order phase offers
+	TotalInvoiced        float64 `json:"totalInvoiced"`        // Sum of invoiced for order phase offers
+}
+
+// IntercompanySplitDTO separates dashboard figures for internal customers (Straye group companies) from external customers
+type IntercompanySplitDTO struct {
+	Internal IntercompanyFiguresDTO `json:"internal"`
+	External IntercompanyFiguresDTO `json:"external"`
+}
+
+// IntercompanyMatrixCellDTO holds won offers from one group company to one internal customer
+type IntercompanyMatrixCellDTO struct {
+	SellerCompanyID   CompanyID  `json:"sellerCompanyId"`
+	BuyerCompanyID    *CompanyID `json:"buyerCompanyId,omitempty"` // Group company with the customer's organization number, if any
+	BuyerCustomerID   uuid.UUID  `json:"buyerCustomerId"`
+	BuyerCustomerName string     `json:"buyerCustomerName"`
+	WonCount          int        `json:"wonCount"`     // Offers in order or completed phases
+	WonValue          float64    `json:"wonValue"`     // Value of won offers
+	Invoiced          float64    `json:"invoiced"`     // Invoiced on won offers
+	OrderReserve      float64    `json:"orderReserve"` // Sum of (value - invoiced) for order phase offers
+}
+
+// IntercompanyMatrixDTO shows which group companies sell to which, for elimination in the group accounts
+type IntercompanyMatrixDTO struct {
+	TimeRange         TimeRange                   `json:"timeRange"`
+	FromDate          *string                     `json:"fromDate,omitempty"` // ISO 8601 format
+	ToDate            *string                     `json:"toDate,omitempty"`   // ISO 8601 format
+	Cells             []IntercompanyMatrixCellDTO `json:"cells"`
+	TotalWonCount     int                         `json:"totalWonCount"`
+	TotalWonValue     float64                     `json:"totalWonValue"`
+	TotalInvoiced     float64                     `json:"totalInvoiced"`
+	TotalOrderReserve float64                     `json:"totalOrderReserve"`
 }
 
 // Search DTOs
@@ -678,6 +801,16 @@ type AddContactRelationshipRequest struct {
 	EntityID   uuid.UUID         `json:"entityId" validate:"required"`
 	Role       string            `json:"role,omitempty" validate:"max=100"`
 	IsPrimary  bool              `json:"isPrimary,omitempty"`
+	BuyingRole *BuyingRole       `json:"buyingRole,omitempty" enums:"decision_maker,economic_buyer,technical_evaluator,influencer,blocker"` // Offers only
+	Sentiment  *ContactSentiment `json:"sentiment,omitempty" enums:"positive,neutral,negative"`                                             // Offers only
+}
+
+// UpdateContactRelationshipRequest updates the role of a contact on an entity.
+// Omitted buying role and sentiment are cleared.
+type UpdateContactRelationshipRequest struct {
+	Role       string            `json:"role,omitempty" validate:"max=100"`
+	BuyingRole *BuyingRole       `json:"buyingRole,omitempty" enums:"decision_maker,economic_buyer,technical_evaluator,influencer,blocker"` // Offers only
+	Sentiment  *ContactSentiment `json:"sentiment,omitempty" enums:"positive,neutral,negative"`                                             // Offers only
 }
 
 // Deal request DTOs
@@ -695,6 +828,7 @@ type CreateDealRequest struct {
 	Source            string     `json:"source,omitempty" validate:"max=100"`
 	Notes             string     `json:"notes,omitempty"`
 	OfferID           *uuid.UUID `json:"offerId,omitempty"`
+	PipelineID        *uuid.UUID `json:"pipelineId,omitempty"` // Defaults to the company's default pipeline
 }
 
 type UpdateDealRequest struct {
@@ -805,26 +939,30 @@ type UpdateOfferItemRequest struct {
 
 // Budget Item Request DTOs
 
+
 type CreateBudgetItemRequest struct {
-	ParentType     BudgetParentType `json:"parentType" validate:"required"`
-	ParentID       uuid.UUID        `json:"parentId" validate:"required"`
-	Name           string           `json:"name" validate:"required,max=200"`
-	ExpectedCost   float64          `json:"expectedCost" validate:"gte=0"`
-	ExpectedMargin float64          `json:"expectedMargin" validate:"gte=0,lte=100"`
-	Quantity       *float64         `json:"quantity,omitempty" validate:"omitempty,gte=0"`
-	PricePerItem   *float64         `json:"pricePerItem,omitempty" validate:"omitempty,gte=0"`
-	Description    string           `json:"description,omitempty"`
-	DisplayOrder   int              `json:"displayOrder,omitempty" validate:"gte=0"`
+	ParentType     BudgetParentType   `json:"parentType" validate:"required"`
+	ParentID       uuid.UUID          `json:"parentId" validate:"required"`
+	Name           string             `json:"name" validate:"required,max=200"`
+	ExpectedCost   float64            `json:"expectedCost" validate:"gte=0"`
+	ExpectedMargin float64            `json:"expectedMargin" validate:"gte=0,lte=100"`
+	Quantity       *float64           `json:"quantity,omitempty" validate:"omitempty,gte=0"`
+	PricePerItem   *float64           `json:"pricePerItem,omitempty" validate:"omitempty,gte=0"`
+	Description    string             `json:"description,omitempty"`
+	DisplayOrder   int                `json:"displayOrder,omitempty" validate:"gte=0"`
+	CostCategory   BudgetCostCategory `json:"costCategory,omitempty" validate:"omitempty,oneof=material employee other"`
 }
 
+
 type UpdateBudgetItemRequest struct {
-	Name           string   `json:"name" validate:"required,max=200"`
-	ExpectedCost   float64  `json:"expectedCost" validate:"gte=0"`
-	ExpectedMargin float64  `json:"expectedMargin" validate:"gte=0,lte=100"`
-	Quantity       *float64 `json:"quantity,omitempty" validate:"omitempty,gte=0"`
-	PricePerItem   *float64 `json:"pricePerItem,omitempty" validate:"omitempty,gte=0"`
-	Description    string   `json:"description,omitempty"`
-	DisplayOrder   int      `json:"displayOrder,omitempty" validate:"gte=0"`
+	Name           string             `json:"name" validate:"required,max=200"`
+	ExpectedCost   float64            `json:"expectedCost" validate:"gte=0"`
+	ExpectedMargin float64            `json:"expectedMargin" validate:"gte=0,lte=100"`
+	Quantity       *float64           `json:"quantity,omitempty" validate:"omitempty,gte=0"`
+	PricePerItem   *float64           `json:"pricePerItem,omitempty" validate:"omitempty,gte=0"`
+	Description    string             `json:"description,omitempty"`
+	DisplayOrder   int                `json:"displayOrder,omitempty" validate:"gte=0"`
+	CostCategory   BudgetCostCategory `json:"costCategory,omitempty" validate:"omitempty,oneof=material employee other"`
 }
 
 // ReorderBudgetItemsRequest contains the ordered list of budget item IDs
@@ -834,14 +972,32 @@ type ReorderBudgetItemsRequest struct {
 
 // AddOfferBudgetItemRequest is the simplified request for adding budget items to an offer
 // ParentType and ParentID are inferred from the URL
+
 type AddOfferBudgetItemRequest struct {
-	Name           string   `json:"name" validate:"required,max=200"`
-	ExpectedCost   float64  `json:"expectedCost" validate:"gte=0"`
-	ExpectedMargin float64  `json:"expectedMargin" validate:"gte=0,lte=100"`
-	Quantity       *float64 `json:"quantity,omitempty" validate:"omitempty,gte=0"`
-	PricePerItem   *float64 `json:"pricePerItem,omitempty" validate:"omitempty,gte=0"`
-	Description    string   `json:"description,omitempty"`
-	DisplayOrder   int      `json:"displayOrder,omitempty" validate:"gte=0"`
+	Name           string             `json:"name" validate:"required,max=200"`
+	ExpectedCost   float64            `json:"expectedCost" validate:"gte=0"`
+	ExpectedMargin float64            `json:"expectedMargin" validate:"gte=0,lte=100"`
+	Quantity       *float64           `json:"quantity,omitempty" validate:"omitempty,gte=0"`
+	PricePerItem   *float64           `json:"pricePerItem,omitempty" validate:"omitempty,gte=0"`
+	Description    string             `json:"description,omitempty"`
+	DisplayOrder   int                `json:"displayOrder,omitempty" validate:"gte=0"`
+	CostCategory   BudgetCostCategory `json:"costCategory,omitempty" validate:"omitempty,oneof=material employee other"`
+}
+
+// AddProjectBudgetItemRequest adds a budget item to a project's working budget
+// ParentType and ParentID are inferred from the URL
+
+type AddProjectBudgetItemRequest struct {
+	Name           string             `json:"name" validate:"required,max=200"`
+	ExpectedCost   float64            `json:"expectedCost" validate:"gte=0"`
+	ExpectedMargin float64            `json:"expectedMargin" validate:"gte=0,lte=100"`
+	Quantity       *float64           `json:"quantity,omitempty" validate:"omitempty,gte=0"`
+	PricePerItem   *float64           `json:"pricePerItem,omitempty" validate:"omitempty,gte=0"`
+	Description    string             `json:"description,omitempty"`
+	DisplayOrder   int                `json:"displayOrder,omitempty" validate:"gte=0"`
+	CostCategory   BudgetCostCategory `json:"costCategory,omitempty" validate:"omitempty,oneof=material employee other"`
+	// SourceItemID is the budget item of a won offer in the project this item rebudgets
+	SourceItemID *uuid.UUID `json:"sourceItemId,omitempty"`
 }
 
 // Project Actual Cost Request DTOs
@@ -926,8 +1082,9 @@ type AcceptOfferRequest struct {
 
 // AcceptOfferResponse contains the result of accepting an offer
 type AcceptOfferResponse struct {
-	Offer   *OfferDTO   `json:"offer"`
-	Project *ProjectDTO `json:"project,omitempty"` // Only present if createProject was true
+	Offer       *OfferDTO       `json:"offer"`
+	Project     *ProjectDTO     `json:"project,omitempty"`     // Only present if createProject was true
+	CreditCheck *CreditCheckDTO `json:"creditCheck,omitempty"` // Only present if the customer has a credit limit
 }
 
 // RejectOfferRequest contains the reason for rejecting an offer
@@ -967,7 +1124,10 @@ type AcceptOrderRequest struct {
 
 // AcceptOrderResponse contains the result of accepting an order
 type AcceptOrderResponse struct {
-	Offer *OfferDTO `json:"offer"`
+	Offer       *OfferDTO       `json:"offer"`
+	CreditCheck *CreditCheckDTO `json:"creditCheck,omitempty"` // Only present if the customer has a credit limit
+	// CapacityWarning is present when the offer's manager is already over capacity in the order's start-end window
+	CapacityWarning *CapacityWarningDTO `json:"capacityWarning,omitempty"`
 }
 
 // UpdateOfferHealthRequest contains the health status update for an offer in order phase
@@ -1033,6 +1193,11 @@ type ActivityDTO struct {
 	CompanyID        *CompanyID         `json:"companyId,omitempty"`
 	Attendees        []string           `json:"attendees,omitempty"`
 	ParentActivityID *uuid.UUID         `json:"parentActivityId,omitempty"`
+	// RecurrenceRule is set on the master of a recurring series
+	RecurrenceRule        string     `json:"recurrenceRule,omitempty"`
+	SeriesID              *uuid.UUID `json:"seriesId,omitempty"`
+	OccurrenceAt          string     `json:"occurrenceAt,omitempty"`
+	IsRecurrenceException bool       `json:"isRecurrenceException,omitempty"`
 }
 
 // UserRole DTOs
@@ -1165,6 +1330,8 @@ type CreateActivityRequest struct {
 	AssignedToID    string             `json:"assignedToId,omitempty" validate:"max=100"`
 	CompanyID       *CompanyID         `json:"companyId,omitempty"`
 	Attendees       []string           `json:"attendees,omitempty"`
+	// RecurrenceRule is an RFC 5545 RRULE, e.g. FREQ=MONTHLY;INTERVAL=3. Requires scheduledAt or dueDate.
+	RecurrenceRule string `json:"recurrenceRule,omitempty" validate:"max=500"`
 }
 
 // UpdateActivityRequest contains the data for updating an existing activity
@@ -1179,6 +1346,11 @@ type UpdateActivityRequest struct {
 	IsPrivate       bool           `json:"isPrivate,omitempty"`
 	AssignedToID    string         `json:"assignedToId,omitempty" validate:"max=100"`
 	Attendees       []string       `json:"attendees,omitempty"`
+	// RecurrenceScope selects the occurrences of a recurring series the update applies to
+	RecurrenceScope RecurrenceScope `json:"recurrenceScope,omitempty" enums:"this,following,all"`
+	// RecurrenceRule replaces the rule of the series with scope following or all, or makes a single
+	// activity recurring. An empty rule ends the series.
+	RecurrenceRule *string `json:"recurrenceRule,omitempty" validate:"omitempty,max=500"`
 }
 
 // CompleteActivityRequest contains optional outcome when completing an activity
@@ -1292,10 +1464,11 @@ type PipelineAnalyticsDTO struct {
 
 // PipelineAnalyticsFilters contains optional filters for analytics queries
 type PipelineAnalyticsFilters struct {
-	CompanyID *CompanyID `json:"companyId,omitempty"`
-	OwnerID   *string    `json:"ownerId,omitempty"`
-	DateFrom  *time.Time `json:"dateFrom,omitempty"`
-	DateTo    *time.Time `json:"dateTo,omitempty"`
+	CompanyID  *CompanyID `json:"companyId,omitempty"`
+	PipelineID *uuid.UUID `json:"pipelineId,omitempty"`
+	OwnerID    *string    `json:"ownerId,omitempty"`
+	DateFrom   *time.Time `json:"dateFrom,omitempty"`
+	DateTo     *time.Time `json:"dateTo,omitempty"`
 }
 
 // CreateOfferFromDealResponse contains the result of creating an offer from a deal
@@ -1320,6 +1493,8 @@ type CreateInquiryRequest struct {
 	Description string     `json:"description,omitempty"`
 	DueDate     *time.Time `json:"dueDate,omitempty"`
 	Responsible string     `json:"responsible,omitempty" validate:"omitempty,max=200"`
+	// EstimatedValue is stored as the inquiry's value and used by value-based assignment rules
+	EstimatedValue *float64 `json:"estimatedValue,omitempty" validate:"omitempty,gte=0"`
 }
 
 // ConvertInquiryRequest contains options for converting an inquiry to an offer
@@ -1332,6 +1507,8 @@ type ConvertInquiryRequest struct {
 type ConvertInquiryResponse struct {
 	Offer       *OfferDTO `json:"offer"`
 	OfferNumber string    `json:"offerNumber"`
+	// AssignmentReason explains how the responsible was chosen when none was given
+	AssignmentReason string `json:"assignmentReason,omitempty"`
 }
 
 // UpdateInquiryCompanyRequest for updating the company of an inquiry
@@ -1469,6 +1646,11 @@ type UpdateCustomerClassRequest struct {
 	CustomerClass string `json:"customerClass" validate:"max=50"`
 }
 
+// UpdateCustomerParentRequest for placing a customer in a customer group
+type UpdateCustomerParentRequest struct {
+	ParentCustomerID *uuid.UUID `json:"parentCustomerId"` // nullable to remove the customer from its group
+}
+
 // UpdateCustomerCreditLimitRequest for updating customer credit limit
 type UpdateCustomerCreditLimitRequest struct {
 	CreditLimit *float64 `json:"creditLimit"` // nullable to allow clearing
@@ -1652,6 +1834,53 @@ type CustomerERPDifferencesResponse struct {
 	DataWarehouseEnabled bool               `json:"dataWarehouseEnabled"` // Whether data warehouse is available
 }
 
+// ============================================================================
+// Customer Registry (Enhetsregisteret) Enrichment DTOs
+// ============================================================================
+
+// EnrichCustomerRequest controls how Enhetsregisteret data is applied to a customer
+type EnrichCustomerRequest struct {
+	// Overwrite replaces existing values with registry values. By default only empty fields are filled.
+	Overwrite bool `json:"overwrite"`
+}
+
+// BulkEnrichCustomersRequest selects customers for bulk enrichment from Enhetsregisteret.
+// If CustomerIDs is empty, customers with an org number that have not been checked
+// within StaleDays are processed, oldest check first.
+type BulkEnrichCustomersRequest struct {
+	CustomerIDs []uuid.UUID `json:"customerIds,omitempty" validate:"max=500"`
+	Overwrite   bool        `json:"overwrite"`
+	StaleDays   int         `json:"staleDays,omitempty" validate:"gte=0"`     // Default 30
+	Limit       int         `json:"limit,omitempty" validate:"gte=0,lte=500"` // Default 100
+}
+
+// CustomerFieldChangeDTO describes a single field changed by enrichment
+type CustomerFieldChangeDTO struct {
+	Field    string `json:"field"`
+	OldValue string `json:"oldValue"`
+	NewValue string `json:"newValue"`
+}
+
+// CustomerEnrichmentResultDTO is the outcome of enriching one customer from Enhetsregisteret
+type CustomerEnrichmentResultDTO struct {
+	CustomerID      uuid.UUID                `json:"customerId"`
+	CustomerName    string                   `json:"customerName"`
+	OrgNumber       string                   `json:"orgNumber"`
+	RegistryStatus  CustomerRegistryStatus   `json:"registryStatus,omitempty"`
+	RegistryFlagged bool                     `json:"registryFlagged"`
+	Changes         []CustomerFieldChangeDTO `json:"changes"`
+	Error           string                   `json:"error,omitempty"`
+}
+
+// BulkCustomerEnrichmentResultDTO summarizes a bulk enrichment run
+type BulkCustomerEnrichmentResultDTO struct {
+	Processed int                           `json:"processed"`
+	Enriched  int                           `json:"enriched"` // Customers with at least one changed field
+	Flagged   int                           `json:"flagged"`  // Customers that are bankrupt, deleted or under liquidation
+	Failed    int                           `json:"failed"`
+	Results   []CustomerEnrichmentResultDTO `json:"results"`
+}
+
 // ============================================================================
 // Supplier DTOs
 // ============================================================================
@@ -1963,3 +2192,1210 @@ type AssignmentSummaryDTO struct {
 	DifferencePercent    float64   `json:"differencePercent"`    // (difference / offerValue) * 100
 	LastSyncedAt         *string   `json:"lastSyncedAt,omitempty"` // ISO 8601, most recent sync
 }
+
+// ============================================================================
+// Postal Code DTOs
+// ============================================================================
+
+// PostalCodeDTO is an entry in the Norwegian postal code register
+type PostalCodeDTO struct {
+	PostalCode         string `json:"postalCode"`
+	City               string `json:"city"`
+	MunicipalityNumber string `json:"municipalityNumber"`
+	Municipality       string `json:"municipality"`
+	County             string `json:"county,omitempty"`
+	Category           string `json:"category"` // Posten category: B, F, G, P or S
+}
+
+// PostalCodeMismatchReason explains why a record is listed in the postal code mismatch report
+type PostalCodeMismatchReason string
+
+const (
+	// PostalCodeMismatchCity means the city does not match the city of the postal code
+	PostalCodeMismatchCity PostalCodeMismatchReason = "city_mismatch"
+	// PostalCodeMismatchUnknown means the postal code is not in the register
+	PostalCodeMismatchUnknown PostalCodeMismatchReason = "unknown_postal_code"
+)
+
+// PostalCodeMismatchDTO is a customer or supplier whose city does not match its postal code
+type PostalCodeMismatchDTO struct {
+	EntityType   string                   `json:"entityType"` // customer or supplier
+	EntityID     uuid.UUID                `json:"entityId"`
+	Name         string                   `json:"name"`
+	PostalCode   string                   `json:"postalCode"`
+	City         string                   `json:"city"`
+	ExpectedCity string                   `json:"expectedCity,omitempty"`
+	Reason       PostalCodeMismatchReason `json:"reason"`
+}
+
+// PostalCodeMismatchReportDTO lists records with inconsistent postal code and city
+type PostalCodeMismatchReportDTO struct {
+	CustomersChecked int                     `json:"customersChecked"`
+	SuppliersChecked int                     `json:"suppliersChecked"`
+	MismatchCount    int                     `json:"mismatchCount"`
+	Mismatches       []PostalCodeMismatchDTO `json:"mismatches"`
+	GeneratedAt      string                  `json:"generatedAt"` // ISO 8601
+}
+
+// ============================================================================
+// Import DTOs
+// ============================================================================
+
+// ImportRowAction is what an import does with a single row
+type ImportRowAction string
+
+const (
+	ImportRowActionCreate ImportRowAction = "create"
+	ImportRowActionUpdate ImportRowAction = "update"
+	ImportRowActionSkip   ImportRowAction = "skip"
+	ImportRowActionError  ImportRowAction = "error"
+)
+
+// ImportFieldDTO describes a field that import columns can be mapped to
+type ImportFieldDTO struct {
+	Field    string `json:"field"`
+	Label    string `json:"label"`
+	Required bool   `json:"required"`
+}
+
+// ImportJobDTO represents an uploaded import file
+type ImportJobDTO struct {
+	ID               uuid.UUID         `json:"id"`
+	EntityType       ImportEntityType  `json:"entityType"`
+	FileName         string            `json:"fileName"`
+	Status           ImportJobStatus   `json:"status"`
+	Headers          []string          `json:"headers"`
+	RowCount         int               `json:"rowCount"`
+	SampleRows       [][]string        `json:"sampleRows,omitempty"`       // First rows of the file, for mapping preview
+	SuggestedMapping map[string]string `json:"suggestedMapping,omitempty"` // Column header -> field, guessed from header names
+	Mapping          map[string]string `json:"mapping,omitempty"`          // Column header -> field used for the last dry run
+	Fields           []ImportFieldDTO  `json:"fields,omitempty"`           // Fields available for this entity type
+	Summary          *ImportSummaryDTO `json:"summary,omitempty"`
+	CompanyID        *CompanyID        `json:"companyId,omitempty"`
+	CreatedByName    string            `json:"createdByName,omitempty"`
+	CreatedAt        string            `json:"createdAt"`             // ISO 8601
+	CommittedAt      *string           `json:"committedAt,omitempty"` // ISO 8601
+}
+
+// RunImportRequest configures a dry run of an import job.
+// Either a saved mapping profile or an explicit mapping must be given.
+type RunImportRequest struct {
+	MappingProfileID *uuid.UUID        `json:"mappingProfileId,omitempty"`
+	Mapping          map[string]string `json:"mapping,omitempty"`        // Column header -> field
+	UpdateExisting   bool              `json:"updateExisting"`           // Update matched customers instead of skipping them
+	MatchThreshold   float64           `json:"matchThreshold,omitempty"` // Minimum fuzzy name confidence (0-1), default 0.8
+	CompanyID        CompanyID         `json:"companyId,omitempty"`      // Company for imported offers
+}
+
+// ImportSummaryDTO counts the outcome of an import per action
+type ImportSummaryDTO struct {
+	Total  int `json:"total"`
+	Create int `json:"create"`
+	Update int `json:"update"`
+	Skip   int `json:"skip"`
+	Error  int `json:"error"`
+}
+
+// ImportRowResultDTO is the outcome for a single row
+type ImportRowResultDTO struct {
+	Row             int             `json:"row"` // Spreadsheet row number (header is row 1)
+	Action          ImportRowAction `json:"action"`
+	Name            string          `json:"name,omitempty"`
+	EntityID        *uuid.UUID      `json:"entityId,omitempty"`    // Matched or created record
+	MatchedBy       string          `json:"matchedBy,omitempty"`   // org_number or name
+	MatchedName     string          `json:"matchedName,omitempty"` // Name of the matched customer
+	MatchConfidence float64         `json:"matchConfidence,omitempty"`
+	Messages        []string        `json:"messages,omitempty"`
+}
+
+// ImportReportDTO is the row-by-row result of a dry run or commit
+type ImportReportDTO struct {
+	JobID       uuid.UUID            `json:"jobId"`
+	EntityType  ImportEntityType     `json:"entityType"`
+	DryRun      bool                 `json:"dryRun"`
+	Summary     ImportSummaryDTO     `json:"summary"`
+	Rows        []ImportRowResultDTO `json:"rows"`
+	GeneratedAt string               `json:"generatedAt"` // ISO 8601
+}
+
+// ImportMappingProfileDTO is a saved column mapping
+type ImportMappingProfileDTO struct {
+	ID            uuid.UUID         `json:"id"`
+	Name          string            `json:"name"`
+	EntityType    ImportEntityType  `json:"entityType"`
+	Mapping       map[string]string `json:"mapping"` // Column header -> field
+	CreatedByName string            `json:"createdByName,omitempty"`
+	CreatedAt     string            `json:"createdAt"` // ISO 8601
+	UpdatedAt     string            `json:"updatedAt"` // ISO 8601
+}
+
+// SaveImportMappingProfileRequest creates or updates a mapping profile
+type SaveImportMappingProfileRequest struct {
+	Name       string            `json:"name" validate:"required,max=200"`
+	EntityType ImportEntityType  `json:"entityType" validate:"required"`
+	Mapping    map[string]string `json:"mapping" validate:"required"`
+}
+
+// ============================================================================
+// ERP Customer Reconciliation DTOs
+// ============================================================================
+
+// ERPReconciliationRunDTO is the result of one ERP customer reconciliation run
+type ERPReconciliationRunDTO struct {
+	ID                 uuid.UUID                  `json:"id"`
+	Trigger            ERPReconciliationTrigger   `json:"trigger"`
+	Status             ERPReconciliationRunStatus `json:"status"`
+	StartedAt          string                     `json:"startedAt"`            // ISO 8601
+	FinishedAt         *string                    `json:"finishedAt,omitempty"` // ISO 8601
+	ERPCustomerCount   int                        `json:"erpCustomerCount"`     // Distinct ERP customers compared
+	LocalCustomerCount int                        `json:"localCustomerCount"`
+	MatchedCount       int                        `json:"matchedCount"`
+	CustomersCreated   int                        `json:"customersCreated"` // Missing customers created automatically
+	CreatesQueued      int                        `json:"createsQueued"`    // Missing customers waiting for approval
+	FieldsUpdated      int                        `json:"fieldsUpdated"`    // Drifted fields updated from the ERP
+	DriftsQueued       int                        `json:"driftsQueued"`     // Drifted fields waiting for approval
+	DriftsKept         int                        `json:"driftsKept"`       // Drifted fields where the CRM value was kept
+	Errors             int                        `json:"errors"`
+	ErrorMessage       string                     `json:"errorMessage,omitempty"`
+	CreatedByName      string                     `json:"createdByName,omitempty"`
+}
+
+// ERPReconciliationDecisionDTO is a missing customer or field drift found by reconciliation
+type ERPReconciliationDecisionDTO struct {
+	ID                uuid.UUID                       `json:"id"`
+	RunID             uuid.UUID                       `json:"runId"`
+	Type              ERPReconciliationDecisionType   `json:"type"`
+	Status            ERPReconciliationDecisionStatus `json:"status"`
+	CustomerID        *uuid.UUID                      `json:"customerId,omitempty"` // Set for drift, and for created customers
+	CustomerName      string                          `json:"customerName,omitempty"`
+	Field             string                          `json:"field,omitempty"` // Drifted field
+	CRMValue          string                          `json:"crmValue,omitempty"`
+	ERPValue          string                          `json:"erpValue,omitempty"`
+	ERPFirmanr        int                             `json:"erpFirmanr,omitempty"`
+	ERPCustomerNumber string                          `json:"erpCustomerNumber,omitempty"`
+	ERPOrgNumber      string                          `json:"erpOrgNumber,omitempty"`
+	ERPName           string                          `json:"erpName,omitempty"`
+	ERPAddress        string                          `json:"erpAddress,omitempty"`
+	ERPPostalCode     string                          `json:"erpPostalCode,omitempty"`
+	ERPCity           string                          `json:"erpCity,omitempty"`
+	Error             string                          `json:"error,omitempty"`
+	CreatedAt         string                          `json:"createdAt"`            // ISO 8601
+	ResolvedAt        *string                         `json:"resolvedAt,omitempty"` // ISO 8601
+	ResolvedByName    string                          `json:"resolvedByName,omitempty"`
+}
+
+// ERPReconciliationPolicyDTO is the drift policy for one customer field
+type ERPReconciliationPolicyDTO struct {
+	Field         string                  `json:"field"`
+	Policy        ERPReconciliationPolicy `json:"policy"`
+	UpdatedByName string                  `json:"updatedByName,omitempty"`
+	UpdatedAt     *string                 `json:"updatedAt,omitempty"` // ISO 8601, empty for defaults
+}
+
+// UpdateERPReconciliationPoliciesRequest sets drift policies for one or more fields
+type UpdateERPReconciliationPoliciesRequest struct {
+	// Policies maps field (name, orgNumber, address, postalCode, city) to erp_wins, crm_wins or review
+	Policies map[string]ERPReconciliationPolicy `json:"policies" validate:"required,min=1"`
+}
+
+// ERPReconciliationOverviewDTO summarizes the state of ERP customer reconciliation
+type ERPReconciliationOverviewDTO struct {
+	DataWarehouseEnabled bool                         `json:"dataWarehouseEnabled"`
+	AutoCreate           bool                         `json:"autoCreate"` // Missing customers are created without approval
+	LastRun              *ERPReconciliationRunDTO     `json:"lastRun,omitempty"`
+	PendingCreates       int                          `json:"pendingCreates"`
+	PendingDrifts        int                          `json:"pendingDrifts"`
+	Policies             []ERPReconciliationPolicyDTO `json:"policies"`
+}
+
+// ============================================================================
+// Customer Tier DTOs
+// ============================================================================
+
+// CustomerTierRuleDTO is the tier rule for a company
+type CustomerTierRuleDTO struct {
+	CompanyID         CompanyID          `json:"companyId"` // "all" is the default rule
+	Metric            CustomerTierMetric `json:"metric"`
+	WindowYears       int                `json:"windowYears"` // Rolling window for won offers, 0 = all time
+	SilverThreshold   float64            `json:"silverThreshold"`
+	GoldThreshold     float64            `json:"goldThreshold"`
+	PlatinumThreshold float64            `json:"platinumThreshold"`
+	UpdatedByName     string             `json:"updatedByName,omitempty"`
+	UpdatedAt         *string            `json:"updatedAt,omitempty"` // ISO 8601, empty for the built-in default
+}
+
+// UpdateCustomerTierRuleRequest creates or replaces the tier rule for a company
+type UpdateCustomerTierRuleRequest struct {
+	Metric            CustomerTierMetric `json:"metric" validate:"required,oneof=won_value order_count"`
+	WindowYears       int                `json:"windowYears" validate:"gte=0,lte=20"`
+	SilverThreshold   float64            `json:"silverThreshold" validate:"gte=0"`
+	GoldThreshold     float64            `json:"goldThreshold" validate:"gtefield=SilverThreshold"`
+	PlatinumThreshold float64            `json:"platinumThreshold" validate:"gtefield=GoldThreshold"`
+}
+
+// CustomerTierHistoryDTO is a change of customer tier
+type CustomerTierHistoryDTO struct {
+	ID            uuid.UUID                `json:"id"`
+	CustomerID    uuid.UUID                `json:"customerId"`
+	PreviousTier  CustomerTier             `json:"previousTier"`
+	NewTier       CustomerTier             `json:"newTier"`
+	Source        CustomerTierChangeSource `json:"source"`                // calculated or manual
+	Metric        CustomerTierMetric       `json:"metric,omitempty"`      // Set for calculated changes
+	MetricValue   *float64                 `json:"metricValue,omitempty"` // Won value or order count at the time of the change
+	WindowYears   *int                     `json:"windowYears,omitempty"`
+	RuleCompanyID *CompanyID               `json:"ruleCompanyId,omitempty"` // Company whose rule was applied
+	ChangedByName string                   `json:"changedByName,omitempty"`
+	CreatedAt     string                   `json:"createdAt"` // ISO 8601
+}
+
+// CustomerTierRecalculationDTO summarizes a tier recalculation of all customers
+type CustomerTierRecalculationDTO struct {
+	StartedAt     string `json:"startedAt"`  // ISO 8601
+	FinishedAt    string `json:"finishedAt"` // ISO 8601
+	Checked       int    `json:"checked"`    // Customers evaluated against their rule
+	Upgraded      int    `json:"upgraded"`
+	Downgraded    int    `json:"downgraded"`
+	SkippedLocked int    `json:"skippedLocked"` // Customers with a manually locked tier
+	Errors        int    `json:"errors"`
+}
+
+// ============================================================================
+// Credit Exposure DTOs
+// ============================================================================
+
+// CustomerCreditExposureDTO is the credit exposure of a customer across all companies
+type CustomerCreditExposureDTO struct {
+	CustomerID        uuid.UUID `json:"customerId"`
+	CreditLimit       *float64  `json:"creditLimit,omitempty"`        // Not set means no limit
+	OpenOrderReserve  float64   `json:"openOrderReserve"`             // Order value not yet invoiced, for offers in order phase
+	WeightedSentValue float64   `json:"weightedSentValue"`            // Sent offer values weighted by probability
+	UnpaidAmount      *float64  `json:"unpaidAmount,omitempty"`       // Unpaid invoices from the data warehouse, when enabled
+	Exposure          float64   `json:"exposure"`                     // Sum of the above
+	Available         *float64  `json:"available,omitempty"`          // Credit limit minus exposure
+	UtilizationPct    *float64  `json:"utilizationPercent,omitempty"` // Exposure as a percentage of the credit limit
+	OverLimit         bool      `json:"overLimit"`
+	OpenOrders        int       `json:"openOrders"`
+	SentOffers        int       `json:"sentOffers"`
+	CalculatedAt      string    `json:"calculatedAt"` // ISO 8601
+}
+
+// CreditCheckDTO is the result of checking an order against the customer's credit limit
+type CreditCheckDTO struct {
+	CreditLimit    float64                `json:"creditLimit"`
+	ExposureBefore float64                `json:"exposureBefore"`    // Exposure before accepting the order
+	ExposureAfter  float64                `json:"exposureAfter"`     // Exposure with the full order value instead of its weighted value
+	Exceeded       bool                   `json:"exceeded"`          // The order pushes the customer over the limit
+	Enforcement    CreditLimitEnforcement `json:"enforcement"`       // Company setting: warn or block
+	Message        string                 `json:"message,omitempty"` // Warning shown to the user when exceeded
+}
+
+// ============================================================================
+// Customer Group DTOs
+// ============================================================================
+
+// CustomerGroupMemberDTO is a customer in a customer group
+type CustomerGroupMemberDTO struct {
+	ID               uuid.UUID         `json:"id"`
+	Name             string            `json:"name"`
+	OrgNumber        string            `json:"orgNumber,omitempty"`
+	Status           CustomerStatus    `json:"status"`
+	ParentCustomerID *uuid.UUID        `json:"parentCustomerId,omitempty"`
+	Depth            int               `json:"depth"` // 0 for the requested customer, negative for parent customers
+	Stats            *CustomerStatsDTO `json:"stats,omitempty"`
+}
+
+// CustomerGroupDTO is a customer with its subsidiaries and statistics rolled up over the group
+type CustomerGroupDTO struct {
+	CustomerID uuid.UUID `json:"customerId"`
+	// Ancestors lists the parent customers, nearest first, up to the top of the group
+	Ancestors []CustomerGroupMemberDTO `json:"ancestors"`
+	// Members lists the customer and all its subsidiaries, by depth and name
+	Members     []CustomerGroupMemberDTO `json:"members"`
+	MemberCount int                      `json:"memberCount"`
+	Stats       CustomerStatsDTO         `json:"stats"` // Sum over all members
+}
+
+// ============================================================================
+// Contact Privacy (GDPR) DTOs
+// ============================================================================
+
+// DataSubjectReferenceDTO is a copy of a data subject's personal data stored on another record
+type DataSubjectReferenceDTO struct {
+	EntityType string            `json:"entityType"` // customer, supplier or offer_supplier
+	EntityID   uuid.UUID         `json:"entityId"`
+	EntityName string            `json:"entityName"`
+	Fields     map[string]string `json:"fields"` // Fields holding the data subject's personal data
+}
+
+// DataSubjectExportDTO gathers everything stored about a contact or email address (data subject access request)
+type DataSubjectExportDTO struct {
+	GeneratedAt string                    `json:"generatedAt"` // ISO 8601
+	ContactID   *uuid.UUID                `json:"contactId,omitempty"`
+	Email       string                    `json:"email,omitempty"`
+	Contacts    []ContactDTO              `json:"contacts"`
+	Activities  []ActivityDTO             `json:"activities"` // Activities on the contact or mentioning it
+	References  []DataSubjectReferenceDTO `json:"references"` // Denormalized copies on customers, suppliers and offer suppliers
+	AuditLogs   []AuditLogDTO             `json:"auditLogs"`  // Audit log entries for the contact or containing its data
+}
+
+// ContactAnonymizationDTO is the result of anonymizing a contact
+type ContactAnonymizationDTO struct {
+	Contact           ContactDTO `json:"contact"`
+	ActivitiesUpdated int64      `json:"activitiesUpdated"`
+	ReferencesUpdated int64      `json:"referencesUpdated"` // Customers, suppliers and offer suppliers
+	AuditLogsUpdated  int64      `json:"auditLogsUpdated"`
+}
+
+// ============================================================================
+// Contact Deduplication and vCard DTOs
+// ============================================================================
+
+// ContactDuplicateGroupDTO is a set of contacts that are probably the same person
+type ContactDuplicateGroupDTO struct {
+	MatchedOn           []string     `json:"matchedOn"`           // email, phone, name and/or customer
+	SuggestedSurvivorID uuid.UUID    `json:"suggestedSurvivorId"` // Contact with the most relationships, oldest first
+	Contacts            []ContactDTO `json:"contacts"`
+}
+
+// MergeContactsRequest merges duplicates into the contact in the URL
+type MergeContactsRequest struct {
+	DuplicateIDs []uuid.UUID `json:"duplicateIds" validate:"required,min=1,max=50"`
+}
+
+// ContactMergeResultDTO is the result of merging duplicates into a surviving contact
+type ContactMergeResultDTO struct {
+	Contact            ContactDTO  `json:"contact"`
+	MergedContactIDs   []uuid.UUID `json:"mergedContactIds"`
+	RelationshipsMoved int64       `json:"relationshipsMoved"`
+	ActivitiesMoved    int64       `json:"activitiesMoved"`
+}
+
+// VCardImportCardDTO is the outcome for a single card of a vCard import
+type VCardImportCardDTO struct {
+	Card      int             `json:"card"` // Position of the card in the file, starting at 1
+	Action    ImportRowAction `json:"action"`
+	Name      string          `json:"name,omitempty"`
+	ContactID *uuid.UUID      `json:"contactId,omitempty"` // Created or updated contact
+	Message   string          `json:"message,omitempty"`
+}
+
+// VCardImportResultDTO is the result of a vCard import
+type VCardImportResultDTO struct {
+	Summary ImportSummaryDTO     `json:"summary"`
+	Cards   []VCardImportCardDTO `json:"cards"`
+}
+
+// ============================================================================
+// Buying Committee DTOs
+// ============================================================================
+
+// BuyingCommitteeMemberDTO is a contact linked to an offer
+type BuyingCommitteeMemberDTO struct {
+	RelationshipID uuid.UUID         `json:"relationshipId"`
+	ContactID      uuid.UUID         `json:"contactId"`
+	FullName       string            `json:"fullName"`
+	Title          string            `json:"title,omitempty"`
+	Email          string            `json:"email,omitempty"`
+	Phone          string            `json:"phone,omitempty"`
+	Mobile         string            `json:"mobile,omitempty"`
+	Role           string            `json:"role,omitempty"` // Free text role
+	BuyingRole     *BuyingRole       `json:"buyingRole,omitempty" enums:"decision_maker,economic_buyer,technical_evaluator,influencer,blocker"`
+	Sentiment      *ContactSentiment `json:"sentiment,omitempty" enums:"positive,neutral,negative"`
+	IsPrimary      bool              `json:"isPrimary"`
+}
+
+// BuyingCommitteeDTO maps the contacts of an offer to buying roles
+type BuyingCommitteeDTO struct {
+	OfferID          uuid.UUID                  `json:"offerId"`
+	Members          []BuyingCommitteeMemberDTO `json:"members"`      // In buying role order, contacts without a buying role last
+	MissingRoles     []BuyingRole               `json:"missingRoles"` // Roles nobody covers, except blocker
+	HasDecisionMaker bool                       `json:"hasDecisionMaker"`
+	PositiveCount    int                        `json:"positiveCount"`
+	NeutralCount     int                        `json:"neutralCount"`
+	NegativeCount    int                        `json:"negativeCount"`
+	Warnings         []OfferWarning             `json:"warnings,omitempty" enums:"missing.decisionMaker"`
+}
+
+// ============================================================================
+// Calendar Feed DTOs
+// ============================================================================
+
+// CalendarFeedDTO describes the current user's calendar subscription. The token itself is only
+// returned when it is created.
+type CalendarFeedDTO struct {
+	Active         bool    `json:"active"`
+	CreatedAt      *string `json:"createdAt,omitempty"`
+	LastAccessedAt *string `json:"lastAccessedAt,omitempty"` // When a calendar client last fetched the feed
+}
+
+// CalendarFeedTokenDTO is a newly created calendar subscription token
+type CalendarFeedTokenDTO struct {
+	Token     string `json:"token"`
+	URL       string `json:"url"` // Subscription URL to add to Outlook, Google Calendar or Apple Calendar
+	CreatedAt string `json:"createdAt"`
+}
+
+// ============================================================================
+// Email Ingestion DTOs
+// ============================================================================
+
+// IngestedEmailDTO is an email ingested from an upload or the drop mailbox
+type IngestedEmailDTO struct {
+	ID              uuid.UUID            `json:"id"`
+	MessageID       string               `json:"messageId"`
+	Subject         string               `json:"subject"`
+	FromAddress     string               `json:"fromAddress,omitempty"`
+	SentAt          *string              `json:"sentAt,omitempty"`
+	Source          EmailSource          `json:"source" enums:"upload,mailbox"`
+	Status          EmailIngestionStatus `json:"status" enums:"matched,unmatched,duplicate"`
+	MatchedBy       EmailMatchMethod     `json:"matchedBy,omitempty" enums:"offer_number,contact,supplier_contact"`
+	TargetType      ActivityTargetType   `json:"targetType,omitempty"`
+	TargetID        *uuid.UUID           `json:"targetId,omitempty"`
+	TargetName      string               `json:"targetName,omitempty"`
+	ActivityID      *uuid.UUID           `json:"activityId,omitempty"`
+	AttachmentCount int                  `json:"attachmentCount"`
+	IngestedByName  string               `json:"ingestedByName,omitempty"`
+	CreatedAt       string               `json:"createdAt"`
+}
+
+// EmailIngestionResultDTO is the outcome of ingesting an .eml file
+type EmailIngestionResultDTO struct {
+	Email IngestedEmailDTO `json:"email"`
+	Files []FileDTO        `json:"files,omitempty"` // Attachments stored on the target
+}
+
+// ============================================================================
+// Deal Pipeline DTOs
+// ============================================================================
+
+// DealPipelineStageDTO is a stage in a deal pipeline
+type DealPipelineStageDTO struct {
+	Key                DealStage     `json:"key"`
+	Name               string        `json:"name"`
+	SortOrder          int           `json:"sortOrder"`
+	Kind               DealStageKind `json:"kind" enums:"open,won,lost"`
+	Probability        int           `json:"probability"`
+	RequiredFields     []string      `json:"requiredFields"`
+	AllowedTransitions []DealStage   `json:"allowedTransitions"`
+	StaleAfterDays     *int          `json:"staleAfterDays,omitempty"`
+}
+
+// DealPipelineDTO is a company's deal pipeline with its stages in order
+type DealPipelineDTO struct {
+	ID          uuid.UUID              `json:"id"`
+	CompanyID   CompanyID              `json:"companyId"`
+	Name        string                 `json:"name"`
+	Description string                 `json:"description,omitempty"`
+	IsDefault   bool                   `json:"isDefault"`
+	Stages      []DealPipelineStageDTO `json:"stages"`
+	CreatedAt   string                 `json:"createdAt"`
+	UpdatedAt   string                 `json:"updatedAt"`
+}
+
+// DealPipelineStageInput defines a stage when creating or updating a pipeline. Stages are ordered as
+// given. The keys won and lost are the closing stages and are added when omitted.
+type DealPipelineStageInput struct {
+	Key            DealStage `json:"key" validate:"required,max=50" example:"befaring"`
+	Name           string    `json:"name" validate:"required,max=100" example:"Befaring"`
+	Probability    int       `json:"probability" validate:"min=0,max=100"`
+	RequiredFields []string  `json:"requiredFields,omitempty" enums:"value,expectedCloseDate,description,source,notes,offerId"` // Deal fields that must be set to enter the stage
+	// AllowedTransitions are the stage keys a deal can move to from this stage. When omitted, deals can
+	// move to the next and previous stage and to lost; from the last open stage also to won.
+	AllowedTransitions []DealStage `json:"allowedTransitions,omitempty"`
+	// StaleAfterDays is how many days an open deal can sit in the stage without activity before it is stale
+	StaleAfterDays *int `json:"staleAfterDays,omitempty" validate:"omitempty,min=1" example:"30"`
+}
+
+// CreateDealPipelineRequest creates a deal pipeline for a company
+type CreateDealPipelineRequest struct {
+	CompanyID   CompanyID                `json:"companyId" validate:"required"`
+	Name        string                   `json:"name" validate:"required,max=100"`
+	Description string                   `json:"description,omitempty"`
+	IsDefault   bool                     `json:"isDefault"` // Makes this the default pipeline for new deals in the company
+	Stages      []DealPipelineStageInput `json:"stages" validate:"required,min=1,dive"`
+}
+
+// UpdateDealPipelineRequest replaces the name and stages of a deal pipeline
+type UpdateDealPipelineRequest struct {
+	Name        string                   `json:"name" validate:"required,max=100"`
+	Description string                   `json:"description,omitempty"`
+	IsDefault   bool                     `json:"isDefault"`
+	Stages      []DealPipelineStageInput `json:"stages" validate:"required,min=1,dive"`
+}
+
+// ============================================================================
+// Sales Target DTOs
+// ============================================================================
+
+// SalesTargetDTO is a sales budget for a company or user and period
+type SalesTargetDTO struct {
+	ID            uuid.UUID         `json:"id"`
+	CompanyID     CompanyID         `json:"companyId"`
+	UserID        *string           `json:"userId,omitempty"` // Empty for a company-wide target
+	UserName      string            `json:"userName,omitempty"`
+	PeriodType    SalesTargetPeriod `json:"periodType" enums:"month,quarter,year"`
+	PeriodStart   string            `json:"periodStart"` // YYYY-MM-DD, first day of the period
+	PeriodEnd     string            `json:"periodEnd"`   // YYYY-MM-DD, last day of the period
+	Metric        SalesTargetMetric `json:"metric" enums:"won_value,order_intake,margin"`
+	TargetValue   float64           `json:"targetValue"`
+	Notes         string            `json:"notes,omitempty"`
+	CreatedByName string            `json:"createdByName,omitempty"`
+	CreatedAt     string            `json:"createdAt"`
+	UpdatedAt     string            `json:"updatedAt"`
+}
+
+// CreateSalesTargetRequest creates a sales target. Without userId the target is company-wide.
+type CreateSalesTargetRequest struct {
+	CompanyID   CompanyID         `json:"companyId" validate:"required"`
+	UserID      *string           `json:"userId,omitempty" validate:"omitempty,max=100"`
+	UserName    string            `json:"userName,omitempty" validate:"max=200"`
+	PeriodType  SalesTargetPeriod `json:"periodType" validate:"required,oneof=month quarter year"`
+	PeriodStart string            `json:"periodStart" validate:"required" example:"2026-01-01"` // YYYY-MM-DD, any day in the period
+	Metric      SalesTargetMetric `json:"metric" validate:"required,oneof=won_value order_intake margin"`
+	TargetValue float64           `json:"targetValue" validate:"gt=0" example:"5000000"`
+	Notes       string            `json:"notes,omitempty"`
+}
+
+// UpdateSalesTargetRequest changes the target value and notes of a sales target
+type UpdateSalesTargetRequest struct {
+	TargetValue float64 `json:"targetValue" validate:"gt=0" example:"5000000"`
+	Notes       string  `json:"notes,omitempty"`
+}
+
+// SalesTargetAttainmentDTO compares a sales target with what has been won in its period so far
+type SalesTargetAttainmentDTO struct {
+	Target SalesTargetDTO `json:"target"`
+	// Actual is the metric achieved in the period so far
+	Actual float64 `json:"actual"`
+	// AttainmentPercent is actual as a percentage of the target
+	AttainmentPercent float64 `json:"attainmentPercent"`
+	// ElapsedPercent is how much of the period has passed
+	ElapsedPercent float64 `json:"elapsedPercent"`
+	// ExpectedToDate is the part of the target that should be reached by now at an even pace
+	ExpectedToDate float64 `json:"expectedToDate"`
+	// PacePercent is actual as a percentage of ExpectedToDate; 100 means on pace
+	PacePercent float64 `json:"pacePercent"`
+	// ProjectedValue is actual extrapolated to the end of the period at the current pace
+	ProjectedValue float64 `json:"projectedValue"`
+	// Gap is what remains to reach the target, 0 when it is reached
+	Gap float64 `json:"gap"`
+	// WeightedPipeline is the weighted value of open deals expected to close before the period ends
+	WeightedPipeline float64 `json:"weightedPipeline"`
+	// ForecastCoverage is WeightedPipeline as a percentage of Gap, nil when the gap is closed
+	ForecastCoverage *float64 `json:"forecastCoverage,omitempty"`
+	// RemainingDays is the number of days left in the period
+	RemainingDays int `json:"remainingDays"`
+}
+
+// ============================================================================
+// Calibrated Forecast DTOs
+// ============================================================================
+
+// CalibratedForecastDTO is a pipeline forecast weighted by historical win rates instead of entered probabilities
+type CalibratedForecastDTO struct {
+	GeneratedAt    string `json:"generatedAt"`
+	LookbackMonths int    `json:"lookbackMonths"`
+	// ConfidenceLevel is the percentage of outcomes expected to fall between the low and high values
+	ConfidenceLevel int `json:"confidenceLevel"`
+	// SampleSize is the number of closed deals and offers the win rates were learned from
+	SampleSize    int                           `json:"sampleSize"`
+	Periods       []CalibratedForecastPeriodDTO `json:"periods"`
+	Total         CalibratedForecastPeriodDTO   `json:"total"`
+	Items         []CalibratedForecastItemDTO   `json:"items"`
+	StageWinRates []StageWinRateDTO             `json:"stageWinRates"`
+	// Calibration compares entered probabilities with actual outcomes per salesperson
+	Calibration        []SalespersonCalibrationDTO `json:"calibration"`
+	OverallCalibration SalespersonCalibrationDTO   `json:"overallCalibration"`
+}
+
+// CalibratedForecastPeriodDTO is the forecast for deals and offers expected to close in one period
+type CalibratedForecastPeriodDTO struct {
+	PeriodStart string  `json:"periodStart"` // YYYY-MM-DD
+	PeriodEnd   string  `json:"periodEnd"`   // YYYY-MM-DD
+	ItemCount   int     `json:"itemCount"`
+	TotalValue  float64 `json:"totalValue"`
+	// EnteredWeightedValue weights each item by the probability entered by the salesperson
+	EnteredWeightedValue float64 `json:"enteredWeightedValue"`
+	// ExpectedValue weights each item by its calibrated win probability
+	ExpectedValue float64 `json:"expectedValue"`
+	LowValue      float64 `json:"lowValue"`  // Lower bound of the confidence band
+	HighValue     float64 `json:"highValue"` // Upper bound of the confidence band
+}
+
+// CalibratedForecastItemDTO is an open deal or offer with its entered and calibrated probability
+type CalibratedForecastItemDTO struct {
+	Type                  string    `json:"type" enums:"deal,offer"`
+	ID                    uuid.UUID `json:"id"`
+	Title                 string    `json:"title"`
+	Stage                 string    `json:"stage"` // Deal stage or offer phase
+	CompanyID             CompanyID `json:"companyId"`
+	OwnerID               string    `json:"ownerId,omitempty"`
+	OwnerName             string    `json:"ownerName,omitempty"`
+	Value                 float64   `json:"value"`
+	ExpectedDate          string    `json:"expectedDate"` // YYYY-MM-DD
+	EnteredProbability    int       `json:"enteredProbability"`
+	CalibratedProbability float64   `json:"calibratedProbability"`
+	// StageSampleSize is the number of closed deals or offers behind the stage's win rate
+	StageSampleSize int `json:"stageSampleSize"`
+}
+
+// StageWinRateDTO is the historical win rate of deals that reached a stage, or offers that reached a phase
+type StageWinRateDTO struct {
+	Type    string  `json:"type" enums:"deal,offer"`
+	Stage   string  `json:"stage"`
+	Closed  int     `json:"closed"`
+	Won     int     `json:"won"`
+	WinRate float64 `json:"winRate"` // Percentage
+}
+
+// SalespersonCalibrationDTO compares the probabilities a salesperson entered with what was actually won
+type SalespersonCalibrationDTO struct {
+	OwnerID               string  `json:"ownerId,omitempty"`
+	OwnerName             string  `json:"ownerName,omitempty"`
+	Closed                int     `json:"closed"`
+	Won                   int     `json:"won"`
+	AvgEnteredProbability float64 `json:"avgEnteredProbability"`
+	ActualWinRate         float64 `json:"actualWinRate"`
+	// Bias is the average entered probability minus the actual win rate; positive means optimistic
+	Bias float64 `json:"bias"`
+	// BrierScore is the mean squared error of the entered probabilities (0 is perfect, 0.25 is a coin flip)
+	BrierScore float64                `json:"brierScore"`
+	Buckets    []CalibrationBucketDTO `json:"buckets"`
+}
+
+// CalibrationBucketDTO groups closed deals and offers by entered probability
+type CalibrationBucketDTO struct {
+	Range                 string  `json:"range" example:"40-59"`
+	Closed                int     `json:"closed"`
+	AvgEnteredProbability float64 `json:"avgEnteredProbability"`
+	ActualWinRate         float64 `json:"actualWinRate"`
+}
+
+// ============================================================================
+// Inquiry Intake DTOs
+// ============================================================================
+
+// InquiryIntakeSiteDTO is a company website allowed to submit inquiries
+type InquiryIntakeSiteDTO struct {
+	ID               uuid.UUID `json:"id"`
+	CompanyID        CompanyID `json:"companyId"`
+	Name             string    `json:"name"`
+	SiteKey          string    `json:"siteKey"` // Public key embedded in the website form
+	AllowedOrigins   []string  `json:"allowedOrigins"`
+	HoneypotField    string    `json:"honeypotField,omitempty"`
+	ProofOfWorkBits  int       `json:"proofOfWorkBits"`
+	SendConfirmation bool      `json:"sendConfirmation"`
+	Enabled          bool      `json:"enabled"`
+	CreatedByName    string    `json:"createdByName,omitempty"`
+	CreatedAt        string    `json:"createdAt"`
+	UpdatedAt        string    `json:"updatedAt"`
+}
+
+// CreateInquiryIntakeSiteRequest registers a website form for a company
+type CreateInquiryIntakeSiteRequest struct {
+	CompanyID CompanyID `json:"companyId" validate:"required"`
+	Name      string    `json:"name" validate:"required,max=200" example:"straye.no kontaktskjema"`
+	// AllowedOrigins limits the origins the form may be posted from; empty allows any origin
+	AllowedOrigins []string `json:"allowedOrigins,omitempty" validate:"omitempty,dive,url" example:"https://straye.no"`
+	// HoneypotField is a hidden form field that must stay empty; empty disables the check
+	HoneypotField string `json:"honeypotField,omitempty" validate:"max=100" example:"website"`
+	// ProofOfWorkBits is the number of leading zero bits required in the proof-of-work hash; 0 disables it
+	ProofOfWorkBits  int   `json:"proofOfWorkBits" validate:"min=0,max=24" example:"16"`
+	SendConfirmation *bool `json:"sendConfirmation,omitempty"` // Default true
+}
+
+// UpdateInquiryIntakeSiteRequest changes the settings of a website form. The site key does not change.
+type UpdateInquiryIntakeSiteRequest struct {
+	Name             string   `json:"name" validate:"required,max=200"`
+	AllowedOrigins   []string `json:"allowedOrigins,omitempty" validate:"omitempty,dive,url"`
+	HoneypotField    string   `json:"honeypotField,omitempty" validate:"max=100"`
+	ProofOfWorkBits  int      `json:"proofOfWorkBits" validate:"min=0,max=24"`
+	SendConfirmation bool     `json:"sendConfirmation"`
+	Enabled          bool     `json:"enabled"`
+}
+
+// PublicInquiryRequest is an inquiry submitted through a website form.
+// Forms with attachments post multipart/form-data with the same field names and files in "attachments".
+type PublicInquiryRequest struct {
+	Name        string `json:"name" validate:"required,max=200"`
+	Email       string `json:"email" validate:"required,email,max=255"`
+	Phone       string `json:"phone,omitempty" validate:"max=50"`
+	CompanyName string `json:"companyName,omitempty" validate:"max=200"`
+	OrgNumber   string `json:"orgNumber,omitempty" validate:"max=20"`
+	Title       string `json:"title,omitempty" validate:"max=200"`
+	Message     string `json:"message" validate:"required,max=10000"`
+	// Challenge and Nonce are the solved proof-of-work challenge, required when the site has proof of work enabled
+	Challenge string `json:"challenge,omitempty"`
+	Nonce     string `json:"nonce,omitempty"`
+}
+
+// PublicInquiryResponseDTO acknowledges a website inquiry
+type PublicInquiryResponseDTO struct {
+	Reference string `json:"reference"` // Submission reference to quote in follow-up questions
+	Message   string `json:"message"`
+}
+
+// InquiryChallengeDTO is a proof-of-work challenge for a website form. The client finds a nonce such that
+// SHA-256(challenge + ":" + nonce) starts with at least bits zero bits and submits both with the inquiry.
+type InquiryChallengeDTO struct {
+	Challenge string `json:"challenge"`
+	Bits      int    `json:"bits"`
+	ExpiresAt string `json:"expiresAt"`
+}
+
+// InquirySubmissionDTO is an inquiry received through a website form
+type InquirySubmissionDTO struct {
+	ID                 uuid.UUID  `json:"id"`
+	SiteID             uuid.UUID  `json:"siteId"`
+	CompanyID          CompanyID  `json:"companyId"`
+	OfferID            *uuid.UUID `json:"offerId,omitempty"`
+	CustomerID         *uuid.UUID `json:"customerId,omitempty"`
+	MatchConfidence    *float64   `json:"matchConfidence,omitempty"`
+	Name               string     `json:"name"`
+	Email              string     `json:"email"`
+	Phone              string     `json:"phone,omitempty"`
+	CompanyName        string     `json:"companyName,omitempty"`
+	OrgNumber          string     `json:"orgNumber,omitempty"`
+	Message            string     `json:"message,omitempty"`
+	AttachmentCount    int        `json:"attachmentCount"`
+	Spam               bool       `json:"spam"`
+	IPAddress          string     `json:"ipAddress,omitempty"`
+	ConfirmationSentAt *string    `json:"confirmationSentAt,omitempty"`
+	CreatedAt          string     `json:"createdAt"`
+}
+
+// InquiryAssignmentRuleDTO is a rule that assigns new inquiries of a company
+type InquiryAssignmentRuleDTO struct {
+	ID                 uuid.UUID                 `json:"id"`
+	CompanyID          CompanyID                 `json:"companyId"`
+	Name               string                    `json:"name"`
+	RuleType           InquiryAssignmentRuleType `json:"ruleType"`
+	Priority           int                       `json:"priority"`
+	Enabled            bool                      `json:"enabled"`
+	Industries         []string                  `json:"industries"`
+	PostalCodePrefixes []string                  `json:"postalCodePrefixes"`
+	Counties           []string                  `json:"counties"`
+	MinValue           *float64                  `json:"minValue,omitempty"`
+	MaxValue           *float64                  `json:"maxValue,omitempty"`
+	AssigneeIDs        []string                  `json:"assigneeIds"`
+	CreatedByName      string                    `json:"createdByName,omitempty"`
+	CreatedAt          string                    `json:"createdAt"`
+	UpdatedAt          string                    `json:"updatedAt"`
+}
+
+// CreateInquiryAssignmentRuleRequest creates an inquiry assignment rule for a company.
+// industry rules need industries, postal_region rules postal code prefixes or counties, and value rules
+// a minimum or maximum value; round_robin rules match every inquiry.
+type CreateInquiryAssignmentRuleRequest struct {
+	CompanyID CompanyID                 `json:"companyId" validate:"required"`
+	Name      string                    `json:"name" validate:"required,max=200" example:"Vestlandet"`
+	RuleType  InquiryAssignmentRuleType `json:"ruleType" validate:"required" example:"postal_region"`
+	// Priority orders the rules of a company; lower runs first (default 100)
+	Priority           *int     `json:"priority,omitempty" validate:"omitempty,min=0,max=10000"`
+	Industries         []string `json:"industries,omitempty" example:"construction"`
+	PostalCodePrefixes []string `json:"postalCodePrefixes,omitempty" validate:"omitempty,dive,numeric,min=1,max=4" example:"50"`
+	Counties           []string `json:"counties,omitempty" example:"Vestland"`
+	MinValue           *float64 `json:"minValue,omitempty" validate:"omitempty,gte=0"`
+	MaxValue           *float64 `json:"maxValue,omitempty" validate:"omitempty,gte=0"`
+	// AssigneeIDs are the users the rule assigns in turn
+	AssigneeIDs []string `json:"assigneeIds" validate:"required,min=1,dive,required,max=100"`
+}
+
+// UpdateInquiryAssignmentRuleRequest replaces the conditions and assignees of a rule. The company cannot change.
+type UpdateInquiryAssignmentRuleRequest struct {
+	Name               string                    `json:"name" validate:"required,max=200"`
+	RuleType           InquiryAssignmentRuleType `json:"ruleType" validate:"required"`
+	Priority           int                       `json:"priority" validate:"min=0,max=10000"`
+	Enabled            bool                      `json:"enabled"`
+	Industries         []string                  `json:"industries,omitempty"`
+	PostalCodePrefixes []string                  `json:"postalCodePrefixes,omitempty" validate:"omitempty,dive,numeric,min=1,max=4"`
+	Counties           []string                  `json:"counties,omitempty"`
+	MinValue           *float64                  `json:"minValue,omitempty" validate:"omitempty,gte=0"`
+	MaxValue           *float64                  `json:"maxValue,omitempty" validate:"omitempty,gte=0"`
+	AssigneeIDs        []string                  `json:"assigneeIds" validate:"required,min=1,dive,required,max=100"`
+}
+
+// ============================================================================
+// Inquiry SLA DTOs
+// ============================================================================
+
+// InquirySLAReportDTO is the response-time SLA compliance of inquiries received in a period.
+// Response times are measured in business hours of the inquiry's company.
+type InquirySLAReportDTO struct {
+	From        string                 `json:"from"` // YYYY-MM-DD
+	To          string                 `json:"to"`   // YYYY-MM-DD, inclusive
+	GeneratedAt string                 `json:"generatedAt"`
+	Overall     InquirySLAStatsDTO     `json:"overall"`
+	Companies   []InquirySLACompanyDTO `json:"companies"`
+	Users       []InquirySLAUserDTO    `json:"users"`
+	// Overdue lists unanswered inquiries past their deadline, most overdue first
+	Overdue []InquirySLAItemDTO `json:"overdue"`
+}
+
+// InquirySLAStatsDTO counts inquiries by SLA outcome
+type InquirySLAStatsDTO struct {
+	Total    int `json:"total"`
+	Met      int `json:"met"`      // Answered before the deadline
+	Breached int `json:"breached"` // Answered after the deadline, or still unanswered past it
+	Open     int `json:"open"`     // Unanswered, deadline not reached
+	Overdue  int `json:"overdue"`  // Unanswered past the deadline (included in breached)
+	// CompliancePercent is met as a percentage of met and breached; nil when no deadline has been decided
+	CompliancePercent *float64 `json:"compliancePercent,omitempty"`
+	// AvgResponseHours and MedianResponseHours are business hours from receipt to response, answered inquiries only
+	AvgResponseHours    *float64 `json:"avgResponseHours,omitempty"`
+	MedianResponseHours *float64 `json:"medianResponseHours,omitempty"`
+}
+
+// InquirySLACompanyDTO is the SLA compliance of one company
+type InquirySLACompanyDTO struct {
+	CompanyID CompanyID `json:"companyId"`
+	SLAHours  int       `json:"slaHours"` // Current SLA of the company in business hours
+	InquirySLAStatsDTO
+}
+
+// InquirySLAUserDTO is the SLA compliance of inquiries a user is responsible for
+type InquirySLAUserDTO struct {
+	UserID   string `json:"userId,omitempty"` // Empty for unassigned inquiries
+	UserName string `json:"userName,omitempty"`
+	InquirySLAStatsDTO
+}
+
+// InquirySLAItemDTO is an inquiry with its SLA clock
+type InquirySLAItemDTO struct {
+	ID                  uuid.UUID `json:"id"`
+	Title               string    `json:"title"`
+	CompanyID           CompanyID `json:"companyId"`
+	ResponsibleUserID   string    `json:"responsibleUserId,omitempty"`
+	ResponsibleUserName string    `json:"responsibleUserName,omitempty"`
+	ReceivedAt          string    `json:"receivedAt"`
+	DueAt               string    `json:"dueAt"`
+	// OverdueHours is business hours past the deadline
+	OverdueHours float64 `json:"overdueHours"`
+}
+
+// ============================================================================
+// Project Schedule DTOs
+// ============================================================================
+
+// ProjectTaskDTO is a task or milestone in a project's schedule
+type ProjectTaskDTO struct {
+	ID              uuid.UUID       `json:"id"`
+	ProjectID       uuid.UUID       `json:"projectId"`
+	Kind            ProjectTaskKind `json:"kind"`
+	Name            string          `json:"name"`
+	Description     string          `json:"description,omitempty"`
+	MilestoneID     *uuid.UUID      `json:"milestoneId,omitempty"`
+	OfferID         *uuid.UUID      `json:"offerId,omitempty"`
+	StartDate       string          `json:"startDate"` // YYYY-MM-DD
+	EndDate         string          `json:"endDate"`   // YYYY-MM-DD
+	AssigneeID      string          `json:"assigneeId,omitempty"`
+	AssigneeName    string          `json:"assigneeName,omitempty"`
+	PercentComplete int             `json:"percentComplete"`
+	SortOrder       int             `json:"sortOrder"`
+	DependsOn       []uuid.UUID     `json:"dependsOn"`
+	CreatedByName   string          `json:"createdByName,omitempty"`
+	CreatedAt       string          `json:"createdAt"`
+	UpdatedAt       string          `json:"updatedAt"`
+}
+
+// CreateProjectTaskRequest adds a task or milestone to a project's schedule
+type CreateProjectTaskRequest struct {
+	Kind        ProjectTaskKind `json:"kind,omitempty" validate:"omitempty,oneof=task milestone"` // Default task
+	Name        string          `json:"name" validate:"required,max=200" example:"Fundamentering"`
+	Description string          `json:"description,omitempty" validate:"max=10000"`
+	// MilestoneID is the milestone the task is part of; the milestone is reached when its tasks are finished
+	MilestoneID *uuid.UUID `json:"milestoneId,omitempty"`
+	// OfferID is the offer in the project whose end date a milestone must be reached by; default the project's won offer
+	OfferID   *uuid.UUID `json:"offerId,omitempty"`
+	StartDate string     `json:"startDate" validate:"required" example:"2026-05-04"` // YYYY-MM-DD
+	// EndDate is the last day of a task (YYYY-MM-DD); milestones only have a start date
+	EndDate         string `json:"endDate,omitempty" example:"2026-05-15"`
+	AssigneeID      string `json:"assigneeId,omitempty" validate:"max=100"`
+	PercentComplete int    `json:"percentComplete" validate:"min=0,max=100"`
+	SortOrder       int    `json:"sortOrder"`
+	// DependsOn are tasks and milestones that must be finished before this one starts (finish-to-start)
+	DependsOn []uuid.UUID `json:"dependsOn,omitempty"`
+}
+
+// UpdateProjectTaskRequest replaces the dates, progress and dependencies of a task or milestone. The kind cannot change.
+type UpdateProjectTaskRequest struct {
+	Name            string      `json:"name" validate:"required,max=200"`
+	Description     string      `json:"description,omitempty" validate:"max=10000"`
+	MilestoneID     *uuid.UUID  `json:"milestoneId,omitempty"`
+	OfferID         *uuid.UUID  `json:"offerId,omitempty"`
+	StartDate       string      `json:"startDate" validate:"required"`
+	EndDate         string      `json:"endDate,omitempty"`
+	AssigneeID      string      `json:"assigneeId,omitempty" validate:"max=100"`
+	PercentComplete int         `json:"percentComplete" validate:"min=0,max=100"`
+	SortOrder       int         `json:"sortOrder"`
+	DependsOn       []uuid.UUID `json:"dependsOn,omitempty"`
+}
+
+// ProjectScheduleDTO is a project's tasks and milestones in a shape Gantt components can render directly,
+// with the dates they can be reached by given their dependencies, the critical path and slip warnings
+type ProjectScheduleDTO struct {
+	ProjectID   uuid.UUID `json:"projectId"`
+	ProjectName string    `json:"projectName"`
+	// StartDate and EndDate span the schedule; EndDate is the forecast finish given the dependencies
+	StartDate string `json:"startDate,omitempty"` // YYYY-MM-DD
+	EndDate   string `json:"endDate,omitempty"`   // YYYY-MM-DD
+	// OfferEndDate is the planned end date of the project's won offer
+	OfferEndDate *string                     `json:"offerEndDate,omitempty"` // YYYY-MM-DD
+	Items        []ProjectScheduleItemDTO    `json:"items"`
+	Links        []ProjectScheduleLinkDTO    `json:"links"`
+	CriticalPath []uuid.UUID                 `json:"criticalPath"` // Items without slack, in schedule order
+	Warnings     []ProjectScheduleWarningDTO `json:"warnings"`
+}
+
+// ProjectScheduleItemDTO is one bar (task) or diamond (milestone) in the Gantt chart
+type ProjectScheduleItemDTO struct {
+	ID           uuid.UUID       `json:"id"`
+	Type         ProjectTaskKind `json:"type"`
+	Name         string          `json:"name"`
+	Start        string          `json:"start"`            // Planned start, YYYY-MM-DD
+	End          string          `json:"end"`              // Planned last day, YYYY-MM-DD
+	Progress     int             `json:"progress"`         // Percent complete
+	Parent       *uuid.UUID      `json:"parent,omitempty"` // Milestone the task is part of
+	Dependencies []uuid.UUID     `json:"dependencies"`     // Items that must finish first
+	AssigneeID   string          `json:"assigneeId,omitempty"`
+	AssigneeName string          `json:"assigneeName,omitempty"`
+	OfferID      *uuid.UUID      `json:"offerId,omitempty"`
+	SortOrder    int             `json:"sortOrder"`
+	// EarliestStart and EarliestFinish are when the item can be done given its dependencies (forecast)
+	EarliestStart  string `json:"earliestStart"`
+	EarliestFinish string `json:"earliestFinish"`
+	// LatestStart and LatestFinish are the latest dates that do not delay the schedule's finish
+	LatestStart  string `json:"latestStart"`
+	LatestFinish string `json:"latestFinish"`
+	SlackDays    int    `json:"slackDays"`
+	Critical     bool   `json:"critical"`
+	// Delayed is true when the dependencies push the item past its planned end
+	Delayed bool `json:"delayed"`
+}
+
+// ProjectScheduleLinkDTO is a dependency arrow between two items
+type ProjectScheduleLinkDTO struct {
+	Source uuid.UUID `json:"source"` // Must finish first
+	Target uuid.UUID `json:"target"`
+	Type   string    `json:"type" enums:"finish_to_start"`
+}
+
+// ProjectScheduleWarningDTO flags a milestone that will not be reached by the end date of its offer
+type ProjectScheduleWarningDTO struct {
+	Code         string    `json:"code" enums:"milestone.after.offer.end_date"`
+	TaskID       uuid.UUID `json:"taskId"`
+	TaskName     string    `json:"taskName"`
+	OfferID      uuid.UUID `json:"offerId"`
+	OfferEndDate string    `json:"offerEndDate"` // YYYY-MM-DD
+	ForecastDate string    `json:"forecastDate"` // YYYY-MM-DD
+	DaysLate     int       `json:"daysLate"`
+	Message      string    `json:"message"`
+}
+
+// ============================================================================
+// Project Budget Roll-up DTOs
+// ============================================================================
+
+// ProjectBudgetRollupDTO compares what was sold (the budget items of the project's won offers) with the
+// project's working budget and the actual costs from the data warehouse, per budget item
+type ProjectBudgetRollupDTO struct {
+	ProjectID   uuid.UUID `json:"projectId"`
+	ProjectName string    `json:"projectName"`
+	// BudgetSource is project when the project has its own budget items, otherwise offer: what was sold is the budget
+	BudgetSource BudgetParentType             `json:"budgetSource" enums:"offer,project"`
+	Offers       []ProjectBudgetOfferDTO      `json:"offers"`
+	Lines        []ProjectBudgetRollupLineDTO `json:"lines"`
+	Totals       ProjectBudgetRollupTotalsDTO `json:"totals"`
+}
+
+// ProjectBudgetOfferDTO is a won offer whose budget and actuals are part of the roll-up
+type ProjectBudgetOfferDTO struct {
+	ID             uuid.UUID  `json:"id"`
+	Title          string     `json:"title"`
+	OfferNumber    string     `json:"offerNumber,omitempty"`
+	Phase          OfferPhase `json:"phase"`
+	Value          float64    `json:"value"`
+	DWLastSyncedAt *string    `json:"dwLastSyncedAt,omitempty"`
+}
+
+// ProjectBudgetRollupLineDTO is one budget item: a sold item with the project items rebudgeting it,
+// a project item added after the sale, or data warehouse costs no budget item covers
+type ProjectBudgetRollupLineDTO struct {
+	Name          string             `json:"name"`
+	CostCategory  BudgetCostCategory `json:"costCategory,omitempty"`
+	OfferID       *uuid.UUID         `json:"offerId,omitempty"`    // Won offer of the sold item
+	SoldItemID    *uuid.UUID         `json:"soldItemId,omitempty"` // Budget item of the won offer
+	BudgetItemIDs []uuid.UUID        `json:"budgetItemIds"`        // Project budget items
+	SoldCost      float64            `json:"soldCost"`
+	SoldRevenue   float64            `json:"soldRevenue"`
+	BudgetCost    float64            `json:"budgetCost"`
+	BudgetRevenue float64            `json:"budgetRevenue"`
+	// ActualCost is the line's share of the data warehouse costs in its cost category, split by budget cost
+	ActualCost    float64  `json:"actualCost"`
+	RemainingCost float64  `json:"remainingCost"`          // budgetCost - actualCost
+	Rebudgeted    float64  `json:"rebudgeted"`             // budgetCost - soldCost
+	PercentSpent  *float64 `json:"percentSpent,omitempty"` // actualCost / budgetCost * 100
+	// Unallocated marks data warehouse costs in a cost category no budget item is tracked in
+	Unallocated bool `json:"unallocated"`
+}
+
+// ProjectBudgetRollupTotalsDTO sums the roll-up lines with the income from the data warehouse
+type ProjectBudgetRollupTotalsDTO struct {
+	SoldCost      float64  `json:"soldCost"`
+	SoldRevenue   float64  `json:"soldRevenue"`
+	BudgetCost    float64  `json:"budgetCost"`
+	BudgetRevenue float64  `json:"budgetRevenue"`
+	ActualCost    float64  `json:"actualCost"`
+	ActualIncome  float64  `json:"actualIncome"`
+	RemainingCost float64  `json:"remainingCost"`
+	Rebudgeted    float64  `json:"rebudgeted"`
+	PercentSpent  *float64 `json:"percentSpent,omitempty"`
+}
+
+// ============================================================================
+// Resource Capacity DTOs
+// ============================================================================
+
+// ResourceAllocationDTO books a user on an offer or project for a date range
+type ResourceAllocationDTO struct {
+	ID          uuid.UUID  `json:"id"`
+	CompanyID   CompanyID  `json:"companyId"`
+	UserID      string     `json:"userId"`
+	UserName    string     `json:"userName,omitempty"`
+	OfferID     *uuid.UUID `json:"offerId,omitempty"`
+	OfferTitle  string     `json:"offerTitle,omitempty"`
+	ProjectID   *uuid.UUID `json:"projectId,omitempty"`
+	ProjectName string     `json:"projectName,omitempty"`
+	StartDate   string     `json:"startDate"` // YYYY-MM-DD
+	EndDate     string     `json:"endDate"`   // YYYY-MM-DD, last day of the allocation
+	// HoursPerWeek and Percent are mutually exclusive; exactly one is set
+	HoursPerWeek  *float64 `json:"hoursPerWeek,omitempty"`
+	Percent       *float64 `json:"percent,omitempty"`
+	Notes         string   `json:"notes,omitempty"`
+	CreatedByName string   `json:"createdByName,omitempty"`
+	CreatedAt     string   `json:"createdAt"`
+	UpdatedAt     string   `json:"updatedAt"`
+}
+
+// CreateResourceAllocationRequest books a user on an offer, a project or both. Set either hoursPerWeek or percent.
+type CreateResourceAllocationRequest struct {
+	UserID    string     `json:"userId" validate:"required,max=100"`
+	UserName  string     `json:"userName,omitempty" validate:"max=200"` // Used when the user has not signed in yet
+	OfferID   *uuid.UUID `json:"offerId,omitempty"`
+	ProjectID *uuid.UUID `json:"projectId,omitempty"`
+	// CompanyID is the company the work is done for; default the offer's company, then the user's company
+	CompanyID    *CompanyID `json:"companyId,omitempty"`
+	StartDate    string     `json:"startDate" validate:"required" example:"2026-05-04"` // YYYY-MM-DD
+	EndDate      string     `json:"endDate" validate:"required" example:"2026-06-26"`   // YYYY-MM-DD
+	HoursPerWeek *float64   `json:"hoursPerWeek,omitempty" validate:"omitempty,gt=0,lte=100"`
+	Percent      *float64   `json:"percent,omitempty" validate:"omitempty,gt=0,lte=100"`
+	Notes        string     `json:"notes,omitempty" validate:"max=2000"`
+}
+
+// UpdateResourceAllocationRequest changes the period and size of an allocation. Set either hoursPerWeek or percent.
+type UpdateResourceAllocationRequest struct {
+	StartDate    string   `json:"startDate" validate:"required"`
+	EndDate      string   `json:"endDate" validate:"required"`
+	HoursPerWeek *float64 `json:"hoursPerWeek,omitempty" validate:"omitempty,gt=0,lte=100"`
+	Percent      *float64 `json:"percent,omitempty" validate:"omitempty,gt=0,lte=100"`
+	Notes        string   `json:"notes,omitempty" validate:"max=2000"`
+}
+
+// UserAbsenceDTO is a period a user is away
+type UserAbsenceDTO struct {
+	ID            uuid.UUID       `json:"id"`
+	UserID        string          `json:"userId"`
+	UserName      string          `json:"userName,omitempty"`
+	Kind          UserAbsenceKind `json:"kind" enums:"vacation,sick_leave,leave,other"`
+	StartDate     string          `json:"startDate"` // YYYY-MM-DD
+	EndDate       string          `json:"endDate"`   // YYYY-MM-DD, last day of the absence
+	Percent       int             `json:"percent"`   // Share of each working day the user is away
+	Notes         string          `json:"notes,omitempty"`
+	CreatedByName string          `json:"createdByName,omitempty"`
+	CreatedAt     string          `json:"createdAt"`
+}
+
+// CreateUserAbsenceRequest registers vacation, sick leave or other absence for a user
+type CreateUserAbsenceRequest struct {
+	Kind      UserAbsenceKind `json:"kind" validate:"required,oneof=vacation sick_leave leave other"`
+	StartDate string          `json:"startDate" validate:"required" example:"2026-07-06"` // YYYY-MM-DD
+	EndDate   string          `json:"endDate" validate:"required" example:"2026-07-24"`   // YYYY-MM-DD
+	// Percent is the share of each working day the user is away, default 100
+	Percent int    `json:"percent,omitempty" validate:"omitempty,min=1,max=100"`
+	Notes   string `json:"notes,omitempty" validate:"max=2000"`
+}
+
+// UpdateUserCapacityRequest sets a user's working hours per week
+type UpdateUserCapacityRequest struct {
+	HoursPerWeek float64 `json:"hoursPerWeek" validate:"min=0,max=100" example:"37.5"`
+}
+
+// CapacityWeekDTO is a user's capacity and bookings in one ISO week, counting only the days within the requested period
+type CapacityWeekDTO struct {
+	WeekStart   string `json:"weekStart"` // Monday, YYYY-MM-DD
+	Year        int    `json:"year"`      // ISO year
+	Week        int    `json:"week"`      // ISO week number
+	WorkingDays int    `json:"workingDays"`
+	// CapacityHours is the user's weekly hours for the working days, excluding public holidays
+	CapacityHours  float64 `json:"capacityHours"`
+	AbsenceHours   float64 `json:"absenceHours"`
+	AvailableHours float64 `json:"availableHours"` // capacityHours - absenceHours
+	AllocatedHours float64 `json:"allocatedHours"`
+	FreeHours      float64 `json:"freeHours"` // availableHours - allocatedHours, negative when overallocated
+	// UtilizationPercent is allocatedHours / availableHours * 100, omitted when the user is not available
+	UtilizationPercent *float64 `json:"utilizationPercent,omitempty"`
+	Overallocated      bool     `json:"overallocated"`
+}
+
+// CapacityCalendarDTO is a user's capacity, absences and allocations week by week
+type CapacityCalendarDTO struct {
+	UserID       string                  `json:"userId"`
+	UserName     string                  `json:"userName,omitempty"`
+	HoursPerWeek float64                 `json:"hoursPerWeek"`
+	From         string                  `json:"from"` // YYYY-MM-DD
+	To           string                  `json:"to"`   // YYYY-MM-DD
+	Weeks        []CapacityWeekDTO       `json:"weeks"`
+	Absences     []UserAbsenceDTO        `json:"absences"`
+	Allocations  []ResourceAllocationDTO `json:"allocations"`
+	// AvailableHours, AllocatedHours and OverallocatedWeeks sum the weeks
+	AvailableHours     float64 `json:"availableHours"`
+	AllocatedHours     float64 `json:"allocatedHours"`
+	OverallocatedWeeks int     `json:"overallocatedWeeks"`
+}
+
+// CapacityOverallocationReportDTO lists, per company, the users booked on its work who are over capacity in the period
+type CapacityOverallocationReportDTO struct {
+	From      string                             `json:"from"` // YYYY-MM-DD
+	To        string                             `json:"to"`   // YYYY-MM-DD
+	Companies []CapacityCompanyOverallocationDTO `json:"companies"`
+}
+
+// CapacityCompanyOverallocationDTO is the overallocated users allocated to one company's offers and projects
+type CapacityCompanyOverallocationDTO struct {
+	CompanyID          CompanyID                      `json:"companyId"`
+	AllocatedUsers     int                            `json:"allocatedUsers"`
+	OverallocatedUsers int                            `json:"overallocatedUsers"`
+	Users              []CapacityOverallocatedUserDTO `json:"users"`
+}
+
+// CapacityOverallocatedUserDTO is a user whose allocations across all companies exceed their available hours
+type CapacityOverallocatedUserDTO struct {
+	UserID       string  `json:"userId"`
+	UserName     string  `json:"userName,omitempty"`
+	HoursPerWeek float64 `json:"hoursPerWeek"`
+	// ExcessHours sums the allocated hours above the available hours in the overallocated weeks
+	ExcessHours            float64           `json:"excessHours"`
+	PeakUtilizationPercent float64           `json:"peakUtilizationPercent"`
+	Weeks                  []CapacityWeekDTO `json:"weeks"` // Only the overallocated weeks
+}
+
+// CapacityWarningDTO warns that a user is already over capacity in a period they are being assigned work in
+type CapacityWarningDTO struct {
+	Code     string            `json:"code" enums:"manager.over.capacity"`
+	UserID   string            `json:"userId"`
+	UserName string            `json:"userName,omitempty"`
+	From     string            `json:"from"`  // YYYY-MM-DD
+	To       string            `json:"to"`    // YYYY-MM-DD
+	Weeks    []CapacityWeekDTO `json:"weeks"` // The overallocated weeks
+	Message  string            `json:"message"`
+}
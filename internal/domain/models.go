@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -50,6 +51,66 @@ const (
 	CustomerIndustryOther         CustomerIndustry = "other"
 )
 
+// CustomerRegistryStatus represents a customer's standing in Enhetsregisteret (Brønnøysundregistrene)
+type CustomerRegistryStatus string
+
+const (
+	CustomerRegistryStatusActive           CustomerRegistryStatus = "active"
+	CustomerRegistryStatusUnderLiquidation CustomerRegistryStatus = "under_liquidation"
+	CustomerRegistryStatusBankrupt         CustomerRegistryStatus = "bankrupt"
+	CustomerRegistryStatusDeleted          CustomerRegistryStatus = "deleted"
+	CustomerRegistryStatusNotFound         CustomerRegistryStatus = "not_found"
+)
+
+// IsValid checks if the registry status is a valid value
+func (s CustomerRegistryStatus) IsValid() bool {
+	switch s {
+	case CustomerRegistryStatusActive, CustomerRegistryStatusUnderLiquidation, CustomerRegistryStatusBankrupt,
+		CustomerRegistryStatusDeleted, CustomerRegistryStatusNotFound:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsFlagged returns true if the registry status indicates the customer should no longer be traded with
+func (s CustomerRegistryStatus) IsFlagged() bool {
+	return s == CustomerRegistryStatusBankrupt || s == CustomerRegistryStatusDeleted || s == CustomerRegistryStatusUnderLiquidation
+}
+
+// orgNumberWeights are the MOD11 weights for the first eight digits of a Norwegian organization number
+var orgNumberWeights = [8]int{3, 2, 7, 6, 5, 4, 3, 2}
+
+// IsValidOrgNumber checks if a Norwegian organization number is valid.
+// The number must be nine digits (spaces and dashes are ignored) where the last
+// digit is a MOD11 check digit of the first eight.
+func IsValidOrgNumber(orgNumber string) bool {
+	digits := strings.NewReplacer(" ", "", "-", "").Replace(strings.TrimSpace(orgNumber))
+	if len(digits) != 9 {
+		return false
+	}
+
+	sum := 0
+	for i := 0; i < 9; i++ {
+		if digits[i] < '0' || digits[i] > '9' {
+			return false
+		}
+		if i < 8 {
+			sum += int(digits[i]-'0') * orgNumberWeights[i]
+		}
+	}
+
+	check := 11 - sum%11
+	if check == 11 {
+		check = 0
+	}
+	// A remainder of 1 gives check digit 10, which is never issued
+	if check == 10 {
+		return false
+	}
+	return int(digits[8]-'0') == check
+}
+
 // CompanyID represents Straye group companies
 type CompanyID string
 
@@ -73,6 +134,25 @@ func IsValidCompanyID(id string) bool {
 	}
 }
 
+// CreditLimitEnforcement decides what happens when an order would push a customer over its credit limit
+type CreditLimitEnforcement string
+
+const (
+	// CreditLimitEnforcementWarn accepts the order and warns the user and company admins
+	CreditLimitEnforcementWarn CreditLimitEnforcement = "warn"
+	// CreditLimitEnforcementBlock rejects the order and notifies company admins
+	CreditLimitEnforcementBlock CreditLimitEnforcement = "block"
+)
+
+// IsValid checks if the credit limit enforcement is a valid value
+func (e CreditLimitEnforcement) IsValid() bool {
+	switch e {
+	case CreditLimitEnforcementWarn, CreditLimitEnforcementBlock:
+		return true
+	}
+	return false
+}
+
 // Company represents a Straye group company (stored in database)
 type Company struct {
 	ID                          CompanyID `gorm:"type:varchar(50);primaryKey" json:"id"`
@@ -84,8 +164,17 @@ type Company struct {
 	IsActive                    bool      `gorm:"not null;default:true;column:is_active" json:"isActive"`
 	DefaultOfferResponsibleID   *string   `gorm:"type:varchar(100);column:default_offer_responsible_id" json:"defaultOfferResponsibleId,omitempty"`
 	DefaultProjectResponsibleID *string   `gorm:"type:varchar(100);column:default_project_responsible_id" json:"defaultProjectResponsibleId,omitempty"`
-	CreatedAt                   time.Time `gorm:"not null;default:CURRENT_TIMESTAMP" json:"createdAt"`
-	UpdatedAt                   time.Time `gorm:"not null;default:CURRENT_TIMESTAMP" json:"updatedAt"`
+	// CreditLimitEnforcement decides whether accepting an order over the customer's credit limit warns or is blocked
+	CreditLimitEnforcement CreditLimitEnforcement `gorm:"type:varchar(20);not null;default:'warn';column:credit_limit_enforcement" json:"creditLimitEnforcement"`
+	// InquirySLAHours is the business hours from an inquiry is received until the customer must have had a response
+	InquirySLAHours int `gorm:"not null;default:16;column:inquiry_sla_hours" json:"inquirySlaHours"`
+	// InquirySLAWarningHours is the business hours before the SLA deadline the responsible user is warned
+	InquirySLAWarningHours int `gorm:"not null;default:4;column:inquiry_sla_warning_hours" json:"inquirySlaWarningHours"`
+	// BusinessDayStart and BusinessDayEnd are the business hours (HH:MM, Europe/Oslo) SLA clocks run in
+	BusinessDayStart string    `gorm:"type:varchar(5);not null;default:'08:00';column:business_day_start" json:"businessDayStart"`
+	BusinessDayEnd   string    `gorm:"type:varchar(5);not null;default:'16:00';column:business_day_end" json:"businessDayEnd"`
+	CreatedAt        time.Time `gorm:"not null;default:CURRENT_TIMESTAMP" json:"createdAt"`
+	UpdatedAt        time.Time `gorm:"not null;default:CURRENT_TIMESTAMP" json:"updatedAt"`
 }
 
 // Customer represents an organization in the CRM
@@ -117,6 +206,17 @@ type Customer struct {
 	Website       string           `gorm:"type:varchar(500)"`
 	CompanyID     *CompanyID       `gorm:"type:varchar(50);column:company_id;index"`
 	Company       *Company         `gorm:"foreignKey:CompanyID"`
+	// Enhetsregisteret enrichment fields
+	IndustryCode      string                 `gorm:"type:varchar(20);column:industry_code"`
+	RegistryStatus    CustomerRegistryStatus `gorm:"type:varchar(50);column:registry_status;index"`
+	RegistryCheckedAt *time.Time             `gorm:"column:registry_checked_at"`
+	// Tier calculation fields; a locked tier is a manual override the recalculation job leaves alone
+	TierLocked       bool       `gorm:"not null;default:false;column:tier_locked;index"`
+	TierLockedAt     *time.Time `gorm:"column:tier_locked_at"`
+	TierLockedByName string     `gorm:"type:varchar(200);column:tier_locked_by_name"`
+	TierCalculatedAt *time.Time `gorm:"column:tier_calculated_at"`
+	// Customer group (konsern); subsidiaries point to their parent customer
+	ParentCustomerID *uuid.UUID `gorm:"type:uuid;column:parent_customer_id;index"`
 	// User tracking fields
 	CreatedByID   string `gorm:"type:varchar(100);column:created_by_id;index"`
 	CreatedByName string `gorm:"type:varchar(200);column:created_by_name"`
@@ -161,6 +261,12 @@ type Contact struct {
 	PreferredContactMethod string      `gorm:"type:varchar(50);default:'email';column:preferred_contact_method"`
 	Notes                  string      `gorm:"type:text"`
 	IsActive               bool        `gorm:"not null;default:true;column:is_active"`
+	// GDPR fields
+	AnonymizedAt        *time.Time `gorm:"column:anonymized_at"`         // Set when personal data has been scrubbed
+	RetentionFlaggedAt  *time.Time `gorm:"column:retention_flagged_at"`  // Set by the retention job when the contact has been inactive too long
+	RetentionReviewedAt *time.Time `gorm:"column:retention_reviewed_at"` // Set when a flagged contact was reviewed and kept
+	// Deduplication
+	MergedIntoID *uuid.UUID `gorm:"type:uuid;column:merged_into_id"` // Surviving contact this duplicate was merged into
 	// User tracking fields
 	CreatedByID   string `gorm:"type:varchar(100);column:created_by_id;index"`
 	CreatedByName string `gorm:"type:varchar(200);column:created_by_name"`
@@ -182,8 +288,18 @@ const (
 	ContactEntityCustomer ContactEntityType = "customer"
 	ContactEntityProject  ContactEntityType = "project"
 	ContactEntityDeal     ContactEntityType = "deal"
+	ContactEntityOffer    ContactEntityType = "offer"
 )
 
+// IsValid checks if the ContactEntityType is a valid enum value
+func (t ContactEntityType) IsValid() bool {
+	switch t {
+	case ContactEntityCustomer, ContactEntityProject, ContactEntityDeal, ContactEntityOffer:
+		return true
+	}
+	return false
+}
+
 // ContactRelationship represents a polymorphic relationship between a contact and an entity
 type ContactRelationship struct {
 	ID         uuid.UUID         `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
@@ -193,9 +309,58 @@ type ContactRelationship struct {
 	EntityID   uuid.UUID         `gorm:"type:uuid;not null;column:entity_id"`
 	Role       string            `gorm:"type:varchar(100)"`
 	IsPrimary  bool              `gorm:"not null;default:false;column:is_primary"`
+	BuyingRole *BuyingRole       `gorm:"type:varchar(50);column:buying_role"` // Buying-committee role, offers only
+	Sentiment  *ContactSentiment `gorm:"type:varchar(20);column:sentiment"`   // Offers only
 	CreatedAt  time.Time         `gorm:"not null;default:CURRENT_TIMESTAMP"`
 }
 
+// BuyingRole is the role of a contact in the buying committee of an offer
+type BuyingRole string
+
+const (
+	BuyingRoleDecisionMaker      BuyingRole = "decision_maker"
+	BuyingRoleEconomicBuyer      BuyingRole = "economic_buyer"
+	BuyingRoleTechnicalEvaluator BuyingRole = "technical_evaluator"
+	BuyingRoleInfluencer         BuyingRole = "influencer"
+	BuyingRoleBlocker            BuyingRole = "blocker"
+)
+
+// BuyingRoles lists the buying-committee roles in display order
+var BuyingRoles = []BuyingRole{
+	BuyingRoleDecisionMaker,
+	BuyingRoleEconomicBuyer,
+	BuyingRoleTechnicalEvaluator,
+	BuyingRoleInfluencer,
+	BuyingRoleBlocker,
+}
+
+// IsValid checks if the BuyingRole is a valid enum value
+func (r BuyingRole) IsValid() bool {
+	switch r {
+	case BuyingRoleDecisionMaker, BuyingRoleEconomicBuyer, BuyingRoleTechnicalEvaluator, BuyingRoleInfluencer, BuyingRoleBlocker:
+		return true
+	}
+	return false
+}
+
+// ContactSentiment is how a contact feels about an offer
+type ContactSentiment string
+
+const (
+	ContactSentimentPositive ContactSentiment = "positive"
+	ContactSentimentNeutral  ContactSentiment = "neutral"
+	ContactSentimentNegative ContactSentiment = "negative"
+)
+
+// IsValid checks if the ContactSentiment is a valid enum value
+func (s ContactSentiment) IsValid() bool {
+	switch s {
+	case ContactSentimentPositive, ContactSentimentNeutral, ContactSentimentNegative:
+		return true
+	}
+	return false
+}
+
 // DealStage represents the stage of a deal in the sales pipeline
 type DealStage string
 
@@ -253,6 +418,9 @@ type Deal struct {
 	LossReasonCategory *LossReasonCategory `gorm:"type:varchar(50);column:loss_reason_category"`
 	OfferID            *uuid.UUID          `gorm:"type:uuid;index;column:offer_id"`
 	Offer              *Offer              `gorm:"foreignKey:OfferID"`
+	PipelineID         *uuid.UUID          `gorm:"type:uuid;index;column:pipeline_id"`
+	// ProbabilityAtClose is the probability entered right before the deal was won or lost
+	ProbabilityAtClose *int `gorm:"type:int;column:probability_at_close"`
 }
 
 // DealStageHistory tracks stage changes for audit purposes
@@ -273,6 +441,91 @@ func (DealStageHistory) TableName() string {
 	return "deal_stage_history"
 }
 
+// DealStageKind is the outcome a pipeline stage represents
+type DealStageKind string
+
+const (
+	DealStageKindOpen DealStageKind = "open"
+	DealStageKindWon  DealStageKind = "won"
+	DealStageKindLost DealStageKind = "lost"
+)
+
+// DealPipeline is a company's sales process: the ordered stages its deals move through
+type DealPipeline struct {
+	BaseModel
+	CompanyID   CompanyID           `gorm:"type:varchar(50);not null;index;column:company_id"`
+	Name        string              `gorm:"type:varchar(100);not null"`
+	Description string              `gorm:"type:text"`
+	IsDefault   bool                `gorm:"not null;default:false;column:is_default"`
+	Stages      []DealPipelineStage `gorm:"foreignKey:PipelineID"`
+}
+
+// TableName returns the table name for DealPipeline
+func (DealPipeline) TableName() string {
+	return "deal_pipelines"
+}
+
+// Stage returns the stage with the given key, or nil if the pipeline has no such stage
+func (p *DealPipeline) Stage(key DealStage) *DealPipelineStage {
+	for i := range p.Stages {
+		if p.Stages[i].Key == key {
+			return &p.Stages[i]
+		}
+	}
+	return nil
+}
+
+// FirstStage returns the first open stage, where new and reopened deals start
+func (p *DealPipeline) FirstStage() *DealPipelineStage {
+	for i := range p.Stages {
+		if p.Stages[i].Kind == DealStageKindOpen {
+			return &p.Stages[i]
+		}
+	}
+	return nil
+}
+
+// OpenStages returns the keys of the open stages in pipeline order
+func (p *DealPipeline) OpenStages() []DealStage {
+	var keys []DealStage
+	for _, stage := range p.Stages {
+		if stage.Kind == DealStageKindOpen {
+			keys = append(keys, stage.Key)
+		}
+	}
+	return keys
+}
+
+// DealPipelineStage is a stage in a deal pipeline. Deals store the stage key; the won and lost
+// stages always use the keys won and lost.
+type DealPipelineStage struct {
+	ID                 uuid.UUID      `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	PipelineID         uuid.UUID      `gorm:"type:uuid;not null;index;column:pipeline_id"`
+	Key                DealStage      `gorm:"type:varchar(50);not null"`
+	Name               string         `gorm:"type:varchar(100);not null"`
+	SortOrder          int            `gorm:"not null;default:0;column:sort_order"`
+	Kind               DealStageKind  `gorm:"type:varchar(20);not null;default:'open'"`
+	Probability        int            `gorm:"not null;default:0"`
+	RequiredFields     pq.StringArray `gorm:"type:text[];not null;default:'{}';column:required_fields"`
+	AllowedTransitions pq.StringArray `gorm:"type:text[];not null;default:'{}';column:allowed_transitions"`
+	StaleAfterDays     *int           `gorm:"column:stale_after_days"` // Days without stage change or activity before a deal is stale
+}
+
+// TableName returns the table name for DealPipelineStage
+func (DealPipelineStage) TableName() string {
+	return "deal_pipeline_stages"
+}
+
+// CanTransitionTo reports whether a deal in this stage can move to the given stage
+func (s *DealPipelineStage) CanTransitionTo(key DealStage) bool {
+	for _, allowed := range s.AllowedTransitions {
+		if DealStage(allowed) == key {
+			return true
+		}
+	}
+	return false
+}
+
 // ProjectPhase represents the lifecycle phase of a project
 // Projects are now lightweight containers for offers. Economic tracking moved to Offer.
 // - tilbud: Offer/bidding phase (default). Project collecting offers.
@@ -535,6 +788,11 @@ const (
 	// from the data warehouse but DWTotalFixedPrice is 0, meaning the project leader
 	// has not entered the contract value in the ERP system.
 	OfferWarningMissingDWTotalFixedPrice OfferWarning = "missing.dwTotalFixedPrice"
+
+	// OfferWarningMissingDecisionMaker indicates that the offer was sent without a
+	// contact linked as decision maker in its buying committee.
+	// This warning is only returned when the offer is sent.
+	OfferWarningMissingDecisionMaker OfferWarning = "missing.decisionMaker"
 )
 
 // Offer represents a sales proposal and, when in order phase, the execution of work
@@ -563,6 +821,8 @@ type Offer struct {
 	MarginPercent         float64     `gorm:"type:decimal(8,4);not null;default:0;column:margin_percent"` // Dekningsgrad: (value - cost) / value * 100, auto-calculated
 	Location              string      `gorm:"type:varchar(200)"`
 	SentDate              *time.Time  `gorm:"type:timestamp;index;column:sent_date"`
+	PhaseChangedAt        *time.Time  `gorm:"column:phase_changed_at;->"`                  // Maintained by a database trigger
+	WonAt                 *time.Time  `gorm:"column:won_at;->"`                            // When accepted as an order, maintained by a database trigger
 	ExpirationDate        *time.Time  `gorm:"type:timestamp;index;column:expiration_date"` // When the offer expires (default: 60 days after sent_date)
 	CustomerHasWonProject bool        `gorm:"not null;default:false;column:customer_has_won_project"`
 	// Order phase execution fields (used when phase = "order" or "completed")
@@ -577,6 +837,10 @@ type Offer struct {
 	StartDate               *time.Time     `gorm:"type:date;column:start_date"`                           // When work started
 	EndDate                 *time.Time     `gorm:"type:date;column:end_date"`                             // Planned end date
 	EstimatedCompletionDate *time.Time     `gorm:"type:date;column:estimated_completion_date"`            // Current estimate for completion
+	// Inquiry response SLA (draft phase)
+	InquirySLADueAt    *time.Time `gorm:"column:inquiry_sla_due_at"`    // When the customer must have had a response
+	InquiryRespondedAt *time.Time `gorm:"column:inquiry_responded_at"`  // When the inquiry was converted to an offer
+	InquirySLAWarnedAt *time.Time `gorm:"column:inquiry_sla_warned_at"` // When the responsible user was warned about the deadline
 	// User tracking fields
 	CreatedByID   string `gorm:"type:varchar(100);column:created_by_id;index"`
 	CreatedByName string `gorm:"type:varchar(200);column:created_by_name"`
@@ -682,6 +946,15 @@ const (
 	BudgetParentProject BudgetParentType = "project"
 )
 
+// BudgetCostCategory is the data warehouse cost category a budget item's actual costs are booked in
+type BudgetCostCategory string
+
+const (
+	BudgetCostCategoryMaterial BudgetCostCategory = "material" // Accounts 4000-4999
+	BudgetCostCategoryEmployee BudgetCostCategory = "employee" // Accounts 5000-5999
+	BudgetCostCategoryOther    BudgetCostCategory = "other"    // Accounts >= 6000
+)
+
 // BudgetItem represents a flexible budget line item that can belong to an offer or project
 // Users define their own budget items with name, cost, margin, and optional quantity/price fields
 type BudgetItem struct {
@@ -697,8 +970,12 @@ type BudgetItem struct {
 	PricePerItem    *float64         `gorm:"type:decimal(15,2);column:price_per_item" json:"pricePerItem,omitempty"`
 	Description     string           `gorm:"type:text" json:"description,omitempty"`
 	DisplayOrder    int              `gorm:"not null;default:0;column:display_order" json:"displayOrder"`
-	CreatedAt       time.Time        `gorm:"not null;default:CURRENT_TIMESTAMP" json:"createdAt"`
-	UpdatedAt       time.Time        `gorm:"not null;default:CURRENT_TIMESTAMP" json:"updatedAt"`
+	// CostCategory is where the item's actual costs are booked in the data warehouse; nil when not tracked
+	CostCategory *BudgetCostCategory `gorm:"type:varchar(20);column:cost_category" json:"costCategory,omitempty"`
+	// SourceItemID is the offer item a project budget item was rebudgeted from
+	SourceItemID *uuid.UUID `gorm:"type:uuid;column:source_item_id" json:"sourceItemId,omitempty"`
+	CreatedAt    time.Time  `gorm:"not null;default:CURRENT_TIMESTAMP" json:"createdAt"`
+	UpdatedAt    time.Time  `gorm:"not null;default:CURRENT_TIMESTAMP" json:"updatedAt"`
 }
 
 // TableName returns the table name for BudgetItem
@@ -794,6 +1071,50 @@ type Activity struct {
 	Attendees        pq.StringArray     `gorm:"type:text[];column:attendees"`
 	ParentActivityID *uuid.UUID         `gorm:"type:uuid;column:parent_activity_id"`
 	ParentActivity   *Activity          `gorm:"foreignKey:ParentActivityID"`
+	// Recurrence: the series master holds the rule and template, occurrences reference it via SeriesID
+	RecurrenceRule          string     `gorm:"type:varchar(500);column:recurrence_rule"`
+	RecurrenceTemplate      string     `gorm:"type:jsonb;column:recurrence_template"`
+	RecurrenceExpandedUntil *time.Time `gorm:"column:recurrence_expanded_until"`
+	SeriesID                *uuid.UUID `gorm:"type:uuid;column:series_id"`
+	OccurrenceAt            *time.Time `gorm:"column:occurrence_at"`
+	IsRecurrenceException   bool       `gorm:"not null;default:false;column:is_recurrence_exception"`
+}
+
+// IsSeriesMaster returns true if the activity is the master of a recurring series
+func (a *Activity) IsSeriesMaster() bool {
+	return a.SeriesID != nil && *a.SeriesID == a.ID
+}
+
+// RecurrenceScope selects which occurrences of a recurring series an edit or delete applies to
+type RecurrenceScope string
+
+const (
+	RecurrenceScopeThis      RecurrenceScope = "this"
+	RecurrenceScopeFollowing RecurrenceScope = "following"
+	RecurrenceScopeAll       RecurrenceScope = "all"
+)
+
+// IsValid checks if the RecurrenceScope is a valid enum value
+func (rs RecurrenceScope) IsValid() bool {
+	switch rs {
+	case RecurrenceScopeThis, RecurrenceScopeFollowing, RecurrenceScopeAll:
+		return true
+	}
+	return false
+}
+
+// ActivityRecurrenceTemplate holds the fields new occurrences of a recurring series are generated
+// from. It is stored as JSON on the series master.
+type ActivityRecurrenceTemplate struct {
+	Title           string   `json:"title"`
+	Body            string   `json:"body,omitempty"`
+	DurationMinutes *int     `json:"durationMinutes,omitempty"`
+	Priority        int      `json:"priority"`
+	IsPrivate       bool     `json:"isPrivate"`
+	AssignedToID    string   `json:"assignedToId,omitempty"`
+	Attendees       []string `json:"attendees,omitempty"`
+	// AllDay is set for series anchored on the due date rather than a scheduled time
+	AllDay bool `json:"allDay,omitempty"`
 }
 
 // UserRoleType represents a role a user can have
@@ -1031,6 +1352,10 @@ const (
 	NotificationTypeOfferRejected    NotificationType = "offer_rejected"
 	NotificationTypeActivityReminder NotificationType = "activity_reminder"
 	NotificationTypeProjectUpdate    NotificationType = "project_update"
+	NotificationTypeCreditLimit      NotificationType = "credit_limit_exceeded"
+	NotificationTypeStaleDigest      NotificationType = "stale_digest"
+	NotificationTypeInquirySLA       NotificationType = "inquiry_sla"
+	NotificationTypeMilestoneSlipped NotificationType = "milestone_slipped"
 )
 
 // Notification represents a user notification
@@ -1238,3 +1563,729 @@ type Assignment struct {
 func (Assignment) TableName() string {
 	return "assignments"
 }
+
+// ImportEntityType is the kind of record created by a spreadsheet import
+type ImportEntityType string
+
+const (
+	ImportEntityCustomer ImportEntityType = "customer"
+	ImportEntityOffer    ImportEntityType = "offer"
+)
+
+// IsValid checks if the import entity type is valid
+func (t ImportEntityType) IsValid() bool {
+	return t == ImportEntityCustomer || t == ImportEntityOffer
+}
+
+// ImportJobStatus represents the lifecycle of an import job
+type ImportJobStatus string
+
+const (
+	ImportJobStatusUploaded  ImportJobStatus = "uploaded"  // File parsed, waiting for column mapping
+	ImportJobStatusValidated ImportJobStatus = "validated" // Dry run completed, ready to commit
+	ImportJobStatusCommitted ImportJobStatus = "committed" // Rows written through the services
+	ImportJobStatusFailed    ImportJobStatus = "failed"    // Commit aborted with an error
+)
+
+// ImportJob is an uploaded spreadsheet being imported as customers or offers.
+// The parsed rows are stored with the job so dry run and commit work on the same data.
+type ImportJob struct {
+	BaseModel
+	EntityType ImportEntityType `gorm:"type:varchar(50);not null;index;column:entity_type"`
+	FileName   string           `gorm:"type:varchar(255);not null;column:file_name"`
+	Status     ImportJobStatus  `gorm:"type:varchar(50);not null;default:'uploaded';index"`
+	Headers    string           `gorm:"type:jsonb;not null"` // JSON array of column headers
+	Rows       string           `gorm:"type:jsonb;not null"` // JSON array of rows (arrays of cell values)
+	RowCount   int              `gorm:"not null;default:0;column:row_count"`
+	Mapping    string           `gorm:"type:jsonb"` // JSON object: column header -> field
+	Options    string           `gorm:"type:jsonb"` // JSON object with dry run options
+	Report     string           `gorm:"type:jsonb"` // JSON report from the last dry run or commit
+	CompanyID  *CompanyID       `gorm:"type:varchar(50);column:company_id"`
+	// Commit tracking
+	CommittedAt *time.Time `gorm:"column:committed_at"`
+	// User tracking fields
+	CreatedByID   string `gorm:"type:varchar(100);column:created_by_id;index"`
+	CreatedByName string `gorm:"type:varchar(200);column:created_by_name"`
+}
+
+// TableName returns the table name for ImportJob
+func (ImportJob) TableName() string {
+	return "import_jobs"
+}
+
+// ImportMappingProfile is a saved column mapping that can be reused for files with the same layout
+type ImportMappingProfile struct {
+	BaseModel
+	Name       string           `gorm:"type:varchar(200);not null"`
+	EntityType ImportEntityType `gorm:"type:varchar(50);not null;index;column:entity_type"`
+	Mapping    string           `gorm:"type:jsonb;not null"` // JSON object: column header -> field
+	// User tracking fields
+	CreatedByID   string `gorm:"type:varchar(100);column:created_by_id"`
+	CreatedByName string `gorm:"type:varchar(200);column:created_by_name"`
+}
+
+// TableName returns the table name for ImportMappingProfile
+func (ImportMappingProfile) TableName() string {
+	return "import_mapping_profiles"
+}
+
+// ERPReconciliationTrigger is what started an ERP customer reconciliation run
+type ERPReconciliationTrigger string
+
+const (
+	ERPReconciliationTriggerScheduled ERPReconciliationTrigger = "scheduled"
+	ERPReconciliationTriggerManual    ERPReconciliationTrigger = "manual"
+)
+
+// ERPReconciliationRunStatus represents the outcome of a reconciliation run
+type ERPReconciliationRunStatus string
+
+const (
+	ERPReconciliationRunStatusRunning   ERPReconciliationRunStatus = "running"
+	ERPReconciliationRunStatusCompleted ERPReconciliationRunStatus = "completed"
+	ERPReconciliationRunStatusFailed    ERPReconciliationRunStatus = "failed"
+)
+
+// ERPReconciliationRun is one comparison of ERP customers against CRM customers
+type ERPReconciliationRun struct {
+	BaseModel
+	Trigger            ERPReconciliationTrigger   `gorm:"type:varchar(50);not null"`
+	Status             ERPReconciliationRunStatus `gorm:"type:varchar(50);not null;default:'running';index"`
+	StartedAt          time.Time                  `gorm:"not null;column:started_at"`
+	FinishedAt         *time.Time                 `gorm:"column:finished_at"`
+	ERPCustomerCount   int                        `gorm:"not null;default:0;column:erp_customer_count"`
+	LocalCustomerCount int                        `gorm:"not null;default:0;column:local_customer_count"`
+	MatchedCount       int                        `gorm:"not null;default:0;column:matched_count"`
+	CustomersCreated   int                        `gorm:"not null;default:0;column:customers_created"` // Missing customers created automatically
+	CreatesQueued      int                        `gorm:"not null;default:0;column:creates_queued"`    // Missing customers queued for approval
+	FieldsUpdated      int                        `gorm:"not null;default:0;column:fields_updated"`    // Drifted fields where the ERP value was applied
+	DriftsQueued       int                        `gorm:"not null;default:0;column:drifts_queued"`     // Drifted fields queued for approval
+	DriftsKept         int                        `gorm:"not null;default:0;column:drifts_kept"`       // Drifted fields where the CRM value was kept
+	Errors             int                        `gorm:"not null;default:0"`
+	ErrorMessage       string                     `gorm:"type:text;column:error_message"`
+	// User tracking fields (empty for scheduled runs)
+	CreatedByID   string `gorm:"type:varchar(100);column:created_by_id"`
+	CreatedByName string `gorm:"type:varchar(200);column:created_by_name"`
+}
+
+// TableName returns the table name for ERPReconciliationRun
+func (ERPReconciliationRun) TableName() string {
+	return "erp_reconciliation_runs"
+}
+
+// ERPReconciliationDecisionType is the kind of change a reconciliation decision makes
+type ERPReconciliationDecisionType string
+
+const (
+	ERPReconciliationDecisionCreateCustomer ERPReconciliationDecisionType = "create_customer" // Customer exists in ERP but not in CRM
+	ERPReconciliationDecisionFieldDrift     ERPReconciliationDecisionType = "field_drift"     // A field differs between ERP and CRM
+)
+
+// ERPReconciliationDecisionStatus represents the lifecycle of a reconciliation decision
+type ERPReconciliationDecisionStatus string
+
+const (
+	ERPReconciliationDecisionPending  ERPReconciliationDecisionStatus = "pending"  // Waiting for approval
+	ERPReconciliationDecisionApproved ERPReconciliationDecisionStatus = "approved" // Approved by a user and applied
+	ERPReconciliationDecisionRejected ERPReconciliationDecisionStatus = "rejected" // Rejected by a user, not suggested again for the same ERP value
+	ERPReconciliationDecisionApplied  ERPReconciliationDecisionStatus = "applied"  // Applied automatically by policy
+	ERPReconciliationDecisionObsolete ERPReconciliationDecisionStatus = "obsolete" // No longer differs in a later run
+)
+
+// IsValid checks if the decision status is a valid value
+func (s ERPReconciliationDecisionStatus) IsValid() bool {
+	switch s {
+	case ERPReconciliationDecisionPending, ERPReconciliationDecisionApproved, ERPReconciliationDecisionRejected,
+		ERPReconciliationDecisionApplied, ERPReconciliationDecisionObsolete:
+		return true
+	default:
+		return false
+	}
+}
+
+// ERPReconciliationDecision is a difference between the ERP and the CRM found by a reconciliation run.
+// Decisions for missing customers carry the ERP customer data; drift decisions carry the field and both values.
+type ERPReconciliationDecision struct {
+	BaseModel
+	RunID        uuid.UUID                       `gorm:"type:uuid;not null;index;column:run_id"`
+	Type         ERPReconciliationDecisionType   `gorm:"type:varchar(50);not null;index"`
+	Status       ERPReconciliationDecisionStatus `gorm:"type:varchar(50);not null;default:'pending';index"`
+	Key          string                          `gorm:"type:varchar(300);not null;index"` // Identifies the same difference across runs
+	CustomerID   *uuid.UUID                      `gorm:"type:uuid;index;column:customer_id"`
+	CustomerName string                          `gorm:"type:varchar(200);column:customer_name"`
+	Field        string                          `gorm:"type:varchar(50)"`
+	CRMValue     string                          `gorm:"type:varchar(500);column:crm_value"`
+	ERPValue     string                          `gorm:"type:varchar(500);column:erp_value"`
+	// ERP customer data
+	ERPFirmanr        int    `gorm:"column:erp_firmanr"`
+	ERPCustomerNumber string `gorm:"type:varchar(50);column:erp_customer_number"`
+	ERPOrgNumber      string `gorm:"type:varchar(20);column:erp_org_number"`
+	ERPName           string `gorm:"type:varchar(200);column:erp_name"`
+	ERPAddress        string `gorm:"type:varchar(500);column:erp_address"`
+	ERPPostalCode     string `gorm:"type:varchar(20);column:erp_postal_code"`
+	ERPCity           string `gorm:"type:varchar(100);column:erp_city"`
+	Error             string `gorm:"type:text"` // Why an automatic change could not be applied
+	// Resolution tracking
+	ResolvedAt     *time.Time `gorm:"column:resolved_at"`
+	ResolvedByID   string     `gorm:"type:varchar(100);column:resolved_by_id"`
+	ResolvedByName string     `gorm:"type:varchar(200);column:resolved_by_name"`
+}
+
+// TableName returns the table name for ERPReconciliationDecision
+func (ERPReconciliationDecision) TableName() string {
+	return "erp_reconciliation_decisions"
+}
+
+// ERPReconciliationPolicy decides which side wins when a customer field differs between ERP and CRM
+type ERPReconciliationPolicy string
+
+const (
+	ERPReconciliationPolicyERPWins ERPReconciliationPolicy = "erp_wins" // The ERP value is applied automatically
+	ERPReconciliationPolicyCRMWins ERPReconciliationPolicy = "crm_wins" // The CRM value is kept; drift is only counted
+	ERPReconciliationPolicyReview  ERPReconciliationPolicy = "review"   // The drift is queued for approval
+)
+
+// IsValid checks if the policy is a valid value
+func (p ERPReconciliationPolicy) IsValid() bool {
+	return p == ERPReconciliationPolicyERPWins || p == ERPReconciliationPolicyCRMWins || p == ERPReconciliationPolicyReview
+}
+
+// Customer fields compared by ERP reconciliation
+const (
+	ERPReconciliationFieldName       = "name"
+	ERPReconciliationFieldOrgNumber  = "orgNumber"
+	ERPReconciliationFieldAddress    = "address"
+	ERPReconciliationFieldPostalCode = "postalCode"
+	ERPReconciliationFieldCity       = "city"
+)
+
+// ERPReconciliationFields lists the reconciled customer fields in comparison order
+var ERPReconciliationFields = []string{
+	ERPReconciliationFieldName,
+	ERPReconciliationFieldOrgNumber,
+	ERPReconciliationFieldAddress,
+	ERPReconciliationFieldPostalCode,
+	ERPReconciliationFieldCity,
+}
+
+// ERPReconciliationFieldPolicy is the drift policy for one customer field
+type ERPReconciliationFieldPolicy struct {
+	Field         string                  `gorm:"type:varchar(50);primaryKey"`
+	Policy        ERPReconciliationPolicy `gorm:"type:varchar(50);not null"`
+	UpdatedAt     time.Time               `gorm:"not null;default:CURRENT_TIMESTAMP"`
+	UpdatedByID   string                  `gorm:"type:varchar(100);column:updated_by_id"`
+	UpdatedByName string                  `gorm:"type:varchar(200);column:updated_by_name"`
+}
+
+// TableName returns the table name for ERPReconciliationFieldPolicy
+func (ERPReconciliationFieldPolicy) TableName() string {
+	return "erp_reconciliation_policies"
+}
+
+// CustomerTierMetric is the measure a tier rule compares against its thresholds
+type CustomerTierMetric string
+
+const (
+	// CustomerTierMetricWonValue is the total value of won offers (order or completed phase)
+	CustomerTierMetricWonValue CustomerTierMetric = "won_value"
+	// CustomerTierMetricOrderCount is the number of won offers (order or completed phase)
+	CustomerTierMetricOrderCount CustomerTierMetric = "order_count"
+)
+
+// IsValid checks if the tier metric is a valid value
+func (m CustomerTierMetric) IsValid() bool {
+	switch m {
+	case CustomerTierMetricWonValue, CustomerTierMetricOrderCount:
+		return true
+	}
+	return false
+}
+
+// CustomerTierRule defines how customer tiers are calculated for a company.
+// The rule for CompanyAll is the default for customers without a company-specific rule.
+type CustomerTierRule struct {
+	CompanyID         CompanyID          `gorm:"type:varchar(50);primaryKey;column:company_id"`
+	Metric            CustomerTierMetric `gorm:"type:varchar(50);not null"`
+	WindowYears       int                `gorm:"not null;default:0;column:window_years"` // 0 = all time
+	SilverThreshold   float64            `gorm:"type:decimal(15,2);not null;column:silver_threshold"`
+	GoldThreshold     float64            `gorm:"type:decimal(15,2);not null;column:gold_threshold"`
+	PlatinumThreshold float64            `gorm:"type:decimal(15,2);not null;column:platinum_threshold"`
+	UpdatedAt         time.Time          `gorm:"not null;default:CURRENT_TIMESTAMP"`
+	UpdatedByID       string             `gorm:"type:varchar(100);column:updated_by_id"`
+	UpdatedByName     string             `gorm:"type:varchar(200);column:updated_by_name"`
+}
+
+// TableName returns the table name for CustomerTierRule
+func (CustomerTierRule) TableName() string {
+	return "customer_tier_rules"
+}
+
+// TierFor returns the tier reached by the given metric value
+func (r *CustomerTierRule) TierFor(value float64) CustomerTier {
+	switch {
+	case value >= r.PlatinumThreshold:
+		return CustomerTierPlatinum
+	case value >= r.GoldThreshold:
+		return CustomerTierGold
+	case value >= r.SilverThreshold:
+		return CustomerTierSilver
+	default:
+		return CustomerTierBronze
+	}
+}
+
+// CustomerTierChangeSource describes what caused a tier change
+type CustomerTierChangeSource string
+
+const (
+	// CustomerTierChangeCalculated is a change made by tier recalculation
+	CustomerTierChangeCalculated CustomerTierChangeSource = "calculated"
+	// CustomerTierChangeManual is a manual override, which locks the tier
+	CustomerTierChangeManual CustomerTierChangeSource = "manual"
+)
+
+// CustomerTierHistory records a change of customer tier
+type CustomerTierHistory struct {
+	ID            uuid.UUID                `gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	CustomerID    uuid.UUID                `gorm:"type:uuid;not null;index;column:customer_id"`
+	PreviousTier  CustomerTier             `gorm:"type:varchar(50);not null;column:previous_tier"`
+	NewTier       CustomerTier             `gorm:"type:varchar(50);not null;column:new_tier"`
+	Source        CustomerTierChangeSource `gorm:"type:varchar(50);not null"`
+	Metric        CustomerTierMetric       `gorm:"type:varchar(50)"`
+	MetricValue   *float64                 `gorm:"type:decimal(15,2);column:metric_value"`
+	WindowYears   *int                     `gorm:"column:window_years"`
+	RuleCompanyID *CompanyID               `gorm:"type:varchar(50);column:rule_company_id"`
+	ChangedByID   string                   `gorm:"type:varchar(100);column:changed_by_id"`
+	ChangedByName string                   `gorm:"type:varchar(200);column:changed_by_name"`
+	CreatedAt     time.Time                `gorm:"not null;default:CURRENT_TIMESTAMP;index"`
+}
+
+// TableName returns the table name for CustomerTierHistory
+func (CustomerTierHistory) TableName() string {
+	return "customer_tier_history"
+}
+
+// CalendarFeedToken is a secret token giving read access to a user's iCalendar subscription feed.
+// Only the SHA-256 hash of the token is stored.
+type CalendarFeedToken struct {
+	BaseModel
+	UserID         string     `gorm:"type:varchar(100);not null;index;column:user_id"`
+	UserName       string     `gorm:"type:varchar(200);column:user_name"`
+	TokenHash      string     `gorm:"type:varchar(64);not null;uniqueIndex;column:token_hash"`
+	LastAccessedAt *time.Time `gorm:"column:last_accessed_at"`
+	RevokedAt      *time.Time `gorm:"column:revoked_at"`
+}
+
+// TableName returns the table name for CalendarFeedToken
+func (CalendarFeedToken) TableName() string {
+	return "calendar_feed_tokens"
+}
+
+// EmailSource is where an ingested email came from
+type EmailSource string
+
+const (
+	EmailSourceUpload  EmailSource = "upload"
+	EmailSourceMailbox EmailSource = "mailbox"
+)
+
+// EmailIngestionStatus is the outcome of ingesting an email
+type EmailIngestionStatus string
+
+const (
+	// EmailIngestionMatched means the email was filed as an activity on a customer, offer or supplier
+	EmailIngestionMatched EmailIngestionStatus = "matched"
+	// EmailIngestionUnmatched means no customer, offer or supplier matched the email
+	EmailIngestionUnmatched EmailIngestionStatus = "unmatched"
+	// EmailIngestionDuplicate means the email had already been ingested; it is never stored
+	EmailIngestionDuplicate EmailIngestionStatus = "duplicate"
+)
+
+// IsValid checks if the EmailIngestionStatus is a stored status
+func (s EmailIngestionStatus) IsValid() bool {
+	switch s {
+	case EmailIngestionMatched, EmailIngestionUnmatched:
+		return true
+	}
+	return false
+}
+
+// EmailMatchMethod is how the target of an ingested email was found
+type EmailMatchMethod string
+
+const (
+	EmailMatchOfferNumber     EmailMatchMethod = "offer_number"
+	EmailMatchContact         EmailMatchMethod = "contact"
+	EmailMatchSupplierContact EmailMatchMethod = "supplier_contact"
+)
+
+// IngestedEmail records an email ingested from an upload or the drop mailbox and the activity it was
+// filed as. The Message-ID keeps the same email from being ingested twice.
+type IngestedEmail struct {
+	BaseModel
+	MessageID       string               `gorm:"type:varchar(998);not null;uniqueIndex;column:message_id"`
+	Subject         string               `gorm:"type:varchar(500)"`
+	FromAddress     string               `gorm:"type:varchar(255);column:from_address"`
+	SentAt          *time.Time           `gorm:"column:sent_at"`
+	Source          EmailSource          `gorm:"type:varchar(20);not null"`
+	Status          EmailIngestionStatus `gorm:"type:varchar(20);not null;index"`
+	MatchedBy       EmailMatchMethod     `gorm:"type:varchar(50);column:matched_by"`
+	TargetType      ActivityTargetType   `gorm:"type:varchar(50);column:target_type"`
+	TargetID        *uuid.UUID           `gorm:"type:uuid;column:target_id"`
+	TargetName      string               `gorm:"type:varchar(255);column:target_name"`
+	ActivityID      *uuid.UUID           `gorm:"type:uuid;column:activity_id"`
+	AttachmentCount int                  `gorm:"not null;default:0;column:attachment_count"`
+	CompanyID       *CompanyID           `gorm:"type:varchar(50);column:company_id"`
+	IngestedByID    string               `gorm:"type:varchar(100);column:ingested_by_id"`
+	IngestedByName  string               `gorm:"type:varchar(200);column:ingested_by_name"`
+}
+
+// TableName returns the table name for IngestedEmail
+func (IngestedEmail) TableName() string {
+	return "ingested_emails"
+}
+
+// SalesTargetPeriod is the length of a sales target period
+type SalesTargetPeriod string
+
+const (
+	SalesTargetPeriodMonth   SalesTargetPeriod = "month"
+	SalesTargetPeriodQuarter SalesTargetPeriod = "quarter"
+	SalesTargetPeriodYear    SalesTargetPeriod = "year"
+)
+
+// IsValid checks if the SalesTargetPeriod is a valid value
+func (p SalesTargetPeriod) IsValid() bool {
+	switch p {
+	case SalesTargetPeriodMonth, SalesTargetPeriodQuarter, SalesTargetPeriodYear:
+		return true
+	}
+	return false
+}
+
+// StartOf returns the first day of the period containing t
+func (p SalesTargetPeriod) StartOf(t time.Time) time.Time {
+	switch p {
+	case SalesTargetPeriodQuarter:
+		return time.Date(t.Year(), t.Month()-(t.Month()-1)%3, 1, 0, 0, 0, 0, t.Location())
+	case SalesTargetPeriodYear:
+		return time.Date(t.Year(), time.January, 1, 0, 0, 0, 0, t.Location())
+	default:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+	}
+}
+
+// EndOf returns the first day after the period starting at start
+func (p SalesTargetPeriod) EndOf(start time.Time) time.Time {
+	switch p {
+	case SalesTargetPeriodQuarter:
+		return start.AddDate(0, 3, 0)
+	case SalesTargetPeriodYear:
+		return start.AddDate(1, 0, 0)
+	default:
+		return start.AddDate(0, 1, 0)
+	}
+}
+
+// SalesTargetMetric is what a sales target is measured in
+type SalesTargetMetric string
+
+const (
+	// SalesTargetMetricWonValue is the value of won deals and of won offers without a won deal
+	SalesTargetMetricWonValue SalesTargetMetric = "won_value"
+	// SalesTargetMetricOrderIntake is the value of offers accepted as orders
+	SalesTargetMetricOrderIntake SalesTargetMetric = "order_intake"
+	// SalesTargetMetricMargin is the value minus cost of offers accepted as orders
+	SalesTargetMetricMargin SalesTargetMetric = "margin"
+)
+
+// IsValid checks if the SalesTargetMetric is a valid value
+func (m SalesTargetMetric) IsValid() bool {
+	switch m {
+	case SalesTargetMetricWonValue, SalesTargetMetricOrderIntake, SalesTargetMetricMargin:
+		return true
+	}
+	return false
+}
+
+// SalesTarget is a sales budget for a company, or a user within a company, for a month, quarter or year
+type SalesTarget struct {
+	BaseModel
+	CompanyID     CompanyID         `gorm:"type:varchar(50);not null;index;column:company_id"`
+	UserID        *string           `gorm:"type:varchar(100);index;column:user_id"` // Nil for a company-wide target
+	UserName      string            `gorm:"type:varchar(200);column:user_name"`
+	PeriodType    SalesTargetPeriod `gorm:"type:varchar(20);not null;column:period_type"`
+	PeriodStart   time.Time         `gorm:"type:date;not null;column:period_start"`
+	Metric        SalesTargetMetric `gorm:"type:varchar(50);not null"`
+	TargetValue   float64           `gorm:"type:decimal(15,2);not null;column:target_value"`
+	Notes         string            `gorm:"type:text"`
+	CreatedByID   string            `gorm:"type:varchar(100);column:created_by_id"`
+	CreatedByName string            `gorm:"type:varchar(200);column:created_by_name"`
+}
+
+// TableName returns the table name for SalesTarget
+func (SalesTarget) TableName() string {
+	return "sales_targets"
+}
+
+// PeriodEnd returns the first day after the target's period
+func (t *SalesTarget) PeriodEnd() time.Time {
+	return t.PeriodType.EndOf(t.PeriodStart)
+}
+
+// InquiryIntakeSite is a company website allowed to submit inquiries through the public intake endpoint
+type InquiryIntakeSite struct {
+	BaseModel
+	CompanyID      CompanyID      `gorm:"type:varchar(50);not null;index;column:company_id"`
+	Name           string         `gorm:"type:varchar(200);not null"`
+	SiteKey        string         `gorm:"type:varchar(64);not null;uniqueIndex;column:site_key"`
+	AllowedOrigins pq.StringArray `gorm:"type:text[];not null;default:'{}';column:allowed_origins"`
+	HoneypotField  string         `gorm:"type:varchar(100);column:honeypot_field"`
+	// ProofOfWorkBits is the number of leading zero bits required in the proof-of-work hash; 0 disables it
+	ProofOfWorkBits  int    `gorm:"type:int;not null;default:0;column:proof_of_work_bits"`
+	ChallengeSecret  string `gorm:"type:varchar(64);not null;column:challenge_secret"`
+	SendConfirmation bool   `gorm:"not null;default:true;column:send_confirmation"`
+	Enabled          bool   `gorm:"not null;default:true"`
+	CreatedByID      string `gorm:"type:varchar(100);column:created_by_id"`
+	CreatedByName    string `gorm:"type:varchar(200);column:created_by_name"`
+}
+
+// TableName returns the table name for InquiryIntakeSite
+func (InquiryIntakeSite) TableName() string {
+	return "inquiry_intake_sites"
+}
+
+// AllowsOrigin reports whether a form may be posted from the origin. Requests without an Origin
+// header (server-side posts) are allowed, as are all origins when none are configured.
+func (s *InquiryIntakeSite) AllowsOrigin(origin string) bool {
+	if origin == "" || len(s.AllowedOrigins) == 0 {
+		return true
+	}
+	for _, allowed := range s.AllowedOrigins {
+		if strings.EqualFold(strings.TrimSuffix(allowed, "/"), origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// InquirySubmission is an inquiry received through a website form
+type InquirySubmission struct {
+	BaseModel
+	SiteID             uuid.UUID  `gorm:"type:uuid;not null;index;column:site_id"`
+	CompanyID          CompanyID  `gorm:"type:varchar(50);not null;column:company_id"`
+	OfferID            *uuid.UUID `gorm:"type:uuid;index;column:offer_id"` // The draft inquiry, nil for spam
+	CustomerID         *uuid.UUID `gorm:"type:uuid;column:customer_id"`
+	MatchConfidence    *float64   `gorm:"type:decimal(5,4);column:match_confidence"`
+	Name               string     `gorm:"type:varchar(200);not null"`
+	Email              string     `gorm:"type:varchar(255);not null"`
+	Phone              string     `gorm:"type:varchar(50)"`
+	CompanyName        string     `gorm:"type:varchar(200);column:company_name"`
+	OrgNumber          string     `gorm:"type:varchar(20);column:org_number"`
+	Message            string     `gorm:"type:text"`
+	AttachmentCount    int        `gorm:"type:int;not null;default:0;column:attachment_count"`
+	Spam               bool       `gorm:"not null;default:false"`
+	PowChallenge       *string    `gorm:"type:varchar(100);column:pow_challenge"` // The solved proof-of-work challenge, so it cannot be reused
+	IPAddress          string     `gorm:"type:varchar(64);column:ip_address"`
+	UserAgent          string     `gorm:"type:varchar(500);column:user_agent"`
+	ConfirmationSentAt *time.Time `gorm:"column:confirmation_sent_at"`
+}
+
+// TableName returns the table name for InquirySubmission
+func (InquirySubmission) TableName() string {
+	return "inquiry_submissions"
+}
+
+// InquiryAssignmentRuleType is the kind of condition an inquiry assignment rule matches on
+type InquiryAssignmentRuleType string
+
+const (
+	// InquiryAssignmentRoundRobin matches every inquiry and rotates among the assignees
+	InquiryAssignmentRoundRobin InquiryAssignmentRuleType = "round_robin"
+	// InquiryAssignmentIndustry matches inquiries for customers in one of the industries
+	InquiryAssignmentIndustry InquiryAssignmentRuleType = "industry"
+	// InquiryAssignmentPostalRegion matches inquiries for customers whose postal code has one of the
+	// prefixes or lies in one of the counties
+	InquiryAssignmentPostalRegion InquiryAssignmentRuleType = "postal_region"
+	// InquiryAssignmentValue matches inquiries whose estimated value lies within the value range
+	InquiryAssignmentValue InquiryAssignmentRuleType = "value"
+)
+
+// IsValid checks if the rule type is valid
+func (t InquiryAssignmentRuleType) IsValid() bool {
+	switch t {
+	case InquiryAssignmentRoundRobin, InquiryAssignmentIndustry, InquiryAssignmentPostalRegion, InquiryAssignmentValue:
+		return true
+	}
+	return false
+}
+
+// InquiryAssignmentRule assigns new inquiries of a company to one of a set of users in turn.
+// Rules are tried in ascending priority; when none matches, the company's default responsible is used.
+type InquiryAssignmentRule struct {
+	BaseModel
+	CompanyID          CompanyID                 `gorm:"type:varchar(50);not null;index;column:company_id"`
+	Name               string                    `gorm:"type:varchar(200);not null"`
+	RuleType           InquiryAssignmentRuleType `gorm:"type:varchar(50);not null;column:rule_type"`
+	Priority           int                       `gorm:"type:int;not null;default:100"`
+	Enabled            bool                      `gorm:"not null;default:true"`
+	Industries         pq.StringArray            `gorm:"type:text[];not null;default:'{}'"`
+	PostalCodePrefixes pq.StringArray            `gorm:"type:text[];not null;default:'{}';column:postal_code_prefixes"`
+	Counties           pq.StringArray            `gorm:"type:text[];not null;default:'{}'"`
+	MinValue           *float64                  `gorm:"type:decimal(15,2);column:min_value"`
+	MaxValue           *float64                  `gorm:"type:decimal(15,2);column:max_value"`
+	AssigneeIDs        pq.StringArray            `gorm:"type:text[];not null;default:'{}';column:assignee_ids"`
+	// NextIndex is the round-robin cursor into AssigneeIDs
+	NextIndex     int    `gorm:"type:int;not null;default:0;column:next_index"`
+	CreatedByID   string `gorm:"type:varchar(100);column:created_by_id"`
+	CreatedByName string `gorm:"type:varchar(200);column:created_by_name"`
+}
+
+// TableName returns the table name for InquiryAssignmentRule
+func (InquiryAssignmentRule) TableName() string {
+	return "inquiry_assignment_rules"
+}
+
+// ProjectTaskKind distinguishes tasks, which take time, from milestones, which are reached on a date
+type ProjectTaskKind string
+
+const (
+	// ProjectTaskKindTask is work with a start and end date
+	ProjectTaskKindTask ProjectTaskKind = "task"
+	// ProjectTaskKindMilestone is a point in the schedule, reached when its tasks and dependencies are finished
+	ProjectTaskKindMilestone ProjectTaskKind = "milestone"
+)
+
+// IsValid checks if the task kind is valid
+func (k ProjectTaskKind) IsValid() bool {
+	switch k {
+	case ProjectTaskKindTask, ProjectTaskKindMilestone:
+		return true
+	}
+	return false
+}
+
+// ProjectTask is a task or milestone in a project's execution schedule
+type ProjectTask struct {
+	BaseModel
+	ProjectID   uuid.UUID       `gorm:"type:uuid;not null;index;column:project_id"`
+	Kind        ProjectTaskKind `gorm:"type:varchar(20);not null;default:'task'"`
+	Name        string          `gorm:"type:varchar(200);not null"`
+	Description string          `gorm:"type:text"`
+	// MilestoneID is the milestone the task is part of
+	MilestoneID *uuid.UUID `gorm:"type:uuid;column:milestone_id"`
+	// OfferID is the offer whose end date a milestone must be reached by; nil uses the project's won offer
+	OfferID         *uuid.UUID `gorm:"type:uuid;column:offer_id"`
+	StartDate       time.Time  `gorm:"type:date;not null;column:start_date"`
+	EndDate         time.Time  `gorm:"type:date;not null;column:end_date"` // Same as StartDate for milestones
+	AssigneeID      string     `gorm:"type:varchar(100);column:assignee_id"`
+	AssigneeName    string     `gorm:"type:varchar(200);column:assignee_name"`
+	PercentComplete int        `gorm:"type:int;not null;default:0;column:percent_complete"`
+	SortOrder       int        `gorm:"type:int;not null;default:0;column:sort_order"`
+	CreatedByID     string     `gorm:"type:varchar(100);column:created_by_id"`
+	CreatedByName   string     `gorm:"type:varchar(200);column:created_by_name"`
+	UpdatedByID     string     `gorm:"type:varchar(100);column:updated_by_id"`
+	UpdatedByName   string     `gorm:"type:varchar(200);column:updated_by_name"`
+}
+
+// TableName returns the table name for ProjectTask
+func (ProjectTask) TableName() string {
+	return "project_tasks"
+}
+
+// ProjectTaskDependency is a finish-to-start dependency: the task cannot start before DependsOnID is finished
+type ProjectTaskDependency struct {
+	TaskID      uuid.UUID `gorm:"type:uuid;primaryKey;column:task_id"`
+	DependsOnID uuid.UUID `gorm:"type:uuid;primaryKey;column:depends_on_id"`
+}
+
+// TableName returns the table name for ProjectTaskDependency
+func (ProjectTaskDependency) TableName() string {
+	return "project_task_dependencies"
+}
+
+// StandardHoursPerWeek is the normal Norwegian working week, used for users without a UserCapacity
+const StandardHoursPerWeek = 37.5
+
+// UserCapacity is the working hours per week of a user who does not work the standard week
+type UserCapacity struct {
+	UserID        string    `gorm:"type:varchar(100);primaryKey;column:user_id"`
+	UserName      string    `gorm:"type:varchar(200);column:user_name"`
+	HoursPerWeek  float64   `gorm:"type:decimal(5,2);not null;column:hours_per_week"`
+	UpdatedByID   string    `gorm:"type:varchar(100);column:updated_by_id"`
+	UpdatedByName string    `gorm:"type:varchar(200);column:updated_by_name"`
+	CreatedAt     time.Time `gorm:"not null;default:CURRENT_TIMESTAMP"`
+	UpdatedAt     time.Time `gorm:"not null;default:CURRENT_TIMESTAMP"`
+}
+
+// TableName returns the table name for UserCapacity
+func (UserCapacity) TableName() string {
+	return "user_capacities"
+}
+
+// UserAbsenceKind is the reason a user is away
+type UserAbsenceKind string
+
+const (
+	UserAbsenceKindVacation  UserAbsenceKind = "vacation"
+	UserAbsenceKindSickLeave UserAbsenceKind = "sick_leave"
+	UserAbsenceKindLeave     UserAbsenceKind = "leave"
+	UserAbsenceKindOther     UserAbsenceKind = "other"
+)
+
+// IsValid checks if the absence kind is valid
+func (k UserAbsenceKind) IsValid() bool {
+	switch k {
+	case UserAbsenceKindVacation, UserAbsenceKindSickLeave, UserAbsenceKindLeave, UserAbsenceKindOther:
+		return true
+	}
+	return false
+}
+
+// UserAbsence is a period a user is not available for project work, fully or for a share of each working day
+type UserAbsence struct {
+	BaseModel
+	UserID    string          `gorm:"type:varchar(100);not null;index;column:user_id"`
+	UserName  string          `gorm:"type:varchar(200);column:user_name"`
+	Kind      UserAbsenceKind `gorm:"type:varchar(20);not null"`
+	StartDate time.Time       `gorm:"type:date;not null;column:start_date"`
+	EndDate   time.Time       `gorm:"type:date;not null;column:end_date"` // Last day of the absence
+	// Percent is the share of each working day the user is away, e.g. 50 for graded sick leave
+	Percent       int    `gorm:"type:int;not null;default:100"`
+	Notes         string `gorm:"type:text"`
+	CreatedByID   string `gorm:"type:varchar(100);column:created_by_id"`
+	CreatedByName string `gorm:"type:varchar(200);column:created_by_name"`
+}
+
+// TableName returns the table name for UserAbsence
+func (UserAbsence) TableName() string {
+	return "user_absences"
+}
+
+// ResourceAllocation books a user on an offer in order or a project for a date range, either in hours per
+// full working week or as a percentage of the user's weekly hours
+type ResourceAllocation struct {
+	BaseModel
+	CompanyID CompanyID  `gorm:"type:varchar(50);not null;index;column:company_id"`
+	UserID    string     `gorm:"type:varchar(100);not null;index;column:user_id"`
+	UserName  string     `gorm:"type:varchar(200);column:user_name"`
+	OfferID   *uuid.UUID `gorm:"type:uuid;column:offer_id"`
+	Offer     *Offer     `gorm:"foreignKey:OfferID"`
+	ProjectID *uuid.UUID `gorm:"type:uuid;column:project_id"`
+	Project   *Project   `gorm:"foreignKey:ProjectID"`
+	StartDate time.Time  `gorm:"type:date;not null;column:start_date"`
+	EndDate   time.Time  `gorm:"type:date;not null;column:end_date"` // Last day of the allocation
+	// HoursPerWeek and Percent are mutually exclusive; exactly one is set
+	HoursPerWeek  *float64 `gorm:"type:decimal(5,2);column:hours_per_week"`
+	Percent       *float64 `gorm:"type:decimal(5,2)"`
+	Notes         string   `gorm:"type:text"`
+	CreatedByID   string   `gorm:"type:varchar(100);column:created_by_id"`
+	CreatedByName string   `gorm:"type:varchar(200);column:created_by_name"`
+	UpdatedByID   string   `gorm:"type:varchar(100);column:updated_by_id"`
+	UpdatedByName string   `gorm:"type:varchar(200);column:updated_by_name"`
+}
+
+// TableName returns the table name for ResourceAllocation
+func (ResourceAllocation) TableName() string {
+	return "resource_allocations"
+}
@@ -0,0 +1,330 @@
+// Package eml parses RFC 822 (RFC 5322) email messages as saved by Outlook, Thunderbird and maildir
+// mailboxes (.eml files), including MIME multipart bodies, encoded headers and attachments.
+package eml
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// MaxAttachments is the maximum number of attachments read from a message
+const MaxAttachments = 50
+
+// maxDepth bounds the nesting of multipart bodies
+const maxDepth = 10
+
+var (
+	// ErrInvalidMessage is returned when the input is not an email message
+	ErrInvalidMessage = errors.New("invalid email message")
+
+	// ErrTooManyAttachments is returned when a message has more than MaxAttachments attachments
+	ErrTooManyAttachments = fmt.Errorf("email message has more than %d attachments", MaxAttachments)
+)
+
+// Address is a mailbox in an address header
+type Address struct {
+	Name  string
+	Email string // Lower case
+}
+
+// String returns the address as "Name <email>", or the email alone without a name
+func (a Address) String() string {
+	if a.Name == "" {
+		return a.Email
+	}
+	return a.Name + " <" + a.Email + ">"
+}
+
+// Attachment is a file attached to a message
+type Attachment struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+// Message is the parsed content of an email
+type Message struct {
+	// MessageID is the Message-ID header without angle brackets; empty if the message has none
+	MessageID   string
+	Subject     string
+	From        *Address
+	To          []Address
+	Cc          []Address
+	Date        time.Time // Zero if the message has no valid Date header
+	Text        string    // Plain text body; converted from HTML if the message has no plain text part
+	Attachments []Attachment
+}
+
+// Addresses returns the sender followed by the recipients, without duplicates
+func (m *Message) Addresses() []Address {
+	seen := make(map[string]bool)
+	var addresses []Address
+	add := func(a Address) {
+		if a.Email != "" && !seen[a.Email] {
+			seen[a.Email] = true
+			addresses = append(addresses, a)
+		}
+	}
+	if m.From != nil {
+		add(*m.From)
+	}
+	for _, a := range m.To {
+		add(a)
+	}
+	for _, a := range m.Cc {
+		add(a)
+	}
+	return addresses
+}
+
+// Parse reads an email message
+func Parse(r io.Reader) (*Message, error) {
+	raw, err := mail.ReadMessage(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidMessage, err)
+	}
+	if raw.Header.Get("From") == "" && raw.Header.Get("Subject") == "" && raw.Header.Get("Message-Id") == "" {
+		return nil, fmt.Errorf("%w: no message headers", ErrInvalidMessage)
+	}
+
+	p := &parser{
+		message: &Message{
+			MessageID: strings.Trim(strings.TrimSpace(raw.Header.Get("Message-Id")), "<>"),
+			Subject:   decodeHeader(raw.Header.Get("Subject")),
+			To:        parseAddressList(raw.Header.Get("To")),
+			Cc:        parseAddressList(raw.Header.Get("Cc")),
+		},
+	}
+	if from := parseAddressList(raw.Header.Get("From")); len(from) > 0 {
+		p.message.From = &from[0]
+	}
+	if date, err := raw.Header.Date(); err == nil {
+		p.message.Date = date
+	}
+
+	if err := p.part(textproto.MIMEHeader(raw.Header), raw.Body, 0); err != nil {
+		return nil, err
+	}
+	if p.message.Text == "" && p.html != "" {
+		p.message.Text = htmlToText(p.html)
+	}
+	p.message.Text = strings.TrimSpace(strings.ReplaceAll(p.message.Text, "\r\n", "\n"))
+	return p.message, nil
+}
+
+// parser collects the body and attachments while walking the MIME tree
+type parser struct {
+	message *Message
+	html    string
+}
+
+// part reads a MIME part and its children
+func (p *parser) part(header textproto.MIMEHeader, body io.Reader, depth int) error {
+	if depth > maxDepth {
+		return fmt.Errorf("%w: MIME parts nested too deeply", ErrInvalidMessage)
+	}
+
+	mediaType, params, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		mediaType, params = "text/plain", map[string]string{}
+	}
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		boundary := params["boundary"]
+		if boundary == "" {
+			return fmt.Errorf("%w: multipart body without boundary", ErrInvalidMessage)
+		}
+		reader := multipart.NewReader(body, boundary)
+		for {
+			child, err := reader.NextRawPart()
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("%w: %v", ErrInvalidMessage, err)
+			}
+			if err := p.part(child.Header, child, depth+1); err != nil {
+				return err
+			}
+		}
+	}
+
+	data, err := io.ReadAll(decodeTransferEncoding(header.Get("Content-Transfer-Encoding"), body))
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidMessage, err)
+	}
+
+	disposition, dispositionParams, _ := mime.ParseMediaType(header.Get("Content-Disposition"))
+	filename := decodeHeader(dispositionParams["filename"])
+	if filename == "" {
+		filename = decodeHeader(params["name"])
+	}
+
+	// Inline parts without a file name are the body; inline images (signatures, logos) are skipped
+	isAttachment := disposition == "attachment" ||
+		(disposition == "" && filename != "" && !strings.HasPrefix(mediaType, "text/"))
+	if !isAttachment {
+		switch {
+		case disposition == "inline" && filename != "":
+			return nil
+		case mediaType == "text/plain" && p.message.Text == "":
+			p.message.Text = decodeCharset(params["charset"], data)
+		case mediaType == "text/html" && p.html == "":
+			p.html = decodeCharset(params["charset"], data)
+		}
+		return nil
+	}
+
+	if len(p.message.Attachments) >= MaxAttachments {
+		return ErrTooManyAttachments
+	}
+	if filename == "" {
+		filename = "vedlegg"
+		if mediaType == "message/rfc822" {
+			filename = "melding.eml"
+		}
+	}
+	p.message.Attachments = append(p.message.Attachments, Attachment{
+		Filename:    filename,
+		ContentType: mediaType,
+		Data:        data,
+	})
+	return nil
+}
+
+// decodeTransferEncoding wraps a part body with the decoder of its Content-Transfer-Encoding
+func decodeTransferEncoding(encoding string, body io.Reader) io.Reader {
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "base64":
+		// The decoder skips line breaks but not other whitespace some mailers add
+		return base64.NewDecoder(base64.StdEncoding, &whitespaceStripper{r: body})
+	case "quoted-printable":
+		return quotedprintable.NewReader(body)
+	default:
+		return body
+	}
+}
+
+// whitespaceStripper removes spaces and tabs from a base64 stream
+type whitespaceStripper struct {
+	r io.Reader
+}
+
+func (w *whitespaceStripper) Read(b []byte) (int, error) {
+	n, err := w.r.Read(b)
+	kept := 0
+	for _, c := range b[:n] {
+		if c != ' ' && c != '\t' {
+			b[kept] = c
+			kept++
+		}
+	}
+	return kept, err
+}
+
+// wordDecoder decodes RFC 2047 encoded words in headers
+var wordDecoder = &mime.WordDecoder{
+	CharsetReader: func(charset string, input io.Reader) (io.Reader, error) {
+		data, err := io.ReadAll(input)
+		if err != nil {
+			return nil, err
+		}
+		return strings.NewReader(decodeCharset(charset, data)), nil
+	},
+}
+
+// decodeHeader decodes RFC 2047 encoded words in a header value
+func decodeHeader(value string) string {
+	decoded, err := wordDecoder.DecodeHeader(value)
+	if err != nil {
+		return strings.TrimSpace(value)
+	}
+	return strings.TrimSpace(decoded)
+}
+
+// parseAddressList parses an address header, skipping it if it is malformed
+func parseAddressList(value string) []Address {
+	if strings.TrimSpace(value) == "" {
+		return nil
+	}
+	parser := mail.AddressParser{WordDecoder: wordDecoder}
+	list, err := parser.ParseList(value)
+	if err != nil {
+		return nil
+	}
+	addresses := make([]Address, 0, len(list))
+	for _, a := range list {
+		addresses = append(addresses, Address{Name: a.Name, Email: strings.ToLower(a.Address)})
+	}
+	return addresses
+}
+
+// windows1252 maps the bytes 0x80-0x9F of Windows-1252 that differ from ISO-8859-1
+var windows1252 = map[byte]rune{
+	0x80: '€', 0x82: '‚', 0x83: 'ƒ', 0x84: '„', 0x85: '…', 0x86: '†', 0x87: '‡', 0x88: 'ˆ',
+	0x89: '‰', 0x8A: 'Š', 0x8B: '‹', 0x8C: 'Œ', 0x8E: 'Ž', 0x91: '‘', 0x92: '’', 0x93: '“',
+	0x94: '”', 0x95: '•', 0x96: '–', 0x97: '—', 0x98: '˜', 0x99: '™', 0x9A: 'š', 0x9B: '›',
+	0x9C: 'œ', 0x9E: 'ž', 0x9F: 'Ÿ',
+}
+
+// decodeCharset converts text in the charsets used by Norwegian mail clients to UTF-8. Unknown
+// charsets are read as UTF-8 when valid and as Windows-1252 otherwise.
+func decodeCharset(charset string, data []byte) string {
+	switch strings.ToLower(strings.TrimSpace(charset)) {
+	case "", "utf-8", "utf8", "us-ascii", "ascii":
+		if utf8.Valid(data) {
+			return string(data)
+		}
+	case "iso-8859-1", "latin1", "iso-8859-15", "windows-1252", "cp1252":
+	default:
+		if utf8.Valid(data) {
+			return string(data)
+		}
+	}
+
+	var b strings.Builder
+	b.Grow(len(data))
+	for _, c := range data {
+		if r, ok := windows1252[c]; ok {
+			b.WriteRune(r)
+		} else {
+			b.WriteRune(rune(c))
+		}
+	}
+	return b.String()
+}
+
+var (
+	htmlHiddenBlocks = regexp.MustCompile(`(?is)<(style|script|head)[^>]*>.*?</(style|script|head)>`)
+	htmlLineBreaks   = regexp.MustCompile(`(?i)<br\s*/?>|</(p|div|tr|li|h[1-6])>`)
+	htmlTags         = regexp.MustCompile(`(?s)<[^>]*>`)
+	blankLines       = regexp.MustCompile(`\n\s*\n\s*\n+`)
+	spaceRuns        = regexp.MustCompile(`[ \t\x{00A0}]+`)
+)
+
+// htmlToText converts an HTML body to plain text
+func htmlToText(value string) string {
+	value = htmlHiddenBlocks.ReplaceAllString(value, "")
+	value = htmlLineBreaks.ReplaceAllString(value, "\n")
+	value = htmlTags.ReplaceAllString(value, "")
+	value = html.UnescapeString(value)
+	value = spaceRuns.ReplaceAllString(value, " ")
+
+	lines := strings.Split(value, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(line)
+	}
+	return strings.TrimSpace(blankLines.ReplaceAllString(strings.Join(lines, "\n"), "\n\n"))
+}
@@ -0,0 +1,139 @@
+package export
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// csvWriter writes rows as CSV. A UTF-8 byte order mark is written first so Excel
+// detects the encoding of Norwegian characters.
+type csvWriter struct {
+	w       *csv.Writer
+	columns []Column
+	opts    Options
+}
+
+func newCSVWriter(w io.Writer, columns []Column, opts Options) (*csvWriter, error) {
+	if _, err := io.WriteString(w, "\ufeff"); err != nil {
+		return nil, err
+	}
+
+	cw := &csvWriter{w: csv.NewWriter(w), columns: columns, opts: opts}
+	if opts.Norwegian {
+		cw.w.Comma = ';'
+	}
+
+	headers := make([]string, len(columns))
+	for i, column := range columns {
+		headers[i] = column.Header
+	}
+	if err := cw.w.Write(headers); err != nil {
+		return nil, err
+	}
+	return cw, nil
+}
+
+// WriteRow writes a row and flushes it to the output
+func (cw *csvWriter) WriteRow(values []interface{}) error {
+	record := make([]string, len(cw.columns))
+	for i, column := range cw.columns {
+		if i < len(values) {
+			record[i] = formatText(values[i], column.Kind, cw.opts)
+		}
+	}
+	if err := cw.w.Write(record); err != nil {
+		return err
+	}
+	cw.w.Flush()
+	return cw.w.Error()
+}
+
+// Close flushes buffered data
+func (cw *csvWriter) Close() error {
+	cw.w.Flush()
+	return cw.w.Error()
+}
+
+// formatText formats a value as text for the column kind
+func formatText(value interface{}, kind Kind, opts Options) string {
+	if value == nil {
+		return ""
+	}
+
+	switch kind {
+	case KindNumber, KindInteger:
+		number, ok := toFloat(value)
+		if !ok {
+			return fmt.Sprint(value)
+		}
+		decimals := 2
+		if kind == KindInteger {
+			decimals = 0
+		}
+		text := strconv.FormatFloat(number, 'f', decimals, 64)
+		if opts.Norwegian {
+			text = strings.Replace(text, ".", ",", 1)
+		}
+		return text
+	case KindDate, KindDateTime:
+		t, ok := value.(time.Time)
+		if !ok {
+			return fmt.Sprint(value)
+		}
+		if t.IsZero() {
+			return ""
+		}
+		return localTime(t, opts).Format(timeLayout(kind, opts))
+	case KindBool:
+		b, ok := value.(bool)
+		if !ok {
+			return fmt.Sprint(value)
+		}
+		switch {
+		case b && opts.Norwegian:
+			return "Ja"
+		case b:
+			return "Yes"
+		case opts.Norwegian:
+			return "Nei"
+		default:
+			return "No"
+		}
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+// timeLayout returns the Go layout for dates and timestamps
+func timeLayout(kind Kind, opts Options) string {
+	switch {
+	case kind == KindDate && opts.Norwegian:
+		return "02.01.2006"
+	case kind == KindDate:
+		return "2006-01-02"
+	case opts.Norwegian:
+		return "02.01.2006 15:04"
+	default:
+		return "2006-01-02 15:04"
+	}
+}
+
+// toFloat converts numeric values to float64
+func toFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	default:
+		return 0, false
+	}
+}
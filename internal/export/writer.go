@@ -0,0 +1,112 @@
+// Package export writes tabular list exports as CSV or XLSX.
+// Rows are written one at a time so large exports can be streamed to the client.
+package export
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"time"
+	_ "time/tzdata" // Europe/Oslo must be available in minimal container images
+)
+
+// Format is the file format of an export
+type Format string
+
+const (
+	FormatCSV  Format = "csv"
+	FormatXLSX Format = "xlsx"
+)
+
+// ErrUnsupportedFormat is returned for formats other than csv and xlsx
+var ErrUnsupportedFormat = errors.New("unsupported export format: must be csv or xlsx")
+
+// ParseFormat parses a format query value. An empty value defaults to CSV.
+func ParseFormat(value string) (Format, error) {
+	switch Format(strings.ToLower(strings.TrimSpace(value))) {
+	case "", FormatCSV:
+		return FormatCSV, nil
+	case FormatXLSX:
+		return FormatXLSX, nil
+	default:
+		return "", ErrUnsupportedFormat
+	}
+}
+
+// ContentType returns the MIME type of the format
+func (f Format) ContentType() string {
+	if f == FormatXLSX {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// Extension returns the file extension of the format, including the dot
+func (f Format) Extension() string {
+	return "." + string(f)
+}
+
+// Kind determines how the values of a column are formatted
+type Kind int
+
+const (
+	KindText     Kind = iota // string
+	KindNumber               // float64 or int, two decimals
+	KindInteger              // int or float64, no decimals
+	KindDate                 // time.Time, date only
+	KindDateTime             // time.Time, date and time
+	KindBool                 // bool
+)
+
+// Column is a column in an export
+type Column struct {
+	Header string
+	Kind   Kind
+}
+
+// Options control the format of an export
+type Options struct {
+	Format Format
+	// Norwegian formats numbers with decimal comma, dates as dd.mm.yyyy in Europe/Oslo time,
+	// booleans as Ja/Nei, and uses semicolon as CSV separator (what Excel expects in Norwegian locale)
+	Norwegian bool
+}
+
+// Writer writes an export row by row.
+// Values passed to WriteRow must match the column kinds; nil values are written as empty cells.
+type Writer interface {
+	WriteRow(values []interface{}) error
+	// Close flushes remaining data. XLSX files are only written to the output on Close.
+	Close() error
+}
+
+// NewWriter creates a writer for the given format and writes the header row
+func NewWriter(w io.Writer, columns []Column, opts Options) (Writer, error) {
+	switch opts.Format {
+	case FormatCSV, "":
+		return newCSVWriter(w, columns, opts)
+	case FormatXLSX:
+		return newXLSXWriter(w, columns, opts)
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+}
+
+// oslo is the time zone used for Norwegian formatting
+var oslo = mustLoadLocation("Europe/Oslo")
+
+func mustLoadLocation(name string) *time.Location {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		panic(err)
+	}
+	return loc
+}
+
+// localTime converts a timestamp to the time zone used for the export
+func localTime(t time.Time, opts Options) time.Time {
+	if opts.Norwegian {
+		return t.In(oslo)
+	}
+	return t.UTC()
+}
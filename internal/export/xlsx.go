@@ -0,0 +1,139 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// sheetName is the name of the single worksheet in XLSX exports
+const sheetName = "Export"
+
+// xlsxWriter writes rows to a worksheet with excelize's stream writer, which keeps
+// memory bounded for large exports. Numbers and dates are written as native cell values
+// with a display format, so they stay sortable and summable in Excel.
+type xlsxWriter struct {
+	out     io.Writer
+	file    *excelize.File
+	stream  *excelize.StreamWriter
+	columns []Column
+	styles  map[Kind]int
+	opts    Options
+	row     int
+}
+
+func newXLSXWriter(w io.Writer, columns []Column, opts Options) (*xlsxWriter, error) {
+	file := excelize.NewFile()
+	if err := file.SetSheetName(file.GetSheetName(0), sheetName); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	stream, err := file.NewStreamWriter(sheetName)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	xw := &xlsxWriter{out: w, file: file, stream: stream, columns: columns, opts: opts, styles: make(map[Kind]int), row: 1}
+	if err := xw.createStyles(); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	headerStyle, err := file.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	headers := make([]interface{}, len(columns))
+	for i, column := range columns {
+		headers[i] = excelize.Cell{StyleID: headerStyle, Value: column.Header}
+	}
+	if err := xw.writeRow(headers); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return xw, nil
+}
+
+// createStyles creates the number formats for numeric and date columns
+func (xw *xlsxWriter) createStyles() error {
+	formats := map[Kind]string{
+		KindNumber:   "#,##0.00",
+		KindInteger:  "0",
+		KindDate:     "yyyy-mm-dd",
+		KindDateTime: "yyyy-mm-dd hh:mm",
+	}
+	if xw.opts.Norwegian {
+		formats[KindDate] = "dd.mm.yyyy"
+		formats[KindDateTime] = "dd.mm.yyyy hh:mm"
+	}
+
+	for kind, format := range formats {
+		numFmt := format
+		styleID, err := xw.file.NewStyle(&excelize.Style{CustomNumFmt: &numFmt})
+		if err != nil {
+			return err
+		}
+		xw.styles[kind] = styleID
+	}
+	return nil
+}
+
+// WriteRow adds a row to the worksheet
+func (xw *xlsxWriter) WriteRow(values []interface{}) error {
+	cells := make([]interface{}, len(xw.columns))
+	for i, column := range xw.columns {
+		if i >= len(values) || values[i] == nil {
+			continue
+		}
+		cells[i] = xw.cell(values[i], column.Kind)
+	}
+	return xw.writeRow(cells)
+}
+
+// cell converts a value to a styled cell
+func (xw *xlsxWriter) cell(value interface{}, kind Kind) interface{} {
+	switch kind {
+	case KindNumber, KindInteger:
+		if number, ok := toFloat(value); ok {
+			return excelize.Cell{StyleID: xw.styles[kind], Value: number}
+		}
+	case KindDate, KindDateTime:
+		if t, ok := value.(time.Time); ok {
+			if t.IsZero() {
+				return nil
+			}
+			// Excel has no time zones; write the wall clock time of the export time zone
+			local := localTime(t, xw.opts)
+			wall := time.Date(local.Year(), local.Month(), local.Day(), local.Hour(), local.Minute(), local.Second(), 0, time.UTC)
+			return excelize.Cell{StyleID: xw.styles[kind], Value: wall}
+		}
+	case KindBool:
+		return formatText(value, kind, xw.opts)
+	}
+	return fmt.Sprint(value)
+}
+
+func (xw *xlsxWriter) writeRow(cells []interface{}) error {
+	cell, err := excelize.CoordinatesToCellName(1, xw.row)
+	if err != nil {
+		return err
+	}
+	xw.row++
+	return xw.stream.SetRow(cell, cells)
+}
+
+// Close finishes the worksheet and writes the file to the output
+func (xw *xlsxWriter) Close() error {
+	defer xw.file.Close()
+
+	if err := xw.stream.Flush(); err != nil {
+		return err
+	}
+	_, err := xw.file.WriteTo(xw.out)
+	return err
+}
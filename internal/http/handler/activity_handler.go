@@ -64,18 +64,45 @@ func (h *ActivityHandler) List(w http.ResponseWriter, r *http.Request) {
 		pageSize = 200
 	}
 
-	// Build filters from query params
+	filters, err := parseActivityFilters(r)
+	if err != nil {
+		respondJSON(w, http.StatusBadRequest, domain.ErrorResponse{
+			Error:   "Bad Request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	result, err := h.activityService.List(r.Context(), filters, page, pageSize)
+	if err != nil {
+		if errors.Is(err, service.ErrUserContextRequired) {
+			respondJSON(w, http.StatusUnauthorized, domain.ErrorResponse{
+				Error:   "Unauthorized",
+				Message: "Authentication required",
+			})
+			return
+		}
+		h.logger.Error("failed to list activities", zap.Error(err))
+		respondJSON(w, http.StatusInternalServerError, domain.ErrorResponse{
+			Error:   "Internal Server Error",
+			Message: "Failed to list activities",
+		})
+		return
+	}
+
+	respondJSON(w, http.StatusOK, result)
+}
+
+// parseActivityFilters parses the activity list filters from the query string.
+// Shared by the list and export endpoints so both return the same activities.
+func parseActivityFilters(r *http.Request) (*domain.ActivityFilters, error) {
 	filters := &domain.ActivityFilters{}
 
 	// Activity type filter
 	if activityType := r.URL.Query().Get("type"); activityType != "" {
 		at := domain.ActivityType(activityType)
 		if !at.IsValid() {
-			respondJSON(w, http.StatusBadRequest, domain.ErrorResponse{
-				Error:   "Bad Request",
-				Message: "Invalid activity type. Valid values: meeting, call, email, task, note, system",
-			})
-			return
+			return nil, errors.New("Invalid activity type. Valid values: meeting, call, email, task, note, system")
 		}
 		filters.ActivityType = &at
 	}
@@ -84,11 +111,7 @@ func (h *ActivityHandler) List(w http.ResponseWriter, r *http.Request) {
 	if status := r.URL.Query().Get("status"); status != "" {
 		s := domain.ActivityStatus(status)
 		if !s.IsValid() {
-			respondJSON(w, http.StatusBadRequest, domain.ErrorResponse{
-				Error:   "Bad Request",
-				Message: "Invalid status. Valid values: planned, in_progress, completed, cancelled",
-			})
-			return
+			return nil, errors.New("Invalid status. Valid values: planned, in_progress, completed, cancelled")
 		}
 		filters.Status = &s
 	}
@@ -103,11 +126,7 @@ func (h *ActivityHandler) List(w http.ResponseWriter, r *http.Request) {
 	if targetIDStr := r.URL.Query().Get("targetId"); targetIDStr != "" {
 		targetID, err := uuid.Parse(targetIDStr)
 		if err != nil {
-			respondJSON(w, http.StatusBadRequest, domain.ErrorResponse{
-				Error:   "Bad Request",
-				Message: "Invalid targetId format, must be a valid UUID",
-			})
-			return
+			return nil, errors.New("Invalid targetId format, must be a valid UUID")
 		}
 		filters.TargetID = &targetID
 	}
@@ -121,11 +140,7 @@ func (h *ActivityHandler) List(w http.ResponseWriter, r *http.Request) {
 	if fromStr := r.URL.Query().Get("from"); fromStr != "" {
 		fromDate, err := time.Parse("2006-01-02", fromStr)
 		if err != nil {
-			respondJSON(w, http.StatusBadRequest, domain.ErrorResponse{
-				Error:   "Bad Request",
-				Message: "Invalid 'from' format, expected YYYY-MM-DD",
-			})
-			return
+			return nil, errors.New("Invalid 'from' format, expected YYYY-MM-DD")
 		}
 		// Set to start of day in UTC
 		fromDate = time.Date(fromDate.Year(), fromDate.Month(), fromDate.Day(), 0, 0, 0, 0, time.UTC)
@@ -136,35 +151,14 @@ func (h *ActivityHandler) List(w http.ResponseWriter, r *http.Request) {
 	if toStr := r.URL.Query().Get("to"); toStr != "" {
 		toDate, err := time.Parse("2006-01-02", toStr)
 		if err != nil {
-			respondJSON(w, http.StatusBadRequest, domain.ErrorResponse{
-				Error:   "Bad Request",
-				Message: "Invalid 'to' format, expected YYYY-MM-DD",
-			})
-			return
+			return nil, errors.New("Invalid 'to' format, expected YYYY-MM-DD")
 		}
 		// Set to end of day in UTC for inclusive filtering
 		toDate = time.Date(toDate.Year(), toDate.Month(), toDate.Day(), 23, 59, 59, 999999999, time.UTC)
 		filters.OccurredTo = &toDate
 	}
 
-	result, err := h.activityService.List(r.Context(), filters, page, pageSize)
-	if err != nil {
-		if errors.Is(err, service.ErrUserContextRequired) {
-			respondJSON(w, http.StatusUnauthorized, domain.ErrorResponse{
-				Error:   "Unauthorized",
-				Message: "Authentication required",
-			})
-			return
-		}
-		h.logger.Error("failed to list activities", zap.Error(err))
-		respondJSON(w, http.StatusInternalServerError, domain.ErrorResponse{
-			Error:   "Internal Server Error",
-			Message: "Failed to list activities",
-		})
-		return
-	}
-
-	respondJSON(w, http.StatusOK, result)
+	return filters, nil
 }
 
 // GetMyTasks godoc
@@ -416,6 +410,9 @@ func (h *ActivityHandler) Create(w http.ResponseWriter, r *http.Request) {
 			})
 			return
 		}
+		if respondRecurrenceError(w, err) {
+			return
+		}
 		h.logger.Error("failed to create activity", zap.Error(err))
 		respondJSON(w, http.StatusInternalServerError, domain.ErrorResponse{
 			Error:   "Internal Server Error",
@@ -429,7 +426,7 @@ func (h *ActivityHandler) Create(w http.ResponseWriter, r *http.Request) {
 
 // Update godoc
 // @Summary Update activity
-// @Description Update an existing activity
+// @Description Update an existing activity. For an occurrence of a recurring series, recurrenceScope selects whether only this occurrence (default), this and the following, or all occurrences are changed. With scope following the series is split at the occurrence.
 // @Tags Activities
 // @Accept json
 // @Produce json
@@ -501,6 +498,9 @@ func (h *ActivityHandler) Update(w http.ResponseWriter, r *http.Request) {
 			})
 			return
 		}
+		if respondRecurrenceError(w, err) {
+			return
+		}
 		h.logger.Error("failed to update activity", zap.Error(err))
 		respondJSON(w, http.StatusInternalServerError, domain.ErrorResponse{
 			Error:   "Internal Server Error",
@@ -514,11 +514,12 @@ func (h *ActivityHandler) Update(w http.ResponseWriter, r *http.Request) {
 
 // Delete godoc
 // @Summary Delete activity
-// @Description Delete an activity
+// @Description Delete an activity. For an occurrence of a recurring series, scope selects whether only this occurrence, this and the following, or all open occurrences of the series are deleted. Completed occurrences are kept.
 // @Tags Activities
 // @Accept json
 // @Produce json
 // @Param id path string true "Activity ID" format(uuid)
+// @Param scope query string false "Recurrence scope" Enums(this, following, all) default(this)
 // @Success 204 "No Content"
 // @Failure 400 {object} domain.ErrorResponse
 // @Failure 401 {object} domain.ErrorResponse
@@ -538,7 +539,8 @@ func (h *ActivityHandler) Delete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.activityService.Delete(r.Context(), id); err != nil {
+	scope := domain.RecurrenceScope(r.URL.Query().Get("scope"))
+	if err := h.activityService.DeleteWithScope(r.Context(), id, scope); err != nil {
 		if errors.Is(err, service.ErrUserContextRequired) {
 			respondJSON(w, http.StatusUnauthorized, domain.ErrorResponse{
 				Error:   "Unauthorized",
@@ -560,6 +562,9 @@ func (h *ActivityHandler) Delete(w http.ResponseWriter, r *http.Request) {
 			})
 			return
 		}
+		if respondRecurrenceError(w, err) {
+			return
+		}
 		h.logger.Error("failed to delete activity", zap.Error(err))
 		respondJSON(w, http.StatusInternalServerError, domain.ErrorResponse{
 			Error:   "Internal Server Error",
@@ -914,3 +919,26 @@ func (h *ActivityHandler) Complete(w http.ResponseWriter, r *http.Request) {
 
 	respondJSON(w, http.StatusOK, activity)
 }
+
+// respondRecurrenceError responds with 400 for invalid recurrence rules and scopes.
+// Returns false if the error is not a recurrence error.
+func respondRecurrenceError(w http.ResponseWriter, err error) bool {
+	switch {
+	case errors.Is(err, service.ErrInvalidRecurrenceRule),
+		errors.Is(err, service.ErrUnsupportedRecurrenceRule),
+		errors.Is(err, service.ErrRecurrenceRequiresSchedule),
+		errors.Is(err, service.ErrRecurrenceRuleRequiresScope):
+		respondJSON(w, http.StatusBadRequest, domain.ErrorResponse{
+			Error:   "Bad Request",
+			Message: err.Error(),
+		})
+	case errors.Is(err, service.ErrInvalidRecurrenceScope):
+		respondJSON(w, http.StatusBadRequest, domain.ErrorResponse{
+			Error:   "Bad Request",
+			Message: "Invalid recurrence scope. Valid values: this, following, all",
+		})
+	default:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,356 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/straye-as/relation-api/internal/domain"
+	"github.com/straye-as/relation-api/internal/service"
+	"go.uber.org/zap"
+)
+
+// ListProjectDimensions returns all budget items for a project
+// @Summary List budget items for a project
+// @Description Get the budget items of a project's working budget
+// @Tags projects,budget
+// @Accept json
+// @Produce json
+// @Param id path string true "Project ID"
+// @Success 200 {array} domain.BudgetItemDTO
+// @Failure 400 {object} domain.APIError
+// @Failure 500 {object} domain.APIError
+// @Router /projects/{id}/budget/dimensions [get]
+// @Security BearerAuth
+func (h *BudgetItemHandler) ListProjectDimensions(w http.ResponseWriter, r *http.Request) {
+	projectID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid project ID")
+		return
+	}
+
+	items, err := h.budgetItemService.ListByProject(r.Context(), projectID)
+	if err != nil {
+		h.logger.Error("Failed to list budget items", zap.Error(err))
+		respondWithError(w, http.StatusInternalServerError, "Failed to list budget items")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, items)
+}
+
+// GetProjectBudgetWithDimensions returns budget summary and items for a project
+// @Summary Get project budget with items
+// @Description Get budget summary and all budget items of a project's working budget
+// @Tags projects,budget
+// @Accept json
+// @Produce json
+// @Param id path string true "Project ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} domain.APIError
+// @Failure 404 {object} domain.APIError
+// @Failure 500 {object} domain.APIError
+// @Router /projects/{id}/budget [get]
+// @Security BearerAuth
+func (h *BudgetItemHandler) GetProjectBudgetWithDimensions(w http.ResponseWriter, r *http.Request) {
+	projectID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid project ID")
+		return
+	}
+
+	summary, items, err := h.budgetItemService.GetProjectBudgetWithItems(r.Context(), projectID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			respondWithError(w, http.StatusNotFound, "Project not found")
+			return
+		}
+		h.logger.Error("Failed to get project budget", zap.Error(err))
+		respondWithError(w, http.StatusInternalServerError, "Failed to get project budget")
+		return
+	}
+
+	response := map[string]interface{}{
+		"summary": summary,
+		"items":   items,
+	}
+
+	respondJSON(w, http.StatusOK, response)
+}
+
+// GetProjectBudgetRollup returns the project's budget compared with what was sold and the actual costs
+// @Summary Get project budget roll-up
+// @Description Compares the budget items of the project's won offers (order or completed) with the project's working budget and the actual costs from the data warehouse, per budget item. Project items rebudgeting a sold item share its line. Until the project has its own budget items, what was sold is the budget. The data warehouse has costs per category (material, employee, other), so each category's costs are split over the budget items tracked in it by budget cost; costs in a category no item is tracked in are returned as unallocated lines.
+// @Tags projects,budget
+// @Produce json
+// @Param id path string true "Project ID"
+// @Success 200 {object} domain.ProjectBudgetRollupDTO
+// @Failure 400 {object} domain.APIError
+// @Failure 404 {object} domain.APIError
+// @Failure 500 {object} domain.APIError
+// @Router /projects/{id}/budget/rollup [get]
+// @Security BearerAuth
+func (h *BudgetItemHandler) GetProjectBudgetRollup(w http.ResponseWriter, r *http.Request) {
+	projectID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid project ID")
+		return
+	}
+
+	rollup, err := h.budgetItemService.GetProjectBudgetRollup(r.Context(), projectID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			respondWithError(w, http.StatusNotFound, "Project not found")
+			return
+		}
+		h.logger.Error("Failed to get project budget roll-up", zap.Error(err))
+		respondWithError(w, http.StatusInternalServerError, "Failed to get project budget roll-up")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, rollup)
+}
+
+// InitializeProjectBudget copies the won offers' budget items into the project
+// @Summary Initialize project budget from won offers
+// @Description Copies the budget items of the project's won offers into the project's working budget, each linked to the item it was copied from, so the project can be rebudgeted without changing the sold offers. Only allowed while the project has no budget items.
+// @Tags projects,budget
+// @Produce json
+// @Param id path string true "Project ID"
+// @Success 201 {array} domain.BudgetItemDTO
+// @Failure 400 {object} domain.APIError
+// @Failure 404 {object} domain.APIError
+// @Failure 409 {object} domain.APIError
+// @Failure 500 {object} domain.APIError
+// @Router /projects/{id}/budget/initialize [post]
+// @Security BearerAuth
+func (h *BudgetItemHandler) InitializeProjectBudget(w http.ResponseWriter, r *http.Request) {
+	projectID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid project ID")
+		return
+	}
+
+	items, err := h.budgetItemService.InitializeProjectBudget(r.Context(), projectID)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrProjectBudgetNotEmpty):
+			respondWithError(w, http.StatusConflict, err.Error())
+		case errors.Is(err, service.ErrProjectHasNoWonOffer):
+			respondWithError(w, http.StatusBadRequest, err.Error())
+		case strings.Contains(err.Error(), "not found"):
+			respondWithError(w, http.StatusNotFound, "Project not found")
+		default:
+			h.logger.Error("Failed to initialize project budget", zap.Error(err))
+			respondWithError(w, http.StatusInternalServerError, "Failed to initialize project budget")
+		}
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, items)
+}
+
+// AddToProject adds a new budget item to a project
+// @Summary Add budget item to project
+// @Description Add a budget item to a project's working budget. sourceItemId links it to the budget item of a won offer it rebudgets.
+// @Tags projects,budget
+// @Accept json
+// @Produce json
+// @Param id path string true "Project ID"
+// @Param request body domain.AddProjectBudgetItemRequest true "Budget item details"
+// @Success 201 {object} domain.BudgetItemDTO
+// @Failure 400 {object} domain.APIError
+// @Failure 404 {object} domain.APIError
+// @Failure 500 {object} domain.APIError
+// @Router /projects/{id}/budget/dimensions [post]
+// @Security BearerAuth
+func (h *BudgetItemHandler) AddToProject(w http.ResponseWriter, r *http.Request) {
+	projectID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid project ID")
+		return
+	}
+
+	var req domain.AddProjectBudgetItemRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := validate.Struct(req); err != nil {
+		respondValidationError(w, err)
+		return
+	}
+
+	item, err := h.budgetItemService.AddToProject(r.Context(), projectID, req)
+	if err != nil {
+		if strings.Contains(err.Error(), "source budget item") {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if strings.Contains(err.Error(), "not found") {
+			respondWithError(w, http.StatusNotFound, "Project not found")
+			return
+		}
+		h.logger.Error("Failed to add budget item", zap.Error(err))
+		respondWithError(w, http.StatusInternalServerError, "Failed to add budget item")
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, item)
+}
+
+// UpdateProjectDimension updates a budget item belonging to a project
+// @Summary Update project budget item
+// @Description Update a budget item of a project's working budget
+// @Tags projects,budget
+// @Accept json
+// @Produce json
+// @Param id path string true "Project ID"
+// @Param dimensionId path string true "Budget Item ID"
+// @Param request body domain.UpdateBudgetItemRequest true "Updated budget item details"
+// @Success 200 {object} domain.BudgetItemDTO
+// @Failure 400 {object} domain.APIError
+// @Failure 404 {object} domain.APIError
+// @Failure 500 {object} domain.APIError
+// @Router /projects/{id}/budget/dimensions/{dimensionId} [put]
+// @Security BearerAuth
+func (h *BudgetItemHandler) UpdateProjectDimension(w http.ResponseWriter, r *http.Request) {
+	projectID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid project ID")
+		return
+	}
+
+	itemID, err := uuid.Parse(chi.URLParam(r, "dimensionId"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid budget item ID")
+		return
+	}
+
+	var req domain.UpdateBudgetItemRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := validate.Struct(req); err != nil {
+		respondValidationError(w, err)
+		return
+	}
+
+	item, err := h.budgetItemService.UpdateProjectDimension(r.Context(), projectID, itemID, req)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			respondWithError(w, http.StatusNotFound, "Budget item not found")
+			return
+		}
+		if strings.Contains(err.Error(), "does not belong") {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		h.logger.Error("Failed to update budget item", zap.Error(err))
+		respondWithError(w, http.StatusInternalServerError, "Failed to update budget item")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, item)
+}
+
+// DeleteProjectDimension removes a budget item from a project
+// @Summary Delete project budget item
+// @Description Delete a budget item from a project's working budget
+// @Tags projects,budget
+// @Accept json
+// @Produce json
+// @Param id path string true "Project ID"
+// @Param dimensionId path string true "Budget Item ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} domain.APIError
+// @Failure 404 {object} domain.APIError
+// @Failure 500 {object} domain.APIError
+// @Router /projects/{id}/budget/dimensions/{dimensionId} [delete]
+// @Security BearerAuth
+func (h *BudgetItemHandler) DeleteProjectDimension(w http.ResponseWriter, r *http.Request) {
+	projectID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid project ID")
+		return
+	}
+
+	itemID, err := uuid.Parse(chi.URLParam(r, "dimensionId"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid budget item ID")
+		return
+	}
+
+	err = h.budgetItemService.DeleteProjectDimension(r.Context(), projectID, itemID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			respondWithError(w, http.StatusNotFound, "Budget item not found")
+			return
+		}
+		if strings.Contains(err.Error(), "does not belong") {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		h.logger.Error("Failed to delete budget item", zap.Error(err))
+		respondWithError(w, http.StatusInternalServerError, "Failed to delete budget item")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ReorderProjectDimensions reorders budget items for a project
+// @Summary Reorder project budget items
+// @Description Reorder the budget items of a project by providing ordered IDs
+// @Tags projects,budget
+// @Accept json
+// @Produce json
+// @Param id path string true "Project ID"
+// @Param request body domain.ReorderBudgetItemsRequest true "Ordered list of budget item IDs"
+// @Success 204 "No Content"
+// @Failure 400 {object} domain.APIError
+// @Failure 404 {object} domain.APIError
+// @Failure 500 {object} domain.APIError
+// @Router /projects/{id}/budget/reorder [put]
+// @Security BearerAuth
+func (h *BudgetItemHandler) ReorderProjectDimensions(w http.ResponseWriter, r *http.Request) {
+	projectID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid project ID")
+		return
+	}
+
+	var req domain.ReorderBudgetItemsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := validate.Struct(req); err != nil {
+		respondValidationError(w, err)
+		return
+	}
+
+	err = h.budgetItemService.ReorderProjectDimensions(r.Context(), projectID, req)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			respondWithError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		if strings.Contains(err.Error(), "does not belong") {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		h.logger.Error("Failed to reorder budget items", zap.Error(err))
+		respondWithError(w, http.StatusInternalServerError, "Failed to reorder budget items")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,151 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/straye-as/relation-api/internal/ical"
+	"github.com/straye-as/relation-api/internal/service"
+	"go.uber.org/zap"
+)
+
+// CalendarHandler handles HTTP requests for iCalendar subscription feeds
+type CalendarHandler struct {
+	calendarService *service.CalendarFeedService
+	logger          *zap.Logger
+}
+
+// NewCalendarHandler creates a new CalendarHandler instance
+func NewCalendarHandler(calendarService *service.CalendarFeedService, logger *zap.Logger) *CalendarHandler {
+	return &CalendarHandler{
+		calendarService: calendarService,
+		logger:          logger,
+	}
+}
+
+// GetFeed godoc
+// @Summary Get calendar subscription
+// @Description Returns whether the current user has an active calendar feed token and when it was created and last fetched. The token itself is only returned when it is created.
+// @Tags Calendar
+// @Produce json
+// @Success 200 {object} domain.CalendarFeedDTO
+// @Failure 401 {object} domain.APIError
+// @Failure 500 {object} domain.APIError
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /calendar/feed [get]
+func (h *CalendarHandler) GetFeed(w http.ResponseWriter, r *http.Request) {
+	feed, err := h.calendarService.GetFeed(r.Context())
+	if err != nil {
+		h.handleCalendarError(w, err, "failed to get calendar feed")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, feed)
+}
+
+// CreateFeedToken godoc
+// @Summary Create calendar subscription
+// @Description Creates a secret calendar feed token for the current user and returns the subscription URL. Any previous token is revoked, so calendars subscribed with it stop updating. The token cannot be retrieved again.
+// @Tags Calendar
+// @Produce json
+// @Success 201 {object} domain.CalendarFeedTokenDTO
+// @Failure 401 {object} domain.APIError
+// @Failure 500 {object} domain.APIError
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /calendar/feed [post]
+func (h *CalendarHandler) CreateFeedToken(w http.ResponseWriter, r *http.Request) {
+	token, err := h.calendarService.CreateToken(r.Context())
+	if err != nil {
+		h.handleCalendarError(w, err, "failed to create calendar feed token")
+		return
+	}
+
+	token.URL = calendarFeedURL(r, token.Token)
+	respondJSON(w, http.StatusCreated, token)
+}
+
+// RevokeFeedToken godoc
+// @Summary Revoke calendar subscription
+// @Description Revokes the current user's calendar feed token. Calendars subscribed with it stop updating.
+// @Tags Calendar
+// @Success 204 "No Content"
+// @Failure 401 {object} domain.APIError
+// @Failure 404 {object} domain.APIError
+// @Failure 500 {object} domain.APIError
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /calendar/feed [delete]
+func (h *CalendarHandler) RevokeFeedToken(w http.ResponseWriter, r *http.Request) {
+	if err := h.calendarService.RevokeToken(r.Context()); err != nil {
+		h.handleCalendarError(w, err, "failed to revoke calendar feed token")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Feed godoc
+// @Summary Calendar subscription feed
+// @Description iCalendar (RFC 5545) feed for calendar clients, authenticated by the secret token in the URL. Contains meetings, calls and other activities assigned to or attended by the token's user, and due and expiration dates of open offers they are responsible for, from 90 days back to a year ahead. Event UIDs are stable, so changed activities replace the existing events.
+// @Tags Calendar
+// @Produce text/calendar
+// @Param token path string true "Calendar feed token"
+// @Success 200 {file} file "iCalendar feed"
+// @Failure 404 {object} domain.APIError
+// @Failure 500 {object} domain.APIError
+// @Router /calendar/{token}.ics [get]
+func (h *CalendarHandler) Feed(w http.ResponseWriter, r *http.Request) {
+	cal, err := h.calendarService.Feed(r.Context(), chi.URLParam(r, "token"))
+	if err != nil {
+		h.handleCalendarError(w, err, "failed to build calendar feed")
+		return
+	}
+
+	// Build the feed in memory so a failure can still be reported as a JSON error
+	var buf bytes.Buffer
+	if err := ical.Write(&buf, cal); err != nil {
+		h.logger.Error("failed to write calendar feed", zap.Error(err))
+		respondWithError(w, http.StatusInternalServerError, "Failed to write calendar feed")
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", "inline; filename=\"straye.ics\"")
+	w.Header().Set("Cache-Control", "private, no-cache")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(buf.Bytes())
+}
+
+// calendarFeedURL returns the absolute subscription URL of a token, honouring proxy headers
+func calendarFeedURL(r *http.Request, token string) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	if proto := r.Header.Get("X-Forwarded-Proto"); proto != "" {
+		scheme = strings.TrimSpace(strings.Split(proto, ",")[0])
+	}
+	host := r.Host
+	if forwarded := r.Header.Get("X-Forwarded-Host"); forwarded != "" {
+		host = strings.TrimSpace(strings.Split(forwarded, ",")[0])
+	}
+	return scheme + "://" + host + "/api/v1/calendar/" + token + ".ics"
+}
+
+// handleCalendarError maps calendar feed service errors to HTTP responses
+func (h *CalendarHandler) handleCalendarError(w http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, service.ErrUserContextRequired):
+		respondWithError(w, http.StatusUnauthorized, "Authentication required")
+	case errors.Is(err, service.ErrCalendarFeedNotFound):
+		respondWithError(w, http.StatusNotFound, "Calendar feed not found")
+	default:
+		h.logger.Error(message, zap.Error(err))
+		respondWithError(w, http.StatusInternalServerError, message)
+	}
+}
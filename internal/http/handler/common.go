@@ -8,6 +8,7 @@ import (
 
 	"github.com/go-playground/validator/v10"
 	"github.com/straye-as/relation-api/internal/domain"
+	"github.com/straye-as/relation-api/internal/repository"
 )
 
 var validate = validator.New()
@@ -85,6 +86,18 @@ func parseJSON(data string, target interface{}) error {
 	return json.Unmarshal([]byte(data), target)
 }
 
+// parseSortConfig parses the sortBy and sortOrder query parameters
+func parseSortConfig(r *http.Request) repository.SortConfig {
+	sort := repository.DefaultSortConfig()
+	if sortBy := r.URL.Query().Get("sortBy"); sortBy != "" {
+		sort.Field = sortBy
+	}
+	if sortOrder := r.URL.Query().Get("sortOrder"); sortOrder != "" {
+		sort.Order = repository.ParseSortOrder(sortOrder)
+	}
+	return sort
+}
+
 // respondWithError sends a standardized JSON error response
 func respondWithError(w http.ResponseWriter, status int, message string) {
 	w.Header().Set("Content-Type", "application/json")
@@ -73,7 +73,7 @@ func (h *CompanyHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 
 // Update godoc
 // @Summary Update company settings
-// @Description Updates company settings including default responsible users for offers and projects
+// @Description Updates company settings: default responsible users for offers and projects, whether orders over a customer's credit limit are warned about or blocked, and the inquiry response SLA with the business hours it is measured in
 // @Tags Companies
 // @Accept json
 // @Produce json
@@ -109,6 +109,10 @@ func (h *CompanyHandler) Update(w http.ResponseWriter, r *http.Request) {
 			respondWithError(w, http.StatusBadRequest, "invalid responsible user ID")
 			return
 		}
+		if errors.Is(err, service.ErrInvalidCreditLimitEnforcement) || errors.Is(err, service.ErrInvalidInquirySLA) || errors.Is(err, service.ErrInvalidBusinessHours) {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
 		h.logger.Error("failed to update company", zap.Error(err), zap.String("companyID", companyID))
 		respondWithError(w, http.StatusInternalServerError, "failed to update company")
 		return
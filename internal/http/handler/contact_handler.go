@@ -39,7 +39,7 @@ func NewContactHandler(contactService *service.ContactService, logger *zap.Logge
 // @Param search query string false "Search by name or email"
 // @Param title query string false "Filter by job title"
 // @Param contactType query string false "Filter by contact type" Enums(primary, secondary, billing, technical, executive, other)
-// @Param entityType query string false "Filter by related entity type" Enums(customer, deal, project)
+// @Param entityType query string false "Filter by related entity type" Enums(customer, deal, project, offer)
 // @Param entityId query string false "Filter by related entity ID"
 // @Param sortBy query string false "Sort option" Enums(name_asc, name_desc, email_asc, created_desc)
 // @Success 200 {object} domain.PaginatedResponse
@@ -88,8 +88,8 @@ func (h *ContactHandler) ListContacts(w http.ResponseWriter, r *http.Request) {
 	// Entity type filter
 	if entityType := r.URL.Query().Get("entityType"); entityType != "" {
 		et := domain.ContactEntityType(entityType)
-		if et != domain.ContactEntityCustomer && et != domain.ContactEntityDeal && et != domain.ContactEntityProject {
-			respondWithError(w, http.StatusBadRequest, "Invalid entityType: must be one of customer, deal, project")
+		if !et.IsValid() {
+			respondWithError(w, http.StatusBadRequest, "Invalid entityType: must be one of customer, deal, project, offer")
 			return
 		}
 		filters.EntityType = &et
@@ -308,15 +308,17 @@ func (h *ContactHandler) AddRelationship(w http.ResponseWriter, r *http.Request)
 	}
 
 	// Validate entity type
-	if req.EntityType != domain.ContactEntityCustomer &&
-		req.EntityType != domain.ContactEntityDeal &&
-		req.EntityType != domain.ContactEntityProject {
-		respondWithError(w, http.StatusBadRequest, "Invalid entityType: must be one of customer, deal, project")
+	if !req.EntityType.IsValid() {
+		respondWithError(w, http.StatusBadRequest, "Invalid entityType: must be one of customer, deal, project, offer")
 		return
 	}
 
 	relationship, err := h.contactService.AddRelationship(r.Context(), contactID, &req)
 	if err != nil {
+		if isBuyingCommitteeError(err) {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
 		if strings.Contains(err.Error(), "not found") {
 			respondWithError(w, http.StatusNotFound, "Contact not found")
 			return
@@ -378,10 +380,10 @@ func (h *ContactHandler) RemoveRelationship(w http.ResponseWriter, r *http.Reque
 
 // GetContactsForEntity godoc
 // @Summary Get contacts for entity
-// @Description Get all contacts related to a specific entity (customer, deal, or project)
+// @Description Get all contacts related to a specific entity (customer, deal, project or offer)
 // @Tags Contacts
 // @Produce json
-// @Param entityType path string true "Entity type" Enums(customers, deals, projects)
+// @Param entityType path string true "Entity type" Enums(customers, deals, projects, offers)
 // @Param id path string true "Entity ID"
 // @Success 200 {array} domain.ContactDTO
 // @Failure 400 {object} map[string]interface{}
@@ -408,6 +410,8 @@ func (h *ContactHandler) GetContactsForEntity(w http.ResponseWriter, r *http.Req
 		entityType = domain.ContactEntityDeal
 	case strings.Contains(path, "/projects/"):
 		entityType = domain.ContactEntityProject
+	case strings.Contains(path, "/offers/"):
+		entityType = domain.ContactEntityOffer
 	default:
 		respondWithError(w, http.StatusBadRequest, "Invalid entity type in URL")
 		return
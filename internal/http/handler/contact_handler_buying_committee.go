@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/straye-as/relation-api/internal/domain"
+	"github.com/straye-as/relation-api/internal/service"
+	"go.uber.org/zap"
+)
+
+// ============================================================================
+// Buying Committee Endpoints
+// ============================================================================
+
+// GetBuyingCommittee godoc
+// @Summary Get offer buying committee
+// @Description Returns the active contacts linked to an offer ordered by buying role (decision maker, economic buyer, technical evaluator, influencer, blocker), the roles nobody covers and a count of positive, neutral and negative contacts. Includes the missing.decisionMaker warning when no decision maker is linked.
+// @Tags Offers
+// @Produce json
+// @Param id path string true "Offer ID" format(uuid)
+// @Success 200 {object} domain.BuyingCommitteeDTO
+// @Failure 400 {object} domain.APIError
+// @Failure 500 {object} domain.APIError
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /offers/{id}/buying-committee [get]
+func (h *ContactHandler) GetBuyingCommittee(w http.ResponseWriter, r *http.Request) {
+	offerID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid offer ID: must be a valid UUID")
+		return
+	}
+
+	committee, err := h.contactService.GetBuyingCommittee(r.Context(), offerID)
+	if err != nil {
+		h.handleBuyingCommitteeError(w, err, "failed to get buying committee")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, committee)
+}
+
+// UpdateRelationship godoc
+// @Summary Update relationship
+// @Description Updates the role of a contact relationship. Buying role (decision_maker, economic_buyer, technical_evaluator, influencer, blocker) and sentiment (positive, neutral, negative) can only be set on offer relationships; omitting them clears them.
+// @Tags Contacts
+// @Accept json
+// @Produce json
+// @Param id path string true "Contact ID" format(uuid)
+// @Param relationshipId path string true "Relationship ID" format(uuid)
+// @Param request body domain.UpdateContactRelationshipRequest true "Relationship data"
+// @Success 200 {object} domain.ContactRelationshipDTO
+// @Failure 400 {object} domain.APIError
+// @Failure 404 {object} domain.APIError
+// @Failure 500 {object} domain.APIError
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /contacts/{id}/relationships/{relationshipId} [put]
+func (h *ContactHandler) UpdateRelationship(w http.ResponseWriter, r *http.Request) {
+	contactID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid contact ID: must be a valid UUID")
+		return
+	}
+	relationshipID, err := uuid.Parse(chi.URLParam(r, "relationshipId"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid relationship ID: must be a valid UUID")
+		return
+	}
+
+	var req domain.UpdateContactRelationshipRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if err := validate.Struct(req); err != nil {
+		respondValidationError(w, err)
+		return
+	}
+
+	relationship, err := h.contactService.UpdateRelationship(r.Context(), contactID, relationshipID, &req)
+	if err != nil {
+		h.handleBuyingCommitteeError(w, err, "failed to update relationship")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, relationship)
+}
+
+// isBuyingCommitteeError reports whether err is a buying role or sentiment validation error
+func isBuyingCommitteeError(err error) bool {
+	return errors.Is(err, service.ErrInvalidBuyingRole) ||
+		errors.Is(err, service.ErrInvalidContactSentiment) ||
+		errors.Is(err, service.ErrBuyingRoleRequiresOffer)
+}
+
+// handleBuyingCommitteeError maps buying committee service errors to HTTP responses
+func (h *ContactHandler) handleBuyingCommitteeError(w http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, service.ErrContactRelationshipNotFound):
+		respondWithError(w, http.StatusNotFound, "Relationship not found")
+	case isBuyingCommitteeError(err):
+		respondWithError(w, http.StatusBadRequest, err.Error())
+	default:
+		h.logger.Error(message, zap.Error(err))
+		respondWithError(w, http.StatusInternalServerError, message)
+	}
+}
@@ -0,0 +1,106 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/straye-as/relation-api/internal/domain"
+	"github.com/straye-as/relation-api/internal/service"
+	"go.uber.org/zap"
+)
+
+// ============================================================================
+// Contact Deduplication Endpoints
+// ============================================================================
+
+// FindDuplicates godoc
+// @Summary Find duplicate contacts
+// @Description Returns groups of active contacts that are probably the same person. Contacts match on email (ignoring case), on phone or mobile number (ignoring formatting and the +47 prefix), or on full name when they share a customer or one of them has no customer. Each group suggests the contact with the most relationships as survivor.
+// @Tags Contacts
+// @Produce json
+// @Param customerId query string false "Only check the contacts of this customer" format(uuid)
+// @Success 200 {array} domain.ContactDuplicateGroupDTO
+// @Failure 400 {object} domain.APIError
+// @Failure 500 {object} domain.APIError
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /contacts/duplicates [get]
+func (h *ContactHandler) FindDuplicates(w http.ResponseWriter, r *http.Request) {
+	var customerID *uuid.UUID
+	if value := r.URL.Query().Get("customerId"); value != "" {
+		id, err := uuid.Parse(value)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "Invalid customerId: must be a valid UUID")
+			return
+		}
+		customerID = &id
+	}
+
+	groups, err := h.contactService.FindDuplicates(r.Context(), customerID)
+	if err != nil {
+		h.handleMergeError(w, err, "failed to find duplicate contacts")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, groups)
+}
+
+// MergeContacts godoc
+// @Summary Merge duplicate contacts
+// @Description Merges duplicates into the contact in the URL. Relationships and activities are moved to the surviving contact, its empty fields are filled in from the duplicates, and the duplicates are deactivated with a reference to it. Requires contacts:delete.
+// @Tags Contacts
+// @Accept json
+// @Produce json
+// @Param id path string true "Surviving contact ID" format(uuid)
+// @Param request body domain.MergeContactsRequest true "Duplicates to merge"
+// @Success 200 {object} domain.ContactMergeResultDTO
+// @Failure 400 {object} domain.APIError
+// @Failure 403 {object} domain.APIError
+// @Failure 404 {object} domain.APIError
+// @Failure 500 {object} domain.APIError
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /contacts/{id}/merge [post]
+func (h *ContactHandler) MergeContacts(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid contact ID: must be a valid UUID")
+		return
+	}
+
+	var req domain.MergeContactsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if err := validate.Struct(req); err != nil {
+		respondValidationError(w, err)
+		return
+	}
+
+	result, err := h.contactService.Merge(r.Context(), id, &req)
+	if err != nil {
+		h.handleMergeError(w, err, "failed to merge contacts")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, result)
+}
+
+// handleMergeError maps contact deduplication service errors to HTTP responses
+func (h *ContactHandler) handleMergeError(w http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, service.ErrContactNotFound):
+		respondWithError(w, http.StatusNotFound, "Contact not found")
+	case errors.Is(err, service.ErrDuplicateContactNotFound):
+		respondWithError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, service.ErrMergeIntoSelf):
+		respondWithError(w, http.StatusBadRequest, err.Error())
+	default:
+		h.logger.Error(message, zap.Error(err))
+		respondWithError(w, http.StatusInternalServerError, message)
+	}
+}
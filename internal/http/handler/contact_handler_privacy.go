@@ -0,0 +1,232 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/straye-as/relation-api/internal/domain"
+	"github.com/straye-as/relation-api/internal/service"
+	"go.uber.org/zap"
+)
+
+// ============================================================================
+// Contact Privacy (GDPR) Endpoints
+// ============================================================================
+
+// ExportDataSubject godoc
+// @Summary Export personal data of a contact
+// @Description Gathers everything stored about a contact (data subject access request): the contact, activities on or mentioning it, copies of its name, email and phone on customers, suppliers and offer suppliers, and audit log entries. Deleted contacts are included. Requires contacts:delete.
+// @Tags Contacts
+// @Produce json
+// @Produce application/zip
+// @Param id path string true "Contact ID" format(uuid)
+// @Param format query string false "Bundle format: JSON document or ZIP with one JSON file per section" Enums(json, zip) default(json)
+// @Success 200 {object} domain.DataSubjectExportDTO
+// @Failure 400 {object} domain.APIError
+// @Failure 403 {object} domain.APIError
+// @Failure 404 {object} domain.APIError
+// @Failure 500 {object} domain.APIError
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /contacts/{id}/data-export [get]
+func (h *ContactHandler) ExportDataSubject(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid contact ID: must be a valid UUID")
+		return
+	}
+	format, ok := parseDataExportFormat(w, r)
+	if !ok {
+		return
+	}
+
+	export, err := h.contactService.ExportDataSubject(r.Context(), id)
+	if err != nil {
+		h.handlePrivacyError(w, err, "failed to export contact data")
+		return
+	}
+
+	h.respondDataExport(w, export, format, "contact_"+id.String())
+}
+
+// ExportDataSubjectByEmail godoc
+// @Summary Export personal data for an email address
+// @Description Gathers everything stored about an email address (data subject access request), including all contacts with the address. Returns an empty export when nothing is stored. Requires contacts:delete.
+// @Tags Contacts
+// @Produce json
+// @Produce application/zip
+// @Param email query string true "Email address of the data subject"
+// @Param format query string false "Bundle format: JSON document or ZIP with one JSON file per section" Enums(json, zip) default(json)
+// @Success 200 {object} domain.DataSubjectExportDTO
+// @Failure 400 {object} domain.APIError
+// @Failure 403 {object} domain.APIError
+// @Failure 500 {object} domain.APIError
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /contacts/data-export [get]
+func (h *ContactHandler) ExportDataSubjectByEmail(w http.ResponseWriter, r *http.Request) {
+	format, ok := parseDataExportFormat(w, r)
+	if !ok {
+		return
+	}
+
+	export, err := h.contactService.ExportDataSubjectByEmail(r.Context(), r.URL.Query().Get("email"))
+	if err != nil {
+		h.handlePrivacyError(w, err, "failed to export data for email address")
+		return
+	}
+
+	h.respondDataExport(w, export, format, "data_subject")
+}
+
+// AnonymizeContact godoc
+// @Summary Anonymize contact
+// @Description Scrubs the personal data of a contact (right to erasure) while keeping its relationships. The contact's name, email and phone numbers are also replaced in activities, customer and supplier contact fields, offer suppliers and audit logs. The contact is deactivated. This cannot be undone. Requires contacts:delete.
+// @Tags Contacts
+// @Produce json
+// @Param id path string true "Contact ID" format(uuid)
+// @Success 200 {object} domain.ContactAnonymizationDTO
+// @Failure 400 {object} domain.APIError
+// @Failure 403 {object} domain.APIError
+// @Failure 404 {object} domain.APIError
+// @Failure 409 {object} domain.APIError "Contact already anonymized"
+// @Failure 500 {object} domain.APIError
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /contacts/{id}/anonymize [post]
+func (h *ContactHandler) AnonymizeContact(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid contact ID: must be a valid UUID")
+		return
+	}
+
+	result, err := h.contactService.Anonymize(r.Context(), id)
+	if err != nil {
+		h.handlePrivacyError(w, err, "failed to anonymize contact")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, result)
+}
+
+// ListRetentionReview godoc
+// @Summary List contacts for retention review
+// @Description Lists contacts flagged by the retention job because neither the contact nor its activities have changed for the retention period. Review each contact and either keep it or anonymize it. Requires contacts:delete.
+// @Tags Contacts
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param pageSize query int false "Items per page" default(20)
+// @Success 200 {object} domain.PaginatedResponse{data=[]domain.ContactDTO}
+// @Failure 403 {object} domain.APIError
+// @Failure 500 {object} domain.APIError
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /contacts/retention-review [get]
+func (h *ContactHandler) ListRetentionReview(w http.ResponseWriter, r *http.Request) {
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	if page < 1 {
+		page = 1
+	}
+	pageSize, _ := strconv.Atoi(r.URL.Query().Get("pageSize"))
+	if pageSize < 1 {
+		pageSize = 20
+	}
+	if pageSize > 200 {
+		pageSize = 200
+	}
+
+	result, err := h.contactService.ListRetentionReview(r.Context(), page, pageSize)
+	if err != nil {
+		h.handlePrivacyError(w, err, "failed to list contacts for retention review")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, result)
+}
+
+// KeepAfterRetentionReview godoc
+// @Summary Keep contact after retention review
+// @Description Clears the retention flag of a reviewed contact that should be kept. The retention period restarts from the review. Requires contacts:delete.
+// @Tags Contacts
+// @Produce json
+// @Param id path string true "Contact ID" format(uuid)
+// @Success 200 {object} domain.ContactDTO
+// @Failure 400 {object} domain.APIError
+// @Failure 403 {object} domain.APIError
+// @Failure 404 {object} domain.APIError
+// @Failure 500 {object} domain.APIError
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /contacts/{id}/retention-review [post]
+func (h *ContactHandler) KeepAfterRetentionReview(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid contact ID: must be a valid UUID")
+		return
+	}
+
+	contact, err := h.contactService.KeepAfterRetentionReview(r.Context(), id)
+	if err != nil {
+		h.handlePrivacyError(w, err, "failed to review contact")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, contact)
+}
+
+// parseDataExportFormat parses the format query parameter of data subject exports
+func parseDataExportFormat(w http.ResponseWriter, r *http.Request) (string, bool) {
+	format := r.URL.Query().Get("format")
+	switch format {
+	case "":
+		return "json", true
+	case "json", "zip":
+		return format, true
+	default:
+		respondWithError(w, http.StatusBadRequest, "Invalid format: must be json or zip")
+		return "", false
+	}
+}
+
+// respondDataExport writes a data subject export as JSON or as a ZIP download
+func (h *ContactHandler) respondDataExport(w http.ResponseWriter, export *domain.DataSubjectExportDTO, format, fileBase string) {
+	if format == "json" {
+		respondJSON(w, http.StatusOK, export)
+		return
+	}
+
+	// Build the archive in memory so a failure can still be reported as a JSON error
+	var buf bytes.Buffer
+	if err := service.WriteDataSubjectArchive(&buf, export); err != nil {
+		h.logger.Error("failed to build data export archive", zap.Error(err))
+		respondWithError(w, http.StatusInternalServerError, "Failed to build data export")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", "attachment; filename="+fileBase+"_"+time.Now().Format("2006-01-02")+".zip")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(buf.Bytes())
+}
+
+// handlePrivacyError maps contact privacy service errors to HTTP responses
+func (h *ContactHandler) handlePrivacyError(w http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, service.ErrContactNotFound):
+		respondWithError(w, http.StatusNotFound, "Contact not found")
+	case errors.Is(err, service.ErrContactAlreadyAnonymized):
+		respondWithError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, service.ErrDataSubjectRequired),
+		errors.Is(err, service.ErrInvalidEmailFormat):
+		respondWithError(w, http.StatusBadRequest, err.Error())
+	default:
+		h.logger.Error(message, zap.Error(err))
+		respondWithError(w, http.StatusInternalServerError, message)
+	}
+}
@@ -0,0 +1,166 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/straye-as/relation-api/internal/service"
+	"github.com/straye-as/relation-api/internal/vcard"
+	"go.uber.org/zap"
+)
+
+// maxVCardUploadMB is the maximum size of an uploaded vCard file
+const maxVCardUploadMB = 10
+
+// vCardFilenameUnsafe matches characters replaced in vCard download file names
+var vCardFilenameUnsafe = regexp.MustCompile(`[^\p{L}\p{N}_-]+`)
+
+// ============================================================================
+// Contact vCard Endpoints
+// ============================================================================
+
+// ExportVCard godoc
+// @Summary Export contact as vCard
+// @Description Downloads a contact as a vCard 4.0 file for import into phones and address books
+// @Tags Contacts
+// @Produce text/vcard
+// @Param id path string true "Contact ID" format(uuid)
+// @Success 200 {file} file "vCard file"
+// @Failure 400 {object} domain.APIError
+// @Failure 404 {object} domain.APIError
+// @Failure 500 {object} domain.APIError
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /contacts/{id}/vcard [get]
+func (h *ContactHandler) ExportVCard(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid contact ID: must be a valid UUID")
+		return
+	}
+
+	card, err := h.contactService.ExportVCard(r.Context(), id)
+	if err != nil {
+		h.handleVCardError(w, err, "failed to export contact as vCard")
+		return
+	}
+
+	h.respondVCard(w, card.FullName, *card)
+}
+
+// ExportCustomerVCards godoc
+// @Summary Export customer contacts as vCard
+// @Description Downloads the active contacts of a customer as a single vCard 4.0 file. Includes contacts with the customer as primary customer and contacts related to it.
+// @Tags Customers
+// @Produce text/vcard
+// @Param id path string true "Customer ID" format(uuid)
+// @Success 200 {file} file "vCard file"
+// @Failure 400 {object} domain.APIError
+// @Failure 404 {object} domain.APIError
+// @Failure 500 {object} domain.APIError
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /customers/{id}/contacts/vcard [get]
+func (h *ContactHandler) ExportCustomerVCards(w http.ResponseWriter, r *http.Request) {
+	customerID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid customer ID: must be a valid UUID")
+		return
+	}
+
+	customer, cards, err := h.contactService.ExportCustomerVCards(r.Context(), customerID)
+	if err != nil {
+		h.handleVCardError(w, err, "failed to export customer contacts as vCard")
+		return
+	}
+
+	h.respondVCard(w, customer.Name+" kontakter", cards...)
+}
+
+// ImportVCards godoc
+// @Summary Import contacts from vCard
+// @Description Imports a vCard file (version 2.1, 3.0 or 4.0) with one or more cards. Cards are matched to existing contacts by email and update only the fields present on the card; other cards create new contacts. With customerId, new contacts get the customer as primary customer and all imported contacts are related to it.
+// @Tags Contacts
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "vCard file (.vcf)"
+// @Param customerId formData string false "Customer to relate the contacts to" format(uuid)
+// @Success 200 {object} domain.VCardImportResultDTO
+// @Failure 400 {object} domain.APIError
+// @Failure 404 {object} domain.APIError
+// @Failure 500 {object} domain.APIError
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /contacts/vcard [post]
+func (h *ContactHandler) ImportVCards(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxVCardUploadMB*1024*1024)
+	if err := r.ParseMultipartForm(maxVCardUploadMB * 1024 * 1024); err != nil {
+		respondWithError(w, http.StatusBadRequest, "file too large or invalid form")
+		return
+	}
+
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "file field is required")
+		return
+	}
+	defer file.Close()
+
+	var customerID *uuid.UUID
+	if value := r.FormValue("customerId"); value != "" {
+		id, err := uuid.Parse(value)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "Invalid customerId: must be a valid UUID")
+			return
+		}
+		customerID = &id
+	}
+
+	result, err := h.contactService.ImportVCards(r.Context(), file, customerID)
+	if err != nil {
+		h.handleVCardError(w, err, "failed to import vCard file")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, result)
+}
+
+// respondVCard writes the cards as a vCard download
+func (h *ContactHandler) respondVCard(w http.ResponseWriter, name string, cards ...vcard.Card) {
+	// Build the file in memory so a failure can still be reported as a JSON error
+	var buf bytes.Buffer
+	if err := vcard.Write(&buf, cards...); err != nil {
+		h.logger.Error("failed to write vCard", zap.Error(err))
+		respondWithError(w, http.StatusInternalServerError, "Failed to write vCard")
+		return
+	}
+
+	filename := strings.Trim(vCardFilenameUnsafe.ReplaceAllString(name, "_"), "_")
+	if filename == "" {
+		filename = "kontakter"
+	}
+	w.Header().Set("Content-Type", "text/vcard; charset=utf-8")
+	w.Header().Set("Content-Disposition", "attachment; filename=\""+filename+".vcf\"")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(buf.Bytes())
+}
+
+// handleVCardError maps vCard service errors to HTTP responses
+func (h *ContactHandler) handleVCardError(w http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, service.ErrContactNotFound):
+		respondWithError(w, http.StatusNotFound, "Contact not found")
+	case errors.Is(err, service.ErrCustomerNotFound):
+		respondWithError(w, http.StatusNotFound, "Customer not found")
+	case errors.Is(err, service.ErrInvalidVCard):
+		respondWithError(w, http.StatusBadRequest, err.Error())
+	default:
+		h.logger.Error(message, zap.Error(err))
+		respondWithError(w, http.StatusInternalServerError, message)
+	}
+}
@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/straye-as/relation-api/internal/service"
+	"go.uber.org/zap"
+)
+
+// CreditExposureHandler handles HTTP requests for customer credit exposure
+type CreditExposureHandler struct {
+	creditExposureService *service.CreditExposureService
+	logger                *zap.Logger
+}
+
+// NewCreditExposureHandler creates a new CreditExposureHandler instance
+func NewCreditExposureHandler(creditExposureService *service.CreditExposureService, logger *zap.Logger) *CreditExposureHandler {
+	return &CreditExposureHandler{
+		creditExposureService: creditExposureService,
+		logger:                logger,
+	}
+}
+
+// GetExposure godoc
+// @Summary Get customer credit exposure
+// @Description Returns the customer's credit exposure: open order reserve plus sent offer values weighted by probability, and unpaid invoices from the ERP when enabled. Exposure is compared to the customer's credit limit.
+// @Tags Customers
+// @Produce json
+// @Param id path string true "Customer ID" format(uuid)
+// @Success 200 {object} domain.CustomerCreditExposureDTO
+// @Failure 400 {object} domain.APIError
+// @Failure 404 {object} domain.APIError
+// @Failure 500 {object} domain.APIError
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /customers/{id}/credit-exposure [get]
+func (h *CreditExposureHandler) GetExposure(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid customer ID")
+		return
+	}
+
+	exposure, err := h.creditExposureService.GetExposure(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, service.ErrCustomerNotFound) {
+			respondWithError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		h.logger.Error("failed to get customer credit exposure", zap.Error(err))
+		respondWithError(w, http.StatusInternalServerError, "failed to get customer credit exposure")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, exposure)
+}
@@ -1,8 +1,10 @@
 package handler
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
+	"io"
 	"net/http"
 	"strconv"
 	"strings"
@@ -53,6 +55,7 @@ func NewCustomerHandler(
 // @Param status query string false "Filter by status" Enums(active, inactive, lead, churned)
 // @Param tier query string false "Filter by tier" Enums(bronze, silver, gold, platinum)
 // @Param industry query string false "Filter by industry" Enums(construction, manufacturing, retail, logistics, agriculture, energy, public_sector, real_estate, other)
+// @Param registryStatus query string false "Filter by Enhetsregisteret status" Enums(active, under_liquidation, bankrupt, deleted, not_found)
 // @Param sortBy query string false "Sort field" Enums(createdAt, updatedAt, name, city, country, status, tier, industry, orgNumber)
 // @Param sortOrder query string false "Sort order" Enums(asc, desc) default(desc)
 // @Success 200 {object} domain.PaginatedResponse{data=[]domain.CustomerDTO}
@@ -72,7 +75,25 @@ func (h *CustomerHandler) List(w http.ResponseWriter, r *http.Request) {
 		pageSize = 20
 	}
 
-	// Build filters
+	filters := parseCustomerFilters(r)
+	sort := parseSortConfig(r)
+
+	result, err := h.customerService.ListWithSort(r.Context(), page, pageSize, filters, sort)
+	if err != nil {
+		h.logger.Error("failed to list customers", zap.Error(err))
+		respondJSON(w, http.StatusInternalServerError, domain.ErrorResponse{
+			Error:   "Internal Server Error",
+			Message: "Failed to list customers",
+		})
+		return
+	}
+
+	respondJSON(w, http.StatusOK, result)
+}
+
+// parseCustomerFilters parses the customer list filters from the query string.
+// Shared by the list and export endpoints so both return the same customers.
+func parseCustomerFilters(r *http.Request) *repository.CustomerFilters {
 	filters := &repository.CustomerFilters{
 		Search:  r.URL.Query().Get("search"),
 		City:    r.URL.Query().Get("city"),
@@ -97,26 +118,13 @@ func (h *CustomerHandler) List(w http.ResponseWriter, r *http.Request) {
 		filters.Industry = &i
 	}
 
-	// Parse sort configuration
-	sort := repository.DefaultSortConfig()
-	if sortBy := r.URL.Query().Get("sortBy"); sortBy != "" {
-		sort.Field = sortBy
-	}
-	if sortOrder := r.URL.Query().Get("sortOrder"); sortOrder != "" {
-		sort.Order = repository.ParseSortOrder(sortOrder)
-	}
-
-	result, err := h.customerService.ListWithSort(r.Context(), page, pageSize, filters, sort)
-	if err != nil {
-		h.logger.Error("failed to list customers", zap.Error(err))
-		respondJSON(w, http.StatusInternalServerError, domain.ErrorResponse{
-			Error:   "Internal Server Error",
-			Message: "Failed to list customers",
-		})
-		return
+	// Parse optional registry status filter
+	if registryStatus := r.URL.Query().Get("registryStatus"); registryStatus != "" {
+		rs := domain.CustomerRegistryStatus(registryStatus)
+		filters.RegistryStatus = &rs
 	}
 
-	respondJSON(w, http.StatusOK, result)
+	return filters
 }
 
 // FuzzySearch godoc
@@ -244,6 +252,13 @@ func (h *CustomerHandler) Create(w http.ResponseWriter, r *http.Request) {
 			})
 			return
 		}
+		if errors.Is(err, service.ErrInvalidOrgNumber) {
+			respondJSON(w, http.StatusBadRequest, domain.ErrorResponse{
+				Error:   "Bad Request",
+				Message: "Invalid organization number: must be 9 digits with a valid check digit",
+			})
+			return
+		}
 		h.logger.Error("failed to create customer", zap.Error(err))
 		respondJSON(w, http.StatusInternalServerError, domain.ErrorResponse{
 			Error:   "Internal Server Error",
@@ -1064,7 +1079,7 @@ func (h *CustomerHandler) UpdateContactInfo(w http.ResponseWriter, r *http.Reque
 
 // UpdatePostalCode godoc
 // @Summary Update customer postal code
-// @Description Update only the postal code of a customer
+// @Description Update the postal code of a customer. City, municipality and county are derived from the Norwegian postal code register when the code is known.
 // @Tags Customers
 // @Accept json
 // @Produce json
@@ -1405,3 +1420,121 @@ func (h *CustomerHandler) GetERPDifferences(w http.ResponseWriter, r *http.Reque
 
 	respondJSON(w, http.StatusOK, result)
 }
+
+// EnrichFromRegistry godoc
+// @Summary Enrich customer from Enhetsregisteret
+// @Description Look up the customer's organization number in Enhetsregisteret (Brønnøysundregistrene) and fill in name, address, postal code, city, municipality, county, website and industry code. By default only empty fields are filled; set overwrite to replace existing values. Customers that are bankrupt, under liquidation or deleted are flagged via registryStatus.
+// @Tags Customers
+// @Accept json
+// @Produce json
+// @Param id path string true "Customer ID" format(uuid)
+// @Param request body domain.EnrichCustomerRequest false "Enrichment options"
+// @Success 200 {object} domain.CustomerEnrichmentResultDTO
+// @Failure 400 {object} domain.ErrorResponse "Invalid ID or customer has no organization number"
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Failure 503 {object} domain.ErrorResponse "Registry integration not available"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /customers/{id}/enrich [post]
+func (h *CustomerHandler) EnrichFromRegistry(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		respondJSON(w, http.StatusBadRequest, domain.ErrorResponse{
+			Error:   "Bad Request",
+			Message: "Invalid customer ID",
+		})
+		return
+	}
+
+	// Request body is optional
+	var req domain.EnrichCustomerRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		respondJSON(w, http.StatusBadRequest, domain.ErrorResponse{
+			Error:   "Bad Request",
+			Message: "Invalid request body",
+		})
+		return
+	}
+
+	result, err := h.customerService.EnrichFromRegistry(r.Context(), id, req.Overwrite)
+	if err != nil {
+		h.handleRegistryError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, result)
+}
+
+// BulkEnrichFromRegistry godoc
+// @Summary Bulk enrich customers from Enhetsregisteret
+// @Description Enrich several customers from Enhetsregisteret. Pass customerIds to enrich specific customers, or omit them to process customers that have not been checked within staleDays (default 30), oldest first. At most limit (default 100, max 500) customers are processed per request.
+// @Tags Customers
+// @Accept json
+// @Produce json
+// @Param request body domain.BulkEnrichCustomersRequest true "Bulk enrichment options"
+// @Success 200 {object} domain.BulkCustomerEnrichmentResultDTO
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 500 {object} domain.ErrorResponse
+// @Failure 503 {object} domain.ErrorResponse "Registry integration not available"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /customers/enrich [post]
+func (h *CustomerHandler) BulkEnrichFromRegistry(w http.ResponseWriter, r *http.Request) {
+	var req domain.BulkEnrichCustomersRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		respondJSON(w, http.StatusBadRequest, domain.ErrorResponse{
+			Error:   "Bad Request",
+			Message: "Invalid request body",
+		})
+		return
+	}
+
+	if err := validate.Struct(req); err != nil {
+		respondValidationError(w, err)
+		return
+	}
+
+	result, err := h.customerService.BulkEnrichFromRegistry(r.Context(), &req)
+	if err != nil {
+		h.handleRegistryError(w, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, result)
+}
+
+// handleRegistryError maps registry enrichment errors to HTTP responses
+func (h *CustomerHandler) handleRegistryError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrCustomerNotFound):
+		respondJSON(w, http.StatusNotFound, domain.ErrorResponse{
+			Error:   "Not Found",
+			Message: "Customer not found",
+		})
+	case errors.Is(err, service.ErrCustomerMissingOrgNumber):
+		respondJSON(w, http.StatusBadRequest, domain.ErrorResponse{
+			Error:   "Bad Request",
+			Message: "Customer has no organization number",
+		})
+	case errors.Is(err, service.ErrRegistryNotAvailable):
+		respondJSON(w, http.StatusServiceUnavailable, domain.ErrorResponse{
+			Error:   "Service Unavailable",
+			Message: "Enhetsregisteret integration is not available",
+		})
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		h.logger.Warn("registry enrichment stopped", zap.Error(err))
+		respondJSON(w, http.StatusServiceUnavailable, domain.ErrorResponse{
+			Error:   "Service Unavailable",
+			Message: "Enrichment from Enhetsregisteret was stopped before it completed",
+		})
+	default:
+		h.logger.Error("failed to enrich customer from registry", zap.Error(err))
+		respondJSON(w, http.StatusInternalServerError, domain.ErrorResponse{
+			Error:   "Internal Server Error",
+			Message: "Failed to enrich customer from Enhetsregisteret",
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/straye-as/relation-api/internal/domain"
+	"github.com/straye-as/relation-api/internal/service"
+	"go.uber.org/zap"
+)
+
+// ============================================================================
+// Customer Group Endpoints
+// ============================================================================
+
+// GetGroup godoc
+// @Summary Get customer group
+// @Description Returns the customer with its parent customers and all its subsidiaries. Statistics (active offer value, won value, project counts) are rolled up over the customer and its subsidiaries.
+// @Tags Customers
+// @Produce json
+// @Param id path string true "Customer ID" format(uuid)
+// @Success 200 {object} domain.CustomerGroupDTO
+// @Failure 400 {object} domain.APIError
+// @Failure 404 {object} domain.APIError
+// @Failure 500 {object} domain.APIError
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /customers/{id}/group [get]
+func (h *CustomerHandler) GetGroup(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid customer ID")
+		return
+	}
+
+	group, err := h.customerService.GetGroup(r.Context(), id)
+	if err != nil {
+		h.handleGroupError(w, err, "failed to get customer group")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, group)
+}
+
+// UpdateParent godoc
+// @Summary Update customer parent
+// @Description Places the customer under a parent customer in a customer group. Set parentCustomerId to null to remove the customer from its group. A customer cannot be placed under itself or one of its subsidiaries.
+// @Tags Customers
+// @Accept json
+// @Produce json
+// @Param id path string true "Customer ID" format(uuid)
+// @Param request body domain.UpdateCustomerParentRequest true "Parent customer"
+// @Success 200 {object} domain.CustomerDTO
+// @Failure 400 {object} domain.APIError "Invalid request, unknown parent or group too deep"
+// @Failure 404 {object} domain.APIError
+// @Failure 409 {object} domain.APIError "Parent would create a cycle"
+// @Failure 500 {object} domain.APIError
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /customers/{id}/parent [put]
+func (h *CustomerHandler) UpdateParent(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid customer ID")
+		return
+	}
+
+	var req domain.UpdateCustomerParentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	customer, err := h.customerService.UpdateParent(r.Context(), id, req.ParentCustomerID)
+	if err != nil {
+		h.handleGroupError(w, err, "failed to update customer parent")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, customer)
+}
+
+// handleGroupError maps customer group service errors to HTTP responses
+func (h *CustomerHandler) handleGroupError(w http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, service.ErrCustomerNotFound):
+		respondWithError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, service.ErrCustomerGroupCycle):
+		respondWithError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, service.ErrParentCustomerNotFound),
+		errors.Is(err, service.ErrCustomerGroupTooDeep):
+		respondWithError(w, http.StatusBadRequest, err.Error())
+	default:
+		h.logger.Error(message, zap.Error(err))
+		respondWithError(w, http.StatusInternalServerError, message)
+	}
+}
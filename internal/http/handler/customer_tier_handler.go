@@ -0,0 +1,235 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/straye-as/relation-api/internal/domain"
+	"github.com/straye-as/relation-api/internal/service"
+	"go.uber.org/zap"
+)
+
+// CustomerTierHandler handles HTTP requests for customer tier rules, recalculation and history
+type CustomerTierHandler struct {
+	tierService *service.CustomerTierService
+	logger      *zap.Logger
+}
+
+// NewCustomerTierHandler creates a new CustomerTierHandler instance
+func NewCustomerTierHandler(tierService *service.CustomerTierService, logger *zap.Logger) *CustomerTierHandler {
+	return &CustomerTierHandler{
+		tierService: tierService,
+		logger:      logger,
+	}
+}
+
+// ListRules godoc
+// @Summary List customer tier rules
+// @Description Returns the tier rule per company. The rule for "all" applies to customers without a company-specific rule.
+// @Tags Customers
+// @Produce json
+// @Success 200 {array} domain.CustomerTierRuleDTO
+// @Failure 500 {object} domain.APIError
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /customers/tier-rules [get]
+func (h *CustomerTierHandler) ListRules(w http.ResponseWriter, r *http.Request) {
+	rules, err := h.tierService.ListRules(r.Context())
+	if err != nil {
+		h.handleTierError(w, err, "failed to list customer tier rules")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, rules)
+}
+
+// UpdateRule godoc
+// @Summary Update customer tier rule
+// @Description Creates or replaces the tier rule for a company ("all" for the default rule). Tiers are updated by the next recalculation.
+// @Tags Customers
+// @Accept json
+// @Produce json
+// @Param companyId path string true "Company ID, or all for the default rule"
+// @Param request body domain.UpdateCustomerTierRuleRequest true "Tier rule"
+// @Success 200 {object} domain.CustomerTierRuleDTO
+// @Failure 400 {object} domain.APIError
+// @Failure 500 {object} domain.APIError
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /customers/tier-rules/{companyId} [put]
+func (h *CustomerTierHandler) UpdateRule(w http.ResponseWriter, r *http.Request) {
+	companyID := domain.CompanyID(chi.URLParam(r, "companyId"))
+
+	var req domain.UpdateCustomerTierRuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if err := validate.Struct(req); err != nil {
+		respondValidationError(w, err)
+		return
+	}
+
+	rule, err := h.tierService.UpdateRule(r.Context(), companyID, &req)
+	if err != nil {
+		h.handleTierError(w, err, "failed to update customer tier rule")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, rule)
+}
+
+// DeleteRule godoc
+// @Summary Delete customer tier rule
+// @Description Removes a company-specific tier rule so the company uses the default rule. The default rule cannot be deleted.
+// @Tags Customers
+// @Param companyId path string true "Company ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} domain.APIError
+// @Failure 404 {object} domain.APIError
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /customers/tier-rules/{companyId} [delete]
+func (h *CustomerTierHandler) DeleteRule(w http.ResponseWriter, r *http.Request) {
+	companyID := domain.CompanyID(chi.URLParam(r, "companyId"))
+
+	if err := h.tierService.DeleteRule(r.Context(), companyID); err != nil {
+		h.handleTierError(w, err, "failed to delete customer tier rule")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RecalculateAll godoc
+// @Summary Recalculate customer tiers
+// @Description Recalculates the tier of every customer from its company's tier rule now. Customers with a locked tier are skipped. Runs nightly by default.
+// @Tags Customers
+// @Produce json
+// @Success 200 {object} domain.CustomerTierRecalculationDTO
+// @Failure 409 {object} domain.APIError "A recalculation is already in progress"
+// @Failure 500 {object} domain.APIError
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /customers/tier-recalculation [post]
+func (h *CustomerTierHandler) RecalculateAll(w http.ResponseWriter, r *http.Request) {
+	result, err := h.tierService.RecalculateAll(r.Context())
+	if err != nil {
+		h.handleTierError(w, err, "customer tier recalculation failed")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, result)
+}
+
+// Recalculate godoc
+// @Summary Recalculate customer tier
+// @Description Recalculates the tier of a single customer from its company's tier rule
+// @Tags Customers
+// @Produce json
+// @Param id path string true "Customer ID" format(uuid)
+// @Success 200 {object} domain.CustomerDTO
+// @Failure 400 {object} domain.APIError
+// @Failure 404 {object} domain.APIError
+// @Failure 409 {object} domain.APIError "Tier is locked by a manual override"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /customers/{id}/tier/recalculate [post]
+func (h *CustomerTierHandler) Recalculate(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid customer ID")
+		return
+	}
+
+	customer, err := h.tierService.RecalculateCustomer(r.Context(), id)
+	if err != nil {
+		h.handleTierError(w, err, "failed to recalculate customer tier")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, customer)
+}
+
+// Unlock godoc
+// @Summary Unlock customer tier
+// @Description Removes the manual tier override set by PUT /customers/{id}/tier and recalculates the tier immediately
+// @Tags Customers
+// @Produce json
+// @Param id path string true "Customer ID" format(uuid)
+// @Success 200 {object} domain.CustomerDTO
+// @Failure 400 {object} domain.APIError
+// @Failure 404 {object} domain.APIError
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /customers/{id}/tier/lock [delete]
+func (h *CustomerTierHandler) Unlock(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid customer ID")
+		return
+	}
+
+	customer, err := h.tierService.UnlockTier(r.Context(), id)
+	if err != nil {
+		h.handleTierError(w, err, "failed to unlock customer tier")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, customer)
+}
+
+// GetHistory godoc
+// @Summary Get customer tier history
+// @Description Returns the tier changes of a customer, from recalculation and manual overrides, newest first
+// @Tags Customers
+// @Produce json
+// @Param id path string true "Customer ID" format(uuid)
+// @Param page query int false "Page number" default(1)
+// @Param pageSize query int false "Items per page (max 200)" default(20)
+// @Success 200 {object} domain.PaginatedResponse{data=[]domain.CustomerTierHistoryDTO}
+// @Failure 400 {object} domain.APIError
+// @Failure 404 {object} domain.APIError
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /customers/{id}/tier/history [get]
+func (h *CustomerTierHandler) GetHistory(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid customer ID")
+		return
+	}
+
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	pageSize, _ := strconv.Atoi(r.URL.Query().Get("pageSize"))
+
+	result, err := h.tierService.GetHistory(r.Context(), id, page, pageSize)
+	if err != nil {
+		h.handleTierError(w, err, "failed to get customer tier history")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, result)
+}
+
+// handleTierError maps customer tier service errors to HTTP responses
+func (h *CustomerTierHandler) handleTierError(w http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, service.ErrCustomerNotFound),
+		errors.Is(err, service.ErrCustomerTierRuleNotFound):
+		respondWithError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, service.ErrCustomerTierRecalculationRunning),
+		errors.Is(err, service.ErrCustomerTierLocked):
+		respondWithError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, service.ErrInvalidCustomerTierRule),
+		errors.Is(err, service.ErrDefaultCustomerTierRuleRequired):
+		respondWithError(w, http.StatusBadRequest, err.Error())
+	default:
+		h.logger.Error(message, zap.Error(err))
+		respondWithError(w, http.StatusInternalServerError, message)
+	}
+}
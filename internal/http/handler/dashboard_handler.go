@@ -78,17 +78,53 @@ func NewDashboardHandler(dashboardService *service.DashboardService, logger *zap
 // @Description - `recentOrders`: Offers in order phase (Siste ordre), sorted by update recency
 // @Description
 // @Description **Top Customers:** Ranked by won offer count (order + completed phases) with total won value
+// @Description
+// @Description **Intercompany View:**
+// @Description Offers to internal customers (Straye group companies) are work sold between group companies.
+// @Description - `all` (default): Internal and external customers are counted together
+// @Description - `consolidated`: Offers to internal customers are eliminated from all offer and order metrics
+// @Description - `split`: As `all`, and `intercompany` reports the key figures for internal and external customers separately
 // @Tags Dashboard
 // @Produce json
 // @Param timeRange query string false "Time range for metrics (ignored if fromDate/toDate provided)" Enums(rolling12months, allTime) default(rolling12months)
 // @Param fromDate query string false "Start date for custom range (YYYY-MM-DD format, 00:00:00)"
 // @Param toDate query string false "End date for custom range (YYYY-MM-DD format, 23:59:59)"
+// @Param view query string false "Treatment of offers to internal customers" Enums(all, consolidated, split) default(all)
 // @Success 200 {object} domain.DashboardMetrics
-// @Failure 400 {object} domain.APIError "Invalid timeRange value or date format"
+// @Failure 400 {object} domain.APIError "Invalid timeRange, view value or date format"
 // @Security BearerAuth
 // @Security ApiKeyAuth
 // @Router /dashboard/metrics [get]
 func (h *DashboardHandler) GetMetrics(w http.ResponseWriter, r *http.Request) {
+	timeRange, dateRange, ok := parseDashboardTimeRange(w, r)
+	if !ok {
+		return
+	}
+
+	// Parse intercompany view (defaults to all customers)
+	view := domain.IntercompanyViewAll
+	if viewParam := r.URL.Query().Get("view"); viewParam != "" {
+		view = domain.IntercompanyView(viewParam)
+		if !view.IsValid() {
+			respondWithError(w, http.StatusBadRequest,
+				fmt.Sprintf("Invalid view value: '%s'. Must be one of: all, consolidated, split", viewParam))
+			return
+		}
+	}
+
+	metrics, err := h.dashboardService.GetMetrics(r.Context(), timeRange, dateRange, view)
+	if err != nil {
+		h.logger.Error("failed to get dashboard metrics", zap.Error(err))
+		respondWithError(w, http.StatusInternalServerError, "Failed to retrieve dashboard metrics")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, metrics)
+}
+
+// parseDashboardTimeRange parses the timeRange, fromDate and toDate query parameters.
+// Writes a 400 response and returns false when a parameter is invalid.
+func parseDashboardTimeRange(w http.ResponseWriter, r *http.Request) (domain.TimeRange, *domain.DateRangeFilter, bool) {
 	// Parse date range parameters
 	fromDateParam := r.URL.Query().Get("fromDate")
 	toDateParam := r.URL.Query().Get("toDate")
@@ -105,7 +141,7 @@ func (h *DashboardHandler) GetMetrics(w http.ResponseWriter, r *http.Request) {
 			if err != nil {
 				respondWithError(w, http.StatusBadRequest,
 					fmt.Sprintf("Invalid fromDate format: '%s'. Expected YYYY-MM-DD (e.g., 2024-01-01)", fromDateParam))
-				return
+				return "", nil, false
 			}
 			// Set to start of day
 			startOfDay := time.Date(parsedDate.Year(), parsedDate.Month(), parsedDate.Day(), 0, 0, 0, 0, parsedDate.Location())
@@ -118,7 +154,7 @@ func (h *DashboardHandler) GetMetrics(w http.ResponseWriter, r *http.Request) {
 			if err != nil {
 				respondWithError(w, http.StatusBadRequest,
 					fmt.Sprintf("Invalid toDate format: '%s'. Expected YYYY-MM-DD (e.g., 2024-12-31)", toDateParam))
-				return
+				return "", nil, false
 			}
 			// Set to end of day
 			endOfDay := time.Date(parsedDate.Year(), parsedDate.Month(), parsedDate.Day(), 23, 59, 59, 999999999, parsedDate.Location())
@@ -141,18 +177,11 @@ func (h *DashboardHandler) GetMetrics(w http.ResponseWriter, r *http.Request) {
 		if !timeRange.IsValid() || timeRange == domain.TimeRangeCustom {
 			respondWithError(w, http.StatusBadRequest,
 				fmt.Sprintf("Invalid timeRange value: '%s'. Must be one of: rolling12months, allTime", timeRangeParam))
-			return
+			return "", nil, false
 		}
 	}
 
-	metrics, err := h.dashboardService.GetMetrics(r.Context(), timeRange, dateRange)
-	if err != nil {
-		h.logger.Error("failed to get dashboard metrics", zap.Error(err))
-		respondWithError(w, http.StatusInternalServerError, "Failed to retrieve dashboard metrics")
-		return
-	}
-
-	respondJSON(w, http.StatusOK, metrics)
+	return timeRange, dateRange, true
 }
 
 // @Summary Global search
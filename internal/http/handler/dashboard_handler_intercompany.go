@@ -0,0 +1,40 @@
+package handler
+
+import (
+	"net/http"
+
+	"go.uber.org/zap"
+)
+
+// GetIntercompanyMatrix godoc
+// @Summary Get intercompany matrix
+// @Description Returns won offers (order and completed phases) sold by one Straye group company to another, for elimination in the group accounts.
+// @Description Each cell is one selling company and one internal customer. `buyerCompanyId` is the group company with the same organization number as the internal customer, and is omitted when no company matches.
+// @Description Uses the same time range options as the dashboard metrics. Requires the reports:view permission.
+// @Tags Dashboard
+// @Produce json
+// @Param timeRange query string false "Time range (ignored if fromDate/toDate provided)" Enums(rolling12months, allTime) default(rolling12months)
+// @Param fromDate query string false "Start date for custom range (YYYY-MM-DD format, 00:00:00)"
+// @Param toDate query string false "End date for custom range (YYYY-MM-DD format, 23:59:59)"
+// @Success 200 {object} domain.IntercompanyMatrixDTO
+// @Failure 400 {object} domain.APIError "Invalid timeRange value or date format"
+// @Failure 403 {object} domain.APIError
+// @Failure 500 {object} domain.APIError
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /dashboard/intercompany-matrix [get]
+func (h *DashboardHandler) GetIntercompanyMatrix(w http.ResponseWriter, r *http.Request) {
+	timeRange, dateRange, ok := parseDashboardTimeRange(w, r)
+	if !ok {
+		return
+	}
+
+	matrix, err := h.dashboardService.GetIntercompanyMatrix(r.Context(), timeRange, dateRange)
+	if err != nil {
+		h.logger.Error("failed to get intercompany matrix", zap.Error(err))
+		respondWithError(w, http.StatusInternalServerError, "Failed to retrieve intercompany matrix")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, matrix)
+}
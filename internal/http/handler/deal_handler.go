@@ -44,6 +44,7 @@ func NewDealHandler(dealService *service.DealService, logger *zap.Logger) *DealH
 // @Param createdBefore query string false "Created before date (YYYY-MM-DD)"
 // @Param closeAfter query string false "Expected close after date (YYYY-MM-DD)"
 // @Param closeBefore query string false "Expected close before date (YYYY-MM-DD)"
+// @Param stale query bool false "Filter by staleness (no stage change or activity within the stage's threshold)"
 // @Param sort query string false "Sort by (created_desc, created_asc, value_desc, value_asc, probability_desc, probability_asc, close_date_desc, close_date_asc, weighted_desc, weighted_asc)"
 // @Success 200 {object} domain.PaginatedResponse
 // @Security BearerAuth
@@ -59,6 +60,21 @@ func (h *DealHandler) List(w http.ResponseWriter, r *http.Request) {
 		pageSize = 20
 	}
 
+	filters, sortBy := parseDealFilters(r)
+
+	result, err := h.dealService.List(r.Context(), page, pageSize, filters, sortBy)
+	if err != nil {
+		h.logger.Error("failed to list deals", zap.Error(err))
+		respondWithError(w, http.StatusInternalServerError, "Failed to list deals")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, result)
+}
+
+// parseDealFilters parses the deal list filters and sort option from the query string.
+// Shared by the list and export endpoints so both return the same deals.
+func parseDealFilters(r *http.Request) (*repository.DealFilters, repository.DealSortOption) {
 	filters := &repository.DealFilters{}
 
 	// Stage filter
@@ -129,20 +145,20 @@ func (h *DealHandler) List(w http.ResponseWriter, r *http.Request) {
 		filters.SearchQuery = &q
 	}
 
+	// Staleness filter
+	if st := r.URL.Query().Get("stale"); st != "" {
+		if stale, err := strconv.ParseBool(st); err == nil {
+			filters.Stale = &stale
+		}
+	}
+
 	// Sort option
 	sortBy := repository.DealSortByCreatedDesc
 	if s := r.URL.Query().Get("sort"); s != "" {
 		sortBy = repository.DealSortOption(s)
 	}
 
-	result, err := h.dealService.List(r.Context(), page, pageSize, filters, sortBy)
-	if err != nil {
-		h.logger.Error("failed to list deals", zap.Error(err))
-		respondWithError(w, http.StatusInternalServerError, "Failed to list deals")
-		return
-	}
-
-	respondJSON(w, http.StatusOK, result)
+	return filters, sortBy
 }
 
 // @Summary Create deal
@@ -173,6 +189,18 @@ func (h *DealHandler) Create(w http.ResponseWriter, r *http.Request) {
 			respondWithError(w, http.StatusBadRequest, "Customer not found")
 			return
 		}
+		if errors.Is(err, service.ErrDealPipelineNotFound) {
+			respondWithError(w, http.StatusBadRequest, "Deal pipeline not found")
+			return
+		}
+		if errors.Is(err, service.ErrInvalidDealPipeline) {
+			respondWithError(w, http.StatusBadRequest, "Deal pipeline belongs to another company")
+			return
+		}
+		if errors.Is(err, service.ErrInvalidDealStage) || errors.Is(err, service.ErrDealStageRequirementsNotMet) {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
 		h.logger.Error("failed to create deal", zap.Error(err))
 		respondWithError(w, http.StatusInternalServerError, "Failed to create deal")
 		return
@@ -265,6 +293,10 @@ func (h *DealHandler) Update(w http.ResponseWriter, r *http.Request) {
 			respondWithError(w, http.StatusNotFound, "Deal not found")
 			return
 		}
+		if errors.Is(err, service.ErrInvalidDealStage) || errors.Is(err, service.ErrDealStageRequirementsNotMet) {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
 		h.logger.Error("failed to update deal", zap.Error(err), zap.String("deal_id", id.String()))
 		respondWithError(w, http.StatusInternalServerError, "Failed to update deal")
 		return
@@ -538,16 +570,34 @@ func (h *DealHandler) GetActivities(w http.ResponseWriter, r *http.Request) {
 }
 
 // @Summary Get pipeline overview
-// @Description Get all deals grouped by stage for pipeline view
+// @Description Get all deals grouped by stage for pipeline view. With pipelineId only that pipeline's deals are included, with an entry for each of its open stages.
 // @Tags Deals
 // @Produce json
+// @Param pipelineId query string false "Deal pipeline ID"
 // @Success 200 {object} map[string][]domain.DealDTO
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
 // @Security BearerAuth
 // @Security ApiKeyAuth
 // @Router /deals/pipeline [get]
 func (h *DealHandler) GetPipelineOverview(w http.ResponseWriter, r *http.Request) {
-	pipeline, err := h.dealService.GetPipelineOverview(r.Context())
+	pipelineID, ok := parsePipelineIDQuery(w, r)
+	if !ok {
+		return
+	}
+
+	var pipeline map[string][]domain.DealDTO
+	var err error
+	if pipelineID != nil {
+		pipeline, err = h.dealService.GetPipelineOverviewByPipeline(r.Context(), *pipelineID)
+	} else {
+		pipeline, err = h.dealService.GetPipelineOverview(r.Context())
+	}
 	if err != nil {
+		if errors.Is(err, service.ErrDealPipelineNotFound) {
+			respondWithError(w, http.StatusNotFound, "Deal pipeline not found")
+			return
+		}
 		h.logger.Error("failed to get pipeline overview", zap.Error(err))
 		respondWithError(w, http.StatusInternalServerError, "Failed to get pipeline overview")
 		return
@@ -557,16 +607,34 @@ func (h *DealHandler) GetPipelineOverview(w http.ResponseWriter, r *http.Request
 }
 
 // @Summary Get pipeline statistics
-// @Description Get aggregated statistics for the sales pipeline
+// @Description Get aggregated statistics for the sales pipeline, optionally for a single deal pipeline
 // @Tags Deals
 // @Produce json
+// @Param pipelineId query string false "Deal pipeline ID"
 // @Success 200 {object} repository.PipelineStats
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
 // @Security BearerAuth
 // @Security ApiKeyAuth
 // @Router /deals/stats [get]
 func (h *DealHandler) GetPipelineStats(w http.ResponseWriter, r *http.Request) {
-	stats, err := h.dealService.GetPipelineStats(r.Context())
+	pipelineID, ok := parsePipelineIDQuery(w, r)
+	if !ok {
+		return
+	}
+
+	var stats *repository.PipelineStats
+	var err error
+	if pipelineID != nil {
+		stats, err = h.dealService.GetPipelineStatsByPipeline(r.Context(), *pipelineID)
+	} else {
+		stats, err = h.dealService.GetPipelineStats(r.Context())
+	}
 	if err != nil {
+		if errors.Is(err, service.ErrDealPipelineNotFound) {
+			respondWithError(w, http.StatusNotFound, "Deal pipeline not found")
+			return
+		}
 		h.logger.Error("failed to get pipeline stats", zap.Error(err))
 		respondWithError(w, http.StatusInternalServerError, "Failed to get pipeline statistics")
 		return
@@ -575,6 +643,21 @@ func (h *DealHandler) GetPipelineStats(w http.ResponseWriter, r *http.Request) {
 	respondJSON(w, http.StatusOK, stats)
 }
 
+// parsePipelineIDQuery parses the optional pipelineId query parameter.
+// Returns false after responding with 400 if it is not a valid UUID.
+func parsePipelineIDQuery(w http.ResponseWriter, r *http.Request) (*uuid.UUID, bool) {
+	value := r.URL.Query().Get("pipelineId")
+	if value == "" {
+		return nil, true
+	}
+	id, err := uuid.Parse(value)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid pipeline ID: must be a valid UUID")
+		return nil, false
+	}
+	return &id, true
+}
+
 // @Summary Get pipeline forecast
 // @Description Get forecast data for upcoming months
 // @Tags Deals
@@ -608,6 +691,7 @@ func (h *DealHandler) GetForecast(w http.ResponseWriter, r *http.Request) {
 // @Tags Deals
 // @Produce json
 // @Param companyId query string false "Filter by company ID"
+// @Param pipelineId query string false "Deal pipeline to calculate conversion rates for (defaults to the company's default pipeline)"
 // @Param ownerId query string false "Filter by owner ID"
 // @Param dateFrom query string false "Filter by date from (YYYY-MM-DD)"
 // @Param dateTo query string false "Filter by date to (YYYY-MM-DD)"
@@ -625,6 +709,12 @@ func (h *DealHandler) GetPipelineAnalytics(w http.ResponseWriter, r *http.Reques
 		filters.CompanyID = &companyID
 	}
 
+	pipelineID, ok := parsePipelineIDQuery(w, r)
+	if !ok {
+		return
+	}
+	filters.PipelineID = pipelineID
+
 	if ownerID := r.URL.Query().Get("ownerId"); ownerID != "" {
 		filters.OwnerID = &ownerID
 	}
@@ -704,7 +794,7 @@ func (h *DealHandler) CreateOffer(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 		if errors.Is(err, service.ErrDealInvalidStageForOffer) {
-			respondWithError(w, http.StatusBadRequest, "Deal must be in lead or qualified stage to create an offer")
+			respondWithError(w, http.StatusBadRequest, "Deal must be in a stage before proposal to create an offer")
 			return
 		}
 		h.logger.Error("failed to create offer from deal", zap.Error(err), zap.String("deal_id", id.String()))
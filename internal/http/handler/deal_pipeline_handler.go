@@ -0,0 +1,203 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/straye-as/relation-api/internal/domain"
+	"github.com/straye-as/relation-api/internal/service"
+	"go.uber.org/zap"
+)
+
+// DealPipelineHandler handles HTTP requests for the deal pipelines configured per company
+type DealPipelineHandler struct {
+	pipelineService *service.DealPipelineService
+	logger          *zap.Logger
+}
+
+// NewDealPipelineHandler creates a new DealPipelineHandler instance
+func NewDealPipelineHandler(pipelineService *service.DealPipelineService, logger *zap.Logger) *DealPipelineHandler {
+	return &DealPipelineHandler{
+		pipelineService: pipelineService,
+		logger:          logger,
+	}
+}
+
+// List godoc
+// @Summary List deal pipelines
+// @Description Returns the deal pipelines with their stages in order. Every company has a default pipeline used for new deals.
+// @Tags Deals
+// @Produce json
+// @Param companyId query string false "Filter by company ID"
+// @Success 200 {array} domain.DealPipelineDTO
+// @Failure 500 {object} domain.APIError
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /deals/pipelines [get]
+func (h *DealPipelineHandler) List(w http.ResponseWriter, r *http.Request) {
+	var companyID *domain.CompanyID
+	if value := r.URL.Query().Get("companyId"); value != "" {
+		id := domain.CompanyID(value)
+		companyID = &id
+	}
+
+	pipelines, err := h.pipelineService.List(r.Context(), companyID)
+	if err != nil {
+		h.handlePipelineError(w, err, "failed to list deal pipelines")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, pipelines)
+}
+
+// GetByID godoc
+// @Summary Get deal pipeline
+// @Description Returns a deal pipeline with its stages, probabilities, required fields and allowed transitions
+// @Tags Deals
+// @Produce json
+// @Param id path string true "Pipeline ID" format(uuid)
+// @Success 200 {object} domain.DealPipelineDTO
+// @Failure 400 {object} domain.APIError
+// @Failure 404 {object} domain.APIError
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /deals/pipelines/{id} [get]
+func (h *DealPipelineHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid pipeline ID: must be a valid UUID")
+		return
+	}
+
+	pipeline, err := h.pipelineService.GetByID(r.Context(), id)
+	if err != nil {
+		h.handlePipelineError(w, err, "failed to get deal pipeline")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, pipeline)
+}
+
+// Create godoc
+// @Summary Create deal pipeline
+// @Description Creates a pipeline for a company. Open stages are ordered as given; won and lost are added when omitted. Requires manager or admin role.
+// @Tags Deals
+// @Accept json
+// @Produce json
+// @Param request body domain.CreateDealPipelineRequest true "Pipeline with stages"
+// @Success 201 {object} domain.DealPipelineDTO
+// @Failure 400 {object} domain.APIError
+// @Failure 403 {object} domain.APIError
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /deals/pipelines [post]
+func (h *DealPipelineHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req domain.CreateDealPipelineRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body: malformed JSON")
+		return
+	}
+	if err := validate.Struct(req); err != nil {
+		respondValidationError(w, err)
+		return
+	}
+
+	pipeline, err := h.pipelineService.Create(r.Context(), &req)
+	if err != nil {
+		h.handlePipelineError(w, err, "failed to create deal pipeline")
+		return
+	}
+
+	w.Header().Set("Location", "/api/v1/deals/pipelines/"+pipeline.ID.String())
+	respondJSON(w, http.StatusCreated, pipeline)
+}
+
+// Update godoc
+// @Summary Update deal pipeline
+// @Description Replaces the name and stages of a pipeline. Stages that deals are currently in cannot be removed. Requires manager or admin role.
+// @Tags Deals
+// @Accept json
+// @Produce json
+// @Param id path string true "Pipeline ID" format(uuid)
+// @Param request body domain.UpdateDealPipelineRequest true "Pipeline with stages"
+// @Success 200 {object} domain.DealPipelineDTO
+// @Failure 400 {object} domain.APIError
+// @Failure 403 {object} domain.APIError
+// @Failure 404 {object} domain.APIError
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /deals/pipelines/{id} [put]
+func (h *DealPipelineHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid pipeline ID: must be a valid UUID")
+		return
+	}
+
+	var req domain.UpdateDealPipelineRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body: malformed JSON")
+		return
+	}
+	if err := validate.Struct(req); err != nil {
+		respondValidationError(w, err)
+		return
+	}
+
+	pipeline, err := h.pipelineService.Update(r.Context(), id, &req)
+	if err != nil {
+		h.handlePipelineError(w, err, "failed to update deal pipeline")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, pipeline)
+}
+
+// Delete godoc
+// @Summary Delete deal pipeline
+// @Description Deletes a pipeline without deals. The default pipeline of a company cannot be deleted. Requires manager or admin role.
+// @Tags Deals
+// @Param id path string true "Pipeline ID" format(uuid)
+// @Success 204 "No Content"
+// @Failure 400 {object} domain.APIError
+// @Failure 404 {object} domain.APIError
+// @Failure 409 {object} domain.APIError "Pipeline has deals"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /deals/pipelines/{id} [delete]
+func (h *DealPipelineHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid pipeline ID: must be a valid UUID")
+		return
+	}
+
+	if err := h.pipelineService.Delete(r.Context(), id); err != nil {
+		h.handlePipelineError(w, err, "failed to delete deal pipeline")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *DealPipelineHandler) handlePipelineError(w http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, service.ErrDealPipelineNotFound):
+		respondWithError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, service.ErrUnauthorized):
+		respondWithError(w, http.StatusUnauthorized, err.Error())
+	case errors.Is(err, service.ErrForbidden):
+		respondWithError(w, http.StatusForbidden, "Insufficient permissions to manage deal pipelines")
+	case errors.Is(err, service.ErrDealPipelineInUse):
+		respondWithError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, service.ErrInvalidDealPipeline),
+		errors.Is(err, service.ErrDefaultDealPipelineRequired):
+		respondWithError(w, http.StatusBadRequest, err.Error())
+	default:
+		h.logger.Error(message, zap.Error(err))
+		respondWithError(w, http.StatusInternalServerError, message)
+	}
+}
@@ -0,0 +1,113 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/straye-as/relation-api/internal/domain"
+	"github.com/straye-as/relation-api/internal/service"
+	"go.uber.org/zap"
+)
+
+// EmailHandler handles HTTP requests for email ingestion
+type EmailHandler struct {
+	emailService *service.EmailIngestionService
+	logger       *zap.Logger
+}
+
+// NewEmailHandler creates a new EmailHandler instance
+func NewEmailHandler(emailService *service.EmailIngestionService, logger *zap.Logger) *EmailHandler {
+	return &EmailHandler{
+		emailService: emailService,
+		logger:       logger,
+	}
+}
+
+// Ingest godoc
+// @Summary Ingest email
+// @Description Files an uploaded email (.eml) as a completed email activity. The email is filed on the offer whose number appears in the subject (e.g. TK-2025-001), else on the primary customer of a contact, else on the supplier of a supplier contact whose email matches the sender or a recipient. Attachments are stored as files on the same entity. Emails that match nothing are recorded as unmatched; emails ingested before are returned with status duplicate.
+// @Tags Emails
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "Email file (.eml)"
+// @Success 201 {object} domain.EmailIngestionResultDTO "Email filed on a matching entity"
+// @Success 200 {object} domain.EmailIngestionResultDTO "Email unmatched or already ingested"
+// @Failure 400 {object} domain.APIError
+// @Failure 500 {object} domain.APIError
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /emails/ingest [post]
+func (h *EmailHandler) Ingest(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, service.MaxEmailSizeMB*1024*1024)
+	if err := r.ParseMultipartForm(service.MaxEmailSizeMB * 1024 * 1024); err != nil {
+		respondWithError(w, http.StatusBadRequest, "file too large or invalid form")
+		return
+	}
+
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "file field is required")
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "failed to read file")
+		return
+	}
+
+	result, err := h.emailService.Ingest(r.Context(), data, domain.EmailSourceUpload)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidEmailMessage), errors.Is(err, service.ErrTooManyEmailAttachments):
+			respondWithError(w, http.StatusBadRequest, err.Error())
+		default:
+			h.logger.Error("failed to ingest email", zap.Error(err))
+			respondWithError(w, http.StatusInternalServerError, "failed to ingest email")
+		}
+		return
+	}
+
+	status := http.StatusOK
+	if result.Email.Status == domain.EmailIngestionMatched {
+		status = http.StatusCreated
+	}
+	respondJSON(w, status, result)
+}
+
+// List godoc
+// @Summary List ingested emails
+// @Description Returns ingested emails, newest first. Use status=unmatched to review emails that could not be filed on a customer, offer or supplier.
+// @Tags Emails
+// @Produce json
+// @Param status query string false "Filter by status" Enums(matched, unmatched)
+// @Param page query int false "Page number (default: 1)"
+// @Param pageSize query int false "Page size (default: 20, max: 200)"
+// @Success 200 {object} domain.PaginatedResponse{data=[]domain.IngestedEmailDTO}
+// @Failure 400 {object} domain.APIError
+// @Failure 500 {object} domain.APIError
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /emails [get]
+func (h *EmailHandler) List(w http.ResponseWriter, r *http.Request) {
+	var status *domain.EmailIngestionStatus
+	if value := r.URL.Query().Get("status"); value != "" {
+		s := domain.EmailIngestionStatus(value)
+		if !s.IsValid() {
+			respondWithError(w, http.StatusBadRequest, "Invalid status: must be matched or unmatched")
+			return
+		}
+		status = &s
+	}
+
+	result, err := h.emailService.List(r.Context(), status, parseIntQuery(r, "page", 1), parseIntQuery(r, "pageSize", 20))
+	if err != nil {
+		h.logger.Error("failed to list ingested emails", zap.Error(err))
+		respondWithError(w, http.StatusInternalServerError, "failed to list ingested emails")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, result)
+}
@@ -0,0 +1,323 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/straye-as/relation-api/internal/domain"
+	"github.com/straye-as/relation-api/internal/repository"
+	"github.com/straye-as/relation-api/internal/service"
+	"go.uber.org/zap"
+)
+
+// ERPReconciliationHandler handles HTTP requests for ERP customer reconciliation
+type ERPReconciliationHandler struct {
+	reconciliationService *service.ERPReconciliationService
+	logger                *zap.Logger
+}
+
+// NewERPReconciliationHandler creates a new ERPReconciliationHandler instance
+func NewERPReconciliationHandler(reconciliationService *service.ERPReconciliationService, logger *zap.Logger) *ERPReconciliationHandler {
+	return &ERPReconciliationHandler{
+		reconciliationService: reconciliationService,
+		logger:                logger,
+	}
+}
+
+// GetOverview godoc
+// @Summary Get ERP reconciliation overview
+// @Description Returns the last reconciliation run, the number of pending decisions and the field drift policies
+// @Tags Customers
+// @Produce json
+// @Success 200 {object} domain.ERPReconciliationOverviewDTO
+// @Failure 500 {object} domain.APIError
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /customers/erp-reconciliation [get]
+func (h *ERPReconciliationHandler) GetOverview(w http.ResponseWriter, r *http.Request) {
+	overview, err := h.reconciliationService.GetOverview(r.Context())
+	if err != nil {
+		h.handleReconciliationError(w, err, "failed to get ERP reconciliation overview")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, overview)
+}
+
+// Run godoc
+// @Summary Run ERP reconciliation
+// @Description Compares ERP customers with CRM customers now. Missing customers are created or queued for approval, and field drift is resolved with the field policies.
+// @Tags Customers
+// @Produce json
+// @Success 200 {object} domain.ERPReconciliationRunDTO
+// @Failure 409 {object} domain.APIError "A run is already in progress"
+// @Failure 500 {object} domain.APIError
+// @Failure 503 {object} domain.APIError "Data warehouse not available"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /customers/erp-reconciliation/runs [post]
+func (h *ERPReconciliationHandler) Run(w http.ResponseWriter, r *http.Request) {
+	run, err := h.reconciliationService.Run(r.Context(), domain.ERPReconciliationTriggerManual)
+	if err != nil {
+		h.handleReconciliationError(w, err, "ERP reconciliation failed")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, run)
+}
+
+// ListRuns godoc
+// @Summary List ERP reconciliation runs
+// @Description Returns the reconciliation history, newest first
+// @Tags Customers
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param pageSize query int false "Items per page (max 200)" default(20)
+// @Success 200 {object} domain.PaginatedResponse{data=[]domain.ERPReconciliationRunDTO}
+// @Failure 500 {object} domain.APIError
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /customers/erp-reconciliation/runs [get]
+func (h *ERPReconciliationHandler) ListRuns(w http.ResponseWriter, r *http.Request) {
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	pageSize, _ := strconv.Atoi(r.URL.Query().Get("pageSize"))
+
+	result, err := h.reconciliationService.ListRuns(r.Context(), page, pageSize)
+	if err != nil {
+		h.handleReconciliationError(w, err, "failed to list ERP reconciliation runs")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, result)
+}
+
+// GetRun godoc
+// @Summary Get ERP reconciliation run
+// @Description Returns a single reconciliation run
+// @Tags Customers
+// @Produce json
+// @Param id path string true "Run ID" format(uuid)
+// @Success 200 {object} domain.ERPReconciliationRunDTO
+// @Failure 400 {object} domain.APIError
+// @Failure 404 {object} domain.APIError
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /customers/erp-reconciliation/runs/{id} [get]
+func (h *ERPReconciliationHandler) GetRun(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid run ID")
+		return
+	}
+
+	run, err := h.reconciliationService.GetRun(r.Context(), id)
+	if err != nil {
+		h.handleReconciliationError(w, err, "failed to get ERP reconciliation run")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, run)
+}
+
+// ListDecisions godoc
+// @Summary List ERP reconciliation decisions
+// @Description Returns missing customers and field drift found by reconciliation, newest first. Use status=pending for the approval queue.
+// @Tags Customers
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param pageSize query int false "Items per page (max 200)" default(20)
+// @Param status query string false "Filter by status" Enums(pending, approved, rejected, applied, obsolete)
+// @Param type query string false "Filter by type" Enums(create_customer, field_drift)
+// @Param runId query string false "Filter by run ID" format(uuid)
+// @Param customerId query string false "Filter by customer ID" format(uuid)
+// @Success 200 {object} domain.PaginatedResponse{data=[]domain.ERPReconciliationDecisionDTO}
+// @Failure 400 {object} domain.APIError
+// @Failure 500 {object} domain.APIError
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /customers/erp-reconciliation/decisions [get]
+func (h *ERPReconciliationHandler) ListDecisions(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	page, _ := strconv.Atoi(query.Get("page"))
+	pageSize, _ := strconv.Atoi(query.Get("pageSize"))
+
+	filters := &repository.ERPReconciliationDecisionFilters{}
+	if value := query.Get("status"); value != "" {
+		status := domain.ERPReconciliationDecisionStatus(value)
+		if !status.IsValid() {
+			respondWithError(w, http.StatusBadRequest, "Invalid status")
+			return
+		}
+		filters.Status = &status
+	}
+	if value := query.Get("type"); value != "" {
+		decisionType := domain.ERPReconciliationDecisionType(value)
+		if decisionType != domain.ERPReconciliationDecisionCreateCustomer && decisionType != domain.ERPReconciliationDecisionFieldDrift {
+			respondWithError(w, http.StatusBadRequest, "Invalid type: must be create_customer or field_drift")
+			return
+		}
+		filters.Type = &decisionType
+	}
+	if value := query.Get("runId"); value != "" {
+		runID, err := uuid.Parse(value)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "Invalid run ID")
+			return
+		}
+		filters.RunID = &runID
+	}
+	if value := query.Get("customerId"); value != "" {
+		customerID, err := uuid.Parse(value)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "Invalid customer ID")
+			return
+		}
+		filters.CustomerID = &customerID
+	}
+
+	result, err := h.reconciliationService.ListDecisions(r.Context(), filters, page, pageSize)
+	if err != nil {
+		h.handleReconciliationError(w, err, "failed to list ERP reconciliation decisions")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, result)
+}
+
+// ApproveDecision godoc
+// @Summary Approve ERP reconciliation decision
+// @Description Applies a pending decision: creates the missing customer or writes the ERP value to the customer
+// @Tags Customers
+// @Produce json
+// @Param id path string true "Decision ID" format(uuid)
+// @Success 200 {object} domain.ERPReconciliationDecisionDTO
+// @Failure 400 {object} domain.APIError
+// @Failure 404 {object} domain.APIError
+// @Failure 409 {object} domain.APIError
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /customers/erp-reconciliation/decisions/{id}/approve [post]
+func (h *ERPReconciliationHandler) ApproveDecision(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid decision ID")
+		return
+	}
+
+	decision, err := h.reconciliationService.ApproveDecision(r.Context(), id)
+	if err != nil {
+		h.handleReconciliationError(w, err, "failed to approve ERP reconciliation decision")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, decision)
+}
+
+// RejectDecision godoc
+// @Summary Reject ERP reconciliation decision
+// @Description Rejects a pending decision. The same difference is not queued again until the ERP value changes.
+// @Tags Customers
+// @Produce json
+// @Param id path string true "Decision ID" format(uuid)
+// @Success 200 {object} domain.ERPReconciliationDecisionDTO
+// @Failure 400 {object} domain.APIError
+// @Failure 404 {object} domain.APIError
+// @Failure 409 {object} domain.APIError
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /customers/erp-reconciliation/decisions/{id}/reject [post]
+func (h *ERPReconciliationHandler) RejectDecision(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid decision ID")
+		return
+	}
+
+	decision, err := h.reconciliationService.RejectDecision(r.Context(), id)
+	if err != nil {
+		h.handleReconciliationError(w, err, "failed to reject ERP reconciliation decision")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, decision)
+}
+
+// GetPolicies godoc
+// @Summary Get ERP reconciliation policies
+// @Description Returns the drift policy per customer field: erp_wins applies the ERP value, crm_wins keeps the CRM value, review queues the drift for approval
+// @Tags Customers
+// @Produce json
+// @Success 200 {array} domain.ERPReconciliationPolicyDTO
+// @Failure 500 {object} domain.APIError
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /customers/erp-reconciliation/policies [get]
+func (h *ERPReconciliationHandler) GetPolicies(w http.ResponseWriter, r *http.Request) {
+	policies, err := h.reconciliationService.ListPolicies(r.Context())
+	if err != nil {
+		h.handleReconciliationError(w, err, "failed to get ERP reconciliation policies")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, policies)
+}
+
+// UpdatePolicies godoc
+// @Summary Update ERP reconciliation policies
+// @Description Sets the drift policy for one or more customer fields. Applies from the next run.
+// @Tags Customers
+// @Accept json
+// @Produce json
+// @Param request body domain.UpdateERPReconciliationPoliciesRequest true "Policies per field"
+// @Success 200 {array} domain.ERPReconciliationPolicyDTO
+// @Failure 400 {object} domain.APIError
+// @Failure 500 {object} domain.APIError
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /customers/erp-reconciliation/policies [put]
+func (h *ERPReconciliationHandler) UpdatePolicies(w http.ResponseWriter, r *http.Request) {
+	var req domain.UpdateERPReconciliationPoliciesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if err := validate.Struct(req); err != nil {
+		respondValidationError(w, err)
+		return
+	}
+
+	policies, err := h.reconciliationService.UpdatePolicies(r.Context(), &req)
+	if err != nil {
+		h.handleReconciliationError(w, err, "failed to update ERP reconciliation policies")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, policies)
+}
+
+// handleReconciliationError maps ERP reconciliation service errors to HTTP responses
+func (h *ERPReconciliationHandler) handleReconciliationError(w http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, service.ErrERPReconciliationRunNotFound),
+		errors.Is(err, service.ErrERPReconciliationDecisionNotFound),
+		errors.Is(err, service.ErrCustomerNotFound):
+		respondWithError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, service.ErrERPReconciliationRunning),
+		errors.Is(err, service.ErrERPReconciliationDecisionResolved),
+		errors.Is(err, service.ErrDuplicateOrgNumber):
+		respondWithError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, service.ErrInvalidERPReconciliationPolicy),
+		errors.Is(err, service.ErrERPCustomerMissingName),
+		errors.Is(err, service.ErrInvalidOrgNumber):
+		respondWithError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, service.ErrDataWarehouseNotAvailable):
+		respondWithError(w, http.StatusServiceUnavailable, "data warehouse not available")
+	default:
+		h.logger.Error(message, zap.Error(err))
+		respondWithError(w, http.StatusInternalServerError, message)
+	}
+}
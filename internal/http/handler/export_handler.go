@@ -0,0 +1,274 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/straye-as/relation-api/internal/export"
+	"github.com/straye-as/relation-api/internal/service"
+	"go.uber.org/zap"
+)
+
+// ExportHandler handles CSV/XLSX exports of the list endpoints.
+// Each export accepts the same filter and sort parameters as the corresponding list endpoint,
+// plus format (csv or xlsx) and locale (nb for Norwegian number and date formatting).
+type ExportHandler struct {
+	exportService *service.ExportService
+	auditService  *service.AuditLogService
+	logger        *zap.Logger
+}
+
+// NewExportHandler creates a new ExportHandler instance
+func NewExportHandler(exportService *service.ExportService, auditService *service.AuditLogService, logger *zap.Logger) *ExportHandler {
+	return &ExportHandler{
+		exportService: exportService,
+		auditService:  auditService,
+		logger:        logger,
+	}
+}
+
+// ExportOffers godoc
+// @Summary Export offers
+// @Description Exports all offers matching the list filters as CSV or XLSX, without page limits. Requires reports:export.
+// @Tags Offers
+// @Produce text/csv
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param format query string false "File format" Enums(csv, xlsx) default(csv)
+// @Param locale query string false "Number and date formatting: nb for Norwegian (decimal comma, dd.mm.yyyy, semicolon-separated CSV)" Enums(en, nb) default(en)
+// @Param customerId query string false "Filter by customer ID"
+// @Param customerGroupId query string false "Filter by customer group (the customer and all its subsidiaries)"
+// @Param projectId query string false "Filter by project ID"
+// @Param phase query string false "Filter by phase"
+// @Param sortBy query string false "Sort field" Enums(createdAt, updatedAt, title, value, probability, phase, status, dueDate, customerName)
+// @Param sortOrder query string false "Sort order" Enums(asc, desc) default(desc)
+// @Success 200 {file} file
+// @Failure 400 {object} domain.APIError
+// @Failure 403 {object} domain.APIError
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /offers/export [get]
+func (h *ExportHandler) ExportOffers(w http.ResponseWriter, r *http.Request) {
+	filters := parseOfferFilters(r)
+	sort := parseSortConfig(r)
+
+	h.stream(w, r, "Offer", "offers", func(out *downloadWriter, opts export.Options) (int, error) {
+		return h.exportService.ExportOffers(r.Context(), out, opts, &filters, sort)
+	})
+}
+
+// ExportCustomers godoc
+// @Summary Export customers
+// @Description Exports all customers matching the list filters as CSV or XLSX, without page limits. Requires reports:export.
+// @Tags Customers
+// @Produce text/csv
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param format query string false "File format" Enums(csv, xlsx) default(csv)
+// @Param locale query string false "Number and date formatting: nb for Norwegian (decimal comma, dd.mm.yyyy, semicolon-separated CSV)" Enums(en, nb) default(en)
+// @Param search query string false "Search by name or organization number"
+// @Param city query string false "Filter by city"
+// @Param country query string false "Filter by country"
+// @Param status query string false "Filter by status" Enums(active, inactive, lead, churned)
+// @Param tier query string false "Filter by tier" Enums(bronze, silver, gold, platinum)
+// @Param industry query string false "Filter by industry"
+// @Param registryStatus query string false "Filter by Enhetsregisteret status"
+// @Param sortBy query string false "Sort field" Enums(createdAt, updatedAt, name, city, country, status, tier, industry, orgNumber)
+// @Param sortOrder query string false "Sort order" Enums(asc, desc) default(desc)
+// @Success 200 {file} file
+// @Failure 400 {object} domain.APIError
+// @Failure 403 {object} domain.APIError
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /customers/export [get]
+func (h *ExportHandler) ExportCustomers(w http.ResponseWriter, r *http.Request) {
+	filters := parseCustomerFilters(r)
+	sort := parseSortConfig(r)
+
+	h.stream(w, r, "Customer", "customers", func(out *downloadWriter, opts export.Options) (int, error) {
+		return h.exportService.ExportCustomers(r.Context(), out, opts, filters, sort)
+	})
+}
+
+// ExportProjects godoc
+// @Summary Export projects
+// @Description Exports all projects matching the list filters as CSV or XLSX, without page limits. Requires reports:export.
+// @Tags Projects
+// @Produce text/csv
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param format query string false "File format" Enums(csv, xlsx) default(csv)
+// @Param locale query string false "Number and date formatting: nb for Norwegian (decimal comma, dd.mm.yyyy, semicolon-separated CSV)" Enums(en, nb) default(en)
+// @Param customerId query string false "Filter by customer ID" format(uuid)
+// @Param customerGroupId query string false "Filter by customer group (the customer and all its subsidiaries)" format(uuid)
+// @Param phase query string false "Filter by phase" Enums(tilbud, working, on_hold, completed, cancelled)
+// @Param sortBy query string false "Sort field" Enums(createdAt, updatedAt, name, phase, startDate, endDate, customerName)
+// @Param sortOrder query string false "Sort order" Enums(asc, desc) default(desc)
+// @Success 200 {file} file
+// @Failure 400 {object} domain.APIError
+// @Failure 403 {object} domain.APIError
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /projects/export [get]
+func (h *ExportHandler) ExportProjects(w http.ResponseWriter, r *http.Request) {
+	filters := parseProjectFilters(r)
+	sort := parseSortConfig(r)
+
+	h.stream(w, r, "Project", "projects", func(out *downloadWriter, opts export.Options) (int, error) {
+		return h.exportService.ExportProjects(r.Context(), out, opts, filters, sort)
+	})
+}
+
+// ExportDeals godoc
+// @Summary Export deals
+// @Description Exports all deals matching the list filters as CSV or XLSX, without page limits. Requires reports:export.
+// @Tags Deals
+// @Produce text/csv
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param format query string false "File format" Enums(csv, xlsx) default(csv)
+// @Param locale query string false "Number and date formatting: nb for Norwegian (decimal comma, dd.mm.yyyy, semicolon-separated CSV)" Enums(en, nb) default(en)
+// @Param stage query string false "Filter by stage (lead, qualified, proposal, negotiation, won, lost)"
+// @Param ownerId query string false "Filter by owner ID"
+// @Param customerId query string false "Filter by customer ID"
+// @Param companyId query string false "Filter by company ID"
+// @Param source query string false "Filter by source"
+// @Param minValue query number false "Minimum value"
+// @Param maxValue query number false "Maximum value"
+// @Param createdAfter query string false "Created after date (YYYY-MM-DD)"
+// @Param createdBefore query string false "Created before date (YYYY-MM-DD)"
+// @Param closeAfter query string false "Expected close after date (YYYY-MM-DD)"
+// @Param closeBefore query string false "Expected close before date (YYYY-MM-DD)"
+// @Param sort query string false "Sort by (created_desc, created_asc, value_desc, value_asc, probability_desc, probability_asc, close_date_desc, close_date_asc, weighted_desc, weighted_asc)"
+// @Success 200 {file} file
+// @Failure 400 {object} domain.APIError
+// @Failure 403 {object} domain.APIError
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /deals/export [get]
+func (h *ExportHandler) ExportDeals(w http.ResponseWriter, r *http.Request) {
+	filters, sortBy := parseDealFilters(r)
+
+	h.stream(w, r, "Deal", "deals", func(out *downloadWriter, opts export.Options) (int, error) {
+		return h.exportService.ExportDeals(r.Context(), out, opts, filters, sortBy)
+	})
+}
+
+// ExportSuppliers godoc
+// @Summary Export suppliers
+// @Description Exports all suppliers matching the list filters as CSV or XLSX, without page limits. Requires reports:export.
+// @Tags Suppliers
+// @Produce text/csv
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param format query string false "File format" Enums(csv, xlsx) default(csv)
+// @Param locale query string false "Number and date formatting: nb for Norwegian (decimal comma, dd.mm.yyyy, semicolon-separated CSV)" Enums(en, nb) default(en)
+// @Param search query string false "Search by name or organization number"
+// @Param city query string false "Filter by city"
+// @Param country query string false "Filter by country"
+// @Param status query string false "Filter by status" Enums(active, inactive, pending, blacklisted)
+// @Param category query string false "Filter by category"
+// @Param sortBy query string false "Sort field" Enums(createdAt, updatedAt, name, city, country, status, category, orgNumber)
+// @Param sortOrder query string false "Sort order" Enums(asc, desc) default(desc)
+// @Success 200 {file} file
+// @Failure 400 {object} domain.APIError
+// @Failure 403 {object} domain.APIError
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /suppliers/export [get]
+func (h *ExportHandler) ExportSuppliers(w http.ResponseWriter, r *http.Request) {
+	filters := parseSupplierFilters(r)
+	sort := parseSortConfig(r)
+
+	h.stream(w, r, "Supplier", "suppliers", func(out *downloadWriter, opts export.Options) (int, error) {
+		return h.exportService.ExportSuppliers(r.Context(), out, opts, filters, sort)
+	})
+}
+
+// ExportActivities godoc
+// @Summary Export activities
+// @Description Exports all activities matching the list filters as CSV or XLSX, without page limits. Requires reports:export.
+// @Tags Activities
+// @Produce text/csv
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param format query string false "File format" Enums(csv, xlsx) default(csv)
+// @Param locale query string false "Number and date formatting: nb for Norwegian (decimal comma, dd.mm.yyyy, semicolon-separated CSV)" Enums(en, nb) default(en)
+// @Param type query string false "Filter by activity type (meeting, task, call, email, note)"
+// @Param status query string false "Filter by status (planned, in_progress, completed, cancelled)"
+// @Param targetType query string false "Filter by target type (customer, project, offer, deal)"
+// @Param targetId query string false "Filter by target entity ID" format(uuid)
+// @Param assignedTo query string false "Filter by assigned user ID"
+// @Param from query string false "Filter activities from this date (YYYY-MM-DD)"
+// @Param to query string false "Filter activities to this date (YYYY-MM-DD)"
+// @Success 200 {file} file
+// @Failure 400 {object} domain.APIError
+// @Failure 401 {object} domain.APIError
+// @Failure 403 {object} domain.APIError
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /activities/export [get]
+func (h *ExportHandler) ExportActivities(w http.ResponseWriter, r *http.Request) {
+	filters, err := parseActivityFilters(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	h.stream(w, r, "Activity", "activities", func(out *downloadWriter, opts export.Options) (int, error) {
+		return h.exportService.ExportActivities(r.Context(), out, opts, filters)
+	})
+}
+
+// stream parses the format options, runs the export and writes the audit entry.
+// Errors before the first byte is written get a normal JSON error response; once streaming
+// has started the status is already sent, so errors are only logged.
+func (h *ExportHandler) stream(w http.ResponseWriter, r *http.Request, entityType, fileBase string, run func(out *downloadWriter, opts export.Options) (int, error)) {
+	format, err := export.ParseFormat(r.URL.Query().Get("format"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	opts := export.Options{
+		Format:    format,
+		Norwegian: r.URL.Query().Get("locale") == "nb",
+	}
+
+	filename := fileBase + "_" + time.Now().Format("2006-01-02") + format.Extension()
+	out := &downloadWriter{w: w, contentType: format.ContentType(), filename: filename}
+
+	count, err := run(out, opts)
+	if err != nil {
+		if !out.started {
+			if errors.Is(err, service.ErrUserContextRequired) {
+				respondWithError(w, http.StatusUnauthorized, "Authentication required")
+				return
+			}
+			h.logger.Error("failed to export", zap.String("entity_type", entityType), zap.Error(err))
+			respondWithError(w, http.StatusInternalServerError, "Failed to export "+fileBase)
+			return
+		}
+		h.logger.Error("export aborted while streaming",
+			zap.String("entity_type", entityType),
+			zap.Int("rows_written", count),
+			zap.Error(err))
+		return
+	}
+
+	// Log the export action (best effort - ignore errors)
+	_ = h.auditService.LogExport(r.Context(), r, entityType, count, string(format), nil)
+}
+
+// downloadWriter sets the download headers on the first write, so handlers can still
+// respond with a JSON error if the export fails before any data is produced
+type downloadWriter struct {
+	w           http.ResponseWriter
+	contentType string
+	filename    string
+	started     bool
+}
+
+func (d *downloadWriter) Write(p []byte) (int, error) {
+	if !d.started {
+		d.started = true
+		d.w.Header().Set("Content-Type", d.contentType)
+		d.w.Header().Set("Content-Disposition", "attachment; filename="+d.filename)
+		d.w.WriteHeader(http.StatusOK)
+	}
+	return d.w.Write(p)
+}
@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/straye-as/relation-api/internal/domain"
+	"github.com/straye-as/relation-api/internal/repository"
+	"github.com/straye-as/relation-api/internal/service"
+	"go.uber.org/zap"
+)
+
+// ForecastHandler handles HTTP requests for the win-rate calibrated pipeline forecast
+type ForecastHandler struct {
+	forecastService *service.WinRateForecastService
+	logger          *zap.Logger
+}
+
+// NewForecastHandler creates a new ForecastHandler instance
+func NewForecastHandler(forecastService *service.WinRateForecastService, logger *zap.Logger) *ForecastHandler {
+	return &ForecastHandler{
+		forecastService: forecastService,
+		logger:          logger,
+	}
+}
+
+// GetCalibratedForecast godoc
+// @Summary Get calibrated pipeline forecast
+// @Description Forecasts open deals (by expected close date) and open offers not linked to a deal (by expiration or due date) for the coming months.
+// @Description Instead of the entered probability, each item is weighted by the historical win rate of deals that reached the same stage, or offers that reached the same phase,
+// @Description adjusted for company, value band, customer tier and owner. Periods include an 80% confidence band.
+// @Description The calibration report compares the probabilities salespeople entered on closed deals and offers with the actual outcomes.
+// @Tags Deals
+// @Produce json
+// @Param months query int false "Number of months to forecast (1-12)" default(3)
+// @Param lookbackMonths query int false "Months of closed deals and offers to learn win rates from (1-60)" default(24)
+// @Param companyId query string false "Filter by company ID"
+// @Param ownerId query string false "Filter by owner ID"
+// @Success 200 {object} domain.CalibratedForecastDTO
+// @Failure 500 {object} domain.APIError
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /deals/forecast/calibrated [get]
+func (h *ForecastHandler) GetCalibratedForecast(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	months, _ := strconv.Atoi(query.Get("months"))
+	if months < 1 {
+		months = 3
+	}
+	if months > 12 {
+		months = 12
+	}
+	lookbackMonths, _ := strconv.Atoi(query.Get("lookbackMonths"))
+	if lookbackMonths < 1 {
+		lookbackMonths = service.DefaultWinRateLookbackMonths
+	}
+	if lookbackMonths > 60 {
+		lookbackMonths = 60
+	}
+
+	filters := &repository.WinRateFilters{}
+	if value := query.Get("companyId"); value != "" {
+		companyID := domain.CompanyID(value)
+		filters.CompanyID = &companyID
+	}
+	if value := query.Get("ownerId"); value != "" {
+		filters.OwnerID = &value
+	}
+
+	forecast, err := h.forecastService.GetCalibratedForecast(r.Context(), months, lookbackMonths, filters)
+	if err != nil {
+		h.logger.Error("failed to get calibrated forecast", zap.Error(err))
+		respondWithError(w, http.StatusInternalServerError, "Failed to get calibrated forecast")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, forecast)
+}
@@ -0,0 +1,438 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/straye-as/relation-api/internal/domain"
+	"github.com/straye-as/relation-api/internal/importer"
+	"github.com/straye-as/relation-api/internal/service"
+	"go.uber.org/zap"
+)
+
+// ImportHandler handles HTTP requests for customer and offer imports
+type ImportHandler struct {
+	importService *service.ImportService
+	auditService  *service.AuditLogService
+	maxUploadMB   int64
+	logger        *zap.Logger
+}
+
+// NewImportHandler creates a new ImportHandler instance
+func NewImportHandler(importService *service.ImportService, auditService *service.AuditLogService, maxUploadMB int64, logger *zap.Logger) *ImportHandler {
+	return &ImportHandler{
+		importService: importService,
+		auditService:  auditService,
+		maxUploadMB:   maxUploadMB,
+		logger:        logger,
+	}
+}
+
+// Upload godoc
+// @Summary Upload import file
+// @Description Uploads an XLSX or CSV file with customers or offers. Returns the columns, sample rows and a suggested column mapping. Nothing is imported until the job is dry-run and committed.
+// @Tags Imports
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "XLSX or CSV file"
+// @Param entityType formData string true "Entity type to import" Enums(customer, offer)
+// @Success 201 {object} domain.ImportJobDTO
+// @Failure 400 {object} domain.APIError
+// @Failure 500 {object} domain.APIError
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /imports [post]
+func (h *ImportHandler) Upload(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, h.maxUploadMB*1024*1024)
+	if err := r.ParseMultipartForm(h.maxUploadMB * 1024 * 1024); err != nil {
+		respondWithError(w, http.StatusBadRequest, "file too large or invalid form")
+		return
+	}
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "file field is required")
+		return
+	}
+	defer file.Close()
+
+	entityType := domain.ImportEntityType(r.FormValue("entityType"))
+	job, err := h.importService.Upload(r.Context(), entityType, header.Filename, file)
+	if err != nil {
+		h.handleImportError(w, err, "failed to upload import file")
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, job)
+}
+
+// List godoc
+// @Summary List import jobs
+// @Description Returns a paginated list of import jobs, newest first
+// @Tags Imports
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param pageSize query int false "Items per page (max 200)" default(20)
+// @Param entityType query string false "Filter by entity type" Enums(customer, offer)
+// @Success 200 {object} domain.PaginatedResponse{data=[]domain.ImportJobDTO}
+// @Failure 400 {object} domain.APIError
+// @Failure 500 {object} domain.APIError
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /imports [get]
+func (h *ImportHandler) List(w http.ResponseWriter, r *http.Request) {
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	pageSize, _ := strconv.Atoi(r.URL.Query().Get("pageSize"))
+
+	var entityType *domain.ImportEntityType
+	if value := r.URL.Query().Get("entityType"); value != "" {
+		t := domain.ImportEntityType(value)
+		entityType = &t
+	}
+
+	result, err := h.importService.ListJobs(r.Context(), entityType, page, pageSize)
+	if err != nil {
+		h.handleImportError(w, err, "failed to list import jobs")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, result)
+}
+
+// GetFields godoc
+// @Summary List importable fields
+// @Description Returns the fields that import columns can be mapped to for an entity type
+// @Tags Imports
+// @Produce json
+// @Param entityType query string true "Entity type" Enums(customer, offer)
+// @Success 200 {array} domain.ImportFieldDTO
+// @Failure 400 {object} domain.APIError
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /imports/fields [get]
+func (h *ImportHandler) GetFields(w http.ResponseWriter, r *http.Request) {
+	fields, err := h.importService.GetFields(domain.ImportEntityType(r.URL.Query().Get("entityType")))
+	if err != nil {
+		h.handleImportError(w, err, "failed to get import fields")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, fields)
+}
+
+// Get godoc
+// @Summary Get import job
+// @Description Returns an import job with sample rows, suggested mapping and the summary of the last dry run or commit
+// @Tags Imports
+// @Produce json
+// @Param id path string true "Import job ID" format(uuid)
+// @Success 200 {object} domain.ImportJobDTO
+// @Failure 400 {object} domain.APIError
+// @Failure 404 {object} domain.APIError
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /imports/{id} [get]
+func (h *ImportHandler) Get(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid import job ID")
+		return
+	}
+
+	job, err := h.importService.GetJob(r.Context(), id)
+	if err != nil {
+		h.handleImportError(w, err, "failed to get import job")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, job)
+}
+
+// Delete godoc
+// @Summary Delete import job
+// @Description Deletes an import job and its stored rows. Records created by a committed import are kept.
+// @Tags Imports
+// @Param id path string true "Import job ID" format(uuid)
+// @Success 204
+// @Failure 400 {object} domain.APIError
+// @Failure 404 {object} domain.APIError
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /imports/{id} [delete]
+func (h *ImportHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid import job ID")
+		return
+	}
+
+	if err := h.importService.DeleteJob(r.Context(), id); err != nil {
+		h.handleImportError(w, err, "failed to delete import job")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// DryRun godoc
+// @Summary Dry run import
+// @Description Validates every row with the given column mapping and reports which rows would be created, updated, skipped or rejected. Customers are matched by organization number, then by fuzzy name.
+// @Tags Imports
+// @Accept json
+// @Produce json
+// @Param id path string true "Import job ID" format(uuid)
+// @Param request body domain.RunImportRequest true "Mapping and options"
+// @Success 200 {object} domain.ImportReportDTO
+// @Failure 400 {object} domain.APIError
+// @Failure 404 {object} domain.APIError
+// @Failure 409 {object} domain.APIError
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /imports/{id}/dry-run [post]
+func (h *ImportHandler) DryRun(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid import job ID")
+		return
+	}
+
+	var req domain.RunImportRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	report, err := h.importService.DryRun(r.Context(), id, &req)
+	if err != nil {
+		h.handleImportError(w, err, "failed to run import dry run")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, report)
+}
+
+// Commit godoc
+// @Summary Commit import
+// @Description Imports the rows of a validated job through the customer and offer services. Rows that fail are reported as errors without stopping the import.
+// @Tags Imports
+// @Produce json
+// @Param id path string true "Import job ID" format(uuid)
+// @Success 200 {object} domain.ImportReportDTO
+// @Failure 400 {object} domain.APIError
+// @Failure 404 {object} domain.APIError
+// @Failure 409 {object} domain.APIError
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /imports/{id}/commit [post]
+func (h *ImportHandler) Commit(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid import job ID")
+		return
+	}
+
+	report, err := h.importService.Commit(r.Context(), id)
+	if err != nil {
+		h.handleImportError(w, err, "failed to commit import")
+		return
+	}
+
+	if h.auditService != nil {
+		job, err := h.importService.GetJob(r.Context(), id)
+		if err == nil {
+			entityType := "Customer"
+			if job.EntityType == domain.ImportEntityOffer {
+				entityType = "Offer"
+			}
+			count := report.Summary.Create + report.Summary.Update
+			_ = h.auditService.LogImport(r.Context(), r, entityType, count, job.FileName, job.CompanyID)
+		}
+	}
+
+	respondJSON(w, http.StatusOK, report)
+}
+
+// GetReport godoc
+// @Summary Get import report
+// @Description Returns the row-by-row report from the last dry run or commit of an import job
+// @Tags Imports
+// @Produce json
+// @Param id path string true "Import job ID" format(uuid)
+// @Success 200 {object} domain.ImportReportDTO
+// @Failure 400 {object} domain.APIError
+// @Failure 404 {object} domain.APIError
+// @Failure 409 {object} domain.APIError
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /imports/{id}/report [get]
+func (h *ImportHandler) GetReport(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid import job ID")
+		return
+	}
+
+	report, err := h.importService.GetReport(r.Context(), id)
+	if err != nil {
+		h.handleImportError(w, err, "failed to get import report")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, report)
+}
+
+// ListProfiles godoc
+// @Summary List import mapping profiles
+// @Description Returns saved column mappings, optionally filtered by entity type
+// @Tags Imports
+// @Produce json
+// @Param entityType query string false "Filter by entity type" Enums(customer, offer)
+// @Success 200 {array} domain.ImportMappingProfileDTO
+// @Failure 400 {object} domain.APIError
+// @Failure 500 {object} domain.APIError
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /imports/mapping-profiles [get]
+func (h *ImportHandler) ListProfiles(w http.ResponseWriter, r *http.Request) {
+	var entityType *domain.ImportEntityType
+	if value := r.URL.Query().Get("entityType"); value != "" {
+		t := domain.ImportEntityType(value)
+		entityType = &t
+	}
+
+	profiles, err := h.importService.ListProfiles(r.Context(), entityType)
+	if err != nil {
+		h.handleImportError(w, err, "failed to list mapping profiles")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, profiles)
+}
+
+// CreateProfile godoc
+// @Summary Create import mapping profile
+// @Description Saves a column mapping for reuse with files that have the same layout
+// @Tags Imports
+// @Accept json
+// @Produce json
+// @Param request body domain.SaveImportMappingProfileRequest true "Mapping profile"
+// @Success 201 {object} domain.ImportMappingProfileDTO
+// @Failure 400 {object} domain.APIError
+// @Failure 409 {object} domain.APIError
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /imports/mapping-profiles [post]
+func (h *ImportHandler) CreateProfile(w http.ResponseWriter, r *http.Request) {
+	var req domain.SaveImportMappingProfileRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if err := validate.Struct(req); err != nil {
+		respondValidationError(w, err)
+		return
+	}
+
+	profile, err := h.importService.CreateProfile(r.Context(), &req)
+	if err != nil {
+		h.handleImportError(w, err, "failed to create mapping profile")
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, profile)
+}
+
+// UpdateProfile godoc
+// @Summary Update import mapping profile
+// @Description Replaces the name and column mapping of a saved mapping profile
+// @Tags Imports
+// @Accept json
+// @Produce json
+// @Param id path string true "Mapping profile ID" format(uuid)
+// @Param request body domain.SaveImportMappingProfileRequest true "Mapping profile"
+// @Success 200 {object} domain.ImportMappingProfileDTO
+// @Failure 400 {object} domain.APIError
+// @Failure 404 {object} domain.APIError
+// @Failure 409 {object} domain.APIError
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /imports/mapping-profiles/{id} [put]
+func (h *ImportHandler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid mapping profile ID")
+		return
+	}
+
+	var req domain.SaveImportMappingProfileRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if err := validate.Struct(req); err != nil {
+		respondValidationError(w, err)
+		return
+	}
+
+	profile, err := h.importService.UpdateProfile(r.Context(), id, &req)
+	if err != nil {
+		h.handleImportError(w, err, "failed to update mapping profile")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, profile)
+}
+
+// DeleteProfile godoc
+// @Summary Delete import mapping profile
+// @Description Deletes a saved mapping profile
+// @Tags Imports
+// @Param id path string true "Mapping profile ID" format(uuid)
+// @Success 204
+// @Failure 400 {object} domain.APIError
+// @Failure 404 {object} domain.APIError
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /imports/mapping-profiles/{id} [delete]
+func (h *ImportHandler) DeleteProfile(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid mapping profile ID")
+		return
+	}
+
+	if err := h.importService.DeleteProfile(r.Context(), id); err != nil {
+		h.handleImportError(w, err, "failed to delete mapping profile")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleImportError maps import service errors to HTTP responses
+func (h *ImportHandler) handleImportError(w http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, service.ErrImportJobNotFound):
+		respondWithError(w, http.StatusNotFound, "import job not found")
+	case errors.Is(err, service.ErrImportMappingProfileNotFound):
+		respondWithError(w, http.StatusNotFound, "mapping profile not found")
+	case errors.Is(err, service.ErrImportNotValidated),
+		errors.Is(err, service.ErrImportAlreadyCommitted),
+		errors.Is(err, service.ErrDuplicateImportMappingProfile):
+		respondWithError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, service.ErrInvalidImportEntityType),
+		errors.Is(err, service.ErrImportMappingRequired),
+		errors.Is(err, service.ErrInvalidImportMapping),
+		errors.Is(err, importer.ErrUnsupportedFormat),
+		errors.Is(err, importer.ErrEmptyFile),
+		errors.Is(err, importer.ErrTooManyRows):
+		respondWithError(w, http.StatusBadRequest, err.Error())
+	default:
+		h.logger.Error(message, zap.Error(err))
+		respondWithError(w, http.StatusInternalServerError, message)
+	}
+}
@@ -0,0 +1,199 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/straye-as/relation-api/internal/domain"
+	"github.com/straye-as/relation-api/internal/service"
+	"go.uber.org/zap"
+)
+
+// InquiryAssignmentHandler handles HTTP requests for inquiry assignment rules
+type InquiryAssignmentHandler struct {
+	assignmentService *service.InquiryAssignmentService
+	logger            *zap.Logger
+}
+
+// NewInquiryAssignmentHandler creates a new InquiryAssignmentHandler instance
+func NewInquiryAssignmentHandler(assignmentService *service.InquiryAssignmentService, logger *zap.Logger) *InquiryAssignmentHandler {
+	return &InquiryAssignmentHandler{
+		assignmentService: assignmentService,
+		logger:            logger,
+	}
+}
+
+// List godoc
+// @Summary List inquiry assignment rules
+// @Description Returns the rules that pick the responsible for new inquiries, per company in evaluation order (ascending priority)
+// @Tags Inquiry Assignment
+// @Produce json
+// @Param companyId query string false "Filter by company ID"
+// @Success 200 {array} domain.InquiryAssignmentRuleDTO
+// @Failure 500 {object} domain.APIError
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /inquiry-assignment-rules [get]
+func (h *InquiryAssignmentHandler) List(w http.ResponseWriter, r *http.Request) {
+	var companyID *domain.CompanyID
+	if value := r.URL.Query().Get("companyId"); value != "" {
+		id := domain.CompanyID(value)
+		companyID = &id
+	}
+
+	rules, err := h.assignmentService.List(r.Context(), companyID)
+	if err != nil {
+		h.handleRuleError(w, err, "failed to list inquiry assignment rules")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, rules)
+}
+
+// GetByID godoc
+// @Summary Get inquiry assignment rule
+// @Tags Inquiry Assignment
+// @Produce json
+// @Param id path string true "Rule ID" format(uuid)
+// @Success 200 {object} domain.InquiryAssignmentRuleDTO
+// @Failure 400 {object} domain.APIError
+// @Failure 404 {object} domain.APIError
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /inquiry-assignment-rules/{id} [get]
+func (h *InquiryAssignmentHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid rule ID: must be a valid UUID")
+		return
+	}
+
+	rule, err := h.assignmentService.GetByID(r.Context(), id)
+	if err != nil {
+		h.handleRuleError(w, err, "failed to get inquiry assignment rule")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, rule)
+}
+
+// Create godoc
+// @Summary Create inquiry assignment rule
+// @Description Adds a rule that assigns new inquiries of a company to its assignees in turn. round_robin rules match every inquiry; industry, postal_region and value rules match on the customer's industry, postal code prefix or county, and the estimated value (minValue inclusive, maxValue exclusive). Inquiries no rule matches go to the company's default responsible. Requires company admin or super admin role.
+// @Tags Inquiry Assignment
+// @Accept json
+// @Produce json
+// @Param request body domain.CreateInquiryAssignmentRuleRequest true "Assignment rule"
+// @Success 201 {object} domain.InquiryAssignmentRuleDTO
+// @Failure 400 {object} domain.APIError
+// @Failure 403 {object} domain.APIError
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /inquiry-assignment-rules [post]
+func (h *InquiryAssignmentHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req domain.CreateInquiryAssignmentRuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body: malformed JSON")
+		return
+	}
+	if err := validate.Struct(req); err != nil {
+		respondValidationError(w, err)
+		return
+	}
+
+	rule, err := h.assignmentService.Create(r.Context(), &req)
+	if err != nil {
+		h.handleRuleError(w, err, "failed to create inquiry assignment rule")
+		return
+	}
+
+	w.Header().Set("Location", "/api/v1/inquiry-assignment-rules/"+rule.ID.String())
+	respondJSON(w, http.StatusCreated, rule)
+}
+
+// Update godoc
+// @Summary Update inquiry assignment rule
+// @Description Replaces the conditions, priority and assignees of a rule. Changing the assignees restarts the rotation. Requires company admin or super admin role.
+// @Tags Inquiry Assignment
+// @Accept json
+// @Produce json
+// @Param id path string true "Rule ID" format(uuid)
+// @Param request body domain.UpdateInquiryAssignmentRuleRequest true "Assignment rule"
+// @Success 200 {object} domain.InquiryAssignmentRuleDTO
+// @Failure 400 {object} domain.APIError
+// @Failure 403 {object} domain.APIError
+// @Failure 404 {object} domain.APIError
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /inquiry-assignment-rules/{id} [put]
+func (h *InquiryAssignmentHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid rule ID: must be a valid UUID")
+		return
+	}
+
+	var req domain.UpdateInquiryAssignmentRuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body: malformed JSON")
+		return
+	}
+	if err := validate.Struct(req); err != nil {
+		respondValidationError(w, err)
+		return
+	}
+
+	rule, err := h.assignmentService.Update(r.Context(), id, &req)
+	if err != nil {
+		h.handleRuleError(w, err, "failed to update inquiry assignment rule")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, rule)
+}
+
+// Delete godoc
+// @Summary Delete inquiry assignment rule
+// @Description Requires company admin or super admin role.
+// @Tags Inquiry Assignment
+// @Param id path string true "Rule ID" format(uuid)
+// @Success 204 "No Content"
+// @Failure 400 {object} domain.APIError
+// @Failure 403 {object} domain.APIError
+// @Failure 404 {object} domain.APIError
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /inquiry-assignment-rules/{id} [delete]
+func (h *InquiryAssignmentHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid rule ID: must be a valid UUID")
+		return
+	}
+
+	if err := h.assignmentService.Delete(r.Context(), id); err != nil {
+		h.handleRuleError(w, err, "failed to delete inquiry assignment rule")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *InquiryAssignmentHandler) handleRuleError(w http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, service.ErrInquiryAssignmentRuleNotFound):
+		respondWithError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, service.ErrUnauthorized):
+		respondWithError(w, http.StatusUnauthorized, err.Error())
+	case errors.Is(err, service.ErrForbidden):
+		respondWithError(w, http.StatusForbidden, "Insufficient permissions to manage inquiry assignment rules")
+	case errors.Is(err, service.ErrInvalidInquiryAssignmentRule):
+		respondWithError(w, http.StatusBadRequest, err.Error())
+	default:
+		h.logger.Error(message, zap.Error(err))
+		respondWithError(w, http.StatusInternalServerError, message)
+	}
+}
@@ -72,7 +72,7 @@ func (h *InquiryHandler) List(w http.ResponseWriter, r *http.Request) {
 
 // Create godoc
 // @Summary Create inquiry
-// @Description Creates a new inquiry (offer in draft phase with minimal required fields)
+// @Description Creates a new inquiry (offer in draft phase with minimal required fields). Without a responsible, the company's inquiry assignment rules pick one and the reason is logged on the inquiry.
 // @Tags Inquiries
 // @Accept json
 // @Produce json
@@ -168,7 +168,7 @@ func (h *InquiryHandler) Delete(w http.ResponseWriter, r *http.Request) {
 
 // Convert godoc
 // @Summary Convert inquiry to offer
-// @Description Converts an inquiry to an offer (phase=in_progress), generating an offer number
+// @Description Converts an inquiry to an offer (phase=in_progress), generating an offer number. Without responsibleUserId, the company's inquiry assignment rules pick the responsible, falling back to its default responsible.
 // @Tags Inquiries
 // @Accept json
 // @Produce json
@@ -0,0 +1,384 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/straye-as/relation-api/internal/domain"
+	"github.com/straye-as/relation-api/internal/service"
+	"go.uber.org/zap"
+)
+
+// InquiryIntakeHandler handles the public website inquiry forms and their configuration
+type InquiryIntakeHandler struct {
+	intakeService   *service.InquiryIntakeService
+	maxUploadSizeMB int64
+	clientIP        func(*http.Request) string
+	logger          *zap.Logger
+}
+
+// NewInquiryIntakeHandler creates a new InquiryIntakeHandler instance. clientIP identifies the
+// submitter the same way the rate limiter does.
+func NewInquiryIntakeHandler(intakeService *service.InquiryIntakeService, maxUploadSizeMB int64, clientIP func(*http.Request) string, logger *zap.Logger) *InquiryIntakeHandler {
+	if maxUploadSizeMB <= 0 {
+		maxUploadSizeMB = 20
+	}
+	return &InquiryIntakeHandler{
+		intakeService:   intakeService,
+		maxUploadSizeMB: maxUploadSizeMB,
+		clientIP:        clientIP,
+		logger:          logger,
+	}
+}
+
+// Challenge godoc
+// @Summary Get inquiry proof-of-work challenge
+// @Description Returns a challenge for a website form. Before submitting, the form finds a nonce such that SHA-256("<challenge>:<nonce>") starts with the given number of zero bits, and sends both with the inquiry. Each challenge can be used once. Bits is 0 when the site does not require proof of work.
+// @Tags Public
+// @Produce json
+// @Param siteKey path string true "Site key"
+// @Success 200 {object} domain.InquiryChallengeDTO
+// @Failure 404 {object} domain.APIError
+// @Router /public/sites/{siteKey}/challenge [get]
+func (h *InquiryIntakeHandler) Challenge(w http.ResponseWriter, r *http.Request) {
+	challenge, err := h.intakeService.Challenge(r.Context(), chi.URLParam(r, "siteKey"))
+	if err != nil {
+		h.handleIntakeError(w, err, "failed to create inquiry challenge")
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	respondJSON(w, http.StatusOK, challenge)
+}
+
+// Submit godoc
+// @Summary Submit website inquiry
+// @Description Files an inquiry from a company website form as a draft inquiry for the site's company, linked to the best matching customer and assigned to the company's default responsible. Accepts JSON, or multipart/form-data with the same field names and up to the configured number of files in "attachments". Submissions are rate limited per IP, checked against the site's allowed origins, honeypot field and proof of work, and the submitter is sent a confirmation email when enabled.
+// @Tags Public
+// @Accept json,mpfd
+// @Produce json
+// @Param siteKey path string true "Site key"
+// @Param request body domain.PublicInquiryRequest true "Inquiry"
+// @Success 202 {object} domain.PublicInquiryResponseDTO
+// @Failure 400 {object} domain.APIError
+// @Failure 403 {object} domain.APIError "Origin not allowed"
+// @Failure 404 {object} domain.APIError
+// @Failure 429 {object} domain.APIError
+// @Router /public/sites/{siteKey}/inquiries [post]
+func (h *InquiryIntakeHandler) Submit(w http.ResponseWriter, r *http.Request) {
+	maxBytes := h.maxUploadSizeMB * 1024 * 1024
+	r.Body = http.MaxBytesReader(w, r.Body, maxBytes)
+
+	submission := &service.PublicInquirySubmission{
+		Fields:    map[string]string{},
+		Origin:    r.Header.Get("Origin"),
+		IPAddress: h.clientIP(r),
+		UserAgent: r.UserAgent(),
+	}
+
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		if err := h.parseMultipartInquiry(r, maxBytes, submission); err != nil {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	} else {
+		if err := parseJSONInquiry(r, submission); err != nil {
+			respondWithError(w, http.StatusBadRequest, "Invalid request body: malformed JSON")
+			return
+		}
+	}
+
+	if err := validate.Struct(submission.Request); err != nil {
+		respondValidationError(w, err)
+		return
+	}
+
+	result, err := h.intakeService.Submit(r.Context(), chi.URLParam(r, "siteKey"), submission)
+	if err != nil {
+		h.handleIntakeError(w, err, "failed to submit inquiry")
+		return
+	}
+
+	respondJSON(w, http.StatusAccepted, result)
+}
+
+// ListSites godoc
+// @Summary List inquiry sites
+// @Description Returns the website forms allowed to submit inquiries, with their public site keys
+// @Tags Inquiry Sites
+// @Produce json
+// @Param companyId query string false "Filter by company ID"
+// @Success 200 {array} domain.InquiryIntakeSiteDTO
+// @Failure 500 {object} domain.APIError
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /inquiry-sites [get]
+func (h *InquiryIntakeHandler) ListSites(w http.ResponseWriter, r *http.Request) {
+	var companyID *domain.CompanyID
+	if value := r.URL.Query().Get("companyId"); value != "" {
+		id := domain.CompanyID(value)
+		companyID = &id
+	}
+
+	sites, err := h.intakeService.ListSites(r.Context(), companyID)
+	if err != nil {
+		h.handleIntakeError(w, err, "failed to list inquiry sites")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, sites)
+}
+
+// GetSite godoc
+// @Summary Get inquiry site
+// @Tags Inquiry Sites
+// @Produce json
+// @Param id path string true "Inquiry site ID" format(uuid)
+// @Success 200 {object} domain.InquiryIntakeSiteDTO
+// @Failure 400 {object} domain.APIError
+// @Failure 404 {object} domain.APIError
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /inquiry-sites/{id} [get]
+func (h *InquiryIntakeHandler) GetSite(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid inquiry site ID: must be a valid UUID")
+		return
+	}
+
+	site, err := h.intakeService.GetSite(r.Context(), id)
+	if err != nil {
+		h.handleIntakeError(w, err, "failed to get inquiry site")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, site)
+}
+
+// CreateSite godoc
+// @Summary Create inquiry site
+// @Description Registers a website form for a company and generates its public site key. Website origins must also be allowed by the API's CORS configuration. Requires company admin or super admin role.
+// @Tags Inquiry Sites
+// @Accept json
+// @Produce json
+// @Param request body domain.CreateInquiryIntakeSiteRequest true "Inquiry site"
+// @Success 201 {object} domain.InquiryIntakeSiteDTO
+// @Failure 400 {object} domain.APIError
+// @Failure 403 {object} domain.APIError
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /inquiry-sites [post]
+func (h *InquiryIntakeHandler) CreateSite(w http.ResponseWriter, r *http.Request) {
+	var req domain.CreateInquiryIntakeSiteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body: malformed JSON")
+		return
+	}
+	if err := validate.Struct(req); err != nil {
+		respondValidationError(w, err)
+		return
+	}
+
+	site, err := h.intakeService.CreateSite(r.Context(), &req)
+	if err != nil {
+		h.handleIntakeError(w, err, "failed to create inquiry site")
+		return
+	}
+
+	w.Header().Set("Location", "/api/v1/inquiry-sites/"+site.ID.String())
+	respondJSON(w, http.StatusCreated, site)
+}
+
+// UpdateSite godoc
+// @Summary Update inquiry site
+// @Description Changes the settings of a website form. The site key does not change. Requires company admin or super admin role.
+// @Tags Inquiry Sites
+// @Accept json
+// @Produce json
+// @Param id path string true "Inquiry site ID" format(uuid)
+// @Param request body domain.UpdateInquiryIntakeSiteRequest true "Inquiry site settings"
+// @Success 200 {object} domain.InquiryIntakeSiteDTO
+// @Failure 400 {object} domain.APIError
+// @Failure 403 {object} domain.APIError
+// @Failure 404 {object} domain.APIError
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /inquiry-sites/{id} [put]
+func (h *InquiryIntakeHandler) UpdateSite(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid inquiry site ID: must be a valid UUID")
+		return
+	}
+
+	var req domain.UpdateInquiryIntakeSiteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body: malformed JSON")
+		return
+	}
+	if err := validate.Struct(req); err != nil {
+		respondValidationError(w, err)
+		return
+	}
+
+	site, err := h.intakeService.UpdateSite(r.Context(), id, &req)
+	if err != nil {
+		h.handleIntakeError(w, err, "failed to update inquiry site")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, site)
+}
+
+// DeleteSite godoc
+// @Summary Delete inquiry site
+// @Description Removes a website form; its site key stops working immediately. Requires company admin or super admin role.
+// @Tags Inquiry Sites
+// @Param id path string true "Inquiry site ID" format(uuid)
+// @Success 204 "No Content"
+// @Failure 400 {object} domain.APIError
+// @Failure 403 {object} domain.APIError
+// @Failure 404 {object} domain.APIError
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /inquiry-sites/{id} [delete]
+func (h *InquiryIntakeHandler) DeleteSite(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid inquiry site ID: must be a valid UUID")
+		return
+	}
+
+	if err := h.intakeService.DeleteSite(r.Context(), id); err != nil {
+		h.handleIntakeError(w, err, "failed to delete inquiry site")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListSubmissions godoc
+// @Summary List inquiry site submissions
+// @Description Returns the inquiries received through a website form, newest first. Submissions caught by the honeypot are left out unless includeSpam=true.
+// @Tags Inquiry Sites
+// @Produce json
+// @Param id path string true "Inquiry site ID" format(uuid)
+// @Param includeSpam query bool false "Include submissions caught by the honeypot"
+// @Param page query int false "Page number (default: 1)"
+// @Param pageSize query int false "Page size (default: 20, max: 200)"
+// @Success 200 {object} domain.PaginatedResponse{data=[]domain.InquirySubmissionDTO}
+// @Failure 400 {object} domain.APIError
+// @Failure 404 {object} domain.APIError
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /inquiry-sites/{id}/submissions [get]
+func (h *InquiryIntakeHandler) ListSubmissions(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid inquiry site ID: must be a valid UUID")
+		return
+	}
+
+	includeSpam := r.URL.Query().Get("includeSpam") == "true"
+	result, err := h.intakeService.ListSubmissions(r.Context(), id, includeSpam, parseIntQuery(r, "page", 1), parseIntQuery(r, "pageSize", 20))
+	if err != nil {
+		h.handleIntakeError(w, err, "failed to list inquiry submissions")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, result)
+}
+
+// parseJSONInquiry decodes a JSON inquiry, keeping every string field for the honeypot check
+func parseJSONInquiry(r *http.Request, submission *service.PublicInquirySubmission) error {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+
+	var req domain.PublicInquiryRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		return err
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return err
+	}
+	for name, value := range fields {
+		if text, ok := value.(string); ok {
+			submission.Fields[name] = text
+		}
+	}
+
+	submission.Request = &req
+	return nil
+}
+
+// parseMultipartInquiry reads a multipart inquiry form and its attachments
+func (h *InquiryIntakeHandler) parseMultipartInquiry(r *http.Request, maxBytes int64, submission *service.PublicInquirySubmission) error {
+	if err := r.ParseMultipartForm(maxBytes); err != nil {
+		return fmt.Errorf("request too large or invalid form")
+	}
+
+	for name, values := range r.MultipartForm.Value {
+		if len(values) > 0 {
+			submission.Fields[name] = values[0]
+		}
+	}
+	submission.Request = &domain.PublicInquiryRequest{
+		Name:        submission.Fields["name"],
+		Email:       submission.Fields["email"],
+		Phone:       submission.Fields["phone"],
+		CompanyName: submission.Fields["companyName"],
+		OrgNumber:   submission.Fields["orgNumber"],
+		Title:       submission.Fields["title"],
+		Message:     submission.Fields["message"],
+		Challenge:   submission.Fields["challenge"],
+		Nonce:       submission.Fields["nonce"],
+	}
+
+	for _, header := range r.MultipartForm.File["attachments"] {
+		file, err := header.Open()
+		if err != nil {
+			return fmt.Errorf("failed to read attachment %s", header.Filename)
+		}
+		data, err := io.ReadAll(file)
+		file.Close()
+		if err != nil {
+			return fmt.Errorf("failed to read attachment %s", header.Filename)
+		}
+		submission.Attachments = append(submission.Attachments, service.IntakeAttachment{
+			Filename:    header.Filename,
+			ContentType: header.Header.Get("Content-Type"),
+			Data:        data,
+		})
+	}
+	return nil
+}
+
+func (h *InquiryIntakeHandler) handleIntakeError(w http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, service.ErrInquirySiteNotFound):
+		respondWithError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, service.ErrInquiryOriginNotAllowed):
+		respondWithError(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, service.ErrUnauthorized):
+		respondWithError(w, http.StatusUnauthorized, err.Error())
+	case errors.Is(err, service.ErrForbidden):
+		respondWithError(w, http.StatusForbidden, "Insufficient permissions to manage inquiry sites")
+	case errors.Is(err, service.ErrInvalidProofOfWork),
+		errors.Is(err, service.ErrTooManyInquiryAttachments),
+		errors.Is(err, service.ErrInvalidInquirySite):
+		respondWithError(w, http.StatusBadRequest, err.Error())
+	default:
+		h.logger.Error(message, zap.Error(err))
+		respondWithError(w, http.StatusInternalServerError, message)
+	}
+}
@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/straye-as/relation-api/internal/domain"
+	"github.com/straye-as/relation-api/internal/service"
+	"go.uber.org/zap"
+)
+
+// InquirySLAHandler handles HTTP requests for the inquiry response SLA report
+type InquirySLAHandler struct {
+	slaService *service.InquirySLAService
+	logger     *zap.Logger
+}
+
+// NewInquirySLAHandler creates a new InquirySLAHandler instance
+func NewInquirySLAHandler(slaService *service.InquirySLAService, logger *zap.Logger) *InquirySLAHandler {
+	return &InquirySLAHandler{
+		slaService: slaService,
+		logger:     logger,
+	}
+}
+
+// GetReport godoc
+// @Summary Get inquiry SLA compliance report
+// @Description Reports how many inquiries received in the period got a customer response within their company's SLA, overall, per company and per responsible user.
+// @Description An inquiry is answered when it is converted to an offer or a user logs a completed email, call or meeting on it. Deadlines and response times are in business hours
+// @Description (the company's business day on Norwegian working days, excluding public holidays). Inquiries created before SLA tracking are not included.
+// @Tags Inquiries
+// @Produce json
+// @Param from query string false "First day of the period (YYYY-MM-DD), default 30 days before to"
+// @Param to query string false "Last day of the period (YYYY-MM-DD), default today"
+// @Param companyId query string false "Filter by company ID"
+// @Success 200 {object} domain.InquirySLAReportDTO
+// @Failure 400 {object} domain.APIError
+// @Failure 500 {object} domain.APIError
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /inquiries/sla-report [get]
+func (h *InquirySLAHandler) GetReport(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	var from, to *time.Time
+	if value := query.Get("from"); value != "" {
+		date, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "Invalid 'from' format, expected YYYY-MM-DD")
+			return
+		}
+		from = &date
+	}
+	if value := query.Get("to"); value != "" {
+		date, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "Invalid 'to' format, expected YYYY-MM-DD")
+			return
+		}
+		to = &date
+	}
+
+	var companyID *domain.CompanyID
+	if value := query.Get("companyId"); value != "" {
+		id := domain.CompanyID(value)
+		companyID = &id
+	}
+
+	report, err := h.slaService.Report(r.Context(), from, to, companyID)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidDateRange) {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		h.logger.Error("failed to get inquiry SLA report", zap.Error(err))
+		respondWithError(w, http.StatusInternalServerError, "Failed to get inquiry SLA report")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, report)
+}
@@ -37,8 +37,10 @@ func NewOfferHandler(offerService *service.OfferService, assignmentService *serv
 // @Param page query int false "Page number" default(1)
 // @Param pageSize query int false "Page size" default(20)
 // @Param customerId query string false "Filter by customer ID"
+// @Param customerGroupId query string false "Filter by customer group (the customer and all its subsidiaries)"
 // @Param projectId query string false "Filter by project ID"
 // @Param phase query string false "Filter by phase"
+// @Param stale query bool false "Filter by staleness (no phase change or activity within the phase's threshold)"
 // @Param sortBy query string false "Sort field" Enums(createdAt, updatedAt, title, value, probability, phase, status, dueDate, customerName)
 // @Param sortOrder query string false "Sort order" Enums(asc, desc) default(desc)
 // @Success 200 {object} domain.PaginatedResponse
@@ -58,41 +60,48 @@ func (h *OfferHandler) List(w http.ResponseWriter, r *http.Request) {
 		pageSize = 200
 	}
 
-	var customerID, projectID *uuid.UUID
+	filters := parseOfferFilters(r)
+	sort := parseSortConfig(r)
+
+	result, err := h.offerService.ListWithFilters(r.Context(), page, pageSize, &filters, sort)
+	if err != nil {
+		h.logger.Error("failed to list offers", zap.Error(err))
+		respondWithError(w, http.StatusInternalServerError, "Failed to list offers")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, result)
+}
+
+// parseOfferFilters parses the offer list filters from the query string.
+// Shared by the list and export endpoints so both return the same offers.
+func parseOfferFilters(r *http.Request) repository.OfferFilters {
+	var filters repository.OfferFilters
 	if cid := r.URL.Query().Get("customerId"); cid != "" {
 		if id, err := uuid.Parse(cid); err == nil {
-			customerID = &id
+			filters.CustomerID = &id
+		}
+	}
+	if gid := r.URL.Query().Get("customerGroupId"); gid != "" {
+		if id, err := uuid.Parse(gid); err == nil {
+			filters.CustomerGroupID = &id
 		}
 	}
 	if pid := r.URL.Query().Get("projectId"); pid != "" {
 		if id, err := uuid.Parse(pid); err == nil {
-			projectID = &id
+			filters.ProjectID = &id
 		}
 	}
-
-	var phase *domain.OfferPhase
 	if p := r.URL.Query().Get("phase"); p != "" {
 		ph := domain.OfferPhase(p)
-		phase = &ph
-	}
-
-	// Parse sort configuration
-	sort := repository.DefaultSortConfig()
-	if sortBy := r.URL.Query().Get("sortBy"); sortBy != "" {
-		sort.Field = sortBy
+		filters.Phase = &ph
 	}
-	if sortOrder := r.URL.Query().Get("sortOrder"); sortOrder != "" {
-		sort.Order = repository.ParseSortOrder(sortOrder)
-	}
-
-	result, err := h.offerService.ListWithSort(r.Context(), page, pageSize, customerID, projectID, phase, sort)
-	if err != nil {
-		h.logger.Error("failed to list offers", zap.Error(err))
-		respondWithError(w, http.StatusInternalServerError, "Failed to list offers")
-		return
+	if st := r.URL.Query().Get("stale"); st != "" {
+		if stale, err := strconv.ParseBool(st); err == nil {
+			filters.Stale = &stale
+		}
 	}
-
-	respondJSON(w, http.StatusOK, result)
+	return filters
 }
 
 // @Summary Create offer
@@ -521,6 +530,8 @@ func (h *OfferHandler) handleOfferError(w http.ResponseWriter, err error) {
 		respondWithError(w, http.StatusBadRequest, "Offer must be in order phase")
 	case errors.Is(err, service.ErrOfferAlreadyInOrder):
 		respondWithError(w, http.StatusBadRequest, "Offer is already in order phase")
+	case errors.Is(err, service.ErrCreditLimitExceeded):
+		respondWithError(w, http.StatusConflict, err.Error())
 	case errors.Is(err, service.ErrOfferAlreadyCompleted):
 		respondWithError(w, http.StatusBadRequest, "Offer is already completed")
 	case errors.Is(err, service.ErrOfferFinancialFieldReadOnly):
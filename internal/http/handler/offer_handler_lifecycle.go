@@ -23,7 +23,7 @@ import (
 
 // Send godoc
 // @Summary Send offer to customer
-// @Description Transitions an offer from draft or in_progress phase to sent phase.
+// @Description Transitions an offer from draft or in_progress phase to sent phase. The returned offer has the warning missing.decisionMaker when no contact is linked to it as decision maker.
 // @Tags Offers
 // @Produce json
 // @Param id path string true "Offer ID"
@@ -62,6 +62,7 @@ func (h *OfferHandler) Send(w http.ResponseWriter, r *http.Request) {
 // @Success 200 {object} domain.AcceptOfferResponse "Accepted offer and optional project"
 // @Failure 400 {object} domain.ErrorResponse "Invalid offer ID, request body, or offer not in sent phase"
 // @Failure 404 {object} domain.ErrorResponse "Offer not found"
+// @Failure 409 {object} domain.ErrorResponse "Customer credit limit exceeded and the company blocks such orders"
 // @Failure 500 {object} domain.ErrorResponse "Internal server error"
 // @Security BearerAuth
 // @Security ApiKeyAuth
@@ -238,7 +239,8 @@ func (h *OfferHandler) Clone(w http.ResponseWriter, r *http.Request) {
 
 // AcceptOrder godoc
 // @Summary Accept order
-// @Description Transitions a won offer to order phase, indicating work is beginning. This is used when a customer accepts a sent offer and work should start.
+// @Description Transitions a won offer to order phase, indicating work is beginning. This is used when a customer accepts a sent offer and work should start. The customer's credit limit is checked; the result is returned in creditCheck.
+// @Description If the offer's manager is already booked beyond capacity between the offer's start and end date, capacityWarning lists the overallocated weeks. The order is accepted regardless.
 // @Tags Offers
 // @Accept json
 // @Produce json
@@ -247,6 +249,7 @@ func (h *OfferHandler) Clone(w http.ResponseWriter, r *http.Request) {
 // @Success 200 {object} domain.AcceptOrderResponse "Offer transitioned to order phase"
 // @Failure 400 {object} domain.ErrorResponse "Invalid offer ID, request body, or offer not in valid phase"
 // @Failure 404 {object} domain.ErrorResponse "Offer not found"
+// @Failure 409 {object} domain.ErrorResponse "Customer credit limit exceeded and the company blocks such orders"
 // @Failure 500 {object} domain.ErrorResponse "Internal server error"
 // @Security BearerAuth
 // @Security ApiKeyAuth
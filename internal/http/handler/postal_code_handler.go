@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/straye-as/relation-api/internal/service"
+	"go.uber.org/zap"
+)
+
+type PostalCodeHandler struct {
+	postalCodeService *service.PostalCodeService
+	logger            *zap.Logger
+}
+
+func NewPostalCodeHandler(postalCodeService *service.PostalCodeService, logger *zap.Logger) *PostalCodeHandler {
+	return &PostalCodeHandler{
+		postalCodeService: postalCodeService,
+		logger:            logger,
+	}
+}
+
+// Lookup godoc
+// @Summary Look up postal code
+// @Description Returns city, municipality and county for a Norwegian postal code from the bundled Posten register
+// @Tags PostalCodes
+// @Produce json
+// @Param code path string true "Four-digit postal code" example(0150)
+// @Success 200 {object} domain.PostalCodeDTO
+// @Failure 400 {object} domain.APIError
+// @Failure 404 {object} domain.APIError
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /postal-codes/{code} [get]
+func (h *PostalCodeHandler) Lookup(w http.ResponseWriter, r *http.Request) {
+	code := chi.URLParam(r, "code")
+
+	result, err := h.postalCodeService.Lookup(code)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidPostalCode):
+			respondWithError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, service.ErrPostalCodeNotFound):
+			respondWithError(w, http.StatusNotFound, "postal code not found")
+		default:
+			h.logger.Error("failed to look up postal code", zap.Error(err), zap.String("code", code))
+			respondWithError(w, http.StatusInternalServerError, "failed to look up postal code")
+		}
+		return
+	}
+
+	respondJSON(w, http.StatusOK, result)
+}
+
+// GetMismatchReport godoc
+// @Summary Postal code data quality report
+// @Description Lists Norwegian customers and suppliers whose city does not match their postal code, or whose postal code is not in the register
+// @Tags PostalCodes
+// @Produce json
+// @Success 200 {object} domain.PostalCodeMismatchReportDTO
+// @Failure 500 {object} domain.APIError
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /postal-codes/mismatches [get]
+func (h *PostalCodeHandler) GetMismatchReport(w http.ResponseWriter, r *http.Request) {
+	report, err := h.postalCodeService.GetMismatchReport(r.Context())
+	if err != nil {
+		h.logger.Error("failed to build postal code mismatch report", zap.Error(err))
+		respondWithError(w, http.StatusInternalServerError, "failed to build postal code mismatch report")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, report)
+}
@@ -37,6 +37,7 @@ func NewProjectHandler(projectService *service.ProjectService, offerService *ser
 // @Param page query int false "Page number" default(1)
 // @Param pageSize query int false "Items per page (max 200)" default(20)
 // @Param customerId query string false "Filter by customer ID" format(uuid)
+// @Param customerGroupId query string false "Filter by customer group (the customer and all its subsidiaries)" format(uuid)
 // @Param phase query string false "Filter by phase" Enums(tilbud, working, on_hold, completed, cancelled)
 // @Param sortBy query string false "Sort field" Enums(createdAt, updatedAt, name, phase, startDate, endDate, customerName)
 // @Param sortOrder query string false "Sort order" Enums(asc, desc) default(desc)
@@ -60,7 +61,22 @@ func (h *ProjectHandler) List(w http.ResponseWriter, r *http.Request) {
 		pageSize = 200
 	}
 
-	// Build filters
+	filters := parseProjectFilters(r)
+	sort := parseSortConfig(r)
+
+	result, err := h.projectService.ListWithSort(r.Context(), page, pageSize, filters, sort)
+	if err != nil {
+		h.logger.Error("failed to list projects", zap.Error(err))
+		respondWithError(w, http.StatusInternalServerError, "Failed to list projects")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, result)
+}
+
+// parseProjectFilters parses the project list filters from the query string.
+// Shared by the list and export endpoints so both return the same projects.
+func parseProjectFilters(r *http.Request) *repository.ProjectFilters {
 	filters := &repository.ProjectFilters{}
 
 	// Parse customer ID filter
@@ -70,29 +86,20 @@ func (h *ProjectHandler) List(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	// Parse customer group filter (customer and its subsidiaries)
+	if gid := r.URL.Query().Get("customerGroupId"); gid != "" {
+		if id, err := uuid.Parse(gid); err == nil {
+			filters.CustomerGroupID = &id
+		}
+	}
+
 	// Parse phase filter
 	if p := r.URL.Query().Get("phase"); p != "" {
 		ph := domain.ProjectPhase(p)
 		filters.Phase = &ph
 	}
 
-	// Parse sort configuration
-	sort := repository.DefaultSortConfig()
-	if sortBy := r.URL.Query().Get("sortBy"); sortBy != "" {
-		sort.Field = sortBy
-	}
-	if sortOrder := r.URL.Query().Get("sortOrder"); sortOrder != "" {
-		sort.Order = repository.ParseSortOrder(sortOrder)
-	}
-
-	result, err := h.projectService.ListWithSort(r.Context(), page, pageSize, filters, sort)
-	if err != nil {
-		h.logger.Error("failed to list projects", zap.Error(err))
-		respondWithError(w, http.StatusInternalServerError, "Failed to list projects")
-		return
-	}
-
-	respondJSON(w, http.StatusOK, result)
+	return filters
 }
 
 // Create godoc
@@ -0,0 +1,214 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/straye-as/relation-api/internal/domain"
+	"github.com/straye-as/relation-api/internal/service"
+	"go.uber.org/zap"
+)
+
+// ProjectScheduleHandler handles HTTP requests for project milestones, tasks and the schedule
+type ProjectScheduleHandler struct {
+	scheduleService *service.ProjectScheduleService
+	logger          *zap.Logger
+}
+
+// NewProjectScheduleHandler creates a new ProjectScheduleHandler instance
+func NewProjectScheduleHandler(scheduleService *service.ProjectScheduleService, logger *zap.Logger) *ProjectScheduleHandler {
+	return &ProjectScheduleHandler{
+		scheduleService: scheduleService,
+		logger:          logger,
+	}
+}
+
+// GetSchedule godoc
+// @Summary Get project schedule
+// @Description Returns the project's tasks and milestones as Gantt items and finish-to-start links. Items start on their planned date or when their dependencies are finished, whichever is later, and a milestone is reached when its tasks are finished. earliestStart/earliestFinish are the forecast, latestStart/latestFinish the latest dates that do not delay the schedule, and items without slack form the critical path. Warnings list milestones forecast after the end date of their offer (the project's won offer unless the milestone links another).
+// @Tags Projects
+// @Produce json
+// @Param id path string true "Project ID" format(uuid)
+// @Success 200 {object} domain.ProjectScheduleDTO
+// @Failure 400 {object} domain.APIError
+// @Failure 404 {object} domain.APIError
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /projects/{id}/schedule [get]
+func (h *ProjectScheduleHandler) GetSchedule(w http.ResponseWriter, r *http.Request) {
+	projectID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid project ID: must be a valid UUID")
+		return
+	}
+
+	schedule, err := h.scheduleService.GetSchedule(r.Context(), projectID)
+	if err != nil {
+		h.handleTaskError(w, err, "failed to get project schedule")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, schedule)
+}
+
+// ListTasks godoc
+// @Summary List project tasks
+// @Description Returns the project's tasks and milestones in display order
+// @Tags Projects
+// @Produce json
+// @Param id path string true "Project ID" format(uuid)
+// @Success 200 {array} domain.ProjectTaskDTO
+// @Failure 400 {object} domain.APIError
+// @Failure 404 {object} domain.APIError
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /projects/{id}/tasks [get]
+func (h *ProjectScheduleHandler) ListTasks(w http.ResponseWriter, r *http.Request) {
+	projectID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid project ID: must be a valid UUID")
+		return
+	}
+
+	tasks, err := h.scheduleService.ListTasks(r.Context(), projectID)
+	if err != nil {
+		h.handleTaskError(w, err, "failed to list project tasks")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, tasks)
+}
+
+// CreateTask godoc
+// @Summary Create project task
+// @Description Adds a task (startDate to endDate) or milestone (a single startDate) to the project. Tasks can be part of a milestone, milestones can be linked to an offer in the project, and dependsOn lists items that must be finished before this one starts. Dependencies that form a cycle are rejected. A milestone that starts slipping past its offer's end date is logged on the project and the offer's manager is notified.
+// @Tags Projects
+// @Accept json
+// @Produce json
+// @Param id path string true "Project ID" format(uuid)
+// @Param request body domain.CreateProjectTaskRequest true "Task or milestone"
+// @Success 201 {object} domain.ProjectTaskDTO
+// @Failure 400 {object} domain.APIError
+// @Failure 404 {object} domain.APIError
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /projects/{id}/tasks [post]
+func (h *ProjectScheduleHandler) CreateTask(w http.ResponseWriter, r *http.Request) {
+	projectID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid project ID: must be a valid UUID")
+		return
+	}
+
+	var req domain.CreateProjectTaskRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body: malformed JSON")
+		return
+	}
+	if err := validate.Struct(req); err != nil {
+		respondValidationError(w, err)
+		return
+	}
+
+	task, err := h.scheduleService.CreateTask(r.Context(), projectID, &req)
+	if err != nil {
+		h.handleTaskError(w, err, "failed to create project task")
+		return
+	}
+
+	w.Header().Set("Location", "/api/v1/projects/"+projectID.String()+"/tasks/"+task.ID.String())
+	respondJSON(w, http.StatusCreated, task)
+}
+
+// UpdateTask godoc
+// @Summary Update project task
+// @Description Replaces the dates, progress, assignee, links and dependencies of a task or milestone. The kind cannot change.
+// @Tags Projects
+// @Accept json
+// @Produce json
+// @Param id path string true "Project ID" format(uuid)
+// @Param taskId path string true "Task ID" format(uuid)
+// @Param request body domain.UpdateProjectTaskRequest true "Task or milestone"
+// @Success 200 {object} domain.ProjectTaskDTO
+// @Failure 400 {object} domain.APIError
+// @Failure 404 {object} domain.APIError
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /projects/{id}/tasks/{taskId} [put]
+func (h *ProjectScheduleHandler) UpdateTask(w http.ResponseWriter, r *http.Request) {
+	projectID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid project ID: must be a valid UUID")
+		return
+	}
+	taskID, err := uuid.Parse(chi.URLParam(r, "taskId"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid task ID: must be a valid UUID")
+		return
+	}
+
+	var req domain.UpdateProjectTaskRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body: malformed JSON")
+		return
+	}
+	if err := validate.Struct(req); err != nil {
+		respondValidationError(w, err)
+		return
+	}
+
+	task, err := h.scheduleService.UpdateTask(r.Context(), projectID, taskID, &req)
+	if err != nil {
+		h.handleTaskError(w, err, "failed to update project task")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, task)
+}
+
+// DeleteTask godoc
+// @Summary Delete project task
+// @Description Removes a task or milestone and its dependencies. Tasks in a deleted milestone are kept without a milestone.
+// @Tags Projects
+// @Param id path string true "Project ID" format(uuid)
+// @Param taskId path string true "Task ID" format(uuid)
+// @Success 204 "No Content"
+// @Failure 400 {object} domain.APIError
+// @Failure 404 {object} domain.APIError
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /projects/{id}/tasks/{taskId} [delete]
+func (h *ProjectScheduleHandler) DeleteTask(w http.ResponseWriter, r *http.Request) {
+	projectID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid project ID: must be a valid UUID")
+		return
+	}
+	taskID, err := uuid.Parse(chi.URLParam(r, "taskId"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid task ID: must be a valid UUID")
+		return
+	}
+
+	if err := h.scheduleService.DeleteTask(r.Context(), projectID, taskID); err != nil {
+		h.handleTaskError(w, err, "failed to delete project task")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *ProjectScheduleHandler) handleTaskError(w http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, service.ErrProjectNotFound), errors.Is(err, service.ErrProjectTaskNotFound):
+		respondWithError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, service.ErrInvalidProjectTask), errors.Is(err, service.ErrProjectTaskDependencyCycle):
+		respondWithError(w, http.StatusBadRequest, err.Error())
+	default:
+		h.logger.Error(message, zap.Error(err))
+		respondWithError(w, http.StatusInternalServerError, message)
+	}
+}
@@ -0,0 +1,429 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/straye-as/relation-api/internal/domain"
+	"github.com/straye-as/relation-api/internal/repository"
+	"github.com/straye-as/relation-api/internal/service"
+	"go.uber.org/zap"
+)
+
+// ResourceCapacityHandler handles HTTP requests for resource allocations, capacity calendars and overallocation
+type ResourceCapacityHandler struct {
+	capacityService *service.ResourceCapacityService
+	logger          *zap.Logger
+}
+
+// NewResourceCapacityHandler creates a new ResourceCapacityHandler instance
+func NewResourceCapacityHandler(capacityService *service.ResourceCapacityService, logger *zap.Logger) *ResourceCapacityHandler {
+	return &ResourceCapacityHandler{
+		capacityService: capacityService,
+		logger:          logger,
+	}
+}
+
+// ListAllocations godoc
+// @Summary List resource allocations
+// @Description Returns who is booked on which offers and projects, earliest first
+// @Tags Capacity
+// @Produce json
+// @Param userId query string false "Filter by user ID"
+// @Param offerId query string false "Filter by offer ID" format(uuid)
+// @Param projectId query string false "Filter by project ID" format(uuid)
+// @Param companyId query string false "Filter by company ID"
+// @Param from query string false "Only allocations ending on or after the date (YYYY-MM-DD)"
+// @Param to query string false "Only allocations starting on or before the date (YYYY-MM-DD)"
+// @Success 200 {array} domain.ResourceAllocationDTO
+// @Failure 400 {object} domain.APIError
+// @Failure 500 {object} domain.APIError
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /resource-allocations [get]
+func (h *ResourceCapacityHandler) ListAllocations(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filters := &repository.ResourceAllocationFilters{}
+
+	if value := query.Get("userId"); value != "" {
+		filters.UserID = &value
+	}
+	if value := query.Get("offerId"); value != "" {
+		offerID, err := uuid.Parse(value)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "Invalid offerId: must be a valid UUID")
+			return
+		}
+		filters.OfferID = &offerID
+	}
+	if value := query.Get("projectId"); value != "" {
+		projectID, err := uuid.Parse(value)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "Invalid projectId: must be a valid UUID")
+			return
+		}
+		filters.ProjectID = &projectID
+	}
+	if value := query.Get("companyId"); value != "" {
+		companyID := domain.CompanyID(value)
+		filters.CompanyID = &companyID
+	}
+	from, to, ok := parseCapacityPeriod(w, r)
+	if !ok {
+		return
+	}
+	filters.From, filters.To = from, to
+
+	allocations, err := h.capacityService.ListAllocations(r.Context(), filters)
+	if err != nil {
+		h.handleCapacityError(w, err, "failed to list resource allocations")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, allocations)
+}
+
+// GetAllocation godoc
+// @Summary Get resource allocation
+// @Tags Capacity
+// @Produce json
+// @Param id path string true "Allocation ID" format(uuid)
+// @Success 200 {object} domain.ResourceAllocationDTO
+// @Failure 400 {object} domain.APIError
+// @Failure 404 {object} domain.APIError
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /resource-allocations/{id} [get]
+func (h *ResourceCapacityHandler) GetAllocation(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid allocation ID: must be a valid UUID")
+		return
+	}
+
+	allocation, err := h.capacityService.GetAllocation(r.Context(), id)
+	if err != nil {
+		h.handleCapacityError(w, err, "failed to get resource allocation")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, allocation)
+}
+
+// CreateAllocation godoc
+// @Summary Create resource allocation
+// @Description Books a user on an offer, a project or both for a date range, in hours per full working week or as a percentage of the user's weekly hours.
+// @Description An allocation on an offer in a project is also linked to the project. Requires manager or admin role.
+// @Tags Capacity
+// @Accept json
+// @Produce json
+// @Param request body domain.CreateResourceAllocationRequest true "Allocation"
+// @Success 201 {object} domain.ResourceAllocationDTO
+// @Failure 400 {object} domain.APIError
+// @Failure 403 {object} domain.APIError
+// @Failure 404 {object} domain.APIError "Offer or project not found"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /resource-allocations [post]
+func (h *ResourceCapacityHandler) CreateAllocation(w http.ResponseWriter, r *http.Request) {
+	var req domain.CreateResourceAllocationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body: malformed JSON")
+		return
+	}
+	if err := validate.Struct(req); err != nil {
+		respondValidationError(w, err)
+		return
+	}
+
+	allocation, err := h.capacityService.CreateAllocation(r.Context(), &req)
+	if err != nil {
+		h.handleCapacityError(w, err, "failed to create resource allocation")
+		return
+	}
+
+	w.Header().Set("Location", "/api/v1/resource-allocations/"+allocation.ID.String())
+	respondJSON(w, http.StatusCreated, allocation)
+}
+
+// UpdateAllocation godoc
+// @Summary Update resource allocation
+// @Description Changes the period, size and notes of an allocation. Requires manager or admin role.
+// @Tags Capacity
+// @Accept json
+// @Produce json
+// @Param id path string true "Allocation ID" format(uuid)
+// @Param request body domain.UpdateResourceAllocationRequest true "Period and size"
+// @Success 200 {object} domain.ResourceAllocationDTO
+// @Failure 400 {object} domain.APIError
+// @Failure 403 {object} domain.APIError
+// @Failure 404 {object} domain.APIError
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /resource-allocations/{id} [put]
+func (h *ResourceCapacityHandler) UpdateAllocation(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid allocation ID: must be a valid UUID")
+		return
+	}
+
+	var req domain.UpdateResourceAllocationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body: malformed JSON")
+		return
+	}
+	if err := validate.Struct(req); err != nil {
+		respondValidationError(w, err)
+		return
+	}
+
+	allocation, err := h.capacityService.UpdateAllocation(r.Context(), id, &req)
+	if err != nil {
+		h.handleCapacityError(w, err, "failed to update resource allocation")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, allocation)
+}
+
+// DeleteAllocation godoc
+// @Summary Delete resource allocation
+// @Description Requires manager or admin role.
+// @Tags Capacity
+// @Param id path string true "Allocation ID" format(uuid)
+// @Success 204 "No Content"
+// @Failure 400 {object} domain.APIError
+// @Failure 403 {object} domain.APIError
+// @Failure 404 {object} domain.APIError
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /resource-allocations/{id} [delete]
+func (h *ResourceCapacityHandler) DeleteAllocation(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid allocation ID: must be a valid UUID")
+		return
+	}
+
+	if err := h.capacityService.DeleteAllocation(r.Context(), id); err != nil {
+		h.handleCapacityError(w, err, "failed to delete resource allocation")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetCalendar godoc
+// @Summary Get capacity calendar
+// @Description Returns a user's available and allocated hours per ISO week with their absences and allocations.
+// @Description Working days are Monday to Friday except Norwegian public holidays, each with a fifth of the user's weekly hours (default 37.5).
+// @Description Allocations count in every company. The period defaults to 12 weeks from the Monday of the current week.
+// @Tags Capacity
+// @Produce json
+// @Param userId path string true "User ID"
+// @Param from query string false "First day (YYYY-MM-DD)"
+// @Param to query string false "Last day (YYYY-MM-DD), at most a year after from"
+// @Success 200 {object} domain.CapacityCalendarDTO
+// @Failure 400 {object} domain.APIError
+// @Failure 500 {object} domain.APIError
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /capacity/users/{userId} [get]
+func (h *ResourceCapacityHandler) GetCalendar(w http.ResponseWriter, r *http.Request) {
+	from, to, ok := parseCapacityPeriod(w, r)
+	if !ok {
+		return
+	}
+
+	calendar, err := h.capacityService.GetCalendar(r.Context(), chi.URLParam(r, "userId"), from, to)
+	if err != nil {
+		h.handleCapacityError(w, err, "failed to get capacity calendar")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, calendar)
+}
+
+// SetHoursPerWeek godoc
+// @Summary Set weekly hours
+// @Description Sets the user's working hours per week, e.g. for part-time employees. Requires manager or admin role.
+// @Tags Capacity
+// @Accept json
+// @Produce json
+// @Param userId path string true "User ID"
+// @Param request body domain.UpdateUserCapacityRequest true "Weekly hours"
+// @Success 200 {object} domain.CapacityCalendarDTO
+// @Failure 400 {object} domain.APIError
+// @Failure 403 {object} domain.APIError
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /capacity/users/{userId}/hours [put]
+func (h *ResourceCapacityHandler) SetHoursPerWeek(w http.ResponseWriter, r *http.Request) {
+	var req domain.UpdateUserCapacityRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body: malformed JSON")
+		return
+	}
+	if err := validate.Struct(req); err != nil {
+		respondValidationError(w, err)
+		return
+	}
+
+	calendar, err := h.capacityService.SetHoursPerWeek(r.Context(), chi.URLParam(r, "userId"), &req)
+	if err != nil {
+		h.handleCapacityError(w, err, "failed to set weekly hours")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, calendar)
+}
+
+// CreateAbsence godoc
+// @Summary Register absence
+// @Description Registers vacation, sick leave or other absence. Percent below 100 is a partial absence such as graded sick leave.
+// @Description Users can register their own absences; managers and admins anyone's.
+// @Tags Capacity
+// @Accept json
+// @Produce json
+// @Param userId path string true "User ID"
+// @Param request body domain.CreateUserAbsenceRequest true "Absence"
+// @Success 201 {object} domain.UserAbsenceDTO
+// @Failure 400 {object} domain.APIError
+// @Failure 403 {object} domain.APIError
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /capacity/users/{userId}/absences [post]
+func (h *ResourceCapacityHandler) CreateAbsence(w http.ResponseWriter, r *http.Request) {
+	var req domain.CreateUserAbsenceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body: malformed JSON")
+		return
+	}
+	if err := validate.Struct(req); err != nil {
+		respondValidationError(w, err)
+		return
+	}
+
+	absence, err := h.capacityService.CreateAbsence(r.Context(), chi.URLParam(r, "userId"), &req)
+	if err != nil {
+		h.handleCapacityError(w, err, "failed to create absence")
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, absence)
+}
+
+// DeleteAbsence godoc
+// @Summary Delete absence
+// @Tags Capacity
+// @Param userId path string true "User ID"
+// @Param absenceId path string true "Absence ID" format(uuid)
+// @Success 204 "No Content"
+// @Failure 400 {object} domain.APIError
+// @Failure 403 {object} domain.APIError
+// @Failure 404 {object} domain.APIError
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /capacity/users/{userId}/absences/{absenceId} [delete]
+func (h *ResourceCapacityHandler) DeleteAbsence(w http.ResponseWriter, r *http.Request) {
+	absenceID, err := uuid.Parse(chi.URLParam(r, "absenceId"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid absence ID: must be a valid UUID")
+		return
+	}
+
+	if err := h.capacityService.DeleteAbsence(r.Context(), chi.URLParam(r, "userId"), absenceID); err != nil {
+		h.handleCapacityError(w, err, "failed to delete absence")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetOverallocationReport godoc
+// @Summary Get overallocation report
+// @Description Lists, per company, the users allocated to its offers and projects in the period whose allocations exceed their available hours in at least one week.
+// @Description A user's load counts their allocations in every company, less absences and public holidays. The period defaults to 12 weeks from the Monday of the current week.
+// @Tags Capacity
+// @Produce json
+// @Param from query string false "First day (YYYY-MM-DD)"
+// @Param to query string false "Last day (YYYY-MM-DD), at most a year after from"
+// @Param companyId query string false "Filter by company ID"
+// @Success 200 {object} domain.CapacityOverallocationReportDTO
+// @Failure 400 {object} domain.APIError
+// @Failure 500 {object} domain.APIError
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /capacity/overallocations [get]
+func (h *ResourceCapacityHandler) GetOverallocationReport(w http.ResponseWriter, r *http.Request) {
+	from, to, ok := parseCapacityPeriod(w, r)
+	if !ok {
+		return
+	}
+
+	var companyID *domain.CompanyID
+	if value := r.URL.Query().Get("companyId"); value != "" {
+		id := domain.CompanyID(value)
+		companyID = &id
+	}
+
+	report, err := h.capacityService.GetOverallocationReport(r.Context(), from, to, companyID)
+	if err != nil {
+		h.handleCapacityError(w, err, "failed to get overallocation report")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, report)
+}
+
+// parseCapacityPeriod parses the optional from and to dates from the query string.
+// Responds with 400 and returns false when a date is malformed.
+func parseCapacityPeriod(w http.ResponseWriter, r *http.Request) (*time.Time, *time.Time, bool) {
+	query := r.URL.Query()
+
+	var from, to *time.Time
+	if value := query.Get("from"); value != "" {
+		date, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "Invalid 'from' format, expected YYYY-MM-DD")
+			return nil, nil, false
+		}
+		from = &date
+	}
+	if value := query.Get("to"); value != "" {
+		date, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "Invalid 'to' format, expected YYYY-MM-DD")
+			return nil, nil, false
+		}
+		to = &date
+	}
+
+	return from, to, true
+}
+
+func (h *ResourceCapacityHandler) handleCapacityError(w http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, service.ErrResourceAllocationNotFound),
+		errors.Is(err, service.ErrUserAbsenceNotFound),
+		errors.Is(err, service.ErrOfferNotFound),
+		errors.Is(err, service.ErrProjectNotFound):
+		respondWithError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, service.ErrUnauthorized):
+		respondWithError(w, http.StatusUnauthorized, err.Error())
+	case errors.Is(err, service.ErrForbidden):
+		respondWithError(w, http.StatusForbidden, "Insufficient permissions to manage capacity")
+	case errors.Is(err, service.ErrInvalidResourceAllocation),
+		errors.Is(err, service.ErrInvalidUserAbsence),
+		errors.Is(err, service.ErrInvalidDateRange):
+		respondWithError(w, http.StatusBadRequest, err.Error())
+	default:
+		h.logger.Error(message, zap.Error(err))
+		respondWithError(w, http.StatusInternalServerError, message)
+	}
+}
@@ -0,0 +1,281 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/straye-as/relation-api/internal/domain"
+	"github.com/straye-as/relation-api/internal/repository"
+	"github.com/straye-as/relation-api/internal/service"
+	"go.uber.org/zap"
+)
+
+// SalesTargetHandler handles HTTP requests for sales targets and quota attainment
+type SalesTargetHandler struct {
+	targetService *service.SalesTargetService
+	logger        *zap.Logger
+}
+
+// NewSalesTargetHandler creates a new SalesTargetHandler instance
+func NewSalesTargetHandler(targetService *service.SalesTargetService, logger *zap.Logger) *SalesTargetHandler {
+	return &SalesTargetHandler{
+		targetService: targetService,
+		logger:        logger,
+	}
+}
+
+// List godoc
+// @Summary List sales targets
+// @Description Returns sales targets per company and user, latest period first
+// @Tags Targets
+// @Produce json
+// @Param companyId query string false "Filter by company ID"
+// @Param userId query string false "Filter by user ID"
+// @Param periodType query string false "Filter by period type" Enums(month, quarter, year)
+// @Param metric query string false "Filter by metric" Enums(won_value, order_intake, margin)
+// @Param date query string false "Only targets whose period contains the date (YYYY-MM-DD)"
+// @Success 200 {array} domain.SalesTargetDTO
+// @Failure 400 {object} domain.APIError
+// @Failure 500 {object} domain.APIError
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /targets [get]
+func (h *SalesTargetHandler) List(w http.ResponseWriter, r *http.Request) {
+	filters, date, ok := parseSalesTargetFilters(w, r)
+	if !ok {
+		return
+	}
+	filters.ActiveOn = date
+
+	targets, err := h.targetService.List(r.Context(), filters)
+	if err != nil {
+		h.handleTargetError(w, err, "failed to list sales targets")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, targets)
+}
+
+// GetAttainment godoc
+// @Summary Get sales target attainment
+// @Description Compares the targets whose period contains the date (default today) with won deals and offers so far.
+// @Description Returns pace-to-target, gap and how much of the gap the weighted pipeline expected to close within the period covers.
+// @Tags Targets
+// @Produce json
+// @Param companyId query string false "Filter by company ID"
+// @Param userId query string false "Filter by user ID"
+// @Param periodType query string false "Filter by period type" Enums(month, quarter, year)
+// @Param metric query string false "Filter by metric" Enums(won_value, order_intake, margin)
+// @Param companyWide query bool false "Only company-wide targets (true) or only user targets (false)"
+// @Param date query string false "Date within the periods to report on (YYYY-MM-DD), default today"
+// @Success 200 {array} domain.SalesTargetAttainmentDTO
+// @Failure 400 {object} domain.APIError
+// @Failure 500 {object} domain.APIError
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /targets/attainment [get]
+func (h *SalesTargetHandler) GetAttainment(w http.ResponseWriter, r *http.Request) {
+	filters, date, ok := parseSalesTargetFilters(w, r)
+	if !ok {
+		return
+	}
+
+	attainment, err := h.targetService.GetAttainment(r.Context(), filters, date)
+	if err != nil {
+		h.handleTargetError(w, err, "failed to get sales target attainment")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, attainment)
+}
+
+// GetByID godoc
+// @Summary Get sales target
+// @Tags Targets
+// @Produce json
+// @Param id path string true "Sales target ID" format(uuid)
+// @Success 200 {object} domain.SalesTargetDTO
+// @Failure 400 {object} domain.APIError
+// @Failure 404 {object} domain.APIError
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /targets/{id} [get]
+func (h *SalesTargetHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid sales target ID: must be a valid UUID")
+		return
+	}
+
+	target, err := h.targetService.GetByID(r.Context(), id)
+	if err != nil {
+		h.handleTargetError(w, err, "failed to get sales target")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, target)
+}
+
+// Create godoc
+// @Summary Create sales target
+// @Description Creates a target for a company, or a user within it, for a month, quarter or year. Requires manager or admin role.
+// @Tags Targets
+// @Accept json
+// @Produce json
+// @Param request body domain.CreateSalesTargetRequest true "Sales target"
+// @Success 201 {object} domain.SalesTargetDTO
+// @Failure 400 {object} domain.APIError
+// @Failure 403 {object} domain.APIError
+// @Failure 409 {object} domain.APIError "A target already exists for the period and metric"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /targets [post]
+func (h *SalesTargetHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req domain.CreateSalesTargetRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body: malformed JSON")
+		return
+	}
+	if err := validate.Struct(req); err != nil {
+		respondValidationError(w, err)
+		return
+	}
+
+	target, err := h.targetService.Create(r.Context(), &req)
+	if err != nil {
+		h.handleTargetError(w, err, "failed to create sales target")
+		return
+	}
+
+	w.Header().Set("Location", "/api/v1/targets/"+target.ID.String())
+	respondJSON(w, http.StatusCreated, target)
+}
+
+// Update godoc
+// @Summary Update sales target
+// @Description Changes the target value and notes. Requires manager or admin role.
+// @Tags Targets
+// @Accept json
+// @Produce json
+// @Param id path string true "Sales target ID" format(uuid)
+// @Param request body domain.UpdateSalesTargetRequest true "Target value and notes"
+// @Success 200 {object} domain.SalesTargetDTO
+// @Failure 400 {object} domain.APIError
+// @Failure 403 {object} domain.APIError
+// @Failure 404 {object} domain.APIError
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /targets/{id} [put]
+func (h *SalesTargetHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid sales target ID: must be a valid UUID")
+		return
+	}
+
+	var req domain.UpdateSalesTargetRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body: malformed JSON")
+		return
+	}
+	if err := validate.Struct(req); err != nil {
+		respondValidationError(w, err)
+		return
+	}
+
+	target, err := h.targetService.Update(r.Context(), id, &req)
+	if err != nil {
+		h.handleTargetError(w, err, "failed to update sales target")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, target)
+}
+
+// Delete godoc
+// @Summary Delete sales target
+// @Description Requires manager or admin role.
+// @Tags Targets
+// @Param id path string true "Sales target ID" format(uuid)
+// @Success 204 "No Content"
+// @Failure 400 {object} domain.APIError
+// @Failure 403 {object} domain.APIError
+// @Failure 404 {object} domain.APIError
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /targets/{id} [delete]
+func (h *SalesTargetHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid sales target ID: must be a valid UUID")
+		return
+	}
+
+	if err := h.targetService.Delete(r.Context(), id); err != nil {
+		h.handleTargetError(w, err, "failed to delete sales target")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// parseSalesTargetFilters parses the sales target filters and report date from the query string.
+// Responds with 400 and returns false when the date is malformed.
+func parseSalesTargetFilters(w http.ResponseWriter, r *http.Request) (*repository.SalesTargetFilters, *time.Time, bool) {
+	query := r.URL.Query()
+	filters := &repository.SalesTargetFilters{}
+
+	if value := query.Get("companyId"); value != "" {
+		companyID := domain.CompanyID(value)
+		filters.CompanyID = &companyID
+	}
+	if value := query.Get("userId"); value != "" {
+		filters.UserID = &value
+	}
+	if value := query.Get("periodType"); value != "" {
+		periodType := domain.SalesTargetPeriod(value)
+		filters.PeriodType = &periodType
+	}
+	if value := query.Get("metric"); value != "" {
+		metric := domain.SalesTargetMetric(value)
+		filters.Metric = &metric
+	}
+	if value := query.Get("companyWide"); value != "" {
+		companyWide := value == "true"
+		filters.CompanyWide = &companyWide
+	}
+
+	var date *time.Time
+	if value := query.Get("date"); value != "" {
+		parsed, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "Invalid date: must be YYYY-MM-DD")
+			return nil, nil, false
+		}
+		date = &parsed
+	}
+
+	return filters, date, true
+}
+
+func (h *SalesTargetHandler) handleTargetError(w http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, service.ErrSalesTargetNotFound):
+		respondWithError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, service.ErrUnauthorized):
+		respondWithError(w, http.StatusUnauthorized, err.Error())
+	case errors.Is(err, service.ErrForbidden):
+		respondWithError(w, http.StatusForbidden, "Insufficient permissions to manage sales targets")
+	case errors.Is(err, service.ErrSalesTargetExists):
+		respondWithError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, service.ErrInvalidSalesTarget):
+		respondWithError(w, http.StatusBadRequest, err.Error())
+	default:
+		h.logger.Error(message, zap.Error(err))
+		respondWithError(w, http.StatusInternalServerError, message)
+	}
+}
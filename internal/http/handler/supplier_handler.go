@@ -63,7 +63,25 @@ func (h *SupplierHandler) List(w http.ResponseWriter, r *http.Request) {
 		pageSize = 20
 	}
 
-	// Build filters
+	filters := parseSupplierFilters(r)
+	sort := parseSortConfig(r)
+
+	result, err := h.supplierService.ListWithSort(r.Context(), page, pageSize, filters, sort)
+	if err != nil {
+		h.logger.Error("failed to list suppliers", zap.Error(err))
+		respondJSON(w, http.StatusInternalServerError, domain.ErrorResponse{
+			Error:   "Internal Server Error",
+			Message: "Failed to list suppliers",
+		})
+		return
+	}
+
+	respondJSON(w, http.StatusOK, result)
+}
+
+// parseSupplierFilters parses the supplier list filters from the query string.
+// Shared by the list and export endpoints so both return the same suppliers.
+func parseSupplierFilters(r *http.Request) *repository.SupplierFilters {
 	filters := &repository.SupplierFilters{
 		Search:   r.URL.Query().Get("search"),
 		City:     r.URL.Query().Get("city"),
@@ -77,26 +95,7 @@ func (h *SupplierHandler) List(w http.ResponseWriter, r *http.Request) {
 		filters.Status = &s
 	}
 
-	// Parse sort configuration
-	sort := repository.DefaultSortConfig()
-	if sortBy := r.URL.Query().Get("sortBy"); sortBy != "" {
-		sort.Field = sortBy
-	}
-	if sortOrder := r.URL.Query().Get("sortOrder"); sortOrder != "" {
-		sort.Order = repository.ParseSortOrder(sortOrder)
-	}
-
-	result, err := h.supplierService.ListWithSort(r.Context(), page, pageSize, filters, sort)
-	if err != nil {
-		h.logger.Error("failed to list suppliers", zap.Error(err))
-		respondJSON(w, http.StatusInternalServerError, domain.ErrorResponse{
-			Error:   "Internal Server Error",
-			Message: "Failed to list suppliers",
-		})
-		return
-	}
-
-	respondJSON(w, http.StatusOK, result)
+	return filters
 }
 
 // GetByID godoc
@@ -182,6 +181,13 @@ func (h *SupplierHandler) Create(w http.ResponseWriter, r *http.Request) {
 			})
 			return
 		}
+		if errors.Is(err, service.ErrInvalidOrgNumber) {
+			respondJSON(w, http.StatusBadRequest, domain.ErrorResponse{
+				Error:   "Bad Request",
+				Message: "Invalid organization number: must be 9 digits with a valid check digit",
+			})
+			return
+		}
 		if errors.Is(err, service.ErrInvalidEmailFormat) {
 			respondJSON(w, http.StatusBadRequest, domain.ErrorResponse{
 				Error:   "Bad Request",
@@ -833,7 +839,7 @@ func (h *SupplierHandler) UpdateAddress(w http.ResponseWriter, r *http.Request)
 
 // UpdatePostalCode godoc
 // @Summary Update supplier postal code
-// @Description Update only the postal code of a supplier
+// @Description Update the postal code of a supplier. City, municipality and county are derived from the Norwegian postal code register when the code is known.
 // @Tags Suppliers
 // @Accept json
 // @Produce json
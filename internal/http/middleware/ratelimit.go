@@ -20,8 +20,12 @@ type RateLimiter struct {
 	userLimiter    func(http.Handler) http.Handler
 	whitelistIPs   map[string]bool
 	whitelistPaths map[string]bool
+	trustedProxies []*net.IPNet
 }
 
+// defaultTrustedProxies are the loopback and private networks a reverse proxy in front of the API runs in
+var defaultTrustedProxies = []string{"127.0.0.0/8", "::1/128", "10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16", "fc00::/7"}
+
 // NewRateLimiter creates a new rate limiter with the given configuration
 func NewRateLimiter(cfg *config.RateLimitConfig, logger *zap.Logger) *RateLimiter {
 	rl := &RateLimiter{
@@ -38,6 +42,25 @@ func NewRateLimiter(cfg *config.RateLimitConfig, logger *zap.Logger) *RateLimite
 	for _, path := range cfg.WhitelistPaths {
 		rl.whitelistPaths[path] = true
 	}
+	trustedProxies := cfg.TrustedProxies
+	if trustedProxies == nil {
+		trustedProxies = defaultTrustedProxies
+	}
+	for _, proxy := range trustedProxies {
+		if !strings.Contains(proxy, "/") {
+			if ip := net.ParseIP(proxy); ip != nil && ip.To4() != nil {
+				proxy += "/32"
+			} else {
+				proxy += "/128"
+			}
+		}
+		_, network, err := net.ParseCIDR(proxy)
+		if err != nil {
+			logger.Warn("ignoring invalid trusted proxy", zap.String("proxy", proxy), zap.Error(err))
+			continue
+		}
+		rl.trustedProxies = append(rl.trustedProxies, network)
+	}
 
 	// Create IP-based rate limiter for unauthenticated requests
 	rl.ipLimiter = httprate.Limit(
@@ -80,7 +103,7 @@ func (rl *RateLimiter) Limit(next http.Handler) http.Handler {
 		}
 
 		// Check if IP is whitelisted
-		clientIP := rl.getClientIP(r)
+		clientIP := rl.ClientIP(r)
 		if rl.isIPWhitelisted(clientIP) {
 			next.ServeHTTP(w, r)
 			return
@@ -112,7 +135,7 @@ func (rl *RateLimiter) LimitByIP(next http.Handler) http.Handler {
 		}
 
 		// Check if IP is whitelisted
-		clientIP := rl.getClientIP(r)
+		clientIP := rl.ClientIP(r)
 		if rl.isIPWhitelisted(clientIP) {
 			next.ServeHTTP(w, r)
 			return
@@ -122,36 +145,89 @@ func (rl *RateLimiter) LimitByIP(next http.Handler) http.Handler {
 	})
 }
 
+// LimitByIPAt returns IP-based rate limiting middleware with its own limit, for public endpoints
+// that need a tighter limit than the rest of the API. It applies even when global rate limiting is
+// disabled; a limit of zero or less disables it.
+func (rl *RateLimiter) LimitByIPAt(requestsPerMinute int) func(http.Handler) http.Handler {
+	if requestsPerMinute <= 0 {
+		return func(next http.Handler) http.Handler { return next }
+	}
+
+	limiter := httprate.Limit(
+		requestsPerMinute,
+		time.Minute,
+		httprate.WithKeyFuncs(func(r *http.Request) (string, error) {
+			return "ip:" + rl.ClientIP(r), nil
+		}),
+		httprate.WithLimitHandler(rl.rateLimitExceededHandler),
+	)
+
+	return func(next http.Handler) http.Handler {
+		limited := limiter(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if rl.isIPWhitelisted(rl.ClientIP(r)) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			limited.ServeHTTP(w, r)
+		})
+	}
+}
+
 // keyByUserOrIP returns user ID for authenticated requests, or IP for unauthenticated
 func (rl *RateLimiter) keyByUserOrIP(r *http.Request) (string, error) {
 	if userCtx, ok := auth.FromContext(r.Context()); ok && userCtx != nil {
 		return "user:" + userCtx.UserID.String(), nil
 	}
-	return "ip:" + rl.getClientIP(r), nil
+	return "ip:" + rl.ClientIP(r), nil
 }
 
-// getClientIP extracts the client IP from the request
-func (rl *RateLimiter) getClientIP(r *http.Request) string {
+// ClientIP returns the IP of the client that sent the request. X-Forwarded-For and X-Real-IP are
+// only honoured when the connection comes from a trusted proxy, and X-Forwarded-For is read from the
+// right, skipping trusted proxies, so a client cannot choose the IP it is identified by.
+func (rl *RateLimiter) ClientIP(r *http.Request) string {
+	remoteIP, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		remoteIP = r.RemoteAddr
+	}
+	if !rl.isTrustedProxy(remoteIP) {
+		return remoteIP
+	}
+
 	// Check X-Forwarded-For header (for proxied requests)
 	if xff := r.Header.Get("X-Forwarded-For"); xff != "" {
-		// X-Forwarded-For can contain multiple IPs, take the first one
-		if idx := strings.Index(xff, ","); idx != -1 {
-			return strings.TrimSpace(xff[:idx])
+		hops := strings.Split(xff, ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			hop := strings.TrimSpace(hops[i])
+			if hop != "" && !rl.isTrustedProxy(hop) {
+				return hop
+			}
+		}
+		if first := strings.TrimSpace(hops[0]); first != "" {
+			return first
 		}
-		return strings.TrimSpace(xff)
 	}
 
 	// Check X-Real-IP header
-	if xri := r.Header.Get("X-Real-IP"); xri != "" {
+	if xri := strings.TrimSpace(r.Header.Get("X-Real-IP")); xri != "" {
 		return xri
 	}
 
-	// Fall back to RemoteAddr
-	ip, _, err := net.SplitHostPort(r.RemoteAddr)
-	if err != nil {
-		return r.RemoteAddr
+	return remoteIP
+}
+
+// isTrustedProxy checks if the IP belongs to a trusted proxy
+func (rl *RateLimiter) isTrustedProxy(value string) bool {
+	ip := net.ParseIP(value)
+	if ip == nil {
+		return false
 	}
-	return ip
+	for _, network := range rl.trustedProxies {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
 }
 
 // isIPWhitelisted checks if the IP is in the whitelist
@@ -181,7 +257,7 @@ func (rl *RateLimiter) isPathWhitelisted(path string) bool {
 
 // rateLimitExceededHandler handles rate limit exceeded responses
 func (rl *RateLimiter) rateLimitExceededHandler(w http.ResponseWriter, r *http.Request) {
-	clientIP := rl.getClientIP(r)
+	clientIP := rl.ClientIP(r)
 	userID := ""
 	if userCtx, ok := auth.FromContext(r.Context()); ok && userCtx != nil {
 		userID = userCtx.UserID.String()
@@ -9,6 +9,7 @@ import (
 	"github.com/straye-as/relation-api/internal/config"
 	"github.com/straye-as/relation-api/internal/database"
 	"github.com/straye-as/relation-api/internal/datawarehouse"
+	"github.com/straye-as/relation-api/internal/domain"
 	"github.com/straye-as/relation-api/internal/http/handler"
 	"github.com/straye-as/relation-api/internal/http/middleware"
 	httpSwagger "github.com/swaggo/http-swagger/v2"
@@ -19,30 +20,46 @@ import (
 )
 
 type Router struct {
-	cfg                     *config.Config
-	logger                  *zap.Logger
-	db                      *gorm.DB
-	dwClient                *datawarehouse.Client
-	authMiddleware          *auth.Middleware
-	companyFilterMiddleware *middleware.CompanyFilterMiddleware
-	rateLimiter             *middleware.RateLimiter
-	auditMiddleware         *middleware.AuditMiddleware
-	customerHandler         *handler.CustomerHandler
-	projectHandler          *handler.ProjectHandler
-	offerHandler            *handler.OfferHandler
-	inquiryHandler          *handler.InquiryHandler
-	dealHandler             *handler.DealHandler
-	fileHandler             *handler.FileHandler
-	dashboardHandler        *handler.DashboardHandler
-	authHandler             *handler.AuthHandler
-	companyHandler          *handler.CompanyHandler
-	auditHandler            *handler.AuditHandler
-	contactHandler          *handler.ContactHandler
-	budgetItemHandler       *handler.BudgetItemHandler
-	notificationHandler     *handler.NotificationHandler
-	activityHandler         *handler.ActivityHandler
-	supplierHandler         *handler.SupplierHandler
-	assignmentHandler       *handler.AssignmentHandler
+	cfg                      *config.Config
+	logger                   *zap.Logger
+	db                       *gorm.DB
+	dwClient                 *datawarehouse.Client
+	authMiddleware           *auth.Middleware
+	companyFilterMiddleware  *middleware.CompanyFilterMiddleware
+	rateLimiter              *middleware.RateLimiter
+	auditMiddleware          *middleware.AuditMiddleware
+	customerHandler          *handler.CustomerHandler
+	projectHandler           *handler.ProjectHandler
+	offerHandler             *handler.OfferHandler
+	inquiryHandler           *handler.InquiryHandler
+	dealHandler              *handler.DealHandler
+	fileHandler              *handler.FileHandler
+	dashboardHandler         *handler.DashboardHandler
+	authHandler              *handler.AuthHandler
+	companyHandler           *handler.CompanyHandler
+	auditHandler             *handler.AuditHandler
+	contactHandler           *handler.ContactHandler
+	budgetItemHandler        *handler.BudgetItemHandler
+	notificationHandler      *handler.NotificationHandler
+	activityHandler          *handler.ActivityHandler
+	supplierHandler          *handler.SupplierHandler
+	assignmentHandler        *handler.AssignmentHandler
+	postalCodeHandler        *handler.PostalCodeHandler
+	importHandler            *handler.ImportHandler
+	exportHandler            *handler.ExportHandler
+	erpReconciliationHandler *handler.ERPReconciliationHandler
+	customerTierHandler      *handler.CustomerTierHandler
+	creditExposureHandler    *handler.CreditExposureHandler
+	calendarHandler          *handler.CalendarHandler
+	emailHandler             *handler.EmailHandler
+	dealPipelineHandler      *handler.DealPipelineHandler
+	salesTargetHandler       *handler.SalesTargetHandler
+	forecastHandler          *handler.ForecastHandler
+	inquiryIntakeHandler     *handler.InquiryIntakeHandler
+	inquiryAssignmentHandler *handler.InquiryAssignmentHandler
+	inquirySLAHandler        *handler.InquirySLAHandler
+	projectScheduleHandler   *handler.ProjectScheduleHandler
+	resourceCapacityHandler  *handler.ResourceCapacityHandler
 }
 
 func NewRouter(
@@ -70,32 +87,64 @@ func NewRouter(
 	activityHandler *handler.ActivityHandler,
 	supplierHandler *handler.SupplierHandler,
 	assignmentHandler *handler.AssignmentHandler,
+	postalCodeHandler *handler.PostalCodeHandler,
+	importHandler *handler.ImportHandler,
+	exportHandler *handler.ExportHandler,
+	erpReconciliationHandler *handler.ERPReconciliationHandler,
+	customerTierHandler *handler.CustomerTierHandler,
+	creditExposureHandler *handler.CreditExposureHandler,
+	calendarHandler *handler.CalendarHandler,
+	emailHandler *handler.EmailHandler,
+	dealPipelineHandler *handler.DealPipelineHandler,
+	salesTargetHandler *handler.SalesTargetHandler,
+	forecastHandler *handler.ForecastHandler,
+	inquiryIntakeHandler *handler.InquiryIntakeHandler,
+	inquiryAssignmentHandler *handler.InquiryAssignmentHandler,
+	inquirySLAHandler *handler.InquirySLAHandler,
+	projectScheduleHandler *handler.ProjectScheduleHandler,
+	resourceCapacityHandler *handler.ResourceCapacityHandler,
 ) *Router {
 	return &Router{
-		cfg:                     cfg,
-		logger:                  logger,
-		db:                      db,
-		dwClient:                dwClient,
-		authMiddleware:          authMiddleware,
-		companyFilterMiddleware: companyFilterMiddleware,
-		rateLimiter:             rateLimiter,
-		auditMiddleware:         auditMiddleware,
-		customerHandler:         customerHandler,
-		projectHandler:          projectHandler,
-		offerHandler:            offerHandler,
-		inquiryHandler:          inquiryHandler,
-		dealHandler:             dealHandler,
-		fileHandler:             fileHandler,
-		dashboardHandler:        dashboardHandler,
-		authHandler:             authHandler,
-		companyHandler:          companyHandler,
-		auditHandler:            auditHandler,
-		contactHandler:          contactHandler,
-		budgetItemHandler:       budgetItemHandler,
-		notificationHandler:     notificationHandler,
-		activityHandler:         activityHandler,
-		supplierHandler:         supplierHandler,
-		assignmentHandler:       assignmentHandler,
+		cfg:                      cfg,
+		logger:                   logger,
+		db:                       db,
+		dwClient:                 dwClient,
+		authMiddleware:           authMiddleware,
+		companyFilterMiddleware:  companyFilterMiddleware,
+		rateLimiter:              rateLimiter,
+		auditMiddleware:          auditMiddleware,
+		customerHandler:          customerHandler,
+		projectHandler:           projectHandler,
+		offerHandler:             offerHandler,
+		inquiryHandler:           inquiryHandler,
+		dealHandler:              dealHandler,
+		fileHandler:              fileHandler,
+		dashboardHandler:         dashboardHandler,
+		authHandler:              authHandler,
+		companyHandler:           companyHandler,
+		auditHandler:             auditHandler,
+		contactHandler:           contactHandler,
+		budgetItemHandler:        budgetItemHandler,
+		notificationHandler:      notificationHandler,
+		activityHandler:          activityHandler,
+		supplierHandler:          supplierHandler,
+		assignmentHandler:        assignmentHandler,
+		postalCodeHandler:        postalCodeHandler,
+		importHandler:            importHandler,
+		exportHandler:            exportHandler,
+		erpReconciliationHandler: erpReconciliationHandler,
+		customerTierHandler:      customerTierHandler,
+		creditExposureHandler:    creditExposureHandler,
+		calendarHandler:          calendarHandler,
+		emailHandler:             emailHandler,
+		dealPipelineHandler:      dealPipelineHandler,
+		salesTargetHandler:       salesTargetHandler,
+		forecastHandler:          forecastHandler,
+		inquiryIntakeHandler:     inquiryIntakeHandler,
+		inquiryAssignmentHandler: inquiryAssignmentHandler,
+		inquirySLAHandler:        inquirySLAHandler,
+		projectScheduleHandler:   projectScheduleHandler,
+		resourceCapacityHandler:  resourceCapacityHandler,
 	}
 }
 
@@ -250,6 +299,13 @@ func (rt *Router) Setup() http.Handler {
 		// Public routes (no auth required)
 		r.Get("/companies", rt.companyHandler.List)
 		r.Get("/customers/search", rt.customerHandler.FuzzySearch) // Fuzzy customer search (no auth)
+		r.Get("/calendar/{token}.ics", rt.calendarHandler.Feed)    // Calendar subscription (secret token in URL)
+
+		// Website inquiry forms (public site key in URL, tighter per-IP limit on submissions)
+		r.Route("/public/sites/{siteKey}", func(r chi.Router) {
+			r.Get("/challenge", rt.inquiryIntakeHandler.Challenge)
+			r.With(rt.rateLimiter.LimitByIPAt(rt.cfg.Intake.RequestsPerMinute)).Post("/inquiries", rt.inquiryIntakeHandler.Submit)
+		})
 
 		// Protected routes
 		r.Group(func(r chi.Router) {
@@ -257,6 +313,9 @@ func (rt *Router) Setup() http.Handler {
 			r.Use(rt.companyFilterMiddleware.Filter)
 			r.Use(rt.auditMiddleware.Audit) // Audit all modifications
 
+			// List exports (CSV/XLSX) require reports:export
+			exportPermission := rt.authMiddleware.RequirePermission(domain.PermissionReportsExport)
+
 			// Companies (protected routes for details and updates)
 			r.Route("/companies", func(r chi.Router) {
 				r.Get("/{id}", rt.companyHandler.GetByID)
@@ -268,6 +327,11 @@ func (rt *Router) Setup() http.Handler {
 			r.Get("/auth/permissions", rt.authHandler.Permissions)
 			r.Get("/users", rt.authHandler.ListUsers)
 
+			// Calendar subscription token for the current user
+			r.Get("/calendar/feed", rt.calendarHandler.GetFeed)
+			r.Post("/calendar/feed", rt.calendarHandler.CreateFeedToken)
+			r.Delete("/calendar/feed", rt.calendarHandler.RevokeFeedToken)
+
 			// Audit logs (requires system:audit_logs permission)
 			r.Route("/audit", func(r chi.Router) {
 				r.Get("/", rt.auditHandler.List)
@@ -281,14 +345,40 @@ func (rt *Router) Setup() http.Handler {
 			r.Route("/customers", func(r chi.Router) {
 				r.Get("/", rt.customerHandler.List)
 				r.Post("/", rt.customerHandler.Create)
+				r.With(exportPermission).Get("/export", rt.exportHandler.ExportCustomers)
 				r.Get("/erp-differences", rt.customerHandler.GetERPDifferences) // ERP sync endpoint
+				r.Post("/enrich", rt.customerHandler.BulkEnrichFromRegistry)    // Enhetsregisteret bulk enrichment
+
+				// Customer tier rules and recalculation
+				r.Get("/tier-rules", rt.customerTierHandler.ListRules)
+				r.Put("/tier-rules/{companyId}", rt.customerTierHandler.UpdateRule)
+				r.Delete("/tier-rules/{companyId}", rt.customerTierHandler.DeleteRule)
+				r.Post("/tier-recalculation", rt.customerTierHandler.RecalculateAll)
+
+				// ERP customer reconciliation (history, approval queue and field policies)
+				r.Route("/erp-reconciliation", func(r chi.Router) {
+					r.Get("/", rt.erpReconciliationHandler.GetOverview)
+					r.Get("/runs", rt.erpReconciliationHandler.ListRuns)
+					r.Post("/runs", rt.erpReconciliationHandler.Run)
+					r.Get("/runs/{id}", rt.erpReconciliationHandler.GetRun)
+					r.Get("/decisions", rt.erpReconciliationHandler.ListDecisions)
+					r.Post("/decisions/{id}/approve", rt.erpReconciliationHandler.ApproveDecision)
+					r.Post("/decisions/{id}/reject", rt.erpReconciliationHandler.RejectDecision)
+					r.Get("/policies", rt.erpReconciliationHandler.GetPolicies)
+					r.Put("/policies", rt.erpReconciliationHandler.UpdatePolicies)
+				})
+
 				r.Get("/{id}", rt.customerHandler.GetByID)
 				r.Put("/{id}", rt.customerHandler.Update)
 				r.Delete("/{id}", rt.customerHandler.Delete)
 				r.Get("/{id}/contacts", rt.contactHandler.GetContactsForEntity)
 				r.Post("/{id}/contacts", rt.customerHandler.CreateContact)
+				r.Get("/{id}/contacts/vcard", rt.contactHandler.ExportCustomerVCards)
 				r.Get("/{id}/offers", rt.customerHandler.ListOffers)
 				r.Get("/{id}/projects", rt.customerHandler.ListProjects)
+				r.Get("/{id}/credit-exposure", rt.creditExposureHandler.GetExposure)
+				r.Get("/{id}/group", rt.customerHandler.GetGroup)
+				r.Post("/{id}/enrich", rt.customerHandler.EnrichFromRegistry)
 
 				// File endpoints
 				r.Get("/{id}/files", rt.fileHandler.ListCustomerFiles)
@@ -296,7 +386,10 @@ func (rt *Router) Setup() http.Handler {
 
 				// Individual property update endpoints
 				r.Put("/{id}/status", rt.customerHandler.UpdateStatus)
-				r.Put("/{id}/tier", rt.customerHandler.UpdateTier)
+				r.Put("/{id}/tier", rt.customerHandler.UpdateTier) // Manual override, locks the tier
+				r.Delete("/{id}/tier/lock", rt.customerTierHandler.Unlock)
+				r.Post("/{id}/tier/recalculate", rt.customerTierHandler.Recalculate)
+				r.Get("/{id}/tier/history", rt.customerTierHandler.GetHistory)
 				r.Put("/{id}/industry", rt.customerHandler.UpdateIndustry)
 				r.Put("/{id}/notes", rt.customerHandler.UpdateNotes)
 				r.Put("/{id}/company", rt.customerHandler.UpdateCompany)
@@ -308,23 +401,40 @@ func (rt *Router) Setup() http.Handler {
 				r.Put("/{id}/postal-code", rt.customerHandler.UpdatePostalCode)
 				r.Put("/{id}/city", rt.customerHandler.UpdateCity)
 				r.Put("/{id}/website", rt.customerHandler.UpdateWebsite)
+				r.Put("/{id}/parent", rt.customerHandler.UpdateParent) // Customer group (konsern)
 			})
 
 			// Contacts
 			r.Route("/contacts", func(r chi.Router) {
 				r.Get("/", rt.contactHandler.ListContacts)
 				r.Post("/", rt.contactHandler.CreateContact)
+				r.Get("/duplicates", rt.contactHandler.FindDuplicates)
+				r.Post("/vcard", rt.contactHandler.ImportVCards)
 				r.Get("/{id}", rt.contactHandler.GetContact)
 				r.Put("/{id}", rt.contactHandler.UpdateContact)
 				r.Delete("/{id}", rt.contactHandler.DeleteContact)
 				r.Post("/{id}/relationships", rt.contactHandler.AddRelationship)
+				r.Put("/{id}/relationships/{relationshipId}", rt.contactHandler.UpdateRelationship)
 				r.Delete("/{id}/relationships/{relationshipId}", rt.contactHandler.RemoveRelationship)
+				r.Get("/{id}/vcard", rt.contactHandler.ExportVCard)
+				r.With(rt.authMiddleware.RequirePermission(domain.PermissionContactsDelete)).Post("/{id}/merge", rt.contactHandler.MergeContacts)
+
+				// GDPR: data subject export, anonymization and retention review require contacts:delete
+				r.Group(func(r chi.Router) {
+					r.Use(rt.authMiddleware.RequirePermission(domain.PermissionContactsDelete))
+					r.Get("/data-export", rt.contactHandler.ExportDataSubjectByEmail)
+					r.Get("/retention-review", rt.contactHandler.ListRetentionReview)
+					r.Get("/{id}/data-export", rt.contactHandler.ExportDataSubject)
+					r.Post("/{id}/anonymize", rt.contactHandler.AnonymizeContact)
+					r.Post("/{id}/retention-review", rt.contactHandler.KeepAfterRetentionReview)
+				})
 			})
 
 			// Projects (simplified containers for offers)
 			r.Route("/projects", func(r chi.Router) {
 				r.Get("/", rt.projectHandler.List)
 				r.Post("/", rt.projectHandler.Create)
+				r.With(exportPermission).Get("/export", rt.exportHandler.ExportProjects)
 				r.Get("/{id}", rt.projectHandler.GetByID)
 				r.Put("/{id}", rt.projectHandler.Update)
 				r.Delete("/{id}", rt.projectHandler.Delete)
@@ -337,6 +447,23 @@ func (rt *Router) Setup() http.Handler {
 				r.Get("/{id}/files", rt.fileHandler.ListProjectFiles)
 				r.Post("/{id}/files", rt.fileHandler.UploadToProject)
 
+				// Budget: working budget rebudgeted from the won offers
+				r.Get("/{id}/budget", rt.budgetItemHandler.GetProjectBudgetWithDimensions)
+				r.Get("/{id}/budget/rollup", rt.budgetItemHandler.GetProjectBudgetRollup)
+				r.Post("/{id}/budget/initialize", rt.budgetItemHandler.InitializeProjectBudget)
+				r.Get("/{id}/budget/dimensions", rt.budgetItemHandler.ListProjectDimensions)
+				r.Post("/{id}/budget/dimensions", rt.budgetItemHandler.AddToProject)
+				r.Put("/{id}/budget/dimensions/{dimensionId}", rt.budgetItemHandler.UpdateProjectDimension)
+				r.Delete("/{id}/budget/dimensions/{dimensionId}", rt.budgetItemHandler.DeleteProjectDimension)
+				r.Put("/{id}/budget/reorder", rt.budgetItemHandler.ReorderProjectDimensions)
+
+				// Schedule: milestones, tasks and dependencies
+				r.Get("/{id}/schedule", rt.projectScheduleHandler.GetSchedule)
+				r.Get("/{id}/tasks", rt.projectScheduleHandler.ListTasks)
+				r.Post("/{id}/tasks", rt.projectScheduleHandler.CreateTask)
+				r.Put("/{id}/tasks/{taskId}", rt.projectScheduleHandler.UpdateTask)
+				r.Delete("/{id}/tasks/{taskId}", rt.projectScheduleHandler.DeleteTask)
+
 				// Individual property update endpoints
 				r.Put("/{id}/name", rt.projectHandler.UpdateName)
 				r.Put("/{id}/description", rt.projectHandler.UpdateDescription)
@@ -349,6 +476,7 @@ func (rt *Router) Setup() http.Handler {
 			r.Route("/inquiries", func(r chi.Router) {
 				r.Get("/", rt.inquiryHandler.List)
 				r.Post("/", rt.inquiryHandler.Create)
+				r.Get("/sla-report", rt.inquirySLAHandler.GetReport)
 				r.Get("/{id}", rt.inquiryHandler.GetByID)
 				r.Delete("/{id}", rt.inquiryHandler.Delete)
 				r.Put("/{id}/company", rt.inquiryHandler.UpdateCompany)
@@ -359,11 +487,16 @@ func (rt *Router) Setup() http.Handler {
 			r.Route("/offers", func(r chi.Router) {
 				r.Get("/", rt.offerHandler.List)
 				r.Post("/", rt.offerHandler.Create)
+				r.With(exportPermission).Get("/export", rt.exportHandler.ExportOffers)
 				r.Get("/next-number", rt.offerHandler.GetNextNumber) // Must be before /{id} to avoid path conflict
 				r.Get("/{id}", rt.offerHandler.GetByID)
 				r.Put("/{id}", rt.offerHandler.Update)
 				r.Delete("/{id}", rt.offerHandler.Delete)
 
+				// Contacts and buying committee
+				r.Get("/{id}/contacts", rt.contactHandler.GetContactsForEntity)
+				r.Get("/{id}/buying-committee", rt.contactHandler.GetBuyingCommittee)
+
 				// Lifecycle endpoints
 				r.Post("/{id}/advance", rt.offerHandler.Advance)
 				r.Post("/{id}/send", rt.offerHandler.Send)
@@ -445,10 +578,20 @@ func (rt *Router) Setup() http.Handler {
 			r.Route("/deals", func(r chi.Router) {
 				r.Get("/", rt.dealHandler.List)
 				r.Post("/", rt.dealHandler.Create)
+				r.With(exportPermission).Get("/export", rt.exportHandler.ExportDeals)
 				r.Get("/analytics", rt.dealHandler.GetPipelineAnalytics)
 				r.Get("/pipeline", rt.dealHandler.GetPipelineOverview)
 				r.Get("/stats", rt.dealHandler.GetPipelineStats)
 				r.Get("/forecast", rt.dealHandler.GetForecast)
+				r.Get("/forecast/calibrated", rt.forecastHandler.GetCalibratedForecast)
+
+				// Pipelines and stages per company
+				r.Get("/pipelines", rt.dealPipelineHandler.List)
+				r.Post("/pipelines", rt.dealPipelineHandler.Create)
+				r.Get("/pipelines/{id}", rt.dealPipelineHandler.GetByID)
+				r.Put("/pipelines/{id}", rt.dealPipelineHandler.Update)
+				r.Delete("/pipelines/{id}", rt.dealPipelineHandler.Delete)
+
 				r.Get("/{id}", rt.dealHandler.GetByID)
 				r.Put("/{id}", rt.dealHandler.Update)
 				r.Delete("/{id}", rt.dealHandler.Delete)
@@ -462,6 +605,34 @@ func (rt *Router) Setup() http.Handler {
 				r.Get("/{id}/contacts", rt.contactHandler.GetContactsForEntity)
 			})
 
+			// Sales targets and quota attainment
+			r.Route("/targets", func(r chi.Router) {
+				r.Get("/", rt.salesTargetHandler.List)
+				r.Post("/", rt.salesTargetHandler.Create)
+				r.Get("/attainment", rt.salesTargetHandler.GetAttainment)
+				r.Get("/{id}", rt.salesTargetHandler.GetByID)
+				r.Put("/{id}", rt.salesTargetHandler.Update)
+				r.Delete("/{id}", rt.salesTargetHandler.Delete)
+			})
+
+			// Resource allocations of users to offers and projects
+			r.Route("/resource-allocations", func(r chi.Router) {
+				r.Get("/", rt.resourceCapacityHandler.ListAllocations)
+				r.Post("/", rt.resourceCapacityHandler.CreateAllocation)
+				r.Get("/{id}", rt.resourceCapacityHandler.GetAllocation)
+				r.Put("/{id}", rt.resourceCapacityHandler.UpdateAllocation)
+				r.Delete("/{id}", rt.resourceCapacityHandler.DeleteAllocation)
+			})
+
+			// Capacity calendars (weekly hours and absences) and overallocation
+			r.Route("/capacity", func(r chi.Router) {
+				r.Get("/overallocations", rt.resourceCapacityHandler.GetOverallocationReport)
+				r.Get("/users/{userId}", rt.resourceCapacityHandler.GetCalendar)
+				r.Put("/users/{userId}/hours", rt.resourceCapacityHandler.SetHoursPerWeek)
+				r.Post("/users/{userId}/absences", rt.resourceCapacityHandler.CreateAbsence)
+				r.Delete("/users/{userId}/absences/{absenceId}", rt.resourceCapacityHandler.DeleteAbsence)
+			})
+
 			// Files (generic operations - entity-specific uploads are under /customers, /projects, /offers, /suppliers)
 			r.Route("/files", func(r chi.Router) {
 				r.Get("/{id}", rt.fileHandler.GetByID)
@@ -471,6 +642,8 @@ func (rt *Router) Setup() http.Handler {
 
 			// Dashboard & Search
 			r.Get("/dashboard/metrics", rt.dashboardHandler.GetMetrics)
+			r.With(rt.authMiddleware.RequirePermission(domain.PermissionReportsView)).
+				Get("/dashboard/intercompany-matrix", rt.dashboardHandler.GetIntercompanyMatrix)
 			r.Get("/search", rt.dashboardHandler.Search)
 
 			// Notifications
@@ -486,6 +659,7 @@ func (rt *Router) Setup() http.Handler {
 			r.Route("/activities", func(r chi.Router) {
 				r.Get("/", rt.activityHandler.List)
 				r.Post("/", rt.activityHandler.Create)
+				r.With(exportPermission).Get("/export", rt.exportHandler.ExportActivities)
 				r.Get("/my-tasks", rt.activityHandler.GetMyTasks)
 				r.Get("/upcoming", rt.activityHandler.GetUpcoming)
 				r.Get("/stats", rt.activityHandler.GetStats)
@@ -498,10 +672,37 @@ func (rt *Router) Setup() http.Handler {
 				r.Delete("/{id}/attendees/{userId}", rt.activityHandler.RemoveAttendee)
 			})
 
+			// Email ingestion (.eml uploads filed as email activities)
+			r.Route("/emails", func(r chi.Router) {
+				r.Use(rt.authMiddleware.RequirePermission(domain.PermissionActivitiesWrite))
+				r.Get("/", rt.emailHandler.List)
+				r.Post("/ingest", rt.emailHandler.Ingest)
+			})
+
+			// Website inquiry forms (site keys, spam protection and submission log)
+			r.Route("/inquiry-sites", func(r chi.Router) {
+				r.Get("/", rt.inquiryIntakeHandler.ListSites)
+				r.Post("/", rt.inquiryIntakeHandler.CreateSite)
+				r.Get("/{id}", rt.inquiryIntakeHandler.GetSite)
+				r.Put("/{id}", rt.inquiryIntakeHandler.UpdateSite)
+				r.Delete("/{id}", rt.inquiryIntakeHandler.DeleteSite)
+				r.Get("/{id}/submissions", rt.inquiryIntakeHandler.ListSubmissions)
+			})
+
+			// Inquiry assignment rules (who new inquiries are assigned to)
+			r.Route("/inquiry-assignment-rules", func(r chi.Router) {
+				r.Get("/", rt.inquiryAssignmentHandler.List)
+				r.Post("/", rt.inquiryAssignmentHandler.Create)
+				r.Get("/{id}", rt.inquiryAssignmentHandler.GetByID)
+				r.Put("/{id}", rt.inquiryAssignmentHandler.Update)
+				r.Delete("/{id}", rt.inquiryAssignmentHandler.Delete)
+			})
+
 			// Suppliers
 			r.Route("/suppliers", func(r chi.Router) {
 				r.Get("/", rt.supplierHandler.List)
 				r.Post("/", rt.supplierHandler.Create)
+				r.With(exportPermission).Get("/export", rt.exportHandler.ExportSuppliers)
 				r.Get("/{id}", rt.supplierHandler.GetByID)
 				r.Put("/{id}", rt.supplierHandler.Update)
 				r.Delete("/{id}", rt.supplierHandler.Delete)
@@ -532,6 +733,34 @@ func (rt *Router) Setup() http.Handler {
 				r.Put("/{id}/contacts/{contactId}", rt.supplierHandler.UpdateContact)
 				r.Delete("/{id}/contacts/{contactId}", rt.supplierHandler.DeleteContact)
 			})
+
+			// Postal codes (bundled Posten register)
+			r.Route("/postal-codes", func(r chi.Router) {
+				r.Get("/mismatches", rt.postalCodeHandler.GetMismatchReport)
+				r.Get("/{code}", rt.postalCodeHandler.Lookup)
+			})
+
+			// Imports (customers and offers from XLSX/CSV)
+			r.Route("/imports", func(r chi.Router) {
+				r.Get("/", rt.importHandler.List)
+				r.Post("/", rt.importHandler.Upload)
+				r.Get("/fields", rt.importHandler.GetFields)
+
+				r.Route("/mapping-profiles", func(r chi.Router) {
+					r.Get("/", rt.importHandler.ListProfiles)
+					r.Post("/", rt.importHandler.CreateProfile)
+					r.Put("/{id}", rt.importHandler.UpdateProfile)
+					r.Delete("/{id}", rt.importHandler.DeleteProfile)
+				})
+
+				r.Route("/{id}", func(r chi.Router) {
+					r.Get("/", rt.importHandler.Get)
+					r.Delete("/", rt.importHandler.Delete)
+					r.Post("/dry-run", rt.importHandler.DryRun)
+					r.Post("/commit", rt.importHandler.Commit)
+					r.Get("/report", rt.importHandler.GetReport)
+				})
+			})
 		})
 	})
 
@@ -0,0 +1,196 @@
+// Package ical writes iCalendar (RFC 5545) subscription feeds that calendar clients such as
+// Outlook, Google Calendar and Apple Calendar poll for updates.
+package ical
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// maxLineOctets is the line length after which content lines are folded
+const maxLineOctets = 75
+
+// Event statuses
+const (
+	StatusConfirmed = "CONFIRMED"
+	StatusTentative = "TENTATIVE"
+	StatusCancelled = "CANCELLED"
+)
+
+// Calendar is a published calendar with its events
+type Calendar struct {
+	ProductID string // PRODID, e.g. -//Straye//Relation API//NO
+	Name      string // X-WR-CALNAME shown by clients when subscribing
+	// RefreshInterval is how often clients should poll the feed; zero leaves it to the client
+	RefreshInterval time.Duration
+	Events          []Event
+}
+
+// Event is a single VEVENT. Clients replace an event when a later feed contains the same UID.
+type Event struct {
+	UID         string
+	Summary     string
+	Description string
+	Location    string
+	URL         string
+	Categories  []string
+	Status      string
+	// Start and End are written in UTC; for all-day events only the date is used and End is exclusive
+	Start        time.Time
+	End          time.Time
+	AllDay       bool
+	Created      time.Time
+	LastModified time.Time
+}
+
+// Write writes the calendar as an iCalendar stream
+func Write(w io.Writer, cal *Calendar) error {
+	bw := bufio.NewWriter(w)
+	for _, line := range cal.lines() {
+		if _, err := bw.WriteString(fold(line)); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// lines returns the unfolded content lines of the calendar
+func (c *Calendar) lines() []string {
+	lines := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:" + c.ProductID,
+		"CALSCALE:GREGORIAN",
+		"METHOD:PUBLISH",
+	}
+	if c.Name != "" {
+		lines = append(lines, "X-WR-CALNAME:"+escape(c.Name))
+	}
+	if c.RefreshInterval > 0 {
+		interval := duration(c.RefreshInterval)
+		lines = append(lines,
+			"REFRESH-INTERVAL;VALUE=DURATION:"+interval,
+			"X-PUBLISHED-TTL:"+interval,
+		)
+	}
+	for i := range c.Events {
+		lines = append(lines, c.Events[i].lines()...)
+	}
+	return append(lines, "END:VCALENDAR")
+}
+
+// lines returns the unfolded content lines of the event
+func (e *Event) lines() []string {
+	stamp := e.LastModified
+	if stamp.IsZero() {
+		stamp = time.Now()
+	}
+
+	lines := []string{
+		"BEGIN:VEVENT",
+		"UID:" + e.UID,
+		"DTSTAMP:" + dateTime(stamp),
+	}
+	if e.AllDay {
+		end := e.End
+		if !end.After(e.Start) {
+			end = e.Start.AddDate(0, 0, 1)
+		}
+		lines = append(lines,
+			"DTSTART;VALUE=DATE:"+date(e.Start),
+			"DTEND;VALUE=DATE:"+date(end),
+		)
+	} else {
+		lines = append(lines, "DTSTART:"+dateTime(e.Start))
+		if e.End.After(e.Start) {
+			lines = append(lines, "DTEND:"+dateTime(e.End))
+		}
+	}
+	lines = append(lines, "SUMMARY:"+escape(e.Summary))
+	if e.Description != "" {
+		lines = append(lines, "DESCRIPTION:"+escape(e.Description))
+	}
+	if e.Location != "" {
+		lines = append(lines, "LOCATION:"+escape(e.Location))
+	}
+	if e.URL != "" {
+		lines = append(lines, "URL:"+e.URL)
+	}
+	if len(e.Categories) > 0 {
+		escaped := make([]string, len(e.Categories))
+		for i, category := range e.Categories {
+			escaped[i] = escape(category)
+		}
+		lines = append(lines, "CATEGORIES:"+strings.Join(escaped, ","))
+	}
+	if e.Status != "" {
+		lines = append(lines, "STATUS:"+e.Status)
+	}
+	// Outlook shows all-day deadlines as busy unless they are marked transparent
+	if e.AllDay {
+		lines = append(lines, "TRANSP:TRANSPARENT")
+	}
+	if !e.Created.IsZero() {
+		lines = append(lines, "CREATED:"+dateTime(e.Created))
+	}
+	if !e.LastModified.IsZero() {
+		lines = append(lines, "LAST-MODIFIED:"+dateTime(e.LastModified))
+	}
+	return append(lines, "END:VEVENT")
+}
+
+// dateTime formats a time as a UTC DATE-TIME value
+func dateTime(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+// date formats the calendar date of a time as a DATE value
+func date(t time.Time) string {
+	return t.Format("20060102")
+}
+
+// duration formats a positive duration as a DURATION value in whole minutes
+func duration(d time.Duration) string {
+	minutes := int(d / time.Minute)
+	if minutes < 1 {
+		minutes = 1
+	}
+	var b strings.Builder
+	b.WriteString("PT")
+	if hours := minutes / 60; hours > 0 {
+		b.WriteString(strconv.Itoa(hours) + "H")
+	}
+	if minutes%60 > 0 {
+		b.WriteString(strconv.Itoa(minutes%60) + "M")
+	}
+	return b.String()
+}
+
+// escape escapes a TEXT value
+func escape(value string) string {
+	return strings.NewReplacer(`\`, `\\`, ",", `\,`, ";", `\;`, "\r\n", `\n`, "\n", `\n`, "\r", `\n`).Replace(value)
+}
+
+// fold splits a content line into lines of at most 75 octets without splitting characters
+func fold(line string) string {
+	var b strings.Builder
+	limit := maxLineOctets
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		// Continuation lines start with a space
+		limit = maxLineOctets - 1
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+	return b.String()
+}
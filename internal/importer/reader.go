@@ -0,0 +1,177 @@
+// Package importer reads tabular import files (XLSX and CSV) and parses the cell values
+// found in spreadsheets exported from the ERP system and Excel.
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// MaxRows is the maximum number of data rows accepted in a single import file
+const MaxRows = 5000
+
+var (
+	// ErrUnsupportedFormat is returned for files that are neither XLSX nor CSV
+	ErrUnsupportedFormat = errors.New("unsupported file format: only .xlsx and .csv are supported")
+
+	// ErrEmptyFile is returned when the file has no header row
+	ErrEmptyFile = errors.New("file has no header row")
+
+	// ErrTooManyRows is returned when the file exceeds MaxRows data rows
+	ErrTooManyRows = fmt.Errorf("file has more than %d rows", MaxRows)
+)
+
+// Sheet is the content of an import file: a header row and the data rows below it.
+// Every row has the same number of cells as the header.
+type Sheet struct {
+	Headers []string
+	Rows    [][]string
+}
+
+// Read parses an import file. The format is determined by the file extension.
+// For XLSX files only the first worksheet is read.
+func Read(filename string, r io.Reader) (*Sheet, error) {
+	var records [][]string
+	var err error
+
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".xlsx":
+		records, err = readXLSX(r)
+	case ".csv":
+		records, err = readCSV(r)
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return newSheet(records)
+}
+
+// readXLSX reads all rows of the first worksheet.
+// Raw cell values are used so numbers keep full precision and dates are Excel serial numbers.
+func readXLSX(r io.Reader) ([][]string, error) {
+	file, err := excelize.OpenReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open xlsx file: %w", err)
+	}
+	defer file.Close()
+
+	sheets := file.GetSheetList()
+	if len(sheets) == 0 {
+		return nil, ErrEmptyFile
+	}
+
+	rows, err := file.GetRows(sheets[0], excelize.Options{RawCellValue: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read worksheet %q: %w", sheets[0], err)
+	}
+	return rows, nil
+}
+
+// readCSV reads a CSV file with comma or semicolon separator.
+// Excel in Norwegian locale exports semicolon-separated files in Windows-1252,
+// so input that is not valid UTF-8 is decoded as Latin-1.
+func readCSV(r io.Reader) ([][]string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read csv file: %w", err)
+	}
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	if !utf8.Valid(data) {
+		data = latin1ToUTF8(data)
+	}
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.Comma = detectSeparator(data)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse csv file: %w", err)
+	}
+	return records, nil
+}
+
+// detectSeparator picks semicolon or comma based on which occurs most in the header line
+func detectSeparator(data []byte) rune {
+	header, _ := bufio.NewReader(bytes.NewReader(data)).ReadString('\n')
+	if strings.Count(header, ";") > strings.Count(header, ",") {
+		return ';'
+	}
+	return ','
+}
+
+// latin1ToUTF8 converts Latin-1 encoded bytes to UTF-8
+func latin1ToUTF8(data []byte) []byte {
+	runes := make([]rune, len(data))
+	for i, b := range data {
+		runes[i] = rune(b)
+	}
+	return []byte(string(runes))
+}
+
+// newSheet builds a sheet from raw records. Leading empty rows are skipped, the first
+// non-empty row is the header, and empty data rows are dropped.
+func newSheet(records [][]string) (*Sheet, error) {
+	start := 0
+	for start < len(records) && isEmptyRow(records[start]) {
+		start++
+	}
+	if start == len(records) {
+		return nil, ErrEmptyFile
+	}
+
+	headers := make([]string, len(records[start]))
+	for i, header := range records[start] {
+		headers[i] = strings.TrimSpace(header)
+	}
+	// Drop trailing columns without a header
+	for len(headers) > 0 && headers[len(headers)-1] == "" {
+		headers = headers[:len(headers)-1]
+	}
+	if len(headers) == 0 {
+		return nil, ErrEmptyFile
+	}
+
+	sheet := &Sheet{Headers: headers, Rows: [][]string{}}
+	for _, record := range records[start+1:] {
+		if isEmptyRow(record) {
+			continue
+		}
+		if len(sheet.Rows) >= MaxRows {
+			return nil, ErrTooManyRows
+		}
+
+		row := make([]string, len(headers))
+		for i := range headers {
+			if i < len(record) {
+				row[i] = strings.TrimSpace(record[i])
+			}
+		}
+		sheet.Rows = append(sheet.Rows, row)
+	}
+
+	return sheet, nil
+}
+
+// isEmptyRow returns true if all cells in the row are blank
+func isEmptyRow(record []string) bool {
+	for _, cell := range record {
+		if strings.TrimSpace(cell) != "" {
+			return false
+		}
+	}
+	return true
+}
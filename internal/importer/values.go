@@ -0,0 +1,131 @@
+package importer
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// excelEpoch is day zero of Excel's 1900 date system (accounting for the 1900 leap year bug)
+var excelEpoch = time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+
+// dateLayouts are the date formats accepted in text cells
+var dateLayouts = []string{
+	"2006-01-02",
+	"02.01.2006",
+	"2.1.2006",
+	"02.01.06",
+	"2006-01-02 15:04:05",
+	"02.01.2006 15:04",
+	"02/01/2006",
+}
+
+// ParseNumber parses a number in either Norwegian ("1 234,50") or English ("1,234.50") notation.
+// Currency suffixes such as "kr" and "NOK" are ignored.
+func ParseNumber(value string) (float64, error) {
+	cleaned := strings.TrimSpace(value)
+	for _, suffix := range []string{"NOK", "nok", "kr", ",-"} {
+		cleaned = strings.TrimSpace(strings.TrimSuffix(cleaned, suffix))
+	}
+	cleaned = strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, cleaned)
+	if cleaned == "" {
+		return 0, fmt.Errorf("empty number")
+	}
+
+	lastComma := strings.LastIndex(cleaned, ",")
+	lastDot := strings.LastIndex(cleaned, ".")
+	switch {
+	case lastComma > lastDot:
+		// Comma is the decimal separator; dots are thousand separators
+		cleaned = strings.ReplaceAll(cleaned, ".", "")
+		cleaned = strings.Replace(cleaned, ",", ".", 1)
+	case lastDot > lastComma:
+		// Dot is the decimal separator; commas are thousand separators
+		cleaned = strings.ReplaceAll(cleaned, ",", "")
+	}
+
+	number, err := strconv.ParseFloat(cleaned, 64)
+	if err != nil || math.IsNaN(number) || math.IsInf(number, 0) {
+		return 0, fmt.Errorf("invalid number %q", value)
+	}
+	return number, nil
+}
+
+// ParseDate parses a date cell. Excel serial numbers (raw XLSX values) and common
+// Norwegian and ISO text formats are accepted.
+func ParseDate(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, fmt.Errorf("empty date")
+	}
+
+	if serial, err := strconv.ParseFloat(value, 64); err == nil {
+		// Excel serial dates from 1950 to 2100 cover all realistic business data
+		if serial >= 18264 && serial <= 73051 {
+			days := math.Floor(serial)
+			seconds := math.Round((serial - days) * 86400)
+			return excelEpoch.AddDate(0, 0, int(days)).Add(time.Duration(seconds) * time.Second), nil
+		}
+		return time.Time{}, fmt.Errorf("invalid date %q", value)
+	}
+
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q", value)
+}
+
+// ParseBool parses yes/no cells in Norwegian or English
+func ParseBool(value string) (bool, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "1", "true", "yes", "y", "ja", "j", "x", "sann":
+		return true, nil
+	case "", "0", "false", "no", "n", "nei", "usann":
+		return false, nil
+	default:
+		return false, fmt.Errorf("invalid yes/no value %q", value)
+	}
+}
+
+// ParseOrgNumber returns the digits of an organization number cell.
+// Numeric XLSX cells can be exported as floats (e.g. "977195500.0") and are converted back.
+func ParseOrgNumber(value string) string {
+	value = strings.TrimSpace(value)
+	if number, err := strconv.ParseFloat(value, 64); err == nil && number == math.Trunc(number) && number > 0 {
+		return strconv.FormatInt(int64(number), 10)
+	}
+	return value
+}
+
+// ParsePostalCode returns a four-digit postal code, restoring leading zeros lost in numeric cells
+func ParsePostalCode(value string) string {
+	value = ParseOrgNumber(value)
+	if len(value) > 0 && len(value) < 4 {
+		if _, err := strconv.Atoi(value); err == nil {
+			return strings.Repeat("0", 4-len(value)) + value
+		}
+	}
+	return value
+}
+
+// NormalizeHeader lower-cases a column header and strips punctuation and whitespace,
+// so "Org.nr." and "org nr" compare equal
+func NormalizeHeader(header string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(header) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
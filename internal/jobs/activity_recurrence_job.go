@@ -0,0 +1,66 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ActivityRecurrenceJobName is the name of the recurring activity expansion job
+const ActivityRecurrenceJobName = "activity_recurrence_expansion"
+
+// ActivityRecurrenceService defines the interface for generating occurrences of recurring activities.
+type ActivityRecurrenceService interface {
+	// ExpandRecurringActivities generates occurrences of all recurring series up to the rolling horizon.
+	// Returns the number of series expanded and occurrences created.
+	ExpandRecurringActivities(ctx context.Context) (series int, created int, err error)
+}
+
+// ActivityRecurrenceJob moves the rolling horizon of recurring activities forward, so upcoming
+// occurrences exist as activities for task lists, reminders and calendar feeds.
+type ActivityRecurrenceJob struct {
+	service ActivityRecurrenceService
+	logger  *zap.Logger
+	timeout time.Duration
+}
+
+// NewActivityRecurrenceJob creates a new recurring activity expansion job.
+func NewActivityRecurrenceJob(service ActivityRecurrenceService, logger *zap.Logger, timeout time.Duration) *ActivityRecurrenceJob {
+	return &ActivityRecurrenceJob{
+		service: service,
+		logger:  logger,
+		timeout: timeout,
+	}
+}
+
+// Run executes the recurring activity expansion.
+// This is called by the scheduler according to the cron expression.
+func (j *ActivityRecurrenceJob) Run() {
+	ctx, cancel := context.WithTimeout(context.Background(), j.timeout)
+	defer cancel()
+
+	start := time.Now()
+	j.logger.Info("starting activity recurrence expansion job")
+
+	series, created, err := j.service.ExpandRecurringActivities(ctx)
+	if err != nil {
+		j.logger.Error("activity recurrence expansion failed",
+			zap.Error(err),
+			zap.Int("series_expanded", series),
+			zap.Int("occurrences_created", created),
+			zap.Duration("duration", time.Since(start)))
+		return
+	}
+
+	j.logger.Info("activity recurrence expansion job completed",
+		zap.Int("series_expanded", series),
+		zap.Int("occurrences_created", created),
+		zap.Duration("duration", time.Since(start)))
+}
+
+// RegisterActivityRecurrenceJob registers the recurring activity expansion job with the scheduler.
+func RegisterActivityRecurrenceJob(scheduler *Scheduler, service ActivityRecurrenceService, logger *zap.Logger, cronExpr string, timeout time.Duration) error {
+	job := NewActivityRecurrenceJob(service, logger, timeout)
+	return scheduler.AddJob(ActivityRecurrenceJobName, cronExpr, job.Run)
+}
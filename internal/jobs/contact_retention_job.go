@@ -0,0 +1,66 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ContactRetentionJobName is the name of the contact retention review job
+const ContactRetentionJobName = "contact_retention_review"
+
+// ContactRetentionService defines the interface for flagging inactive contacts for retention review.
+type ContactRetentionService interface {
+	// FlagInactiveContacts flags contacts without changes or activities for the given number of years.
+	// Returns the number of contacts flagged in this run and the number awaiting review.
+	FlagInactiveContacts(ctx context.Context, inactiveYears int) (flagged int, pending int, err error)
+}
+
+// ContactRetentionJob flags contacts that have been inactive for the retention period,
+// so their personal data can be reviewed and anonymized if no longer needed.
+type ContactRetentionJob struct {
+	service       ContactRetentionService
+	logger        *zap.Logger
+	timeout       time.Duration
+	inactiveYears int
+}
+
+// NewContactRetentionJob creates a new contact retention review job.
+func NewContactRetentionJob(service ContactRetentionService, logger *zap.Logger, timeout time.Duration, inactiveYears int) *ContactRetentionJob {
+	return &ContactRetentionJob{
+		service:       service,
+		logger:        logger,
+		timeout:       timeout,
+		inactiveYears: inactiveYears,
+	}
+}
+
+// Run executes the contact retention review.
+// This is called by the scheduler according to the cron expression.
+func (j *ContactRetentionJob) Run() {
+	ctx, cancel := context.WithTimeout(context.Background(), j.timeout)
+	defer cancel()
+
+	start := time.Now()
+	j.logger.Info("starting contact retention review job", zap.Int("inactive_years", j.inactiveYears))
+
+	flagged, pending, err := j.service.FlagInactiveContacts(ctx, j.inactiveYears)
+	if err != nil {
+		j.logger.Error("contact retention review failed",
+			zap.Error(err),
+			zap.Duration("duration", time.Since(start)))
+		return
+	}
+
+	j.logger.Info("contact retention review job completed",
+		zap.Int("contacts_flagged", flagged),
+		zap.Int("contacts_pending_review", pending),
+		zap.Duration("duration", time.Since(start)))
+}
+
+// RegisterContactRetentionJob registers the contact retention review job with the scheduler.
+func RegisterContactRetentionJob(scheduler *Scheduler, service ContactRetentionService, logger *zap.Logger, cronExpr string, timeout time.Duration, inactiveYears int) error {
+	job := NewContactRetentionJob(service, logger, timeout, inactiveYears)
+	return scheduler.AddJob(ContactRetentionJobName, cronExpr, job.Run)
+}
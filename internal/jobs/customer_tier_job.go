@@ -0,0 +1,64 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// CustomerTierJobName is the name of the customer tier recalculation job
+const CustomerTierJobName = "customer_tier_recalculation"
+
+// CustomerTierService defines the interface for recalculating customer tiers.
+type CustomerTierService interface {
+	// RunScheduledRecalculation evaluates all customers against their company's tier rule.
+	// Returns the number of customers checked, changed and skipped because their tier is locked.
+	RunScheduledRecalculation(ctx context.Context) (checked int, changed int, skipped int, err error)
+}
+
+// CustomerTierJob recalculates customer tiers so tiers in list views stay current.
+type CustomerTierJob struct {
+	service CustomerTierService
+	logger  *zap.Logger
+	timeout time.Duration
+}
+
+// NewCustomerTierJob creates a new customer tier recalculation job.
+func NewCustomerTierJob(service CustomerTierService, logger *zap.Logger, timeout time.Duration) *CustomerTierJob {
+	return &CustomerTierJob{
+		service: service,
+		logger:  logger,
+		timeout: timeout,
+	}
+}
+
+// Run executes the customer tier recalculation.
+// This is called by the scheduler according to the cron expression.
+func (j *CustomerTierJob) Run() {
+	ctx, cancel := context.WithTimeout(context.Background(), j.timeout)
+	defer cancel()
+
+	start := time.Now()
+	j.logger.Info("starting customer tier recalculation job")
+
+	checked, changed, skipped, err := j.service.RunScheduledRecalculation(ctx)
+	if err != nil {
+		j.logger.Error("customer tier recalculation failed",
+			zap.Error(err),
+			zap.Duration("duration", time.Since(start)))
+		return
+	}
+
+	j.logger.Info("customer tier recalculation job completed",
+		zap.Int("customers_checked", checked),
+		zap.Int("tiers_changed", changed),
+		zap.Int("locked_skipped", skipped),
+		zap.Duration("duration", time.Since(start)))
+}
+
+// RegisterCustomerTierJob registers the customer tier recalculation job with the scheduler.
+func RegisterCustomerTierJob(scheduler *Scheduler, service CustomerTierService, logger *zap.Logger, cronExpr string, timeout time.Duration) error {
+	job := NewCustomerTierJob(service, logger, timeout)
+	return scheduler.AddJob(CustomerTierJobName, cronExpr, job.Run)
+}
@@ -0,0 +1,69 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// EmailIngestionJobName is the name of the mailbox email ingestion job
+const EmailIngestionJobName = "email_mailbox_ingestion"
+
+// EmailIngestionService defines the interface for ingesting emails from a drop mailbox.
+type EmailIngestionService interface {
+	// IngestMaildir ingests the new emails in a maildir folder.
+	// Returns the number of emails ingested and how many of them matched a customer, offer or supplier.
+	IngestMaildir(ctx context.Context, dir string) (ingested int, matched int, err error)
+}
+
+// EmailIngestionJob files emails dropped in the mailbox folder as email activities.
+type EmailIngestionJob struct {
+	service EmailIngestionService
+	logger  *zap.Logger
+	timeout time.Duration
+	dir     string
+}
+
+// NewEmailIngestionJob creates a new mailbox email ingestion job.
+func NewEmailIngestionJob(service EmailIngestionService, logger *zap.Logger, timeout time.Duration, dir string) *EmailIngestionJob {
+	return &EmailIngestionJob{
+		service: service,
+		logger:  logger,
+		timeout: timeout,
+		dir:     dir,
+	}
+}
+
+// Run executes the mailbox email ingestion.
+// This is called by the scheduler according to the cron expression.
+func (j *EmailIngestionJob) Run() {
+	ctx, cancel := context.WithTimeout(context.Background(), j.timeout)
+	defer cancel()
+
+	start := time.Now()
+	j.logger.Debug("starting email mailbox ingestion job", zap.String("dir", j.dir))
+
+	ingested, matched, err := j.service.IngestMaildir(ctx, j.dir)
+	if err != nil {
+		j.logger.Error("email mailbox ingestion failed",
+			zap.Error(err),
+			zap.Int("emails_ingested", ingested),
+			zap.Int("emails_matched", matched),
+			zap.Duration("duration", time.Since(start)))
+		return
+	}
+
+	if ingested > 0 {
+		j.logger.Info("email mailbox ingestion job completed",
+			zap.Int("emails_ingested", ingested),
+			zap.Int("emails_matched", matched),
+			zap.Duration("duration", time.Since(start)))
+	}
+}
+
+// RegisterEmailIngestionJob registers the mailbox email ingestion job with the scheduler.
+func RegisterEmailIngestionJob(scheduler *Scheduler, service EmailIngestionService, logger *zap.Logger, cronExpr string, timeout time.Duration, dir string) error {
+	job := NewEmailIngestionJob(service, logger, timeout, dir)
+	return scheduler.AddJob(EmailIngestionJobName, cronExpr, job.Run)
+}
@@ -0,0 +1,64 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ERPReconciliationJobName is the name of the ERP customer reconciliation job
+const ERPReconciliationJobName = "erp_customer_reconciliation"
+
+// ERPReconciliationService defines the interface for reconciling ERP customers with CRM customers.
+type ERPReconciliationService interface {
+	// RunScheduledReconciliation compares ERP customers with CRM customers and applies the field policies.
+	// Returns the number of customers created, fields updated from the ERP and decisions queued for approval.
+	RunScheduledReconciliation(ctx context.Context) (created int, updated int, queued int, err error)
+}
+
+// ERPReconciliationJob creates missing customers and resolves field drift between the ERP and the CRM.
+type ERPReconciliationJob struct {
+	service ERPReconciliationService
+	logger  *zap.Logger
+	timeout time.Duration
+}
+
+// NewERPReconciliationJob creates a new ERP customer reconciliation job.
+func NewERPReconciliationJob(service ERPReconciliationService, logger *zap.Logger, timeout time.Duration) *ERPReconciliationJob {
+	return &ERPReconciliationJob{
+		service: service,
+		logger:  logger,
+		timeout: timeout,
+	}
+}
+
+// Run executes the ERP customer reconciliation.
+// This is called by the scheduler according to the cron expression.
+func (j *ERPReconciliationJob) Run() {
+	ctx, cancel := context.WithTimeout(context.Background(), j.timeout)
+	defer cancel()
+
+	start := time.Now()
+	j.logger.Info("starting ERP customer reconciliation job")
+
+	created, updated, queued, err := j.service.RunScheduledReconciliation(ctx)
+	if err != nil {
+		j.logger.Error("ERP customer reconciliation failed",
+			zap.Error(err),
+			zap.Duration("duration", time.Since(start)))
+		return
+	}
+
+	j.logger.Info("ERP customer reconciliation job completed",
+		zap.Int("customers_created", created),
+		zap.Int("fields_updated", updated),
+		zap.Int("decisions_queued", queued),
+		zap.Duration("duration", time.Since(start)))
+}
+
+// RegisterERPReconciliationJob registers the ERP customer reconciliation job with the scheduler.
+func RegisterERPReconciliationJob(scheduler *Scheduler, service ERPReconciliationService, logger *zap.Logger, cronExpr string, timeout time.Duration) error {
+	job := NewERPReconciliationJob(service, logger, timeout)
+	return scheduler.AddJob(ERPReconciliationJobName, cronExpr, job.Run)
+}
@@ -0,0 +1,63 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// InquirySLAJobName is the name of the inquiry SLA warning job
+const InquirySLAJobName = "inquiry_sla_warnings"
+
+// InquirySLAService defines the interface for warning about inquiries approaching their response deadline.
+type InquirySLAService interface {
+	// SendWarnings notifies responsible users of unanswered inquiries close to or past their deadline.
+	// Returns the number of unanswered inquiries checked and the number of warnings sent.
+	SendWarnings(ctx context.Context) (checked int, warned int, err error)
+}
+
+// InquirySLAJob warns responsible users before unanswered inquiries breach their company's response SLA.
+type InquirySLAJob struct {
+	service InquirySLAService
+	logger  *zap.Logger
+	timeout time.Duration
+}
+
+// NewInquirySLAJob creates a new inquiry SLA warning job.
+func NewInquirySLAJob(service InquirySLAService, logger *zap.Logger, timeout time.Duration) *InquirySLAJob {
+	return &InquirySLAJob{
+		service: service,
+		logger:  logger,
+		timeout: timeout,
+	}
+}
+
+// Run executes the inquiry SLA warnings.
+// This is called by the scheduler according to the cron expression.
+func (j *InquirySLAJob) Run() {
+	ctx, cancel := context.WithTimeout(context.Background(), j.timeout)
+	defer cancel()
+
+	start := time.Now()
+	j.logger.Debug("starting inquiry SLA warning job")
+
+	checked, warned, err := j.service.SendWarnings(ctx)
+	if err != nil {
+		j.logger.Error("inquiry SLA warnings failed",
+			zap.Error(err),
+			zap.Duration("duration", time.Since(start)))
+		return
+	}
+
+	j.logger.Info("inquiry SLA warning job completed",
+		zap.Int("unanswered_inquiries", checked),
+		zap.Int("warnings_sent", warned),
+		zap.Duration("duration", time.Since(start)))
+}
+
+// RegisterInquirySLAJob registers the inquiry SLA warning job with the scheduler.
+func RegisterInquirySLAJob(scheduler *Scheduler, service InquirySLAService, logger *zap.Logger, cronExpr string, timeout time.Duration) error {
+	job := NewInquirySLAJob(service, logger, timeout)
+	return scheduler.AddJob(InquirySLAJobName, cronExpr, job.Run)
+}
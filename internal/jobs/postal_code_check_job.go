@@ -0,0 +1,63 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// PostalCodeCheckJobName is the name of the postal code data quality job
+const PostalCodeCheckJobName = "postal_code_check"
+
+// PostalCodeCheckService defines the interface for checking postal codes against the postal code register.
+type PostalCodeCheckService interface {
+	// RunPostalCodeCheck checks customers and suppliers whose city does not match their postal code.
+	// Returns the number of records checked and the number of mismatches found.
+	RunPostalCodeCheck(ctx context.Context) (checked int, mismatches int, err error)
+}
+
+// PostalCodeCheckJob reports customers and suppliers whose city does not match their postal code.
+type PostalCodeCheckJob struct {
+	service PostalCodeCheckService
+	logger  *zap.Logger
+	timeout time.Duration
+}
+
+// NewPostalCodeCheckJob creates a new postal code data quality job.
+func NewPostalCodeCheckJob(service PostalCodeCheckService, logger *zap.Logger, timeout time.Duration) *PostalCodeCheckJob {
+	return &PostalCodeCheckJob{
+		service: service,
+		logger:  logger,
+		timeout: timeout,
+	}
+}
+
+// Run executes the postal code check.
+// This is called by the scheduler according to the cron expression.
+func (j *PostalCodeCheckJob) Run() {
+	ctx, cancel := context.WithTimeout(context.Background(), j.timeout)
+	defer cancel()
+
+	start := time.Now()
+	j.logger.Info("starting postal code check job")
+
+	checked, mismatches, err := j.service.RunPostalCodeCheck(ctx)
+	if err != nil {
+		j.logger.Error("postal code check failed",
+			zap.Error(err),
+			zap.Duration("duration", time.Since(start)))
+		return
+	}
+
+	j.logger.Info("postal code check job completed",
+		zap.Int("checked", checked),
+		zap.Int("mismatches", mismatches),
+		zap.Duration("duration", time.Since(start)))
+}
+
+// RegisterPostalCodeCheckJob registers the postal code data quality job with the scheduler.
+func RegisterPostalCodeCheckJob(scheduler *Scheduler, service PostalCodeCheckService, logger *zap.Logger, cronExpr string, timeout time.Duration) error {
+	job := NewPostalCodeCheckJob(service, logger, timeout)
+	return scheduler.AddJob(PostalCodeCheckJobName, cronExpr, job.Run)
+}
@@ -0,0 +1,65 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// StaleDigestJobName is the name of the stale deal and offer digest job
+const StaleDigestJobName = "stale_digest"
+
+// StaleDigestService defines the interface for sending the stale deal and offer digest.
+type StaleDigestService interface {
+	// SendDigest notifies owners and managers of stale deals and offers.
+	// Returns the number of stale deals and offers found and the number of digests sent.
+	SendDigest(ctx context.Context) (staleDeals int, staleOffers int, sent int, err error)
+}
+
+// StaleDigestJob sends owners and managers a digest of deals and offers that have gone
+// without stage changes or activity for longer than their stage's threshold.
+type StaleDigestJob struct {
+	service StaleDigestService
+	logger  *zap.Logger
+	timeout time.Duration
+}
+
+// NewStaleDigestJob creates a new stale digest job.
+func NewStaleDigestJob(service StaleDigestService, logger *zap.Logger, timeout time.Duration) *StaleDigestJob {
+	return &StaleDigestJob{
+		service: service,
+		logger:  logger,
+		timeout: timeout,
+	}
+}
+
+// Run executes the stale digest.
+// This is called by the scheduler according to the cron expression.
+func (j *StaleDigestJob) Run() {
+	ctx, cancel := context.WithTimeout(context.Background(), j.timeout)
+	defer cancel()
+
+	start := time.Now()
+	j.logger.Info("starting stale digest job")
+
+	staleDeals, staleOffers, sent, err := j.service.SendDigest(ctx)
+	if err != nil {
+		j.logger.Error("stale digest failed",
+			zap.Error(err),
+			zap.Duration("duration", time.Since(start)))
+		return
+	}
+
+	j.logger.Info("stale digest job completed",
+		zap.Int("stale_deals", staleDeals),
+		zap.Int("stale_offers", staleOffers),
+		zap.Int("digests_sent", sent),
+		zap.Duration("duration", time.Since(start)))
+}
+
+// RegisterStaleDigestJob registers the stale digest job with the scheduler.
+func RegisterStaleDigestJob(scheduler *Scheduler, service StaleDigestService, logger *zap.Logger, cronExpr string, timeout time.Duration) error {
+	job := NewStaleDigestJob(service, logger, timeout)
+	return scheduler.AddJob(StaleDigestJobName, cronExpr, job.Run)
+}
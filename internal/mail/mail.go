@@ -0,0 +1,195 @@
+// Package mail sends plain-text emails, such as confirmations to people who submit inquiries
+// through the company websites, over SMTP.
+package mail
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidAddress is returned when a sender or recipient is not a valid email address
+var ErrInvalidAddress = errors.New("invalid email address")
+
+// Message is a plain-text email
+type Message struct {
+	To      string
+	ToName  string
+	Subject string
+	Body    string
+	// ReplyTo is where replies go, e.g. the responsible salesperson; empty leaves replies with the sender
+	ReplyTo string
+}
+
+// Sender sends emails
+type Sender interface {
+	// Enabled reports whether messages are actually delivered
+	Enabled() bool
+	Send(ctx context.Context, msg *Message) error
+}
+
+// Config configures SMTP delivery. Delivery is disabled when Host is empty.
+type Config struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+	FromName string
+	Timeout  time.Duration
+}
+
+// NewSender returns an SMTP sender, or a sender that drops every message when no host is configured
+func NewSender(cfg Config) Sender {
+	if cfg.Host == "" {
+		return disabledSender{}
+	}
+	if cfg.Port == 0 {
+		cfg.Port = 587
+	}
+	if cfg.Timeout == 0 {
+		cfg.Timeout = 30 * time.Second
+	}
+	return &smtpSender{cfg: cfg}
+}
+
+type disabledSender struct{}
+
+func (disabledSender) Enabled() bool { return false }
+
+func (disabledSender) Send(context.Context, *Message) error { return nil }
+
+type smtpSender struct {
+	cfg Config
+}
+
+func (s *smtpSender) Enabled() bool { return true }
+
+// Send delivers a message, using STARTTLS when the server offers it
+func (s *smtpSender) Send(ctx context.Context, msg *Message) error {
+	from, err := mail.ParseAddress(s.cfg.From)
+	if err != nil {
+		return fmt.Errorf("%w: from %q", ErrInvalidAddress, s.cfg.From)
+	}
+	to, err := mail.ParseAddress(msg.To)
+	if err != nil {
+		return fmt.Errorf("%w: to %q", ErrInvalidAddress, msg.To)
+	}
+	data, err := Compose(&mail.Address{Name: s.cfg.FromName, Address: from.Address}, msg, time.Now())
+	if err != nil {
+		return err
+	}
+
+	deadline := time.Now().Add(s.cfg.Timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	addr := net.JoinHostPort(s.cfg.Host, strconv.Itoa(s.cfg.Port))
+	dialer := &net.Dialer{Deadline: deadline}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to connect to mail server: %w", err)
+	}
+	_ = conn.SetDeadline(deadline)
+
+	client, err := smtp.NewClient(conn, s.cfg.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to start SMTP session: %w", err)
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: s.cfg.Host, MinVersion: tls.VersionTLS12}); err != nil {
+			return fmt.Errorf("failed to start TLS: %w", err)
+		}
+	}
+	if s.cfg.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", s.cfg.Username, s.cfg.Password, s.cfg.Host)); err != nil {
+			return fmt.Errorf("failed to authenticate with mail server: %w", err)
+		}
+	}
+	if err := client.Mail(from.Address); err != nil {
+		return fmt.Errorf("mail server rejected sender: %w", err)
+	}
+	if err := client.Rcpt(to.Address); err != nil {
+		return fmt.Errorf("mail server rejected recipient: %w", err)
+	}
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("failed to send message: %w", err)
+	}
+	if _, err := w.Write(data); err != nil {
+		return fmt.Errorf("failed to send message: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("failed to send message: %w", err)
+	}
+	return client.Quit()
+}
+
+// Compose formats a message as RFC 5322 with a quoted-printable UTF-8 body
+func Compose(from *mail.Address, msg *Message, date time.Time) ([]byte, error) {
+	to, err := mail.ParseAddress(msg.To)
+	if err != nil {
+		return nil, fmt.Errorf("%w: to %q", ErrInvalidAddress, msg.To)
+	}
+	to.Name = msg.ToName
+
+	var buf bytes.Buffer
+	writeHeader(&buf, "From", from.String())
+	writeHeader(&buf, "To", to.String())
+	if msg.ReplyTo != "" {
+		replyTo, err := mail.ParseAddress(msg.ReplyTo)
+		if err != nil {
+			return nil, fmt.Errorf("%w: reply-to %q", ErrInvalidAddress, msg.ReplyTo)
+		}
+		writeHeader(&buf, "Reply-To", replyTo.String())
+	}
+	writeHeader(&buf, "Subject", mime.QEncoding.Encode("utf-8", msg.Subject))
+	writeHeader(&buf, "Date", date.Format(time.RFC1123Z))
+	writeHeader(&buf, "Message-ID", messageID(from.Address))
+	writeHeader(&buf, "MIME-Version", "1.0")
+	writeHeader(&buf, "Content-Type", `text/plain; charset="utf-8"`)
+	writeHeader(&buf, "Content-Transfer-Encoding", "quoted-printable")
+	buf.WriteString("\r\n")
+
+	qp := quotedprintable.NewWriter(&buf)
+	body := strings.ReplaceAll(strings.ReplaceAll(msg.Body, "\r\n", "\n"), "\n", "\r\n")
+	if _, err := qp.Write([]byte(body)); err != nil {
+		return nil, err
+	}
+	if err := qp.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writeHeader writes a header line, dropping line breaks so values cannot inject headers
+func writeHeader(buf *bytes.Buffer, name, value string) {
+	value = strings.NewReplacer("\r", "", "\n", "").Replace(value)
+	buf.WriteString(name + ": " + value + "\r\n")
+}
+
+// messageID returns a unique Message-ID in the sender's domain
+func messageID(from string) string {
+	host := "localhost"
+	if at := strings.LastIndex(from, "@"); at >= 0 {
+		host = from[at+1:]
+	}
+	random := make([]byte, 12)
+	_, _ = rand.Read(random)
+	return "<" + hex.EncodeToString(random) + "@" + host + ">"
+}
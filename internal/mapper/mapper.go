@@ -10,6 +10,22 @@ import (
 
 // ToCustomerDTO converts Customer to CustomerDTO
 func ToCustomerDTO(customer *domain.Customer, totalValueActive float64, totalValueWon float64, activeOffers int) domain.CustomerDTO {
+	var registryCheckedAt *string
+	if customer.RegistryCheckedAt != nil {
+		checkedAt := customer.RegistryCheckedAt.UTC().Format(time.RFC3339)
+		registryCheckedAt = &checkedAt
+	}
+	var tierLockedAt *string
+	if customer.TierLockedAt != nil {
+		lockedAt := customer.TierLockedAt.UTC().Format(time.RFC3339)
+		tierLockedAt = &lockedAt
+	}
+	var tierCalculatedAt *string
+	if customer.TierCalculatedAt != nil {
+		calculatedAt := customer.TierCalculatedAt.UTC().Format(time.RFC3339)
+		tierCalculatedAt = &calculatedAt
+	}
+
 	return domain.CustomerDTO{
 		ID:               customer.ID,
 		Name:             customer.Name,
@@ -42,6 +58,17 @@ func ToCustomerDTO(customer *domain.Customer, totalValueActive float64, totalVal
 		CreatedByName:    customer.CreatedByName,
 		UpdatedByID:      customer.UpdatedByID,
 		UpdatedByName:    customer.UpdatedByName,
+		// Enhetsregisteret fields
+		IndustryCode:      customer.IndustryCode,
+		RegistryStatus:    customer.RegistryStatus,
+		RegistryFlagged:   customer.RegistryStatus.IsFlagged(),
+		RegistryCheckedAt: registryCheckedAt,
+		// Tier override fields
+		TierLocked:       customer.TierLocked,
+		TierLockedAt:     tierLockedAt,
+		TierLockedByName: customer.TierLockedByName,
+		TierCalculatedAt: tierCalculatedAt,
+		ParentCustomerID: customer.ParentCustomerID,
 	}
 }
 
@@ -67,6 +94,10 @@ func ToContactDTO(contact *domain.Contact) domain.ContactDTO {
 		PreferredContactMethod: contact.PreferredContactMethod,
 		Notes:                  contact.Notes,
 		IsActive:               contact.IsActive,
+		AnonymizedAt:           formatTimePointer(contact.AnonymizedAt),
+		RetentionFlaggedAt:     formatTimePointer(contact.RetentionFlaggedAt),
+		RetentionReviewedAt:    formatTimePointer(contact.RetentionReviewedAt),
+		MergedIntoID:           contact.MergedIntoID,
 		CreatedAt:              contact.CreatedAt.UTC().Format(time.RFC3339),
 		UpdatedAt:              contact.UpdatedAt.UTC().Format(time.RFC3339),
 		CreatedByID:            contact.CreatedByID,
@@ -95,6 +126,8 @@ func ToContactRelationshipDTO(rel *domain.ContactRelationship) domain.ContactRel
 		EntityID:   rel.EntityID,
 		Role:       rel.Role,
 		IsPrimary:  rel.IsPrimary,
+		BuyingRole: rel.BuyingRole,
+		Sentiment:  rel.Sentiment,
 		CreatedAt:  rel.CreatedAt.UTC().Format(time.RFC3339),
 	}
 }
@@ -120,6 +153,8 @@ func ToDealDTO(deal *domain.Deal) domain.DealDTO {
 		LostReason:         deal.LostReason,
 		LossReasonCategory: deal.LossReasonCategory,
 		OfferID:            deal.OfferID,
+		PipelineID:         deal.PipelineID,
+		ProbabilityAtClose: deal.ProbabilityAtClose,
 		CreatedAt:          deal.CreatedAt.UTC().Format(time.RFC3339),
 		UpdatedAt:          deal.UpdatedAt.UTC().Format(time.RFC3339),
 	}
@@ -156,6 +191,43 @@ func ToDealStageHistoryDTO(history *domain.DealStageHistory) domain.DealStageHis
 	return dto
 }
 
+// ToDealPipelineDTO converts DealPipeline to DealPipelineDTO
+func ToDealPipelineDTO(pipeline *domain.DealPipeline) domain.DealPipelineDTO {
+	dto := domain.DealPipelineDTO{
+		ID:          pipeline.ID,
+		CompanyID:   pipeline.CompanyID,
+		Name:        pipeline.Name,
+		Description: pipeline.Description,
+		IsDefault:   pipeline.IsDefault,
+		Stages:      make([]domain.DealPipelineStageDTO, len(pipeline.Stages)),
+		CreatedAt:   pipeline.CreatedAt.UTC().Format(time.RFC3339),
+		UpdatedAt:   pipeline.UpdatedAt.UTC().Format(time.RFC3339),
+	}
+
+	for i, stage := range pipeline.Stages {
+		transitions := make([]domain.DealStage, len(stage.AllowedTransitions))
+		for j, key := range stage.AllowedTransitions {
+			transitions[j] = domain.DealStage(key)
+		}
+		requiredFields := []string(stage.RequiredFields)
+		if requiredFields == nil {
+			requiredFields = []string{}
+		}
+		dto.Stages[i] = domain.DealPipelineStageDTO{
+			Key:                stage.Key,
+			Name:               stage.Name,
+			SortOrder:          stage.SortOrder,
+			Kind:               stage.Kind,
+			Probability:        stage.Probability,
+			RequiredFields:     requiredFields,
+			AllowedTransitions: transitions,
+			StaleAfterDays:     stage.StaleAfterDays,
+		}
+	}
+
+	return dto
+}
+
 // ToProjectDTO converts Project to ProjectDTO
 // Projects are now simplified containers for offers - economic tracking moved to Offer
 func ToProjectDTO(project *domain.Project) domain.ProjectDTO {
@@ -313,6 +385,9 @@ func ToOfferDTO(offer *domain.Offer) domain.OfferDTO {
 		StartDate:               startDate,
 		EndDate:                 endDate,
 		EstimatedCompletionDate: estimatedCompletionDate,
+		// Inquiry response SLA
+		InquirySLADueAt:    formatTimePointer(offer.InquirySLADueAt),
+		InquiryRespondedAt: formatTimePointer(offer.InquiryRespondedAt),
 		// User tracking fields
 		CreatedByID:   offer.CreatedByID,
 		CreatedByName: offer.CreatedByName,
@@ -378,7 +453,7 @@ func ToOfferItemDTO(item *domain.OfferItem) domain.OfferItemDTO {
 
 // ToBudgetItemDTO converts BudgetItem to BudgetItemDTO
 func ToBudgetItemDTO(item *domain.BudgetItem) domain.BudgetItemDTO {
-	return domain.BudgetItemDTO{
+	dto := domain.BudgetItemDTO{
 		ID:              item.ID,
 		ParentType:      item.ParentType,
 		ParentID:        item.ParentID,
@@ -391,9 +466,14 @@ func ToBudgetItemDTO(item *domain.BudgetItem) domain.BudgetItemDTO {
 		PricePerItem:    item.PricePerItem,
 		Description:     item.Description,
 		DisplayOrder:    item.DisplayOrder,
+		SourceItemID:    item.SourceItemID,
 		CreatedAt:       item.CreatedAt.UTC().Format(time.RFC3339),
 		UpdatedAt:       item.UpdatedAt.UTC().Format(time.RFC3339),
 	}
+	if item.CostCategory != nil {
+		dto.CostCategory = *item.CostCategory
+	}
+	return dto
 }
 
 // ToBudgetSummaryDTO creates a summary DTO from budget items
@@ -596,6 +676,10 @@ func ToActivityDTO(activity *domain.Activity) domain.ActivityDTO {
 		AssignedToID:     activity.AssignedToID,
 		CompanyID:        activity.CompanyID,
 		ParentActivityID: activity.ParentActivityID,
+
+		RecurrenceRule:        activity.RecurrenceRule,
+		SeriesID:              activity.SeriesID,
+		IsRecurrenceException: activity.IsRecurrenceException,
 	}
 
 	// Convert pq.StringArray to []string for attendees
@@ -615,6 +699,10 @@ func ToActivityDTO(activity *domain.Activity) domain.ActivityDTO {
 		dto.CompletedAt = activity.CompletedAt.UTC().Format(time.RFC3339)
 	}
 
+	if activity.OccurrenceAt != nil {
+		dto.OccurrenceAt = activity.OccurrenceAt.UTC().Format(time.RFC3339)
+	}
+
 	return dto
 }
 
@@ -756,6 +844,11 @@ func ToCompanyDetailDTO(company *domain.Company) domain.CompanyDetailDTO {
 		IsActive:                    company.IsActive,
 		DefaultOfferResponsibleID:   company.DefaultOfferResponsibleID,
 		DefaultProjectResponsibleID: company.DefaultProjectResponsibleID,
+		CreditLimitEnforcement:      company.CreditLimitEnforcement,
+		InquirySLAHours:             company.InquirySLAHours,
+		InquirySLAWarningHours:      company.InquirySLAWarningHours,
+		BusinessDayStart:            company.BusinessDayStart,
+		BusinessDayEnd:              company.BusinessDayEnd,
 		CreatedAt:                   company.CreatedAt.UTC().Format(time.RFC3339),
 		UpdatedAt:                   company.UpdatedAt.UTC().Format(time.RFC3339),
 	}
@@ -974,3 +1067,207 @@ func AssignmentsToDTOs(assignments []domain.Assignment) []domain.AssignmentDTO {
 	}
 	return dtos
 }
+
+// ToCalendarFeedDTO converts the active calendar feed token of a user to a DTO; nil means no active feed
+func ToCalendarFeedDTO(token *domain.CalendarFeedToken) domain.CalendarFeedDTO {
+	if token == nil {
+		return domain.CalendarFeedDTO{}
+	}
+	return domain.CalendarFeedDTO{
+		Active:         true,
+		CreatedAt:      formatTimePointer(&token.CreatedAt),
+		LastAccessedAt: formatTimePointer(token.LastAccessedAt),
+	}
+}
+
+// ToIngestedEmailDTO converts an IngestedEmail to IngestedEmailDTO
+func ToIngestedEmailDTO(email *domain.IngestedEmail) domain.IngestedEmailDTO {
+	dto := domain.IngestedEmailDTO{
+		ID:              email.ID,
+		MessageID:       email.MessageID,
+		Subject:         email.Subject,
+		FromAddress:     email.FromAddress,
+		Source:          email.Source,
+		Status:          email.Status,
+		MatchedBy:       email.MatchedBy,
+		TargetType:      email.TargetType,
+		TargetID:        email.TargetID,
+		TargetName:      email.TargetName,
+		ActivityID:      email.ActivityID,
+		AttachmentCount: email.AttachmentCount,
+		IngestedByName:  email.IngestedByName,
+		CreatedAt:       email.CreatedAt.UTC().Format(time.RFC3339),
+	}
+	if email.SentAt != nil {
+		sentAt := email.SentAt.UTC().Format(time.RFC3339)
+		dto.SentAt = &sentAt
+	}
+	return dto
+}
+
+// ToSalesTargetDTO converts a SalesTarget to SalesTargetDTO
+func ToSalesTargetDTO(target *domain.SalesTarget) domain.SalesTargetDTO {
+	return domain.SalesTargetDTO{
+		ID:            target.ID,
+		CompanyID:     target.CompanyID,
+		UserID:        target.UserID,
+		UserName:      target.UserName,
+		PeriodType:    target.PeriodType,
+		PeriodStart:   target.PeriodStart.Format("2006-01-02"),
+		PeriodEnd:     target.PeriodEnd().AddDate(0, 0, -1).Format("2006-01-02"),
+		Metric:        target.Metric,
+		TargetValue:   target.TargetValue,
+		Notes:         target.Notes,
+		CreatedByName: target.CreatedByName,
+		CreatedAt:     target.CreatedAt.UTC().Format(time.RFC3339),
+		UpdatedAt:     target.UpdatedAt.UTC().Format(time.RFC3339),
+	}
+}
+
+// ToInquiryIntakeSiteDTO converts an InquiryIntakeSite entity to InquiryIntakeSiteDTO
+func ToInquiryIntakeSiteDTO(site *domain.InquiryIntakeSite) domain.InquiryIntakeSiteDTO {
+	origins := []string(site.AllowedOrigins)
+	if origins == nil {
+		origins = []string{}
+	}
+	return domain.InquiryIntakeSiteDTO{
+		ID:               site.ID,
+		CompanyID:        site.CompanyID,
+		Name:             site.Name,
+		SiteKey:          site.SiteKey,
+		AllowedOrigins:   origins,
+		HoneypotField:    site.HoneypotField,
+		ProofOfWorkBits:  site.ProofOfWorkBits,
+		SendConfirmation: site.SendConfirmation,
+		Enabled:          site.Enabled,
+		CreatedByName:    site.CreatedByName,
+		CreatedAt:        site.CreatedAt.UTC().Format(time.RFC3339),
+		UpdatedAt:        site.UpdatedAt.UTC().Format(time.RFC3339),
+	}
+}
+
+// ToInquirySubmissionDTO converts an InquirySubmission entity to InquirySubmissionDTO
+func ToInquirySubmissionDTO(submission *domain.InquirySubmission) domain.InquirySubmissionDTO {
+	dto := domain.InquirySubmissionDTO{
+		ID:              submission.ID,
+		SiteID:          submission.SiteID,
+		CompanyID:       submission.CompanyID,
+		OfferID:         submission.OfferID,
+		CustomerID:      submission.CustomerID,
+		MatchConfidence: submission.MatchConfidence,
+		Name:            submission.Name,
+		Email:           submission.Email,
+		Phone:           submission.Phone,
+		CompanyName:     submission.CompanyName,
+		OrgNumber:       submission.OrgNumber,
+		Message:         submission.Message,
+		AttachmentCount: submission.AttachmentCount,
+		Spam:            submission.Spam,
+		IPAddress:       submission.IPAddress,
+		CreatedAt:       submission.CreatedAt.UTC().Format(time.RFC3339),
+	}
+	if submission.ConfirmationSentAt != nil {
+		sentAt := submission.ConfirmationSentAt.UTC().Format(time.RFC3339)
+		dto.ConfirmationSentAt = &sentAt
+	}
+	return dto
+}
+
+// ToInquiryAssignmentRuleDTO converts an InquiryAssignmentRule entity to InquiryAssignmentRuleDTO
+func ToInquiryAssignmentRuleDTO(rule *domain.InquiryAssignmentRule) domain.InquiryAssignmentRuleDTO {
+	return domain.InquiryAssignmentRuleDTO{
+		ID:                 rule.ID,
+		CompanyID:          rule.CompanyID,
+		Name:               rule.Name,
+		RuleType:           rule.RuleType,
+		Priority:           rule.Priority,
+		Enabled:            rule.Enabled,
+		Industries:         nonNilStrings(rule.Industries),
+		PostalCodePrefixes: nonNilStrings(rule.PostalCodePrefixes),
+		Counties:           nonNilStrings(rule.Counties),
+		MinValue:           rule.MinValue,
+		MaxValue:           rule.MaxValue,
+		AssigneeIDs:        nonNilStrings(rule.AssigneeIDs),
+		CreatedByName:      rule.CreatedByName,
+		CreatedAt:          rule.CreatedAt.UTC().Format(time.RFC3339),
+		UpdatedAt:          rule.UpdatedAt.UTC().Format(time.RFC3339),
+	}
+}
+
+// nonNilStrings returns the strings, or an empty slice so JSON shows [] instead of null
+func nonNilStrings(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
+
+// ToProjectTaskDTO converts a ProjectTask and the tasks it depends on to ProjectTaskDTO
+func ToProjectTaskDTO(task *domain.ProjectTask, dependsOn []uuid.UUID) domain.ProjectTaskDTO {
+	if dependsOn == nil {
+		dependsOn = []uuid.UUID{}
+	}
+	return domain.ProjectTaskDTO{
+		ID:              task.ID,
+		ProjectID:       task.ProjectID,
+		Kind:            task.Kind,
+		Name:            task.Name,
+		Description:     task.Description,
+		MilestoneID:     task.MilestoneID,
+		OfferID:         task.OfferID,
+		StartDate:       task.StartDate.Format("2006-01-02"),
+		EndDate:         task.EndDate.Format("2006-01-02"),
+		AssigneeID:      task.AssigneeID,
+		AssigneeName:    task.AssigneeName,
+		PercentComplete: task.PercentComplete,
+		SortOrder:       task.SortOrder,
+		DependsOn:       dependsOn,
+		CreatedByName:   task.CreatedByName,
+		CreatedAt:       task.CreatedAt.UTC().Format(time.RFC3339),
+		UpdatedAt:       task.UpdatedAt.UTC().Format(time.RFC3339),
+	}
+}
+
+// ToResourceAllocationDTO converts a ResourceAllocation to ResourceAllocationDTO, with the offer title and
+// project name when they are loaded
+func ToResourceAllocationDTO(allocation *domain.ResourceAllocation) domain.ResourceAllocationDTO {
+	dto := domain.ResourceAllocationDTO{
+		ID:            allocation.ID,
+		CompanyID:     allocation.CompanyID,
+		UserID:        allocation.UserID,
+		UserName:      allocation.UserName,
+		OfferID:       allocation.OfferID,
+		ProjectID:     allocation.ProjectID,
+		StartDate:     allocation.StartDate.Format("2006-01-02"),
+		EndDate:       allocation.EndDate.Format("2006-01-02"),
+		HoursPerWeek:  allocation.HoursPerWeek,
+		Percent:       allocation.Percent,
+		Notes:         allocation.Notes,
+		CreatedByName: allocation.CreatedByName,
+		CreatedAt:     allocation.CreatedAt.UTC().Format(time.RFC3339),
+		UpdatedAt:     allocation.UpdatedAt.UTC().Format(time.RFC3339),
+	}
+	if allocation.Offer != nil {
+		dto.OfferTitle = allocation.Offer.Title
+	}
+	if allocation.Project != nil {
+		dto.ProjectName = allocation.Project.Name
+	}
+	return dto
+}
+
+// ToUserAbsenceDTO converts a UserAbsence to UserAbsenceDTO
+func ToUserAbsenceDTO(absence *domain.UserAbsence) domain.UserAbsenceDTO {
+	return domain.UserAbsenceDTO{
+		ID:            absence.ID,
+		UserID:        absence.UserID,
+		UserName:      absence.UserName,
+		Kind:          absence.Kind,
+		StartDate:     absence.StartDate.Format("2006-01-02"),
+		EndDate:       absence.EndDate.Format("2006-01-02"),
+		Percent:       absence.Percent,
+		Notes:         absence.Notes,
+		CreatedByName: absence.CreatedByName,
+		CreatedAt:     absence.CreatedAt.UTC().Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,30 @@
+package postal
+
+// countyByNumber maps the first two digits of a municipality number (kommunenummer)
+// to the county (fylke) name, using the county structure in effect from 2024.
+var countyByNumber = map[string]string{
+	"03": "Oslo",
+	"11": "Rogaland",
+	"15": "Møre og Romsdal",
+	"18": "Nordland",
+	"31": "Østfold",
+	"32": "Akershus",
+	"33": "Buskerud",
+	"34": "Innlandet",
+	"39": "Vestfold",
+	"40": "Telemark",
+	"42": "Agder",
+	"46": "Vestland",
+	"50": "Trøndelag",
+	"55": "Troms",
+	"56": "Finnmark",
+}
+
+// CountyForMunicipalityNumber returns the county name for a four-digit municipality number.
+// Returns an empty string if the county is unknown (e.g. Svalbard).
+func CountyForMunicipalityNumber(municipalityNumber string) string {
+	if len(municipalityNumber) < 2 {
+		return ""
+	}
+	return countyByNumber[municipalityNumber[:2]]
+}
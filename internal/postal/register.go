@@ -0,0 +1,217 @@
+// Package postal provides the Norwegian postal code register (postnummerregisteret) published
+// by Posten. The register is embedded in the binary so lookups work without network access.
+//
+// The bundled file is refreshed with `go run ./cmd/postalcodes -in <Postnummerregister-ansi.txt>`
+// using the full register downloaded from Posten/Bring.
+package postal
+
+import (
+	"bufio"
+	"bytes"
+	_ "embed"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+//go:embed data/postnummerregister.txt
+var bundledRegister []byte
+
+// Category is the Posten category for a postal code
+type Category string
+
+const (
+	// CategoryBoth is used for both street addresses and post boxes
+	CategoryBoth Category = "B"
+	// CategoryMultiple is used for postal codes with multiple usages (e.g. street addresses and service codes)
+	CategoryMultiple Category = "F"
+	// CategoryStreet is used for street addresses only
+	CategoryStreet Category = "G"
+	// CategoryPostBox is used for post boxes only
+	CategoryPostBox Category = "P"
+	// CategoryService is used for service postal codes (e.g. business reply mail)
+	CategoryService Category = "S"
+)
+
+// PostalCode is one entry in the postal code register
+type PostalCode struct {
+	Code               string
+	City               string
+	MunicipalityNumber string
+	Municipality       string
+	County             string
+	Category           Category
+}
+
+// Register is an in-memory postal code register keyed by four-digit postal code
+type Register struct {
+	codes map[string]PostalCode
+}
+
+var (
+	defaultRegister     *Register
+	defaultRegisterOnce sync.Once
+)
+
+// Default returns the register bundled with the binary.
+// The bundled file is parsed on first use; it is validated by tests so parse errors panic.
+func Default() *Register {
+	defaultRegisterOnce.Do(func() {
+		register, err := Parse(bytes.NewReader(bundledRegister))
+		if err != nil {
+			panic(fmt.Sprintf("postal: invalid bundled register: %v", err))
+		}
+		defaultRegister = register
+	})
+	return defaultRegister
+}
+
+// Parse reads a register in Posten's tab-separated format:
+// Postnummer, Poststed, Kommunenummer, Kommunenavn, Kategori.
+// Posten publishes the file in Windows-1252; lines that are not valid UTF-8 are decoded as Latin-1.
+// City and municipality names are stored in Posten's upper case and formatted on lookup.
+func Parse(r io.Reader) (*Register, error) {
+	register := &Register{codes: make(map[string]PostalCode)}
+
+	scanner := bufio.NewScanner(r)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := decodeLine(scanner.Bytes())
+		line = strings.TrimPrefix(line, "\ufeff")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		fields := strings.Split(strings.TrimRight(line, "\r"), "\t")
+		if len(fields) < 5 {
+			return nil, fmt.Errorf("line %d: expected 5 tab-separated fields, got %d", lineNumber, len(fields))
+		}
+
+		code := strings.TrimSpace(fields[0])
+		if !IsValidCode(code) {
+			return nil, fmt.Errorf("line %d: invalid postal code %q", lineNumber, code)
+		}
+
+		municipalityNumber := strings.TrimSpace(fields[2])
+		register.codes[code] = PostalCode{
+			Code:               code,
+			City:               strings.TrimSpace(fields[1]),
+			MunicipalityNumber: municipalityNumber,
+			Municipality:       strings.TrimSpace(fields[3]),
+			County:             CountyForMunicipalityNumber(municipalityNumber),
+			Category:           Category(strings.TrimSpace(fields[4])),
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read postal register: %w", err)
+	}
+	if len(register.codes) == 0 {
+		return nil, fmt.Errorf("postal register is empty")
+	}
+
+	return register, nil
+}
+
+// Lookup returns the postal code entry with city and municipality names formatted for display.
+// Returns false if the code is not in the register.
+func (r *Register) Lookup(code string) (PostalCode, bool) {
+	entry, ok := r.codes[strings.TrimSpace(code)]
+	if !ok {
+		return PostalCode{}, false
+	}
+	entry.City = FormatName(entry.City)
+	entry.Municipality = FormatName(entry.Municipality)
+	return entry, true
+}
+
+// Len returns the number of postal codes in the register
+func (r *Register) Len() int {
+	return len(r.codes)
+}
+
+// WriteTo writes the register in Posten's tab-separated format as UTF-8, sorted by postal code.
+// This is the format of the bundled data file.
+func (r *Register) WriteTo(w io.Writer) (int64, error) {
+	codes := make([]string, 0, len(r.codes))
+	for code := range r.codes {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+
+	var written int64
+	for _, code := range codes {
+		entry := r.codes[code]
+		n, err := fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+			entry.Code, entry.City, entry.MunicipalityNumber, entry.Municipality, entry.Category)
+		written += int64(n)
+		if err != nil {
+			return written, err
+		}
+	}
+	return written, nil
+}
+
+// IsValidCode returns true if the value is a four-digit Norwegian postal code
+func IsValidCode(code string) bool {
+	if len(code) != 4 {
+		return false
+	}
+	for _, c := range code {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// SameCity returns true if two city names refer to the same poststed, ignoring case and whitespace
+func SameCity(a, b string) bool {
+	return strings.EqualFold(strings.Join(strings.Fields(a), " "), strings.Join(strings.Fields(b), " "))
+}
+
+// FormatName converts an upper-case register name to display form.
+// "BØ I TELEMARK" becomes "Bø i Telemark", "SØR-VARANGER" becomes "Sør-Varanger" and
+// single-letter suffixes are kept, so "KRISTIANSAND S" becomes "Kristiansand S".
+func FormatName(name string) string {
+	words := strings.Fields(strings.ToLower(name))
+	for i, word := range words {
+		if i > 0 && (word == "i" || word == "og" || word == "på") {
+			continue
+		}
+		if utf8.RuneCountInString(word) == 1 {
+			words[i] = strings.ToUpper(word)
+			continue
+		}
+		parts := strings.Split(word, "-")
+		for j, part := range parts {
+			parts[j] = capitalize(part)
+		}
+		words[i] = strings.Join(parts, "-")
+	}
+	return strings.Join(words, " ")
+}
+
+// capitalize upper-cases the first letter of a word
+func capitalize(word string) string {
+	r, size := utf8.DecodeRuneInString(word)
+	if r == utf8.RuneError {
+		return word
+	}
+	return strings.ToUpper(string(r)) + word[size:]
+}
+
+// decodeLine returns the line as a string, decoding Latin-1 if the bytes are not valid UTF-8
+func decodeLine(line []byte) string {
+	if utf8.Valid(line) {
+		return string(line)
+	}
+	runes := make([]rune, len(line))
+	for i, b := range line {
+		runes[i] = rune(b)
+	}
+	return string(runes)
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/straye-as/relation-api/internal/domain"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // ActivityRepository handles database operations for activities.
@@ -107,17 +108,26 @@ func (r *ActivityRepository) ListByTarget(ctx context.Context, targetType domain
 }
 
 // GetMyTasks retrieves tasks assigned to the specified user that are not completed or cancelled.
+// Of a recurring series only the earliest open occurrence is included, so a daily task does not
+// fill the list with months of future occurrences.
 // Tasks are ordered by due_date (nulls last), then by priority (descending), then by created_at.
 func (r *ActivityRepository) GetMyTasks(ctx context.Context, userID string, page, pageSize int) ([]domain.Activity, int64, error) {
 	var activities []domain.Activity
 	var total int64
 
+	closedStatuses := []domain.ActivityStatus{
+		domain.ActivityStatusCompleted,
+		domain.ActivityStatusCancelled,
+	}
 	query := r.db.WithContext(ctx).Model(&domain.Activity{}).
 		Where("assigned_to_id = ?", userID).
-		Where("status NOT IN ?", []domain.ActivityStatus{
-			domain.ActivityStatusCompleted,
-			domain.ActivityStatusCancelled,
-		})
+		Where("status NOT IN ?", closedStatuses).
+		Where(`(series_id IS NULL OR id = (
+			SELECT s.id FROM activities s
+			WHERE s.series_id = activities.series_id AND s.assigned_to_id = activities.assigned_to_id
+				AND s.status NOT IN ?
+			ORDER BY COALESCE(s.scheduled_at, s.due_date), s.id
+			LIMIT 1))`, closedStatuses)
 
 	// Apply multi-tenant company filter
 	query = ApplyCompanyFilter(ctx, query)
@@ -327,3 +337,102 @@ func (r *ActivityRepository) GetRecentActivitiesInWindow(ctx context.Context, si
 	err := query.Find(&activities).Error
 	return activities, err
 }
+
+// ListForCalendar returns the activities assigned to or attended by a user that are scheduled, or
+// without a scheduled time are due, within [from, to). Ordered by scheduled time or due date.
+// The company filter is not applied since calendar feeds are fetched without a user context.
+func (r *ActivityRepository) ListForCalendar(ctx context.Context, userID string, from, to time.Time, limit int) ([]domain.Activity, error) {
+	var activities []domain.Activity
+	err := r.db.WithContext(ctx).
+		Where("(assigned_to_id = ? OR ? = ANY(attendees))", userID, userID).
+		Where("((scheduled_at >= ? AND scheduled_at < ?) OR (scheduled_at IS NULL AND due_date >= ? AND due_date < ?))",
+			from, to, from, to).
+		Order("COALESCE(scheduled_at, due_date) ASC").
+		Limit(limit).
+		Find(&activities).Error
+	if err != nil {
+		return nil, fmt.Errorf("fetching calendar activities: %w", err)
+	}
+	return activities, nil
+}
+
+// CreateOccurrences inserts generated occurrences of a recurring series. Occurrences that already
+// exist for the same series and start are skipped. Returns the number of occurrences inserted.
+func (r *ActivityRepository) CreateOccurrences(ctx context.Context, occurrences []domain.Activity) (int64, error) {
+	if len(occurrences) == 0 {
+		return 0, nil
+	}
+	result := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		CreateInBatches(&occurrences, 100)
+	if result.Error != nil {
+		return 0, fmt.Errorf("creating occurrences: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// SetRecurrenceExpandedUntil records how far ahead the occurrences of a series have been generated
+func (r *ActivityRepository) SetRecurrenceExpandedUntil(ctx context.Context, masterID uuid.UUID, until time.Time) error {
+	return r.db.WithContext(ctx).Model(&domain.Activity{}).
+		Where("id = ?", masterID).
+		UpdateColumn("recurrence_expanded_until", until).Error
+}
+
+// ListRecurringMasters returns masters of recurring series whose occurrences have not been generated
+// up to before, oldest expansion first. The company filter is not applied since expansion runs as a
+// background job.
+func (r *ActivityRepository) ListRecurringMasters(ctx context.Context, before time.Time, limit int) ([]domain.Activity, error) {
+	var masters []domain.Activity
+	err := r.db.WithContext(ctx).
+		Where("recurrence_rule <> ''").
+		Where("series_id = id").
+		Where("(recurrence_expanded_until IS NULL OR recurrence_expanded_until < ?)", before).
+		Order("recurrence_expanded_until ASC NULLS FIRST").
+		Limit(limit).
+		Find(&masters).Error
+	if err != nil {
+		return nil, fmt.Errorf("fetching recurring activities: %w", err)
+	}
+	return masters, nil
+}
+
+// GetNextInSeries returns the earliest occurrence of a series after the master
+func (r *ActivityRepository) GetNextInSeries(ctx context.Context, seriesID uuid.UUID) (*domain.Activity, error) {
+	var activity domain.Activity
+	err := r.db.WithContext(ctx).
+		Where("series_id = ? AND id <> ?", seriesID, seriesID).
+		Order("occurrence_at ASC").
+		First(&activity).Error
+	if err != nil {
+		return nil, err
+	}
+	return &activity, nil
+}
+
+// DeleteSeriesOccurrences deletes the occurrences of a series, other than the master and the excluded
+// activities, that start at or after from and are not completed. Occurrences edited on their own are
+// only deleted when includeExceptions is set. Returns the number of occurrences deleted.
+func (r *ActivityRepository) DeleteSeriesOccurrences(ctx context.Context, seriesID uuid.UUID, from time.Time, includeExceptions bool, excludeIDs ...uuid.UUID) (int64, error) {
+	query := r.db.WithContext(ctx).
+		Where("series_id = ? AND id <> ?", seriesID, seriesID).
+		Where("occurrence_at >= ?", from).
+		Where("status <> ?", domain.ActivityStatusCompleted)
+	if !includeExceptions {
+		query = query.Where("is_recurrence_exception = ?", false)
+	}
+	if len(excludeIDs) > 0 {
+		query = query.Where("id NOT IN ?", excludeIDs)
+	}
+	result := query.Delete(&domain.Activity{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("deleting occurrences: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// MoveSeries moves all activities of a series, including the master, to another master
+func (r *ActivityRepository) MoveSeries(ctx context.Context, seriesID, newSeriesID uuid.UUID) error {
+	return r.db.WithContext(ctx).Model(&domain.Activity{}).
+		Where("series_id = ?", seriesID).
+		UpdateColumn("series_id", newSeriesID).Error
+}
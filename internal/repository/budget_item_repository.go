@@ -61,6 +61,31 @@ func (r *BudgetItemRepository) ListByParent(ctx context.Context, parentType doma
 	return items, err
 }
 
+// ListByParents returns the budget items of several parents of the same type
+func (r *BudgetItemRepository) ListByParents(ctx context.Context, parentType domain.BudgetParentType, parentIDs []uuid.UUID) ([]domain.BudgetItem, error) {
+	var items []domain.BudgetItem
+	if len(parentIDs) == 0 {
+		return items, nil
+	}
+	err := r.db.WithContext(ctx).
+		Where("parent_type = ? AND parent_id IN ?", parentType, parentIDs).
+		Order("parent_id ASC, display_order ASC, created_at ASC").
+		Find(&items).Error
+	return items, err
+}
+
+// CreateBatch inserts several budget items in one transaction
+func (r *BudgetItemRepository) CreateBatch(ctx context.Context, items []domain.BudgetItem) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for i := range items {
+			if err := tx.Create(&items[i]).Error; err != nil {
+				return fmt.Errorf("failed to create budget item: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
 // DeleteByParent removes all budget items for a specific parent
 func (r *BudgetItemRepository) DeleteByParent(ctx context.Context, parentType domain.BudgetParentType, parentID uuid.UUID) error {
 	return r.db.WithContext(ctx).
@@ -170,6 +195,7 @@ func (r *BudgetItemRepository) CloneItems(ctx context.Context, fromParentType do
 				PricePerItem:   item.PricePerItem,
 				Description:    item.Description,
 				DisplayOrder:   item.DisplayOrder,
+				CostCategory:   item.CostCategory,
 			}
 			if err := tx.Create(&newItem).Error; err != nil {
 				return fmt.Errorf("failed to clone budget item: %w", err)
@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/straye-as/relation-api/internal/domain"
+	"gorm.io/gorm"
+)
+
+// CalendarFeedTokenRepository handles data access for calendar feed tokens
+type CalendarFeedTokenRepository struct {
+	db *gorm.DB
+}
+
+// NewCalendarFeedTokenRepository creates a new calendar feed token repository instance
+func NewCalendarFeedTokenRepository(db *gorm.DB) *CalendarFeedTokenRepository {
+	return &CalendarFeedTokenRepository{db: db}
+}
+
+// GetActiveByUser retrieves the active token of a user
+func (r *CalendarFeedTokenRepository) GetActiveByUser(ctx context.Context, userID string) (*domain.CalendarFeedToken, error) {
+	var token domain.CalendarFeedToken
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Order("created_at DESC").
+		First(&token).Error
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// GetActiveByHash retrieves an active token by the hash of its value
+func (r *CalendarFeedTokenRepository) GetActiveByHash(ctx context.Context, tokenHash string) (*domain.CalendarFeedToken, error) {
+	var token domain.CalendarFeedToken
+	err := r.db.WithContext(ctx).
+		Where("token_hash = ? AND revoked_at IS NULL", tokenHash).
+		First(&token).Error
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// Rotate revokes the active tokens of the token's user and creates the token in one transaction
+func (r *CalendarFeedTokenRepository) Rotate(ctx context.Context, token *domain.CalendarFeedToken) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := revokeCalendarFeedTokens(tx, token.UserID).Error; err != nil {
+			return err
+		}
+		return tx.Create(token).Error
+	})
+}
+
+// RevokeByUser revokes the active tokens of a user and returns the number revoked
+func (r *CalendarFeedTokenRepository) RevokeByUser(ctx context.Context, userID string) (int64, error) {
+	result := revokeCalendarFeedTokens(r.db.WithContext(ctx), userID)
+	return result.RowsAffected, result.Error
+}
+
+// TouchLastAccessed records that a calendar client fetched the feed
+func (r *CalendarFeedTokenRepository) TouchLastAccessed(ctx context.Context, token *domain.CalendarFeedToken) error {
+	now := time.Now()
+	token.LastAccessedAt = &now
+	return r.db.WithContext(ctx).
+		Model(&domain.CalendarFeedToken{}).
+		Where("id = ?", token.ID).
+		UpdateColumn("last_accessed_at", now).Error
+}
+
+// revokeCalendarFeedTokens revokes the active tokens of a user
+func revokeCalendarFeedTokens(tx *gorm.DB, userID string) *gorm.DB {
+	now := time.Now()
+	return tx.Model(&domain.CalendarFeedToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Updates(map[string]interface{}{"revoked_at": now, "updated_at": now})
+}
@@ -260,6 +260,22 @@ func (r *ContactRepository) RemoveRelationshipByID(ctx context.Context, relation
 	return nil
 }
 
+// UpdateRelationship saves the role fields of a relationship
+func (r *ContactRepository) UpdateRelationship(ctx context.Context, rel *domain.ContactRelationship) error {
+	return r.db.WithContext(ctx).Omit("Contact").Save(rel).Error
+}
+
+// CountByBuyingRole counts the active contacts with a buying role on an entity
+func (r *ContactRepository) CountByBuyingRole(ctx context.Context, entityType domain.ContactEntityType, entityID uuid.UUID, role domain.BuyingRole) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&domain.ContactRelationship{}).
+		Joins("JOIN contacts ON contacts.id = contact_relationships.contact_id").
+		Where("contact_relationships.entity_type = ? AND contact_relationships.entity_id = ?", entityType, entityID).
+		Where("contact_relationships.buying_role = ? AND contacts.is_active = ?", role, true).
+		Count(&count).Error
+	return count, err
+}
+
 // CheckRelationshipExists checks if a relationship already exists
 func (r *ContactRepository) CheckRelationshipExists(ctx context.Context, contactID uuid.UUID, entityType domain.ContactEntityType, entityID uuid.UUID) (bool, error) {
 	var count int64
@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/straye-as/relation-api/internal/domain"
+	"gorm.io/gorm"
+)
+
+// ============================================================================
+// Contact Deduplication Methods
+// ============================================================================
+
+// customerContactsCondition matches contacts with the customer as primary customer or related to it
+const customerContactsCondition = `contacts.primary_customer_id = ? OR EXISTS (
+	SELECT 1 FROM contact_relationships cr
+	WHERE cr.contact_id = contacts.id AND cr.entity_type = ? AND cr.entity_id = ?
+)`
+
+// ListForCustomer returns active contacts with the customer as primary customer or related to it
+func (r *ContactRepository) ListForCustomer(ctx context.Context, customerID uuid.UUID) ([]domain.Contact, error) {
+	var contacts []domain.Contact
+	err := r.db.WithContext(ctx).
+		Preload("Relationships").
+		Preload("PrimaryCustomer").
+		Where("contacts.is_active = ?", true).
+		Where(customerContactsCondition, customerID, domain.ContactEntityCustomer, customerID).
+		Order("contacts.last_name, contacts.first_name").
+		Find(&contacts).Error
+	return contacts, err
+}
+
+// ListDuplicateCandidates returns the active contacts checked for duplicates, optionally limited to a customer
+func (r *ContactRepository) ListDuplicateCandidates(ctx context.Context, customerID *uuid.UUID) ([]domain.Contact, error) {
+	if customerID != nil {
+		return r.ListForCustomer(ctx, *customerID)
+	}
+
+	var contacts []domain.Contact
+	err := r.db.WithContext(ctx).
+		Preload("Relationships").
+		Preload("PrimaryCustomer").
+		Where("is_active = ?", true).
+		Order("created_at").
+		Find(&contacts).Error
+	return contacts, err
+}
+
+// ListActiveByIDs returns the active contacts with the given IDs
+func (r *ContactRepository) ListActiveByIDs(ctx context.Context, ids []uuid.UUID) ([]domain.Contact, error) {
+	var contacts []domain.Contact
+	err := r.db.WithContext(ctx).
+		Where("id IN ? AND is_active = ?", ids, true).
+		Find(&contacts).Error
+	return contacts, err
+}
+
+// ContactMerge counts the records moved to the surviving contact
+type ContactMerge struct {
+	Relationships int64
+	Activities    int64
+}
+
+// Merge moves the relationships and activities of the duplicates to the survivor in one transaction.
+// Relationships the survivor already has are dropped, keeping the primary flag. The duplicates are
+// deactivated and point at the survivor, and the survivor is saved with the fields filled in from them.
+func (r *ContactRepository) Merge(ctx context.Context, survivor *domain.Contact, duplicates []domain.Contact) (*ContactMerge, error) {
+	result := &ContactMerge{}
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, duplicate := range duplicates {
+			// Relationships to entities the survivor is already related to
+			if err := tx.Exec(`
+				UPDATE contact_relationships s SET is_primary = true
+				FROM contact_relationships d
+				WHERE s.contact_id = ? AND d.contact_id = ? AND d.is_primary
+				AND d.entity_type = s.entity_type AND d.entity_id = s.entity_id`,
+				survivor.ID, duplicate.ID).Error; err != nil {
+				return err
+			}
+			if err := tx.Exec(`
+				DELETE FROM contact_relationships d
+				WHERE d.contact_id = ? AND EXISTS (
+					SELECT 1 FROM contact_relationships s
+					WHERE s.contact_id = ? AND s.entity_type = d.entity_type AND s.entity_id = d.entity_id
+				)`,
+				duplicate.ID, survivor.ID).Error; err != nil {
+				return err
+			}
+
+			moved := tx.Model(&domain.ContactRelationship{}).
+				Where("contact_id = ?", duplicate.ID).
+				Update("contact_id", survivor.ID)
+			if moved.Error != nil {
+				return moved.Error
+			}
+			result.Relationships += moved.RowsAffected
+
+			moved = tx.Model(&domain.Activity{}).
+				Where("target_type = ? AND target_id = ?", domain.ActivityTargetContact, duplicate.ID).
+				Updates(map[string]interface{}{
+					"target_id":   survivor.ID,
+					"target_name": survivor.FullName(),
+				})
+			if moved.Error != nil {
+				return moved.Error
+			}
+			result.Activities += moved.RowsAffected
+
+			// Deactivate the duplicate before saving the survivor, which may take over its email
+			if err := tx.Model(&domain.Contact{}).
+				Where("id = ?", duplicate.ID).
+				Updates(map[string]interface{}{
+					"is_active":      false,
+					"merged_into_id": survivor.ID,
+					"email":          duplicate.Email,
+				}).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Omit("Relationships", "PrimaryCustomer").Save(survivor).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
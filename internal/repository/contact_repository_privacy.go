@@ -0,0 +1,382 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/straye-as/relation-api/internal/domain"
+	"gorm.io/gorm"
+)
+
+// ============================================================================
+// Contact Privacy (GDPR) Methods
+// ============================================================================
+
+// DataSubject identifies a person in contacts, free text and denormalized copies.
+// Names and emails are matched case-insensitively, phone numbers exactly.
+type DataSubject struct {
+	ContactIDs []uuid.UUID
+	Names      []string // Full names
+	Emails     []string
+	Phones     []string
+}
+
+// minDataSubjectTermLength is the shortest name, email or phone matched in free text, to avoid scrubbing unrelated text
+const minDataSubjectTermLength = 3
+
+// terms returns the names, emails and phone numbers of the data subject used for matching free text
+func (d *DataSubject) terms() []string {
+	var terms []string
+	for _, values := range [][]string{d.Names, d.Emails, d.Phones} {
+		for _, value := range values {
+			if value = strings.TrimSpace(value); len(value) >= minDataSubjectTermLength {
+				terms = append(terms, value)
+			}
+		}
+	}
+	return terms
+}
+
+// lowerNonEmpty returns the trimmed, lowercased non-empty values
+func lowerNonEmpty(values []string) []string {
+	result := []string{}
+	for _, value := range values {
+		if value = strings.ToLower(strings.TrimSpace(value)); value != "" {
+			result = append(result, value)
+		}
+	}
+	return result
+}
+
+// containsPattern returns an ILIKE pattern matching text containing value
+func containsPattern(value string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return "%" + replacer.Replace(value) + "%"
+}
+
+// textMatch builds an OR condition matching any of the data subject's terms in the given text columns
+func (d *DataSubject) textMatch(columns ...string) (string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+	for _, term := range d.terms() {
+		for _, column := range columns {
+			conditions = append(conditions, fmt.Sprintf("COALESCE(%s, '') ILIKE ?", column))
+			args = append(args, containsPattern(term))
+		}
+	}
+	if len(conditions) == 0 {
+		return "FALSE", nil
+	}
+	return "(" + strings.Join(conditions, " OR ") + ")", args
+}
+
+// termsRegex returns a case-insensitive (with the 'gi' flags) Postgres regular expression matching any term
+func (d *DataSubject) termsRegex() string {
+	terms := d.terms()
+	quoted := make([]string, len(terms))
+	for i, term := range terms {
+		quoted[i] = regexp.QuoteMeta(term)
+	}
+	return strings.Join(quoted, "|")
+}
+
+// GetByIDIncludingInactive returns a contact by ID, including deleted (inactive) contacts
+func (r *ContactRepository) GetByIDIncludingInactive(ctx context.Context, id uuid.UUID) (*domain.Contact, error) {
+	var contact domain.Contact
+	err := r.db.WithContext(ctx).
+		Preload("Relationships").
+		Preload("PrimaryCustomer").
+		First(&contact, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &contact, nil
+}
+
+// ListByEmailIncludingInactive returns all contacts with the email address, including deleted (inactive) contacts
+func (r *ContactRepository) ListByEmailIncludingInactive(ctx context.Context, email string) ([]domain.Contact, error) {
+	var contacts []domain.Contact
+	err := r.db.WithContext(ctx).
+		Preload("Relationships").
+		Preload("PrimaryCustomer").
+		Where("LOWER(email) = LOWER(?)", strings.TrimSpace(email)).
+		Order("created_at").
+		Find(&contacts).Error
+	return contacts, err
+}
+
+// dataSubjectActivities returns a query for activities on the data subject's contacts or mentioning it
+func (r *ContactRepository) dataSubjectActivities(db *gorm.DB, subject *DataSubject) *gorm.DB {
+	textCondition, args := subject.textMatch("title", "body", "target_name")
+	condition := textCondition + " OR COALESCE(attendees, '{}') && ?"
+	args = append(args, pq.StringArray(subject.attendees()))
+	if len(subject.ContactIDs) > 0 {
+		condition = "(target_type = ? AND target_id IN ?) OR " + condition
+		args = append([]interface{}{domain.ActivityTargetContact, subject.ContactIDs}, args...)
+	}
+	return db.Model(&domain.Activity{}).Where(condition, args...)
+}
+
+// attendees returns the values the data subject can appear as in activity attendees
+func (d *DataSubject) attendees() []string {
+	attendees := []string{}
+	for _, values := range [][]string{d.Names, d.Emails} {
+		for _, value := range values {
+			if value = strings.TrimSpace(value); value != "" {
+				attendees = append(attendees, value, strings.ToLower(value))
+			}
+		}
+	}
+	return attendees
+}
+
+// ListDataSubjectActivities returns activities on the data subject's contacts or mentioning its name, email or phone
+func (r *ContactRepository) ListDataSubjectActivities(ctx context.Context, subject *DataSubject) ([]domain.Activity, error) {
+	var activities []domain.Activity
+	err := r.dataSubjectActivities(r.db.WithContext(ctx), subject).
+		Order("occurred_at DESC").
+		Find(&activities).Error
+	return activities, err
+}
+
+// DataSubjectReference is a denormalized copy of a data subject's personal data on another record
+type DataSubjectReference struct {
+	EntityType string
+	EntityID   uuid.UUID
+	EntityName string
+	Fields     map[string]string
+}
+
+// dataSubjectReferenceTable describes a table holding denormalized contact person data
+type dataSubjectReferenceTable struct {
+	entityType  string
+	table       string
+	nameColumn  string // Column with the record's own name
+	personField string // Column with the contact person's name
+	emailField  string
+	phoneField  string
+}
+
+var dataSubjectReferenceTables = []dataSubjectReferenceTable{
+	{entityType: "customer", table: "customers", nameColumn: "name", personField: "contact_person", emailField: "contact_email", phoneField: "contact_phone"},
+	{entityType: "supplier", table: "suppliers", nameColumn: "name", personField: "contact_person", emailField: "contact_email", phoneField: "contact_phone"},
+	{entityType: "offer_supplier", table: "offer_suppliers", nameColumn: "supplier_name || ' - ' || offer_title", personField: "contact_name"},
+}
+
+// match returns the condition matching records holding the data subject's personal data
+func (t dataSubjectReferenceTable) match(subject *DataSubject) (string, []interface{}) {
+	conditions := []string{fmt.Sprintf("LOWER(TRIM(%s)) IN ?", t.personField)}
+	args := []interface{}{lowerNonEmpty(subject.Names)}
+	if t.emailField != "" {
+		conditions = append(conditions, fmt.Sprintf("LOWER(TRIM(%s)) IN ?", t.emailField))
+		args = append(args, lowerNonEmpty(subject.Emails))
+	}
+	if t.phoneField != "" {
+		conditions = append(conditions, fmt.Sprintf("TRIM(%s) IN ?", t.phoneField))
+		args = append(args, lowerNonEmpty(subject.Phones))
+	}
+	return "(" + strings.Join(conditions, " OR ") + ")", args
+}
+
+// ListDataSubjectReferences returns customers, suppliers and offer suppliers whose contact person fields
+// hold the data subject's name, email or phone
+func (r *ContactRepository) ListDataSubjectReferences(ctx context.Context, subject *DataSubject) ([]DataSubjectReference, error) {
+	var references []DataSubjectReference
+	for _, table := range dataSubjectReferenceTables {
+		var rows []struct {
+			ID     uuid.UUID
+			Name   string
+			Person string
+			Email  string
+			Phone  string
+		}
+
+		columns := []string{"id", table.nameColumn + " AS name", table.personField + " AS person"}
+		if table.emailField != "" {
+			columns = append(columns, table.emailField+" AS email")
+		}
+		if table.phoneField != "" {
+			columns = append(columns, table.phoneField+" AS phone")
+		}
+
+		condition, args := table.match(subject)
+		err := r.db.WithContext(ctx).
+			Table(table.table).
+			Select(strings.Join(columns, ", ")).
+			Where(condition, args...).
+			Order("id").
+			Scan(&rows).Error
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s references: %w", table.entityType, err)
+		}
+
+		for _, row := range rows {
+			fields := map[string]string{}
+			for field, value := range map[string]string{table.personField: row.Person, table.emailField: row.Email, table.phoneField: row.Phone} {
+				if field != "" && strings.TrimSpace(value) != "" {
+					fields[field] = value
+				}
+			}
+			references = append(references, DataSubjectReference{
+				EntityType: table.entityType,
+				EntityID:   row.ID,
+				EntityName: row.Name,
+				Fields:     fields,
+			})
+		}
+	}
+	return references, nil
+}
+
+// dataSubjectAuditLogs returns a query for audit log entries on the data subject's contacts or containing its data
+func (r *ContactRepository) dataSubjectAuditLogs(db *gorm.DB, subject *DataSubject) *gorm.DB {
+	condition, args := subject.textMatch("old_values::text", "new_values::text", "changes::text")
+	if len(subject.ContactIDs) > 0 {
+		// The audit middleware records contact routes with entity type "Contact"
+		condition = "(entity_type = 'Contact' AND entity_id IN ?) OR " + condition
+		args = append([]interface{}{subject.ContactIDs}, args...)
+	}
+	return db.Model(&domain.AuditLog{}).Where(condition, args...)
+}
+
+// ListDataSubjectAuditLogs returns audit log entries for the data subject's contacts or containing its data
+func (r *ContactRepository) ListDataSubjectAuditLogs(ctx context.Context, subject *DataSubject) ([]domain.AuditLog, error) {
+	var logs []domain.AuditLog
+	err := r.dataSubjectAuditLogs(r.db.WithContext(ctx), subject).
+		Order("performed_at DESC").
+		Find(&logs).Error
+	return logs, err
+}
+
+// ContactAnonymization holds the number of records scrubbed when anonymizing a contact
+type ContactAnonymization struct {
+	Activities int64
+	References int64
+	AuditLogs  int64
+}
+
+// Anonymize saves a scrubbed contact and replaces the data subject's name, email and phone with the placeholder
+// in activities, customer and supplier contact fields, offer suppliers and audit logs.
+// Relationships, activities and audit log entries are kept; only their personal data is replaced.
+// The data subject must hold the contact's personal data from before it was scrubbed. Runs in a single transaction.
+func (r *ContactRepository) Anonymize(ctx context.Context, contact *domain.Contact, subject *DataSubject, placeholder string) (*ContactAnonymization, error) {
+	result := &ContactAnonymization{}
+	pattern := subject.termsRegex()
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Relationships", "PrimaryCustomer").Save(contact).Error; err != nil {
+			return fmt.Errorf("failed to anonymize contact: %w", err)
+		}
+
+		if pattern == "" {
+			return nil
+		}
+
+		// Activities: replace mentions, rename activities on the contact and drop it from attendees
+		activities := r.dataSubjectActivities(tx, subject).
+			UpdateColumns(map[string]interface{}{
+				"title":       gorm.Expr("regexp_replace(title, ?, ?, 'gi')", pattern, placeholder),
+				"body":        gorm.Expr("regexp_replace(body, ?, ?, 'gi')", pattern, placeholder),
+				"target_name": gorm.Expr("regexp_replace(target_name, ?, ?, 'gi')", pattern, placeholder),
+				"attendees":   gorm.Expr("ARRAY(SELECT a FROM unnest(attendees) a WHERE LOWER(a) <> ALL(?))", pq.StringArray(lowerNonEmpty(subject.attendees()))),
+			})
+		if activities.Error != nil {
+			return fmt.Errorf("failed to anonymize activities: %w", activities.Error)
+		}
+		result.Activities = activities.RowsAffected
+
+		// Denormalized contact person fields on customers, suppliers and offer suppliers
+		for _, table := range dataSubjectReferenceTables {
+			condition, args := table.match(subject)
+			updates := map[string]interface{}{
+				table.personField: gorm.Expr(fmt.Sprintf("CASE WHEN LOWER(TRIM(%s)) IN ? THEN '' ELSE %s END", table.personField, table.personField), lowerNonEmpty(subject.Names)),
+			}
+			if table.emailField != "" {
+				updates[table.emailField] = gorm.Expr(fmt.Sprintf("CASE WHEN LOWER(TRIM(%s)) IN ? THEN '' ELSE %s END", table.emailField, table.emailField), lowerNonEmpty(subject.Emails))
+			}
+			if table.phoneField != "" {
+				updates[table.phoneField] = gorm.Expr(fmt.Sprintf("CASE WHEN TRIM(%s) IN ? THEN '' ELSE %s END", table.phoneField, table.phoneField), lowerNonEmpty(subject.Phones))
+			}
+			references := tx.Table(table.table).Where(condition, args...).UpdateColumns(updates)
+			if references.Error != nil {
+				return fmt.Errorf("failed to anonymize %s references: %w", table.entityType, references.Error)
+			}
+			result.References += references.RowsAffected
+		}
+
+		// Audit logs: keep the entries, replace the personal data in the recorded values
+		auditLogs := r.dataSubjectAuditLogs(tx, subject).
+			UpdateColumns(map[string]interface{}{
+				"entity_name": gorm.Expr("regexp_replace(entity_name, ?, ?, 'gi')", pattern, placeholder),
+				"old_values":  gorm.Expr("regexp_replace(old_values::text, ?, ?, 'gi')::jsonb", pattern, placeholder),
+				"new_values":  gorm.Expr("regexp_replace(new_values::text, ?, ?, 'gi')::jsonb", pattern, placeholder),
+				"changes":     gorm.Expr("regexp_replace(changes::text, ?, ?, 'gi')::jsonb", pattern, placeholder),
+			})
+		if auditLogs.Error != nil {
+			return fmt.Errorf("failed to anonymize audit logs: %w", auditLogs.Error)
+		}
+		result.AuditLogs = auditLogs.RowsAffected
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// FlagInactiveForRetention flags contacts for retention review when neither the contact nor its activities
+// have changed since the cutoff. Reviewed contacts are only flagged again once the review is older than the cutoff.
+// Anonymized and already flagged contacts are skipped. Returns the number of contacts flagged.
+func (r *ContactRepository) FlagInactiveForRetention(ctx context.Context, cutoff time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Model(&domain.Contact{}).
+		Where("anonymized_at IS NULL AND retention_flagged_at IS NULL").
+		Where("updated_at < ?", cutoff).
+		Where("retention_reviewed_at IS NULL OR retention_reviewed_at < ?", cutoff).
+		Where(`NOT EXISTS (
+			SELECT 1 FROM activities a
+			WHERE a.target_type = ? AND a.target_id = contacts.id AND a.occurred_at >= ?
+		)`, domain.ActivityTargetContact, cutoff).
+		UpdateColumn("retention_flagged_at", time.Now())
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to flag contacts for retention review: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// ListFlaggedForRetention returns contacts flagged for retention review, oldest flag first
+func (r *ContactRepository) ListFlaggedForRetention(ctx context.Context, page, pageSize int) ([]domain.Contact, int64, error) {
+	var contacts []domain.Contact
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&domain.Contact{}).
+		Where("retention_flagged_at IS NOT NULL AND anonymized_at IS NULL")
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.
+		Preload("PrimaryCustomer").
+		Order("retention_flagged_at, last_name, first_name").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&contacts).Error
+	return contacts, total, err
+}
+
+// MarkRetentionReviewed clears the retention flag of a contact that was reviewed and kept
+func (r *ContactRepository) MarkRetentionReviewed(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).
+		Model(&domain.Contact{}).
+		Where("id = ?", id).
+		UpdateColumns(map[string]interface{}{
+			"retention_flagged_at":  nil,
+			"retention_reviewed_at": time.Now(),
+		}).Error
+}
@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
@@ -32,6 +33,8 @@ type CustomerFilters struct {
 	Status   *domain.CustomerStatus
 	Tier     *domain.CustomerTier
 	Industry *domain.CustomerIndustry
+	// RegistryStatus filters by Enhetsregisteret status (e.g. bankrupt, deleted)
+	RegistryStatus *domain.CustomerRegistryStatus
 }
 
 // customerSortableFields maps API field names to database column names for customers
@@ -73,8 +76,16 @@ func (r *CustomerRepository) Update(ctx context.Context, customer *domain.Custom
 	return r.db.WithContext(ctx).Save(customer).Error
 }
 
+// Delete soft deletes a customer. Its subsidiaries are detached from the customer group.
 func (r *CustomerRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	return r.db.WithContext(ctx).Delete(&domain.Customer{}, "id = ?", id).Error
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&domain.Customer{}).
+			Where("parent_customer_id = ?", id).
+			Update("parent_customer_id", nil).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&domain.Customer{}, "id = ?", id).Error
+	})
 }
 
 func (r *CustomerRepository) List(ctx context.Context, page, pageSize int, search string) ([]domain.Customer, int64, error) {
@@ -134,6 +145,9 @@ func (r *CustomerRepository) ListWithSortConfig(ctx context.Context, page, pageS
 		if filters.Industry != nil {
 			query = query.Where("industry = ?", *filters.Industry)
 		}
+		if filters.RegistryStatus != nil {
+			query = query.Where("registry_status = ?", *filters.RegistryStatus)
+		}
 	}
 
 	if err := query.Count(&total).Error; err != nil {
@@ -235,12 +249,21 @@ type CustomerStats struct {
 // GetCustomerStats returns aggregated statistics for a customer
 // Respects the X-Company-Id header for filtering offers, projects, and deals
 func (r *CustomerRepository) GetCustomerStats(ctx context.Context, customerID uuid.UUID) (*CustomerStats, error) {
+	return r.GetGroupStats(ctx, []uuid.UUID{customerID})
+}
+
+// GetGroupStats returns statistics summed over a set of customers, e.g. a customer group
+// Respects the X-Company-Id header for filtering offers, projects, and deals
+func (r *CustomerRepository) GetGroupStats(ctx context.Context, customerIDs []uuid.UUID) (*CustomerStats, error) {
 	stats := &CustomerStats{}
+	if len(customerIDs) == 0 {
+		return stats, nil
+	}
 
 	// Get working offers count (in_progress or sent phases)
 	var workingOffersCount int64
 	workingOfferQuery := r.db.WithContext(ctx).Model(&domain.Offer{}).
-		Where("customer_id = ? AND phase IN ?", customerID, []domain.OfferPhase{domain.OfferPhaseInProgress, domain.OfferPhaseSent})
+		Where("customer_id IN ? AND phase IN ?", customerIDs, []domain.OfferPhase{domain.OfferPhaseInProgress, domain.OfferPhaseSent})
 	workingOfferQuery = ApplyCompanyFilter(ctx, workingOfferQuery)
 	err := workingOfferQuery.Count(&workingOffersCount).Error
 	if err != nil {
@@ -255,7 +278,7 @@ func (r *CustomerRepository) GetCustomerStats(ctx context.Context, customerID uu
 	}
 	activeOfferQuery := r.db.WithContext(ctx).Model(&domain.Offer{}).
 		Select("COUNT(*) as count, COALESCE(SUM(value), 0) as total_value").
-		Where("customer_id = ? AND phase = ?", customerID, domain.OfferPhaseOrder)
+		Where("customer_id IN ? AND phase = ?", customerIDs, domain.OfferPhaseOrder)
 	activeOfferQuery = ApplyCompanyFilter(ctx, activeOfferQuery)
 	err = activeOfferQuery.Scan(&activeOfferStats).Error
 	if err != nil {
@@ -267,7 +290,7 @@ func (r *CustomerRepository) GetCustomerStats(ctx context.Context, customerID uu
 	// Get completed offers count (completed phase)
 	var completedOffersCount int64
 	completedOfferQuery := r.db.WithContext(ctx).Model(&domain.Offer{}).
-		Where("customer_id = ? AND phase = ?", customerID, domain.OfferPhaseCompleted)
+		Where("customer_id IN ? AND phase = ?", customerIDs, domain.OfferPhaseCompleted)
 	completedOfferQuery = ApplyCompanyFilter(ctx, completedOfferQuery)
 	err = completedOfferQuery.Count(&completedOffersCount).Error
 	if err != nil {
@@ -279,7 +302,7 @@ func (r *CustomerRepository) GetCustomerStats(ctx context.Context, customerID uu
 	var wonValue float64
 	wonOfferQuery := r.db.WithContext(ctx).Model(&domain.Offer{}).
 		Select("COALESCE(SUM(value), 0)").
-		Where("customer_id = ? AND phase IN ?", customerID, []domain.OfferPhase{domain.OfferPhaseOrder, domain.OfferPhaseCompleted})
+		Where("customer_id IN ? AND phase IN ?", customerIDs, []domain.OfferPhase{domain.OfferPhaseOrder, domain.OfferPhaseCompleted})
 	wonOfferQuery = ApplyCompanyFilter(ctx, wonOfferQuery)
 	err = wonOfferQuery.Scan(&wonValue).Error
 	if err != nil {
@@ -290,7 +313,7 @@ func (r *CustomerRepository) GetCustomerStats(ctx context.Context, customerID uu
 	// Get total offers count (all offers for this customer)
 	var totalOffersCount int64
 	totalOffersQuery := r.db.WithContext(ctx).Model(&domain.Offer{}).
-		Where("customer_id = ?", customerID)
+		Where("customer_id IN ?", customerIDs)
 	totalOffersQuery = ApplyCompanyFilter(ctx, totalOffersQuery)
 	err = totalOffersQuery.Count(&totalOffersCount).Error
 	if err != nil {
@@ -301,7 +324,7 @@ func (r *CustomerRepository) GetCustomerStats(ctx context.Context, customerID uu
 	// Get active deals count
 	var dealsCount int64
 	dealsQuery := r.db.WithContext(ctx).Model(&domain.Deal{}).
-		Where("customer_id = ? AND stage NOT IN (?, ?)", customerID, domain.DealStageWon, domain.DealStageLost)
+		Where("customer_id IN ? AND stage NOT IN (?, ?)", customerIDs, domain.DealStageWon, domain.DealStageLost)
 	dealsQuery = ApplyCompanyFilter(ctx, dealsQuery)
 	err = dealsQuery.Count(&dealsCount).Error
 	if err != nil {
@@ -314,7 +337,7 @@ func (r *CustomerRepository) GetCustomerStats(ctx context.Context, customerID uu
 	// Projects are linked to customers which are global entities
 	var activeProjectsCount int64
 	err = r.db.WithContext(ctx).Model(&domain.Project{}).
-		Where("customer_id = ? AND phase IN (?)", customerID, []domain.ProjectPhase{domain.ProjectPhaseTilbud, domain.ProjectPhaseWorking, domain.ProjectPhaseOnHold}).
+		Where("customer_id IN ? AND phase IN (?)", customerIDs, []domain.ProjectPhase{domain.ProjectPhaseTilbud, domain.ProjectPhaseWorking, domain.ProjectPhaseOnHold}).
 		Count(&activeProjectsCount).Error
 	if err != nil {
 		return nil, err
@@ -325,7 +348,7 @@ func (r *CustomerRepository) GetCustomerStats(ctx context.Context, customerID uu
 	// Note: Projects no longer have company_id (removed in migration 00050)
 	var totalProjectsCount int64
 	err = r.db.WithContext(ctx).Model(&domain.Project{}).
-		Where("customer_id = ?", customerID).
+		Where("customer_id IN ?", customerIDs).
 		Count(&totalProjectsCount).Error
 	if err != nil {
 		return nil, err
@@ -337,7 +360,7 @@ func (r *CustomerRepository) GetCustomerStats(ctx context.Context, customerID uu
 	// Contacts do not have company_id - they belong to customers, not companies
 	var contactsCount int64
 	err = r.db.WithContext(ctx).Model(&domain.Contact{}).
-		Where("primary_customer_id = ?", customerID).
+		Where("primary_customer_id IN ?", customerIDs).
 		Count(&contactsCount).Error
 	if err != nil {
 		return nil, err
@@ -347,6 +370,121 @@ func (r *CustomerRepository) GetCustomerStats(ctx context.Context, customerID uu
 	return stats, nil
 }
 
+// MaxCustomerGroupDepth limits how many levels of subsidiaries a customer group can have
+const MaxCustomerGroupDepth = 10
+
+// customerGroupSQL selects a customer and all its subsidiaries with their depth below the customer.
+// The depth limit guards against cycles in the parent relation.
+const customerGroupSQL = `WITH RECURSIVE customer_group AS (
+	SELECT id, 0 AS depth FROM customers WHERE id = ? AND deleted_at IS NULL
+	UNION ALL
+	SELECT c.id, g.depth + 1 FROM customers c
+	JOIN customer_group g ON c.parent_customer_id = g.id
+	WHERE c.deleted_at IS NULL AND g.depth < ?
+)`
+
+// customerGroupIDsSubquery returns a subquery selecting the IDs of a customer and its subsidiaries,
+// for filtering other tables by customer group
+func customerGroupIDsSubquery(db *gorm.DB, customerID uuid.UUID) *gorm.DB {
+	return db.Raw(customerGroupSQL+` SELECT id FROM customer_group`, customerID, MaxCustomerGroupDepth)
+}
+
+// CustomerGroupMember is a customer in a customer group with its depth below the group's root
+type CustomerGroupMember struct {
+	Customer domain.Customer
+	Depth    int
+}
+
+// ListGroupMembers returns a customer and all its subsidiaries, ordered by depth and name
+func (r *CustomerRepository) ListGroupMembers(ctx context.Context, customerID uuid.UUID) ([]CustomerGroupMember, error) {
+	var rows []struct {
+		ID    uuid.UUID
+		Depth int
+	}
+	err := r.db.WithContext(ctx).
+		Raw(customerGroupSQL+` SELECT id, MIN(depth) AS depth FROM customer_group GROUP BY id`, customerID, MaxCustomerGroupDepth).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	depths := make(map[uuid.UUID]int, len(rows))
+	ids := make([]uuid.UUID, len(rows))
+	for i, row := range rows {
+		depths[row.ID] = row.Depth
+		ids[i] = row.ID
+	}
+
+	var customers []domain.Customer
+	if err := r.db.WithContext(ctx).Where("id IN ?", ids).Order("name ASC").Find(&customers).Error; err != nil {
+		return nil, err
+	}
+
+	members := make([]CustomerGroupMember, len(customers))
+	for i, customer := range customers {
+		members[i] = CustomerGroupMember{Customer: customer, Depth: depths[customer.ID]}
+	}
+	sort.SliceStable(members, func(i, j int) bool { return members[i].Depth < members[j].Depth })
+	return members, nil
+}
+
+// ListAncestors returns the parent customers of a customer, nearest first, up to the top of the group
+func (r *CustomerRepository) ListAncestors(ctx context.Context, customerID uuid.UUID) ([]domain.Customer, error) {
+	var rows []struct {
+		ID    uuid.UUID
+		Depth int
+	}
+	err := r.db.WithContext(ctx).Raw(`WITH RECURSIVE ancestors AS (
+	SELECT parent_customer_id AS id, 1 AS depth FROM customers WHERE id = ? AND parent_customer_id IS NOT NULL
+	UNION ALL
+	SELECT c.parent_customer_id, a.depth + 1 FROM customers c
+	JOIN ancestors a ON c.id = a.id
+	WHERE c.parent_customer_id IS NOT NULL AND c.deleted_at IS NULL AND a.depth < ?
+) SELECT id, MIN(depth) AS depth FROM ancestors GROUP BY id ORDER BY depth`, customerID, MaxCustomerGroupDepth).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]uuid.UUID, len(rows))
+	for i, row := range rows {
+		ids[i] = row.ID
+	}
+
+	var customers []domain.Customer
+	if err := r.db.WithContext(ctx).Where("id IN ?", ids).Find(&customers).Error; err != nil {
+		return nil, err
+	}
+
+	// Keep the nearest-first order of the recursive query
+	byID := make(map[uuid.UUID]domain.Customer, len(customers))
+	for _, customer := range customers {
+		byID[customer.ID] = customer
+	}
+	ancestors := make([]domain.Customer, 0, len(customers))
+	for _, id := range ids {
+		if customer, ok := byID[id]; ok {
+			ancestors = append(ancestors, customer)
+		}
+	}
+	return ancestors, nil
+}
+
+// GetGroupDepth returns how many levels of subsidiaries are below a customer
+func (r *CustomerRepository) GetGroupDepth(ctx context.Context, customerID uuid.UUID) (int, error) {
+	var depth int
+	err := r.db.WithContext(ctx).
+		Raw(customerGroupSQL+` SELECT COALESCE(MAX(depth), 0) FROM customer_group`, customerID, MaxCustomerGroupDepth).
+		Scan(&depth).Error
+	return depth, err
+}
+
 // GetCustomerWithRelations returns a customer with preloaded contacts
 func (r *CustomerRepository) GetCustomerWithRelations(ctx context.Context, id uuid.UUID) (*domain.Customer, error) {
 	var customer domain.Customer
@@ -608,6 +746,191 @@ func (r *CustomerRepository) GetAllMinimal(ctx context.Context) ([]domain.Custom
 	return customers, err
 }
 
+// ListForRegistryCheck returns customers with an organization number that have never been
+// checked against Enhetsregisteret or were last checked before the given time.
+// Customers never checked come first, then the oldest checks.
+func (r *CustomerRepository) ListForRegistryCheck(ctx context.Context, checkedBefore time.Time, limit int) ([]domain.Customer, error) {
+	var customers []domain.Customer
+	err := r.db.WithContext(ctx).
+		Where("org_number IS NOT NULL AND org_number != ''").
+		Where("registry_checked_at IS NULL OR registry_checked_at < ?", checkedBefore).
+		Order("registry_checked_at ASC NULLS FIRST, name ASC").
+		Limit(limit).
+		Find(&customers).Error
+	return customers, err
+}
+
+// ListWithPostalCode returns all customers that have a postal code, ordered by name
+func (r *CustomerRepository) ListWithPostalCode(ctx context.Context) ([]domain.Customer, error) {
+	var customers []domain.Customer
+	err := r.db.WithContext(ctx).
+		Where("postal_code IS NOT NULL AND postal_code != ''").
+		Order("name ASC").
+		Find(&customers).Error
+	return customers, err
+}
+
+// ListForReconciliation returns all customers with the fields compared against the ERP, ordered by name
+func (r *CustomerRepository) ListForReconciliation(ctx context.Context) ([]domain.Customer, error) {
+	var customers []domain.Customer
+	err := r.db.WithContext(ctx).
+		Select("id, name, org_number, address, postal_code, city, country, company_id").
+		Order("name ASC").
+		Find(&customers).Error
+	return customers, err
+}
+
+// ListForTierRecalculation returns all customers with the fields needed to recalculate their tier
+func (r *CustomerRepository) ListForTierRecalculation(ctx context.Context) ([]domain.Customer, error) {
+	var customers []domain.Customer
+	err := r.db.WithContext(ctx).
+		Select("id, name, tier, tier_locked, company_id").
+		Order("name ASC").
+		Find(&customers).Error
+	return customers, err
+}
+
+// WonOfferTotals holds the won offers (order or completed phase) of a customer
+type WonOfferTotals struct {
+	Value float64
+	Count int
+}
+
+// GetWonOfferTotals returns won offer value and count per customer across all companies.
+// Offers are dated by sent date, falling back to creation date. If since is nil all offers are counted.
+// If customerIDs is empty, totals are returned for all customers.
+func (r *CustomerRepository) GetWonOfferTotals(ctx context.Context, since *time.Time, customerIDs []uuid.UUID) (map[uuid.UUID]WonOfferTotals, error) {
+	var rows []struct {
+		CustomerID uuid.UUID
+		Value      float64
+		Count      int
+	}
+
+	query := r.db.WithContext(ctx).Model(&domain.Offer{}).
+		Select("customer_id, COALESCE(SUM(value), 0) as value, COUNT(*) as count").
+		Where("customer_id IS NOT NULL AND phase IN ?", []domain.OfferPhase{domain.OfferPhaseOrder, domain.OfferPhaseCompleted})
+	if since != nil {
+		query = query.Where("COALESCE(sent_date, created_at) >= ?", *since)
+	}
+	if len(customerIDs) > 0 {
+		query = query.Where("customer_id IN ?", customerIDs)
+	}
+
+	if err := query.Group("customer_id").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	totals := make(map[uuid.UUID]WonOfferTotals, len(rows))
+	for _, row := range rows {
+		totals[row.CustomerID] = WonOfferTotals{Value: row.Value, Count: row.Count}
+	}
+	return totals, nil
+}
+
+// ChangeTier updates the tier columns of a customer and records the change in the tier history.
+// history may be nil when only the lock or calculation timestamp changes.
+func (r *CustomerRepository) ChangeTier(ctx context.Context, customerID uuid.UUID, updates map[string]interface{}, history *domain.CustomerTierHistory) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		updates["updated_at"] = time.Now()
+		result := tx.Model(&domain.Customer{}).Where("id = ?", customerID).Updates(updates)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		if history != nil {
+			history.CustomerID = customerID
+			if err := tx.Create(history).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// CreateTierHistory records a change of customer tier
+func (r *CustomerRepository) CreateTierHistory(ctx context.Context, history *domain.CustomerTierHistory) error {
+	return r.db.WithContext(ctx).Create(history).Error
+}
+
+// MarkTierCalculated sets the tier calculation timestamp for customers whose tier did not change
+func (r *CustomerRepository) MarkTierCalculated(ctx context.Context, customerIDs []uuid.UUID, calculatedAt time.Time) error {
+	if len(customerIDs) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Model(&domain.Customer{}).
+		Where("id IN ?", customerIDs).
+		UpdateColumn("tier_calculated_at", calculatedAt).Error
+}
+
+// ListTierHistory returns the tier changes of a customer, newest first
+func (r *CustomerRepository) ListTierHistory(ctx context.Context, customerID uuid.UUID, page, pageSize int) ([]domain.CustomerTierHistory, int64, error) {
+	var history []domain.CustomerTierHistory
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&domain.CustomerTierHistory{}).Where("customer_id = ?", customerID)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	err := query.
+		Order("created_at DESC").
+		Offset(offset).
+		Limit(pageSize).
+		Find(&history).Error
+
+	return history, total, err
+}
+
+// CreditExposureTotals holds the open orders and sent offers of a customer used for credit exposure
+type CreditExposureTotals struct {
+	OpenOrderReserve  float64
+	OpenOrders        int
+	WeightedSentValue float64
+	SentOffers        int
+}
+
+// GetCreditExposureTotals returns the open order reserve and probability-weighted sent offer value
+// for a customer across all companies. excludeOfferID leaves one offer out, e.g. the offer being accepted.
+func (r *CustomerRepository) GetCreditExposureTotals(ctx context.Context, customerID uuid.UUID, excludeOfferID *uuid.UUID) (*CreditExposureTotals, error) {
+	var orders struct {
+		Count   int
+		Reserve float64
+	}
+	orderQuery := r.db.WithContext(ctx).Model(&domain.Offer{}).
+		Select("COUNT(*) as count, COALESCE(SUM(GREATEST(order_reserve, 0)), 0) as reserve").
+		Where("customer_id = ? AND phase = ?", customerID, domain.OfferPhaseOrder)
+	if excludeOfferID != nil {
+		orderQuery = orderQuery.Where("id <> ?", *excludeOfferID)
+	}
+	if err := orderQuery.Scan(&orders).Error; err != nil {
+		return nil, err
+	}
+
+	var sent struct {
+		Count    int
+		Weighted float64
+	}
+	sentQuery := r.db.WithContext(ctx).Model(&domain.Offer{}).
+		Select("COUNT(*) as count, COALESCE(SUM(value * probability / 100.0), 0) as weighted").
+		Where("customer_id = ? AND phase = ?", customerID, domain.OfferPhaseSent)
+	if excludeOfferID != nil {
+		sentQuery = sentQuery.Where("id <> ?", *excludeOfferID)
+	}
+	if err := sentQuery.Scan(&sent).Error; err != nil {
+		return nil, err
+	}
+
+	return &CreditExposureTotals{
+		OpenOrderReserve:  orders.Reserve,
+		OpenOrders:        orders.Count,
+		WeightedSentValue: sent.Weighted,
+		SentOffers:        sent.Count,
+	}, nil
+}
+
 // GetTopCustomersWithOfferStats returns top customers ranked by offer count within a time window
 // If since is nil, no date filter is applied (all time)
 // Excludes draft and expired offers from the counts
@@ -688,8 +1011,9 @@ func (r *CustomerRepository) GetTopCustomersWithWonStats(ctx context.Context, fr
 		Order("won_offer_count DESC, won_offer_value DESC").
 		Limit(limit)
 
-	// Apply company filter on offers table
+	// Apply company filter and customer scope on offers table
 	query = ApplyCompanyFilterWithAlias(ctx, query, "offers")
+	query = ApplyCustomerScopeWithAlias(ctx, query, "offers")
 
 	if err := query.Scan(&results).Error; err != nil {
 		return nil, fmt.Errorf("failed to get top customers with won stats: %w", err)
@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/straye-as/relation-api/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CustomerTierRuleRepository handles data access for customer tier rules
+type CustomerTierRuleRepository struct {
+	db *gorm.DB
+}
+
+// NewCustomerTierRuleRepository creates a new customer tier rule repository instance
+func NewCustomerTierRuleRepository(db *gorm.DB) *CustomerTierRuleRepository {
+	return &CustomerTierRuleRepository{db: db}
+}
+
+// List returns the tier rules for all configured companies
+func (r *CustomerTierRuleRepository) List(ctx context.Context) ([]domain.CustomerTierRule, error) {
+	var rules []domain.CustomerTierRule
+	err := r.db.WithContext(ctx).Order("company_id ASC").Find(&rules).Error
+	return rules, err
+}
+
+// GetByCompanyID retrieves the tier rule for a company
+func (r *CustomerTierRuleRepository) GetByCompanyID(ctx context.Context, companyID domain.CompanyID) (*domain.CustomerTierRule, error) {
+	var rule domain.CustomerTierRule
+	err := r.db.WithContext(ctx).Where("company_id = ?", companyID).First(&rule).Error
+	if err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// Save creates or replaces the tier rule for a company
+func (r *CustomerTierRuleRepository) Save(ctx context.Context, rule *domain.CustomerTierRule) error {
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "company_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{
+				"metric", "window_years", "silver_threshold", "gold_threshold", "platinum_threshold",
+				"updated_at", "updated_by_id", "updated_by_name",
+			}),
+		}).
+		Create(rule).Error
+}
+
+// Delete removes the tier rule for a company
+func (r *CustomerTierRuleRepository) Delete(ctx context.Context, companyID domain.CompanyID) error {
+	result := r.db.WithContext(ctx).Where("company_id = ?", companyID).Delete(&domain.CustomerTierRule{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
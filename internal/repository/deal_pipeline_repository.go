@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/straye-as/relation-api/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DealPipelineRepository handles data access for deal pipelines and their stages
+type DealPipelineRepository struct {
+	db *gorm.DB
+}
+
+// NewDealPipelineRepository creates a new deal pipeline repository instance
+func NewDealPipelineRepository(db *gorm.DB) *DealPipelineRepository {
+	return &DealPipelineRepository{db: db}
+}
+
+// orderPipelineStages loads pipeline stages in pipeline order
+func orderPipelineStages(db *gorm.DB) *gorm.DB {
+	return db.Order("sort_order ASC")
+}
+
+// List returns the pipelines visible to the user, optionally for a single company
+func (r *DealPipelineRepository) List(ctx context.Context, companyID *domain.CompanyID) ([]domain.DealPipeline, error) {
+	var pipelines []domain.DealPipeline
+	query := r.db.WithContext(ctx).Preload("Stages", orderPipelineStages)
+	if companyID != nil {
+		query = query.Where("company_id = ?", *companyID)
+	}
+	query = ApplyCompanyFilter(ctx, query)
+	err := query.Order("company_id ASC, is_default DESC, name ASC").Find(&pipelines).Error
+	return pipelines, err
+}
+
+// GetByID retrieves a pipeline with its stages
+func (r *DealPipelineRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.DealPipeline, error) {
+	var pipeline domain.DealPipeline
+	query := r.db.WithContext(ctx).Preload("Stages", orderPipelineStages).Where("id = ?", id)
+	query = ApplyCompanyFilter(ctx, query)
+	if err := query.First(&pipeline).Error; err != nil {
+		return nil, err
+	}
+	return &pipeline, nil
+}
+
+// GetDefault retrieves the default pipeline of a company
+func (r *DealPipelineRepository) GetDefault(ctx context.Context, companyID domain.CompanyID) (*domain.DealPipeline, error) {
+	var pipeline domain.DealPipeline
+	err := r.db.WithContext(ctx).
+		Preload("Stages", orderPipelineStages).
+		Where("company_id = ? AND is_default = ?", companyID, true).
+		First(&pipeline).Error
+	if err != nil {
+		return nil, err
+	}
+	return &pipeline, nil
+}
+
+// Create stores a pipeline with its stages. A default pipeline replaces the company's previous default.
+func (r *DealPipelineRepository) Create(ctx context.Context, pipeline *domain.DealPipeline) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if pipeline.IsDefault {
+			if err := clearDefaultPipeline(tx, pipeline.CompanyID, uuid.Nil); err != nil {
+				return err
+			}
+		}
+		return tx.Create(pipeline).Error
+	})
+}
+
+// Update saves the pipeline fields and replaces its stages
+func (r *DealPipelineRepository) Update(ctx context.Context, pipeline *domain.DealPipeline) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if pipeline.IsDefault {
+			if err := clearDefaultPipeline(tx, pipeline.CompanyID, pipeline.ID); err != nil {
+				return err
+			}
+		}
+
+		pipeline.UpdatedAt = time.Now()
+		err := tx.Model(&domain.DealPipeline{}).Where("id = ?", pipeline.ID).Updates(map[string]interface{}{
+			"name":        pipeline.Name,
+			"description": pipeline.Description,
+			"is_default":  pipeline.IsDefault,
+			"updated_at":  pipeline.UpdatedAt,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Where("pipeline_id = ?", pipeline.ID).Delete(&domain.DealPipelineStage{}).Error; err != nil {
+			return err
+		}
+		for i := range pipeline.Stages {
+			pipeline.Stages[i].ID = uuid.Nil
+			pipeline.Stages[i].PipelineID = pipeline.ID
+		}
+		return tx.Omit(clause.Associations).Create(&pipeline.Stages).Error
+	})
+}
+
+// clearDefaultPipeline unsets the default flag on the company's other pipelines
+func clearDefaultPipeline(tx *gorm.DB, companyID domain.CompanyID, exceptID uuid.UUID) error {
+	return tx.Model(&domain.DealPipeline{}).
+		Where("company_id = ? AND is_default = ? AND id <> ?", companyID, true, exceptID).
+		Update("is_default", false).Error
+}
+
+// Delete removes a pipeline and its stages
+func (r *DealPipelineRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	result := r.db.WithContext(ctx).Where("id = ?", id).Delete(&domain.DealPipeline{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// CountDeals returns the number of deals in a pipeline
+func (r *DealPipelineRepository) CountDeals(ctx context.Context, pipelineID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&domain.Deal{}).Where("pipeline_id = ?", pipelineID).Count(&count).Error
+	return count, err
+}
+
+// StagesInUse returns the stages that deals in the pipeline are currently in
+func (r *DealPipelineRepository) StagesInUse(ctx context.Context, pipelineID uuid.UUID) ([]domain.DealStage, error) {
+	var stages []domain.DealStage
+	err := r.db.WithContext(ctx).Model(&domain.Deal{}).
+		Where("pipeline_id = ?", pipelineID).
+		Distinct().
+		Pluck("stage", &stages).Error
+	return stages, err
+}
@@ -29,6 +29,7 @@ type DealFilters struct {
 	IsWon         *bool
 	IsLost        *bool
 	SearchQuery   *string
+	Stale         *bool
 }
 
 // DealSortOption represents available sort options
@@ -123,11 +124,23 @@ func (r *DealRepository) GetByStage(ctx context.Context, stage domain.DealStage)
 
 // GetPipelineOverview returns deals grouped by stage for full pipeline view
 func (r *DealRepository) GetPipelineOverview(ctx context.Context) (map[domain.DealStage][]domain.Deal, error) {
+	return r.pipelineOverview(ctx, nil)
+}
+
+// GetPipelineOverviewByPipeline returns the open deals of a single pipeline grouped by stage
+func (r *DealRepository) GetPipelineOverviewByPipeline(ctx context.Context, pipelineID uuid.UUID) (map[domain.DealStage][]domain.Deal, error) {
+	return r.pipelineOverview(ctx, &pipelineID)
+}
+
+func (r *DealRepository) pipelineOverview(ctx context.Context, pipelineID *uuid.UUID) (map[domain.DealStage][]domain.Deal, error) {
 	var deals []domain.Deal
 	query := r.db.WithContext(ctx).
 		Preload("Customer").
 		Preload("Company").
 		Where("stage NOT IN ?", []domain.DealStage{domain.DealStageWon, domain.DealStageLost})
+	if pipelineID != nil {
+		query = query.Where("pipeline_id = ?", *pipelineID)
+	}
 	query = ApplyCompanyFilter(ctx, query)
 	err := query.Order("stage, weighted_value DESC").Find(&deals).Error
 	if err != nil {
@@ -188,6 +201,15 @@ func (r *DealRepository) GetWeightedPipelineValue(ctx context.Context) (float64,
 
 // GetPipelineStats returns aggregated statistics for the sales pipeline
 func (r *DealRepository) GetPipelineStats(ctx context.Context) (*PipelineStats, error) {
+	return r.pipelineStats(ctx, nil)
+}
+
+// GetPipelineStatsByPipeline returns aggregated statistics for the open deals of a single pipeline
+func (r *DealRepository) GetPipelineStatsByPipeline(ctx context.Context, pipelineID uuid.UUID) (*PipelineStats, error) {
+	return r.pipelineStats(ctx, &pipelineID)
+}
+
+func (r *DealRepository) pipelineStats(ctx context.Context, pipelineID *uuid.UUID) (*PipelineStats, error) {
 	stats := &PipelineStats{
 		ByStage: make(map[domain.DealStage]StageStats),
 	}
@@ -205,6 +227,9 @@ func (r *DealRepository) GetPipelineStats(ctx context.Context) (*PipelineStats,
 		Select("stage, COUNT(*) as count, COALESCE(SUM(value), 0) as total_value, COALESCE(SUM(weighted_value), 0) as weighted_value").
 		Where("stage NOT IN ?", []domain.DealStage{domain.DealStageWon, domain.DealStageLost}).
 		Group("stage")
+	if pipelineID != nil {
+		query = query.Where("pipeline_id = ?", *pipelineID)
+	}
 	query = ApplyCompanyFilter(ctx, query)
 	if err := query.Scan(&results).Error; err != nil {
 		return nil, err
@@ -306,23 +331,26 @@ func (r *DealRepository) UpdateStage(ctx context.Context, id uuid.UUID, stage do
 	return r.db.WithContext(ctx).Model(&domain.Deal{}).Where("id = ?", id).Updates(updates).Error
 }
 
-// MarkAsWon marks a deal as won with the close date
+// MarkAsWon marks a deal as won with the close date, keeping the entered probability in probability_at_close
 func (r *DealRepository) MarkAsWon(ctx context.Context, id uuid.UUID, closeDate time.Time) error {
 	updates := map[string]interface{}{
-		"stage":             domain.DealStageWon,
-		"actual_close_date": closeDate,
-		"probability":       100,
-		"updated_at":        time.Now(),
+		"stage":                domain.DealStageWon,
+		"actual_close_date":    closeDate,
+		"probability":          100,
+		"probability_at_close": gorm.Expr("probability"),
+		"updated_at":           time.Now(),
 	}
 	return r.db.WithContext(ctx).Model(&domain.Deal{}).Where("id = ?", id).Updates(updates).Error
 }
 
-// MarkAsLost marks a deal as lost with the close date, reason category, and notes
+// MarkAsLost marks a deal as lost with the close date, reason category, and notes,
+// keeping the entered probability in probability_at_close
 func (r *DealRepository) MarkAsLost(ctx context.Context, id uuid.UUID, closeDate time.Time, reasonCategory domain.LossReasonCategory, notes string) error {
 	updates := map[string]interface{}{
 		"stage":                domain.DealStageLost,
 		"actual_close_date":    closeDate,
 		"probability":          0,
+		"probability_at_close": gorm.Expr("probability"),
 		"loss_reason_category": reasonCategory,
 		"lost_reason":          notes,
 		"updated_at":           time.Now(),
@@ -451,6 +479,10 @@ func (r *DealRepository) applyFilters(query *gorm.DB, filters *DealFilters) *gor
 		query = query.Where("LOWER(title) LIKE ? OR LOWER(customer_name) LIKE ?", searchPattern, searchPattern)
 	}
 
+	if filters.Stale != nil {
+		query = applyDealStaleFilter(query, *filters.Stale)
+	}
+
 	return query
 }
 
@@ -663,17 +695,20 @@ type ConversionRateResult struct {
 	ConversionRate float64
 }
 
-// GetConversionRates calculates stage-to-stage conversion rates from deal_stage_history
-func (r *DealRepository) GetConversionRates(ctx context.Context, companyID *domain.CompanyID) ([]ConversionRateResult, error) {
+// GetConversionRates calculates stage-to-stage conversion rates from deal_stage_history.
+// stages is the ordered path through the pipeline (its open stages followed by won); a rate is
+// calculated for each consecutive pair. pipelineID optionally limits the rates to deals in one pipeline.
+func (r *DealRepository) GetConversionRates(ctx context.Context, companyID *domain.CompanyID, pipelineID *uuid.UUID, stages []domain.DealStage) ([]ConversionRateResult, error) {
 	// Define the key conversion stages to track
-	conversions := []struct {
+	var conversions []struct {
 		from domain.DealStage
 		to   domain.DealStage
-	}{
-		{domain.DealStageLead, domain.DealStageQualified},
-		{domain.DealStageQualified, domain.DealStageProposal},
-		{domain.DealStageProposal, domain.DealStageNegotiation},
-		{domain.DealStageNegotiation, domain.DealStageWon},
+	}
+	for i := 1; i < len(stages); i++ {
+		conversions = append(conversions, struct {
+			from domain.DealStage
+			to   domain.DealStage
+		}{stages[i-1], stages[i]})
 	}
 
 	var results []ConversionRateResult
@@ -694,6 +729,9 @@ func (r *DealRepository) GetConversionRates(ctx context.Context, companyID *doma
 		if companyID != nil {
 			fromQuery = fromQuery.Where("deals.company_id = ?", *companyID)
 		}
+		if pipelineID != nil {
+			fromQuery = fromQuery.Where("deals.pipeline_id = ?", *pipelineID)
+		}
 
 		fromQuery.Scan(&totalFromStage)
 
@@ -712,6 +750,9 @@ func (r *DealRepository) GetConversionRates(ctx context.Context, companyID *doma
 		if companyID != nil {
 			toQuery = toQuery.Where("deals.company_id = ?", *companyID)
 		}
+		if pipelineID != nil {
+			toQuery = toQuery.Where("deals.pipeline_id = ?", *pipelineID)
+		}
 
 		toQuery.Scan(&transitioned)
 
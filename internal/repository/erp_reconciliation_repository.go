@@ -0,0 +1,184 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/straye-as/relation-api/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ERPReconciliationDecisionFilters filters reconciliation decisions
+type ERPReconciliationDecisionFilters struct {
+	Status     *domain.ERPReconciliationDecisionStatus
+	Type       *domain.ERPReconciliationDecisionType
+	RunID      *uuid.UUID
+	CustomerID *uuid.UUID
+}
+
+// ERPReconciliationRepository handles data access for ERP customer reconciliation runs,
+// decisions and field policies
+type ERPReconciliationRepository struct {
+	db *gorm.DB
+}
+
+// NewERPReconciliationRepository creates a new ERP reconciliation repository instance
+func NewERPReconciliationRepository(db *gorm.DB) *ERPReconciliationRepository {
+	return &ERPReconciliationRepository{db: db}
+}
+
+// CreateRun creates a new reconciliation run
+func (r *ERPReconciliationRepository) CreateRun(ctx context.Context, run *domain.ERPReconciliationRun) error {
+	return r.db.WithContext(ctx).Create(run).Error
+}
+
+// UpdateRun updates an existing reconciliation run
+func (r *ERPReconciliationRepository) UpdateRun(ctx context.Context, run *domain.ERPReconciliationRun) error {
+	return r.db.WithContext(ctx).Save(run).Error
+}
+
+// GetRunByID retrieves a reconciliation run by its ID
+func (r *ERPReconciliationRepository) GetRunByID(ctx context.Context, id uuid.UUID) (*domain.ERPReconciliationRun, error) {
+	var run domain.ERPReconciliationRun
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&run).Error
+	if err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
+
+// GetLatestRun retrieves the most recently started reconciliation run
+func (r *ERPReconciliationRepository) GetLatestRun(ctx context.Context) (*domain.ERPReconciliationRun, error) {
+	var run domain.ERPReconciliationRun
+	err := r.db.WithContext(ctx).Order("started_at DESC").First(&run).Error
+	if err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
+
+// ListRuns returns reconciliation runs, newest first
+func (r *ERPReconciliationRepository) ListRuns(ctx context.Context, page, pageSize int) ([]domain.ERPReconciliationRun, int64, error) {
+	var runs []domain.ERPReconciliationRun
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&domain.ERPReconciliationRun{})
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	err := query.
+		Order("started_at DESC").
+		Offset(offset).
+		Limit(pageSize).
+		Find(&runs).Error
+
+	return runs, total, err
+}
+
+// CreateDecision creates a new reconciliation decision
+func (r *ERPReconciliationRepository) CreateDecision(ctx context.Context, decision *domain.ERPReconciliationDecision) error {
+	return r.db.WithContext(ctx).Create(decision).Error
+}
+
+// UpdateDecision updates an existing reconciliation decision
+func (r *ERPReconciliationRepository) UpdateDecision(ctx context.Context, decision *domain.ERPReconciliationDecision) error {
+	return r.db.WithContext(ctx).Save(decision).Error
+}
+
+// GetDecisionByID retrieves a reconciliation decision by its ID
+func (r *ERPReconciliationRepository) GetDecisionByID(ctx context.Context, id uuid.UUID) (*domain.ERPReconciliationDecision, error) {
+	var decision domain.ERPReconciliationDecision
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&decision).Error
+	if err != nil {
+		return nil, err
+	}
+	return &decision, nil
+}
+
+// ListDecisions returns reconciliation decisions matching the filters, newest first
+func (r *ERPReconciliationRepository) ListDecisions(ctx context.Context, filters *ERPReconciliationDecisionFilters, page, pageSize int) ([]domain.ERPReconciliationDecision, int64, error) {
+	var decisions []domain.ERPReconciliationDecision
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&domain.ERPReconciliationDecision{})
+	if filters != nil {
+		if filters.Status != nil {
+			query = query.Where("status = ?", *filters.Status)
+		}
+		if filters.Type != nil {
+			query = query.Where("type = ?", *filters.Type)
+		}
+		if filters.RunID != nil {
+			query = query.Where("run_id = ?", *filters.RunID)
+		}
+		if filters.CustomerID != nil {
+			query = query.Where("customer_id = ?", *filters.CustomerID)
+		}
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	err := query.
+		Order("created_at DESC").
+		Offset(offset).
+		Limit(pageSize).
+		Find(&decisions).Error
+
+	return decisions, total, err
+}
+
+// ListDecisionsByStatus returns all decisions with the given status
+func (r *ERPReconciliationRepository) ListDecisionsByStatus(ctx context.Context, status domain.ERPReconciliationDecisionStatus) ([]domain.ERPReconciliationDecision, error) {
+	var decisions []domain.ERPReconciliationDecision
+	err := r.db.WithContext(ctx).
+		Where("status = ?", status).
+		Order("created_at ASC").
+		Find(&decisions).Error
+	return decisions, err
+}
+
+// CountPendingByType counts pending decisions per decision type
+func (r *ERPReconciliationRepository) CountPendingByType(ctx context.Context) (map[domain.ERPReconciliationDecisionType]int, error) {
+	var rows []struct {
+		Type  domain.ERPReconciliationDecisionType
+		Count int
+	}
+	err := r.db.WithContext(ctx).
+		Model(&domain.ERPReconciliationDecision{}).
+		Select("type, COUNT(*) as count").
+		Where("status = ?", domain.ERPReconciliationDecisionPending).
+		Group("type").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[domain.ERPReconciliationDecisionType]int, len(rows))
+	for _, row := range rows {
+		counts[row.Type] = row.Count
+	}
+	return counts, nil
+}
+
+// ListPolicies returns the drift policies for all configured fields
+func (r *ERPReconciliationRepository) ListPolicies(ctx context.Context) ([]domain.ERPReconciliationFieldPolicy, error) {
+	var policies []domain.ERPReconciliationFieldPolicy
+	err := r.db.WithContext(ctx).Order("field ASC").Find(&policies).Error
+	return policies, err
+}
+
+// SavePolicy creates or replaces the drift policy for a field
+func (r *ERPReconciliationRepository) SavePolicy(ctx context.Context, policy *domain.ERPReconciliationFieldPolicy) error {
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "field"}},
+			DoUpdates: clause.AssignmentColumns([]string{"policy", "updated_at", "updated_by_id", "updated_by_name"}),
+		}).
+		Create(policy).Error
+}
@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/straye-as/relation-api/internal/domain"
+	"gorm.io/gorm"
+)
+
+// ImportRepository handles data access for import jobs and saved mapping profiles
+type ImportRepository struct {
+	db *gorm.DB
+}
+
+// NewImportRepository creates a new import repository instance
+func NewImportRepository(db *gorm.DB) *ImportRepository {
+	return &ImportRepository{db: db}
+}
+
+// CreateJob creates a new import job
+func (r *ImportRepository) CreateJob(ctx context.Context, job *domain.ImportJob) error {
+	return r.db.WithContext(ctx).Create(job).Error
+}
+
+// GetJobByID retrieves an import job by its ID
+func (r *ImportRepository) GetJobByID(ctx context.Context, id uuid.UUID) (*domain.ImportJob, error) {
+	var job domain.ImportJob
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&job).Error
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// UpdateJob updates an existing import job
+func (r *ImportRepository) UpdateJob(ctx context.Context, job *domain.ImportJob) error {
+	return r.db.WithContext(ctx).Save(job).Error
+}
+
+// DeleteJob deletes an import job
+func (r *ImportRepository) DeleteJob(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Delete(&domain.ImportJob{}, "id = ?", id).Error
+}
+
+// ListJobs returns import jobs, newest first, optionally filtered by entity type.
+// The stored rows are not loaded.
+func (r *ImportRepository) ListJobs(ctx context.Context, entityType *domain.ImportEntityType, page, pageSize int) ([]domain.ImportJob, int64, error) {
+	var jobs []domain.ImportJob
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&domain.ImportJob{})
+	if entityType != nil {
+		query = query.Where("entity_type = ?", *entityType)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	err := query.
+		Omit("rows").
+		Order("created_at DESC").
+		Offset(offset).
+		Limit(pageSize).
+		Find(&jobs).Error
+
+	return jobs, total, err
+}
+
+// CreateProfile creates a new mapping profile
+func (r *ImportRepository) CreateProfile(ctx context.Context, profile *domain.ImportMappingProfile) error {
+	return r.db.WithContext(ctx).Create(profile).Error
+}
+
+// GetProfileByID retrieves a mapping profile by its ID
+func (r *ImportRepository) GetProfileByID(ctx context.Context, id uuid.UUID) (*domain.ImportMappingProfile, error) {
+	var profile domain.ImportMappingProfile
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&profile).Error
+	if err != nil {
+		return nil, err
+	}
+	return &profile, nil
+}
+
+// UpdateProfile updates an existing mapping profile
+func (r *ImportRepository) UpdateProfile(ctx context.Context, profile *domain.ImportMappingProfile) error {
+	return r.db.WithContext(ctx).Save(profile).Error
+}
+
+// DeleteProfile deletes a mapping profile
+func (r *ImportRepository) DeleteProfile(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Delete(&domain.ImportMappingProfile{}, "id = ?", id).Error
+}
+
+// ListProfiles returns mapping profiles ordered by name, optionally filtered by entity type
+func (r *ImportRepository) ListProfiles(ctx context.Context, entityType *domain.ImportEntityType) ([]domain.ImportMappingProfile, error) {
+	var profiles []domain.ImportMappingProfile
+	query := r.db.WithContext(ctx)
+	if entityType != nil {
+		query = query.Where("entity_type = ?", *entityType)
+	}
+	err := query.Order("name ASC").Find(&profiles).Error
+	return profiles, err
+}
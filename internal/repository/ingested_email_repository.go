@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/straye-as/relation-api/internal/auth"
+	"github.com/straye-as/relation-api/internal/domain"
+	"gorm.io/gorm"
+)
+
+// IngestedEmailRepository handles data access for ingested emails
+type IngestedEmailRepository struct {
+	db *gorm.DB
+}
+
+// NewIngestedEmailRepository creates a new ingested email repository instance
+func NewIngestedEmailRepository(db *gorm.DB) *IngestedEmailRepository {
+	return &IngestedEmailRepository{db: db}
+}
+
+// Create records an ingested email
+func (r *IngestedEmailRepository) Create(ctx context.Context, email *domain.IngestedEmail) error {
+	return r.db.WithContext(ctx).Create(email).Error
+}
+
+// GetByMessageID retrieves an ingested email by its Message-ID.
+// The company filter is not applied, so an email is only ingested once across companies.
+func (r *IngestedEmailRepository) GetByMessageID(ctx context.Context, messageID string) (*domain.IngestedEmail, error) {
+	var email domain.IngestedEmail
+	err := r.db.WithContext(ctx).Where("message_id = ?", messageID).First(&email).Error
+	if err != nil {
+		return nil, err
+	}
+	return &email, nil
+}
+
+// List returns ingested emails, newest first, optionally filtered by status.
+// Unmatched emails have no company and are visible to all companies.
+func (r *IngestedEmailRepository) List(ctx context.Context, status *domain.EmailIngestionStatus, page, pageSize int) ([]domain.IngestedEmail, int64, error) {
+	var emails []domain.IngestedEmail
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&domain.IngestedEmail{})
+	if companyID := auth.GetEffectiveCompanyFilter(ctx); companyID != nil {
+		query = query.Where("company_id = ? OR company_id IS NULL", *companyID)
+	}
+	if status != nil {
+		query = query.Where("status = ?", *status)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	err := query.Order("created_at DESC").Offset(offset).Limit(pageSize).Find(&emails).Error
+	return emails, total, err
+}
@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/straye-as/relation-api/internal/domain"
+	"gorm.io/gorm"
+)
+
+// InquiryAssignmentRuleRepository handles data access for inquiry assignment rules
+type InquiryAssignmentRuleRepository struct {
+	db *gorm.DB
+}
+
+// NewInquiryAssignmentRuleRepository creates a new inquiry assignment rule repository instance
+func NewInquiryAssignmentRuleRepository(db *gorm.DB) *InquiryAssignmentRuleRepository {
+	return &InquiryAssignmentRuleRepository{db: db}
+}
+
+// List returns the assignment rules, optionally for one company, in evaluation order
+func (r *InquiryAssignmentRuleRepository) List(ctx context.Context, companyID *domain.CompanyID) ([]domain.InquiryAssignmentRule, error) {
+	var rules []domain.InquiryAssignmentRule
+	query := r.db.WithContext(ctx).Model(&domain.InquiryAssignmentRule{})
+	if companyID != nil {
+		query = query.Where("company_id = ?", *companyID)
+	}
+	query = ApplyCompanyFilter(ctx, query)
+	err := query.Order("company_id ASC, priority ASC, created_at ASC").Find(&rules).Error
+	return rules, err
+}
+
+// ListEnabledForCompany returns the enabled rules of a company in evaluation order.
+// No company filter applies: inquiries are assigned for the company they belong to, which may
+// differ from the user's company (e.g. website inquiries and conversions to another company).
+func (r *InquiryAssignmentRuleRepository) ListEnabledForCompany(ctx context.Context, companyID domain.CompanyID) ([]domain.InquiryAssignmentRule, error) {
+	var rules []domain.InquiryAssignmentRule
+	err := r.db.WithContext(ctx).
+		Where("company_id = ? AND enabled = ?", companyID, true).
+		Order("priority ASC, created_at ASC").
+		Find(&rules).Error
+	return rules, err
+}
+
+// GetByID retrieves an assignment rule
+func (r *InquiryAssignmentRuleRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.InquiryAssignmentRule, error) {
+	var rule domain.InquiryAssignmentRule
+	query := r.db.WithContext(ctx).Where("id = ?", id)
+	query = ApplyCompanyFilter(ctx, query)
+	if err := query.First(&rule).Error; err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// Create stores a new assignment rule
+func (r *InquiryAssignmentRuleRepository) Create(ctx context.Context, rule *domain.InquiryAssignmentRule) error {
+	return r.db.WithContext(ctx).Create(rule).Error
+}
+
+// Update saves the conditions and assignees of a rule. Changing the assignees restarts the rotation.
+func (r *InquiryAssignmentRuleRepository) Update(ctx context.Context, rule *domain.InquiryAssignmentRule) error {
+	rule.UpdatedAt = time.Now()
+	return r.db.WithContext(ctx).Model(&domain.InquiryAssignmentRule{}).Where("id = ?", rule.ID).Updates(map[string]interface{}{
+		"name":                 rule.Name,
+		"rule_type":            rule.RuleType,
+		"priority":             rule.Priority,
+		"enabled":              rule.Enabled,
+		"industries":           rule.Industries,
+		"postal_code_prefixes": rule.PostalCodePrefixes,
+		"counties":             rule.Counties,
+		"min_value":            rule.MinValue,
+		"max_value":            rule.MaxValue,
+		"assignee_ids":         rule.AssigneeIDs,
+		"next_index":           rule.NextIndex,
+		"updated_at":           rule.UpdatedAt,
+	}).Error
+}
+
+// Delete removes an assignment rule
+func (r *InquiryAssignmentRuleRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	result := r.db.WithContext(ctx).Where("id = ?", id).Delete(&domain.InquiryAssignmentRule{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// NextAssigneeIndex advances the round-robin cursor of a rule and returns its previous value.
+// The update is atomic, so concurrent inquiries go to different assignees.
+func (r *InquiryAssignmentRuleRepository) NextAssigneeIndex(ctx context.Context, id uuid.UUID) (int, error) {
+	var index int
+	err := r.db.WithContext(ctx).Raw(
+		`UPDATE inquiry_assignment_rules SET next_index = next_index + 1, updated_at = updated_at
+		WHERE id = ? RETURNING next_index - 1`, id).Scan(&index).Error
+	return index, err
+}
@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/straye-as/relation-api/internal/domain"
+	"gorm.io/gorm"
+)
+
+// InquiryIntakeRepository handles data access for website forms and the inquiries submitted through them
+type InquiryIntakeRepository struct {
+	db *gorm.DB
+}
+
+// NewInquiryIntakeRepository creates a new inquiry intake repository instance
+func NewInquiryIntakeRepository(db *gorm.DB) *InquiryIntakeRepository {
+	return &InquiryIntakeRepository{db: db}
+}
+
+// ListSites returns the website forms, optionally for one company
+func (r *InquiryIntakeRepository) ListSites(ctx context.Context, companyID *domain.CompanyID) ([]domain.InquiryIntakeSite, error) {
+	var sites []domain.InquiryIntakeSite
+	query := r.db.WithContext(ctx).Model(&domain.InquiryIntakeSite{})
+	if companyID != nil {
+		query = query.Where("company_id = ?", *companyID)
+	}
+	query = ApplyCompanyFilter(ctx, query)
+	err := query.Order("company_id ASC, name ASC").Find(&sites).Error
+	return sites, err
+}
+
+// GetSiteByID retrieves a website form
+func (r *InquiryIntakeRepository) GetSiteByID(ctx context.Context, id uuid.UUID) (*domain.InquiryIntakeSite, error) {
+	var site domain.InquiryIntakeSite
+	query := r.db.WithContext(ctx).Where("id = ?", id)
+	query = ApplyCompanyFilter(ctx, query)
+	if err := query.First(&site).Error; err != nil {
+		return nil, err
+	}
+	return &site, nil
+}
+
+// GetSiteByKey retrieves an enabled website form by its public site key.
+// Used by the public endpoint, so no company filter applies.
+func (r *InquiryIntakeRepository) GetSiteByKey(ctx context.Context, siteKey string) (*domain.InquiryIntakeSite, error) {
+	var site domain.InquiryIntakeSite
+	err := r.db.WithContext(ctx).Where("site_key = ? AND enabled = ?", siteKey, true).First(&site).Error
+	if err != nil {
+		return nil, err
+	}
+	return &site, nil
+}
+
+// CreateSite stores a new website form
+func (r *InquiryIntakeRepository) CreateSite(ctx context.Context, site *domain.InquiryIntakeSite) error {
+	return r.db.WithContext(ctx).Create(site).Error
+}
+
+// UpdateSite saves the settings of a website form
+func (r *InquiryIntakeRepository) UpdateSite(ctx context.Context, site *domain.InquiryIntakeSite) error {
+	site.UpdatedAt = time.Now()
+	return r.db.WithContext(ctx).Model(&domain.InquiryIntakeSite{}).Where("id = ?", site.ID).Updates(map[string]interface{}{
+		"name":               site.Name,
+		"allowed_origins":    site.AllowedOrigins,
+		"honeypot_field":     site.HoneypotField,
+		"proof_of_work_bits": site.ProofOfWorkBits,
+		"send_confirmation":  site.SendConfirmation,
+		"enabled":            site.Enabled,
+		"updated_at":         site.UpdatedAt,
+	}).Error
+}
+
+// DeleteSite removes a website form and its submission log
+func (r *InquiryIntakeRepository) DeleteSite(ctx context.Context, id uuid.UUID) error {
+	result := r.db.WithContext(ctx).Where("id = ?", id).Delete(&domain.InquiryIntakeSite{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// CreateSubmission records an inquiry received through a website form
+func (r *InquiryIntakeRepository) CreateSubmission(ctx context.Context, submission *domain.InquirySubmission) error {
+	return r.db.WithContext(ctx).Create(submission).Error
+}
+
+// CompleteSubmission saves the inquiry, customer match and attachment count of a claimed submission
+func (r *InquiryIntakeRepository) CompleteSubmission(ctx context.Context, submission *domain.InquirySubmission) error {
+	return r.db.WithContext(ctx).Model(&domain.InquirySubmission{}).Where("id = ?", submission.ID).Updates(map[string]interface{}{
+		"offer_id":         submission.OfferID,
+		"customer_id":      submission.CustomerID,
+		"match_confidence": submission.MatchConfidence,
+		"attachment_count": submission.AttachmentCount,
+		"updated_at":       time.Now(),
+	}).Error
+}
+
+// DeleteSubmission removes a submission, releasing its proof-of-work challenge
+func (r *InquiryIntakeRepository) DeleteSubmission(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Delete(&domain.InquirySubmission{}, "id = ?", id).Error
+}
+
+// MarkConfirmationSent records when the submitter was sent a confirmation
+func (r *InquiryIntakeRepository) MarkConfirmationSent(ctx context.Context, id uuid.UUID, sentAt time.Time) error {
+	return r.db.WithContext(ctx).Model(&domain.InquirySubmission{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"confirmation_sent_at": sentAt,
+		"updated_at":           time.Now(),
+	}).Error
+}
+
+// ListSubmissions returns the submissions of a website form, newest first
+func (r *InquiryIntakeRepository) ListSubmissions(ctx context.Context, siteID uuid.UUID, includeSpam bool, page, pageSize int) ([]domain.InquirySubmission, int64, error) {
+	var submissions []domain.InquirySubmission
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&domain.InquirySubmission{}).Where("site_id = ?", siteID)
+	if !includeSpam {
+		query = query.Where("spam = ?", false)
+	}
+	query = ApplyCompanyFilter(ctx, query)
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	err := query.Order("created_at DESC").Offset(offset).Limit(pageSize).Find(&submissions).Error
+	return submissions, total, err
+}
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/straye-as/relation-api/internal/domain"
+)
+
+// inquiryFirstResponseSQL is when the customer first got a response to an inquiry: the earliest
+// completed email, call or meeting logged on it by a user. Emails ingested from the mailbox
+// without a signed-in user are the customer writing to us and do not count.
+const inquiryFirstResponseSQL = `(SELECT MIN(a.occurred_at) FROM activities a
+	WHERE a.target_type = 'Offer' AND a.target_id = offers.id
+	AND a.activity_type IN ('email', 'call', 'meeting') AND a.status = 'completed'
+	AND COALESCE(a.creator_id, '') <> ''
+	AND a.occurred_at >= offers.created_at AND a.occurred_at <= NOW())`
+
+// InquirySLARecord holds the SLA clock of one inquiry
+type InquirySLARecord struct {
+	ID                  uuid.UUID
+	Title               string
+	CompanyID           domain.CompanyID
+	ResponsibleUserID   string
+	ResponsibleUserName string
+	CreatedAt           time.Time
+	DueAt               time.Time
+	// RespondedAt is the earlier of the conversion and the first customer-facing activity, nil while unanswered
+	RespondedAt *time.Time
+}
+
+// ListInquirySLARecords returns the SLA clocks of inquiries received in [from, to), optionally for one company.
+// Inquiries created before SLA tracking have no deadline and are left out. Applies the company filter.
+func (r *OfferRepository) ListInquirySLARecords(ctx context.Context, from, to time.Time, companyID *domain.CompanyID) ([]InquirySLARecord, error) {
+	var records []InquirySLARecord
+	query := r.db.WithContext(ctx).Model(&domain.Offer{}).
+		Select("offers.id, offers.title, offers.company_id, offers.responsible_user_id, offers.responsible_user_name, "+
+			"offers.created_at, offers.inquiry_sla_due_at AS due_at, "+
+			"LEAST(offers.inquiry_responded_at, "+inquiryFirstResponseSQL+") AS responded_at").
+		Where("offers.inquiry_sla_due_at IS NOT NULL").
+		Where("offers.created_at >= ? AND offers.created_at < ?", from, to)
+	query = ApplyCompanyFilterWithColumn(ctx, query, "offers.company_id")
+	if companyID != nil {
+		query = query.Where("offers.company_id = ?", *companyID)
+	}
+	err := query.Order("offers.created_at ASC").Scan(&records).Error
+	return records, err
+}
+
+// ListUnansweredInquiries returns draft inquiries with a responsible user that have neither been
+// answered nor warned about, earliest deadline first
+func (r *OfferRepository) ListUnansweredInquiries(ctx context.Context) ([]domain.Offer, error) {
+	var offers []domain.Offer
+	query := r.db.WithContext(ctx).Model(&domain.Offer{}).
+		Where("offers.phase = ?", domain.OfferPhaseDraft).
+		Where("offers.inquiry_sla_due_at IS NOT NULL AND offers.inquiry_responded_at IS NULL AND offers.inquiry_sla_warned_at IS NULL").
+		Where("COALESCE(offers.responsible_user_id, '') <> ''").
+		Where(inquiryFirstResponseSQL + " IS NULL")
+	query = ApplyCompanyFilterWithColumn(ctx, query, "offers.company_id")
+	err := query.Order("offers.inquiry_sla_due_at ASC").Find(&offers).Error
+	return offers, err
+}
+
+// MarkInquirySLAWarned records that the responsible user was warned about the inquiry's deadline
+func (r *OfferRepository) MarkInquirySLAWarned(ctx context.Context, id uuid.UUID, at time.Time) error {
+	return r.db.WithContext(ctx).Model(&domain.Offer{}).
+		Where("id = ?", id).
+		UpdateColumn("inquiry_sla_warned_at", at).Error
+}
@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/straye-as/relation-api/internal/domain"
+	"gorm.io/gorm"
+)
+
+// CustomerScope selects offers by whether the customer is internal (a Straye group company)
+type CustomerScope string
+
+const (
+	// CustomerScopeAll includes offers to both internal and external customers
+	CustomerScopeAll CustomerScope = "all"
+	// CustomerScopeExternal excludes offers to internal customers, eliminating intercompany sales
+	CustomerScopeExternal CustomerScope = "external"
+	// CustomerScopeInternal only includes offers to internal customers
+	CustomerScopeInternal CustomerScope = "internal"
+)
+
+type customerScopeKey struct{}
+
+// WithCustomerScope returns a context that restricts offer statistics to the given customer scope.
+// Like the company filter, the scope is carried in the context so the statistics queries stay unchanged for callers.
+func WithCustomerScope(ctx context.Context, scope CustomerScope) context.Context {
+	return context.WithValue(ctx, customerScopeKey{}, scope)
+}
+
+// CustomerScopeFromContext returns the customer scope of the context, CustomerScopeAll if none is set
+func CustomerScopeFromContext(ctx context.Context) CustomerScope {
+	if scope, ok := ctx.Value(customerScopeKey{}).(CustomerScope); ok && scope != "" {
+		return scope
+	}
+	return CustomerScopeAll
+}
+
+// customerScopeCondition returns the SQL condition for the customer scope on an offers table alias.
+// Returns an empty string for CustomerScopeAll.
+func customerScopeCondition(scope CustomerScope, alias string) string {
+	column := "customer_id"
+	if alias != "" {
+		column = alias + ".customer_id"
+	}
+	internal := fmt.Sprintf("EXISTS (SELECT 1 FROM customers ic WHERE ic.id = %s AND ic.is_internal)", column)
+
+	switch scope {
+	case CustomerScopeExternal:
+		return "NOT " + internal
+	case CustomerScopeInternal:
+		return internal
+	default:
+		return ""
+	}
+}
+
+// ApplyCustomerScope applies the customer scope of the context to a query on the offers table
+func ApplyCustomerScope(ctx context.Context, query *gorm.DB) *gorm.DB {
+	return ApplyCustomerScopeWithAlias(ctx, query, "")
+}
+
+// ApplyCustomerScopeWithAlias applies the customer scope of the context using an offers table alias
+func ApplyCustomerScopeWithAlias(ctx context.Context, query *gorm.DB, tableAlias string) *gorm.DB {
+	if condition := customerScopeCondition(CustomerScopeFromContext(ctx), tableAlias); condition != "" {
+		return query.Where(condition)
+	}
+	return query
+}
+
+// IntercompanySales holds won offers from one group company to one internal customer
+type IntercompanySales struct {
+	SellerCompanyID   domain.CompanyID
+	BuyerCompanyID    *domain.CompanyID // Group company matched by organization number
+	BuyerCustomerID   uuid.UUID
+	BuyerCustomerName string
+	WonCount          int
+	WonValue          float64
+	Invoiced          float64
+	OrderReserve      float64
+}
+
+// GetIntercompanySales returns won offers (order and completed phases) to internal customers,
+// grouped by selling company and internal customer.
+// Internal customers are matched to the group company with the same organization number.
+func (r *OfferRepository) GetIntercompanySales(ctx context.Context, fromDate, toDate *time.Time) ([]IntercompanySales, error) {
+	var rows []struct {
+		SellerCompanyID   string
+		BuyerCompanyID    *string
+		BuyerCustomerID   uuid.UUID
+		BuyerCustomerName string
+		WonCount          int
+		WonValue          float64
+		Invoiced          float64
+		OrderReserve      float64
+	}
+
+	query := r.db.WithContext(ctx).
+		Table("offers o").
+		Select(`o.company_id AS seller_company_id,
+			bc.id AS buyer_company_id,
+			c.id AS buyer_customer_id,
+			c.name AS buyer_customer_name,
+			COUNT(*) AS won_count,
+			COALESCE(SUM(o.value), 0) AS won_value,
+			COALESCE(SUM(o.invoiced), 0) AS invoiced,
+			COALESCE(SUM(CASE WHEN o.phase = ? THEN o.value - o.invoiced ELSE 0 END), 0) AS order_reserve`, domain.OfferPhaseOrder).
+		Joins("JOIN customers c ON c.id = o.customer_id AND c.is_internal").
+		Joins("LEFT JOIN companies bc ON bc.org_number <> '' AND REPLACE(bc.org_number, ' ', '') = REPLACE(c.org_number, ' ', '')").
+		Where("o.phase IN ?", []domain.OfferPhase{domain.OfferPhaseOrder, domain.OfferPhaseCompleted})
+	if fromDate != nil {
+		query = query.Where("o.created_at >= ?", *fromDate)
+	}
+	if toDate != nil {
+		query = query.Where("o.created_at <= ?", *toDate)
+	}
+	query = ApplyCompanyFilterWithAlias(ctx, query, "o")
+
+	err := query.
+		Group("o.company_id, bc.id, c.id, c.name").
+		Order("o.company_id, c.name").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get intercompany sales: %w", err)
+	}
+
+	sales := make([]IntercompanySales, len(rows))
+	for i, row := range rows {
+		sales[i] = IntercompanySales{
+			SellerCompanyID:   domain.CompanyID(row.SellerCompanyID),
+			BuyerCustomerID:   row.BuyerCustomerID,
+			BuyerCustomerName: strings.TrimSpace(row.BuyerCustomerName),
+			WonCount:          row.WonCount,
+			WonValue:          row.WonValue,
+			Invoiced:          row.Invoiced,
+			OrderReserve:      row.OrderReserve,
+		}
+		if row.BuyerCompanyID != nil {
+			buyer := domain.CompanyID(*row.BuyerCompanyID)
+			sales[i].BuyerCompanyID = &buyer
+		}
+	}
+	return sales, nil
+}
@@ -20,6 +20,11 @@ type OfferFilters struct {
 	ProjectID  *uuid.UUID
 	Phase      *domain.OfferPhase
 	Status     *domain.OfferStatus
+	// CustomerGroupID matches the customer and all its subsidiaries
+	CustomerGroupID *uuid.UUID
+	// Stale filters on staleness using the thresholds in StaleAfterDays
+	Stale          *bool
+	StaleAfterDays OfferStaleThresholds
 }
 
 // offerSortableFields maps API field names to database column names for offers
@@ -165,6 +170,10 @@ func (r *OfferRepository) ListWithFilters(ctx context.Context, page, pageSize in
 			query = query.Where("customer_id = ?", *filters.CustomerID)
 		}
 
+		if filters.CustomerGroupID != nil {
+			query = query.Where("customer_id IN (?)", customerGroupIDsSubquery(r.db.WithContext(ctx), *filters.CustomerGroupID))
+		}
+
 		if filters.ProjectID != nil {
 			query = query.Where("project_id = ?", *filters.ProjectID)
 		}
@@ -176,6 +185,10 @@ func (r *OfferRepository) ListWithFilters(ctx context.Context, page, pageSize in
 		if filters.Status != nil {
 			query = query.Where("status = ?", *filters.Status)
 		}
+
+		if filters.Stale != nil {
+			query = applyOfferStaleFilter(query, *filters.Stale, filters.StaleAfterDays)
+		}
 	}
 
 	if err := query.Count(&total).Error; err != nil {
@@ -267,6 +280,16 @@ func (r *OfferRepository) Search(ctx context.Context, searchQuery string, limit
 	return offers, err
 }
 
+// ExistsForCustomerWithTitle checks if the customer already has an offer with the given title (case-insensitive)
+func (r *OfferRepository) ExistsForCustomerWithTitle(ctx context.Context, customerID uuid.UUID, title string) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&domain.Offer{}).
+		Where("customer_id = ? AND LOWER(title) = LOWER(?)", customerID, strings.TrimSpace(title)).
+		Count(&count).Error
+	return count > 0, err
+}
+
 // UpdateStatus updates only the status field of an offer
 // Returns error if offer not found or update fails
 // Applies company filter for multi-tenant isolation
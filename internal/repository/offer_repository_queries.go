@@ -191,6 +191,17 @@ func (r *OfferRepository) OfferNumberExists(ctx context.Context, offerNumber str
 	return count > 0, nil
 }
 
+// GetByOfferNumber retrieves an offer by its internal offer number, ignoring case
+func (r *OfferRepository) GetByOfferNumber(ctx context.Context, offerNumber string) (*domain.Offer, error) {
+	var offer domain.Offer
+	query := r.db.WithContext(ctx).Where("UPPER(offer_number) = UPPER(?)", offerNumber)
+	query = ApplyCompanyFilter(ctx, query)
+	if err := query.First(&offer).Error; err != nil {
+		return nil, err
+	}
+	return &offer, nil
+}
+
 // SetExternalReference sets the external reference for an offer
 func (r *OfferRepository) SetExternalReference(ctx context.Context, id uuid.UUID, externalReference string) error {
 	return r.UpdateField(ctx, id, "external_reference", externalReference)
@@ -412,6 +423,18 @@ func (r *OfferRepository) UpdateProjectNameByProjectID(ctx context.Context, proj
 	return nil
 }
 
+// ListWonByProject returns the offers of a project the customer has accepted (order or completed),
+// largest first. No company filter - projects are cross-company.
+func (r *OfferRepository) ListWonByProject(ctx context.Context, projectID uuid.UUID) ([]domain.Offer, error) {
+	var offers []domain.Offer
+	err := r.db.WithContext(ctx).
+		Where("project_id = ?", projectID).
+		Where("phase IN ?", []domain.OfferPhase{domain.OfferPhaseOrder, domain.OfferPhaseCompleted}).
+		Order("value DESC, created_at ASC").
+		Find(&offers).Error
+	return offers, err
+}
+
 // GetBestOfferForProject returns the "best" offer for a project based on priority:
 // 1. Completed offer (if any exists)
 // 2. Order offer (if any exists)
@@ -596,3 +619,21 @@ func (r *OfferRepository) DeleteOfferSupplier(ctx context.Context, offerID, supp
 	}
 	return nil
 }
+
+// ============================================================================
+// Calendar Methods
+// ============================================================================
+
+// ListDeadlinesForResponsible returns open offers (draft, in progress or sent) a user is responsible
+// for with a due date or expiration date within [from, to). The company filter is not applied since
+// calendar feeds are fetched without a user context.
+func (r *OfferRepository) ListDeadlinesForResponsible(ctx context.Context, userID string, from, to time.Time) ([]domain.Offer, error) {
+	var offers []domain.Offer
+	err := r.db.WithContext(ctx).
+		Where("responsible_user_id = ?", userID).
+		Where("phase IN ?", []domain.OfferPhase{domain.OfferPhaseDraft, domain.OfferPhaseInProgress, domain.OfferPhaseSent}).
+		Where("((due_date >= ? AND due_date < ?) OR (expiration_date >= ? AND expiration_date < ?))", from, to, from, to).
+		Order("updated_at DESC").
+		Find(&offers).Error
+	return offers, err
+}
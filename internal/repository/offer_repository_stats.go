@@ -347,6 +347,7 @@ func (r *OfferRepository) GetDashboardWinRateStats(ctx context.Context, fromDate
 		wonQuery = wonQuery.Where("created_at <= ?", *toDate)
 	}
 	wonQuery = ApplyCompanyFilter(ctx, wonQuery)
+	wonQuery = ApplyCustomerScope(ctx, wonQuery)
 
 	var wonResult struct {
 		Count      int64
@@ -368,6 +369,7 @@ func (r *OfferRepository) GetDashboardWinRateStats(ctx context.Context, fromDate
 		lostQuery = lostQuery.Where("created_at <= ?", *toDate)
 	}
 	lostQuery = ApplyCompanyFilter(ctx, lostQuery)
+	lostQuery = ApplyCustomerScope(ctx, lostQuery)
 
 	var lostResult struct {
 		Count      int64
@@ -427,6 +429,7 @@ func (r *OfferRepository) GetRecentOffersByPhase(ctx context.Context, phase doma
 	}
 	query = query.Order("updated_at DESC").Limit(limit)
 	query = ApplyCompanyFilter(ctx, query)
+	query = ApplyCustomerScope(ctx, query)
 	err := query.Find(&offers).Error
 	return offers, err
 }
@@ -450,9 +453,9 @@ type AggregatedPipelineStats struct {
 // If since is nil, no date filter is applied (queries the pre-computed view)
 // Note: The view only includes valid phases (excludes draft and expired)
 func (r *OfferRepository) GetAggregatedPipelineStats(ctx context.Context, fromDate, toDate *time.Time) ([]AggregatedPipelineStats, error) {
-	// If we have a date filter, we need to fall back to raw query
-	// because the view doesn't have date-based aggregation
-	if fromDate != nil || toDate != nil {
+	// If we have a date filter or customer scope, we need to fall back to raw query
+	// because the view doesn't have date-based aggregation or customer information
+	if fromDate != nil || toDate != nil || CustomerScopeFromContext(ctx) != CustomerScopeAll {
 		return r.getAggregatedPipelineStatsWithDateFilter(ctx, fromDate, toDate)
 	}
 
@@ -529,6 +532,11 @@ func (r *OfferRepository) getAggregatedPipelineStatsWithDateFilter(ctx context.C
 		argIndex++
 	}
 
+	// The customer scope has no arguments, so it is added with the date conditions
+	if condition := customerScopeCondition(CustomerScopeFromContext(ctx), "o"); condition != "" {
+		dateConditions = append(dateConditions, condition)
+	}
+
 	dateFilter := "TRUE"
 	if len(dateConditions) > 0 {
 		dateFilter = strings.Join(dateConditions, " AND ")
@@ -761,6 +769,7 @@ func (r *OfferRepository) GetAggregatedOfferStats(ctx context.Context, fromDate,
 		avgProbQuery = avgProbQuery.Where("created_at <= ?", *toDate)
 	}
 	avgProbQuery = ApplyCompanyFilter(ctx, avgProbQuery)
+	avgProbQuery = ApplyCustomerScope(ctx, avgProbQuery)
 	if err := avgProbQuery.Select("COALESCE(AVG(probability), 0)").Scan(&stats.AverageProbability).Error; err != nil {
 		return nil, fmt.Errorf("failed to calculate avg probability: %w", err)
 	}
@@ -958,6 +967,7 @@ func (r *OfferRepository) GetOrderStats(ctx context.Context, companyID *domain.C
 	} else {
 		baseQuery = ApplyCompanyFilter(ctx, baseQuery)
 	}
+	baseQuery = ApplyCustomerScope(ctx, baseQuery)
 	// Apply date filters
 	if fromDate != nil {
 		baseQuery = baseQuery.Where("created_at >= ?", *fromDate)
@@ -1037,6 +1047,7 @@ func (r *OfferRepository) CountByHealthStatus(ctx context.Context, companyID *do
 	} else {
 		query = ApplyCompanyFilter(ctx, query)
 	}
+	query = ApplyCustomerScope(ctx, query)
 	// Apply date filters
 	if fromDate != nil {
 		query = query.Where("created_at >= ?", *fromDate)
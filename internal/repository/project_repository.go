@@ -17,6 +17,8 @@ import (
 type ProjectFilters struct {
 	CustomerID *uuid.UUID
 	Phase      *domain.ProjectPhase
+	// CustomerGroupID matches the customer and all its subsidiaries
+	CustomerGroupID *uuid.UUID
 }
 
 // projectSortableFields maps API field names to database column names for projects
@@ -99,6 +101,10 @@ func (r *ProjectRepository) ListWithFilters(ctx context.Context, page, pageSize
 			query = query.Where("customer_id = ?", *filters.CustomerID)
 		}
 
+		if filters.CustomerGroupID != nil {
+			query = query.Where("customer_id IN (?)", customerGroupIDsSubquery(r.db.WithContext(ctx), *filters.CustomerGroupID))
+		}
+
 		if filters.Phase != nil {
 			query = query.Where("phase = ?", *filters.Phase)
 		}
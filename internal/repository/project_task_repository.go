@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/straye-as/relation-api/internal/domain"
+	"gorm.io/gorm"
+)
+
+// ProjectTaskRepository handles data access for project tasks, milestones and their dependencies.
+// Projects are cross-company, so no company filter applies.
+type ProjectTaskRepository struct {
+	db *gorm.DB
+}
+
+// NewProjectTaskRepository creates a new project task repository instance
+func NewProjectTaskRepository(db *gorm.DB) *ProjectTaskRepository {
+	return &ProjectTaskRepository{db: db}
+}
+
+// ListByProject returns the tasks and milestones of a project in display order
+func (r *ProjectTaskRepository) ListByProject(ctx context.Context, projectID uuid.UUID) ([]domain.ProjectTask, error) {
+	var tasks []domain.ProjectTask
+	err := r.db.WithContext(ctx).
+		Where("project_id = ?", projectID).
+		Order("sort_order ASC, start_date ASC, created_at ASC").
+		Find(&tasks).Error
+	return tasks, err
+}
+
+// ListDependencies returns the dependencies between the tasks of a project
+func (r *ProjectTaskRepository) ListDependencies(ctx context.Context, projectID uuid.UUID) ([]domain.ProjectTaskDependency, error) {
+	var deps []domain.ProjectTaskDependency
+	err := r.db.WithContext(ctx).
+		Model(&domain.ProjectTaskDependency{}).
+		Joins("JOIN project_tasks t ON t.id = project_task_dependencies.task_id").
+		Where("t.project_id = ?", projectID).
+		Order("project_task_dependencies.task_id, project_task_dependencies.depends_on_id").
+		Find(&deps).Error
+	return deps, err
+}
+
+// GetByID retrieves a task or milestone of a project
+func (r *ProjectTaskRepository) GetByID(ctx context.Context, projectID, id uuid.UUID) (*domain.ProjectTask, error) {
+	var task domain.ProjectTask
+	err := r.db.WithContext(ctx).Where("id = ? AND project_id = ?", id, projectID).First(&task).Error
+	if err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// Create stores a new task together with the tasks it depends on
+func (r *ProjectTaskRepository) Create(ctx context.Context, task *domain.ProjectTask, dependsOn []uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(task).Error; err != nil {
+			return err
+		}
+		return replaceTaskDependencies(tx, task.ID, dependsOn)
+	})
+}
+
+// Update saves a task and replaces the tasks it depends on
+func (r *ProjectTaskRepository) Update(ctx context.Context, task *domain.ProjectTask, dependsOn []uuid.UUID) error {
+	task.UpdatedAt = time.Now()
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&domain.ProjectTask{}).Where("id = ?", task.ID).Updates(map[string]interface{}{
+			"name":             task.Name,
+			"description":      task.Description,
+			"milestone_id":     task.MilestoneID,
+			"offer_id":         task.OfferID,
+			"start_date":       task.StartDate,
+			"end_date":         task.EndDate,
+			"assignee_id":      task.AssigneeID,
+			"assignee_name":    task.AssigneeName,
+			"percent_complete": task.PercentComplete,
+			"sort_order":       task.SortOrder,
+			"updated_by_id":    task.UpdatedByID,
+			"updated_by_name":  task.UpdatedByName,
+			"updated_at":       task.UpdatedAt,
+		}).Error
+		if err != nil {
+			return err
+		}
+		return replaceTaskDependencies(tx, task.ID, dependsOn)
+	})
+}
+
+// Delete removes a task. Its dependencies go with it and tasks in a deleted milestone are detached.
+func (r *ProjectTaskRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Delete(&domain.ProjectTask{}, "id = ?", id).Error
+}
+
+func replaceTaskDependencies(tx *gorm.DB, taskID uuid.UUID, dependsOn []uuid.UUID) error {
+	if err := tx.Where("task_id = ?", taskID).Delete(&domain.ProjectTaskDependency{}).Error; err != nil {
+		return err
+	}
+	if len(dependsOn) == 0 {
+		return nil
+	}
+	deps := make([]domain.ProjectTaskDependency, 0, len(dependsOn))
+	for _, id := range dependsOn {
+		deps = append(deps, domain.ProjectTaskDependency{TaskID: taskID, DependsOnID: id})
+	}
+	return tx.Create(&deps).Error
+}
@@ -0,0 +1,184 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/straye-as/relation-api/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ResourceAllocationFilters defines filtering options for listing resource allocations
+type ResourceAllocationFilters struct {
+	CompanyID *domain.CompanyID
+	UserID    *string
+	OfferID   *uuid.UUID
+	ProjectID *uuid.UUID
+	// From and To match allocations overlapping the period (both inclusive)
+	From *time.Time
+	To   *time.Time
+}
+
+// ResourceCapacityRepository handles data access for users' weekly hours, absences and resource allocations
+type ResourceCapacityRepository struct {
+	db *gorm.DB
+}
+
+// NewResourceCapacityRepository creates a new resource capacity repository instance
+func NewResourceCapacityRepository(db *gorm.DB) *ResourceCapacityRepository {
+	return &ResourceCapacityRepository{db: db}
+}
+
+// preloadAllocationTargets loads the title of the offer and the name of the project an allocation is for
+func preloadAllocationTargets(query *gorm.DB) *gorm.DB {
+	return query.
+		Preload("Offer", func(db *gorm.DB) *gorm.DB { return db.Select("id", "title") }).
+		Preload("Project", func(db *gorm.DB) *gorm.DB { return db.Select("id", "name") })
+}
+
+// ListAllocations returns the allocations matching the filters, earliest first
+func (r *ResourceCapacityRepository) ListAllocations(ctx context.Context, filters *ResourceAllocationFilters) ([]domain.ResourceAllocation, error) {
+	var allocations []domain.ResourceAllocation
+	query := r.db.WithContext(ctx).Model(&domain.ResourceAllocation{})
+
+	if filters != nil {
+		if filters.CompanyID != nil {
+			query = query.Where("company_id = ?", *filters.CompanyID)
+		}
+		if filters.UserID != nil {
+			query = query.Where("user_id = ?", *filters.UserID)
+		}
+		if filters.OfferID != nil {
+			query = query.Where("offer_id = ?", *filters.OfferID)
+		}
+		if filters.ProjectID != nil {
+			query = query.Where("project_id = ?", *filters.ProjectID)
+		}
+		if filters.From != nil {
+			query = query.Where("end_date >= ?", *filters.From)
+		}
+		if filters.To != nil {
+			query = query.Where("start_date <= ?", *filters.To)
+		}
+	}
+
+	query = ApplyCompanyFilter(ctx, query)
+	err := preloadAllocationTargets(query).Order("start_date ASC, user_name ASC, created_at ASC").Find(&allocations).Error
+	return allocations, err
+}
+
+// ListAllocationsByUsers returns all allocations of the users overlapping the period in any company.
+// Capacity is per person, so a user's load counts their work for every company.
+func (r *ResourceCapacityRepository) ListAllocationsByUsers(ctx context.Context, userIDs []string, from, to time.Time) ([]domain.ResourceAllocation, error) {
+	var allocations []domain.ResourceAllocation
+	if len(userIDs) == 0 {
+		return allocations, nil
+	}
+	query := r.db.WithContext(ctx).
+		Where("user_id IN ? AND end_date >= ? AND start_date <= ?", userIDs, from, to)
+	err := preloadAllocationTargets(query).Order("start_date ASC, created_at ASC").Find(&allocations).Error
+	return allocations, err
+}
+
+// GetAllocation retrieves a resource allocation
+func (r *ResourceCapacityRepository) GetAllocation(ctx context.Context, id uuid.UUID) (*domain.ResourceAllocation, error) {
+	var allocation domain.ResourceAllocation
+	query := r.db.WithContext(ctx).Where("id = ?", id)
+	query = ApplyCompanyFilter(ctx, query)
+	if err := preloadAllocationTargets(query).First(&allocation).Error; err != nil {
+		return nil, err
+	}
+	return &allocation, nil
+}
+
+// CreateAllocation stores a new resource allocation
+func (r *ResourceCapacityRepository) CreateAllocation(ctx context.Context, allocation *domain.ResourceAllocation) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Create(allocation).Error
+}
+
+// UpdateAllocation saves the period, size and notes of a resource allocation
+func (r *ResourceCapacityRepository) UpdateAllocation(ctx context.Context, allocation *domain.ResourceAllocation) error {
+	allocation.UpdatedAt = time.Now()
+	return r.db.WithContext(ctx).Model(&domain.ResourceAllocation{}).Where("id = ?", allocation.ID).Updates(map[string]interface{}{
+		"start_date":      allocation.StartDate,
+		"end_date":        allocation.EndDate,
+		"hours_per_week":  allocation.HoursPerWeek,
+		"percent":         allocation.Percent,
+		"notes":           allocation.Notes,
+		"updated_by_id":   allocation.UpdatedByID,
+		"updated_by_name": allocation.UpdatedByName,
+		"updated_at":      allocation.UpdatedAt,
+	}).Error
+}
+
+// DeleteAllocation removes a resource allocation
+func (r *ResourceCapacityRepository) DeleteAllocation(ctx context.Context, id uuid.UUID) error {
+	result := r.db.WithContext(ctx).Where("id = ?", id).Delete(&domain.ResourceAllocation{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// ListCapacities returns the weekly hours of the users who do not work the standard week
+func (r *ResourceCapacityRepository) ListCapacities(ctx context.Context, userIDs []string) ([]domain.UserCapacity, error) {
+	var capacities []domain.UserCapacity
+	if len(userIDs) == 0 {
+		return capacities, nil
+	}
+	err := r.db.WithContext(ctx).Where("user_id IN ?", userIDs).Find(&capacities).Error
+	return capacities, err
+}
+
+// UpsertCapacity stores a user's weekly hours
+func (r *ResourceCapacityRepository) UpsertCapacity(ctx context.Context, capacity *domain.UserCapacity) error {
+	capacity.UpdatedAt = time.Now()
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"user_name", "hours_per_week", "updated_by_id", "updated_by_name", "updated_at"}),
+	}).Create(capacity).Error
+}
+
+// ListAbsences returns the absences of the users overlapping the period, earliest first
+func (r *ResourceCapacityRepository) ListAbsences(ctx context.Context, userIDs []string, from, to time.Time) ([]domain.UserAbsence, error) {
+	var absences []domain.UserAbsence
+	if len(userIDs) == 0 {
+		return absences, nil
+	}
+	err := r.db.WithContext(ctx).
+		Where("user_id IN ? AND end_date >= ? AND start_date <= ?", userIDs, from, to).
+		Order("start_date ASC, created_at ASC").
+		Find(&absences).Error
+	return absences, err
+}
+
+// GetAbsence retrieves an absence of a user
+func (r *ResourceCapacityRepository) GetAbsence(ctx context.Context, userID string, id uuid.UUID) (*domain.UserAbsence, error) {
+	var absence domain.UserAbsence
+	if err := r.db.WithContext(ctx).Where("id = ? AND user_id = ?", id, userID).First(&absence).Error; err != nil {
+		return nil, err
+	}
+	return &absence, nil
+}
+
+// CreateAbsence stores a new absence
+func (r *ResourceCapacityRepository) CreateAbsence(ctx context.Context, absence *domain.UserAbsence) error {
+	return r.db.WithContext(ctx).Create(absence).Error
+}
+
+// DeleteAbsence removes an absence
+func (r *ResourceCapacityRepository) DeleteAbsence(ctx context.Context, id uuid.UUID) error {
+	result := r.db.WithContext(ctx).Where("id = ?", id).Delete(&domain.UserAbsence{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
@@ -0,0 +1,165 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/straye-as/relation-api/internal/domain"
+	"gorm.io/gorm"
+)
+
+// SalesTargetFilters defines filtering options for listing sales targets
+type SalesTargetFilters struct {
+	CompanyID  *domain.CompanyID
+	UserID     *string
+	PeriodType *domain.SalesTargetPeriod
+	Metric     *domain.SalesTargetMetric
+	// ActiveOn matches targets whose period contains the date
+	ActiveOn *time.Time
+	// CompanyWide matches only targets without a user (true) or only user targets (false)
+	CompanyWide *bool
+}
+
+// SalesTargetRepository handles data access for sales targets and the won business they are measured against
+type SalesTargetRepository struct {
+	db *gorm.DB
+}
+
+// NewSalesTargetRepository creates a new sales target repository instance
+func NewSalesTargetRepository(db *gorm.DB) *SalesTargetRepository {
+	return &SalesTargetRepository{db: db}
+}
+
+// List returns the sales targets matching the filters, latest period first
+func (r *SalesTargetRepository) List(ctx context.Context, filters *SalesTargetFilters) ([]domain.SalesTarget, error) {
+	var targets []domain.SalesTarget
+	query := r.db.WithContext(ctx).Model(&domain.SalesTarget{})
+
+	if filters != nil {
+		if filters.CompanyID != nil {
+			query = query.Where("company_id = ?", *filters.CompanyID)
+		}
+		if filters.UserID != nil {
+			query = query.Where("user_id = ?", *filters.UserID)
+		}
+		if filters.PeriodType != nil {
+			query = query.Where("period_type = ?", *filters.PeriodType)
+		}
+		if filters.Metric != nil {
+			query = query.Where("metric = ?", *filters.Metric)
+		}
+		if filters.ActiveOn != nil {
+			query = query.Where(`period_start <= ? AND ? < period_start + CASE period_type
+				WHEN 'month' THEN INTERVAL '1 month'
+				WHEN 'quarter' THEN INTERVAL '3 months'
+				ELSE INTERVAL '1 year' END`, *filters.ActiveOn, *filters.ActiveOn)
+		}
+		if filters.CompanyWide != nil {
+			if *filters.CompanyWide {
+				query = query.Where("user_id IS NULL")
+			} else {
+				query = query.Where("user_id IS NOT NULL")
+			}
+		}
+	}
+
+	query = ApplyCompanyFilter(ctx, query)
+	err := query.Order("period_start DESC, company_id ASC, user_name ASC, metric ASC").Find(&targets).Error
+	return targets, err
+}
+
+// GetByID retrieves a sales target
+func (r *SalesTargetRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.SalesTarget, error) {
+	var target domain.SalesTarget
+	query := r.db.WithContext(ctx).Where("id = ?", id)
+	query = ApplyCompanyFilter(ctx, query)
+	if err := query.First(&target).Error; err != nil {
+		return nil, err
+	}
+	return &target, nil
+}
+
+// Exists reports whether a target already exists for the company or user, period and metric
+func (r *SalesTargetRepository) Exists(ctx context.Context, target *domain.SalesTarget) (bool, error) {
+	var count int64
+	query := r.db.WithContext(ctx).Model(&domain.SalesTarget{}).
+		Where("company_id = ? AND period_type = ? AND period_start = ? AND metric = ?",
+			target.CompanyID, target.PeriodType, target.PeriodStart, target.Metric)
+	if target.UserID != nil {
+		query = query.Where("user_id = ?", *target.UserID)
+	} else {
+		query = query.Where("user_id IS NULL")
+	}
+	err := query.Count(&count).Error
+	return count > 0, err
+}
+
+// Create stores a new sales target
+func (r *SalesTargetRepository) Create(ctx context.Context, target *domain.SalesTarget) error {
+	return r.db.WithContext(ctx).Create(target).Error
+}
+
+// Update saves the target value and notes of a sales target
+func (r *SalesTargetRepository) Update(ctx context.Context, target *domain.SalesTarget) error {
+	target.UpdatedAt = time.Now()
+	return r.db.WithContext(ctx).Model(&domain.SalesTarget{}).Where("id = ?", target.ID).Updates(map[string]interface{}{
+		"target_value": target.TargetValue,
+		"notes":        target.Notes,
+		"updated_at":   target.UpdatedAt,
+	}).Error
+}
+
+// Delete removes a sales target
+func (r *SalesTargetRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	result := r.db.WithContext(ctx).Where("id = ?", id).Delete(&domain.SalesTarget{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// GetAchieved returns the metric achieved by a company, or a user within it, between from (inclusive) and to (exclusive).
+// Deals count by owner and close date; offers by responsible user and the date they were accepted as orders.
+func (r *SalesTargetRepository) GetAchieved(ctx context.Context, metric domain.SalesTargetMetric, companyID domain.CompanyID, userID *string, from, to time.Time) (float64, error) {
+	var offerValue float64
+	offerSum := "COALESCE(SUM(value), 0)"
+	if metric == domain.SalesTargetMetricMargin {
+		offerSum = "COALESCE(SUM(value - cost), 0)"
+	}
+
+	offerQuery := r.db.WithContext(ctx).Model(&domain.Offer{}).
+		Select(offerSum).
+		Where("company_id = ? AND won_at >= ? AND won_at < ?", companyID, from, to)
+	if userID != nil {
+		offerQuery = offerQuery.Where("responsible_user_id = ?", *userID)
+	}
+	if metric == domain.SalesTargetMetricWonValue {
+		// Won deals are counted below; skip the offers they were won through
+		offerQuery = offerQuery.Where("NOT EXISTS (SELECT 1 FROM deals d WHERE d.offer_id = offers.id AND d.stage = ?)", domain.DealStageWon)
+	}
+	if err := offerQuery.Scan(&offerValue).Error; err != nil {
+		return 0, err
+	}
+
+	if metric != domain.SalesTargetMetricWonValue {
+		return offerValue, nil
+	}
+
+	var dealValue float64
+	dealQuery := r.db.WithContext(ctx).Model(&domain.Deal{}).
+		Select("COALESCE(SUM(value), 0)").
+		Where("company_id = ? AND stage = ? AND actual_close_date >= ? AND actual_close_date < ?",
+			companyID, domain.DealStageWon, from, to)
+	if userID != nil {
+		dealQuery = dealQuery.Where("owner_id = ?", *userID)
+	}
+	if err := dealQuery.Scan(&dealValue).Error; err != nil {
+		return 0, err
+	}
+
+	return offerValue + dealValue, nil
+}
@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/straye-as/relation-api/internal/domain"
+	"gorm.io/gorm"
+)
+
+// StalenessInfo holds the timestamps a deal's or offer's staleness is computed from
+type StalenessInfo struct {
+	ID uuid.UUID
+	// StageEnteredAt is when the deal entered its stage or the offer entered its phase
+	StageEnteredAt time.Time
+	// LastActivityAt is when the latest activity on the item occurred, nil if it has none
+	LastActivityAt *time.Time
+	// StaleAfterDays is the threshold for the current stage/phase, nil if it never goes stale
+	StaleAfterDays *int
+}
+
+// OfferStaleThresholds maps offer phases to the number of days without activity before an offer in the phase is stale
+type OfferStaleThresholds map[domain.OfferPhase]int
+
+// Staleness SQL fragments. An item is stale when neither its stage change nor its latest activity
+// is more recent than the threshold of its current stage.
+const (
+	dealStageEnteredAtSQL = `COALESCE((SELECT MAX(h.changed_at) FROM deal_stage_history h WHERE h.deal_id = deals.id AND h.to_stage = deals.stage), deals.created_at)`
+	dealLastActivitySQL   = `(SELECT MAX(a.occurred_at) FROM activities a WHERE a.target_type = 'Deal' AND a.target_id = deals.id AND a.occurred_at <= NOW())`
+	dealStaleAfterDaysSQL = `(SELECT s.stale_after_days FROM deal_pipeline_stages s WHERE s.pipeline_id = deals.pipeline_id AND s.key = deals.stage AND s.kind = 'open')`
+
+	offerStageEnteredAtSQL = `offers.phase_changed_at`
+	offerLastActivitySQL   = `(SELECT MAX(a.occurred_at) FROM activities a WHERE a.target_type = 'Offer' AND a.target_id = offers.id AND a.occurred_at <= NOW())`
+)
+
+// staleCondition returns a boolean SQL expression that is true when the item is stale
+func staleCondition(enteredAt, lastActivity, staleAfterDays string) string {
+	return "COALESCE(GREATEST(" + enteredAt + ", " + lastActivity + ") < NOW() - make_interval(days => " + staleAfterDays + "), false)"
+}
+
+// offerStaleAfterDaysSQL builds the per-phase threshold expression for offers
+func offerStaleAfterDaysSQL(thresholds OfferStaleThresholds) (string, []interface{}) {
+	phases := make([]string, 0, len(thresholds))
+	for phase, days := range thresholds {
+		if days > 0 {
+			phases = append(phases, string(phase))
+		}
+	}
+	if len(phases) == 0 {
+		return "NULL::integer", nil
+	}
+	sort.Strings(phases)
+
+	var sb strings.Builder
+	args := make([]interface{}, 0, len(phases)*2)
+	sb.WriteString("(CASE offers.phase")
+	for _, phase := range phases {
+		sb.WriteString(" WHEN ? THEN ?::integer")
+		args = append(args, phase, thresholds[domain.OfferPhase(phase)])
+	}
+	sb.WriteString(" ELSE NULL END)")
+	return sb.String(), args
+}
+
+// applyDealStaleFilter restricts the query to stale (or not stale) deals
+func applyDealStaleFilter(query *gorm.DB, stale bool) *gorm.DB {
+	condition := staleCondition(dealStageEnteredAtSQL, dealLastActivitySQL, dealStaleAfterDaysSQL)
+	if !stale {
+		condition = "NOT " + condition
+	}
+	return query.Where(condition)
+}
+
+// applyOfferStaleFilter restricts the query to stale (or not stale) offers
+func applyOfferStaleFilter(query *gorm.DB, stale bool, thresholds OfferStaleThresholds) *gorm.DB {
+	staleAfterDays, args := offerStaleAfterDaysSQL(thresholds)
+	condition := staleCondition(offerStageEnteredAtSQL, offerLastActivitySQL, staleAfterDays)
+	if !stale {
+		condition = "NOT " + condition
+	}
+	return query.Where(condition, args...)
+}
+
+// GetStaleness returns the staleness timestamps of the given deals
+func (r *DealRepository) GetStaleness(ctx context.Context, ids []uuid.UUID) ([]StalenessInfo, error) {
+	var infos []StalenessInfo
+	if len(ids) == 0 {
+		return infos, nil
+	}
+	err := r.db.WithContext(ctx).Model(&domain.Deal{}).
+		Select("deals.id AS id, "+
+			dealStageEnteredAtSQL+" AS stage_entered_at, "+
+			dealLastActivitySQL+" AS last_activity_at, "+
+			dealStaleAfterDaysSQL+" AS stale_after_days").
+		Where("deals.id IN ?", ids).
+		Scan(&infos).Error
+	return infos, err
+}
+
+// ListStale returns all stale open deals, oldest activity first. Applies the company filter when a user is present.
+func (r *DealRepository) ListStale(ctx context.Context) ([]domain.Deal, error) {
+	var deals []domain.Deal
+	query := r.db.WithContext(ctx).Model(&domain.Deal{})
+	query = ApplyCompanyFilter(ctx, query)
+	query = applyDealStaleFilter(query, true)
+	err := query.Order("GREATEST(" + dealStageEnteredAtSQL + ", " + dealLastActivitySQL + ") ASC").Find(&deals).Error
+	return deals, err
+}
+
+// GetStaleness returns the staleness timestamps of the given offers
+func (r *OfferRepository) GetStaleness(ctx context.Context, ids []uuid.UUID, thresholds OfferStaleThresholds) ([]StalenessInfo, error) {
+	var infos []StalenessInfo
+	if len(ids) == 0 {
+		return infos, nil
+	}
+	staleAfterDays, args := offerStaleAfterDaysSQL(thresholds)
+	err := r.db.WithContext(ctx).Model(&domain.Offer{}).
+		Select("offers.id AS id, "+
+			offerStageEnteredAtSQL+" AS stage_entered_at, "+
+			offerLastActivitySQL+" AS last_activity_at, "+
+			staleAfterDays+" AS stale_after_days", args...).
+		Where("offers.id IN ?", ids).
+		Scan(&infos).Error
+	return infos, err
+}
+
+// ListStale returns all stale offers, oldest activity first. Applies the company filter when a user is present.
+func (r *OfferRepository) ListStale(ctx context.Context, thresholds OfferStaleThresholds) ([]domain.Offer, error) {
+	var offers []domain.Offer
+	query := r.db.WithContext(ctx).Model(&domain.Offer{})
+	query = ApplyCompanyFilter(ctx, query)
+	query = applyOfferStaleFilter(query, true, thresholds)
+	err := query.Order("GREATEST(" + offerStageEnteredAtSQL + ", " + offerLastActivitySQL + ") ASC").Find(&offers).Error
+	return offers, err
+}
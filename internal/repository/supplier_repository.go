@@ -167,6 +167,16 @@ func (r *SupplierRepository) Search(ctx context.Context, searchQuery string, lim
 	return suppliers, err
 }
 
+// ListWithPostalCode returns all suppliers that have a postal code, ordered by name
+func (r *SupplierRepository) ListWithPostalCode(ctx context.Context) ([]domain.Supplier, error) {
+	var suppliers []domain.Supplier
+	err := r.db.WithContext(ctx).
+		Where("postal_code IS NOT NULL AND postal_code != ''").
+		Order("name ASC").
+		Find(&suppliers).Error
+	return suppliers, err
+}
+
 // GetWithContacts retrieves a supplier with preloaded contacts
 func (r *SupplierRepository) GetWithContacts(ctx context.Context, id uuid.UUID) (*domain.Supplier, error) {
 	var supplier domain.Supplier
@@ -308,6 +318,19 @@ func (r *SupplierRepository) GetContactByID(ctx context.Context, contactID uuid.
 	return &contact, nil
 }
 
+// GetContactByEmail retrieves a supplier contact by email address, ignoring case
+func (r *SupplierRepository) GetContactByEmail(ctx context.Context, email string) (*domain.SupplierContact, error) {
+	var contact domain.SupplierContact
+	err := r.db.WithContext(ctx).
+		Where("LOWER(email) = LOWER(?)", email).
+		Order("is_primary DESC, created_at ASC").
+		First(&contact).Error
+	if err != nil {
+		return nil, err
+	}
+	return &contact, nil
+}
+
 // CreateContact creates a new supplier contact
 func (r *SupplierRepository) CreateContact(ctx context.Context, contact *domain.SupplierContact) error {
 	return r.db.WithContext(ctx).Create(contact).Error
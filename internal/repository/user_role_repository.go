@@ -141,6 +141,18 @@ func (r *UserRoleRepository) HasRoleInCompany(ctx context.Context, userID string
 	return count > 0, nil
 }
 
+// ListUserIDsWithRoleInCompany returns the IDs of users with an active role in a company
+func (r *UserRoleRepository) ListUserIDsWithRoleInCompany(ctx context.Context, role domain.UserRoleType, companyID domain.CompanyID) ([]string, error) {
+	var userIDs []string
+	err := r.db.WithContext(ctx).
+		Model(&domain.UserRole{}).
+		Distinct("user_id").
+		Where("role = ? AND company_id = ? AND is_active = true", role, companyID).
+		Where("expires_at IS NULL OR expires_at > ?", time.Now()).
+		Pluck("user_id", &userIDs).Error
+	return userIDs, err
+}
+
 // AssignRole assigns a role to a user
 func (r *UserRoleRepository) AssignRole(ctx context.Context, userID string, role domain.UserRoleType, companyID *domain.CompanyID, grantedBy string, expiresAt *time.Time) (*domain.UserRole, error) {
 	userRole := &domain.UserRole{
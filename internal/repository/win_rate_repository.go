@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/straye-as/relation-api/internal/domain"
+	"gorm.io/gorm"
+)
+
+// Kinds of records the win-rate model learns from and forecasts
+const (
+	WinRateKindDeal  = "deal"
+	WinRateKindOffer = "offer"
+)
+
+// WinRateFilters limits the deals and offers used by the win-rate model
+type WinRateFilters struct {
+	CompanyID *domain.CompanyID
+	OwnerID   *string
+}
+
+// ClosedOutcome is a won or lost deal or offer the win-rate model learns from
+type ClosedOutcome struct {
+	Kind         string
+	ID           uuid.UUID
+	CompanyID    domain.CompanyID
+	OwnerID      string
+	OwnerName    string
+	Value        float64
+	CustomerTier domain.CustomerTier
+	Won          bool
+	// EnteredProbability is the probability the salesperson had entered before the outcome was known
+	EnteredProbability *int
+	// Stages holds the deal stages or offer phases passed through before closing
+	Stages   pq.StringArray `gorm:"type:text[]"`
+	ClosedAt time.Time
+}
+
+// OpenForecastItem is an open deal or offer expected to close on a date
+type OpenForecastItem struct {
+	Kind         string
+	ID           uuid.UUID
+	Title        string
+	Stage        string
+	CompanyID    domain.CompanyID
+	OwnerID      string
+	OwnerName    string
+	Value        float64
+	Probability  int
+	CustomerTier domain.CustomerTier
+	ExpectedDate time.Time
+}
+
+// WinRateRepository reads the closed and open deals and offers behind the calibrated forecast
+type WinRateRepository struct {
+	db *gorm.DB
+}
+
+// NewWinRateRepository creates a new win-rate repository instance
+func NewWinRateRepository(db *gorm.DB) *WinRateRepository {
+	return &WinRateRepository{db: db}
+}
+
+// ListClosedOutcomes returns the deals and offers won or lost since the given time.
+// Offers linked to a deal are left out; the deal carries the outcome.
+func (r *WinRateRepository) ListClosedOutcomes(ctx context.Context, since time.Time, filters *WinRateFilters) ([]ClosedOutcome, error) {
+	var deals []ClosedOutcome
+	dealQuery := r.db.WithContext(ctx).Table("deals d").
+		Select(`'deal' AS kind, d.id, d.company_id, d.owner_id, d.owner_name, d.value,
+			COALESCE(c.tier, ?) AS customer_tier,
+			d.stage = ? AS won,
+			d.probability_at_close AS entered_probability,
+			ARRAY(SELECT DISTINCT h.to_stage FROM deal_stage_history h
+				WHERE h.deal_id = d.id AND h.to_stage NOT IN ?) AS stages,
+			COALESCE(d.actual_close_date, d.updated_at) AS closed_at`,
+			domain.CustomerTierBronze, domain.DealStageWon, []domain.DealStage{domain.DealStageWon, domain.DealStageLost}).
+		Joins("LEFT JOIN customers c ON c.id = d.customer_id").
+		Where("d.stage IN ?", []domain.DealStage{domain.DealStageWon, domain.DealStageLost}).
+		Where("COALESCE(d.actual_close_date, d.updated_at) >= ?", since)
+	dealQuery = applyWinRateFilters(ctx, dealQuery, filters, "d", "d.owner_id")
+	if err := dealQuery.Scan(&deals).Error; err != nil {
+		return nil, err
+	}
+
+	var offers []ClosedOutcome
+	offerQuery := r.db.WithContext(ctx).Table("offers o").
+		Select(`'offer' AS kind, o.id, o.company_id, o.responsible_user_id AS owner_id,
+			o.responsible_user_name AS owner_name, o.value,
+			COALESCE(c.tier, ?) AS customer_tier,
+			o.phase IN ? AS won,
+			o.probability AS entered_probability,
+			CASE WHEN o.sent_date IS NOT NULL THEN ARRAY[?, ?] ELSE ARRAY[?] END AS stages,
+			COALESCE(o.won_at, o.phase_changed_at, o.updated_at) AS closed_at`,
+			domain.CustomerTierBronze,
+			[]domain.OfferPhase{domain.OfferPhaseOrder, domain.OfferPhaseCompleted},
+			string(domain.OfferPhaseInProgress), string(domain.OfferPhaseSent), string(domain.OfferPhaseInProgress)).
+		Joins("LEFT JOIN customers c ON c.id = o.customer_id").
+		Where("o.phase IN ?", []domain.OfferPhase{domain.OfferPhaseOrder, domain.OfferPhaseCompleted, domain.OfferPhaseLost, domain.OfferPhaseExpired}).
+		Where("COALESCE(o.won_at, o.phase_changed_at, o.updated_at) >= ?", since).
+		Where("NOT EXISTS (SELECT 1 FROM deals d WHERE d.offer_id = o.id)")
+	offerQuery = applyWinRateFilters(ctx, offerQuery, filters, "o", "o.responsible_user_id")
+	if err := offerQuery.Scan(&offers).Error; err != nil {
+		return nil, err
+	}
+
+	return append(deals, offers...), nil
+}
+
+// ListOpenItems returns the open deals expected to close and the open offers expiring
+// (or due, when no expiration is set) between from (inclusive) and to (exclusive).
+// Offers linked to a deal are left out; the deal is forecast instead.
+func (r *WinRateRepository) ListOpenItems(ctx context.Context, from, to time.Time, filters *WinRateFilters) ([]OpenForecastItem, error) {
+	var deals []OpenForecastItem
+	dealQuery := r.db.WithContext(ctx).Table("deals d").
+		Select(`'deal' AS kind, d.id, d.title, d.stage, d.company_id, d.owner_id, d.owner_name,
+			d.value, d.probability, COALESCE(c.tier, ?) AS customer_tier, d.expected_close_date AS expected_date`,
+			domain.CustomerTierBronze).
+		Joins("LEFT JOIN customers c ON c.id = d.customer_id").
+		Where("d.stage NOT IN ?", []domain.DealStage{domain.DealStageWon, domain.DealStageLost}).
+		Where("d.expected_close_date >= ? AND d.expected_close_date < ?", from, to)
+	dealQuery = applyWinRateFilters(ctx, dealQuery, filters, "d", "d.owner_id")
+	if err := dealQuery.Scan(&deals).Error; err != nil {
+		return nil, err
+	}
+
+	var offers []OpenForecastItem
+	offerQuery := r.db.WithContext(ctx).Table("offers o").
+		Select(`'offer' AS kind, o.id, o.title, o.phase AS stage, o.company_id,
+			o.responsible_user_id AS owner_id, o.responsible_user_name AS owner_name,
+			o.value, o.probability, COALESCE(c.tier, ?) AS customer_tier,
+			COALESCE(o.expiration_date, o.due_date) AS expected_date`,
+			domain.CustomerTierBronze).
+		Joins("LEFT JOIN customers c ON c.id = o.customer_id").
+		Where("o.phase IN ?", []domain.OfferPhase{domain.OfferPhaseInProgress, domain.OfferPhaseSent}).
+		Where("COALESCE(o.expiration_date, o.due_date) >= ? AND COALESCE(o.expiration_date, o.due_date) < ?", from, to).
+		Where("NOT EXISTS (SELECT 1 FROM deals d WHERE d.offer_id = o.id)")
+	offerQuery = applyWinRateFilters(ctx, offerQuery, filters, "o", "o.responsible_user_id")
+	if err := offerQuery.Scan(&offers).Error; err != nil {
+		return nil, err
+	}
+
+	return append(deals, offers...), nil
+}
+
+// applyWinRateFilters applies the company, owner and multi-tenant filters to an aliased deals or offers query
+func applyWinRateFilters(ctx context.Context, query *gorm.DB, filters *WinRateFilters, alias, ownerColumn string) *gorm.DB {
+	if filters != nil {
+		if filters.CompanyID != nil {
+			query = query.Where(alias+".company_id = ?", *filters.CompanyID)
+		}
+		if filters.OwnerID != nil {
+			query = query.Where(ownerColumn+" = ?", *filters.OwnerID)
+		}
+	}
+	return ApplyCompanyFilterWithAlias(ctx, query, alias)
+}
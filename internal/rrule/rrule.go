@@ -0,0 +1,589 @@
+// Package rrule parses and expands RFC 5545 recurrence rules (RRULE) for recurring activities.
+//
+// Supported rule parts are FREQ (DAILY, WEEKLY, MONTHLY, YEARLY), INTERVAL, COUNT, UNTIL, BYDAY,
+// BYMONTHDAY, BYMONTH, BYSETPOS and WKST. Occurrences keep the wall clock time of the start in its
+// location, so a meeting at 10:00 in Europe/Oslo stays at 10:00 across daylight saving changes.
+package rrule
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MaxOccurrences is the maximum number of occurrences returned by a single expansion
+const MaxOccurrences = 1000
+
+// maxPeriods bounds the number of periods examined, for rules that rarely or never match
+const maxPeriods = 50000
+
+// Frequency is the FREQ of a rule
+type Frequency string
+
+// Supported frequencies
+const (
+	Daily   Frequency = "DAILY"
+	Weekly  Frequency = "WEEKLY"
+	Monthly Frequency = "MONTHLY"
+	Yearly  Frequency = "YEARLY"
+)
+
+var (
+	// ErrInvalidRule is returned for malformed rules
+	ErrInvalidRule = errors.New("invalid recurrence rule")
+
+	// ErrUnsupportedRule is returned for valid rules using parts this package does not expand
+	ErrUnsupportedRule = errors.New("unsupported recurrence rule")
+)
+
+// weekdays maps RFC 5545 weekday codes to weekdays
+var weekdays = map[string]time.Weekday{
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+	"SU": time.Sunday,
+}
+
+// WeekdayNum is a BYDAY entry; N is the ordinal within the month (1 = first, -1 = last, 0 = every)
+type WeekdayNum struct {
+	N       int
+	Weekday time.Weekday
+}
+
+// String returns the BYDAY form, e.g. MO, 2TU or -1FR
+func (w WeekdayNum) String() string {
+	code := weekdayCode(w.Weekday)
+	if w.N != 0 {
+		return strconv.Itoa(w.N) + code
+	}
+	return code
+}
+
+// Rule is a parsed recurrence rule
+type Rule struct {
+	Freq       Frequency
+	Interval   int
+	Count      int // 0 when the rule has no COUNT
+	Until      *time.Time
+	ByDay      []WeekdayNum
+	ByMonthDay []int
+	ByMonth    []int
+	BySetPos   []int
+	WeekStart  time.Weekday
+	// untilFloating is set for UNTIL values without Z, which are wall clock times in the start's location
+	untilFloating bool
+}
+
+// Parse parses a recurrence rule such as FREQ=MONTHLY;INTERVAL=3;BYDAY=1MO. A leading RRULE: is ignored.
+func Parse(s string) (*Rule, error) {
+	s = strings.TrimSpace(s)
+	if len(s) >= 6 && strings.EqualFold(s[:6], "RRULE:") {
+		s = s[6:]
+	}
+	if s == "" {
+		return nil, fmt.Errorf("%w: empty rule", ErrInvalidRule)
+	}
+
+	rule := &Rule{Interval: 1, WeekStart: time.Monday}
+	seen := map[string]bool{}
+	for _, part := range strings.Split(s, ";") {
+		name, value, ok := strings.Cut(part, "=")
+		name = strings.ToUpper(strings.TrimSpace(name))
+		value = strings.ToUpper(strings.TrimSpace(value))
+		if !ok || name == "" || value == "" {
+			return nil, fmt.Errorf("%w: malformed part %q", ErrInvalidRule, part)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("%w: %s given more than once", ErrInvalidRule, name)
+		}
+		seen[name] = true
+
+		var err error
+		switch name {
+		case "FREQ":
+			switch Frequency(value) {
+			case Daily, Weekly, Monthly, Yearly:
+				rule.Freq = Frequency(value)
+			case "SECONDLY", "MINUTELY", "HOURLY":
+				return nil, fmt.Errorf("%w: FREQ=%s", ErrUnsupportedRule, value)
+			default:
+				return nil, fmt.Errorf("%w: unknown FREQ %q", ErrInvalidRule, value)
+			}
+		case "INTERVAL":
+			rule.Interval, err = parseInt(name, value, 1, 1000)
+		case "COUNT":
+			rule.Count, err = parseInt(name, value, 1, MaxOccurrences)
+		case "UNTIL":
+			err = rule.parseUntil(value)
+		case "BYDAY":
+			rule.ByDay, err = parseByDay(value)
+		case "BYMONTHDAY":
+			rule.ByMonthDay, err = parseIntList(name, value, 1, 31, true)
+		case "BYMONTH":
+			rule.ByMonth, err = parseIntList(name, value, 1, 12, false)
+		case "BYSETPOS":
+			rule.BySetPos, err = parseIntList(name, value, 1, 366, true)
+		case "WKST":
+			day, ok := weekdays[value]
+			if !ok {
+				return nil, fmt.Errorf("%w: unknown WKST %q", ErrInvalidRule, value)
+			}
+			rule.WeekStart = day
+		case "BYSECOND", "BYMINUTE", "BYHOUR", "BYYEARDAY", "BYWEEKNO":
+			return nil, fmt.Errorf("%w: %s", ErrUnsupportedRule, name)
+		default:
+			return nil, fmt.Errorf("%w: unknown part %s", ErrInvalidRule, name)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := rule.validate(); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+// validate checks the combinations of rule parts
+func (r *Rule) validate() error {
+	if r.Freq == "" {
+		return fmt.Errorf("%w: FREQ is required", ErrInvalidRule)
+	}
+	if r.Count > 0 && r.Until != nil {
+		return fmt.Errorf("%w: COUNT and UNTIL cannot both be given", ErrInvalidRule)
+	}
+	hasOrdinal := false
+	for _, day := range r.ByDay {
+		if day.N != 0 {
+			hasOrdinal = true
+		}
+	}
+	switch r.Freq {
+	case Daily, Weekly:
+		if hasOrdinal {
+			return fmt.Errorf("%w: BYDAY ordinals require FREQ=MONTHLY or YEARLY", ErrInvalidRule)
+		}
+		if r.Freq == Weekly && len(r.ByMonthDay) > 0 {
+			return fmt.Errorf("%w: BYMONTHDAY cannot be used with FREQ=WEEKLY", ErrInvalidRule)
+		}
+	case Yearly:
+		if len(r.ByDay) > 0 && len(r.ByMonth) == 0 {
+			return fmt.Errorf("%w: BYDAY with FREQ=YEARLY requires BYMONTH", ErrUnsupportedRule)
+		}
+	}
+	if hasOrdinal && len(r.ByMonthDay) > 0 {
+		return fmt.Errorf("%w: BYDAY ordinals cannot be combined with BYMONTHDAY", ErrUnsupportedRule)
+	}
+	return nil
+}
+
+// String returns the rule in canonical form, without the RRULE: prefix
+func (r *Rule) String() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if r.Until != nil {
+		if r.untilFloating {
+			parts = append(parts, "UNTIL="+r.Until.Format("20060102T150405"))
+		} else {
+			parts = append(parts, "UNTIL="+r.Until.UTC().Format("20060102T150405Z"))
+		}
+	}
+	if len(r.ByDay) > 0 {
+		days := make([]string, len(r.ByDay))
+		for i, day := range r.ByDay {
+			days[i] = day.String()
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if len(r.ByMonthDay) > 0 {
+		parts = append(parts, "BYMONTHDAY="+joinInts(r.ByMonthDay))
+	}
+	if len(r.ByMonth) > 0 {
+		parts = append(parts, "BYMONTH="+joinInts(r.ByMonth))
+	}
+	if len(r.BySetPos) > 0 {
+		parts = append(parts, "BYSETPOS="+joinInts(r.BySetPos))
+	}
+	if r.WeekStart != time.Monday {
+		parts = append(parts, "WKST="+weekdayCode(r.WeekStart))
+	}
+	return strings.Join(parts, ";")
+}
+
+// WithUntil returns a copy of the rule ending at until (inclusive), replacing any COUNT or UNTIL
+func (r *Rule) WithUntil(until time.Time) *Rule {
+	copied := *r
+	until = until.UTC()
+	copied.Count = 0
+	copied.Until = &until
+	copied.untilFloating = false
+	return &copied
+}
+
+// WithCount returns a copy of the rule with count occurrences, replacing any COUNT or UNTIL
+func (r *Rule) WithCount(count int) *Rule {
+	copied := *r
+	copied.Count = count
+	copied.Until = nil
+	copied.untilFloating = false
+	return &copied
+}
+
+// Occurrences returns the start times of the occurrences from start (the first occurrence, DTSTART)
+// up to and including end, in start's location. At most MaxOccurrences are returned.
+func (r *Rule) Occurrences(start, end time.Time) []time.Time {
+	return r.Between(start, start, end)
+}
+
+// Between returns the start times of the occurrences of the series starting at start (DTSTART) that
+// fall within [from, end], in start's location. COUNT is counted from start, so long running series
+// can be expanded window by window. At most MaxOccurrences are returned.
+func (r *Rule) Between(start, from, end time.Time) []time.Time {
+	loc := start.Location()
+	limit := end
+	if r.Until != nil {
+		until := *r.Until
+		if r.untilFloating {
+			until = time.Date(until.Year(), until.Month(), until.Day(), until.Hour(), until.Minute(), until.Second(), 0, loc)
+		}
+		if until.Before(limit) {
+			limit = until
+		}
+	}
+	if start.After(limit) || from.After(limit) {
+		return nil
+	}
+
+	// DTSTART is always the first occurrence, even when it does not match the rule
+	var occurrences []time.Time
+	if !start.Before(from) {
+		occurrences = append(occurrences, start)
+	}
+	count := 1
+	hour, minute, second := start.Clock()
+	for period := 0; period < maxPeriods; period++ {
+		periodStart, dates := r.period(start, period)
+		if periodStart.After(limit) {
+			break
+		}
+		for _, d := range dates {
+			t := time.Date(d.year, d.month, d.day, hour, minute, second, 0, loc)
+			if !t.After(start) {
+				continue
+			}
+			if t.After(limit) || (r.Count > 0 && count >= r.Count) || len(occurrences) >= MaxOccurrences {
+				return occurrences
+			}
+			count++
+			if !t.Before(from) {
+				occurrences = append(occurrences, t)
+			}
+		}
+	}
+	return occurrences
+}
+
+// date is a calendar date
+type date struct {
+	year  int
+	month time.Month
+	day   int
+}
+
+// period returns the first day of the n-th period after start's period and the sorted candidate
+// dates in it, after BYSETPOS
+func (r *Rule) period(start time.Time, n int) (time.Time, []date) {
+	loc := start.Location()
+	year, month, day := start.Date()
+	step := n * r.Interval
+
+	var first time.Time
+	var candidates []date
+	switch r.Freq {
+	case Daily:
+		first = time.Date(year, month, day+step, 0, 0, 0, 0, loc)
+		if r.matches(first, true) {
+			candidates = append(candidates, toDate(first))
+		}
+	case Weekly:
+		offset := (int(start.Weekday()) - int(r.WeekStart) + 7) % 7
+		first = time.Date(year, month, day-offset+7*step, 0, 0, 0, 0, loc)
+		days := r.ByDay
+		if len(days) == 0 {
+			days = []WeekdayNum{{Weekday: start.Weekday()}}
+		}
+		for _, wd := range days {
+			t := first.AddDate(0, 0, (int(wd.Weekday)-int(r.WeekStart)+7)%7)
+			if r.inMonths(t.Month()) {
+				candidates = append(candidates, toDate(t))
+			}
+		}
+	case Monthly:
+		first = time.Date(year, month+time.Month(step), 1, 0, 0, 0, 0, loc)
+		if r.inMonths(first.Month()) {
+			candidates = r.monthCandidates(first.Year(), first.Month(), day)
+		}
+	case Yearly:
+		first = time.Date(year+step, time.January, 1, 0, 0, 0, 0, loc)
+		months := r.ByMonth
+		if len(months) == 0 {
+			months = []int{int(month)}
+		}
+		for _, m := range months {
+			candidates = append(candidates, r.monthCandidates(first.Year(), time.Month(m), day)...)
+		}
+	}
+
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].before(candidates[j]) })
+	candidates = dedupe(candidates)
+	return first, r.applySetPos(candidates)
+}
+
+// monthCandidates returns the dates in a month matching BYMONTHDAY and BYDAY, or the start day
+func (r *Rule) monthCandidates(year int, month time.Month, startDay int) []date {
+	lastDay := time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	var candidates []date
+	switch {
+	case len(r.ByMonthDay) > 0:
+		for _, md := range r.ByMonthDay {
+			d := md
+			if md < 0 {
+				d = lastDay + md + 1
+			}
+			if d < 1 || d > lastDay {
+				continue
+			}
+			t := time.Date(year, month, d, 0, 0, 0, 0, time.UTC)
+			if r.matchesWeekday(t) {
+				candidates = append(candidates, toDate(t))
+			}
+		}
+	case len(r.ByDay) > 0:
+		for _, wd := range r.ByDay {
+			var matching []int
+			for d := 1; d <= lastDay; d++ {
+				if time.Date(year, month, d, 0, 0, 0, 0, time.UTC).Weekday() == wd.Weekday {
+					matching = append(matching, d)
+				}
+			}
+			switch {
+			case wd.N == 0:
+				for _, d := range matching {
+					candidates = append(candidates, date{year, month, d})
+				}
+			case wd.N > 0 && wd.N <= len(matching):
+				candidates = append(candidates, date{year, month, matching[wd.N-1]})
+			case wd.N < 0 && -wd.N <= len(matching):
+				candidates = append(candidates, date{year, month, matching[len(matching)+wd.N]})
+			}
+		}
+	default:
+		// Months without the start day (e.g. the 31st) are skipped, as RFC 5545 requires
+		if startDay <= lastDay {
+			candidates = append(candidates, date{year, month, startDay})
+		}
+	}
+	return candidates
+}
+
+// matches reports whether a day matches the BYMONTH, BYMONTHDAY and (when checkWeekday) BYDAY filters
+func (r *Rule) matches(t time.Time, checkWeekday bool) bool {
+	if !r.inMonths(t.Month()) {
+		return false
+	}
+	if len(r.ByMonthDay) > 0 {
+		lastDay := time.Date(t.Year(), t.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+		found := false
+		for _, md := range r.ByMonthDay {
+			if md == t.Day() || (md < 0 && lastDay+md+1 == t.Day()) {
+				found = true
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return !checkWeekday || r.matchesWeekday(t)
+}
+
+// matchesWeekday reports whether the day's weekday is in BYDAY, or BYDAY is empty
+func (r *Rule) matchesWeekday(t time.Time) bool {
+	if len(r.ByDay) == 0 {
+		return true
+	}
+	for _, wd := range r.ByDay {
+		if wd.Weekday == t.Weekday() {
+			return true
+		}
+	}
+	return false
+}
+
+// inMonths reports whether the month is in BYMONTH, or BYMONTH is empty
+func (r *Rule) inMonths(month time.Month) bool {
+	if len(r.ByMonth) == 0 {
+		return true
+	}
+	for _, m := range r.ByMonth {
+		if time.Month(m) == month {
+			return true
+		}
+	}
+	return false
+}
+
+// applySetPos keeps the candidates at the BYSETPOS positions
+func (r *Rule) applySetPos(candidates []date) []date {
+	if len(r.BySetPos) == 0 || len(candidates) == 0 {
+		return candidates
+	}
+	var selected []date
+	for _, pos := range r.BySetPos {
+		i := pos - 1
+		if pos < 0 {
+			i = len(candidates) + pos
+		}
+		if i >= 0 && i < len(candidates) {
+			selected = append(selected, candidates[i])
+		}
+	}
+	sort.Slice(selected, func(i, j int) bool { return selected[i].before(selected[j]) })
+	return dedupe(selected)
+}
+
+// parseUntil parses an UNTIL value as a UTC date-time, a floating date-time or a date
+func (r *Rule) parseUntil(value string) error {
+	layouts := []struct {
+		layout   string
+		floating bool
+		endOfDay bool
+	}{
+		{"20060102T150405Z", false, false},
+		{"20060102T150405", true, false},
+		{"20060102", true, true},
+	}
+	for _, l := range layouts {
+		t, err := time.Parse(l.layout, value)
+		if err != nil {
+			continue
+		}
+		if l.endOfDay {
+			t = t.Add(24*time.Hour - time.Second)
+		}
+		r.Until = &t
+		r.untilFloating = l.floating
+		return nil
+	}
+	return fmt.Errorf("%w: malformed UNTIL %q", ErrInvalidRule, value)
+}
+
+// before reports whether d is before other
+func (d date) before(other date) bool {
+	if d.year != other.year {
+		return d.year < other.year
+	}
+	if d.month != other.month {
+		return d.month < other.month
+	}
+	return d.day < other.day
+}
+
+// toDate returns the calendar date of a time
+func toDate(t time.Time) date {
+	y, m, d := t.Date()
+	return date{y, m, d}
+}
+
+// dedupe removes adjacent duplicates from sorted dates
+func dedupe(dates []date) []date {
+	out := dates[:0]
+	for i, d := range dates {
+		if i == 0 || d != dates[i-1] {
+			out = append(out, d)
+		}
+	}
+	return out
+}
+
+// parseByDay parses a BYDAY list such as MO,WE or 1MO,-1FR
+func parseByDay(value string) ([]WeekdayNum, error) {
+	var days []WeekdayNum
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if len(item) < 2 {
+			return nil, fmt.Errorf("%w: malformed BYDAY %q", ErrInvalidRule, item)
+		}
+		day, ok := weekdays[item[len(item)-2:]]
+		if !ok {
+			return nil, fmt.Errorf("%w: malformed BYDAY %q", ErrInvalidRule, item)
+		}
+		wd := WeekdayNum{Weekday: day}
+		if ordinal := item[:len(item)-2]; ordinal != "" {
+			n, err := strconv.Atoi(ordinal)
+			if err != nil || n == 0 || n < -5 || n > 5 {
+				return nil, fmt.Errorf("%w: BYDAY ordinal must be between -5 and 5 in %q", ErrInvalidRule, item)
+			}
+			wd.N = n
+		}
+		days = append(days, wd)
+	}
+	return days, nil
+}
+
+// parseInt parses an integer part within [min, max]
+func parseInt(name, value string, min, max int) (int, error) {
+	n, err := strconv.Atoi(value)
+	if err != nil || n < min || n > max {
+		return 0, fmt.Errorf("%w: %s must be between %d and %d", ErrInvalidRule, name, min, max)
+	}
+	return n, nil
+}
+
+// parseIntList parses a comma separated list of integers within [min, max], or [-max, -min] when
+// negative values are allowed
+func parseIntList(name, value string, min, max int, allowNegative bool) ([]int, error) {
+	var values []int
+	for _, item := range strings.Split(value, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(item))
+		abs := n
+		if n < 0 && allowNegative {
+			abs = -n
+		}
+		if err != nil || abs < min || abs > max {
+			return nil, fmt.Errorf("%w: %s values must be between %d and %d", ErrInvalidRule, name, min, max)
+		}
+		values = append(values, n)
+	}
+	return values, nil
+}
+
+// joinInts joins integers with commas
+func joinInts(values []int) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = strconv.Itoa(v)
+	}
+	return strings.Join(parts, ",")
+}
+
+// weekdayCode returns the RFC 5545 code of a weekday
+func weekdayCode(day time.Weekday) string {
+	for code, wd := range weekdays {
+		if wd == day {
+			return code
+		}
+	}
+	return ""
+}
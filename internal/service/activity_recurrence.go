@@ -0,0 +1,530 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/straye-as/relation-api/internal/domain"
+	"github.com/straye-as/relation-api/internal/rrule"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// Recurring activity defaults
+const (
+	// defaultRecurrenceHorizonDays is how far ahead occurrences of recurring activities are generated
+	defaultRecurrenceHorizonDays = 180
+	// recurrenceExpansionBatchSize is the number of series expanded per batch by the background job
+	recurrenceExpansionBatchSize = 200
+)
+
+// SetRecurrenceHorizonDays sets how far ahead occurrences of recurring activities are generated
+func (s *ActivityService) SetRecurrenceHorizonDays(days int) {
+	if days > 0 {
+		s.recurrenceHorizonDays = days
+	}
+}
+
+// ExpandRecurringActivities generates the occurrences of all recurring series up to the rolling
+// horizon. Returns the number of series expanded and occurrences created.
+func (s *ActivityService) ExpandRecurringActivities(ctx context.Context) (series int, created int, err error) {
+	horizon := s.recurrenceHorizon()
+	for {
+		masters, err := s.activityRepo.ListRecurringMasters(ctx, horizon, recurrenceExpansionBatchSize)
+		if err != nil {
+			return series, created, fmt.Errorf("failed to list recurring activities: %w", err)
+		}
+
+		expanded := 0
+		for i := range masters {
+			n, err := s.expandSeries(ctx, &masters[i], horizon)
+			if err != nil {
+				s.logger.Warn("failed to expand recurring activity",
+					zap.String("activity_id", masters[i].ID.String()),
+					zap.Error(err))
+				continue
+			}
+			expanded++
+			created += n
+		}
+		series += expanded
+
+		// Stop when the batch was the last one, or nothing could be expanded so the same series
+		// would be returned again
+		if len(masters) < recurrenceExpansionBatchSize || expanded == 0 {
+			return series, created, nil
+		}
+	}
+}
+
+// recurrenceHorizon returns the time up to which occurrences are generated
+func (s *ActivityService) recurrenceHorizon() time.Time {
+	return time.Now().AddDate(0, 0, s.recurrenceHorizonDays)
+}
+
+// parseRecurrenceRule parses an RRULE. Errors wrap ErrInvalidRecurrenceRule or
+// ErrUnsupportedRecurrenceRule.
+func parseRecurrenceRule(value string) (*rrule.Rule, error) {
+	return rrule.Parse(value)
+}
+
+// recurrenceAnchor returns the start of an activity as an occurrence of a series: the scheduled time
+// in Oslo time, or for activities with only a due date the date at midnight UTC
+func recurrenceAnchor(activity *domain.Activity, allDay bool) (time.Time, bool) {
+	if !allDay && activity.ScheduledAt != nil {
+		return activity.ScheduledAt.In(osloLocation), true
+	}
+	if allDay && activity.DueDate != nil {
+		y, m, d := activity.DueDate.Date()
+		return time.Date(y, m, d, 0, 0, 0, 0, time.UTC), true
+	}
+	return time.Time{}, false
+}
+
+// seriesStart returns DTSTART of a series in the location its rule is expanded in
+func seriesStart(master *domain.Activity, template *domain.ActivityRecurrenceTemplate) time.Time {
+	if template.AllDay {
+		return master.OccurrenceAt.UTC()
+	}
+	return master.OccurrenceAt.In(osloLocation)
+}
+
+// recurrenceTemplateFrom returns the template of new occurrences from an activity
+func recurrenceTemplateFrom(activity *domain.Activity, allDay bool) *domain.ActivityRecurrenceTemplate {
+	return &domain.ActivityRecurrenceTemplate{
+		Title:           activity.Title,
+		Body:            activity.Body,
+		DurationMinutes: activity.DurationMinutes,
+		Priority:        activity.Priority,
+		IsPrivate:       activity.IsPrivate,
+		AssignedToID:    activity.AssignedToID,
+		Attendees:       []string(activity.Attendees),
+		AllDay:          allDay,
+	}
+}
+
+// encodeRecurrenceTemplate returns the JSON stored on the series master
+func encodeRecurrenceTemplate(template *domain.ActivityRecurrenceTemplate) (string, error) {
+	data, err := json.Marshal(template)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode recurrence template: %w", err)
+	}
+	return string(data), nil
+}
+
+// decodeRecurrenceTemplate returns the template stored on a series master
+func decodeRecurrenceTemplate(master *domain.Activity) (*domain.ActivityRecurrenceTemplate, error) {
+	var template domain.ActivityRecurrenceTemplate
+	if master.RecurrenceTemplate == "" {
+		return recurrenceTemplateFrom(master, master.ScheduledAt == nil), nil
+	}
+	if err := json.Unmarshal([]byte(master.RecurrenceTemplate), &template); err != nil {
+		return nil, fmt.Errorf("failed to decode recurrence template: %w", err)
+	}
+	return &template, nil
+}
+
+// startSeries makes an activity that is about to be saved the master of a new recurring series.
+// A nil rule makes it a series without further occurrences. The activity is assigned an ID if it
+// has none.
+func startSeries(activity *domain.Activity, rule *rrule.Rule) error {
+	allDay := activity.ScheduledAt == nil
+	anchor, ok := recurrenceAnchor(activity, allDay)
+	if !ok {
+		return ErrRecurrenceRequiresSchedule
+	}
+	template, err := encodeRecurrenceTemplate(recurrenceTemplateFrom(activity, allDay))
+	if err != nil {
+		return err
+	}
+
+	if activity.ID == uuid.Nil {
+		activity.ID = uuid.New()
+	}
+	id := activity.ID
+	activity.SeriesID = &id
+	activity.OccurrenceAt = &anchor
+	activity.RecurrenceRule = ""
+	if rule != nil {
+		activity.RecurrenceRule = rule.String()
+	}
+	activity.RecurrenceTemplate = template
+	activity.RecurrenceExpandedUntil = nil
+	activity.IsRecurrenceException = false
+	return nil
+}
+
+// expandSeries generates the occurrences of a series after the previous expansion up to until.
+// Occurrences deleted since the previous expansion are not generated again. Returns the number of
+// occurrences created.
+func (s *ActivityService) expandSeries(ctx context.Context, master *domain.Activity, until time.Time) (int, error) {
+	if master.RecurrenceRule == "" || master.OccurrenceAt == nil {
+		return 0, nil
+	}
+	rule, err := parseRecurrenceRule(master.RecurrenceRule)
+	if err != nil {
+		return 0, err
+	}
+	template, err := decodeRecurrenceTemplate(master)
+	if err != nil {
+		return 0, err
+	}
+
+	start := seriesStart(master, template)
+	from := start
+	if master.RecurrenceExpandedUntil != nil && master.RecurrenceExpandedUntil.After(from) {
+		from = *master.RecurrenceExpandedUntil
+	}
+	if !until.After(from) {
+		return 0, nil
+	}
+
+	now := time.Now()
+	var occurrences []domain.Activity
+	for _, t := range rule.Between(start, from, until) {
+		// The start of the series is the master itself, and the previous expansion covered from
+		if !t.After(from) {
+			continue
+		}
+		occurrenceAt := t
+		occurrence := domain.Activity{
+			TargetType:      master.TargetType,
+			TargetID:        master.TargetID,
+			TargetName:      master.TargetName,
+			Title:           template.Title,
+			Body:            template.Body,
+			OccurredAt:      now,
+			CreatorName:     master.CreatorName,
+			ActivityType:    master.ActivityType,
+			Status:          domain.ActivityStatusPlanned,
+			DurationMinutes: template.DurationMinutes,
+			Priority:        template.Priority,
+			IsPrivate:       template.IsPrivate,
+			CreatorID:       master.CreatorID,
+			AssignedToID:    template.AssignedToID,
+			CompanyID:       master.CompanyID,
+			Attendees:       pq.StringArray(template.Attendees),
+			SeriesID:        &master.ID,
+			OccurrenceAt:    &occurrenceAt,
+		}
+		// scheduled_at and due_date have no time zone and hold UTC
+		start := occurrenceAt.UTC()
+		if template.AllDay {
+			occurrence.DueDate = &start
+		} else {
+			occurrence.ScheduledAt = &start
+		}
+		occurrences = append(occurrences, occurrence)
+	}
+
+	created, err := s.activityRepo.CreateOccurrences(ctx, occurrences)
+	if err != nil {
+		return 0, err
+	}
+	if err := s.activityRepo.SetRecurrenceExpandedUntil(ctx, master.ID, until); err != nil {
+		return 0, fmt.Errorf("failed to record recurrence expansion: %w", err)
+	}
+	master.RecurrenceExpandedUntil = &until
+
+	if created > 0 {
+		s.logger.Info("recurring activity expanded",
+			zap.String("activity_id", master.ID.String()),
+			zap.Int64("occurrences_created", created),
+			zap.Time("until", until))
+	}
+	return int(created), nil
+}
+
+// getSeriesMaster returns the master of the series an activity belongs to
+func (s *ActivityService) getSeriesMaster(ctx context.Context, activity *domain.Activity) (*domain.Activity, error) {
+	if activity.IsSeriesMaster() {
+		return activity, nil
+	}
+	master, err := s.activityRepo.GetByID(ctx, *activity.SeriesID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get series master: %w", err)
+	}
+	return master, nil
+}
+
+// occurrencesBefore returns how many occurrences of a series start before t
+func occurrencesBefore(rule *rrule.Rule, start, t time.Time) int {
+	return len(rule.Between(start, start, t.Add(-time.Second)))
+}
+
+// updateSeries applies an update with scope following or all to the series an activity belongs to.
+// The activity already has the requested changes; previous holds its values before the update.
+func (s *ActivityService) updateSeries(ctx context.Context, activity, previous *domain.Activity, req *domain.UpdateActivityRequest, scope domain.RecurrenceScope) error {
+	master, err := s.getSeriesMaster(ctx, activity)
+	if err != nil {
+		return err
+	}
+
+	var rule *rrule.Rule
+	ruleValue := master.RecurrenceRule
+	if req.RecurrenceRule != nil {
+		ruleValue = *req.RecurrenceRule
+	}
+	if ruleValue != "" {
+		if rule, err = parseRecurrenceRule(ruleValue); err != nil {
+			return err
+		}
+	}
+
+	if scope == domain.RecurrenceScopeFollowing && master.ID != activity.ID {
+		return s.splitSeries(ctx, master, activity, rule, req.RecurrenceRule != nil)
+	}
+	return s.updateWholeSeries(ctx, master, activity, previous, rule)
+}
+
+// updateWholeSeries updates the template and rule of a series from an edited occurrence. Regular
+// open occurrences from now on are generated again; moving the occurrence moves the whole series.
+// When the series is moved or its rule changes, open occurrences edited on their own are replaced too.
+func (s *ActivityService) updateWholeSeries(ctx context.Context, master, activity, previous *domain.Activity, rule *rrule.Rule) error {
+	template, err := decodeRecurrenceTemplate(master)
+	if err != nil {
+		return err
+	}
+	oldStart, _ := recurrenceAnchor(previous, template.AllDay)
+	newStart, ok := recurrenceAnchor(activity, template.AllDay)
+	if !ok {
+		return ErrRecurrenceRequiresSchedule
+	}
+	shift := time.Duration(0)
+	if !oldStart.IsZero() {
+		shift = newStart.Sub(oldStart)
+	}
+
+	encoded, err := encodeRecurrenceTemplate(recurrenceTemplateFrom(activity, template.AllDay))
+	if err != nil {
+		return err
+	}
+
+	ruleValue := ""
+	if rule != nil {
+		ruleValue = rule.String()
+	}
+	rescheduled := shift != 0 || ruleValue != master.RecurrenceRule
+
+	now := time.Now()
+	deleted, err := s.activityRepo.DeleteSeriesOccurrences(ctx, master.ID, now, rescheduled, activity.ID)
+	if err != nil {
+		return err
+	}
+
+	if activity.ID != master.ID {
+		// The edited occurrence follows the series again
+		activity.IsRecurrenceException = false
+		if activity.OccurrenceAt != nil {
+			shifted := activity.OccurrenceAt.Add(shift)
+			activity.OccurrenceAt = &shifted
+		}
+		if err := s.activityRepo.Update(ctx, activity); err != nil {
+			return fmt.Errorf("failed to update activity: %w", err)
+		}
+	}
+
+	shifted := master.OccurrenceAt.Add(shift)
+	master.OccurrenceAt = &shifted
+	if activity.ID != master.ID && !master.IsRecurrenceException &&
+		master.Status != domain.ActivityStatusCompleted && shifted.After(now) {
+		// A master that has not taken place yet is an occurrence like the others
+		applyRecurrenceTemplate(master, recurrenceTemplateFrom(activity, template.AllDay), shifted)
+	}
+	master.RecurrenceTemplate = encoded
+	master.RecurrenceRule = ruleValue
+	master.RecurrenceExpandedUntil = nil
+	if rule != nil {
+		master.RecurrenceExpandedUntil = &now
+	}
+	if err := s.activityRepo.Update(ctx, master); err != nil {
+		return fmt.Errorf("failed to update series: %w", err)
+	}
+
+	created, err := s.expandSeries(ctx, master, s.recurrenceHorizon())
+	if err != nil {
+		return err
+	}
+
+	s.logger.Info("recurring series updated",
+		zap.String("series_id", master.ID.String()),
+		zap.Int64("occurrences_deleted", deleted),
+		zap.Int("occurrences_created", created))
+	return nil
+}
+
+// splitSeries ends a series before an edited occurrence and makes the occurrence the master of a new
+// series with the edited fields. Without a rule change the new series continues the old rule,
+// including the occurrences left of a COUNT.
+func (s *ActivityService) splitSeries(ctx context.Context, master, activity *domain.Activity, rule *rrule.Rule, ruleChanged bool) error {
+	cut := *activity.OccurrenceAt
+	if master.RecurrenceRule != "" {
+		oldRule, err := parseRecurrenceRule(master.RecurrenceRule)
+		if err != nil {
+			return err
+		}
+		if !ruleChanged && rule != nil && oldRule.Count > 0 {
+			template, err := decodeRecurrenceTemplate(master)
+			if err != nil {
+				return err
+			}
+			remaining := oldRule.Count - occurrencesBefore(oldRule, seriesStart(master, template), cut)
+			if remaining < 1 {
+				remaining = 1
+			}
+			rule = rule.WithCount(remaining)
+		}
+		master.RecurrenceRule = oldRule.WithUntil(cut.Add(-time.Second)).String()
+	}
+
+	// Validate the new series before changing the old one
+	next := *activity
+	if err := startSeries(&next, rule); err != nil {
+		return err
+	}
+
+	deleted, err := s.activityRepo.DeleteSeriesOccurrences(ctx, master.ID, cut, false, activity.ID)
+	if err != nil {
+		return err
+	}
+	if err := s.activityRepo.Update(ctx, master); err != nil {
+		return fmt.Errorf("failed to update series: %w", err)
+	}
+
+	*activity = next
+	if err := s.activityRepo.Update(ctx, activity); err != nil {
+		return fmt.Errorf("failed to update activity: %w", err)
+	}
+	created, err := s.expandSeries(ctx, activity, s.recurrenceHorizon())
+	if err != nil {
+		return err
+	}
+
+	s.logger.Info("recurring series split",
+		zap.String("series_id", master.ID.String()),
+		zap.String("new_series_id", activity.ID.String()),
+		zap.Int64("occurrences_deleted", deleted),
+		zap.Int("occurrences_created", created))
+	return nil
+}
+
+// applyRecurrenceTemplate sets the fields of an occurrence from a series template. The start is
+// stored in UTC, as scheduled_at and due_date have no time zone.
+func applyRecurrenceTemplate(activity *domain.Activity, template *domain.ActivityRecurrenceTemplate, start time.Time) {
+	start = start.UTC()
+	activity.Title = template.Title
+	activity.Body = template.Body
+	activity.DurationMinutes = template.DurationMinutes
+	activity.Priority = template.Priority
+	activity.IsPrivate = template.IsPrivate
+	activity.AssignedToID = template.AssignedToID
+	activity.Attendees = pq.StringArray(template.Attendees)
+	if template.AllDay {
+		activity.DueDate = &start
+	} else {
+		activity.ScheduledAt = &start
+	}
+}
+
+// deleteFromSeries deletes an activity of a recurring series with the given scope. Completed
+// occurrences are kept with scope following and all, unless they are the deleted activity itself.
+func (s *ActivityService) deleteFromSeries(ctx context.Context, activity *domain.Activity, scope domain.RecurrenceScope) error {
+	master, err := s.getSeriesMaster(ctx, activity)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case scope == domain.RecurrenceScopeThis && activity.ID == master.ID:
+		return s.deleteSeriesMaster(ctx, master)
+
+	case scope == domain.RecurrenceScopeThis:
+		// The expansion watermark keeps the occurrence from being generated again
+		return s.activityRepo.Delete(ctx, activity.ID)
+
+	case scope == domain.RecurrenceScopeFollowing && activity.ID != master.ID:
+		cut := *activity.OccurrenceAt
+		if master.RecurrenceRule != "" {
+			rule, err := parseRecurrenceRule(master.RecurrenceRule)
+			if err != nil {
+				return err
+			}
+			master.RecurrenceRule = rule.WithUntil(cut.Add(-time.Second)).String()
+			if err := s.activityRepo.Update(ctx, master); err != nil {
+				return fmt.Errorf("failed to update series: %w", err)
+			}
+		}
+		if _, err := s.activityRepo.DeleteSeriesOccurrences(ctx, master.ID, cut, true); err != nil {
+			return err
+		}
+		if err := s.activityRepo.Delete(ctx, activity.ID); err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		return nil
+
+	default:
+		// Following from the master, or all
+		if _, err := s.activityRepo.DeleteSeriesOccurrences(ctx, master.ID, time.Time{}, true); err != nil {
+			return err
+		}
+		if activity.ID != master.ID {
+			if err := s.activityRepo.Delete(ctx, activity.ID); err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+		}
+		if master.Status == domain.ActivityStatusCompleted && activity.ID != master.ID {
+			// Keep the completed master as history, but end the series
+			master.RecurrenceRule = ""
+			return s.activityRepo.Update(ctx, master)
+		}
+		return s.activityRepo.Delete(ctx, master.ID)
+	}
+}
+
+// deleteSeriesMaster deletes only the master of a series. The next occurrence becomes the master,
+// taking over the rule and template.
+func (s *ActivityService) deleteSeriesMaster(ctx context.Context, master *domain.Activity) error {
+	next, err := s.activityRepo.GetNextInSeries(ctx, master.ID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return s.activityRepo.Delete(ctx, master.ID)
+		}
+		return fmt.Errorf("failed to get next occurrence: %w", err)
+	}
+
+	if master.RecurrenceRule != "" {
+		rule, err := parseRecurrenceRule(master.RecurrenceRule)
+		if err != nil {
+			return err
+		}
+		if rule.Count > 0 {
+			template, err := decodeRecurrenceTemplate(master)
+			if err != nil {
+				return err
+			}
+			remaining := rule.Count - occurrencesBefore(rule, seriesStart(master, template), *next.OccurrenceAt)
+			if remaining < 1 {
+				remaining = 1
+			}
+			rule = rule.WithCount(remaining)
+		}
+		next.RecurrenceRule = rule.String()
+	}
+	next.RecurrenceTemplate = master.RecurrenceTemplate
+	next.RecurrenceExpandedUntil = master.RecurrenceExpandedUntil
+	id := next.ID
+	next.SeriesID = &id
+
+	if err := s.activityRepo.MoveSeries(ctx, master.ID, next.ID); err != nil {
+		return fmt.Errorf("failed to move series: %w", err)
+	}
+	if err := s.activityRepo.Update(ctx, next); err != nil {
+		return fmt.Errorf("failed to update series: %w", err)
+	}
+	return s.activityRepo.Delete(ctx, master.ID)
+}
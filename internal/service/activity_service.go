@@ -12,6 +12,7 @@ import (
 	"github.com/straye-as/relation-api/internal/domain"
 	"github.com/straye-as/relation-api/internal/mapper"
 	"github.com/straye-as/relation-api/internal/repository"
+	"github.com/straye-as/relation-api/internal/rrule"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
@@ -26,13 +27,19 @@ var (
 	ErrAttendeeAlreadyAdded            = errors.New("user is already an attendee")
 	ErrAttendeeNotFound                = errors.New("attendee not found")
 	ErrFollowUpRequiresCompletedParent = errors.New("follow-up can only be created from a completed activity")
+	ErrInvalidRecurrenceRule           = rrule.ErrInvalidRule
+	ErrUnsupportedRecurrenceRule       = rrule.ErrUnsupportedRule
+	ErrRecurrenceRequiresSchedule      = errors.New("recurring activities require a scheduled time or due date")
+	ErrInvalidRecurrenceScope          = errors.New("invalid recurrence scope")
+	ErrRecurrenceRuleRequiresScope     = errors.New("changing the recurrence rule of a series requires scope following or all")
 )
 
 // ActivityService handles business logic for activities (meetings, tasks, calls, emails, notes)
 type ActivityService struct {
-	activityRepo        *repository.ActivityRepository
-	notificationService *NotificationService
-	logger              *zap.Logger
+	activityRepo          *repository.ActivityRepository
+	notificationService   *NotificationService
+	logger                *zap.Logger
+	recurrenceHorizonDays int
 }
 
 // NewActivityService creates a new ActivityService instance
@@ -42,9 +49,10 @@ func NewActivityService(
 	logger *zap.Logger,
 ) *ActivityService {
 	return &ActivityService{
-		activityRepo:        activityRepo,
-		notificationService: notificationService,
-		logger:              logger,
+		activityRepo:          activityRepo,
+		notificationService:   notificationService,
+		logger:                logger,
+		recurrenceHorizonDays: defaultRecurrenceHorizonDays,
 	}
 }
 
@@ -114,6 +122,17 @@ func (s *ActivityService) Create(ctx context.Context, req *domain.CreateActivity
 		}
 	}
 
+	// Recurring activities become the master of a series, which holds the rule
+	if req.RecurrenceRule != "" {
+		rule, err := parseRecurrenceRule(req.RecurrenceRule)
+		if err != nil {
+			return nil, err
+		}
+		if err := startSeries(activity, rule); err != nil {
+			return nil, err
+		}
+	}
+
 	if err := s.activityRepo.Create(ctx, activity); err != nil {
 		return nil, fmt.Errorf("failed to create activity: %w", err)
 	}
@@ -124,6 +143,16 @@ func (s *ActivityService) Create(ctx context.Context, req *domain.CreateActivity
 		zap.String("creator_id", activity.CreatorID),
 	)
 
+	// Generate the occurrences; the expansion job retries on failure
+	if activity.RecurrenceRule != "" {
+		if _, err := s.expandSeries(ctx, activity, s.recurrenceHorizon()); err != nil {
+			s.logger.Warn("failed to expand recurring activity",
+				zap.String("activity_id", activity.ID.String()),
+				zap.Error(err),
+			)
+		}
+	}
+
 	// Send notification if task is assigned to someone other than creator
 	if req.AssignedToID != "" && req.AssignedToID != userCtx.UserID.String() {
 		s.sendTaskAssignmentNotification(ctx, activity, userCtx.DisplayName)
@@ -163,6 +192,18 @@ func (s *ActivityService) Update(ctx context.Context, id uuid.UUID, req *domain.
 		return nil, ErrActivityForbidden
 	}
 
+	scope := req.RecurrenceScope
+	if scope == "" {
+		scope = domain.RecurrenceScopeThis
+	}
+	if !scope.IsValid() {
+		return nil, ErrInvalidRecurrenceScope
+	}
+	if activity.SeriesID != nil && scope == domain.RecurrenceScopeThis && req.RecurrenceRule != nil {
+		return nil, ErrRecurrenceRuleRequiresScope
+	}
+	previous := *activity
+
 	// Track if assignment changed for notification
 	oldAssignedToID := activity.AssignedToID
 	assignmentChanged := req.AssignedToID != oldAssignedToID && req.AssignedToID != ""
@@ -194,8 +235,39 @@ func (s *ActivityService) Update(ctx context.Context, id uuid.UUID, req *domain.
 		activity.Attendees = pq.StringArray(req.Attendees)
 	}
 
-	if err := s.activityRepo.Update(ctx, activity); err != nil {
-		return nil, fmt.Errorf("failed to update activity: %w", err)
+	switch {
+	case activity.SeriesID == nil:
+		// A single activity becomes recurring when given a rule
+		if req.RecurrenceRule != nil && *req.RecurrenceRule != "" {
+			rule, err := parseRecurrenceRule(*req.RecurrenceRule)
+			if err != nil {
+				return nil, err
+			}
+			if err := startSeries(activity, rule); err != nil {
+				return nil, err
+			}
+		}
+		if err := s.activityRepo.Update(ctx, activity); err != nil {
+			return nil, fmt.Errorf("failed to update activity: %w", err)
+		}
+		if activity.RecurrenceRule != "" {
+			if _, err := s.expandSeries(ctx, activity, s.recurrenceHorizon()); err != nil {
+				s.logger.Warn("failed to expand recurring activity",
+					zap.String("activity_id", activity.ID.String()),
+					zap.Error(err),
+				)
+			}
+		}
+	case scope == domain.RecurrenceScopeThis:
+		// The occurrence no longer follows the series
+		activity.IsRecurrenceException = true
+		if err := s.activityRepo.Update(ctx, activity); err != nil {
+			return nil, fmt.Errorf("failed to update activity: %w", err)
+		}
+	default:
+		if err := s.updateSeries(ctx, activity, &previous, req, scope); err != nil {
+			return nil, err
+		}
 	}
 
 	s.logger.Info("activity updated",
@@ -222,8 +294,21 @@ func (s *ActivityService) Update(ctx context.Context, id uuid.UUID, req *domain.
 	return &dto, nil
 }
 
-// Delete removes an activity
+// Delete removes an activity. Of a recurring series only the given occurrence is removed.
 func (s *ActivityService) Delete(ctx context.Context, id uuid.UUID) error {
+	return s.DeleteWithScope(ctx, id, domain.RecurrenceScopeThis)
+}
+
+// DeleteWithScope removes an activity, and with scope following or all also the open occurrences of
+// its recurring series from it on or all of them. Completed occurrences are kept as history.
+func (s *ActivityService) DeleteWithScope(ctx context.Context, id uuid.UUID, scope domain.RecurrenceScope) error {
+	if scope == "" {
+		scope = domain.RecurrenceScopeThis
+	}
+	if !scope.IsValid() {
+		return ErrInvalidRecurrenceScope
+	}
+
 	userCtx, ok := auth.FromContext(ctx)
 	if !ok {
 		return ErrUserContextRequired
@@ -243,12 +328,17 @@ func (s *ActivityService) Delete(ctx context.Context, id uuid.UUID) error {
 		return ErrActivityForbidden
 	}
 
-	if err := s.activityRepo.Delete(ctx, id); err != nil {
+	if activity.SeriesID != nil {
+		if err := s.deleteFromSeries(ctx, activity, scope); err != nil {
+			return fmt.Errorf("failed to delete activity: %w", err)
+		}
+	} else if err := s.activityRepo.Delete(ctx, id); err != nil {
 		return fmt.Errorf("failed to delete activity: %w", err)
 	}
 
 	s.logger.Info("activity deleted",
 		zap.String("activity_id", id.String()),
+		zap.String("scope", string(scope)),
 		zap.String("deleted_by", userCtx.UserID.String()),
 	)
 
@@ -364,6 +454,18 @@ func (s *ActivityService) Complete(ctx context.Context, id uuid.UUID, outcome st
 		zap.String("completed_by", userCtx.UserID.String()),
 	)
 
+	// A completed occurrence stays linked to its series; make sure the next ones exist
+	if activity.SeriesID != nil {
+		if master, err := s.getSeriesMaster(ctx, activity); err == nil {
+			if _, err := s.expandSeries(ctx, master, s.recurrenceHorizon()); err != nil {
+				s.logger.Warn("failed to expand recurring activity",
+					zap.String("activity_id", master.ID.String()),
+					zap.Error(err),
+				)
+			}
+		}
+	}
+
 	dto := mapper.ToActivityDTO(activity)
 	return &dto, nil
 }
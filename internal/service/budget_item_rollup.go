@@ -0,0 +1,219 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/straye-as/relation-api/internal/domain"
+)
+
+// GetProjectBudgetRollup compares the budget items of the project's won offers with the project's
+// working budget and the actual costs synced from the data warehouse
+func (s *BudgetItemService) GetProjectBudgetRollup(ctx context.Context, projectID uuid.UUID) (*domain.ProjectBudgetRollupDTO, error) {
+	project, err := s.projectRepo.GetByID(ctx, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("project not found: %w", err)
+	}
+
+	offers, err := s.offerRepo.ListWonByProject(ctx, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list won offers: %w", err)
+	}
+	offerIDs := make([]uuid.UUID, len(offers))
+	for i, offer := range offers {
+		offerIDs[i] = offer.ID
+	}
+	soldItems, err := s.budgetItemRepo.ListByParents(ctx, domain.BudgetParentOffer, offerIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list offer budget items: %w", err)
+	}
+	projectItems, err := s.budgetItemRepo.ListByParent(ctx, domain.BudgetParentProject, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list project budget items: %w", err)
+	}
+
+	rollup := BuildProjectBudgetRollup(project, offers, soldItems, projectItems)
+	return &rollup, nil
+}
+
+// budgetEntry is a budget item the data warehouse costs of its cost category are split over
+type budgetEntry struct {
+	line     int
+	category domain.BudgetCostCategory
+	cost     float64
+}
+
+// BuildProjectBudgetRollup builds the budget roll-up of a project. Each sold item is a line with the
+// project items rebudgeting it; project items without a sold item get their own line. Until the
+// project has budget items of its own, what was sold is the budget.
+//
+// The data warehouse only has the won offers' costs per cost category (material, employee, other),
+// so each category's costs are split over the budget items in it by budget cost. Costs in a
+// category no budget item is tracked in are reported on an unallocated line.
+func BuildProjectBudgetRollup(project *domain.Project, offers []domain.Offer, soldItems, projectItems []domain.BudgetItem) domain.ProjectBudgetRollupDTO {
+	rollup := domain.ProjectBudgetRollupDTO{
+		ProjectID:    project.ID,
+		ProjectName:  project.Name,
+		BudgetSource: domain.BudgetParentOffer,
+		Offers:       make([]domain.ProjectBudgetOfferDTO, 0, len(offers)),
+		Lines:        []domain.ProjectBudgetRollupLineDTO{},
+	}
+	if len(projectItems) > 0 {
+		rollup.BudgetSource = domain.BudgetParentProject
+	}
+
+	actuals := make(map[domain.BudgetCostCategory]float64, 3)
+	offerOrder := make(map[uuid.UUID]int, len(offers))
+	for i, offer := range offers {
+		offerOrder[offer.ID] = i
+		actuals[domain.BudgetCostCategoryMaterial] += offer.DWMaterialCosts
+		actuals[domain.BudgetCostCategoryEmployee] += offer.DWEmployeeCosts
+		actuals[domain.BudgetCostCategoryOther] += offer.DWOtherCosts
+		rollup.Totals.ActualIncome += offer.DWTotalIncome
+
+		dto := domain.ProjectBudgetOfferDTO{
+			ID:          offer.ID,
+			Title:       offer.Title,
+			OfferNumber: offer.OfferNumber,
+			Phase:       offer.Phase,
+			Value:       offer.Value,
+		}
+		if offer.DWLastSyncedAt != nil {
+			syncedAt := offer.DWLastSyncedAt.UTC().Format(time.RFC3339)
+			dto.DWLastSyncedAt = &syncedAt
+		}
+		rollup.Offers = append(rollup.Offers, dto)
+	}
+
+	var entries []budgetEntry
+	soldLines := make(map[uuid.UUID]int, len(soldItems))
+	for _, item := range soldItems {
+		offerID, itemID := item.ParentID, item.ID
+		if _, ok := offerOrder[offerID]; !ok {
+			continue
+		}
+		line := domain.ProjectBudgetRollupLineDTO{
+			Name:          item.Name,
+			CostCategory:  budgetItemCategory(&item),
+			OfferID:       &offerID,
+			SoldItemID:    &itemID,
+			BudgetItemIDs: []uuid.UUID{},
+			SoldCost:      item.ExpectedCost,
+			SoldRevenue:   item.ExpectedRevenue,
+		}
+		soldLines[item.ID] = len(rollup.Lines)
+		if rollup.BudgetSource == domain.BudgetParentOffer {
+			line.BudgetCost = item.ExpectedCost
+			line.BudgetRevenue = item.ExpectedRevenue
+			entries = append(entries, budgetEntry{line: len(rollup.Lines), category: line.CostCategory, cost: item.ExpectedCost})
+		}
+		rollup.Lines = append(rollup.Lines, line)
+	}
+
+	for _, item := range projectItems {
+		index, linked := -1, false
+		if item.SourceItemID != nil {
+			index, linked = soldLines[*item.SourceItemID]
+		}
+		if !linked {
+			index = len(rollup.Lines)
+			rollup.Lines = append(rollup.Lines, domain.ProjectBudgetRollupLineDTO{
+				Name:          item.Name,
+				BudgetItemIDs: []uuid.UUID{},
+			})
+		}
+		line := &rollup.Lines[index]
+		category := budgetItemCategory(&item)
+		if len(line.BudgetItemIDs) == 0 {
+			line.CostCategory = category
+		}
+		line.BudgetItemIDs = append(line.BudgetItemIDs, item.ID)
+		line.BudgetCost += item.ExpectedCost
+		line.BudgetRevenue += item.ExpectedRevenue
+		entries = append(entries, budgetEntry{line: index, category: category, cost: item.ExpectedCost})
+	}
+
+	// Split each cost category's actuals over its budget entries
+	for _, category := range []domain.BudgetCostCategory{
+		domain.BudgetCostCategoryMaterial,
+		domain.BudgetCostCategoryEmployee,
+		domain.BudgetCostCategoryOther,
+	} {
+		actual := actuals[category]
+		var inCategory []budgetEntry
+		weight := 0.0
+		for _, entry := range entries {
+			if entry.category == category {
+				inCategory = append(inCategory, entry)
+				weight += entry.cost
+			}
+		}
+		if len(inCategory) == 0 {
+			if actual != 0 {
+				rollup.Lines = append(rollup.Lines, domain.ProjectBudgetRollupLineDTO{
+					Name:          string(category),
+					CostCategory:  category,
+					BudgetItemIDs: []uuid.UUID{},
+					ActualCost:    actual,
+					Unallocated:   true,
+				})
+			}
+			continue
+		}
+		for _, entry := range inCategory {
+			share := 1 / float64(len(inCategory))
+			if weight > 0 {
+				share = entry.cost / weight
+			}
+			rollup.Lines[entry.line].ActualCost += actual * share
+		}
+	}
+
+	totals := &rollup.Totals
+	for i := range rollup.Lines {
+		line := &rollup.Lines[i]
+		totals.SoldCost += line.SoldCost
+		totals.SoldRevenue += line.SoldRevenue
+		totals.BudgetCost += line.BudgetCost
+		totals.BudgetRevenue += line.BudgetRevenue
+		totals.ActualCost += line.ActualCost
+
+		line.SoldCost = roundAmount(line.SoldCost)
+		line.SoldRevenue = roundAmount(line.SoldRevenue)
+		line.BudgetCost = roundAmount(line.BudgetCost)
+		line.BudgetRevenue = roundAmount(line.BudgetRevenue)
+		line.ActualCost = roundAmount(line.ActualCost)
+		line.RemainingCost = roundAmount(line.BudgetCost - line.ActualCost)
+		line.Rebudgeted = roundAmount(line.BudgetCost - line.SoldCost)
+		line.PercentSpent = percentSpent(line.ActualCost, line.BudgetCost)
+	}
+	totals.SoldCost = roundAmount(totals.SoldCost)
+	totals.SoldRevenue = roundAmount(totals.SoldRevenue)
+	totals.BudgetCost = roundAmount(totals.BudgetCost)
+	totals.BudgetRevenue = roundAmount(totals.BudgetRevenue)
+	totals.ActualCost = roundAmount(totals.ActualCost)
+	totals.ActualIncome = roundAmount(totals.ActualIncome)
+	totals.RemainingCost = roundAmount(totals.BudgetCost - totals.ActualCost)
+	totals.Rebudgeted = roundAmount(totals.BudgetCost - totals.SoldCost)
+	totals.PercentSpent = percentSpent(totals.ActualCost, totals.BudgetCost)
+
+	return rollup
+}
+
+func budgetItemCategory(item *domain.BudgetItem) domain.BudgetCostCategory {
+	if item.CostCategory == nil {
+		return ""
+	}
+	return *item.CostCategory
+}
+
+// percentSpent returns actual as a percentage of budget, nil without a budget
+func percentSpent(actual, budget float64) *float64 {
+	if budget <= 0 {
+		return nil
+	}
+	percent := roundAmount(actual / budget * 100)
+	return &percent
+}
@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"fmt"
+	"sort"
 
 	"github.com/google/uuid"
 	"github.com/straye-as/relation-api/internal/domain"
@@ -36,6 +37,11 @@ func NewBudgetItemService(
 
 // Create creates a new budget item
 func (s *BudgetItemService) Create(ctx context.Context, req domain.CreateBudgetItemRequest) (*domain.BudgetItemDTO, error) {
+	return s.create(ctx, req, nil)
+}
+
+// create creates a budget item, optionally rebudgeting an offer item
+func (s *BudgetItemService) create(ctx context.Context, req domain.CreateBudgetItemRequest, sourceItemID *uuid.UUID) (*domain.BudgetItemDTO, error) {
 	// Validate parent exists
 	if err := s.validateParent(ctx, req.ParentType, req.ParentID); err != nil {
 		return nil, err
@@ -62,6 +68,8 @@ func (s *BudgetItemService) Create(ctx context.Context, req domain.CreateBudgetI
 		PricePerItem:   req.PricePerItem,
 		Description:    req.Description,
 		DisplayOrder:   displayOrder,
+		CostCategory:   costCategoryPtr(req.CostCategory),
+		SourceItemID:   sourceItemID,
 	}
 
 	if err := s.budgetItemRepo.Create(ctx, item); err != nil {
@@ -113,6 +121,7 @@ func (s *BudgetItemService) Update(ctx context.Context, id uuid.UUID, req domain
 	item.PricePerItem = req.PricePerItem
 	item.Description = req.Description
 	item.DisplayOrder = req.DisplayOrder
+	item.CostCategory = costCategoryPtr(req.CostCategory)
 
 	if err := s.budgetItemRepo.Update(ctx, item); err != nil {
 		s.logger.Error("Failed to update budget item", zap.Error(err))
@@ -244,6 +253,7 @@ func (s *BudgetItemService) AddToOffer(ctx context.Context, offerID uuid.UUID, r
 		PricePerItem:   req.PricePerItem,
 		Description:    req.Description,
 		DisplayOrder:   req.DisplayOrder,
+		CostCategory:   req.CostCategory,
 	}
 
 	return s.Create(ctx, createReq)
@@ -251,48 +261,194 @@ func (s *BudgetItemService) AddToOffer(ctx context.Context, offerID uuid.UUID, r
 
 // UpdateOfferDimension updates a budget item belonging to an offer
 func (s *BudgetItemService) UpdateOfferDimension(ctx context.Context, offerID, itemID uuid.UUID, req domain.UpdateBudgetItemRequest) (*domain.BudgetItemDTO, error) {
-	// Verify item belongs to this offer
+	return s.updateDimension(ctx, domain.BudgetParentOffer, offerID, itemID, req)
+}
+
+// DeleteOfferDimension deletes a budget item from an offer
+func (s *BudgetItemService) DeleteOfferDimension(ctx context.Context, offerID, itemID uuid.UUID) error {
+	return s.deleteDimension(ctx, domain.BudgetParentOffer, offerID, itemID)
+}
+
+// ReorderOfferDimensions reorders budget items for an offer
+func (s *BudgetItemService) ReorderOfferDimensions(ctx context.Context, offerID uuid.UUID, req domain.ReorderBudgetItemsRequest) error {
+	return s.reorderDimensions(ctx, domain.BudgetParentOffer, offerID, req)
+}
+
+// AddToProject adds a budget item to a project's working budget. A source item must be a budget
+// item of one of the project's won offers; the offer itself is left untouched.
+func (s *BudgetItemService) AddToProject(ctx context.Context, projectID uuid.UUID, req domain.AddProjectBudgetItemRequest) (*domain.BudgetItemDTO, error) {
+	// Verify project exists
+	_, err := s.projectRepo.GetByID(ctx, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("project not found: %w", err)
+	}
+
+	if req.SourceItemID != nil {
+		if err := s.validateSourceItem(ctx, projectID, *req.SourceItemID); err != nil {
+			return nil, err
+		}
+	}
+
+	createReq := domain.CreateBudgetItemRequest{
+		ParentType:     domain.BudgetParentProject,
+		ParentID:       projectID,
+		Name:           req.Name,
+		ExpectedCost:   req.ExpectedCost,
+		ExpectedMargin: req.ExpectedMargin,
+		Quantity:       req.Quantity,
+		PricePerItem:   req.PricePerItem,
+		Description:    req.Description,
+		DisplayOrder:   req.DisplayOrder,
+		CostCategory:   req.CostCategory,
+	}
+
+	return s.create(ctx, createReq, req.SourceItemID)
+}
+
+// UpdateProjectDimension updates a budget item belonging to a project
+func (s *BudgetItemService) UpdateProjectDimension(ctx context.Context, projectID, itemID uuid.UUID, req domain.UpdateBudgetItemRequest) (*domain.BudgetItemDTO, error) {
+	return s.updateDimension(ctx, domain.BudgetParentProject, projectID, itemID, req)
+}
+
+// DeleteProjectDimension deletes a budget item from a project
+func (s *BudgetItemService) DeleteProjectDimension(ctx context.Context, projectID, itemID uuid.UUID) error {
+	return s.deleteDimension(ctx, domain.BudgetParentProject, projectID, itemID)
+}
+
+// ReorderProjectDimensions reorders budget items for a project
+func (s *BudgetItemService) ReorderProjectDimensions(ctx context.Context, projectID uuid.UUID, req domain.ReorderBudgetItemsRequest) error {
+	return s.reorderDimensions(ctx, domain.BudgetParentProject, projectID, req)
+}
+
+// InitializeProjectBudget copies the budget items of the project's won offers into the project so
+// it can be rebudgeted without changing what was sold. Each copy links back to its offer item.
+func (s *BudgetItemService) InitializeProjectBudget(ctx context.Context, projectID uuid.UUID) ([]domain.BudgetItemDTO, error) {
+	if _, err := s.projectRepo.GetByID(ctx, projectID); err != nil {
+		return nil, fmt.Errorf("project not found: %w", err)
+	}
+
+	count, err := s.budgetItemRepo.CountByParent(ctx, domain.BudgetParentProject, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count project budget items: %w", err)
+	}
+	if count > 0 {
+		return nil, ErrProjectBudgetNotEmpty
+	}
+
+	offers, err := s.offerRepo.ListWonByProject(ctx, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list won offers: %w", err)
+	}
+	if len(offers) == 0 {
+		return nil, ErrProjectHasNoWonOffer
+	}
+	offerIDs := make([]uuid.UUID, len(offers))
+	for i, offer := range offers {
+		offerIDs[i] = offer.ID
+	}
+	soldItems, err := s.budgetItemRepo.ListByParents(ctx, domain.BudgetParentOffer, offerIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list offer budget items: %w", err)
+	}
+
+	// Keep the offers' order, largest offer first
+	offerOrder := make(map[uuid.UUID]int, len(offers))
+	for i, offer := range offers {
+		offerOrder[offer.ID] = i
+	}
+	sort.SliceStable(soldItems, func(i, j int) bool {
+		return offerOrder[soldItems[i].ParentID] < offerOrder[soldItems[j].ParentID]
+	})
+
+	items := make([]domain.BudgetItem, len(soldItems))
+	for i, sold := range soldItems {
+		sourceID := sold.ID
+		items[i] = domain.BudgetItem{
+			ParentType:     domain.BudgetParentProject,
+			ParentID:       projectID,
+			Name:           sold.Name,
+			ExpectedCost:   sold.ExpectedCost,
+			ExpectedMargin: sold.ExpectedMargin,
+			Quantity:       sold.Quantity,
+			PricePerItem:   sold.PricePerItem,
+			Description:    sold.Description,
+			DisplayOrder:   i + 1,
+			CostCategory:   sold.CostCategory,
+			SourceItemID:   &sourceID,
+		}
+	}
+	if err := s.budgetItemRepo.CreateBatch(ctx, items); err != nil {
+		s.logger.Error("Failed to initialize project budget", zap.String("projectId", projectID.String()), zap.Error(err))
+		return nil, fmt.Errorf("failed to initialize project budget: %w", err)
+	}
+
+	return s.ListByProject(ctx, projectID)
+}
+
+// updateDimension updates a budget item after checking it belongs to the parent
+func (s *BudgetItemService) updateDimension(ctx context.Context, parentType domain.BudgetParentType, parentID, itemID uuid.UUID, req domain.UpdateBudgetItemRequest) (*domain.BudgetItemDTO, error) {
+	// Verify item belongs to this parent
 	item, err := s.budgetItemRepo.GetByID(ctx, itemID)
 	if err != nil {
 		return nil, fmt.Errorf("budget item not found: %w", err)
 	}
-	if item.ParentType != domain.BudgetParentOffer || item.ParentID != offerID {
-		return nil, fmt.Errorf("budget item does not belong to this offer")
+	if item.ParentType != parentType || item.ParentID != parentID {
+		return nil, fmt.Errorf("budget item does not belong to this %s", parentType)
 	}
 
 	return s.Update(ctx, itemID, req)
 }
 
-// DeleteOfferDimension deletes a budget item from an offer
-func (s *BudgetItemService) DeleteOfferDimension(ctx context.Context, offerID, itemID uuid.UUID) error {
-	// Verify item belongs to this offer
+// deleteDimension deletes a budget item after checking it belongs to the parent
+func (s *BudgetItemService) deleteDimension(ctx context.Context, parentType domain.BudgetParentType, parentID, itemID uuid.UUID) error {
+	// Verify item belongs to this parent
 	item, err := s.budgetItemRepo.GetByID(ctx, itemID)
 	if err != nil {
 		return fmt.Errorf("budget item not found: %w", err)
 	}
-	if item.ParentType != domain.BudgetParentOffer || item.ParentID != offerID {
-		return fmt.Errorf("budget item does not belong to this offer")
+	if item.ParentType != parentType || item.ParentID != parentID {
+		return fmt.Errorf("budget item does not belong to this %s", parentType)
 	}
 
 	return s.Delete(ctx, itemID)
 }
 
-// ReorderOfferDimensions reorders budget items for an offer
-func (s *BudgetItemService) ReorderOfferDimensions(ctx context.Context, offerID uuid.UUID, req domain.ReorderBudgetItemsRequest) error {
-	// Verify all items belong to this offer
+// reorderDimensions reorders the budget items of a parent
+func (s *BudgetItemService) reorderDimensions(ctx context.Context, parentType domain.BudgetParentType, parentID uuid.UUID, req domain.ReorderBudgetItemsRequest) error {
+	// Verify all items belong to this parent
 	for _, id := range req.OrderedIDs {
 		item, err := s.budgetItemRepo.GetByID(ctx, id)
 		if err != nil {
 			return fmt.Errorf("budget item %s not found: %w", id, err)
 		}
-		if item.ParentType != domain.BudgetParentOffer || item.ParentID != offerID {
-			return fmt.Errorf("budget item %s does not belong to this offer", id)
+		if item.ParentType != parentType || item.ParentID != parentID {
+			return fmt.Errorf("budget item %s does not belong to this %s", id, parentType)
 		}
 	}
 
 	return s.budgetItemRepo.UpdateDisplayOrders(ctx, req.OrderedIDs)
 }
 
+// validateSourceItem checks that an item being rebudgeted belongs to a won offer of the project
+func (s *BudgetItemService) validateSourceItem(ctx context.Context, projectID, sourceItemID uuid.UUID) error {
+	source, err := s.budgetItemRepo.GetByID(ctx, sourceItemID)
+	if err != nil {
+		return fmt.Errorf("source budget item not found: %w", err)
+	}
+	if source.ParentType == domain.BudgetParentOffer {
+		offers, err := s.offerRepo.ListWonByProject(ctx, projectID)
+		if err != nil {
+			return fmt.Errorf("failed to list won offers: %w", err)
+		}
+		for _, offer := range offers {
+			if offer.ID == source.ParentID {
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("source budget item does not belong to a won offer of this project")
+}
+
 // validateParent checks that the parent entity exists
 func (s *BudgetItemService) validateParent(ctx context.Context, parentType domain.BudgetParentType, parentID uuid.UUID) error {
 	switch parentType {
@@ -327,3 +483,11 @@ func (s *BudgetItemService) updateParentTotals(ctx context.Context, parentType d
 
 	return nil
 }
+
+// costCategoryPtr returns nil for an empty category so the item is stored without one
+func costCategoryPtr(category domain.BudgetCostCategory) *domain.BudgetCostCategory {
+	if category == "" {
+		return nil
+	}
+	return &category
+}
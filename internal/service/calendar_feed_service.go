@@ -0,0 +1,287 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/straye-as/relation-api/internal/auth"
+	"github.com/straye-as/relation-api/internal/domain"
+	"github.com/straye-as/relation-api/internal/ical"
+	"github.com/straye-as/relation-api/internal/mapper"
+	"github.com/straye-as/relation-api/internal/repository"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// Calendar feed window and limits
+const (
+	// calendarFeedPastDays is how far back the feed includes activities and deadlines
+	calendarFeedPastDays = 90
+	// calendarFeedFutureDays is how far ahead the feed includes activities and deadlines
+	calendarFeedFutureDays = 365
+	// calendarFeedMaxActivities caps the number of activities in a feed
+	calendarFeedMaxActivities = 2000
+	// calendarFeedRefreshInterval is how often calendar clients are asked to refresh the feed
+	calendarFeedRefreshInterval = time.Hour
+	// defaultActivityDurationMinutes is the length of scheduled activities without a duration
+	defaultActivityDurationMinutes = 30
+	// calendarUIDDomain is the domain part of event UIDs, which must stay stable across feeds
+	calendarUIDDomain = "relation-api.straye"
+)
+
+// osloLocation is the time zone of the business, used for the dates of all-day events and for
+// expanding recurring activities
+var osloLocation = func() *time.Location {
+	loc, err := time.LoadLocation("Europe/Oslo")
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}()
+
+// ErrCalendarFeedNotFound is returned for unknown or revoked calendar feed tokens, and when revoking
+// without an active token
+var ErrCalendarFeedNotFound = errors.New("calendar feed not found")
+
+// CalendarFeedService manages calendar subscription tokens and builds the iCalendar feed of a user's
+// scheduled activities and offer deadlines
+type CalendarFeedService struct {
+	tokenRepo    *repository.CalendarFeedTokenRepository
+	activityRepo *repository.ActivityRepository
+	offerRepo    *repository.OfferRepository
+	userRepo     *repository.UserRepository
+	logger       *zap.Logger
+}
+
+// NewCalendarFeedService creates a new calendar feed service instance
+func NewCalendarFeedService(
+	tokenRepo *repository.CalendarFeedTokenRepository,
+	activityRepo *repository.ActivityRepository,
+	offerRepo *repository.OfferRepository,
+	userRepo *repository.UserRepository,
+	logger *zap.Logger,
+) *CalendarFeedService {
+	return &CalendarFeedService{
+		tokenRepo:    tokenRepo,
+		activityRepo: activityRepo,
+		offerRepo:    offerRepo,
+		userRepo:     userRepo,
+		logger:       logger,
+	}
+}
+
+// GetFeed returns the calendar subscription of the current user
+func (s *CalendarFeedService) GetFeed(ctx context.Context) (*domain.CalendarFeedDTO, error) {
+	userCtx, ok := auth.FromContext(ctx)
+	if !ok {
+		return nil, ErrUserContextRequired
+	}
+
+	token, err := s.tokenRepo.GetActiveByUser(ctx, userCtx.UserID.String())
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to get calendar feed token: %w", err)
+	}
+
+	dto := mapper.ToCalendarFeedDTO(token)
+	return &dto, nil
+}
+
+// CreateToken creates a calendar feed token for the current user. An existing token is revoked, so
+// calendars subscribed with it stop updating. The token is only returned here; the URL is left to
+// the caller.
+func (s *CalendarFeedService) CreateToken(ctx context.Context) (*domain.CalendarFeedTokenDTO, error) {
+	userCtx, ok := auth.FromContext(ctx)
+	if !ok {
+		return nil, ErrUserContextRequired
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, fmt.Errorf("failed to generate calendar feed token: %w", err)
+	}
+	value := base64.RawURLEncoding.EncodeToString(secret)
+
+	token := &domain.CalendarFeedToken{
+		UserID:    userCtx.UserID.String(),
+		UserName:  userCtx.DisplayName,
+		TokenHash: hashCalendarFeedToken(value),
+	}
+	if err := s.tokenRepo.Rotate(ctx, token); err != nil {
+		return nil, fmt.Errorf("failed to create calendar feed token: %w", err)
+	}
+
+	s.logger.Info("calendar feed token created", zap.String("user_id", token.UserID))
+	return &domain.CalendarFeedTokenDTO{
+		Token:     value,
+		CreatedAt: token.CreatedAt.UTC().Format(time.RFC3339),
+	}, nil
+}
+
+// RevokeToken revokes the calendar feed token of the current user
+func (s *CalendarFeedService) RevokeToken(ctx context.Context) error {
+	userCtx, ok := auth.FromContext(ctx)
+	if !ok {
+		return ErrUserContextRequired
+	}
+
+	revoked, err := s.tokenRepo.RevokeByUser(ctx, userCtx.UserID.String())
+	if err != nil {
+		return fmt.Errorf("failed to revoke calendar feed token: %w", err)
+	}
+	if revoked == 0 {
+		return ErrCalendarFeedNotFound
+	}
+
+	s.logger.Info("calendar feed token revoked", zap.String("user_id", userCtx.UserID.String()))
+	return nil
+}
+
+// Feed builds the iCalendar feed for a token. It covers activities assigned to or attended by the
+// token's user and due and expiration dates of open offers the user is responsible for, from 90 days
+// back to a year ahead.
+func (s *CalendarFeedService) Feed(ctx context.Context, value string) (*ical.Calendar, error) {
+	token, err := s.tokenRepo.GetActiveByHash(ctx, hashCalendarFeedToken(value))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCalendarFeedNotFound
+		}
+		return nil, fmt.Errorf("failed to get calendar feed token: %w", err)
+	}
+
+	// Deactivated users keep their tokens but the feed stops working
+	if user, err := s.userRepo.GetByStringID(ctx, token.UserID); err == nil && !user.IsActive {
+		return nil, ErrCalendarFeedNotFound
+	}
+
+	now := time.Now()
+	from := now.AddDate(0, 0, -calendarFeedPastDays)
+	to := now.AddDate(0, 0, calendarFeedFutureDays)
+
+	activities, err := s.activityRepo.ListForCalendar(ctx, token.UserID, from, to, calendarFeedMaxActivities)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list calendar activities: %w", err)
+	}
+	offers, err := s.offerRepo.ListDeadlinesForResponsible(ctx, token.UserID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list offer deadlines: %w", err)
+	}
+
+	name := "Straye CRM"
+	if token.UserName != "" {
+		name += " - " + token.UserName
+	}
+	cal := &ical.Calendar{
+		ProductID:       "-//Straye//Relation API//NO",
+		Name:            name,
+		RefreshInterval: calendarFeedRefreshInterval,
+		Events:          make([]ical.Event, 0, len(activities)+len(offers)),
+	}
+	for i := range activities {
+		cal.Events = append(cal.Events, activityToCalendarEvent(&activities[i]))
+	}
+	for i := range offers {
+		cal.Events = append(cal.Events, offerDeadlineEvents(&offers[i], from, to)...)
+	}
+
+	if err := s.tokenRepo.TouchLastAccessed(ctx, token); err != nil {
+		s.logger.Warn("failed to record calendar feed access", zap.Error(err))
+	}
+	return cal, nil
+}
+
+// hashCalendarFeedToken returns the stored hash of a token value
+func hashCalendarFeedToken(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
+
+// activityToCalendarEvent converts an activity to a calendar event. Scheduled activities are timed
+// events; activities with only a due date are all-day events.
+func activityToCalendarEvent(activity *domain.Activity) ical.Event {
+	event := ical.Event{
+		UID:          fmt.Sprintf("activity-%s@%s", activity.ID, calendarUIDDomain),
+		Summary:      activity.Title,
+		Categories:   []string{string(activity.ActivityType)},
+		Status:       ical.StatusConfirmed,
+		Created:      activity.CreatedAt,
+		LastModified: activity.UpdatedAt,
+	}
+	if activity.Status == domain.ActivityStatusCancelled {
+		event.Status = ical.StatusCancelled
+	}
+
+	var description []string
+	if activity.Body != "" {
+		description = append(description, activity.Body)
+	}
+	if activity.TargetName != "" {
+		description = append(description, "Gjelder: "+activity.TargetName)
+	}
+	event.Description = strings.Join(description, "\n\n")
+
+	if activity.ScheduledAt != nil {
+		minutes := defaultActivityDurationMinutes
+		if activity.DurationMinutes != nil && *activity.DurationMinutes > 0 {
+			minutes = *activity.DurationMinutes
+		}
+		event.Start = *activity.ScheduledAt
+		event.End = activity.ScheduledAt.Add(time.Duration(minutes) * time.Minute)
+	} else if activity.DueDate != nil {
+		event.Start = activity.DueDate.In(osloLocation)
+		event.AllDay = true
+	}
+	return event
+}
+
+// offerDeadlineEvents returns all-day events for the due date and expiration date of an offer that
+// fall within [from, to)
+func offerDeadlineEvents(offer *domain.Offer, from, to time.Time) []ical.Event {
+	title := offer.Title
+	if offer.OfferNumber != "" {
+		title = offer.OfferNumber + " " + title
+	}
+	description := ""
+	if offer.CustomerName != "" {
+		description = "Kunde: " + offer.CustomerName
+	}
+	inWindow := func(t *time.Time) bool {
+		return t != nil && !t.Before(from) && t.Before(to)
+	}
+
+	var events []ical.Event
+	if inWindow(offer.DueDate) {
+		events = append(events, ical.Event{
+			UID:          fmt.Sprintf("offer-%s-due@%s", offer.ID, calendarUIDDomain),
+			Summary:      "Tilbudsfrist: " + title,
+			Description:  description,
+			Categories:   []string{"offer"},
+			Status:       ical.StatusConfirmed,
+			Start:        offer.DueDate.In(osloLocation),
+			AllDay:       true,
+			Created:      offer.CreatedAt,
+			LastModified: offer.UpdatedAt,
+		})
+	}
+	// Only sent offers can expire
+	if offer.Phase == domain.OfferPhaseSent && inWindow(offer.ExpirationDate) {
+		events = append(events, ical.Event{
+			UID:          fmt.Sprintf("offer-%s-expiration@%s", offer.ID, calendarUIDDomain),
+			Summary:      "Tilbud utløper: " + title,
+			Description:  description,
+			Categories:   []string{"offer"},
+			Status:       ical.StatusConfirmed,
+			Start:        offer.ExpirationDate.In(osloLocation),
+			AllDay:       true,
+			Created:      offer.CreatedAt,
+			LastModified: offer.UpdatedAt,
+		})
+	}
+	return events
+}
@@ -4,10 +4,12 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/straye-as/relation-api/internal/domain"
 	"github.com/straye-as/relation-api/internal/mapper"
 	"github.com/straye-as/relation-api/internal/repository"
+	"github.com/straye-as/relation-api/internal/workcalendar"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
@@ -16,6 +18,15 @@ import (
 var (
 	// ErrInvalidResponsibleUser is returned when a responsible user ID is invalid
 	ErrInvalidResponsibleUser = errors.New("invalid responsible user ID")
+
+	// ErrInvalidCreditLimitEnforcement is returned when the credit limit enforcement is not warn or block
+	ErrInvalidCreditLimitEnforcement = errors.New("invalid credit limit enforcement: must be warn or block")
+
+	// ErrInvalidInquirySLA is returned when the inquiry SLA warning is not shorter than the SLA itself
+	ErrInvalidInquirySLA = errors.New("invalid inquiry SLA: warning hours must be less than SLA hours")
+
+	// ErrInvalidBusinessHours is returned when business hours are not HH:MM or end before they start
+	ErrInvalidBusinessHours = errors.New("invalid business hours: must be HH:MM and end after they start")
 )
 
 // CompanyService handles business logic for companies
@@ -186,6 +197,33 @@ func (s *CompanyService) Update(ctx context.Context, id domain.CompanyID, req *d
 		}
 	}
 
+	if req.CreditLimitEnforcement != nil {
+		if !req.CreditLimitEnforcement.IsValid() {
+			return nil, ErrInvalidCreditLimitEnforcement
+		}
+		company.CreditLimitEnforcement = *req.CreditLimitEnforcement
+	}
+
+	if req.InquirySLAHours != nil {
+		company.InquirySLAHours = *req.InquirySLAHours
+	}
+	if req.InquirySLAWarningHours != nil {
+		company.InquirySLAWarningHours = *req.InquirySLAWarningHours
+	}
+	if company.InquirySLAHours <= 0 || company.InquirySLAWarningHours < 0 || company.InquirySLAWarningHours >= company.InquirySLAHours {
+		return nil, ErrInvalidInquirySLA
+	}
+
+	if req.BusinessDayStart != nil {
+		company.BusinessDayStart = *req.BusinessDayStart
+	}
+	if req.BusinessDayEnd != nil {
+		company.BusinessDayEnd = *req.BusinessDayEnd
+	}
+	if _, err := workcalendar.New(company.BusinessDayStart, company.BusinessDayEnd); err != nil {
+		return nil, ErrInvalidBusinessHours
+	}
+
 	if err := s.companyRepo.Update(ctx, company); err != nil {
 		return nil, fmt.Errorf("failed to update company: %w", err)
 	}
@@ -224,6 +262,34 @@ func (s *CompanyService) GetDefaultProjectResponsible(ctx context.Context, compa
 	return company.DefaultProjectResponsibleID
 }
 
+// GetCreditLimitEnforcement returns how a company handles orders over the customer credit limit.
+// Defaults to warn when the company cannot be loaded.
+func (s *CompanyService) GetCreditLimitEnforcement(ctx context.Context, companyID domain.CompanyID) domain.CreditLimitEnforcement {
+	company, err := s.GetByID(ctx, companyID)
+	if err != nil || !company.CreditLimitEnforcement.IsValid() {
+		return domain.CreditLimitEnforcementWarn
+	}
+	return company.CreditLimitEnforcement
+}
+
+// GetInquirySLAPolicy returns a company's inquiry response SLA and the business hours it is measured in.
+// Falls back to the default policy when the company cannot be loaded or its settings are invalid.
+func (s *CompanyService) GetInquirySLAPolicy(ctx context.Context, companyID domain.CompanyID) InquirySLAPolicy {
+	company, err := s.GetByID(ctx, companyID)
+	if err != nil || company.InquirySLAHours <= 0 {
+		return DefaultInquirySLAPolicy()
+	}
+	calendar, err := workcalendar.New(company.BusinessDayStart, company.BusinessDayEnd)
+	if err != nil {
+		return DefaultInquirySLAPolicy()
+	}
+	return InquirySLAPolicy{
+		Calendar: calendar,
+		Target:   time.Duration(company.InquirySLAHours) * time.Hour,
+		Warning:  time.Duration(company.InquirySLAWarningHours) * time.Hour,
+	}
+}
+
 // validateUserExists checks if a user ID exists in the system
 func (s *CompanyService) validateUserExists(ctx context.Context, userID string) error {
 	if s.userRepo == nil {
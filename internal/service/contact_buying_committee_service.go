@@ -0,0 +1,166 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/straye-as/relation-api/internal/auth"
+	"github.com/straye-as/relation-api/internal/domain"
+	"github.com/straye-as/relation-api/internal/mapper"
+	"gorm.io/gorm"
+)
+
+// ErrInvalidBuyingRole is returned for a buying role outside the controlled set
+var ErrInvalidBuyingRole = errors.New("invalid buying role: must be one of decision_maker, economic_buyer, technical_evaluator, influencer, blocker")
+
+// ErrInvalidContactSentiment is returned for a sentiment other than positive, neutral or negative
+var ErrInvalidContactSentiment = errors.New("invalid sentiment: must be one of positive, neutral, negative")
+
+// ErrBuyingRoleRequiresOffer is returned when a buying role or sentiment is set on a non-offer relationship
+var ErrBuyingRoleRequiresOffer = errors.New("buying role and sentiment can only be set on offer relationships")
+
+// ErrContactRelationshipNotFound is returned when a relationship does not exist or belongs to another contact
+var ErrContactRelationshipNotFound = errors.New("contact relationship not found")
+
+// validateBuyingCommitteeFields checks the buying role and sentiment of a relationship
+func validateBuyingCommitteeFields(entityType domain.ContactEntityType, role *domain.BuyingRole, sentiment *domain.ContactSentiment) error {
+	if role == nil && sentiment == nil {
+		return nil
+	}
+	if entityType != domain.ContactEntityOffer {
+		return ErrBuyingRoleRequiresOffer
+	}
+	if role != nil && !role.IsValid() {
+		return ErrInvalidBuyingRole
+	}
+	if sentiment != nil && !sentiment.IsValid() {
+		return ErrInvalidContactSentiment
+	}
+	return nil
+}
+
+// UpdateRelationship updates the role, buying role and sentiment of a contact's relationship
+func (s *ContactService) UpdateRelationship(ctx context.Context, contactID, relationshipID uuid.UUID, req *domain.UpdateContactRelationshipRequest) (*domain.ContactRelationshipDTO, error) {
+	rel, err := s.contactRepo.GetRelationshipByID(ctx, relationshipID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrContactRelationshipNotFound
+		}
+		return nil, fmt.Errorf("failed to get relationship: %w", err)
+	}
+	if rel.ContactID != contactID {
+		return nil, ErrContactRelationshipNotFound
+	}
+
+	if err := validateBuyingCommitteeFields(rel.EntityType, req.BuyingRole, req.Sentiment); err != nil {
+		return nil, err
+	}
+
+	rel.Role = req.Role
+	rel.BuyingRole = req.BuyingRole
+	rel.Sentiment = req.Sentiment
+	if err := s.contactRepo.UpdateRelationship(ctx, rel); err != nil {
+		return nil, fmt.Errorf("failed to update relationship: %w", err)
+	}
+
+	if userCtx, ok := auth.FromContext(ctx); ok && rel.Contact != nil {
+		activity := &domain.Activity{
+			TargetType:  domain.ActivityTargetContact,
+			TargetID:    contactID,
+			TargetName:  rel.Contact.FullName(),
+			Title:       "Relasjon oppdatert",
+			Body:        fmt.Sprintf("Rollen til kontakten '%s' på %s ble oppdatert", rel.Contact.FullName(), rel.EntityType),
+			CreatorName: userCtx.DisplayName,
+		}
+		_ = s.activityRepo.Create(ctx, activity)
+	}
+
+	dto := mapper.ToContactRelationshipDTO(rel)
+	return &dto, nil
+}
+
+// GetBuyingCommittee returns the active contacts linked to an offer grouped by buying role,
+// with the roles nobody covers and a summary of their sentiment
+func (s *ContactService) GetBuyingCommittee(ctx context.Context, offerID uuid.UUID) (*domain.BuyingCommitteeDTO, error) {
+	contacts, err := s.contactRepo.ListByEntity(ctx, domain.ContactEntityOffer, offerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list offer contacts: %w", err)
+	}
+
+	committee := &domain.BuyingCommitteeDTO{
+		OfferID: offerID,
+		Members: []domain.BuyingCommitteeMemberDTO{},
+	}
+	covered := map[domain.BuyingRole]bool{}
+	for i := range contacts {
+		contact := &contacts[i]
+		if !contact.IsActive {
+			continue
+		}
+		for _, rel := range contact.Relationships {
+			if rel.EntityType != domain.ContactEntityOffer || rel.EntityID != offerID {
+				continue
+			}
+			committee.Members = append(committee.Members, domain.BuyingCommitteeMemberDTO{
+				RelationshipID: rel.ID,
+				ContactID:      contact.ID,
+				FullName:       contact.FullName(),
+				Title:          contact.Title,
+				Email:          contact.Email,
+				Phone:          contact.Phone,
+				Mobile:         contact.Mobile,
+				Role:           rel.Role,
+				BuyingRole:     rel.BuyingRole,
+				Sentiment:      rel.Sentiment,
+				IsPrimary:      rel.IsPrimary,
+			})
+			if rel.BuyingRole != nil {
+				covered[*rel.BuyingRole] = true
+			}
+			if rel.Sentiment != nil {
+				switch *rel.Sentiment {
+				case domain.ContactSentimentPositive:
+					committee.PositiveCount++
+				case domain.ContactSentimentNeutral:
+					committee.NeutralCount++
+				case domain.ContactSentimentNegative:
+					committee.NegativeCount++
+				}
+			}
+		}
+	}
+
+	// Members in buying role order, contacts without a buying role last
+	sort.SliceStable(committee.Members, func(a, b int) bool {
+		return buyingRoleRank(committee.Members[a].BuyingRole) < buyingRoleRank(committee.Members[b].BuyingRole)
+	})
+
+	committee.MissingRoles = []domain.BuyingRole{}
+	for _, role := range domain.BuyingRoles {
+		// Nobody needs to block the deal
+		if !covered[role] && role != domain.BuyingRoleBlocker {
+			committee.MissingRoles = append(committee.MissingRoles, role)
+		}
+	}
+	committee.HasDecisionMaker = covered[domain.BuyingRoleDecisionMaker]
+	if !committee.HasDecisionMaker {
+		committee.Warnings = append(committee.Warnings, domain.OfferWarningMissingDecisionMaker)
+	}
+
+	return committee, nil
+}
+
+// buyingRoleRank returns the position of a buying role in domain.BuyingRoles
+func buyingRoleRank(role *domain.BuyingRole) int {
+	if role != nil {
+		for i, r := range domain.BuyingRoles {
+			if r == *role {
+				return i
+			}
+		}
+	}
+	return len(domain.BuyingRoles)
+}
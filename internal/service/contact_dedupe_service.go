@@ -0,0 +1,348 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/google/uuid"
+	"github.com/straye-as/relation-api/internal/auth"
+	"github.com/straye-as/relation-api/internal/domain"
+	"github.com/straye-as/relation-api/internal/mapper"
+	"gorm.io/gorm"
+)
+
+// ErrMergeIntoSelf is returned when a contact is listed as a duplicate of itself
+var ErrMergeIntoSelf = errors.New("a contact cannot be merged into itself")
+
+// ErrDuplicateContactNotFound is returned when a duplicate to merge does not exist or is inactive
+var ErrDuplicateContactNotFound = errors.New("duplicate contact not found")
+
+// Reasons contacts are reported as duplicates
+const (
+	duplicateMatchEmail    = "email"
+	duplicateMatchPhone    = "phone"
+	duplicateMatchName     = "name"
+	duplicateMatchCustomer = "customer"
+)
+
+// minDuplicatePhoneDigits is the shortest phone number compared, to skip extensions and placeholders
+const minDuplicatePhoneDigits = 6
+
+// FindDuplicates returns groups of active contacts that are probably the same person, optionally
+// limited to the contacts of a customer. Contacts match on email (ignoring case), on phone or mobile
+// number (ignoring formatting and the +47 prefix), or on full name when they share a customer or
+// at least one of them has no customer.
+func (s *ContactService) FindDuplicates(ctx context.Context, customerID *uuid.UUID) ([]domain.ContactDuplicateGroupDTO, error) {
+	contacts, err := s.contactRepo.ListDuplicateCandidates(ctx, customerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list contacts: %w", err)
+	}
+
+	groups := newDuplicateGroups(len(contacts))
+	customers := make([]map[uuid.UUID]bool, len(contacts))
+	byEmail := map[string][]int{}
+	byPhone := map[string][]int{}
+	byName := map[string][]int{}
+	for i := range contacts {
+		customers[i] = contactCustomerIDs(&contacts[i])
+		if email := strings.ToLower(strings.TrimSpace(contacts[i].Email)); email != "" {
+			byEmail[email] = append(byEmail[email], i)
+		}
+		phones := map[string]bool{}
+		for _, phone := range []string{contacts[i].Phone, contacts[i].Mobile} {
+			if phone = normalizePhone(phone); phone != "" && !phones[phone] {
+				phones[phone] = true
+				byPhone[phone] = append(byPhone[phone], i)
+			}
+		}
+		if name := normalizeName(contacts[i].FullName()); name != "" {
+			byName[name] = append(byName[name], i)
+		}
+	}
+
+	for _, indexes := range byEmail {
+		for _, j := range indexes[1:] {
+			groups.union(indexes[0], j, duplicateMatchEmail)
+		}
+	}
+	for _, indexes := range byPhone {
+		for _, j := range indexes[1:] {
+			groups.union(indexes[0], j, duplicateMatchPhone)
+		}
+	}
+	for _, indexes := range byName {
+		for a := 0; a < len(indexes); a++ {
+			for b := a + 1; b < len(indexes); b++ {
+				i, j := indexes[a], indexes[b]
+				switch {
+				case sharesCustomer(customers[i], customers[j]):
+					groups.union(i, j, duplicateMatchName, duplicateMatchCustomer)
+				case len(customers[i]) == 0 || len(customers[j]) == 0:
+					groups.union(i, j, duplicateMatchName)
+				}
+			}
+		}
+	}
+
+	members := map[int][]int{}
+	for i := range contacts {
+		root := groups.find(i)
+		members[root] = append(members[root], i)
+	}
+
+	result := []domain.ContactDuplicateGroupDTO{}
+	for root, indexes := range members {
+		if len(indexes) < 2 {
+			continue
+		}
+
+		// The contact with the most relationships survives, the oldest one on ties
+		sort.SliceStable(indexes, func(a, b int) bool {
+			ca, cb := &contacts[indexes[a]], &contacts[indexes[b]]
+			if len(ca.Relationships) != len(cb.Relationships) {
+				return len(ca.Relationships) > len(cb.Relationships)
+			}
+			return ca.CreatedAt.Before(cb.CreatedAt)
+		})
+
+		group := domain.ContactDuplicateGroupDTO{
+			MatchedOn:           groups.reasonsOf(root),
+			SuggestedSurvivorID: contacts[indexes[0]].ID,
+			Contacts:            make([]domain.ContactDTO, len(indexes)),
+		}
+		for k, i := range indexes {
+			group.Contacts[k] = mapper.ToContactDTO(&contacts[i])
+			if contacts[i].PrimaryCustomer != nil {
+				group.Contacts[k].PrimaryCustomerName = contacts[i].PrimaryCustomer.Name
+			}
+		}
+		result = append(result, group)
+	}
+
+	sort.Slice(result, func(a, b int) bool {
+		return result[a].Contacts[0].FullName < result[b].Contacts[0].FullName
+	})
+	return result, nil
+}
+
+// Merge merges duplicates into the surviving contact. Relationships and activities are moved to the
+// survivor, empty fields on the survivor are filled in from the duplicates, and the duplicates are
+// deactivated with a reference to the survivor.
+func (s *ContactService) Merge(ctx context.Context, survivorID uuid.UUID, req *domain.MergeContactsRequest) (*domain.ContactMergeResultDTO, error) {
+	survivor, err := s.contactRepo.GetByID(ctx, survivorID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrContactNotFound
+		}
+		return nil, fmt.Errorf("failed to get contact: %w", err)
+	}
+
+	ids := make([]uuid.UUID, 0, len(req.DuplicateIDs))
+	seen := map[uuid.UUID]bool{}
+	for _, id := range req.DuplicateIDs {
+		if id == survivorID {
+			return nil, ErrMergeIntoSelf
+		}
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	duplicates, err := s.contactRepo.ListActiveByIDs(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get duplicate contacts: %w", err)
+	}
+	if len(duplicates) != len(ids) {
+		return nil, ErrDuplicateContactNotFound
+	}
+
+	// Keep the order of the request, so earlier duplicates win when filling in fields
+	sort.SliceStable(duplicates, func(a, b int) bool {
+		return indexOf(ids, duplicates[a].ID) < indexOf(ids, duplicates[b].ID)
+	})
+
+	names := make([]string, len(duplicates))
+	mergedIDs := make([]uuid.UUID, len(duplicates))
+	for i := range duplicates {
+		fillContactFromDuplicate(survivor, &duplicates[i])
+		names[i] = duplicates[i].FullName()
+		mergedIDs[i] = duplicates[i].ID
+	}
+	if userCtx, ok := auth.FromContext(ctx); ok {
+		survivor.UpdatedByID = userCtx.UserID.String()
+		survivor.UpdatedByName = userCtx.DisplayName
+	}
+
+	moved, err := s.contactRepo.Merge(ctx, survivor, duplicates)
+	if err != nil {
+		return nil, fmt.Errorf("failed to merge contacts: %w", err)
+	}
+
+	if userCtx, ok := auth.FromContext(ctx); ok {
+		activity := &domain.Activity{
+			TargetType:  domain.ActivityTargetContact,
+			TargetID:    survivor.ID,
+			TargetName:  survivor.FullName(),
+			Title:       "Kontakter slått sammen",
+			Body:        fmt.Sprintf("Duplikatene %s ble slått sammen med kontakten '%s'", strings.Join(names, ", "), survivor.FullName()),
+			CreatorName: userCtx.DisplayName,
+		}
+		_ = s.activityRepo.Create(ctx, activity)
+	}
+
+	merged, err := s.contactRepo.GetByID(ctx, survivor.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get merged contact: %w", err)
+	}
+	return &domain.ContactMergeResultDTO{
+		Contact:            mapper.ToContactDTO(merged),
+		MergedContactIDs:   mergedIDs,
+		RelationshipsMoved: moved.Relationships,
+		ActivitiesMoved:    moved.Activities,
+	}, nil
+}
+
+// fillContactFromDuplicate fills empty fields of the survivor from a duplicate. The email is moved,
+// since contact emails are unique, and notes are appended.
+func fillContactFromDuplicate(survivor, duplicate *domain.Contact) {
+	if survivor.Email == "" && duplicate.Email != "" {
+		survivor.Email = duplicate.Email
+		duplicate.Email = ""
+	}
+	fill := func(target *string, value string) {
+		if strings.TrimSpace(*target) == "" {
+			*target = value
+		}
+	}
+	fill(&survivor.Phone, duplicate.Phone)
+	fill(&survivor.Mobile, duplicate.Mobile)
+	fill(&survivor.Title, duplicate.Title)
+	fill(&survivor.Department, duplicate.Department)
+	fill(&survivor.Address, duplicate.Address)
+	fill(&survivor.City, duplicate.City)
+	fill(&survivor.PostalCode, duplicate.PostalCode)
+	fill(&survivor.LinkedInURL, duplicate.LinkedInURL)
+	if survivor.PrimaryCustomerID == nil {
+		survivor.PrimaryCustomerID = duplicate.PrimaryCustomerID
+	}
+	if notes := strings.TrimSpace(duplicate.Notes); notes != "" && !strings.Contains(survivor.Notes, notes) {
+		if survivor.Notes != "" {
+			survivor.Notes += "\n\n"
+		}
+		survivor.Notes += notes
+	}
+}
+
+// duplicateGroups is a union-find over contact indexes that records why contacts were joined
+type duplicateGroups struct {
+	parent  []int
+	reasons map[int]map[string]bool
+}
+
+func newDuplicateGroups(n int) *duplicateGroups {
+	parent := make([]int, n)
+	for i := range parent {
+		parent[i] = i
+	}
+	return &duplicateGroups{parent: parent, reasons: map[int]map[string]bool{}}
+}
+
+func (g *duplicateGroups) find(i int) int {
+	for g.parent[i] != i {
+		g.parent[i] = g.parent[g.parent[i]]
+		i = g.parent[i]
+	}
+	return i
+}
+
+func (g *duplicateGroups) union(i, j int, reasons ...string) {
+	ri, rj := g.find(i), g.find(j)
+	if ri != rj {
+		g.parent[rj] = ri
+		for reason := range g.reasons[rj] {
+			g.addReason(ri, reason)
+		}
+		delete(g.reasons, rj)
+	}
+	for _, reason := range reasons {
+		g.addReason(ri, reason)
+	}
+}
+
+func (g *duplicateGroups) addReason(root int, reason string) {
+	if g.reasons[root] == nil {
+		g.reasons[root] = map[string]bool{}
+	}
+	g.reasons[root][reason] = true
+}
+
+// reasonsOf returns the match reasons of a group in a stable order
+func (g *duplicateGroups) reasonsOf(root int) []string {
+	var reasons []string
+	for _, reason := range []string{duplicateMatchEmail, duplicateMatchPhone, duplicateMatchName, duplicateMatchCustomer} {
+		if g.reasons[root][reason] {
+			reasons = append(reasons, reason)
+		}
+	}
+	return reasons
+}
+
+// contactCustomerIDs returns the primary customer and the customers the contact is related to
+func contactCustomerIDs(contact *domain.Contact) map[uuid.UUID]bool {
+	ids := map[uuid.UUID]bool{}
+	if contact.PrimaryCustomerID != nil {
+		ids[*contact.PrimaryCustomerID] = true
+	}
+	for _, rel := range contact.Relationships {
+		if rel.EntityType == domain.ContactEntityCustomer {
+			ids[rel.EntityID] = true
+		}
+	}
+	return ids
+}
+
+func sharesCustomer(a, b map[uuid.UUID]bool) bool {
+	for id := range a {
+		if b[id] {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeName lowercases a name and collapses whitespace
+func normalizeName(name string) string {
+	return strings.Join(strings.Fields(strings.ToLower(name)), " ")
+}
+
+// normalizePhone keeps the digits of a phone number and strips the Norwegian country code
+func normalizePhone(phone string) string {
+	digits := strings.Map(func(r rune) rune {
+		if unicode.IsDigit(r) {
+			return r
+		}
+		return -1
+	}, phone)
+	digits = strings.TrimPrefix(digits, "00")
+	if len(digits) == 10 && strings.HasPrefix(digits, "47") {
+		digits = digits[2:]
+	}
+	if len(digits) < minDuplicatePhoneDigits {
+		return ""
+	}
+	return digits
+}
+
+func indexOf(ids []uuid.UUID, id uuid.UUID) int {
+	for i := range ids {
+		if ids[i] == id {
+			return i
+		}
+	}
+	return len(ids)
+}
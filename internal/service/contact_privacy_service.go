@@ -0,0 +1,331 @@
+package service
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/straye-as/relation-api/internal/auth"
+	"github.com/straye-as/relation-api/internal/domain"
+	"github.com/straye-as/relation-api/internal/mapper"
+	"github.com/straye-as/relation-api/internal/repository"
+	"gorm.io/gorm"
+)
+
+// ErrContactNotFound is returned when a contact does not exist
+var ErrContactNotFound = errors.New("contact not found")
+
+// ErrContactAlreadyAnonymized is returned when anonymizing a contact that has already been anonymized
+var ErrContactAlreadyAnonymized = errors.New("contact has already been anonymized")
+
+// ErrDataSubjectRequired is returned when a data subject export has neither a contact nor an email address
+var ErrDataSubjectRequired = errors.New("a contact ID or email address is required")
+
+// Anonymized contacts are renamed to "Anonymisert kontakt"
+const (
+	anonymizedFirstName = "Anonymisert"
+	anonymizedLastName  = "kontakt"
+)
+
+// anonymizedPlaceholder replaces the personal data of an anonymized contact in free text and denormalized copies
+const anonymizedPlaceholder = "[anonymisert]"
+
+// ExportDataSubject gathers everything stored about a contact: the contact itself, activities on or mentioning it,
+// denormalized copies on customers, suppliers and offer suppliers, and audit log entries.
+// Deleted (inactive) contacts are included.
+func (s *ContactService) ExportDataSubject(ctx context.Context, contactID uuid.UUID) (*domain.DataSubjectExportDTO, error) {
+	contact, err := s.getContactIncludingInactive(ctx, contactID)
+	if err != nil {
+		return nil, err
+	}
+
+	export, err := s.exportDataSubject(ctx, []domain.Contact{*contact}, contact.Email)
+	if err != nil {
+		return nil, err
+	}
+	export.ContactID = &contact.ID
+
+	s.logPrivacyActivity(ctx, contact, "Personopplysninger eksportert", "Personopplysninger om kontakten ble eksportert (innsynsforespørsel)")
+	return export, nil
+}
+
+// ExportDataSubjectByEmail gathers everything stored about an email address, including all contacts with the address.
+// Returns an empty export when nothing is stored about the address.
+func (s *ContactService) ExportDataSubjectByEmail(ctx context.Context, email string) (*domain.DataSubjectExportDTO, error) {
+	email = strings.TrimSpace(email)
+	if email == "" {
+		return nil, ErrDataSubjectRequired
+	}
+	if !emailRegex.MatchString(email) {
+		return nil, ErrInvalidEmailFormat
+	}
+
+	contacts, err := s.contactRepo.ListByEmailIncludingInactive(ctx, email)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find contacts: %w", err)
+	}
+
+	export, err := s.exportDataSubject(ctx, contacts, email)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range contacts {
+		s.logPrivacyActivity(ctx, &contacts[i], "Personopplysninger eksportert", "Personopplysninger om kontakten ble eksportert (innsynsforespørsel)")
+	}
+	return export, nil
+}
+
+func (s *ContactService) exportDataSubject(ctx context.Context, contacts []domain.Contact, email string) (*domain.DataSubjectExportDTO, error) {
+	subject := dataSubjectFor(contacts, email)
+
+	activities, err := s.contactRepo.ListDataSubjectActivities(ctx, subject)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list activities: %w", err)
+	}
+	references, err := s.contactRepo.ListDataSubjectReferences(ctx, subject)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list references: %w", err)
+	}
+	auditLogs, err := s.contactRepo.ListDataSubjectAuditLogs(ctx, subject)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit logs: %w", err)
+	}
+
+	export := &domain.DataSubjectExportDTO{
+		GeneratedAt: time.Now().UTC().Format(time.RFC3339),
+		Email:       email,
+		Contacts:    make([]domain.ContactDTO, len(contacts)),
+		Activities:  make([]domain.ActivityDTO, len(activities)),
+		References:  make([]domain.DataSubjectReferenceDTO, len(references)),
+		AuditLogs:   make([]domain.AuditLogDTO, len(auditLogs)),
+	}
+	for i := range contacts {
+		export.Contacts[i] = mapper.ToContactDTO(&contacts[i])
+	}
+	for i := range activities {
+		export.Activities[i] = mapper.ToActivityDTO(&activities[i])
+	}
+	for i, reference := range references {
+		export.References[i] = domain.DataSubjectReferenceDTO{
+			EntityType: reference.EntityType,
+			EntityID:   reference.EntityID,
+			EntityName: reference.EntityName,
+			Fields:     reference.Fields,
+		}
+	}
+	for i := range auditLogs {
+		export.AuditLogs[i] = mapper.ToAuditLogDTO(&auditLogs[i])
+	}
+
+	return export, nil
+}
+
+// WriteDataSubjectArchive writes a data subject export as a ZIP bundle with one JSON file per section
+func WriteDataSubjectArchive(w io.Writer, export *domain.DataSubjectExportDTO) error {
+	archive := zip.NewWriter(w)
+
+	files := []struct {
+		name string
+		data interface{}
+	}{
+		{"export.json", export},
+		{"contacts.json", export.Contacts},
+		{"activities.json", export.Activities},
+		{"references.json", export.References},
+		{"audit-logs.json", export.AuditLogs},
+	}
+	for _, file := range files {
+		entry, err := archive.Create(file.name)
+		if err != nil {
+			return fmt.Errorf("failed to add %s: %w", file.name, err)
+		}
+		encoder := json.NewEncoder(entry)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(file.data); err != nil {
+			return fmt.Errorf("failed to write %s: %w", file.name, err)
+		}
+	}
+
+	return archive.Close()
+}
+
+// Anonymize scrubs the personal data of a contact (GDPR right to erasure) while keeping its relationships.
+// The contact's name, email and phone numbers are also replaced in activities, customer and supplier
+// contact fields, offer suppliers and audit logs. The contact is deactivated and cannot be anonymized again.
+func (s *ContactService) Anonymize(ctx context.Context, id uuid.UUID) (*domain.ContactAnonymizationDTO, error) {
+	contact, err := s.getContactIncludingInactive(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if contact.AnonymizedAt != nil {
+		return nil, ErrContactAlreadyAnonymized
+	}
+
+	// Collect the personal data before scrubbing the contact
+	subject := dataSubjectFor([]domain.Contact{*contact}, contact.Email)
+
+	now := time.Now()
+	contact.FirstName = anonymizedFirstName
+	contact.LastName = anonymizedLastName
+	contact.Email = ""
+	contact.Phone = ""
+	contact.Mobile = ""
+	contact.Title = ""
+	contact.Department = ""
+	contact.Address = ""
+	contact.City = ""
+	contact.PostalCode = ""
+	contact.LinkedInURL = ""
+	contact.Notes = ""
+	contact.IsActive = false
+	contact.AnonymizedAt = &now
+	contact.RetentionFlaggedAt = nil
+	if userCtx, ok := auth.FromContext(ctx); ok {
+		contact.UpdatedByID = userCtx.UserID.String()
+		contact.UpdatedByName = userCtx.DisplayName
+	}
+
+	result, err := s.contactRepo.Anonymize(ctx, contact, subject, anonymizedPlaceholder)
+	if err != nil {
+		return nil, fmt.Errorf("failed to anonymize contact: %w", err)
+	}
+
+	s.logPrivacyActivity(ctx, contact, "Kontakt anonymisert",
+		fmt.Sprintf("Personopplysninger ble anonymisert i kontakten, %d aktiviteter, %d kunde- og leverandørfelt og %d revisjonslogger",
+			result.Activities, result.References, result.AuditLogs))
+
+	return &domain.ContactAnonymizationDTO{
+		Contact:           mapper.ToContactDTO(contact),
+		ActivitiesUpdated: result.Activities,
+		ReferencesUpdated: result.References,
+		AuditLogsUpdated:  result.AuditLogs,
+	}, nil
+}
+
+// FlagInactiveContacts flags contacts for retention review when neither the contact nor its activities
+// have changed for the given number of years. Returns the number of contacts flagged in this run
+// and the number of contacts awaiting review.
+func (s *ContactService) FlagInactiveContacts(ctx context.Context, inactiveYears int) (flagged int, pending int, err error) {
+	if inactiveYears < 1 {
+		return 0, 0, fmt.Errorf("%w: retention period must be at least one year", ErrInvalidInput)
+	}
+
+	count, err := s.contactRepo.FlagInactiveForRetention(ctx, time.Now().AddDate(-inactiveYears, 0, 0))
+	if err != nil {
+		return 0, 0, err
+	}
+
+	_, total, err := s.contactRepo.ListFlaggedForRetention(ctx, 1, 1)
+	if err != nil {
+		return int(count), 0, fmt.Errorf("failed to count contacts awaiting retention review: %w", err)
+	}
+	return int(count), int(total), nil
+}
+
+// ListRetentionReview returns contacts flagged for retention review, oldest flag first
+func (s *ContactService) ListRetentionReview(ctx context.Context, page, pageSize int) (*domain.PaginatedResponse, error) {
+	contacts, total, err := s.contactRepo.ListFlaggedForRetention(ctx, page, pageSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list contacts for retention review: %w", err)
+	}
+
+	dtos := make([]domain.ContactDTO, len(contacts))
+	for i := range contacts {
+		dtos[i] = mapper.ToContactDTO(&contacts[i])
+		if contacts[i].PrimaryCustomer != nil {
+			dtos[i].PrimaryCustomerName = contacts[i].PrimaryCustomer.Name
+		}
+	}
+
+	totalPages := int(total) / pageSize
+	if int(total)%pageSize > 0 {
+		totalPages++
+	}
+
+	return &domain.PaginatedResponse{
+		Data:       dtos,
+		Total:      total,
+		Page:       page,
+		PageSize:   pageSize,
+		TotalPages: totalPages,
+	}, nil
+}
+
+// KeepAfterRetentionReview clears the retention flag of a reviewed contact that should be kept.
+// The retention period restarts from the review.
+func (s *ContactService) KeepAfterRetentionReview(ctx context.Context, id uuid.UUID) (*domain.ContactDTO, error) {
+	contact, err := s.getContactIncludingInactive(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.contactRepo.MarkRetentionReviewed(ctx, id); err != nil {
+		return nil, fmt.Errorf("failed to mark contact as reviewed: %w", err)
+	}
+
+	s.logPrivacyActivity(ctx, contact, "Oppbevaring vurdert", "Kontakten ble vurdert og beholdes")
+
+	contact, err = s.getContactIncludingInactive(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	dto := mapper.ToContactDTO(contact)
+	return &dto, nil
+}
+
+func (s *ContactService) getContactIncludingInactive(ctx context.Context, id uuid.UUID) (*domain.Contact, error) {
+	contact, err := s.contactRepo.GetByIDIncludingInactive(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrContactNotFound
+		}
+		return nil, fmt.Errorf("failed to get contact: %w", err)
+	}
+	return contact, nil
+}
+
+// dataSubjectFor collects the names, emails and phone numbers of the contacts and the email address
+func dataSubjectFor(contacts []domain.Contact, email string) *repository.DataSubject {
+	subject := &repository.DataSubject{}
+	if email != "" {
+		subject.Emails = append(subject.Emails, email)
+	}
+	for _, contact := range contacts {
+		subject.ContactIDs = append(subject.ContactIDs, contact.ID)
+		if name := strings.TrimSpace(contact.FullName()); name != "" {
+			subject.Names = append(subject.Names, name)
+		}
+		if contact.Email != "" && !strings.EqualFold(contact.Email, email) {
+			subject.Emails = append(subject.Emails, contact.Email)
+		}
+		for _, phone := range []string{contact.Phone, contact.Mobile} {
+			if phone != "" {
+				subject.Phones = append(subject.Phones, phone)
+			}
+		}
+	}
+	return subject
+}
+
+// logPrivacyActivity records a GDPR operation on the contact. Texts must not contain personal data.
+func (s *ContactService) logPrivacyActivity(ctx context.Context, contact *domain.Contact, title, body string) {
+	activity := &domain.Activity{
+		TargetType: domain.ActivityTargetContact,
+		TargetID:   contact.ID,
+		TargetName: contact.FullName(),
+		Title:      title,
+		Body:       body,
+	}
+	if userCtx, ok := auth.FromContext(ctx); ok {
+		activity.CreatorName = userCtx.DisplayName
+		activity.CreatorID = userCtx.UserID.String()
+	}
+	_ = s.activityRepo.Create(ctx, activity)
+}
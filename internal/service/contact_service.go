@@ -316,6 +316,10 @@ func (s *ContactService) AddRelationship(ctx context.Context, contactID uuid.UUI
 		return nil, fmt.Errorf("contact not found: %w", err)
 	}
 
+	if err := validateBuyingCommitteeFields(req.EntityType, req.BuyingRole, req.Sentiment); err != nil {
+		return nil, err
+	}
+
 	// Check if relationship already exists
 	exists, err := s.contactRepo.CheckRelationshipExists(ctx, contactID, req.EntityType, req.EntityID)
 	if err != nil {
@@ -332,6 +336,8 @@ func (s *ContactService) AddRelationship(ctx context.Context, contactID uuid.UUI
 		EntityID:   req.EntityID,
 		Role:       req.Role,
 		IsPrimary:  req.IsPrimary,
+		BuyingRole: req.BuyingRole,
+		Sentiment:  req.Sentiment,
 	}
 
 	if err := s.contactRepo.AddRelationship(ctx, rel); err != nil {
@@ -0,0 +1,273 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/straye-as/relation-api/internal/auth"
+	"github.com/straye-as/relation-api/internal/domain"
+	"github.com/straye-as/relation-api/internal/vcard"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// ErrInvalidVCard is returned when an uploaded vCard file cannot be read
+var ErrInvalidVCard = errors.New("invalid vCard file")
+
+// ExportVCard returns a contact as a vCard
+func (s *ContactService) ExportVCard(ctx context.Context, id uuid.UUID) (*vcard.Card, error) {
+	contact, err := s.contactRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrContactNotFound
+		}
+		return nil, fmt.Errorf("failed to get contact: %w", err)
+	}
+
+	card := contactToVCard(contact)
+	return &card, nil
+}
+
+// ExportCustomerVCards returns the active contacts of a customer as vCards, including contacts
+// with the customer as primary customer and contacts related to it
+func (s *ContactService) ExportCustomerVCards(ctx context.Context, customerID uuid.UUID) (*domain.Customer, []vcard.Card, error) {
+	customer, err := s.customerRepo.GetByID(ctx, customerID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, ErrCustomerNotFound
+		}
+		return nil, nil, fmt.Errorf("failed to get customer: %w", err)
+	}
+
+	contacts, err := s.contactRepo.ListForCustomer(ctx, customerID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list contacts: %w", err)
+	}
+
+	cards := make([]vcard.Card, len(contacts))
+	for i := range contacts {
+		cards[i] = contactToVCard(&contacts[i])
+		// Related contacts with another primary customer are exported under this customer
+		cards[i].Organization = customer.Name
+	}
+	return customer, cards, nil
+}
+
+// ImportVCards creates or updates contacts from a vCard file. Cards are matched to existing contacts
+// by email (ignoring case), and a matched contact only gets the fields present on the card. Cards
+// without email always create a new contact. When a customer is given, new contacts get it as primary
+// customer and all imported contacts are related to it.
+func (s *ContactService) ImportVCards(ctx context.Context, r io.Reader, customerID *uuid.UUID) (*domain.VCardImportResultDTO, error) {
+	if customerID != nil {
+		if _, err := s.customerRepo.GetByID(ctx, *customerID); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, ErrCustomerNotFound
+			}
+			return nil, fmt.Errorf("failed to get customer: %w", err)
+		}
+	}
+
+	cards, err := vcard.Parse(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidVCard, err)
+	}
+
+	result := &domain.VCardImportResultDTO{Cards: make([]domain.VCardImportCardDTO, 0, len(cards))}
+	for i := range cards {
+		outcome := s.importVCard(ctx, &cards[i], customerID)
+		outcome.Card = i + 1
+		switch outcome.Action {
+		case domain.ImportRowActionCreate:
+			result.Summary.Create++
+		case domain.ImportRowActionUpdate:
+			result.Summary.Update++
+		case domain.ImportRowActionSkip:
+			result.Summary.Skip++
+		default:
+			result.Summary.Error++
+		}
+		result.Cards = append(result.Cards, outcome)
+	}
+	result.Summary.Total = len(cards)
+
+	s.logger.Info("imported vCards",
+		zap.Int("created", result.Summary.Create),
+		zap.Int("updated", result.Summary.Update),
+		zap.Int("errors", result.Summary.Error))
+	return result, nil
+}
+
+// importVCard creates or updates the contact of a single card
+func (s *ContactService) importVCard(ctx context.Context, card *vcard.Card, customerID *uuid.UUID) domain.VCardImportCardDTO {
+	given, family := card.Name()
+	outcome := domain.VCardImportCardDTO{Name: strings.TrimSpace(given + " " + family)}
+	email := strings.TrimSpace(card.Email)
+	if outcome.Name == "" {
+		outcome.Name = email
+	}
+
+	if email != "" && !emailRegex.MatchString(email) {
+		outcome.Action = domain.ImportRowActionError
+		outcome.Message = ErrInvalidEmailFormat.Error()
+		return outcome
+	}
+
+	var existing *domain.Contact
+	if email != "" {
+		found, err := s.contactRepo.GetByEmail(ctx, email)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			outcome.Action = domain.ImportRowActionError
+			outcome.Message = "failed to look up contact by email"
+			s.logger.Error("failed to look up contact by email", zap.Error(err))
+			return outcome
+		}
+		existing = found
+	}
+	// Merged duplicates keep their email when the survivor has one; update the survivor instead
+	if existing != nil && existing.MergedIntoID != nil {
+		if survivor, err := s.contactRepo.GetByIDIncludingInactive(ctx, *existing.MergedIntoID); err == nil {
+			existing = survivor
+		}
+	}
+
+	if existing == nil {
+		if given == "" && family == "" {
+			outcome.Action = domain.ImportRowActionSkip
+			outcome.Message = "card has no name"
+			return outcome
+		}
+		req := &domain.CreateContactRequest{
+			FirstName:         given,
+			LastName:          family,
+			Email:             email,
+			Phone:             card.Phone,
+			Mobile:            card.Mobile,
+			Title:             card.Title,
+			Department:        card.Department,
+			PrimaryCustomerID: customerID,
+			Address:           card.Address.Street,
+			City:              card.Address.City,
+			PostalCode:        card.Address.PostalCode,
+			Country:           card.Address.Country,
+			LinkedInURL:       card.URL,
+			Notes:             card.Note,
+		}
+		created, err := s.Create(ctx, req)
+		if err != nil {
+			outcome.Action = domain.ImportRowActionError
+			outcome.Message = err.Error()
+			return outcome
+		}
+		outcome.Action = domain.ImportRowActionCreate
+		outcome.ContactID = &created.ID
+		s.relateImportedContact(ctx, created.ID, customerID)
+		return outcome
+	}
+
+	applyVCard(existing, card)
+	if existing.PrimaryCustomerID == nil {
+		existing.PrimaryCustomerID = customerID
+	}
+	// Importing a deleted contact restores it
+	existing.IsActive = true
+	if userCtx, ok := auth.FromContext(ctx); ok {
+		existing.UpdatedByID = userCtx.UserID.String()
+		existing.UpdatedByName = userCtx.DisplayName
+	}
+	if err := s.contactRepo.Update(ctx, existing); err != nil {
+		outcome.Action = domain.ImportRowActionError
+		outcome.Message = "failed to update contact"
+		s.logger.Error("failed to update contact from vCard", zap.Error(err))
+		return outcome
+	}
+
+	if userCtx, ok := auth.FromContext(ctx); ok {
+		activity := &domain.Activity{
+			TargetType:  domain.ActivityTargetContact,
+			TargetID:    existing.ID,
+			TargetName:  existing.FullName(),
+			Title:       "Kontakt oppdatert",
+			Body:        fmt.Sprintf("Kontakten '%s' ble oppdatert fra vCard", existing.FullName()),
+			CreatorName: userCtx.DisplayName,
+		}
+		_ = s.activityRepo.Create(ctx, activity)
+	}
+
+	outcome.Action = domain.ImportRowActionUpdate
+	outcome.ContactID = &existing.ID
+	s.relateImportedContact(ctx, existing.ID, customerID)
+	return outcome
+}
+
+// relateImportedContact relates an imported contact to the customer it was imported for
+func (s *ContactService) relateImportedContact(ctx context.Context, contactID uuid.UUID, customerID *uuid.UUID) {
+	if customerID == nil {
+		return
+	}
+	exists, err := s.contactRepo.CheckRelationshipExists(ctx, contactID, domain.ContactEntityCustomer, *customerID)
+	if err != nil || exists {
+		return
+	}
+	rel := &domain.ContactRelationship{
+		ContactID:  contactID,
+		EntityType: domain.ContactEntityCustomer,
+		EntityID:   *customerID,
+	}
+	if err := s.contactRepo.AddRelationship(ctx, rel); err != nil {
+		s.logger.Warn("failed to relate imported contact to customer", zap.Error(err))
+	}
+}
+
+// contactToVCard converts a contact to a vCard
+func contactToVCard(contact *domain.Contact) vcard.Card {
+	card := vcard.Card{
+		UID:        "urn:uuid:" + contact.ID.String(),
+		FullName:   contact.FullName(),
+		GivenName:  contact.FirstName,
+		FamilyName: contact.LastName,
+		Department: contact.Department,
+		Title:      contact.Title,
+		Email:      contact.Email,
+		Phone:      contact.Phone,
+		Mobile:     contact.Mobile,
+		Address: vcard.Address{
+			Street:     contact.Address,
+			City:       contact.City,
+			PostalCode: contact.PostalCode,
+			Country:    contact.Country,
+		},
+		URL:      contact.LinkedInURL,
+		Note:     contact.Notes,
+		Revision: contact.UpdatedAt,
+	}
+	if contact.PrimaryCustomer != nil {
+		card.Organization = contact.PrimaryCustomer.Name
+	}
+	return card
+}
+
+// applyVCard overwrites the contact fields present on the card
+func applyVCard(contact *domain.Contact, card *vcard.Card) {
+	set := func(target *string, value string) {
+		if value = strings.TrimSpace(value); value != "" {
+			*target = value
+		}
+	}
+	given, family := card.Name()
+	set(&contact.FirstName, given)
+	set(&contact.LastName, family)
+	set(&contact.Phone, card.Phone)
+	set(&contact.Mobile, card.Mobile)
+	set(&contact.Title, card.Title)
+	set(&contact.Department, card.Department)
+	set(&contact.Address, card.Address.Street)
+	set(&contact.City, card.Address.City)
+	set(&contact.PostalCode, card.Address.PostalCode)
+	set(&contact.Country, card.Address.Country)
+	set(&contact.LinkedInURL, card.URL)
+	set(&contact.Notes, card.Note)
+}
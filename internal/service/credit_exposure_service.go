@@ -0,0 +1,253 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/straye-as/relation-api/internal/auth"
+	"github.com/straye-as/relation-api/internal/domain"
+	"github.com/straye-as/relation-api/internal/repository"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrCreditLimitExceeded is returned when accepting an order would push the customer over its credit limit
+	// and the company blocks such orders
+	ErrCreditLimitExceeded = errors.New("credit limit exceeded")
+)
+
+// UnpaidAmountSource provides unpaid invoice amounts per customer, typically from the data warehouse
+type UnpaidAmountSource interface {
+	IsEnabled() bool
+	GetCustomerUnpaidAmount(ctx context.Context, orgNumber string) (float64, error)
+}
+
+// CreditExposureService calculates customer credit exposure and checks orders against credit limits.
+// Exposure is the open order reserve plus sent offer values weighted by probability,
+// optionally including unpaid invoices from the data warehouse.
+type CreditExposureService struct {
+	customerRepo     *repository.CustomerRepository
+	userRoleRepo     *repository.UserRoleRepository
+	notificationRepo *repository.NotificationRepository
+	activityRepo     *repository.ActivityRepository
+	companyService   *CompanyService
+	unpaidSource     UnpaidAmountSource
+	logger           *zap.Logger
+}
+
+// NewCreditExposureService creates a new credit exposure service
+func NewCreditExposureService(
+	customerRepo *repository.CustomerRepository,
+	userRoleRepo *repository.UserRoleRepository,
+	notificationRepo *repository.NotificationRepository,
+	activityRepo *repository.ActivityRepository,
+	companyService *CompanyService,
+	logger *zap.Logger,
+) *CreditExposureService {
+	return &CreditExposureService{
+		customerRepo:     customerRepo,
+		userRoleRepo:     userRoleRepo,
+		notificationRepo: notificationRepo,
+		activityRepo:     activityRepo,
+		companyService:   companyService,
+		logger:           logger,
+	}
+}
+
+// SetUnpaidAmountSource sets the source of unpaid invoice amounts.
+// This is called after construction because including unpaid amounts is optional.
+func (s *CreditExposureService) SetUnpaidAmountSource(source UnpaidAmountSource) {
+	s.unpaidSource = source
+}
+
+// GetExposure returns the credit exposure of a customer
+func (s *CreditExposureService) GetExposure(ctx context.Context, customerID uuid.UUID) (*domain.CustomerCreditExposureDTO, error) {
+	customer, err := s.customerRepo.GetByID(ctx, customerID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCustomerNotFound
+		}
+		return nil, fmt.Errorf("failed to get customer: %w", err)
+	}
+
+	return s.calculate(ctx, customer, nil)
+}
+
+// CheckOrder checks whether accepting the offer as an order keeps the customer within its credit limit.
+// Returns nil when the offer has no customer or the customer has no credit limit.
+// When the limit is exceeded company admins are notified, and ErrCreditLimitExceeded is returned
+// if the offer's company blocks orders over the limit.
+func (s *CreditExposureService) CheckOrder(ctx context.Context, offer *domain.Offer) (*domain.CreditCheckDTO, error) {
+	if offer.CustomerID == nil {
+		return nil, nil
+	}
+
+	customer, err := s.customerRepo.GetByID(ctx, *offer.CustomerID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get customer: %w", err)
+	}
+	if customer.CreditLimit == nil {
+		return nil, nil
+	}
+
+	// The offer is counted at its full value once accepted, instead of its weighted sent value
+	exposure, err := s.calculate(ctx, customer, &offer.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	check := &domain.CreditCheckDTO{
+		CreditLimit:    *customer.CreditLimit,
+		ExposureBefore: roundAmount(exposure.Exposure + offer.Value*float64(offer.Probability)/100),
+		ExposureAfter:  roundAmount(exposure.Exposure + offer.Value),
+		Enforcement:    s.companyService.GetCreditLimitEnforcement(ctx, offer.CompanyID),
+	}
+	check.Exceeded = check.ExposureAfter > check.CreditLimit
+	if !check.Exceeded {
+		return check, nil
+	}
+
+	check.Message = fmt.Sprintf("Ordren gir kunden '%s' en eksponering på %.0f NOK, over kredittgrensen på %.0f NOK",
+		customer.Name, check.ExposureAfter, check.CreditLimit)
+
+	s.notifyCompanyAdmins(ctx, offer, customer, check)
+	s.logActivity(ctx, customer, offer, check)
+
+	if check.Enforcement == domain.CreditLimitEnforcementBlock {
+		return check, fmt.Errorf("%w: exposure %.0f NOK would exceed the credit limit of %.0f NOK for customer '%s'",
+			ErrCreditLimitExceeded, check.ExposureAfter, check.CreditLimit, customer.Name)
+	}
+	return check, nil
+}
+
+// calculate sums the exposure of a customer, leaving out excludeOfferID if set
+func (s *CreditExposureService) calculate(ctx context.Context, customer *domain.Customer, excludeOfferID *uuid.UUID) (*domain.CustomerCreditExposureDTO, error) {
+	totals, err := s.customerRepo.GetCreditExposureTotals(ctx, customer.ID, excludeOfferID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get credit exposure: %w", err)
+	}
+
+	dto := &domain.CustomerCreditExposureDTO{
+		CustomerID:        customer.ID,
+		CreditLimit:       customer.CreditLimit,
+		OpenOrderReserve:  roundAmount(totals.OpenOrderReserve),
+		WeightedSentValue: roundAmount(totals.WeightedSentValue),
+		OpenOrders:        totals.OpenOrders,
+		SentOffers:        totals.SentOffers,
+		CalculatedAt:      time.Now().UTC().Format(time.RFC3339),
+	}
+	exposure := totals.OpenOrderReserve + totals.WeightedSentValue
+
+	// Unpaid invoices need an org number to find the customer in the ERP
+	if s.unpaidSource != nil && s.unpaidSource.IsEnabled() && customer.OrgNumber != "" {
+		unpaid, err := s.unpaidSource.GetCustomerUnpaidAmount(ctx, customer.OrgNumber)
+		if err != nil {
+			s.logger.Warn("failed to get unpaid amount from data warehouse",
+				zap.Error(err),
+				zap.String("customer_id", customer.ID.String()))
+		} else {
+			unpaid = roundAmount(unpaid)
+			dto.UnpaidAmount = &unpaid
+			exposure += unpaid
+		}
+	}
+
+	dto.Exposure = roundAmount(exposure)
+	if customer.CreditLimit != nil {
+		available := roundAmount(*customer.CreditLimit - dto.Exposure)
+		dto.Available = &available
+		dto.OverLimit = dto.Exposure > *customer.CreditLimit
+		if *customer.CreditLimit > 0 {
+			utilization := math.Round(dto.Exposure / *customer.CreditLimit * 1000) / 10
+			dto.UtilizationPct = &utilization
+		}
+	}
+
+	return dto, nil
+}
+
+// notifyCompanyAdmins notifies the admins of the offer's company that an order exceeds a credit limit
+func (s *CreditExposureService) notifyCompanyAdmins(ctx context.Context, offer *domain.Offer, customer *domain.Customer, check *domain.CreditCheckDTO) {
+	if s.notificationRepo == nil || s.userRoleRepo == nil {
+		return
+	}
+
+	adminIDs, err := s.userRoleRepo.ListUserIDsWithRoleInCompany(ctx, domain.RoleCompanyAdmin, offer.CompanyID)
+	if err != nil {
+		s.logger.Warn("failed to list company admins for credit limit notification", zap.Error(err))
+		return
+	}
+
+	title := "Kredittgrense overskredet"
+	if check.Enforcement == domain.CreditLimitEnforcementBlock {
+		title = "Ordre stoppet av kredittgrense"
+	}
+	message := fmt.Sprintf("Ordre '%s' for %s: eksponering %.0f NOK, kredittgrense %.0f NOK",
+		offer.Title, customer.Name, check.ExposureAfter, check.CreditLimit)
+
+	for _, adminID := range adminIDs {
+		userID, err := uuid.Parse(adminID)
+		if err != nil {
+			continue
+		}
+		notification := &domain.Notification{
+			UserID:     userID,
+			Type:       string(domain.NotificationTypeCreditLimit),
+			Title:      title,
+			Message:    message,
+			EntityID:   &offer.ID,
+			EntityType: "offer",
+		}
+		if err := s.notificationRepo.Create(ctx, notification); err != nil {
+			s.logger.Warn("failed to create credit limit notification",
+				zap.Error(err),
+				zap.String("user_id", adminID))
+		}
+	}
+}
+
+// logActivity records the exceeded credit limit on the customer's activity feed
+func (s *CreditExposureService) logActivity(ctx context.Context, customer *domain.Customer, offer *domain.Offer, check *domain.CreditCheckDTO) {
+	creatorName := "System"
+	if userCtx, ok := auth.FromContext(ctx); ok && userCtx.DisplayName != "" {
+		creatorName = userCtx.DisplayName
+	}
+
+	outcome := "Ordren ble akseptert med advarsel"
+	if check.Enforcement == domain.CreditLimitEnforcementBlock {
+		outcome = "Ordren ble stoppet"
+	}
+
+	companyID := offer.CompanyID
+	activity := &domain.Activity{
+		TargetType:   domain.ActivityTargetCustomer,
+		TargetID:     customer.ID,
+		TargetName:   customer.Name,
+		Title:        "Kredittgrense overskredet",
+		Body:         fmt.Sprintf("%s. %s.", check.Message, outcome),
+		ActivityType: domain.ActivityTypeSystem,
+		Status:       domain.ActivityStatusCompleted,
+		OccurredAt:   time.Now(),
+		CreatorName:  creatorName,
+		CompanyID:    &companyID,
+	}
+
+	if err := s.activityRepo.Create(ctx, activity); err != nil {
+		s.logger.Warn("failed to log credit limit activity",
+			zap.Error(err),
+			zap.String("customer_id", customer.ID.String()))
+	}
+}
+
+// roundAmount rounds an amount to whole øre
+func roundAmount(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
@@ -0,0 +1,184 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/straye-as/relation-api/internal/auth"
+	"github.com/straye-as/relation-api/internal/domain"
+	"github.com/straye-as/relation-api/internal/mapper"
+	"github.com/straye-as/relation-api/internal/repository"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// ErrParentCustomerNotFound is returned when the parent customer of a customer group does not exist
+var ErrParentCustomerNotFound = errors.New("parent customer not found")
+
+// ErrCustomerGroupCycle is returned when a parent would make a customer its own ancestor
+var ErrCustomerGroupCycle = errors.New("customer cannot be placed under itself or one of its subsidiaries")
+
+// ErrCustomerGroupTooDeep is returned when a customer group would exceed the maximum depth
+var ErrCustomerGroupTooDeep = errors.New("customer group is too deep")
+
+// UpdateParent places a customer under a parent customer, or removes it from its group when parentID is nil.
+// The parent must not be the customer itself or one of its subsidiaries.
+func (s *CustomerService) UpdateParent(ctx context.Context, id uuid.UUID, parentID *uuid.UUID) (*domain.CustomerDTO, error) {
+	customer, err := s.customerRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCustomerNotFound
+		}
+		return nil, fmt.Errorf("failed to get customer: %w", err)
+	}
+
+	var parent *domain.Customer
+	if parentID != nil {
+		if *parentID == id {
+			return nil, ErrCustomerGroupCycle
+		}
+		parent, err = s.customerRepo.GetByID(ctx, *parentID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, ErrParentCustomerNotFound
+			}
+			return nil, fmt.Errorf("failed to get parent customer: %w", err)
+		}
+		if err := s.validateParent(ctx, id, parent); err != nil {
+			return nil, err
+		}
+	}
+
+	var oldParentName string
+	if customer.ParentCustomerID != nil {
+		if oldParent, err := s.customerRepo.GetByID(ctx, *customer.ParentCustomerID); err == nil {
+			oldParentName = oldParent.Name
+		}
+	}
+
+	customer.ParentCustomerID = parentID
+
+	// Set updated by fields (never modify created by)
+	if userCtx, ok := auth.FromContext(ctx); ok {
+		customer.UpdatedByID = userCtx.UserID.String()
+		customer.UpdatedByName = userCtx.DisplayName
+	}
+
+	if err := s.customerRepo.Update(ctx, customer); err != nil {
+		return nil, fmt.Errorf("failed to update customer parent: %w", err)
+	}
+
+	if parent != nil {
+		s.logActivity(ctx, customer.ID, customer.Name, "Konsern oppdatert", fmt.Sprintf("Kunden ble lagt under '%s'", parent.Name))
+	} else if oldParentName != "" {
+		s.logActivity(ctx, customer.ID, customer.Name, "Konsern oppdatert", fmt.Sprintf("Kunden ble fjernet fra konsernet under '%s'", oldParentName))
+	}
+
+	stats, _ := s.customerRepo.GetCustomerStats(ctx, id)
+	if stats == nil {
+		stats = &repository.CustomerStats{}
+	}
+	dto := mapper.ToCustomerDTO(customer, stats.TotalValueActive, stats.TotalValueWon, stats.ActiveOffers)
+	return &dto, nil
+}
+
+// validateParent checks that placing the customer under parent keeps the group acyclic and within the depth limit
+func (s *CustomerService) validateParent(ctx context.Context, id uuid.UUID, parent *domain.Customer) error {
+	ancestors, err := s.customerRepo.ListAncestors(ctx, parent.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get parent customer group: %w", err)
+	}
+	for _, ancestor := range ancestors {
+		if ancestor.ID == id {
+			return ErrCustomerGroupCycle
+		}
+	}
+
+	depthBelow, err := s.customerRepo.GetGroupDepth(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to get customer group depth: %w", err)
+	}
+	if len(ancestors)+1+depthBelow > repository.MaxCustomerGroupDepth {
+		return fmt.Errorf("%w: at most %d levels of subsidiaries are allowed", ErrCustomerGroupTooDeep, repository.MaxCustomerGroupDepth)
+	}
+	return nil
+}
+
+// GetGroup returns a customer with its parent customers and subsidiaries.
+// Statistics are rolled up over the customer and all its subsidiaries.
+func (s *CustomerService) GetGroup(ctx context.Context, id uuid.UUID) (*domain.CustomerGroupDTO, error) {
+	if _, err := s.customerRepo.GetByID(ctx, id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCustomerNotFound
+		}
+		return nil, fmt.Errorf("failed to get customer: %w", err)
+	}
+
+	members, err := s.customerRepo.ListGroupMembers(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list customer group: %w", err)
+	}
+	ancestors, err := s.customerRepo.ListAncestors(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list parent customers: %w", err)
+	}
+
+	result := &domain.CustomerGroupDTO{
+		CustomerID:  id,
+		Ancestors:   make([]domain.CustomerGroupMemberDTO, len(ancestors)),
+		Members:     make([]domain.CustomerGroupMemberDTO, len(members)),
+		MemberCount: len(members),
+	}
+	for i := range ancestors {
+		result.Ancestors[i] = toCustomerGroupMemberDTO(&ancestors[i], -(i + 1), nil)
+	}
+
+	memberIDs := make([]uuid.UUID, len(members))
+	for i := range members {
+		memberIDs[i] = members[i].Customer.ID
+
+		stats, err := s.customerRepo.GetCustomerStats(ctx, members[i].Customer.ID)
+		if err != nil {
+			s.logger.Warn("failed to get customer stats", zap.Error(err), zap.String("customer_id", members[i].Customer.ID.String()))
+			stats = &repository.CustomerStats{}
+		}
+		result.Members[i] = toCustomerGroupMemberDTO(&members[i].Customer, members[i].Depth, toCustomerStatsDTO(stats))
+	}
+
+	groupStats, err := s.customerRepo.GetGroupStats(ctx, memberIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get customer group stats: %w", err)
+	}
+	result.Stats = *toCustomerStatsDTO(groupStats)
+
+	return result, nil
+}
+
+func toCustomerGroupMemberDTO(customer *domain.Customer, depth int, stats *domain.CustomerStatsDTO) domain.CustomerGroupMemberDTO {
+	return domain.CustomerGroupMemberDTO{
+		ID:               customer.ID,
+		Name:             customer.Name,
+		OrgNumber:        customer.OrgNumber,
+		Status:           customer.Status,
+		ParentCustomerID: customer.ParentCustomerID,
+		Depth:            depth,
+		Stats:            stats,
+	}
+}
+
+func toCustomerStatsDTO(stats *repository.CustomerStats) *domain.CustomerStatsDTO {
+	return &domain.CustomerStatsDTO{
+		TotalValueActive: stats.TotalValueActive,
+		TotalValueWon:    stats.TotalValueWon,
+		WorkingOffers:    stats.WorkingOffers,
+		ActiveOffers:     stats.ActiveOffers,
+		CompletedOffers:  stats.CompletedOffers,
+		TotalOffers:      stats.TotalOffers,
+		ActiveDeals:      stats.ActiveDeals,
+		ActiveProjects:   stats.ActiveProjects,
+		TotalProjects:    stats.TotalProjects,
+		TotalContacts:    stats.TotalContacts,
+	}
+}
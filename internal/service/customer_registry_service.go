@@ -0,0 +1,345 @@
+package service
+
+// This file contains Enhetsregisteret (Brønnøysundregistrene) enrichment methods for customers.
+// These methods handle:
+// - Filling in name, address, municipality, county and industry code from the registry
+// - Flagging customers that are bankrupt, under liquidation or deleted
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/straye-as/relation-api/internal/auth"
+	"github.com/straye-as/relation-api/internal/domain"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// ErrRegistryNotAvailable is returned when no organization registry client is configured
+var ErrRegistryNotAvailable = errors.New("organization registry not available")
+
+// ErrOrganizationNotInRegistry is returned by OrganizationRegistry when the org number is not registered
+var ErrOrganizationNotInRegistry = errors.New("organization not found in registry")
+
+// ErrCustomerMissingOrgNumber is returned when enriching a customer without an organization number
+var ErrCustomerMissingOrgNumber = errors.New("customer has no organization number")
+
+const (
+	defaultRegistryBulkLimit = 100
+	maxRegistryBulkLimit     = 500
+	defaultRegistryStaleDays = 30
+)
+
+// OrganizationRegistry looks up organizations in the national register of legal entities.
+// Implementations must return ErrOrganizationNotInRegistry for unknown org numbers.
+type OrganizationRegistry interface {
+	LookupOrganization(ctx context.Context, orgNumber string) (*RegistryOrganization, error)
+}
+
+// RegistryOrganization is an organization as registered in Enhetsregisteret
+type RegistryOrganization struct {
+	OrganizationNumber string
+	Name               string
+	Address            string
+	PostalCode         string
+	City               string
+	Municipality       string
+	County             string
+	IndustryCode       string
+	Website            string
+	Bankrupt           bool
+	UnderLiquidation   bool
+	Deleted            bool
+}
+
+// SetOrganizationRegistry sets the registry client used for customer enrichment.
+// bulkDelay is the pause between lookups during bulk enrichment.
+// This is called after construction because the registry integration is optional.
+func (s *CustomerService) SetOrganizationRegistry(registry OrganizationRegistry, bulkDelay time.Duration) {
+	s.registry = registry
+	s.registryBulkDelay = bulkDelay
+}
+
+// EnrichFromRegistry looks up a customer in Enhetsregisteret and fills in registry data.
+// By default only empty fields are filled; with overwrite, existing values are replaced.
+// The registry status and industry code are always updated.
+func (s *CustomerService) EnrichFromRegistry(ctx context.Context, id uuid.UUID, overwrite bool) (*domain.CustomerEnrichmentResultDTO, error) {
+	if s.registry == nil {
+		return nil, ErrRegistryNotAvailable
+	}
+
+	customer, err := s.customerRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCustomerNotFound
+		}
+		return nil, fmt.Errorf("failed to get customer: %w", err)
+	}
+
+	if normalizeOrgNumber(customer.OrgNumber) == "" {
+		return nil, ErrCustomerMissingOrgNumber
+	}
+
+	return s.enrichCustomer(ctx, customer, overwrite)
+}
+
+// BulkEnrichFromRegistry enriches several customers from Enhetsregisteret.
+// Failures for individual customers are reported in the result and do not stop the run,
+// but a cancelled or expired context does: no further lookups are made and the context error is returned.
+func (s *CustomerService) BulkEnrichFromRegistry(ctx context.Context, req *domain.BulkEnrichCustomersRequest) (*domain.BulkCustomerEnrichmentResultDTO, error) {
+	if s.registry == nil {
+		return nil, ErrRegistryNotAvailable
+	}
+
+	limit := req.Limit
+	if limit <= 0 {
+		limit = defaultRegistryBulkLimit
+	}
+	if limit > maxRegistryBulkLimit {
+		limit = maxRegistryBulkLimit
+	}
+
+	result := &domain.BulkCustomerEnrichmentResultDTO{
+		Results: []domain.CustomerEnrichmentResultDTO{},
+	}
+
+	var customers []domain.Customer
+	if len(req.CustomerIDs) > 0 {
+		for _, id := range req.CustomerIDs {
+			customer, err := s.customerRepo.GetByID(ctx, id)
+			if err != nil {
+				result.Processed++
+				result.Failed++
+				result.Results = append(result.Results, domain.CustomerEnrichmentResultDTO{
+					CustomerID: id,
+					Changes:    []domain.CustomerFieldChangeDTO{},
+					Error:      ErrCustomerNotFound.Error(),
+				})
+				continue
+			}
+			customers = append(customers, *customer)
+		}
+	} else {
+		staleDays := req.StaleDays
+		if staleDays <= 0 {
+			staleDays = defaultRegistryStaleDays
+		}
+		checkedBefore := time.Now().AddDate(0, 0, -staleDays)
+
+		var err error
+		customers, err = s.customerRepo.ListForRegistryCheck(ctx, checkedBefore, limit)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list customers for registry check: %w", err)
+		}
+	}
+
+	for i := range customers {
+		if len(result.Results) >= limit {
+			break
+		}
+		// Stop calling the registry once the client has gone or the request has timed out
+		if i > 0 && s.registryBulkDelay > 0 {
+			select {
+			case <-ctx.Done():
+				return nil, fmt.Errorf("bulk registry enrichment stopped after %d customers: %w", result.Processed, ctx.Err())
+			case <-time.After(s.registryBulkDelay):
+			}
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, fmt.Errorf("bulk registry enrichment stopped after %d customers: %w", result.Processed, err)
+		}
+
+		customer := &customers[i]
+		result.Processed++
+
+		if normalizeOrgNumber(customer.OrgNumber) == "" {
+			result.Failed++
+			result.Results = append(result.Results, domain.CustomerEnrichmentResultDTO{
+				CustomerID:   customer.ID,
+				CustomerName: customer.Name,
+				Changes:      []domain.CustomerFieldChangeDTO{},
+				Error:        ErrCustomerMissingOrgNumber.Error(),
+			})
+			continue
+		}
+
+		enriched, err := s.enrichCustomer(ctx, customer, req.Overwrite)
+		if err != nil {
+			s.logger.Warn("registry enrichment failed for customer",
+				zap.String("customer_id", customer.ID.String()),
+				zap.String("org_number", customer.OrgNumber),
+				zap.Error(err))
+			result.Failed++
+			result.Results = append(result.Results, domain.CustomerEnrichmentResultDTO{
+				CustomerID:   customer.ID,
+				CustomerName: customer.Name,
+				OrgNumber:    customer.OrgNumber,
+				Changes:      []domain.CustomerFieldChangeDTO{},
+				Error:        err.Error(),
+			})
+			continue
+		}
+
+		if len(enriched.Changes) > 0 {
+			result.Enriched++
+		}
+		if enriched.RegistryFlagged {
+			result.Flagged++
+		}
+		result.Results = append(result.Results, *enriched)
+	}
+
+	s.logger.Info("bulk registry enrichment completed",
+		zap.Int("processed", result.Processed),
+		zap.Int("enriched", result.Enriched),
+		zap.Int("flagged", result.Flagged),
+		zap.Int("failed", result.Failed))
+
+	return result, nil
+}
+
+// enrichCustomer performs the registry lookup for one customer and persists the result
+func (s *CustomerService) enrichCustomer(ctx context.Context, customer *domain.Customer, overwrite bool) (*domain.CustomerEnrichmentResultDTO, error) {
+	orgNumber := normalizeOrgNumber(customer.OrgNumber)
+	previousStatus := customer.RegistryStatus
+
+	result := &domain.CustomerEnrichmentResultDTO{
+		CustomerID: customer.ID,
+		OrgNumber:  customer.OrgNumber,
+		Changes:    []domain.CustomerFieldChangeDTO{},
+	}
+
+	org, err := s.registry.LookupOrganization(ctx, orgNumber)
+	switch {
+	case errors.Is(err, ErrOrganizationNotInRegistry):
+		customer.RegistryStatus = domain.CustomerRegistryStatusNotFound
+	case err != nil:
+		return nil, fmt.Errorf("registry lookup failed: %w", err)
+	default:
+		customer.RegistryStatus = registryStatusFor(org)
+		applyRegistryFields(customer, org, overwrite, result)
+	}
+
+	now := time.Now()
+	customer.RegistryCheckedAt = &now
+
+	if userCtx, ok := auth.FromContext(ctx); ok {
+		customer.UpdatedByID = userCtx.UserID.String()
+		customer.UpdatedByName = userCtx.DisplayName
+	}
+
+	if err := s.customerRepo.Update(ctx, customer); err != nil {
+		return nil, fmt.Errorf("failed to update customer: %w", err)
+	}
+
+	result.CustomerName = customer.Name
+	result.RegistryStatus = customer.RegistryStatus
+	result.RegistryFlagged = customer.RegistryStatus.IsFlagged()
+
+	if len(result.Changes) > 0 {
+		fields := make([]string, len(result.Changes))
+		for i, change := range result.Changes {
+			fields[i] = change.Field
+		}
+		s.logRegistryActivity(ctx, customer, "Kunde beriket fra Enhetsregisteret",
+			fmt.Sprintf("Følgende felt ble oppdatert fra Enhetsregisteret: %s", strings.Join(fields, ", ")))
+	}
+
+	if customer.RegistryStatus != previousStatus && customer.RegistryStatus.IsFlagged() {
+		s.logRegistryActivity(ctx, customer, "Kunde flagget i Enhetsregisteret",
+			fmt.Sprintf("Kunden er registrert som %s i Enhetsregisteret", registryStatusLabel(customer.RegistryStatus)))
+	}
+
+	return result, nil
+}
+
+// applyRegistryFields copies registry values onto the customer and records each change.
+// The industry code is owned by the registry and is always kept in sync.
+func applyRegistryFields(customer *domain.Customer, org *RegistryOrganization, overwrite bool, result *domain.CustomerEnrichmentResultDTO) {
+	set := func(field string, target *string, value string, force bool) {
+		value = strings.TrimSpace(value)
+		if value == "" || *target == value {
+			return
+		}
+		if *target != "" && !overwrite && !force {
+			return
+		}
+		result.Changes = append(result.Changes, domain.CustomerFieldChangeDTO{
+			Field:    field,
+			OldValue: *target,
+			NewValue: value,
+		})
+		*target = value
+	}
+
+	set("name", &customer.Name, org.Name, false)
+	set("address", &customer.Address, org.Address, false)
+	set("postalCode", &customer.PostalCode, org.PostalCode, false)
+	set("city", &customer.City, org.City, false)
+	set("municipality", &customer.Municipality, org.Municipality, false)
+	set("county", &customer.County, org.County, false)
+	set("website", &customer.Website, org.Website, false)
+	set("industryCode", &customer.IndustryCode, org.IndustryCode, true)
+}
+
+// registryStatusFor derives the customer registry status from registry flags.
+// Deletion takes precedence over bankruptcy, which takes precedence over liquidation.
+func registryStatusFor(org *RegistryOrganization) domain.CustomerRegistryStatus {
+	switch {
+	case org.Deleted:
+		return domain.CustomerRegistryStatusDeleted
+	case org.Bankrupt:
+		return domain.CustomerRegistryStatusBankrupt
+	case org.UnderLiquidation:
+		return domain.CustomerRegistryStatusUnderLiquidation
+	default:
+		return domain.CustomerRegistryStatusActive
+	}
+}
+
+// registryStatusLabel returns the Norwegian label for a registry status
+func registryStatusLabel(status domain.CustomerRegistryStatus) string {
+	switch status {
+	case domain.CustomerRegistryStatusBankrupt:
+		return "konkurs"
+	case domain.CustomerRegistryStatusDeleted:
+		return "slettet"
+	case domain.CustomerRegistryStatusUnderLiquidation:
+		return "under avvikling"
+	case domain.CustomerRegistryStatusNotFound:
+		return "ikke funnet"
+	default:
+		return "aktiv"
+	}
+}
+
+// logRegistryActivity creates a system activity on the customer for registry events
+func (s *CustomerService) logRegistryActivity(ctx context.Context, customer *domain.Customer, title, body string) {
+	creatorName := "System"
+	if userCtx, ok := auth.FromContext(ctx); ok && userCtx.DisplayName != "" {
+		creatorName = userCtx.DisplayName
+	}
+
+	activity := &domain.Activity{
+		TargetType:   domain.ActivityTargetCustomer,
+		TargetID:     customer.ID,
+		TargetName:   customer.Name,
+		Title:        title,
+		Body:         body,
+		ActivityType: domain.ActivityTypeSystem,
+		Status:       domain.ActivityStatusCompleted,
+		OccurredAt:   time.Now(),
+		CreatorName:  creatorName,
+		CompanyID:    customer.CompanyID,
+	}
+
+	if err := s.activityRepo.Create(ctx, activity); err != nil {
+		s.logger.Warn("failed to log registry activity",
+			zap.Error(err),
+			zap.String("customer_id", customer.ID.String()))
+	}
+}
@@ -32,6 +32,9 @@ var ErrInvalidEmailFormat = errors.New("invalid email format")
 // ErrInvalidPhoneFormat is returned when a phone number has invalid format
 var ErrInvalidPhoneFormat = errors.New("invalid phone format")
 
+// ErrInvalidOrgNumber is returned when an organization number fails the MOD11 check
+var ErrInvalidOrgNumber = errors.New("invalid organization number")
+
 // Email and phone validation patterns
 var (
 	emailRegex = regexp.MustCompile(`^[a-zA-Z0-9._%+\-]+@[a-zA-Z0-9.\-]+\.[a-zA-Z]{2,}$`)
@@ -65,23 +68,15 @@ func validatePhone(phone string) error {
 	return nil
 }
 
-// calculateTierFromValue determines the customer tier based on total business value
-// Thresholds (in NOK):
-// - Platinum: >= 10,000,000 (10M)
-// - Gold: >= 1,000,000 (1M)
-// - Silver: >= 100,000 (100K)
-// - Bronze: < 100,000
-func calculateTierFromValue(totalValue float64) domain.CustomerTier {
-	switch {
-	case totalValue >= 10_000_000:
-		return domain.CustomerTierPlatinum
-	case totalValue >= 1_000_000:
-		return domain.CustomerTierGold
-	case totalValue >= 100_000:
-		return domain.CustomerTierSilver
-	default:
-		return domain.CustomerTierBronze
+// validateOrgNumber checks that a Norwegian organization number has nine digits and a valid MOD11 check digit
+func validateOrgNumber(orgNumber string) error {
+	if orgNumber == "" {
+		return nil // Org number is optional (private persons have none)
+	}
+	if !domain.IsValidOrgNumber(orgNumber) {
+		return ErrInvalidOrgNumber
 	}
+	return nil
 }
 
 type CustomerService struct {
@@ -92,6 +87,9 @@ type CustomerService struct {
 	activityRepo *repository.ActivityRepository
 	dwClient     DataWarehouseClient
 	logger       *zap.Logger
+	// Optional Enhetsregisteret client for enrichment
+	registry          OrganizationRegistry
+	registryBulkDelay time.Duration
 }
 
 // DataWarehouseClient interface for customer sync operations
@@ -102,8 +100,13 @@ type DataWarehouseClient interface {
 
 // DataWarehouseERPCustomer represents a customer from the ERP data warehouse
 type DataWarehouseERPCustomer struct {
+	Firmanr            int
+	CustomerNumber     string
 	OrganizationNumber string
 	Name               string
+	Address            string
+	PostalCode         string
+	City               string
 }
 
 func NewCustomerService(
@@ -160,9 +163,15 @@ func (s *CustomerService) Create(ctx context.Context, req *domain.CreateCustomer
 		return nil, fmt.Errorf("%w: contactPhone", ErrInvalidPhoneFormat)
 	}
 
+	// Validate org number (MOD11) and store it without formatting
+	if err := validateOrgNumber(req.OrgNumber); err != nil {
+		return nil, fmt.Errorf("%w: orgNumber", err)
+	}
+	orgNumber := normalizeOrgNumber(req.OrgNumber)
+
 	// Check for duplicate org number
-	if req.OrgNumber != "" {
-		existing, err := s.customerRepo.GetByOrgNumber(ctx, req.OrgNumber)
+	if orgNumber != "" {
+		existing, err := s.customerRepo.GetByOrgNumber(ctx, orgNumber)
 		if err == nil && existing != nil {
 			return nil, ErrDuplicateOrgNumber
 		}
@@ -186,7 +195,7 @@ func (s *CustomerService) Create(ctx context.Context, req *domain.CreateCustomer
 
 	customer := &domain.Customer{
 		Name:          req.Name,
-		OrgNumber:     req.OrgNumber,
+		OrgNumber:     orgNumber,
 		Email:         req.Email,
 		Phone:         req.Phone,
 		Address:       req.Address,
@@ -208,6 +217,9 @@ func (s *CustomerService) Create(ctx context.Context, req *domain.CreateCustomer
 		Website:       req.Website,
 	}
 
+	// Derive city, municipality and county from the postal code register
+	derivePostalAddress(customer.Country, customer.PostalCode, &customer.City, &customer.Municipality, &customer.County)
+
 	// Set user tracking fields on creation
 	if userCtx, ok := auth.FromContext(ctx); ok {
 		customer.CreatedByID = userCtx.UserID.String()
@@ -257,42 +269,10 @@ func (s *CustomerService) GetByID(ctx context.Context, id uuid.UUID) (*domain.Cu
 		stats = &repository.CustomerStats{}
 	}
 
-	// Auto-update tier based on total value
-	s.updateTierIfNeeded(ctx, customer, stats.TotalValueWon)
-
 	dto := mapper.ToCustomerDTO(customer, stats.TotalValueActive, stats.TotalValueWon, stats.ActiveOffers)
 	return &dto, nil
 }
 
-// updateTierIfNeeded checks if the customer tier should be updated based on total value
-// and updates it if the calculated tier is higher than the current tier
-func (s *CustomerService) updateTierIfNeeded(ctx context.Context, customer *domain.Customer, totalValue float64) {
-	calculatedTier := calculateTierFromValue(totalValue)
-
-	// Only upgrade tiers, never downgrade automatically
-	tierOrder := map[domain.CustomerTier]int{
-		domain.CustomerTierBronze:   1,
-		domain.CustomerTierSilver:   2,
-		domain.CustomerTierGold:     3,
-		domain.CustomerTierPlatinum: 4,
-	}
-
-	if tierOrder[calculatedTier] > tierOrder[customer.Tier] {
-		customer.Tier = calculatedTier
-		if err := s.customerRepo.Update(ctx, customer); err != nil {
-			s.logger.Warn("failed to auto-update customer tier",
-				zap.String("customerID", customer.ID.String()),
-				zap.String("newTier", string(calculatedTier)),
-				zap.Error(err))
-		} else {
-			s.logger.Info("auto-upgraded customer tier",
-				zap.String("customerID", customer.ID.String()),
-				zap.String("newTier", string(calculatedTier)),
-				zap.Float64("totalValue", totalValue))
-		}
-	}
-}
-
 // GetByIDWithDetails returns a customer with full details including contacts, deals, and projects
 func (s *CustomerService) GetByIDWithDetails(ctx context.Context, id uuid.UUID) (*domain.CustomerWithDetailsDTO, error) {
 	customer, err := s.customerRepo.GetCustomerWithRelations(ctx, id)
@@ -328,9 +308,6 @@ func (s *CustomerService) GetByIDWithDetails(ctx context.Context, id uuid.UUID)
 		}
 	}
 
-	// Auto-update tier based on total value
-	s.updateTierIfNeeded(ctx, customer, stats.TotalValueWon)
-
 	// Build base customer DTO
 	customerDTO := mapper.ToCustomerDTO(customer, stats.TotalValueActive, stats.TotalValueWon, stats.ActiveOffers)
 
@@ -448,8 +425,11 @@ func (s *CustomerService) Update(ctx context.Context, id uuid.UUID, req *domain.
 	if req.Status != "" {
 		customer.Status = req.Status
 	}
-	if req.Tier != "" {
+	// A manually changed tier is locked so tier recalculation does not overwrite it
+	previousTier := customer.Tier
+	if req.Tier != "" && req.Tier != customer.Tier {
 		customer.Tier = req.Tier
+		lockCustomerTier(ctx, customer)
 	}
 	if req.Industry != "" {
 		customer.Industry = req.Industry
@@ -473,6 +453,11 @@ func (s *CustomerService) Update(ctx context.Context, id uuid.UUID, req *domain.
 		customer.Website = req.Website
 	}
 
+	// A changed postal code determines city, municipality and county
+	if req.PostalCode != "" {
+		derivePostalAddress(customer.Country, customer.PostalCode, &customer.City, &customer.Municipality, &customer.County)
+	}
+
 	// Set updated by fields (never modify created by)
 	if userCtx, ok := auth.FromContext(ctx); ok {
 		customer.UpdatedByID = userCtx.UserID.String()
@@ -486,6 +471,10 @@ func (s *CustomerService) Update(ctx context.Context, id uuid.UUID, req *domain.
 		return nil, fmt.Errorf("failed to update customer: %w", err)
 	}
 
+	if customer.Tier != previousTier {
+		s.recordManualTierChange(ctx, customer, previousTier)
+	}
+
 	// Create activity
 	if userCtx, ok := auth.FromContext(ctx); ok {
 		activity := &domain.Activity{
@@ -749,7 +738,10 @@ func (s *CustomerService) UpdateTier(ctx context.Context, id uuid.UUID, tier dom
 		return nil, fmt.Errorf("failed to get customer: %w", err)
 	}
 
+	// A manually set tier is locked so tier recalculation does not overwrite it
+	previousTier := customer.Tier
 	customer.Tier = tier
+	lockCustomerTier(ctx, customer)
 
 	// Set updated by fields (never modify created by)
 	if userCtx, ok := auth.FromContext(ctx); ok {
@@ -761,7 +753,11 @@ func (s *CustomerService) UpdateTier(ctx context.Context, id uuid.UUID, tier dom
 		return nil, fmt.Errorf("failed to update customer tier: %w", err)
 	}
 
-	s.logActivity(ctx, customer.ID, customer.Name, "Nivå oppdatert", fmt.Sprintf("Kundenivå endret til '%s'", tier))
+	if tier != previousTier {
+		s.recordManualTierChange(ctx, customer, previousTier)
+	}
+
+	s.logActivity(ctx, customer.ID, customer.Name, "Nivå oppdatert", fmt.Sprintf("Kundenivå endret til '%s' og låst mot automatisk beregning", tier))
 
 	stats, _ := s.customerRepo.GetCustomerStats(ctx, id)
 	if stats == nil {
@@ -771,6 +767,37 @@ func (s *CustomerService) UpdateTier(ctx context.Context, id uuid.UUID, tier dom
 	return &dto, nil
 }
 
+// lockCustomerTier marks the customer tier as a manual override
+func lockCustomerTier(ctx context.Context, customer *domain.Customer) {
+	now := time.Now()
+	customer.TierLocked = true
+	customer.TierLockedAt = &now
+	customer.TierLockedByName = ""
+	if userCtx, ok := auth.FromContext(ctx); ok {
+		customer.TierLockedByName = userCtx.DisplayName
+	}
+}
+
+// recordManualTierChange adds a manual tier change to the customer's tier history
+func (s *CustomerService) recordManualTierChange(ctx context.Context, customer *domain.Customer, previousTier domain.CustomerTier) {
+	history := &domain.CustomerTierHistory{
+		CustomerID:   customer.ID,
+		PreviousTier: previousTier,
+		NewTier:      customer.Tier,
+		Source:       domain.CustomerTierChangeManual,
+	}
+	if userCtx, ok := auth.FromContext(ctx); ok {
+		history.ChangedByID = userCtx.UserID.String()
+		history.ChangedByName = userCtx.DisplayName
+	}
+
+	if err := s.customerRepo.CreateTierHistory(ctx, history); err != nil {
+		s.logger.Warn("failed to record customer tier history",
+			zap.Error(err),
+			zap.String("customer_id", customer.ID.String()))
+	}
+}
+
 // UpdateIndustry updates only the customer industry
 func (s *CustomerService) UpdateIndustry(ctx context.Context, id uuid.UUID, industry domain.CustomerIndustry) (*domain.CustomerDTO, error) {
 	customer, err := s.customerRepo.GetByID(ctx, id)
@@ -989,6 +1016,7 @@ func (s *CustomerService) UpdateAddress(ctx context.Context, id uuid.UUID, addre
 	customer.City = city
 	customer.PostalCode = postalCode
 	customer.Country = country
+	derivePostalAddress(customer.Country, customer.PostalCode, &customer.City, &customer.Municipality, &customer.County)
 
 	// Set updated by fields (never modify created by)
 	if userCtx, ok := auth.FromContext(ctx); ok {
@@ -1010,7 +1038,8 @@ func (s *CustomerService) UpdateAddress(ctx context.Context, id uuid.UUID, addre
 	return &dto, nil
 }
 
-// UpdatePostalCode updates only the customer postal code
+// UpdatePostalCode updates the customer postal code.
+// City, municipality and county are derived from the postal code register when the code is known.
 func (s *CustomerService) UpdatePostalCode(ctx context.Context, id uuid.UUID, postalCode string) (*domain.CustomerDTO, error) {
 	customer, err := s.customerRepo.GetByID(ctx, id)
 	if err != nil {
@@ -1022,6 +1051,7 @@ func (s *CustomerService) UpdatePostalCode(ctx context.Context, id uuid.UUID, po
 
 	oldPostalCode := customer.PostalCode
 	customer.PostalCode = postalCode
+	derivePostalAddress(customer.Country, customer.PostalCode, &customer.City, &customer.Municipality, &customer.County)
 
 	// Set updated by fields (never modify created by)
 	if userCtx, ok := auth.FromContext(ctx); ok {
@@ -0,0 +1,552 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/straye-as/relation-api/internal/auth"
+	"github.com/straye-as/relation-api/internal/domain"
+	"github.com/straye-as/relation-api/internal/mapper"
+	"github.com/straye-as/relation-api/internal/repository"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrCustomerTierRecalculationRunning is returned when a tier recalculation is already in progress
+	ErrCustomerTierRecalculationRunning = errors.New("customer tier recalculation is already running")
+
+	// ErrCustomerTierLocked is returned when recalculating a customer whose tier is manually locked
+	ErrCustomerTierLocked = errors.New("customer tier is locked by a manual override")
+
+	// ErrCustomerTierRuleNotFound is returned when a company has no tier rule
+	ErrCustomerTierRuleNotFound = errors.New("customer tier rule not found")
+
+	// ErrInvalidCustomerTierRule is returned when a tier rule is invalid
+	ErrInvalidCustomerTierRule = errors.New("invalid customer tier rule")
+
+	// ErrDefaultCustomerTierRuleRequired is returned when deleting the default tier rule
+	ErrDefaultCustomerTierRuleRequired = errors.New("the default tier rule cannot be deleted")
+)
+
+// defaultCustomerTierRule is used when no rule is configured for "all".
+// Thresholds are all-time won value in NOK: Silver 100K, Gold 1M, Platinum 10M.
+func defaultCustomerTierRule() domain.CustomerTierRule {
+	return domain.CustomerTierRule{
+		CompanyID:         domain.CompanyAll,
+		Metric:            domain.CustomerTierMetricWonValue,
+		WindowYears:       0,
+		SilverThreshold:   100_000,
+		GoldThreshold:     1_000_000,
+		PlatinumThreshold: 10_000_000,
+	}
+}
+
+// customerTierOrder ranks tiers from lowest to highest
+var customerTierOrder = map[domain.CustomerTier]int{
+	domain.CustomerTierBronze:   1,
+	domain.CustomerTierSilver:   2,
+	domain.CustomerTierGold:     3,
+	domain.CustomerTierPlatinum: 4,
+}
+
+// CustomerTierService calculates customer tiers from per-company tier rules.
+// Customers with a manually locked tier are never changed by recalculation.
+type CustomerTierService struct {
+	ruleRepo     *repository.CustomerTierRuleRepository
+	customerRepo *repository.CustomerRepository
+	activityRepo *repository.ActivityRepository
+	logger       *zap.Logger
+
+	// mu prevents concurrent recalculations of all customers
+	mu sync.Mutex
+}
+
+// NewCustomerTierService creates a new customer tier service
+func NewCustomerTierService(
+	ruleRepo *repository.CustomerTierRuleRepository,
+	customerRepo *repository.CustomerRepository,
+	activityRepo *repository.ActivityRepository,
+	logger *zap.Logger,
+) *CustomerTierService {
+	return &CustomerTierService{
+		ruleRepo:     ruleRepo,
+		customerRepo: customerRepo,
+		activityRepo: activityRepo,
+		logger:       logger,
+	}
+}
+
+// ListRules returns the configured tier rules. The default rule is always included.
+func (s *CustomerTierService) ListRules(ctx context.Context) ([]domain.CustomerTierRuleDTO, error) {
+	rules, err := s.ruleRepo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tier rules: %w", err)
+	}
+
+	dtos := make([]domain.CustomerTierRuleDTO, 0, len(rules)+1)
+	hasDefault := false
+	for i := range rules {
+		if rules[i].CompanyID == domain.CompanyAll {
+			hasDefault = true
+		}
+		dtos = append(dtos, toCustomerTierRuleDTO(&rules[i]))
+	}
+	if !hasDefault {
+		rule := defaultCustomerTierRule()
+		dtos = append([]domain.CustomerTierRuleDTO{toCustomerTierRuleDTO(&rule)}, dtos...)
+	}
+
+	return dtos, nil
+}
+
+// UpdateRule creates or replaces the tier rule for a company ("all" for the default rule).
+// Customer tiers are updated by the next recalculation.
+func (s *CustomerTierService) UpdateRule(ctx context.Context, companyID domain.CompanyID, req *domain.UpdateCustomerTierRuleRequest) (*domain.CustomerTierRuleDTO, error) {
+	if companyID != domain.CompanyAll && !domain.IsValidCompanyID(string(companyID)) {
+		return nil, fmt.Errorf("%w: unknown company %q", ErrInvalidCustomerTierRule, companyID)
+	}
+	if !req.Metric.IsValid() {
+		return nil, fmt.Errorf("%w: unknown metric %q", ErrInvalidCustomerTierRule, req.Metric)
+	}
+	if req.WindowYears < 0 {
+		return nil, fmt.Errorf("%w: windowYears cannot be negative", ErrInvalidCustomerTierRule)
+	}
+	if req.SilverThreshold < 0 || req.GoldThreshold < req.SilverThreshold || req.PlatinumThreshold < req.GoldThreshold {
+		return nil, fmt.Errorf("%w: thresholds must be ascending from silver to platinum", ErrInvalidCustomerTierRule)
+	}
+
+	rule := &domain.CustomerTierRule{
+		CompanyID:         companyID,
+		Metric:            req.Metric,
+		WindowYears:       req.WindowYears,
+		SilverThreshold:   req.SilverThreshold,
+		GoldThreshold:     req.GoldThreshold,
+		PlatinumThreshold: req.PlatinumThreshold,
+		UpdatedAt:         time.Now(),
+	}
+	if userCtx, ok := auth.FromContext(ctx); ok {
+		rule.UpdatedByID = userCtx.UserID.String()
+		rule.UpdatedByName = userCtx.DisplayName
+	}
+
+	if err := s.ruleRepo.Save(ctx, rule); err != nil {
+		return nil, fmt.Errorf("failed to save tier rule: %w", err)
+	}
+
+	s.logger.Info("customer tier rule updated",
+		zap.String("company_id", string(companyID)),
+		zap.String("metric", string(rule.Metric)),
+		zap.Int("window_years", rule.WindowYears))
+
+	dto := toCustomerTierRuleDTO(rule)
+	return &dto, nil
+}
+
+// DeleteRule removes a company-specific tier rule so the company falls back to the default rule
+func (s *CustomerTierService) DeleteRule(ctx context.Context, companyID domain.CompanyID) error {
+	if companyID == domain.CompanyAll {
+		return ErrDefaultCustomerTierRuleRequired
+	}
+
+	if err := s.ruleRepo.Delete(ctx, companyID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrCustomerTierRuleNotFound
+		}
+		return fmt.Errorf("failed to delete tier rule: %w", err)
+	}
+	return nil
+}
+
+// RecalculateAll evaluates every customer against the tier rule for its company.
+// Tiers are both upgraded and downgraded; customers with a locked tier are skipped.
+func (s *CustomerTierService) RecalculateAll(ctx context.Context) (*domain.CustomerTierRecalculationDTO, error) {
+	if !s.mu.TryLock() {
+		return nil, ErrCustomerTierRecalculationRunning
+	}
+	defer s.mu.Unlock()
+
+	startedAt := time.Now()
+	result := &domain.CustomerTierRecalculationDTO{
+		StartedAt: startedAt.UTC().Format(time.RFC3339),
+	}
+
+	rules, err := s.loadRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	customers, err := s.customerRepo.ListForTierRecalculation(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list customers: %w", err)
+	}
+
+	// Won offer totals depend only on the rule window, so load them once per window
+	totalsByWindow := make(map[int]map[uuid.UUID]repository.WonOfferTotals)
+	var unchanged []uuid.UUID
+
+	for i := range customers {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		customer := &customers[i]
+		if customer.TierLocked {
+			result.SkippedLocked++
+			continue
+		}
+
+		rule := ruleForCustomer(rules, customer)
+		totals, ok := totalsByWindow[rule.WindowYears]
+		if !ok {
+			totals, err = s.customerRepo.GetWonOfferTotals(ctx, tierWindowStart(rule, startedAt), nil)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get won offer totals: %w", err)
+			}
+			totalsByWindow[rule.WindowYears] = totals
+		}
+
+		result.Checked++
+		value := tierMetricValue(rule, totals[customer.ID])
+		tier := rule.TierFor(value)
+		if tier == customer.Tier {
+			unchanged = append(unchanged, customer.ID)
+			continue
+		}
+
+		previousTier := customer.Tier
+		if err := s.applyCalculatedTier(ctx, customer, rule, tier, value, nil); err != nil {
+			result.Errors++
+			s.logger.Warn("failed to update customer tier",
+				zap.Error(err),
+				zap.String("customer_id", customer.ID.String()))
+			continue
+		}
+
+		if customerTierOrder[tier] > customerTierOrder[previousTier] {
+			result.Upgraded++
+		} else {
+			result.Downgraded++
+		}
+	}
+
+	if err := s.customerRepo.MarkTierCalculated(ctx, unchanged, startedAt); err != nil {
+		s.logger.Warn("failed to mark customer tiers as calculated", zap.Error(err))
+	}
+
+	result.FinishedAt = time.Now().UTC().Format(time.RFC3339)
+	return result, nil
+}
+
+// RunScheduledRecalculation recalculates all customer tiers.
+// Returns the number of customers checked, changed and skipped because their tier is locked.
+func (s *CustomerTierService) RunScheduledRecalculation(ctx context.Context) (checked int, changed int, skipped int, err error) {
+	result, err := s.RecalculateAll(ctx)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	return result.Checked, result.Upgraded + result.Downgraded, result.SkippedLocked, nil
+}
+
+// RecalculateCustomer evaluates a single customer against its tier rule
+func (s *CustomerTierService) RecalculateCustomer(ctx context.Context, id uuid.UUID) (*domain.CustomerDTO, error) {
+	customer, err := s.getCustomer(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if customer.TierLocked {
+		return nil, ErrCustomerTierLocked
+	}
+
+	if err := s.recalculate(ctx, customer, nil); err != nil {
+		return nil, err
+	}
+
+	return s.toCustomerDTO(ctx, customer), nil
+}
+
+// UnlockTier removes a manual tier override and immediately recalculates the tier
+func (s *CustomerTierService) UnlockTier(ctx context.Context, id uuid.UUID) (*domain.CustomerDTO, error) {
+	customer, err := s.getCustomer(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !customer.TierLocked {
+		return s.toCustomerDTO(ctx, customer), nil
+	}
+
+	unlock := map[string]interface{}{
+		"tier_locked":         false,
+		"tier_locked_at":      nil,
+		"tier_locked_by_name": "",
+	}
+	customer.TierLocked = false
+	customer.TierLockedAt = nil
+	customer.TierLockedByName = ""
+
+	if err := s.recalculate(ctx, customer, unlock); err != nil {
+		return nil, err
+	}
+
+	s.logActivity(ctx, customer, "Nivålås fjernet",
+		fmt.Sprintf("Manuell overstyring av kundenivå er fjernet. Kundenivået beregnes automatisk og er nå '%s'", customer.Tier))
+
+	return s.toCustomerDTO(ctx, customer), nil
+}
+
+// GetHistory returns the tier changes of a customer, newest first
+func (s *CustomerTierService) GetHistory(ctx context.Context, id uuid.UUID, page, pageSize int) (*domain.PaginatedResponse, error) {
+	if _, err := s.getCustomer(ctx, id); err != nil {
+		return nil, err
+	}
+
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = 20
+	}
+	if pageSize > 200 {
+		pageSize = 200
+	}
+
+	history, total, err := s.customerRepo.ListTierHistory(ctx, id, page, pageSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tier history: %w", err)
+	}
+
+	dtos := make([]domain.CustomerTierHistoryDTO, len(history))
+	for i := range history {
+		dtos[i] = toCustomerTierHistoryDTO(&history[i])
+	}
+
+	return &domain.PaginatedResponse{
+		Data:       dtos,
+		Total:      total,
+		Page:       page,
+		PageSize:   pageSize,
+		TotalPages: int((total + int64(pageSize) - 1) / int64(pageSize)),
+	}, nil
+}
+
+// recalculate evaluates one customer and saves the result together with any extra column updates
+func (s *CustomerTierService) recalculate(ctx context.Context, customer *domain.Customer, updates map[string]interface{}) error {
+	rules, err := s.loadRules(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	rule := ruleForCustomer(rules, customer)
+	totals, err := s.customerRepo.GetWonOfferTotals(ctx, tierWindowStart(rule, now), []uuid.UUID{customer.ID})
+	if err != nil {
+		return fmt.Errorf("failed to get won offer totals: %w", err)
+	}
+
+	value := tierMetricValue(rule, totals[customer.ID])
+	tier := rule.TierFor(value)
+	if tier != customer.Tier {
+		return s.applyCalculatedTier(ctx, customer, rule, tier, value, updates)
+	}
+
+	if updates == nil {
+		updates = map[string]interface{}{}
+	}
+	updates["tier_calculated_at"] = now
+	if err := s.customerRepo.ChangeTier(ctx, customer.ID, updates, nil); err != nil {
+		return fmt.Errorf("failed to update customer tier: %w", err)
+	}
+	customer.TierCalculatedAt = &now
+	return nil
+}
+
+// applyCalculatedTier saves a calculated tier, records it in the tier history and logs an activity
+func (s *CustomerTierService) applyCalculatedTier(ctx context.Context, customer *domain.Customer, rule *domain.CustomerTierRule, tier domain.CustomerTier, value float64, updates map[string]interface{}) error {
+	now := time.Now()
+	if updates == nil {
+		updates = map[string]interface{}{}
+	}
+	updates["tier"] = tier
+	updates["tier_calculated_at"] = now
+
+	metricValue := value
+	windowYears := rule.WindowYears
+	ruleCompanyID := rule.CompanyID
+	history := &domain.CustomerTierHistory{
+		PreviousTier:  customer.Tier,
+		NewTier:       tier,
+		Source:        domain.CustomerTierChangeCalculated,
+		Metric:        rule.Metric,
+		MetricValue:   &metricValue,
+		WindowYears:   &windowYears,
+		RuleCompanyID: &ruleCompanyID,
+		ChangedByName: "System",
+	}
+	if userCtx, ok := auth.FromContext(ctx); ok {
+		history.ChangedByID = userCtx.UserID.String()
+		history.ChangedByName = userCtx.DisplayName
+	}
+
+	if err := s.customerRepo.ChangeTier(ctx, customer.ID, updates, history); err != nil {
+		return fmt.Errorf("failed to update customer tier: %w", err)
+	}
+
+	previousTier := customer.Tier
+	customer.Tier = tier
+	customer.TierCalculatedAt = &now
+
+	s.logActivity(ctx, customer, "Kundenivå beregnet",
+		fmt.Sprintf("Kundenivå endret fra '%s' til '%s' (%s)", previousTier, tier, describeTierMetric(rule, value)))
+
+	s.logger.Info("customer tier recalculated",
+		zap.String("customer_id", customer.ID.String()),
+		zap.String("previous_tier", string(previousTier)),
+		zap.String("new_tier", string(tier)),
+		zap.Float64("metric_value", value))
+
+	return nil
+}
+
+// loadRules returns the tier rules by company, always including a default rule for "all"
+func (s *CustomerTierService) loadRules(ctx context.Context) (map[domain.CompanyID]*domain.CustomerTierRule, error) {
+	rules, err := s.ruleRepo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tier rules: %w", err)
+	}
+
+	byCompany := make(map[domain.CompanyID]*domain.CustomerTierRule, len(rules)+1)
+	for i := range rules {
+		byCompany[rules[i].CompanyID] = &rules[i]
+	}
+	if _, ok := byCompany[domain.CompanyAll]; !ok {
+		rule := defaultCustomerTierRule()
+		byCompany[domain.CompanyAll] = &rule
+	}
+	return byCompany, nil
+}
+
+func (s *CustomerTierService) getCustomer(ctx context.Context, id uuid.UUID) (*domain.Customer, error) {
+	customer, err := s.customerRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCustomerNotFound
+		}
+		return nil, fmt.Errorf("failed to get customer: %w", err)
+	}
+	return customer, nil
+}
+
+func (s *CustomerTierService) toCustomerDTO(ctx context.Context, customer *domain.Customer) *domain.CustomerDTO {
+	stats, _ := s.customerRepo.GetCustomerStats(ctx, customer.ID)
+	if stats == nil {
+		stats = &repository.CustomerStats{}
+	}
+	dto := mapper.ToCustomerDTO(customer, stats.TotalValueActive, stats.TotalValueWon, stats.ActiveOffers)
+	return &dto
+}
+
+// logActivity records a tier change on the customer's activity feed
+func (s *CustomerTierService) logActivity(ctx context.Context, customer *domain.Customer, title, body string) {
+	creatorName := "System"
+	creatorID := ""
+	if userCtx, ok := auth.FromContext(ctx); ok && userCtx.DisplayName != "" {
+		creatorName = userCtx.DisplayName
+		creatorID = userCtx.UserID.String()
+	}
+
+	activity := &domain.Activity{
+		TargetType:   domain.ActivityTargetCustomer,
+		TargetID:     customer.ID,
+		TargetName:   customer.Name,
+		Title:        title,
+		Body:         body,
+		ActivityType: domain.ActivityTypeSystem,
+		Status:       domain.ActivityStatusCompleted,
+		OccurredAt:   time.Now(),
+		CreatorName:  creatorName,
+		CreatorID:    creatorID,
+		CompanyID:    customer.CompanyID,
+	}
+
+	if err := s.activityRepo.Create(ctx, activity); err != nil {
+		s.logger.Warn("failed to log tier activity",
+			zap.Error(err),
+			zap.String("customer_id", customer.ID.String()))
+	}
+}
+
+// ruleForCustomer returns the rule for the customer's company, falling back to the default rule
+func ruleForCustomer(rules map[domain.CompanyID]*domain.CustomerTierRule, customer *domain.Customer) *domain.CustomerTierRule {
+	if customer.CompanyID != nil {
+		if rule, ok := rules[*customer.CompanyID]; ok {
+			return rule
+		}
+	}
+	return rules[domain.CompanyAll]
+}
+
+// tierWindowStart returns the start of the rule's rolling window, or nil for all time
+func tierWindowStart(rule *domain.CustomerTierRule, now time.Time) *time.Time {
+	if rule.WindowYears <= 0 {
+		return nil
+	}
+	since := now.AddDate(-rule.WindowYears, 0, 0)
+	return &since
+}
+
+// tierMetricValue returns the value the rule compares against its thresholds
+func tierMetricValue(rule *domain.CustomerTierRule, totals repository.WonOfferTotals) float64 {
+	if rule.Metric == domain.CustomerTierMetricOrderCount {
+		return float64(totals.Count)
+	}
+	return totals.Value
+}
+
+// describeTierMetric describes the metric value behind a calculated tier, in Norwegian
+func describeTierMetric(rule *domain.CustomerTierRule, value float64) string {
+	period := "totalt"
+	if rule.WindowYears > 0 {
+		period = fmt.Sprintf("siste %d år", rule.WindowYears)
+	}
+	if rule.Metric == domain.CustomerTierMetricOrderCount {
+		return fmt.Sprintf("antall vunne ordre %s: %.0f", period, value)
+	}
+	return fmt.Sprintf("vunnet ordreverdi %s: %.0f NOK", period, value)
+}
+
+func toCustomerTierRuleDTO(rule *domain.CustomerTierRule) domain.CustomerTierRuleDTO {
+	dto := domain.CustomerTierRuleDTO{
+		CompanyID:         rule.CompanyID,
+		Metric:            rule.Metric,
+		WindowYears:       rule.WindowYears,
+		SilverThreshold:   rule.SilverThreshold,
+		GoldThreshold:     rule.GoldThreshold,
+		PlatinumThreshold: rule.PlatinumThreshold,
+		UpdatedByName:     rule.UpdatedByName,
+	}
+	if !rule.UpdatedAt.IsZero() {
+		updatedAt := rule.UpdatedAt.UTC().Format(time.RFC3339)
+		dto.UpdatedAt = &updatedAt
+	}
+	return dto
+}
+
+func toCustomerTierHistoryDTO(history *domain.CustomerTierHistory) domain.CustomerTierHistoryDTO {
+	return domain.CustomerTierHistoryDTO{
+		ID:            history.ID,
+		CustomerID:    history.CustomerID,
+		PreviousTier:  history.PreviousTier,
+		NewTier:       history.NewTier,
+		Source:        history.Source,
+		Metric:        history.Metric,
+		MetricValue:   history.MetricValue,
+		WindowYears:   history.WindowYears,
+		RuleCompanyID: history.RuleCompanyID,
+		ChangedByName: history.ChangedByName,
+		CreatedAt:     history.CreatedAt.UTC().Format(time.RFC3339),
+	}
+}
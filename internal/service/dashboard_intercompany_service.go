@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/straye-as/relation-api/internal/domain"
+	"github.com/straye-as/relation-api/internal/repository"
+	"go.uber.org/zap"
+)
+
+// getIntercompanySplit calculates the key dashboard figures separately for internal and external customers.
+// Failures are logged and leave the affected figures at zero, like the other dashboard metrics.
+func (s *DashboardService) getIntercompanySplit(ctx context.Context, fromDate, toDate *time.Time) *domain.IntercompanySplitDTO {
+	return &domain.IntercompanySplitDTO{
+		Internal: s.getIntercompanyFigures(repository.WithCustomerScope(ctx, repository.CustomerScopeInternal), fromDate, toDate),
+		External: s.getIntercompanyFigures(repository.WithCustomerScope(ctx, repository.CustomerScopeExternal), fromDate, toDate),
+	}
+}
+
+// getIntercompanyFigures calculates the key dashboard figures for the customer scope of the context
+func (s *DashboardService) getIntercompanyFigures(ctx context.Context, fromDate, toDate *time.Time) domain.IntercompanyFiguresDTO {
+	var figures domain.IntercompanyFiguresDTO
+	scope := zap.String("customer_scope", string(repository.CustomerScopeFromContext(ctx)))
+
+	offerStats, err := s.offerRepo.GetAggregatedOfferStats(ctx, fromDate, toDate)
+	if err != nil {
+		s.logger.Warn("failed to get aggregated offer stats", zap.Error(err), scope)
+	} else {
+		figures.OfferCount = offerStats.TotalOfferCount
+		figures.OfferReserve = offerStats.OfferReserve
+		figures.WeightedOfferReserve = offerStats.WeightedOfferReserve
+	}
+
+	winRateStats, err := s.offerRepo.GetDashboardWinRateStats(ctx, fromDate, toDate)
+	if err != nil {
+		s.logger.Warn("failed to get dashboard win rate stats", zap.Error(err), scope)
+	} else {
+		figures.WonCount = winRateStats.WonCount
+		figures.WonValue = winRateStats.WonValue
+	}
+
+	orderStats, err := s.offerRepo.GetOrderStats(ctx, nil, fromDate, toDate)
+	if err != nil {
+		s.logger.Warn("failed to get order stats", zap.Error(err), scope)
+	} else {
+		figures.ActiveOrderCount = int(orderStats.TotalOrders)
+		figures.OrderValue = orderStats.TotalValue
+		figures.OrderReserve = orderStats.OrderReserve
+		figures.TotalInvoiced = orderStats.TotalInvoiced
+	}
+
+	return figures
+}
+
+// GetIntercompanyMatrix returns won offers (order and completed phases) to internal customers,
+// grouped by selling company and internal customer, for elimination in the group accounts.
+// Uses the same time range options as GetMetrics.
+func (s *DashboardService) GetIntercompanyMatrix(ctx context.Context, timeRange domain.TimeRange, dateRange *domain.DateRangeFilter) (*domain.IntercompanyMatrixDTO, error) {
+	if timeRange == "" {
+		timeRange = domain.TimeRangeRolling12Months
+	}
+	_, fromDate, toDate := resolveDashboardDateRange(timeRange, dateRange)
+
+	sales, err := s.offerRepo.GetIntercompanySales(ctx, fromDate, toDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get intercompany sales: %w", err)
+	}
+
+	matrix := &domain.IntercompanyMatrixDTO{
+		TimeRange: timeRange,
+		Cells:     make([]domain.IntercompanyMatrixCellDTO, len(sales)),
+	}
+	matrix.FromDate, matrix.ToDate = formatDashboardDateRange(timeRange, dateRange)
+
+	for i, sale := range sales {
+		matrix.Cells[i] = domain.IntercompanyMatrixCellDTO{
+			SellerCompanyID:   sale.SellerCompanyID,
+			BuyerCompanyID:    sale.BuyerCompanyID,
+			BuyerCustomerID:   sale.BuyerCustomerID,
+			BuyerCustomerName: sale.BuyerCustomerName,
+			WonCount:          sale.WonCount,
+			WonValue:          sale.WonValue,
+			Invoiced:          sale.Invoiced,
+			OrderReserve:      sale.OrderReserve,
+		}
+		matrix.TotalWonCount += sale.WonCount
+		matrix.TotalWonValue += sale.WonValue
+		matrix.TotalInvoiced += sale.Invoiced
+		matrix.TotalOrderReserve += sale.OrderReserve
+	}
+
+	return matrix, nil
+}
@@ -49,33 +49,31 @@ func NewDashboardService(
 // IMPORTANT: Pipeline and offer metrics use aggregation to avoid double-counting.
 // When a project has multiple offers, only the highest value offer per phase is counted.
 // Orphan offers (without project) are included at full value.
-func (s *DashboardService) GetMetrics(ctx context.Context, timeRange domain.TimeRange, dateRange *domain.DateRangeFilter) (*domain.DashboardMetrics, error) {
+//
+// view controls offers to internal customers (Straye group companies): "all" (default) counts them as revenue,
+// "consolidated" eliminates them, and "split" additionally reports internal and external figures separately.
+func (s *DashboardService) GetMetrics(ctx context.Context, timeRange domain.TimeRange, dateRange *domain.DateRangeFilter, view domain.IntercompanyView) (*domain.DashboardMetrics, error) {
 	// Default to rolling 12 months if not specified or invalid
 	if timeRange == "" {
 		timeRange = domain.TimeRangeRolling12Months
 	}
+	if view == "" {
+		view = domain.IntercompanyViewAll
+	}
 
 	// Calculate date filter based on time range or custom date range
-	var since *time.Time
-	var fromDate, toDate *time.Time
+	since, fromDate, toDate := resolveDashboardDateRange(timeRange, dateRange)
 
-	if timeRange == domain.TimeRangeCustom && dateRange != nil {
-		// Use custom date range
-		fromDate = dateRange.FromDate
-		toDate = dateRange.ToDate
-		// For backward compatibility with existing pipeline stats, use fromDate as "since"
-		since = fromDate
-	} else if timeRange == domain.TimeRangeRolling12Months {
-		t := time.Now().AddDate(-1, 0, 0)
-		since = &t
-		fromDate = &t
+	// Consolidated figures eliminate intercompany sales from every offer metric below
+	if view == domain.IntercompanyViewConsolidated {
+		ctx = repository.WithCustomerScope(ctx, repository.CustomerScopeExternal)
 	}
-	// For allTime, since/fromDate/toDate remain nil (no date filter)
 
 	const recentLimit = 5
 
 	metrics := &domain.DashboardMetrics{
 		TimeRange:        timeRange,
+		View:             view,
 		Pipeline:         []domain.PipelinePhaseData{},
 		RecentOffers:     []domain.OfferDTO{},
 		RecentOrders:     []domain.OfferDTO{},
@@ -84,16 +82,7 @@ func (s *DashboardService) GetMetrics(ctx context.Context, timeRange domain.Time
 	}
 
 	// Set date range in response for custom ranges
-	if timeRange == domain.TimeRangeCustom && dateRange != nil {
-		if dateRange.FromDate != nil {
-			fromStr := dateRange.FromDate.UTC().Format(time.RFC3339)
-			metrics.FromDate = &fromStr
-		}
-		if dateRange.ToDate != nil {
-			toStr := dateRange.ToDate.UTC().Format(time.RFC3339)
-			metrics.ToDate = &toStr
-		}
-	}
+	metrics.FromDate, metrics.ToDate = formatDashboardDateRange(timeRange, dateRange)
 
 	// Get aggregated offer statistics (using aggregation to avoid double-counting)
 	// This replaces the old GetDashboardOfferStats method
@@ -213,9 +202,46 @@ func (s *DashboardService) GetMetrics(ctx context.Context, timeRange domain.Time
 		}
 	}
 
+	if view == domain.IntercompanyViewSplit {
+		metrics.Intercompany = s.getIntercompanySplit(ctx, fromDate, toDate)
+	}
+
 	return metrics, nil
 }
 
+// resolveDashboardDateRange calculates the date filter for a time range or custom date range.
+// since is used for activity windows; for allTime all values are nil (no date filter).
+func resolveDashboardDateRange(timeRange domain.TimeRange, dateRange *domain.DateRangeFilter) (since, fromDate, toDate *time.Time) {
+	if timeRange == domain.TimeRangeCustom && dateRange != nil {
+		// Use custom date range
+		fromDate = dateRange.FromDate
+		toDate = dateRange.ToDate
+		// For backward compatibility with existing pipeline stats, use fromDate as "since"
+		since = fromDate
+	} else if timeRange == domain.TimeRangeRolling12Months {
+		t := time.Now().AddDate(-1, 0, 0)
+		since = &t
+		fromDate = &t
+	}
+	return since, fromDate, toDate
+}
+
+// formatDashboardDateRange returns the custom date range in ISO 8601 format for the response
+func formatDashboardDateRange(timeRange domain.TimeRange, dateRange *domain.DateRangeFilter) (fromDate, toDate *string) {
+	if timeRange != domain.TimeRangeCustom || dateRange == nil {
+		return nil, nil
+	}
+	if dateRange.FromDate != nil {
+		fromStr := dateRange.FromDate.UTC().Format(time.RFC3339)
+		fromDate = &fromStr
+	}
+	if dateRange.ToDate != nil {
+		toStr := dateRange.ToDate.UTC().Format(time.RFC3339)
+		toDate = &toStr
+	}
+	return fromDate, toDate
+}
+
 func (s *DashboardService) Search(ctx context.Context, query string) (*domain.SearchResults, error) {
 	limit := 10
 
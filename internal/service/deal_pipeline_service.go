@@ -0,0 +1,295 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/straye-as/relation-api/internal/auth"
+	"github.com/straye-as/relation-api/internal/domain"
+	"github.com/straye-as/relation-api/internal/mapper"
+	"github.com/straye-as/relation-api/internal/repository"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// dealStageKeyPattern restricts stage keys to lowercase identifiers, e.g. "befaring" or "call_off"
+var dealStageKeyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// DealPipelineService manages the deal pipelines and stages configured per company
+type DealPipelineService struct {
+	pipelineRepo *repository.DealPipelineRepository
+	logger       *zap.Logger
+}
+
+// NewDealPipelineService creates a new deal pipeline service
+func NewDealPipelineService(pipelineRepo *repository.DealPipelineRepository, logger *zap.Logger) *DealPipelineService {
+	return &DealPipelineService{
+		pipelineRepo: pipelineRepo,
+		logger:       logger,
+	}
+}
+
+// List returns the pipelines visible to the user, optionally for a single company
+func (s *DealPipelineService) List(ctx context.Context, companyID *domain.CompanyID) ([]domain.DealPipelineDTO, error) {
+	pipelines, err := s.pipelineRepo.List(ctx, companyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deal pipelines: %w", err)
+	}
+
+	dtos := make([]domain.DealPipelineDTO, len(pipelines))
+	for i := range pipelines {
+		dtos[i] = mapper.ToDealPipelineDTO(&pipelines[i])
+	}
+	return dtos, nil
+}
+
+// GetByID returns a pipeline with its stages
+func (s *DealPipelineService) GetByID(ctx context.Context, id uuid.UUID) (*domain.DealPipelineDTO, error) {
+	pipeline, err := s.get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	dto := mapper.ToDealPipelineDTO(pipeline)
+	return &dto, nil
+}
+
+// Create adds a pipeline to a company. The first pipeline of a company always becomes its default.
+func (s *DealPipelineService) Create(ctx context.Context, req *domain.CreateDealPipelineRequest) (*domain.DealPipelineDTO, error) {
+	if err := s.checkManage(ctx, req.CompanyID); err != nil {
+		return nil, err
+	}
+	if !domain.IsValidCompanyID(string(req.CompanyID)) {
+		return nil, fmt.Errorf("%w: unknown company %q", ErrInvalidDealPipeline, req.CompanyID)
+	}
+
+	stages, err := buildDealPipelineStages(req.Stages)
+	if err != nil {
+		return nil, err
+	}
+
+	pipeline := &domain.DealPipeline{
+		CompanyID:   req.CompanyID,
+		Name:        req.Name,
+		Description: req.Description,
+		IsDefault:   req.IsDefault,
+		Stages:      stages,
+	}
+	if !pipeline.IsDefault {
+		if _, err := s.pipelineRepo.GetDefault(ctx, req.CompanyID); errors.Is(err, gorm.ErrRecordNotFound) {
+			pipeline.IsDefault = true
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to get default deal pipeline: %w", err)
+		}
+	}
+
+	if err := s.pipelineRepo.Create(ctx, pipeline); err != nil {
+		return nil, fmt.Errorf("failed to create deal pipeline: %w", err)
+	}
+
+	s.logger.Info("deal pipeline created",
+		zap.String("pipeline_id", pipeline.ID.String()),
+		zap.String("company_id", string(pipeline.CompanyID)),
+		zap.Int("stages", len(pipeline.Stages)))
+
+	dto := mapper.ToDealPipelineDTO(pipeline)
+	return &dto, nil
+}
+
+// Update replaces the name and stages of a pipeline. Stages that deals are currently in cannot be removed.
+func (s *DealPipelineService) Update(ctx context.Context, id uuid.UUID, req *domain.UpdateDealPipelineRequest) (*domain.DealPipelineDTO, error) {
+	pipeline, err := s.get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkManage(ctx, pipeline.CompanyID); err != nil {
+		return nil, err
+	}
+	if pipeline.IsDefault && !req.IsDefault {
+		return nil, ErrDefaultDealPipelineRequired
+	}
+
+	stages, err := buildDealPipelineStages(req.Stages)
+	if err != nil {
+		return nil, err
+	}
+
+	inUse, err := s.pipelineRepo.StagesInUse(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stages in use: %w", err)
+	}
+	updated := &domain.DealPipeline{Stages: stages}
+	for _, key := range inUse {
+		if updated.Stage(key) == nil {
+			return nil, fmt.Errorf("%w: stage %s has deals", ErrInvalidDealPipeline, key)
+		}
+	}
+
+	pipeline.Name = req.Name
+	pipeline.Description = req.Description
+	pipeline.IsDefault = req.IsDefault
+	pipeline.Stages = stages
+
+	if err := s.pipelineRepo.Update(ctx, pipeline); err != nil {
+		return nil, fmt.Errorf("failed to update deal pipeline: %w", err)
+	}
+
+	dto := mapper.ToDealPipelineDTO(pipeline)
+	return &dto, nil
+}
+
+// Delete removes a pipeline without deals. The default pipeline of a company cannot be deleted.
+func (s *DealPipelineService) Delete(ctx context.Context, id uuid.UUID) error {
+	pipeline, err := s.get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.checkManage(ctx, pipeline.CompanyID); err != nil {
+		return err
+	}
+	if pipeline.IsDefault {
+		return ErrDefaultDealPipelineRequired
+	}
+
+	count, err := s.pipelineRepo.CountDeals(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to count deals in pipeline: %w", err)
+	}
+	if count > 0 {
+		return ErrDealPipelineInUse
+	}
+
+	if err := s.pipelineRepo.Delete(ctx, id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrDealPipelineNotFound
+		}
+		return fmt.Errorf("failed to delete deal pipeline: %w", err)
+	}
+	return nil
+}
+
+func (s *DealPipelineService) get(ctx context.Context, id uuid.UUID) (*domain.DealPipeline, error) {
+	pipeline, err := s.pipelineRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrDealPipelineNotFound
+		}
+		return nil, fmt.Errorf("failed to get deal pipeline: %w", err)
+	}
+	return pipeline, nil
+}
+
+// checkManage verifies that the user may configure the pipelines of a company
+func (s *DealPipelineService) checkManage(ctx context.Context, companyID domain.CompanyID) error {
+	userCtx, ok := auth.FromContext(ctx)
+	if !ok {
+		return ErrUnauthorized
+	}
+	if !userCtx.HasAnyRole(domain.RoleManager, domain.RoleCompanyAdmin, domain.RoleSuperAdmin) || !userCtx.CanAccessCompany(companyID) {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// buildDealPipelineStages validates the stage definitions and orders them: open stages as given,
+// followed by won and lost, which are added when omitted.
+func buildDealPipelineStages(inputs []domain.DealPipelineStageInput) ([]domain.DealPipelineStage, error) {
+	var open []domain.DealPipelineStage
+	var won, lost *domain.DealPipelineStage
+	seen := make(map[domain.DealStage]bool, len(inputs))
+
+	for _, input := range inputs {
+		if !dealStageKeyPattern.MatchString(string(input.Key)) {
+			return nil, fmt.Errorf("%w: stage key %q must be lowercase letters, digits and underscores", ErrInvalidDealPipeline, input.Key)
+		}
+		if seen[input.Key] {
+			return nil, fmt.Errorf("%w: duplicate stage key %s", ErrInvalidDealPipeline, input.Key)
+		}
+		seen[input.Key] = true
+
+		for _, field := range input.RequiredFields {
+			if _, ok := dealStageRequirements[field]; !ok {
+				return nil, fmt.Errorf("%w: unknown required field %q on stage %s", ErrInvalidDealPipeline, field, input.Key)
+			}
+		}
+
+		stage := domain.DealPipelineStage{
+			Key:            input.Key,
+			Name:           input.Name,
+			Kind:           domain.DealStageKindOpen,
+			Probability:    input.Probability,
+			RequiredFields: pq.StringArray(input.RequiredFields),
+		}
+		if input.AllowedTransitions != nil {
+			stage.AllowedTransitions = make(pq.StringArray, len(input.AllowedTransitions))
+			for i, key := range input.AllowedTransitions {
+				stage.AllowedTransitions[i] = string(key)
+			}
+		}
+		if stage.RequiredFields == nil {
+			stage.RequiredFields = pq.StringArray{}
+		}
+
+		switch input.Key {
+		case domain.DealStageWon:
+			stage.Kind = domain.DealStageKindWon
+			won = &stage
+		case domain.DealStageLost:
+			stage.Kind = domain.DealStageKindLost
+			lost = &stage
+		default:
+			// Only open stages can go stale
+			stage.StaleAfterDays = input.StaleAfterDays
+			open = append(open, stage)
+		}
+	}
+
+	if len(open) == 0 {
+		return nil, fmt.Errorf("%w: at least one open stage is required", ErrInvalidDealPipeline)
+	}
+	if won == nil {
+		won = &domain.DealPipelineStage{Key: domain.DealStageWon, Name: "Vunnet", Kind: domain.DealStageKindWon, Probability: 100, RequiredFields: pq.StringArray{}}
+	}
+	if lost == nil {
+		lost = &domain.DealPipelineStage{Key: domain.DealStageLost, Name: "Tapt", Kind: domain.DealStageKindLost, RequiredFields: pq.StringArray{}}
+	}
+
+	// Default transitions: next, previous and lost for open stages; won is final; lost reopens to the first stage
+	for i := range open {
+		if open[i].AllowedTransitions != nil {
+			continue
+		}
+		next := domain.DealStageWon
+		if i+1 < len(open) {
+			next = open[i+1].Key
+		}
+		transitions := pq.StringArray{string(next)}
+		if i > 0 {
+			transitions = append(transitions, string(open[i-1].Key))
+		}
+		open[i].AllowedTransitions = append(transitions, string(domain.DealStageLost))
+	}
+	if won.AllowedTransitions == nil {
+		won.AllowedTransitions = pq.StringArray{}
+	}
+	if lost.AllowedTransitions == nil {
+		lost.AllowedTransitions = pq.StringArray{string(open[0].Key)}
+	}
+
+	stages := append(open, *won, *lost)
+	for i := range stages {
+		stages[i].SortOrder = i + 1
+		for _, key := range stages[i].AllowedTransitions {
+			if !seen[domain.DealStage(key)] && key != string(domain.DealStageWon) && key != string(domain.DealStageLost) {
+				return nil, fmt.Errorf("%w: stage %s allows transition to unknown stage %s", ErrInvalidDealPipeline, stages[i].Key, key)
+			}
+			if domain.DealStage(key) == stages[i].Key {
+				return nil, fmt.Errorf("%w: stage %s cannot transition to itself", ErrInvalidDealPipeline, key)
+			}
+		}
+	}
+	return stages, nil
+}
@@ -4,9 +4,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"github.com/straye-as/relation-api/internal/auth"
 	"github.com/straye-as/relation-api/internal/domain"
 	"github.com/straye-as/relation-api/internal/mapper"
@@ -15,24 +17,39 @@ import (
 	"gorm.io/gorm"
 )
 
-// Stage transition rules: defines valid transitions between deal stages
-var validStageTransitions = map[domain.DealStage][]domain.DealStage{
-	domain.DealStageLead:        {domain.DealStageQualified, domain.DealStageLost},
-	domain.DealStageQualified:   {domain.DealStageProposal, domain.DealStageLead, domain.DealStageLost},
-	domain.DealStageProposal:    {domain.DealStageNegotiation, domain.DealStageQualified, domain.DealStageLost},
-	domain.DealStageNegotiation: {domain.DealStageWon, domain.DealStageProposal, domain.DealStageLost},
-	domain.DealStageWon:         {},                     // Terminal state
-	domain.DealStageLost:        {domain.DealStageLead}, // Can reopen as new lead
+// defaultDealPipeline returns the standard pipeline (lead → qualified → proposal → negotiation → won/lost).
+// It matches the default pipeline seeded for every company and is used when a company has none.
+func defaultDealPipeline(companyID domain.CompanyID) *domain.DealPipeline {
+	days := func(n int) *int { return &n }
+	stages := []domain.DealPipelineStage{
+		{Key: domain.DealStageLead, Name: "Lead", Kind: domain.DealStageKindOpen, Probability: 10, StaleAfterDays: days(14),
+			AllowedTransitions: pq.StringArray{"qualified", "lost"}},
+		{Key: domain.DealStageQualified, Name: "Kvalifisert", Kind: domain.DealStageKindOpen, Probability: 25, StaleAfterDays: days(21),
+			AllowedTransitions: pq.StringArray{"proposal", "lead", "lost"}},
+		{Key: domain.DealStageProposal, Name: "Tilbud", Kind: domain.DealStageKindOpen, Probability: 50, StaleAfterDays: days(30),
+			AllowedTransitions: pq.StringArray{"negotiation", "qualified", "lost"}},
+		{Key: domain.DealStageNegotiation, Name: "Forhandling", Kind: domain.DealStageKindOpen, Probability: 75, StaleAfterDays: days(30),
+			AllowedTransitions: pq.StringArray{"won", "proposal", "lost"}},
+		{Key: domain.DealStageWon, Name: "Vunnet", Kind: domain.DealStageKindWon, Probability: 100,
+			AllowedTransitions: pq.StringArray{}}, // Terminal state
+		{Key: domain.DealStageLost, Name: "Tapt", Kind: domain.DealStageKindLost, Probability: 0,
+			AllowedTransitions: pq.StringArray{"lead"}}, // Can reopen as new lead
+	}
+	for i := range stages {
+		stages[i].SortOrder = i + 1
+		stages[i].RequiredFields = pq.StringArray{}
+	}
+	return &domain.DealPipeline{CompanyID: companyID, Name: "Standard", IsDefault: true, Stages: stages}
 }
 
-// Default probabilities by stage
-var stageProbabilities = map[domain.DealStage]int{
-	domain.DealStageLead:        10,
-	domain.DealStageQualified:   25,
-	domain.DealStageProposal:    50,
-	domain.DealStageNegotiation: 75,
-	domain.DealStageWon:         100,
-	domain.DealStageLost:        0,
+// dealStageRequirements checks the deal fields a pipeline stage can require
+var dealStageRequirements = map[string]func(*domain.Deal) bool{
+	"value":             func(d *domain.Deal) bool { return d.Value > 0 },
+	"expectedCloseDate": func(d *domain.Deal) bool { return d.ExpectedCloseDate != nil },
+	"description":       func(d *domain.Deal) bool { return strings.TrimSpace(d.Description) != "" },
+	"source":            func(d *domain.Deal) bool { return strings.TrimSpace(d.Source) != "" },
+	"notes":             func(d *domain.Deal) bool { return strings.TrimSpace(d.Notes) != "" },
+	"offerId":           func(d *domain.Deal) bool { return d.OfferID != nil },
 }
 
 type DealService struct {
@@ -44,6 +61,7 @@ type DealService struct {
 	offerRepo        *repository.OfferRepository
 	budgetItemRepo   *repository.BudgetItemRepository
 	notificationRepo *repository.NotificationRepository
+	pipelineRepo     *repository.DealPipelineRepository
 	logger           *zap.Logger
 	db               *gorm.DB
 }
@@ -74,6 +92,55 @@ func NewDealService(
 	}
 }
 
+// SetPipelineRepository enables company-specific deal pipelines.
+// Without it all deals follow the standard pipeline.
+func (s *DealService) SetPipelineRepository(repo *repository.DealPipelineRepository) {
+	s.pipelineRepo = repo
+}
+
+// companyPipeline returns the company's default pipeline, falling back to the standard pipeline
+func (s *DealService) companyPipeline(ctx context.Context, companyID domain.CompanyID) (*domain.DealPipeline, error) {
+	if s.pipelineRepo == nil {
+		return defaultDealPipeline(companyID), nil
+	}
+	pipeline, err := s.pipelineRepo.GetDefault(ctx, companyID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return defaultDealPipeline(companyID), nil
+		}
+		return nil, fmt.Errorf("failed to get default deal pipeline: %w", err)
+	}
+	return pipeline, nil
+}
+
+// pipelineFor returns the pipeline a deal moves through
+func (s *DealService) pipelineFor(ctx context.Context, deal *domain.Deal) (*domain.DealPipeline, error) {
+	if s.pipelineRepo != nil && deal.PipelineID != nil {
+		pipeline, err := s.pipelineRepo.GetByID(ctx, *deal.PipelineID)
+		if err == nil {
+			return pipeline, nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("failed to get deal pipeline: %w", err)
+		}
+	}
+	return s.companyPipeline(ctx, deal.CompanyID)
+}
+
+// checkStageRequirements verifies that the deal has the fields required to enter a stage
+func checkStageRequirements(deal *domain.Deal, stage *domain.DealPipelineStage) error {
+	var missing []string
+	for _, field := range stage.RequiredFields {
+		if check, ok := dealStageRequirements[field]; ok && !check(deal) {
+			missing = append(missing, field)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%w: %s requires %s", ErrDealStageRequirementsNotMet, stage.Key, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
 func (s *DealService) Create(ctx context.Context, req *domain.CreateDealRequest) (*domain.DealDTO, error) {
 	// Verify customer exists
 	customer, err := s.customerRepo.GetByID(ctx, req.CustomerID)
@@ -81,15 +148,41 @@ func (s *DealService) Create(ctx context.Context, req *domain.CreateDealRequest)
 		return nil, fmt.Errorf("customer not found: %w", err)
 	}
 
+	// Resolve the pipeline: the requested one, or the company's default
+	var pipeline *domain.DealPipeline
+	if req.PipelineID != nil && s.pipelineRepo != nil {
+		pipeline, err = s.pipelineRepo.GetByID(ctx, *req.PipelineID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, ErrDealPipelineNotFound
+			}
+			return nil, fmt.Errorf("failed to get deal pipeline: %w", err)
+		}
+		if pipeline.CompanyID != req.CompanyID {
+			return nil, ErrInvalidDealPipeline
+		}
+	} else {
+		pipeline, err = s.companyPipeline(ctx, req.CompanyID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Set defaults
 	stage := req.Stage
 	if stage == "" {
-		stage = domain.DealStageLead
+		if first := pipeline.FirstStage(); first != nil {
+			stage = first.Key
+		}
+	}
+	stageDef := pipeline.Stage(stage)
+	if stageDef == nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidDealStage, stage)
 	}
 
 	probability := req.Probability
 	if probability == 0 {
-		probability = stageProbabilities[stage]
+		probability = stageDef.Probability
 	}
 
 	currency := req.Currency
@@ -124,6 +217,13 @@ func (s *DealService) Create(ctx context.Context, req *domain.CreateDealRequest)
 		Notes:             req.Notes,
 		OfferID:           req.OfferID,
 	}
+	if pipeline.ID != uuid.Nil {
+		deal.PipelineID = &pipeline.ID
+	}
+
+	if err := checkStageRequirements(deal, stageDef); err != nil {
+		return nil, err
+	}
 
 	if err := s.dealRepo.Create(ctx, deal); err != nil {
 		return nil, fmt.Errorf("failed to create deal: %w", err)
@@ -166,8 +266,11 @@ func (s *DealService) GetByID(ctx context.Context, id uuid.UUID) (*domain.DealDT
 		return nil, fmt.Errorf("failed to get deal: %w", err)
 	}
 
-	dto := mapper.ToDealDTO(deal)
-	return &dto, nil
+	dtos := []domain.DealDTO{mapper.ToDealDTO(deal)}
+	if err := s.applyDealStaleness(ctx, dtos); err != nil {
+		return nil, err
+	}
+	return &dtos[0], nil
 }
 
 func (s *DealService) Update(ctx context.Context, id uuid.UUID, req *domain.UpdateDealRequest) (*domain.DealDTO, error) {
@@ -185,17 +288,37 @@ func (s *DealService) Update(ctx context.Context, id uuid.UUID, req *domain.Upda
 
 	oldStage := deal.Stage
 
+	var stageDef *domain.DealPipelineStage
+	if req.Stage != "" {
+		pipeline, err := s.pipelineFor(ctx, deal)
+		if err != nil {
+			return nil, err
+		}
+		if stageDef = pipeline.Stage(req.Stage); stageDef == nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidDealStage, req.Stage)
+		}
+	}
+
 	// Update fields
+	enteredProbability := deal.Probability
 	deal.Title = req.Title
 	deal.Description = req.Description
 	if req.Stage != "" {
 		deal.Stage = req.Stage
 	}
+	if stageDef != nil && req.Stage != oldStage {
+		// Keep the probability entered before closing for forecast calibration
+		if stageDef.Kind == domain.DealStageKindOpen {
+			deal.ProbabilityAtClose = nil
+		} else if deal.ProbabilityAtClose == nil {
+			deal.ProbabilityAtClose = &enteredProbability
+		}
+	}
 	if req.Probability > 0 || req.Stage != "" {
 		if req.Probability > 0 {
 			deal.Probability = req.Probability
-		} else if req.Stage != "" {
-			deal.Probability = stageProbabilities[req.Stage]
+		} else if stageDef != nil {
+			deal.Probability = stageDef.Probability
 		}
 	}
 	deal.Value = req.Value
@@ -211,6 +334,12 @@ func (s *DealService) Update(ctx context.Context, id uuid.UUID, req *domain.Upda
 	deal.Notes = req.Notes
 	deal.LostReason = req.LostReason
 
+	if stageDef != nil && req.Stage != oldStage {
+		if err := checkStageRequirements(deal, stageDef); err != nil {
+			return nil, err
+		}
+	}
+
 	if err := s.dealRepo.Update(ctx, deal); err != nil {
 		return nil, fmt.Errorf("failed to update deal: %w", err)
 	}
@@ -288,6 +417,9 @@ func (s *DealService) List(ctx context.Context, page, pageSize int, filters *rep
 	for i, deal := range deals {
 		dtos[i] = mapper.ToDealDTO(&deal)
 	}
+	if err := s.applyDealStaleness(ctx, dtos); err != nil {
+		return nil, err
+	}
 
 	totalPages := int((total + int64(pageSize) - 1) / int64(pageSize))
 	return &domain.PaginatedResponse{
@@ -306,14 +438,26 @@ func (s *DealService) AdvanceStage(ctx context.Context, id uuid.UUID, req *domai
 		return nil, fmt.Errorf("failed to get deal: %w", err)
 	}
 
+	pipeline, err := s.pipelineFor(ctx, deal)
+	if err != nil {
+		return nil, err
+	}
+
 	// Validate stage transition
-	if !s.isValidTransition(deal.Stage, req.Stage) {
-		return nil, fmt.Errorf("invalid stage transition from %s to %s", deal.Stage, req.Stage)
+	target := pipeline.Stage(req.Stage)
+	if target == nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidDealStage, req.Stage)
+	}
+	if current := pipeline.Stage(deal.Stage); current == nil || !current.CanTransitionTo(req.Stage) {
+		return nil, fmt.Errorf("%w: from %s to %s", ErrInvalidDealStageTransition, deal.Stage, req.Stage)
+	}
+	if err := checkStageRequirements(deal, target); err != nil {
+		return nil, err
 	}
 
 	oldStage := deal.Stage
 	deal.Stage = req.Stage
-	deal.Probability = stageProbabilities[req.Stage]
+	deal.Probability = target.Probability
 
 	if err := s.dealRepo.Update(ctx, deal); err != nil {
 		return nil, fmt.Errorf("failed to update deal stage: %w", err)
@@ -351,9 +495,20 @@ func (s *DealService) WinDeal(ctx context.Context, id uuid.UUID, createProject b
 		return nil, nil, fmt.Errorf("failed to get deal: %w", err)
 	}
 
-	// Can only win from negotiation stage
-	if deal.Stage != domain.DealStageNegotiation {
-		return nil, nil, fmt.Errorf("deal must be in negotiation stage to be won (current: %s)", deal.Stage)
+	pipeline, err := s.pipelineFor(ctx, deal)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Can only win from a stage that allows it (negotiation in the standard pipeline)
+	current := pipeline.Stage(deal.Stage)
+	if current == nil || !current.CanTransitionTo(domain.DealStageWon) {
+		return nil, nil, fmt.Errorf("%w: deal cannot be won from stage %s", ErrInvalidDealStageTransition, deal.Stage)
+	}
+	if won := pipeline.Stage(domain.DealStageWon); won != nil {
+		if err := checkStageRequirements(deal, won); err != nil {
+			return nil, nil, err
+		}
 	}
 
 	oldStage := deal.Stage
@@ -533,7 +688,7 @@ func (s *DealService) LoseDeal(ctx context.Context, id uuid.UUID, req *domain.Lo
 	return &dto, nil
 }
 
-// ReopenDeal reopens a lost deal as a new lead
+// ReopenDeal reopens a lost deal in the first stage of its pipeline
 func (s *DealService) ReopenDeal(ctx context.Context, id uuid.UUID) (*domain.DealDTO, error) {
 	deal, err := s.dealRepo.GetByID(ctx, id)
 	if err != nil {
@@ -544,10 +699,20 @@ func (s *DealService) ReopenDeal(ctx context.Context, id uuid.UUID) (*domain.Dea
 		return nil, fmt.Errorf("only lost deals can be reopened")
 	}
 
+	pipeline, err := s.pipelineFor(ctx, deal)
+	if err != nil {
+		return nil, err
+	}
+	first := pipeline.FirstStage()
+	if first == nil {
+		return nil, ErrInvalidDealPipeline
+	}
+
 	oldStage := deal.Stage
-	deal.Stage = domain.DealStageLead
-	deal.Probability = stageProbabilities[domain.DealStageLead]
+	deal.Stage = first.Key
+	deal.Probability = first.Probability
 	deal.ActualCloseDate = nil
+	deal.ProbabilityAtClose = nil
 	deal.LostReason = ""
 	deal.LossReasonCategory = nil
 
@@ -561,7 +726,7 @@ func (s *DealService) ReopenDeal(ctx context.Context, id uuid.UUID) (*domain.Dea
 		changedByID = userCtx.UserID.String()
 		changedByName = userCtx.DisplayName
 	}
-	_ = s.historyRepo.RecordTransition(ctx, deal.ID, &oldStage, domain.DealStageLead, changedByID, changedByName, "Deal reopened")
+	_ = s.historyRepo.RecordTransition(ctx, deal.ID, &oldStage, deal.Stage, changedByID, changedByName, "Deal reopened")
 
 	// Create activity
 	if changedByName != "" {
@@ -602,7 +767,30 @@ func (s *DealService) GetPipelineOverview(ctx context.Context) (map[string][]dom
 		return nil, fmt.Errorf("failed to get pipeline overview: %w", err)
 	}
 
+	return toPipelineOverviewDTO(pipeline, nil), nil
+}
+
+// GetPipelineOverviewByPipeline returns the open deals of one pipeline grouped by stage.
+// Every open stage of the pipeline is included, also when it has no deals.
+func (s *DealService) GetPipelineOverviewByPipeline(ctx context.Context, pipelineID uuid.UUID) (map[string][]domain.DealDTO, error) {
+	dealPipeline, err := s.getPipeline(ctx, pipelineID)
+	if err != nil {
+		return nil, err
+	}
+
+	pipeline, err := s.dealRepo.GetPipelineOverviewByPipeline(ctx, pipelineID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pipeline overview: %w", err)
+	}
+
+	return toPipelineOverviewDTO(pipeline, dealPipeline.OpenStages()), nil
+}
+
+func toPipelineOverviewDTO(pipeline map[domain.DealStage][]domain.Deal, stages []domain.DealStage) map[string][]domain.DealDTO {
 	result := make(map[string][]domain.DealDTO)
+	for _, stage := range stages {
+		result[string(stage)] = []domain.DealDTO{}
+	}
 	for stage, deals := range pipeline {
 		dtos := make([]domain.DealDTO, len(deals))
 		for i, deal := range deals {
@@ -610,8 +798,7 @@ func (s *DealService) GetPipelineOverview(ctx context.Context) (map[string][]dom
 		}
 		result[string(stage)] = dtos
 	}
-
-	return result, nil
+	return result
 }
 
 // GetPipelineStats returns aggregated statistics
@@ -619,6 +806,40 @@ func (s *DealService) GetPipelineStats(ctx context.Context) (*repository.Pipelin
 	return s.dealRepo.GetPipelineStats(ctx)
 }
 
+// GetPipelineStatsByPipeline returns aggregated statistics for one pipeline, including its empty open stages
+func (s *DealService) GetPipelineStatsByPipeline(ctx context.Context, pipelineID uuid.UUID) (*repository.PipelineStats, error) {
+	pipeline, err := s.getPipeline(ctx, pipelineID)
+	if err != nil {
+		return nil, err
+	}
+
+	stats, err := s.dealRepo.GetPipelineStatsByPipeline(ctx, pipelineID)
+	if err != nil {
+		return nil, err
+	}
+	for _, stage := range pipeline.OpenStages() {
+		if _, ok := stats.ByStage[stage]; !ok {
+			stats.ByStage[stage] = repository.StageStats{}
+		}
+	}
+	return stats, nil
+}
+
+// getPipeline returns a configured pipeline by ID
+func (s *DealService) getPipeline(ctx context.Context, pipelineID uuid.UUID) (*domain.DealPipeline, error) {
+	if s.pipelineRepo == nil {
+		return nil, ErrDealPipelineNotFound
+	}
+	pipeline, err := s.pipelineRepo.GetByID(ctx, pipelineID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrDealPipelineNotFound
+		}
+		return nil, fmt.Errorf("failed to get deal pipeline: %w", err)
+	}
+	return pipeline, nil
+}
+
 // GetForecast returns pipeline forecast for upcoming months
 func (s *DealService) GetForecast(ctx context.Context, months int) ([]repository.ForecastPeriod, error) {
 	if months < 1 {
@@ -645,21 +866,6 @@ func (s *DealService) GetActivities(ctx context.Context, id uuid.UUID, limit int
 	return dtos, nil
 }
 
-// isValidTransition checks if a stage transition is allowed
-func (s *DealService) isValidTransition(from, to domain.DealStage) bool {
-	validNextStages, ok := validStageTransitions[from]
-	if !ok {
-		return false
-	}
-
-	for _, validStage := range validNextStages {
-		if validStage == to {
-			return true
-		}
-	}
-	return false
-}
-
 // GetPipelineAnalytics returns comprehensive pipeline analytics with forecasts and conversion rates
 func (s *DealService) GetPipelineAnalytics(ctx context.Context, filters *domain.PipelineAnalyticsFilters) (*domain.PipelineAnalyticsDTO, error) {
 	analytics := &domain.PipelineAnalyticsDTO{
@@ -670,11 +876,13 @@ func (s *DealService) GetPipelineAnalytics(ctx context.Context, filters *domain.
 
 	// Extract filter values
 	var companyID *domain.CompanyID
+	var pipelineID *uuid.UUID
 	var ownerID *string
 	var dateFrom, dateTo *time.Time
 
 	if filters != nil {
 		companyID = filters.CompanyID
+		pipelineID = filters.PipelineID
 		ownerID = filters.OwnerID
 		dateFrom = filters.DateFrom
 		dateTo = filters.DateTo
@@ -725,8 +933,8 @@ func (s *DealService) GetPipelineAnalytics(ctx context.Context, filters *domain.
 		}
 	}
 
-	// Get conversion rates
-	conversionRates, err := s.dealRepo.GetConversionRates(ctx, companyID)
+	// Get conversion rates along the pipeline's stages
+	conversionRates, err := s.pipelineConversionRates(ctx, companyID, pipelineID)
 	if err != nil {
 		s.logger.Warn("failed to get conversion rates", zap.Error(err))
 	} else {
@@ -762,6 +970,31 @@ func (s *DealService) GetPipelineAnalytics(ctx context.Context, filters *domain.
 	return analytics, nil
 }
 
+// pipelineConversionRates calculates conversion rates between consecutive stages of a pipeline: the
+// requested pipeline, the default pipeline of the filtered or current company, or the standard pipeline.
+func (s *DealService) pipelineConversionRates(ctx context.Context, companyID *domain.CompanyID, pipelineID *uuid.UUID) ([]repository.ConversionRateResult, error) {
+	var pipeline *domain.DealPipeline
+	var err error
+	switch {
+	case pipelineID != nil:
+		pipeline, err = s.getPipeline(ctx, *pipelineID)
+	case companyID != nil:
+		pipeline, err = s.companyPipeline(ctx, *companyID)
+	default:
+		company := domain.CompanyGruppen
+		if userCtx, ok := auth.FromContext(ctx); ok && userCtx.CompanyID != "" {
+			company = userCtx.CompanyID
+		}
+		pipeline, err = s.companyPipeline(ctx, company)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	stages := append(pipeline.OpenStages(), domain.DealStageWon)
+	return s.dealRepo.GetConversionRates(ctx, companyID, pipelineID, stages)
+}
+
 // CreateOfferFromDeal creates an offer linked to a deal, advancing the deal to proposal stage
 func (s *DealService) CreateOfferFromDeal(ctx context.Context, dealID uuid.UUID, req *domain.CreateOfferFromDealRequest) (*domain.CreateOfferFromDealResponse, error) {
 	// Get deal
@@ -773,11 +1006,25 @@ func (s *DealService) CreateOfferFromDeal(ctx context.Context, dealID uuid.UUID,
 		return nil, fmt.Errorf("failed to get deal: %w", err)
 	}
 
-	// Validate deal stage - only lead or qualified can create an offer
-	if deal.Stage != domain.DealStageLead && deal.Stage != domain.DealStageQualified {
+	pipeline, err := s.pipelineFor(ctx, deal)
+	if err != nil {
+		return nil, err
+	}
+
+	// Validate deal stage - only open stages before proposal can create an offer
+	current := pipeline.Stage(deal.Stage)
+	proposal := pipeline.Stage(domain.DealStageProposal)
+	if current == nil || current.Kind != domain.DealStageKindOpen ||
+		(proposal != nil && current.SortOrder >= proposal.SortOrder) {
 		return nil, ErrDealInvalidStageForOffer
 	}
 
+	// Pipelines without a proposal stage keep the deal in its current stage
+	targetStage := *current
+	if proposal != nil {
+		targetStage = *proposal
+	}
+
 	// Ensure deal doesn't already have a linked offer
 	if deal.OfferID != nil {
 		return nil, ErrDealAlreadyHasOffer
@@ -816,7 +1063,7 @@ func (s *DealService) CreateOfferFromDeal(ctx context.Context, dealID uuid.UUID,
 			CustomerName:        deal.CustomerName,
 			CompanyID:           deal.CompanyID,
 			Phase:               domain.OfferPhaseDraft,
-			Probability:         stageProbabilities[domain.DealStageProposal], // 50%
+			Probability:         targetStage.Probability, // 50% in the standard pipeline
 			Value:               deal.Value,
 			Status:              domain.OfferStatusActive,
 			ResponsibleUserID:   responsibleUserID,
@@ -850,6 +1097,7 @@ func (s *DealService) CreateOfferFromDeal(ctx context.Context, dealID uuid.UUID,
 						PricePerItem:   item.PricePerItem,
 						Description:    item.Description,
 						DisplayOrder:   item.DisplayOrder,
+						CostCategory:   item.CostCategory,
 					}
 					if err := tx.Create(&cloned).Error; err != nil {
 						s.logger.Warn("failed to clone budget item",
@@ -871,8 +1119,8 @@ func (s *DealService) CreateOfferFromDeal(ctx context.Context, dealID uuid.UUID,
 
 		// Update deal: link to offer and advance to proposal stage
 		deal.OfferID = &createdOffer.ID
-		deal.Stage = domain.DealStageProposal
-		deal.Probability = stageProbabilities[domain.DealStageProposal]
+		deal.Stage = targetStage.Key
+		deal.Probability = targetStage.Probability
 
 		if err := tx.Save(deal).Error; err != nil {
 			return fmt.Errorf("failed to update deal: %w", err)
@@ -886,8 +1134,8 @@ func (s *DealService) CreateOfferFromDeal(ctx context.Context, dealID uuid.UUID,
 	}
 
 	// Record stage history (outside transaction for non-critical operation)
-	if creatorName != "" {
-		_ = s.historyRepo.RecordTransition(ctx, deal.ID, &oldStage, domain.DealStageProposal, ownerID, creatorName, "Tilbud opprettet fra salgsmulighet")
+	if creatorName != "" && deal.Stage != oldStage {
+		_ = s.historyRepo.RecordTransition(ctx, deal.ID, &oldStage, deal.Stage, ownerID, creatorName, "Tilbud opprettet fra salgsmulighet")
 	}
 
 	// Log activity on deal
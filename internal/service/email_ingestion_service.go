@@ -0,0 +1,452 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/straye-as/relation-api/internal/auth"
+	"github.com/straye-as/relation-api/internal/domain"
+	"github.com/straye-as/relation-api/internal/eml"
+	"github.com/straye-as/relation-api/internal/mapper"
+	"github.com/straye-as/relation-api/internal/repository"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// MaxEmailSizeMB is the maximum size of an ingested email including attachments
+const MaxEmailSizeMB = 25
+
+var (
+	// ErrInvalidEmailMessage is returned when an uploaded file is not an email message
+	ErrInvalidEmailMessage = eml.ErrInvalidMessage
+
+	// ErrTooManyEmailAttachments is returned when an email has more attachments than are stored
+	ErrTooManyEmailAttachments = eml.ErrTooManyAttachments
+)
+
+// offerNumberPattern finds offer numbers such as TK-2025-001 in email subjects
+var offerNumberPattern = regexp.MustCompile(`\b[A-Za-z]{2,4}-\d{4}-\d{3,}\b`)
+
+// EmailIngestionService files emails from .eml uploads and the drop mailbox as email activities on
+// the offer named in the subject, or the customer or supplier of a known contact address
+type EmailIngestionService struct {
+	emailRepo    *repository.IngestedEmailRepository
+	offerRepo    *repository.OfferRepository
+	contactRepo  *repository.ContactRepository
+	customerRepo *repository.CustomerRepository
+	supplierRepo *repository.SupplierRepository
+	activityRepo *repository.ActivityRepository
+	fileService  *FileService
+	logger       *zap.Logger
+}
+
+// NewEmailIngestionService creates a new email ingestion service instance
+func NewEmailIngestionService(
+	emailRepo *repository.IngestedEmailRepository,
+	offerRepo *repository.OfferRepository,
+	contactRepo *repository.ContactRepository,
+	customerRepo *repository.CustomerRepository,
+	supplierRepo *repository.SupplierRepository,
+	activityRepo *repository.ActivityRepository,
+	fileService *FileService,
+	logger *zap.Logger,
+) *EmailIngestionService {
+	return &EmailIngestionService{
+		emailRepo:    emailRepo,
+		offerRepo:    offerRepo,
+		contactRepo:  contactRepo,
+		customerRepo: customerRepo,
+		supplierRepo: supplierRepo,
+		activityRepo: activityRepo,
+		fileService:  fileService,
+		logger:       logger,
+	}
+}
+
+// emailTarget is the entity an email is filed on
+type emailTarget struct {
+	targetType domain.ActivityTargetType
+	id         uuid.UUID
+	name       string
+	companyID  *domain.CompanyID
+	matchedBy  domain.EmailMatchMethod
+}
+
+// Ingest parses an email and files it as a completed email activity on the matching offer, customer
+// or supplier, storing its attachments as files on the same entity. Emails that match nothing are
+// recorded as unmatched for review; emails ingested before are reported as duplicates.
+func (s *EmailIngestionService) Ingest(ctx context.Context, data []byte, source domain.EmailSource) (*domain.EmailIngestionResultDTO, error) {
+	msg, err := eml.Parse(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	// Messages without a Message-ID are recognised by their content
+	messageID := msg.MessageID
+	if messageID == "" {
+		sum := sha256.Sum256(data)
+		messageID = "sha256:" + hex.EncodeToString(sum[:])
+	}
+
+	existing, err := s.emailRepo.GetByMessageID(ctx, messageID)
+	if err == nil {
+		dto := mapper.ToIngestedEmailDTO(existing)
+		dto.Status = domain.EmailIngestionDuplicate
+		return &domain.EmailIngestionResultDTO{Email: dto}, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to check for ingested email: %w", err)
+	}
+
+	record := &domain.IngestedEmail{
+		MessageID: messageID,
+		Subject:   truncateRunes(msg.Subject, 500),
+		Source:    source,
+		Status:    domain.EmailIngestionUnmatched,
+	}
+	if msg.From != nil {
+		record.FromAddress = truncateRunes(msg.From.Email, 255)
+	}
+	if !msg.Date.IsZero() {
+		sentAt := msg.Date
+		record.SentAt = &sentAt
+	}
+	if userCtx, ok := auth.FromContext(ctx); ok {
+		record.IngestedByID = userCtx.UserID.String()
+		record.IngestedByName = userCtx.DisplayName
+	}
+
+	target, err := s.matchTarget(ctx, msg)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []domain.FileDTO
+	if target != nil {
+		companyID := s.targetCompany(ctx, target)
+		activity := s.emailActivity(ctx, msg, target, companyID)
+		if err := s.activityRepo.Create(ctx, activity); err != nil {
+			return nil, fmt.Errorf("failed to create email activity: %w", err)
+		}
+
+		files = s.storeAttachments(ctx, msg, target, companyID)
+
+		record.Status = domain.EmailIngestionMatched
+		record.MatchedBy = target.matchedBy
+		record.TargetType = target.targetType
+		record.TargetID = &target.id
+		record.TargetName = truncateRunes(target.name, 255)
+		record.ActivityID = &activity.ID
+		record.AttachmentCount = len(files)
+		record.CompanyID = &companyID
+	}
+
+	if err := s.emailRepo.Create(ctx, record); err != nil {
+		return nil, fmt.Errorf("failed to record ingested email: %w", err)
+	}
+
+	s.logger.Info("email ingested",
+		zap.String("message_id", messageID),
+		zap.String("source", string(source)),
+		zap.String("status", string(record.Status)),
+		zap.String("matched_by", string(record.MatchedBy)),
+		zap.Int("attachments", len(files)),
+	)
+
+	return &domain.EmailIngestionResultDTO{
+		Email: mapper.ToIngestedEmailDTO(record),
+		Files: files,
+	}, nil
+}
+
+// List returns ingested emails, newest first, optionally filtered by status
+func (s *EmailIngestionService) List(ctx context.Context, status *domain.EmailIngestionStatus, page, pageSize int) (*domain.PaginatedResponse, error) {
+	if pageSize < 1 {
+		pageSize = 20
+	}
+	if pageSize > 200 {
+		pageSize = 200
+	}
+	if page < 1 {
+		page = 1
+	}
+
+	emails, total, err := s.emailRepo.List(ctx, status, page, pageSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ingested emails: %w", err)
+	}
+
+	dtos := make([]domain.IngestedEmailDTO, len(emails))
+	for i := range emails {
+		dtos[i] = mapper.ToIngestedEmailDTO(&emails[i])
+	}
+
+	totalPages := int((total + int64(pageSize) - 1) / int64(pageSize))
+	return &domain.PaginatedResponse{
+		Data:       dtos,
+		Total:      total,
+		Page:       page,
+		PageSize:   pageSize,
+		TotalPages: totalPages,
+	}, nil
+}
+
+// IngestMaildir ingests the emails in the new/ folder of a maildir and moves them to cur/, marked
+// seen when ingested and trashed when they could not be parsed. Returns the number of emails
+// ingested and how many of them matched.
+func (s *EmailIngestionService) IngestMaildir(ctx context.Context, dir string) (ingested int, matched int, err error) {
+	newDir := filepath.Join(dir, "new")
+	curDir := filepath.Join(dir, "cur")
+	entries, err := os.ReadDir(newDir)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to read maildir: %w", err)
+	}
+	if err := os.MkdirAll(curDir, 0o750); err != nil {
+		return 0, 0, fmt.Errorf("failed to create maildir cur folder: %w", err)
+	}
+
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry.Type().IsRegular() {
+			names = append(names, entry.Name())
+		}
+	}
+	// Maildir names start with the delivery time, so this ingests the oldest first
+	sort.Strings(names)
+
+	for _, name := range names {
+		if err := ctx.Err(); err != nil {
+			return ingested, matched, err
+		}
+
+		path := filepath.Join(newDir, name)
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		flag := "S"
+		if info.Size() > MaxEmailSizeMB*1024*1024 {
+			s.logger.Warn("email in drop mailbox is too large, skipping", zap.String("file", name))
+			flag = "T"
+		} else {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return ingested, matched, fmt.Errorf("failed to read email %s: %w", name, err)
+			}
+			result, err := s.Ingest(ctx, data, domain.EmailSourceMailbox)
+			switch {
+			case errors.Is(err, ErrInvalidEmailMessage), errors.Is(err, ErrTooManyEmailAttachments):
+				s.logger.Warn("invalid email in drop mailbox", zap.String("file", name), zap.Error(err))
+				flag = "T"
+			case err != nil:
+				// Leave the email in new/ so the next run retries it
+				return ingested, matched, err
+			default:
+				ingested++
+				if result.Email.Status == domain.EmailIngestionMatched {
+					matched++
+				}
+			}
+		}
+
+		// Maildir info suffix: S is seen, T is trashed
+		if err := os.Rename(path, filepath.Join(curDir, strings.SplitN(name, ":", 2)[0]+":2,"+flag)); err != nil {
+			return ingested, matched, fmt.Errorf("failed to move email %s: %w", name, err)
+		}
+	}
+	return ingested, matched, nil
+}
+
+// matchTarget finds the entity an email belongs to: an offer numbered in the subject, then the
+// primary customer of a contact, then the supplier of a supplier contact, trying the sender before
+// the recipients
+func (s *EmailIngestionService) matchTarget(ctx context.Context, msg *eml.Message) (*emailTarget, error) {
+	for _, number := range offerNumberPattern.FindAllString(msg.Subject, -1) {
+		offer, err := s.offerRepo.GetByOfferNumber(ctx, number)
+		if err == nil {
+			companyID := offer.CompanyID
+			return &emailTarget{
+				targetType: domain.ActivityTargetOffer,
+				id:         offer.ID,
+				name:       offer.Title,
+				companyID:  &companyID,
+				matchedBy:  domain.EmailMatchOfferNumber,
+			}, nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("failed to get offer by number: %w", err)
+		}
+	}
+
+	addresses := msg.Addresses()
+	for _, address := range addresses {
+		contact, err := s.contactRepo.GetByEmail(ctx, address.Email)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to get contact by email: %w", err)
+		}
+		if contact.PrimaryCustomerID == nil {
+			continue
+		}
+		customer, err := s.customerRepo.GetByID(ctx, *contact.PrimaryCustomerID)
+		if err != nil {
+			continue
+		}
+		return &emailTarget{
+			targetType: domain.ActivityTargetCustomer,
+			id:         customer.ID,
+			name:       customer.Name,
+			companyID:  customer.CompanyID,
+			matchedBy:  domain.EmailMatchContact,
+		}, nil
+	}
+
+	for _, address := range addresses {
+		contact, err := s.supplierRepo.GetContactByEmail(ctx, address.Email)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to get supplier contact by email: %w", err)
+		}
+		supplier, err := s.supplierRepo.GetByID(ctx, contact.SupplierID)
+		if err != nil {
+			continue
+		}
+		return &emailTarget{
+			targetType: domain.ActivityTargetSupplier,
+			id:         supplier.ID,
+			name:       supplier.Name,
+			companyID:  supplier.CompanyID,
+			matchedBy:  domain.EmailMatchSupplierContact,
+		}, nil
+	}
+	return nil, nil
+}
+
+// targetCompany returns the company the activity and files are stored under: the target's company,
+// else the uploading user's company, else Gruppen
+func (s *EmailIngestionService) targetCompany(ctx context.Context, target *emailTarget) domain.CompanyID {
+	if target.companyID != nil && *target.companyID != "" {
+		return *target.companyID
+	}
+	if userCtx, ok := auth.FromContext(ctx); ok && userCtx.CompanyID != "" {
+		return userCtx.CompanyID
+	}
+	return domain.CompanyGruppen
+}
+
+// emailActivity builds the completed email activity for a message
+func (s *EmailIngestionService) emailActivity(ctx context.Context, msg *eml.Message, target *emailTarget, companyID domain.CompanyID) *domain.Activity {
+	occurredAt := time.Now()
+	if !msg.Date.IsZero() {
+		occurredAt = msg.Date
+	}
+
+	title := msg.Subject
+	if title == "" {
+		title = "(uten emne)"
+	}
+
+	var header []string
+	if msg.From != nil {
+		header = append(header, "Fra: "+msg.From.String())
+	}
+	if len(msg.To) > 0 {
+		header = append(header, "Til: "+joinAddresses(msg.To))
+	}
+	if len(msg.Cc) > 0 {
+		header = append(header, "Kopi: "+joinAddresses(msg.Cc))
+	}
+	body := strings.Join(header, "\n")
+	if msg.Text != "" {
+		body += "\n\n" + msg.Text
+	}
+
+	activity := &domain.Activity{
+		TargetType:   target.targetType,
+		TargetID:     target.id,
+		TargetName:   target.name,
+		Title:        truncateRunes(title, 200),
+		Body:         truncateRunes(body, 2000),
+		OccurredAt:   occurredAt,
+		ActivityType: domain.ActivityTypeEmail,
+		Status:       domain.ActivityStatusCompleted,
+		CompletedAt:  &occurredAt,
+		CompanyID:    &companyID,
+	}
+	if userCtx, ok := auth.FromContext(ctx); ok {
+		activity.CreatorID = userCtx.UserID.String()
+		activity.CreatorName = userCtx.DisplayName
+	} else if msg.From != nil {
+		activity.CreatorName = truncateRunes(msg.From.String(), 200)
+	}
+	return activity
+}
+
+// storeAttachments stores the attachments of a message as files on the target. Attachments that
+// fail to upload are logged and skipped, so the email itself is still filed.
+func (s *EmailIngestionService) storeAttachments(ctx context.Context, msg *eml.Message, target *emailTarget, companyID domain.CompanyID) []domain.FileDTO {
+	var files []domain.FileDTO
+	for _, attachment := range msg.Attachments {
+		contentType := attachment.ContentType
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		data := bytes.NewReader(attachment.Data)
+
+		var file *domain.FileDTO
+		var err error
+		switch target.targetType {
+		case domain.ActivityTargetOffer:
+			file, err = s.fileService.UploadToOffer(ctx, target.id, attachment.Filename, contentType, data, companyID)
+		case domain.ActivityTargetCustomer:
+			file, err = s.fileService.UploadToCustomer(ctx, target.id, attachment.Filename, contentType, data, companyID)
+		case domain.ActivityTargetSupplier:
+			file, err = s.fileService.UploadToSupplier(ctx, target.id, attachment.Filename, contentType, data, companyID)
+		}
+		if err != nil {
+			s.logger.Warn("failed to store email attachment",
+				zap.String("filename", attachment.Filename),
+				zap.Error(err),
+			)
+			continue
+		}
+		if file != nil {
+			files = append(files, *file)
+		}
+	}
+	return files
+}
+
+// joinAddresses formats a list of addresses for an activity body
+func joinAddresses(addresses []eml.Address) string {
+	formatted := make([]string, len(addresses))
+	for i, address := range addresses {
+		formatted[i] = address.String()
+	}
+	return strings.Join(formatted, ", ")
+}
+
+// truncateRunes shortens a string to at most limit characters
+func truncateRunes(value string, limit int) string {
+	if utf8.RuneCountInString(value) <= limit {
+		return value
+	}
+	runes := []rune(value)
+	return string(runes[:limit-1]) + "…"
+}
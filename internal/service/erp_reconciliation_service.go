@@ -0,0 +1,953 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/straye-as/relation-api/internal/auth"
+	"github.com/straye-as/relation-api/internal/domain"
+	"github.com/straye-as/relation-api/internal/importer"
+	"github.com/straye-as/relation-api/internal/repository"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// ERP reconciliation service errors
+var (
+	// ErrERPReconciliationRunning is returned when a run is started while another run is in progress
+	ErrERPReconciliationRunning = errors.New("ERP reconciliation is already running")
+
+	// ErrERPReconciliationRunNotFound is returned when a reconciliation run is not found
+	ErrERPReconciliationRunNotFound = errors.New("ERP reconciliation run not found")
+
+	// ErrERPReconciliationDecisionNotFound is returned when a reconciliation decision is not found
+	ErrERPReconciliationDecisionNotFound = errors.New("ERP reconciliation decision not found")
+
+	// ErrERPReconciliationDecisionResolved is returned when approving or rejecting a decision that is not pending
+	ErrERPReconciliationDecisionResolved = errors.New("ERP reconciliation decision is already resolved")
+
+	// ErrERPCustomerMissingName is returned when creating a customer from an ERP record without a name
+	ErrERPCustomerMissingName = errors.New("ERP customer has no name")
+
+	// ErrInvalidERPReconciliationPolicy is returned for unknown fields or policies
+	ErrInvalidERPReconciliationPolicy = errors.New("invalid ERP reconciliation policy: field must be name, orgNumber, address, postalCode or city and policy must be erp_wins, crm_wins or review")
+)
+
+// defaultERPReconciliationPolicies are used for fields without a stored policy.
+// The ERP is the source of truth for postal addresses; names and org numbers are reviewed.
+var defaultERPReconciliationPolicies = map[string]domain.ERPReconciliationPolicy{
+	domain.ERPReconciliationFieldName:       domain.ERPReconciliationPolicyReview,
+	domain.ERPReconciliationFieldOrgNumber:  domain.ERPReconciliationPolicyReview,
+	domain.ERPReconciliationFieldAddress:    domain.ERPReconciliationPolicyERPWins,
+	domain.ERPReconciliationFieldPostalCode: domain.ERPReconciliationPolicyERPWins,
+	domain.ERPReconciliationFieldCity:       domain.ERPReconciliationPolicyERPWins,
+}
+
+// erpFieldChange is a drifted field where the ERP value should be written to the customer
+type erpFieldChange struct {
+	field    string
+	crmValue string
+	erpValue string
+}
+
+// erpReconciliationState holds the lookups used during a single run
+type erpReconciliationState struct {
+	run      *domain.ERPReconciliationRun
+	policies map[string]domain.ERPReconciliationPolicy
+	pending  map[string]*domain.ERPReconciliationDecision
+	rejected map[string]bool // key + ERP value of rejected decisions
+	seen     map[string]bool // keys of differences found in this run
+}
+
+// ERPReconciliationService reconciles ERP customers (dbo.Kunde) with CRM customers.
+// Customers missing in the CRM are created or queued for approval, and drift in
+// name, org number and postal address is resolved with per-field policies.
+type ERPReconciliationService struct {
+	reconciliationRepo *repository.ERPReconciliationRepository
+	customerRepo       *repository.CustomerRepository
+	activityRepo       *repository.ActivityRepository
+	customerService    *CustomerService
+	dwClient           DataWarehouseClient
+	autoCreate         bool
+	logger             *zap.Logger
+	// runMu prevents overlapping runs (scheduled and manual)
+	runMu sync.Mutex
+}
+
+// NewERPReconciliationService creates a new ERPReconciliationService.
+// With autoCreate, customers missing in the CRM are created without approval.
+func NewERPReconciliationService(
+	reconciliationRepo *repository.ERPReconciliationRepository,
+	customerRepo *repository.CustomerRepository,
+	activityRepo *repository.ActivityRepository,
+	customerService *CustomerService,
+	autoCreate bool,
+	logger *zap.Logger,
+) *ERPReconciliationService {
+	return &ERPReconciliationService{
+		reconciliationRepo: reconciliationRepo,
+		customerRepo:       customerRepo,
+		activityRepo:       activityRepo,
+		customerService:    customerService,
+		autoCreate:         autoCreate,
+		logger:             logger,
+	}
+}
+
+// SetDataWarehouseClient sets the data warehouse client used to read ERP customers.
+// This is called after construction because the data warehouse is optional.
+func (s *ERPReconciliationService) SetDataWarehouseClient(client DataWarehouseClient) {
+	s.dwClient = client
+}
+
+// GetOverview returns the last run, pending decision counts and the field policies
+func (s *ERPReconciliationService) GetOverview(ctx context.Context) (*domain.ERPReconciliationOverviewDTO, error) {
+	overview := &domain.ERPReconciliationOverviewDTO{
+		DataWarehouseEnabled: s.dwClient != nil && s.dwClient.IsEnabled(),
+		AutoCreate:           s.autoCreate,
+	}
+
+	lastRun, err := s.reconciliationRepo.GetLatestRun(ctx)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to get latest reconciliation run: %w", err)
+	}
+	if lastRun != nil {
+		dto := toERPReconciliationRunDTO(lastRun)
+		overview.LastRun = &dto
+	}
+
+	counts, err := s.reconciliationRepo.CountPendingByType(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count pending decisions: %w", err)
+	}
+	overview.PendingCreates = counts[domain.ERPReconciliationDecisionCreateCustomer]
+	overview.PendingDrifts = counts[domain.ERPReconciliationDecisionFieldDrift]
+
+	overview.Policies, err = s.ListPolicies(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return overview, nil
+}
+
+// Run reconciles ERP customers with CRM customers and records the run.
+// A failed run is stored with its error and returned together with the error.
+func (s *ERPReconciliationService) Run(ctx context.Context, trigger domain.ERPReconciliationTrigger) (*domain.ERPReconciliationRunDTO, error) {
+	if s.dwClient == nil || !s.dwClient.IsEnabled() {
+		return nil, ErrDataWarehouseNotAvailable
+	}
+	if !s.runMu.TryLock() {
+		return nil, ErrERPReconciliationRunning
+	}
+	defer s.runMu.Unlock()
+
+	run := &domain.ERPReconciliationRun{
+		Trigger:   trigger,
+		Status:    domain.ERPReconciliationRunStatusRunning,
+		StartedAt: time.Now(),
+	}
+	if userCtx, ok := auth.FromContext(ctx); ok {
+		run.CreatedByID = userCtx.UserID.String()
+		run.CreatedByName = userCtx.DisplayName
+	}
+	if err := s.reconciliationRepo.CreateRun(ctx, run); err != nil {
+		return nil, fmt.Errorf("failed to create reconciliation run: %w", err)
+	}
+
+	runErr := s.reconcile(ctx, run)
+
+	finishedAt := time.Now()
+	run.FinishedAt = &finishedAt
+	run.Status = domain.ERPReconciliationRunStatusCompleted
+	if runErr != nil {
+		run.Status = domain.ERPReconciliationRunStatusFailed
+		run.ErrorMessage = runErr.Error()
+	}
+	// Use a fresh context so a timed out run is still recorded as failed
+	saveCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+	defer cancel()
+	if err := s.reconciliationRepo.UpdateRun(saveCtx, run); err != nil {
+		s.logger.Error("failed to save reconciliation run", zap.Error(err), zap.String("run_id", run.ID.String()))
+	}
+
+	s.logger.Info("ERP customer reconciliation completed",
+		zap.String("run_id", run.ID.String()),
+		zap.String("status", string(run.Status)),
+		zap.Int("erp_customers", run.ERPCustomerCount),
+		zap.Int("matched", run.MatchedCount),
+		zap.Int("customers_created", run.CustomersCreated),
+		zap.Int("creates_queued", run.CreatesQueued),
+		zap.Int("fields_updated", run.FieldsUpdated),
+		zap.Int("drifts_queued", run.DriftsQueued),
+		zap.Int("drifts_kept", run.DriftsKept),
+		zap.Int("errors", run.Errors),
+		zap.Duration("duration", finishedAt.Sub(run.StartedAt)))
+
+	dto := toERPReconciliationRunDTO(run)
+	if runErr != nil {
+		return &dto, runErr
+	}
+	return &dto, nil
+}
+
+// RunScheduledReconciliation runs a reconciliation from the scheduler.
+// Returns the number of customers created, fields updated and decisions queued.
+func (s *ERPReconciliationService) RunScheduledReconciliation(ctx context.Context) (created int, updated int, queued int, err error) {
+	run, err := s.Run(ctx, domain.ERPReconciliationTriggerScheduled)
+	if run == nil {
+		return 0, 0, 0, err
+	}
+	return run.CustomersCreated, run.FieldsUpdated, run.CreatesQueued + run.DriftsQueued, err
+}
+
+// reconcile compares every ERP customer with the CRM and applies or queues the differences
+func (s *ERPReconciliationService) reconcile(ctx context.Context, run *domain.ERPReconciliationRun) error {
+	erpCustomers, err := s.dwClient.GetERPCustomers(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get ERP customers: %w", err)
+	}
+	erpCustomers = dedupeERPCustomers(erpCustomers)
+	run.ERPCustomerCount = len(erpCustomers)
+
+	localCustomers, err := s.customerRepo.ListForReconciliation(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get local customers: %w", err)
+	}
+	run.LocalCustomerCount = len(localCustomers)
+
+	localByOrgNumber := make(map[string]*domain.Customer)
+	localByName := make(map[string]*domain.Customer)
+	for i := range localCustomers {
+		customer := &localCustomers[i]
+		if orgNumber := normalizeOrgNumber(customer.OrgNumber); orgNumber != "" {
+			localByOrgNumber[orgNumber] = customer
+		}
+		if name := normalizeReconciliationText(customer.Name); name != "" {
+			if _, exists := localByName[name]; !exists {
+				localByName[name] = customer
+			}
+		}
+	}
+
+	state, err := s.loadState(ctx, run)
+	if err != nil {
+		return err
+	}
+
+	for i := range erpCustomers {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		erpCustomer := &erpCustomers[i]
+
+		// Match by org number first, then by name (which may reveal org number drift)
+		local := localByOrgNumber[normalizeOrgNumber(erpCustomer.OrganizationNumber)]
+		if local == nil {
+			local = localByName[normalizeReconciliationText(erpCustomer.Name)]
+		}
+
+		if local == nil {
+			s.reconcileMissing(ctx, state, erpCustomer)
+			continue
+		}
+		run.MatchedCount++
+		s.reconcileDrift(ctx, state, erpCustomer, local)
+	}
+
+	// Pending decisions for differences that are gone are no longer relevant
+	now := time.Now()
+	for key, decision := range state.pending {
+		if state.seen[key] {
+			continue
+		}
+		decision.Status = domain.ERPReconciliationDecisionObsolete
+		decision.ResolvedAt = &now
+		if err := s.reconciliationRepo.UpdateDecision(ctx, decision); err != nil {
+			s.logger.Warn("failed to mark reconciliation decision obsolete", zap.Error(err), zap.String("decision_id", decision.ID.String()))
+		}
+	}
+
+	return nil
+}
+
+// loadState loads policies, pending decisions and rejected decisions for a run
+func (s *ERPReconciliationService) loadState(ctx context.Context, run *domain.ERPReconciliationRun) (*erpReconciliationState, error) {
+	state := &erpReconciliationState{
+		run:      run,
+		pending:  make(map[string]*domain.ERPReconciliationDecision),
+		rejected: make(map[string]bool),
+		seen:     make(map[string]bool),
+	}
+
+	var err error
+	state.policies, err = s.loadPolicies(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	pending, err := s.reconciliationRepo.ListDecisionsByStatus(ctx, domain.ERPReconciliationDecisionPending)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pending decisions: %w", err)
+	}
+	for i := range pending {
+		state.pending[pending[i].Key] = &pending[i]
+	}
+
+	rejected, err := s.reconciliationRepo.ListDecisionsByStatus(ctx, domain.ERPReconciliationDecisionRejected)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list rejected decisions: %w", err)
+	}
+	for _, decision := range rejected {
+		state.rejected[decision.Key+"\x00"+decision.ERPValue] = true
+	}
+
+	return state, nil
+}
+
+// reconcileMissing creates or queues an ERP customer that does not exist in the CRM
+func (s *ERPReconciliationService) reconcileMissing(ctx context.Context, state *erpReconciliationState, erpCustomer *DataWarehouseERPCustomer) {
+	key := erpCreateKey(erpCustomer)
+	if key == "" {
+		return
+	}
+	state.seen[key] = true
+	if state.rejected[key+"\x00"] {
+		return
+	}
+
+	decision := newERPDecision(state, key, domain.ERPReconciliationDecisionCreateCustomer, erpCustomer)
+
+	if s.autoCreate {
+		customer, err := s.createFromERP(ctx, erpCustomer)
+		if err == nil {
+			now := time.Now()
+			decision.Status = domain.ERPReconciliationDecisionApplied
+			decision.CustomerID = &customer.ID
+			decision.CustomerName = customer.Name
+			decision.ResolvedAt = &now
+			state.run.CustomersCreated++
+			s.saveDecision(ctx, state, decision)
+			return
+		}
+		// Queue the customer so the problem can be fixed and approved by hand
+		decision.Error = err.Error()
+		state.run.Errors++
+	}
+
+	state.run.CreatesQueued++
+	s.saveDecision(ctx, state, decision)
+}
+
+// reconcileDrift compares the reconciled fields of a matched customer and applies the field policies
+func (s *ERPReconciliationService) reconcileDrift(ctx context.Context, state *erpReconciliationState, erpCustomer *DataWarehouseERPCustomer, local *domain.Customer) {
+	var changes []erpFieldChange
+	for _, field := range domain.ERPReconciliationFields {
+		crmValue := customerReconciliationValue(local, field)
+		erpValue := erpReconciliationValue(erpCustomer, field)
+		if erpValue == "" || reconciliationValuesEqual(field, crmValue, erpValue) {
+			continue
+		}
+
+		key := erpDriftKey(local.ID, field)
+		state.seen[key] = true
+
+		switch state.policies[field] {
+		case domain.ERPReconciliationPolicyCRMWins:
+			state.run.DriftsKept++
+		case domain.ERPReconciliationPolicyERPWins:
+			changes = append(changes, erpFieldChange{field: field, crmValue: crmValue, erpValue: erpValue})
+		default:
+			if state.rejected[key+"\x00"+erpValue] {
+				continue
+			}
+			decision := newERPDriftDecision(state, key, erpCustomer, local, field, crmValue, erpValue)
+			state.run.DriftsQueued++
+			s.saveDecision(ctx, state, decision)
+		}
+	}
+
+	if len(changes) == 0 {
+		return
+	}
+
+	applyErr := s.applyERPValues(ctx, local.ID, changes)
+	now := time.Now()
+	for _, change := range changes {
+		decision := newERPDriftDecision(state, erpDriftKey(local.ID, change.field), erpCustomer, local, change.field, change.crmValue, change.erpValue)
+		if applyErr != nil {
+			// Queue the drift so it can be resolved by hand
+			decision.Error = applyErr.Error()
+			state.run.Errors++
+			state.run.DriftsQueued++
+		} else {
+			decision.Status = domain.ERPReconciliationDecisionApplied
+			decision.ResolvedAt = &now
+			state.run.FieldsUpdated++
+		}
+		s.saveDecision(ctx, state, decision)
+	}
+}
+
+// saveDecision stores a decision. A pending decision with the same key is refreshed
+// instead of queueing a duplicate, and is resolved when the new decision is applied.
+func (s *ERPReconciliationService) saveDecision(ctx context.Context, state *erpReconciliationState, decision *domain.ERPReconciliationDecision) {
+	existing := state.pending[decision.Key]
+
+	var err error
+	switch {
+	case existing != nil && decision.Status == domain.ERPReconciliationDecisionPending:
+		decision.ID = existing.ID
+		decision.CreatedAt = existing.CreatedAt
+		err = s.reconciliationRepo.UpdateDecision(ctx, decision)
+		state.pending[decision.Key] = decision
+	case existing != nil:
+		existing.Status = domain.ERPReconciliationDecisionObsolete
+		existing.ResolvedAt = decision.ResolvedAt
+		if err = s.reconciliationRepo.UpdateDecision(ctx, existing); err == nil {
+			delete(state.pending, decision.Key)
+			err = s.reconciliationRepo.CreateDecision(ctx, decision)
+		}
+	default:
+		err = s.reconciliationRepo.CreateDecision(ctx, decision)
+		if err == nil && decision.Status == domain.ERPReconciliationDecisionPending {
+			state.pending[decision.Key] = decision
+		}
+	}
+
+	if err != nil {
+		state.run.Errors++
+		s.logger.Warn("failed to save reconciliation decision",
+			zap.Error(err),
+			zap.String("key", decision.Key),
+			zap.String("type", string(decision.Type)))
+	}
+}
+
+// ListRuns returns a paginated list of reconciliation runs, newest first
+func (s *ERPReconciliationService) ListRuns(ctx context.Context, page, pageSize int) (*domain.PaginatedResponse, error) {
+	page, pageSize = clampERPReconciliationPage(page, pageSize)
+
+	runs, total, err := s.reconciliationRepo.ListRuns(ctx, page, pageSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list reconciliation runs: %w", err)
+	}
+
+	dtos := make([]domain.ERPReconciliationRunDTO, len(runs))
+	for i := range runs {
+		dtos[i] = toERPReconciliationRunDTO(&runs[i])
+	}
+
+	return &domain.PaginatedResponse{
+		Data:       dtos,
+		Total:      total,
+		Page:       page,
+		PageSize:   pageSize,
+		TotalPages: int((total + int64(pageSize) - 1) / int64(pageSize)),
+	}, nil
+}
+
+// GetRun returns a reconciliation run by ID
+func (s *ERPReconciliationService) GetRun(ctx context.Context, id uuid.UUID) (*domain.ERPReconciliationRunDTO, error) {
+	run, err := s.reconciliationRepo.GetRunByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrERPReconciliationRunNotFound
+		}
+		return nil, fmt.Errorf("failed to get reconciliation run: %w", err)
+	}
+
+	dto := toERPReconciliationRunDTO(run)
+	return &dto, nil
+}
+
+// ListDecisions returns a paginated list of reconciliation decisions, newest first
+func (s *ERPReconciliationService) ListDecisions(ctx context.Context, filters *repository.ERPReconciliationDecisionFilters, page, pageSize int) (*domain.PaginatedResponse, error) {
+	page, pageSize = clampERPReconciliationPage(page, pageSize)
+
+	decisions, total, err := s.reconciliationRepo.ListDecisions(ctx, filters, page, pageSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list reconciliation decisions: %w", err)
+	}
+
+	dtos := make([]domain.ERPReconciliationDecisionDTO, len(decisions))
+	for i := range decisions {
+		dtos[i] = toERPReconciliationDecisionDTO(&decisions[i])
+	}
+
+	return &domain.PaginatedResponse{
+		Data:       dtos,
+		Total:      total,
+		Page:       page,
+		PageSize:   pageSize,
+		TotalPages: int((total + int64(pageSize) - 1) / int64(pageSize)),
+	}, nil
+}
+
+// ApproveDecision applies a pending decision: the missing customer is created,
+// or the ERP value is written to the customer
+func (s *ERPReconciliationService) ApproveDecision(ctx context.Context, id uuid.UUID) (*domain.ERPReconciliationDecisionDTO, error) {
+	decision, err := s.getPendingDecision(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	switch decision.Type {
+	case domain.ERPReconciliationDecisionCreateCustomer:
+		customer, err := s.createFromERP(ctx, &DataWarehouseERPCustomer{
+			Firmanr:            decision.ERPFirmanr,
+			CustomerNumber:     decision.ERPCustomerNumber,
+			OrganizationNumber: decision.ERPOrgNumber,
+			Name:               decision.ERPName,
+			Address:            decision.ERPAddress,
+			PostalCode:         decision.ERPPostalCode,
+			City:               decision.ERPCity,
+		})
+		if err != nil {
+			return nil, err
+		}
+		decision.CustomerID = &customer.ID
+		decision.CustomerName = customer.Name
+	default:
+		if decision.CustomerID == nil {
+			return nil, ErrCustomerNotFound
+		}
+		if err := s.applyERPValues(ctx, *decision.CustomerID, []erpFieldChange{{
+			field:    decision.Field,
+			crmValue: decision.CRMValue,
+			erpValue: decision.ERPValue,
+		}}); err != nil {
+			return nil, err
+		}
+	}
+
+	decision.Error = ""
+	return s.resolveDecision(ctx, decision, domain.ERPReconciliationDecisionApproved)
+}
+
+// RejectDecision rejects a pending decision. The same difference is not queued again
+// until the ERP value changes.
+func (s *ERPReconciliationService) RejectDecision(ctx context.Context, id uuid.UUID) (*domain.ERPReconciliationDecisionDTO, error) {
+	decision, err := s.getPendingDecision(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.resolveDecision(ctx, decision, domain.ERPReconciliationDecisionRejected)
+}
+
+func (s *ERPReconciliationService) getPendingDecision(ctx context.Context, id uuid.UUID) (*domain.ERPReconciliationDecision, error) {
+	decision, err := s.reconciliationRepo.GetDecisionByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrERPReconciliationDecisionNotFound
+		}
+		return nil, fmt.Errorf("failed to get reconciliation decision: %w", err)
+	}
+	if decision.Status != domain.ERPReconciliationDecisionPending {
+		return nil, ErrERPReconciliationDecisionResolved
+	}
+	return decision, nil
+}
+
+func (s *ERPReconciliationService) resolveDecision(ctx context.Context, decision *domain.ERPReconciliationDecision, status domain.ERPReconciliationDecisionStatus) (*domain.ERPReconciliationDecisionDTO, error) {
+	now := time.Now()
+	decision.Status = status
+	decision.ResolvedAt = &now
+	if userCtx, ok := auth.FromContext(ctx); ok {
+		decision.ResolvedByID = userCtx.UserID.String()
+		decision.ResolvedByName = userCtx.DisplayName
+	}
+
+	if err := s.reconciliationRepo.UpdateDecision(ctx, decision); err != nil {
+		return nil, fmt.Errorf("failed to update reconciliation decision: %w", err)
+	}
+
+	dto := toERPReconciliationDecisionDTO(decision)
+	return &dto, nil
+}
+
+// ListPolicies returns the drift policy for every reconciled field
+func (s *ERPReconciliationService) ListPolicies(ctx context.Context) ([]domain.ERPReconciliationPolicyDTO, error) {
+	stored, err := s.reconciliationRepo.ListPolicies(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list reconciliation policies: %w", err)
+	}
+
+	byField := make(map[string]*domain.ERPReconciliationFieldPolicy, len(stored))
+	for i := range stored {
+		byField[stored[i].Field] = &stored[i]
+	}
+
+	dtos := make([]domain.ERPReconciliationPolicyDTO, 0, len(domain.ERPReconciliationFields))
+	for _, field := range domain.ERPReconciliationFields {
+		dto := domain.ERPReconciliationPolicyDTO{
+			Field:  field,
+			Policy: defaultERPReconciliationPolicies[field],
+		}
+		if policy, ok := byField[field]; ok && policy.Policy.IsValid() {
+			dto.Policy = policy.Policy
+			dto.UpdatedByName = policy.UpdatedByName
+			if policy.UpdatedByID != "" {
+				updatedAt := policy.UpdatedAt.UTC().Format(time.RFC3339)
+				dto.UpdatedAt = &updatedAt
+			}
+		}
+		dtos = append(dtos, dto)
+	}
+	return dtos, nil
+}
+
+// UpdatePolicies sets the drift policy for one or more fields.
+// New policies apply from the next run; pending decisions are kept.
+func (s *ERPReconciliationService) UpdatePolicies(ctx context.Context, req *domain.UpdateERPReconciliationPoliciesRequest) ([]domain.ERPReconciliationPolicyDTO, error) {
+	for field, policy := range req.Policies {
+		if _, ok := defaultERPReconciliationPolicies[field]; !ok || !policy.IsValid() {
+			return nil, ErrInvalidERPReconciliationPolicy
+		}
+	}
+
+	for field, policy := range req.Policies {
+		fieldPolicy := &domain.ERPReconciliationFieldPolicy{
+			Field:     field,
+			Policy:    policy,
+			UpdatedAt: time.Now(),
+		}
+		if userCtx, ok := auth.FromContext(ctx); ok {
+			fieldPolicy.UpdatedByID = userCtx.UserID.String()
+			fieldPolicy.UpdatedByName = userCtx.DisplayName
+		}
+		if err := s.reconciliationRepo.SavePolicy(ctx, fieldPolicy); err != nil {
+			return nil, fmt.Errorf("failed to save reconciliation policy: %w", err)
+		}
+	}
+
+	return s.ListPolicies(ctx)
+}
+
+// loadPolicies returns the effective policy per field
+func (s *ERPReconciliationService) loadPolicies(ctx context.Context) (map[string]domain.ERPReconciliationPolicy, error) {
+	dtos, err := s.ListPolicies(ctx)
+	if err != nil {
+		return nil, err
+	}
+	policies := make(map[string]domain.ERPReconciliationPolicy, len(dtos))
+	for _, dto := range dtos {
+		policies[dto.Field] = dto.Policy
+	}
+	return policies, nil
+}
+
+// createFromERP creates a customer from ERP data through CustomerService,
+// so org number validation, postal code derivation and activities apply
+func (s *ERPReconciliationService) createFromERP(ctx context.Context, erpCustomer *DataWarehouseERPCustomer) (*domain.CustomerDTO, error) {
+	req := &domain.CreateCustomerRequest{
+		Name:       strings.TrimSpace(erpCustomer.Name),
+		OrgNumber:  normalizeOrgNumber(erpCustomer.OrganizationNumber),
+		Address:    strings.TrimSpace(erpCustomer.Address),
+		PostalCode: importer.ParsePostalCode(erpCustomer.PostalCode),
+		City:       strings.TrimSpace(erpCustomer.City),
+		Country:    "Norway",
+		Notes:      fmt.Sprintf("Opprettet fra ERP (firmanr %d, kundenr %s)", erpCustomer.Firmanr, erpCustomer.CustomerNumber),
+	}
+	if req.Name == "" {
+		return nil, ErrERPCustomerMissingName
+	}
+	return s.customerService.Create(ctx, req)
+}
+
+// applyERPValues writes ERP values to a customer and logs an activity.
+// A changed org number must be valid and not belong to another customer.
+func (s *ERPReconciliationService) applyERPValues(ctx context.Context, customerID uuid.UUID, changes []erpFieldChange) error {
+	customer, err := s.customerRepo.GetByID(ctx, customerID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrCustomerNotFound
+		}
+		return fmt.Errorf("failed to get customer: %w", err)
+	}
+
+	fields := make([]string, 0, len(changes))
+	var postalCodeChanged bool
+	var city string
+	for _, change := range changes {
+		switch change.field {
+		case domain.ERPReconciliationFieldName:
+			customer.Name = change.erpValue
+		case domain.ERPReconciliationFieldOrgNumber:
+			orgNumber := normalizeOrgNumber(change.erpValue)
+			if err := validateOrgNumber(orgNumber); err != nil {
+				return err
+			}
+			existing, err := s.customerRepo.GetByOrgNumber(ctx, orgNumber)
+			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("failed to check org number: %w", err)
+			}
+			if existing != nil && existing.ID != customer.ID {
+				return ErrDuplicateOrgNumber
+			}
+			customer.OrgNumber = orgNumber
+		case domain.ERPReconciliationFieldAddress:
+			customer.Address = change.erpValue
+		case domain.ERPReconciliationFieldPostalCode:
+			customer.PostalCode = importer.ParsePostalCode(change.erpValue)
+			postalCodeChanged = true
+		case domain.ERPReconciliationFieldCity:
+			city = change.erpValue
+		default:
+			return fmt.Errorf("unknown reconciliation field: %s", change.field)
+		}
+		fields = append(fields, change.field)
+	}
+
+	// A changed postal code determines city, municipality and county; an explicit ERP city wins
+	if postalCodeChanged {
+		derivePostalAddress(customer.Country, customer.PostalCode, &customer.City, &customer.Municipality, &customer.County)
+	}
+	if city != "" {
+		customer.City = city
+	}
+
+	if userCtx, ok := auth.FromContext(ctx); ok {
+		customer.UpdatedByID = userCtx.UserID.String()
+		customer.UpdatedByName = userCtx.DisplayName
+	}
+
+	if err := s.customerRepo.Update(ctx, customer); err != nil {
+		if strings.Contains(err.Error(), "duplicate key") || strings.Contains(err.Error(), "unique constraint") {
+			return ErrDuplicateOrgNumber
+		}
+		return fmt.Errorf("failed to update customer: %w", err)
+	}
+
+	s.logActivity(ctx, customer, "Kunde oppdatert fra ERP",
+		fmt.Sprintf("Følgende felt ble oppdatert fra ERP: %s", strings.Join(fields, ", ")))
+	return nil
+}
+
+// logActivity creates a system activity on the customer for reconciliation changes
+func (s *ERPReconciliationService) logActivity(ctx context.Context, customer *domain.Customer, title, body string) {
+	creatorName := "System"
+	if userCtx, ok := auth.FromContext(ctx); ok && userCtx.DisplayName != "" {
+		creatorName = userCtx.DisplayName
+	}
+
+	activity := &domain.Activity{
+		TargetType:   domain.ActivityTargetCustomer,
+		TargetID:     customer.ID,
+		TargetName:   customer.Name,
+		Title:        title,
+		Body:         body,
+		ActivityType: domain.ActivityTypeSystem,
+		Status:       domain.ActivityStatusCompleted,
+		OccurredAt:   time.Now(),
+		CreatorName:  creatorName,
+		CompanyID:    customer.CompanyID,
+	}
+
+	if err := s.activityRepo.Create(ctx, activity); err != nil {
+		s.logger.Warn("failed to log reconciliation activity",
+			zap.Error(err),
+			zap.String("customer_id", customer.ID.String()))
+	}
+}
+
+// dedupeERPCustomers merges ERP customers registered in several companies.
+// Customers are identified by org number, or by name when they have none.
+// Empty address fields of the first record are filled from later records.
+func dedupeERPCustomers(customers []DataWarehouseERPCustomer) []DataWarehouseERPCustomer {
+	result := make([]DataWarehouseERPCustomer, 0, len(customers))
+	index := make(map[string]int, len(customers))
+
+	for _, customer := range customers {
+		key := erpCreateKey(&customer)
+		if key == "" {
+			continue
+		}
+		i, exists := index[key]
+		if !exists {
+			index[key] = len(result)
+			result = append(result, customer)
+			continue
+		}
+
+		merged := &result[i]
+		if merged.Address == "" && merged.PostalCode == "" && merged.City == "" {
+			merged.Address = customer.Address
+			merged.PostalCode = customer.PostalCode
+			merged.City = customer.City
+		}
+	}
+	return result
+}
+
+// erpCreateKey identifies an ERP customer across runs
+func erpCreateKey(customer *DataWarehouseERPCustomer) string {
+	if orgNumber := normalizeOrgNumber(customer.OrganizationNumber); orgNumber != "" {
+		return "create:org:" + orgNumber
+	}
+	if name := normalizeReconciliationText(customer.Name); name != "" {
+		return "create:name:" + name
+	}
+	return ""
+}
+
+// erpDriftKey identifies drift of one field of a customer across runs
+func erpDriftKey(customerID uuid.UUID, field string) string {
+	return "drift:" + customerID.String() + ":" + field
+}
+
+// newERPDecision creates a pending decision carrying the ERP customer data
+func newERPDecision(state *erpReconciliationState, key string, decisionType domain.ERPReconciliationDecisionType, erpCustomer *DataWarehouseERPCustomer) *domain.ERPReconciliationDecision {
+	return &domain.ERPReconciliationDecision{
+		RunID:             state.run.ID,
+		Type:              decisionType,
+		Status:            domain.ERPReconciliationDecisionPending,
+		Key:               key,
+		ERPFirmanr:        erpCustomer.Firmanr,
+		ERPCustomerNumber: erpCustomer.CustomerNumber,
+		ERPOrgNumber:      erpCustomer.OrganizationNumber,
+		ERPName:           erpCustomer.Name,
+		ERPAddress:        erpCustomer.Address,
+		ERPPostalCode:     erpCustomer.PostalCode,
+		ERPCity:           erpCustomer.City,
+	}
+}
+
+// newERPDriftDecision creates a pending decision for a drifted field
+func newERPDriftDecision(state *erpReconciliationState, key string, erpCustomer *DataWarehouseERPCustomer, local *domain.Customer, field, crmValue, erpValue string) *domain.ERPReconciliationDecision {
+	decision := newERPDecision(state, key, domain.ERPReconciliationDecisionFieldDrift, erpCustomer)
+	decision.CustomerID = &local.ID
+	decision.CustomerName = local.Name
+	decision.Field = field
+	decision.CRMValue = crmValue
+	decision.ERPValue = erpValue
+	return decision
+}
+
+// customerReconciliationValue returns the CRM value of a reconciled field
+func customerReconciliationValue(customer *domain.Customer, field string) string {
+	switch field {
+	case domain.ERPReconciliationFieldName:
+		return customer.Name
+	case domain.ERPReconciliationFieldOrgNumber:
+		return customer.OrgNumber
+	case domain.ERPReconciliationFieldAddress:
+		return customer.Address
+	case domain.ERPReconciliationFieldPostalCode:
+		return customer.PostalCode
+	case domain.ERPReconciliationFieldCity:
+		return customer.City
+	default:
+		return ""
+	}
+}
+
+// erpReconciliationValue returns the ERP value of a reconciled field
+func erpReconciliationValue(customer *DataWarehouseERPCustomer, field string) string {
+	switch field {
+	case domain.ERPReconciliationFieldName:
+		return strings.TrimSpace(customer.Name)
+	case domain.ERPReconciliationFieldOrgNumber:
+		return normalizeOrgNumber(customer.OrganizationNumber)
+	case domain.ERPReconciliationFieldAddress:
+		return strings.TrimSpace(customer.Address)
+	case domain.ERPReconciliationFieldPostalCode:
+		return importer.ParsePostalCode(customer.PostalCode)
+	case domain.ERPReconciliationFieldCity:
+		return strings.TrimSpace(customer.City)
+	default:
+		return ""
+	}
+}
+
+// reconciliationValuesEqual compares field values ignoring case, repeated whitespace
+// and formatting of org numbers and postal codes
+func reconciliationValuesEqual(field, crmValue, erpValue string) bool {
+	switch field {
+	case domain.ERPReconciliationFieldOrgNumber:
+		return normalizeOrgNumber(crmValue) == normalizeOrgNumber(erpValue)
+	case domain.ERPReconciliationFieldPostalCode:
+		return importer.ParsePostalCode(crmValue) == importer.ParsePostalCode(erpValue)
+	default:
+		return normalizeReconciliationText(crmValue) == normalizeReconciliationText(erpValue)
+	}
+}
+
+// normalizeReconciliationText lower-cases text and collapses whitespace for comparison
+func normalizeReconciliationText(value string) string {
+	return strings.ToLower(strings.Join(strings.Fields(value), " "))
+}
+
+// clampERPReconciliationPage applies the default and maximum page size
+func clampERPReconciliationPage(page, pageSize int) (int, int) {
+	if pageSize < 1 {
+		pageSize = 20
+	}
+	if pageSize > 200 {
+		pageSize = 200
+	}
+	if page < 1 {
+		page = 1
+	}
+	return page, pageSize
+}
+
+// toERPReconciliationRunDTO converts a reconciliation run to a DTO
+func toERPReconciliationRunDTO(run *domain.ERPReconciliationRun) domain.ERPReconciliationRunDTO {
+	dto := domain.ERPReconciliationRunDTO{
+		ID:                 run.ID,
+		Trigger:            run.Trigger,
+		Status:             run.Status,
+		StartedAt:          run.StartedAt.UTC().Format(time.RFC3339),
+		ERPCustomerCount:   run.ERPCustomerCount,
+		LocalCustomerCount: run.LocalCustomerCount,
+		MatchedCount:       run.MatchedCount,
+		CustomersCreated:   run.CustomersCreated,
+		CreatesQueued:      run.CreatesQueued,
+		FieldsUpdated:      run.FieldsUpdated,
+		DriftsQueued:       run.DriftsQueued,
+		DriftsKept:         run.DriftsKept,
+		Errors:             run.Errors,
+		ErrorMessage:       run.ErrorMessage,
+		CreatedByName:      run.CreatedByName,
+	}
+	if run.FinishedAt != nil {
+		finishedAt := run.FinishedAt.UTC().Format(time.RFC3339)
+		dto.FinishedAt = &finishedAt
+	}
+	return dto
+}
+
+// toERPReconciliationDecisionDTO converts a reconciliation decision to a DTO
+func toERPReconciliationDecisionDTO(decision *domain.ERPReconciliationDecision) domain.ERPReconciliationDecisionDTO {
+	dto := domain.ERPReconciliationDecisionDTO{
+		ID:                decision.ID,
+		RunID:             decision.RunID,
+		Type:              decision.Type,
+		Status:            decision.Status,
+		CustomerID:        decision.CustomerID,
+		CustomerName:      decision.CustomerName,
+		Field:             decision.Field,
+		CRMValue:          decision.CRMValue,
+		ERPValue:          decision.ERPValue,
+		ERPFirmanr:        decision.ERPFirmanr,
+		ERPCustomerNumber: decision.ERPCustomerNumber,
+		ERPOrgNumber:      decision.ERPOrgNumber,
+		ERPName:           decision.ERPName,
+		ERPAddress:        decision.ERPAddress,
+		ERPPostalCode:     decision.ERPPostalCode,
+		ERPCity:           decision.ERPCity,
+		Error:             decision.Error,
+		CreatedAt:         decision.CreatedAt.UTC().Format(time.RFC3339),
+		ResolvedByName:    decision.ResolvedByName,
+	}
+	if decision.ResolvedAt != nil {
+		resolvedAt := decision.ResolvedAt.UTC().Format(time.RFC3339)
+		dto.ResolvedAt = &resolvedAt
+	}
+	return dto
+}
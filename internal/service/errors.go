@@ -78,7 +78,39 @@ var (
 	ErrDealAlreadyHasOffer = errors.New("deal already has a linked offer")
 
 	// ErrDealInvalidStageForOffer is returned when deal is in invalid stage for creating an offer
-	ErrDealInvalidStageForOffer = errors.New("deal must be in lead or qualified stage to create an offer")
+	ErrDealInvalidStageForOffer = errors.New("deal must be in an open stage before proposal to create an offer")
+
+	// ErrDealPipelineNotFound is returned when a deal pipeline is not found
+	ErrDealPipelineNotFound = errors.New("deal pipeline not found")
+
+	// ErrInvalidDealPipeline is returned when a pipeline definition is invalid or belongs to another company
+	ErrInvalidDealPipeline = errors.New("invalid deal pipeline")
+
+	// ErrDealPipelineInUse is returned when deleting a pipeline that still has deals
+	ErrDealPipelineInUse = errors.New("deal pipeline has deals and cannot be deleted")
+
+	// ErrDefaultDealPipelineRequired is returned when deleting or unsetting the default pipeline of a company
+	ErrDefaultDealPipelineRequired = errors.New("the default deal pipeline cannot be deleted or unset")
+
+	// ErrInvalidDealStage is returned when a stage is not part of the deal's pipeline
+	ErrInvalidDealStage = errors.New("stage is not part of the deal's pipeline")
+
+	// ErrInvalidDealStageTransition is returned when the pipeline does not allow moving between two stages
+	ErrInvalidDealStageTransition = errors.New("stage transition is not allowed by the deal's pipeline")
+
+	// ErrDealStageRequirementsNotMet is returned when a deal lacks fields required by its stage
+	ErrDealStageRequirementsNotMet = errors.New("deal is missing fields required by the stage")
+
+	// Sales target errors
+
+	// ErrSalesTargetNotFound is returned when a sales target is not found
+	ErrSalesTargetNotFound = errors.New("sales target not found")
+
+	// ErrInvalidSalesTarget is returned when a sales target has an invalid company or period
+	ErrInvalidSalesTarget = errors.New("invalid sales target")
+
+	// ErrSalesTargetExists is returned when the company or user already has a target for the period and metric
+	ErrSalesTargetExists = errors.New("a sales target already exists for this period and metric")
 
 	// Inquiry errors
 
@@ -91,9 +123,57 @@ var (
 	// ErrInquiryMissingConversionData is returned when conversion requires data that wasn't provided
 	ErrInquiryMissingConversionData = errors.New("conversion requires responsibleUserId or companyId with default responsible user")
 
+	// ErrInquirySiteNotFound is returned when a website form does not exist or is disabled
+	ErrInquirySiteNotFound = errors.New("inquiry site not found")
+
+	// ErrInvalidInquirySite is returned when a website form has invalid settings
+	ErrInvalidInquirySite = errors.New("invalid inquiry site")
+
+	// ErrInquiryOriginNotAllowed is returned when a website inquiry is posted from an origin the site does not allow
+	ErrInquiryOriginNotAllowed = errors.New("inquiries are not accepted from this origin")
+
+	// ErrInvalidProofOfWork is returned when a website inquiry lacks a valid, unused proof-of-work solution
+	ErrInvalidProofOfWork = errors.New("invalid or expired proof-of-work solution")
+
+	// ErrTooManyInquiryAttachments is returned when a website inquiry has more attachments than are accepted
+	ErrTooManyInquiryAttachments = errors.New("too many attachments")
+
+	// ErrInquiryAssignmentRuleNotFound is returned when an inquiry assignment rule does not exist
+	ErrInquiryAssignmentRuleNotFound = errors.New("inquiry assignment rule not found")
+
+	// ErrInvalidInquiryAssignmentRule is returned when an inquiry assignment rule has invalid conditions or assignees
+	ErrInvalidInquiryAssignmentRule = errors.New("invalid inquiry assignment rule")
+
 	// ErrProjectNotFound is returned when a project is not found
 	ErrProjectNotFound = errors.New("project not found")
 
+	// ErrProjectBudgetNotEmpty is returned when initializing a project budget that already has budget items
+	ErrProjectBudgetNotEmpty = errors.New("project budget already has budget items")
+
+	// ErrProjectHasNoWonOffer is returned when a project has no offer in order or completed phase
+	ErrProjectHasNoWonOffer = errors.New("project has no won offer")
+
+	// ErrProjectTaskNotFound is returned when a task or milestone does not exist in the project
+	ErrProjectTaskNotFound = errors.New("project task not found")
+
+	// ErrInvalidProjectTask is returned when a task or milestone has invalid dates, links or assignee
+	ErrInvalidProjectTask = errors.New("invalid project task")
+
+	// ErrProjectTaskDependencyCycle is returned when dependencies would make a task wait for itself
+	ErrProjectTaskDependencyCycle = errors.New("project task dependencies form a cycle")
+
+	// ErrResourceAllocationNotFound is returned when a resource allocation is not found
+	ErrResourceAllocationNotFound = errors.New("resource allocation not found")
+
+	// ErrInvalidResourceAllocation is returned when an allocation has no offer or project, invalid dates or no size
+	ErrInvalidResourceAllocation = errors.New("invalid resource allocation")
+
+	// ErrUserAbsenceNotFound is returned when an absence is not found for the user
+	ErrUserAbsenceNotFound = errors.New("absence not found")
+
+	// ErrInvalidUserAbsence is returned when an absence has invalid dates
+	ErrInvalidUserAbsence = errors.New("invalid absence")
+
 	// ErrInvalidCompanyID is returned when an invalid company ID is provided
 	ErrInvalidCompanyID = errors.New("invalid company ID")
 
@@ -193,4 +273,7 @@ var (
 
 	// ErrInvalidOfferSupplierStatus is returned when an invalid status is provided
 	ErrInvalidOfferSupplierStatus = errors.New("invalid offer-supplier status")
+
+	// ErrInvalidDateRange is returned when a report period ends before it starts
+	ErrInvalidDateRange = errors.New("invalid date range: from must not be after to")
 )
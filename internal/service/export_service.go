@@ -0,0 +1,383 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/straye-as/relation-api/internal/domain"
+	"github.com/straye-as/relation-api/internal/export"
+	"github.com/straye-as/relation-api/internal/repository"
+	"go.uber.org/zap"
+)
+
+// exportPageSize is the number of records fetched per page while streaming an export
+const exportPageSize = 200
+
+// ExportService exports list endpoints as CSV or XLSX.
+// Records are read page by page through the same service methods as the list endpoints,
+// so filters, sorting and company scoping match what the user sees.
+type ExportService struct {
+	offerService    *OfferService
+	customerService *CustomerService
+	projectService  *ProjectService
+	dealService     *DealService
+	supplierService *SupplierService
+	activityService *ActivityService
+	logger          *zap.Logger
+}
+
+// NewExportService creates a new ExportService
+func NewExportService(
+	offerService *OfferService,
+	customerService *CustomerService,
+	projectService *ProjectService,
+	dealService *DealService,
+	supplierService *SupplierService,
+	activityService *ActivityService,
+	logger *zap.Logger,
+) *ExportService {
+	return &ExportService{
+		offerService:    offerService,
+		customerService: customerService,
+		projectService:  projectService,
+		dealService:     dealService,
+		supplierService: supplierService,
+		activityService: activityService,
+		logger:          logger,
+	}
+}
+
+// offerExportColumns are the columns of an offer export
+var offerExportColumns = []export.Column{
+	{Header: "Offer number", Kind: export.KindText},
+	{Header: "Title", Kind: export.KindText},
+	{Header: "Customer", Kind: export.KindText},
+	{Header: "Project", Kind: export.KindText},
+	{Header: "Company", Kind: export.KindText},
+	{Header: "Phase", Kind: export.KindText},
+	{Header: "Status", Kind: export.KindText},
+	{Header: "Probability (%)", Kind: export.KindInteger},
+	{Header: "Value", Kind: export.KindNumber},
+	{Header: "Cost", Kind: export.KindNumber},
+	{Header: "Margin", Kind: export.KindNumber},
+	{Header: "Margin (%)", Kind: export.KindNumber},
+	{Header: "Responsible", Kind: export.KindText},
+	{Header: "Location", Kind: export.KindText},
+	{Header: "Sent date", Kind: export.KindDate},
+	{Header: "Due date", Kind: export.KindDate},
+	{Header: "Expiration date", Kind: export.KindDate},
+	{Header: "Created", Kind: export.KindDateTime},
+	{Header: "Updated", Kind: export.KindDateTime},
+}
+
+// ExportOffers writes all offers matching the list filters. Returns the number of rows written.
+func (s *ExportService) ExportOffers(ctx context.Context, w io.Writer, opts export.Options, filters *repository.OfferFilters, sort repository.SortConfig) (int, error) {
+	return exportPages(w, offerExportColumns, opts, func(page int) (*domain.PaginatedResponse, error) {
+		return s.offerService.ListWithFilters(ctx, page, exportPageSize, filters, sort)
+	}, func(out export.Writer, data interface{}) (int, error) {
+		offers, _ := data.([]domain.OfferDTO)
+		for _, o := range offers {
+			err := out.WriteRow([]interface{}{
+				o.OfferNumber, o.Title, o.CustomerName, o.ProjectName, string(o.CompanyID),
+				string(o.Phase), string(o.Status), o.Probability,
+				o.Value, o.Cost, o.Margin, o.MarginPercent,
+				o.ResponsibleUserName, o.Location,
+				exportTimePtr(o.SentDate), exportTimePtr(o.DueDate), exportTimePtr(o.ExpirationDate),
+				exportTime(o.CreatedAt), exportTime(o.UpdatedAt),
+			})
+			if err != nil {
+				return 0, err
+			}
+		}
+		return len(offers), nil
+	})
+}
+
+// customerExportColumns are the columns of a customer export
+var customerExportColumns = []export.Column{
+	{Header: "Name", Kind: export.KindText},
+	{Header: "Org number", Kind: export.KindText},
+	{Header: "Email", Kind: export.KindText},
+	{Header: "Phone", Kind: export.KindText},
+	{Header: "Address", Kind: export.KindText},
+	{Header: "Postal code", Kind: export.KindText},
+	{Header: "City", Kind: export.KindText},
+	{Header: "Municipality", Kind: export.KindText},
+	{Header: "County", Kind: export.KindText},
+	{Header: "Country", Kind: export.KindText},
+	{Header: "Contact person", Kind: export.KindText},
+	{Header: "Contact email", Kind: export.KindText},
+	{Header: "Contact phone", Kind: export.KindText},
+	{Header: "Status", Kind: export.KindText},
+	{Header: "Tier", Kind: export.KindText},
+	{Header: "Industry", Kind: export.KindText},
+	{Header: "Customer class", Kind: export.KindText},
+	{Header: "Credit limit", Kind: export.KindNumber},
+	{Header: "Internal", Kind: export.KindBool},
+	{Header: "Active offers", Kind: export.KindInteger},
+	{Header: "Active value", Kind: export.KindNumber},
+	{Header: "Won value", Kind: export.KindNumber},
+	{Header: "Created", Kind: export.KindDateTime},
+	{Header: "Updated", Kind: export.KindDateTime},
+}
+
+// ExportCustomers writes all customers matching the list filters. Returns the number of rows written.
+func (s *ExportService) ExportCustomers(ctx context.Context, w io.Writer, opts export.Options, filters *repository.CustomerFilters, sort repository.SortConfig) (int, error) {
+	return exportPages(w, customerExportColumns, opts, func(page int) (*domain.PaginatedResponse, error) {
+		return s.customerService.ListWithSort(ctx, page, exportPageSize, filters, sort)
+	}, func(out export.Writer, data interface{}) (int, error) {
+		customers, _ := data.([]domain.CustomerDTO)
+		for _, c := range customers {
+			var creditLimit interface{}
+			if c.CreditLimit != nil {
+				creditLimit = *c.CreditLimit
+			}
+			err := out.WriteRow([]interface{}{
+				c.Name, c.OrgNumber, c.Email, c.Phone,
+				c.Address, c.PostalCode, c.City, c.Municipality, c.County, c.Country,
+				c.ContactPerson, c.ContactEmail, c.ContactPhone,
+				string(c.Status), string(c.Tier), string(c.Industry), c.CustomerClass,
+				creditLimit, c.IsInternal,
+				c.ActiveOffers, c.TotalValueActive, c.TotalValueWon,
+				exportTime(c.CreatedAt), exportTime(c.UpdatedAt),
+			})
+			if err != nil {
+				return 0, err
+			}
+		}
+		return len(customers), nil
+	})
+}
+
+// projectExportColumns are the columns of a project export
+var projectExportColumns = []export.Column{
+	{Header: "Project number", Kind: export.KindText},
+	{Header: "Name", Kind: export.KindText},
+	{Header: "Customer", Kind: export.KindText},
+	{Header: "Phase", Kind: export.KindText},
+	{Header: "Location", Kind: export.KindText},
+	{Header: "Start date", Kind: export.KindDate},
+	{Header: "End date", Kind: export.KindDate},
+	{Header: "Offers", Kind: export.KindInteger},
+	{Header: "Files", Kind: export.KindInteger},
+	{Header: "External reference", Kind: export.KindText},
+	{Header: "Created", Kind: export.KindDateTime},
+	{Header: "Updated", Kind: export.KindDateTime},
+}
+
+// ExportProjects writes all projects matching the list filters. Returns the number of rows written.
+func (s *ExportService) ExportProjects(ctx context.Context, w io.Writer, opts export.Options, filters *repository.ProjectFilters, sort repository.SortConfig) (int, error) {
+	return exportPages(w, projectExportColumns, opts, func(page int) (*domain.PaginatedResponse, error) {
+		return s.projectService.ListWithSort(ctx, page, exportPageSize, filters, sort)
+	}, func(out export.Writer, data interface{}) (int, error) {
+		projects, _ := data.([]domain.ProjectDTO)
+		for _, p := range projects {
+			err := out.WriteRow([]interface{}{
+				p.ProjectNumber, p.Name, p.CustomerName, string(p.Phase), p.Location,
+				exportTime(p.StartDate), exportTime(p.EndDate),
+				p.OfferCount, p.FileCount, p.ExternalReference,
+				exportTime(p.CreatedAt), exportTime(p.UpdatedAt),
+			})
+			if err != nil {
+				return 0, err
+			}
+		}
+		return len(projects), nil
+	})
+}
+
+// dealExportColumns are the columns of a deal export
+var dealExportColumns = []export.Column{
+	{Header: "Title", Kind: export.KindText},
+	{Header: "Customer", Kind: export.KindText},
+	{Header: "Company", Kind: export.KindText},
+	{Header: "Stage", Kind: export.KindText},
+	{Header: "Probability (%)", Kind: export.KindInteger},
+	{Header: "Value", Kind: export.KindNumber},
+	{Header: "Weighted value", Kind: export.KindNumber},
+	{Header: "Currency", Kind: export.KindText},
+	{Header: "Expected close", Kind: export.KindDate},
+	{Header: "Actual close", Kind: export.KindDate},
+	{Header: "Owner", Kind: export.KindText},
+	{Header: "Source", Kind: export.KindText},
+	{Header: "Lost reason", Kind: export.KindText},
+	{Header: "Created", Kind: export.KindDateTime},
+	{Header: "Updated", Kind: export.KindDateTime},
+}
+
+// ExportDeals writes all deals matching the list filters. Returns the number of rows written.
+func (s *ExportService) ExportDeals(ctx context.Context, w io.Writer, opts export.Options, filters *repository.DealFilters, sortBy repository.DealSortOption) (int, error) {
+	return exportPages(w, dealExportColumns, opts, func(page int) (*domain.PaginatedResponse, error) {
+		return s.dealService.List(ctx, page, exportPageSize, filters, sortBy)
+	}, func(out export.Writer, data interface{}) (int, error) {
+		deals, _ := data.([]domain.DealDTO)
+		for _, d := range deals {
+			err := out.WriteRow([]interface{}{
+				d.Title, d.CustomerName, string(d.CompanyID), string(d.Stage), d.Probability,
+				d.Value, d.WeightedValue, d.Currency,
+				exportTimePtr(d.ExpectedCloseDate), exportTimePtr(d.ActualCloseDate),
+				d.OwnerName, d.Source, d.LostReason,
+				exportTime(d.CreatedAt), exportTime(d.UpdatedAt),
+			})
+			if err != nil {
+				return 0, err
+			}
+		}
+		return len(deals), nil
+	})
+}
+
+// supplierExportColumns are the columns of a supplier export
+var supplierExportColumns = []export.Column{
+	{Header: "Name", Kind: export.KindText},
+	{Header: "Org number", Kind: export.KindText},
+	{Header: "Email", Kind: export.KindText},
+	{Header: "Phone", Kind: export.KindText},
+	{Header: "Address", Kind: export.KindText},
+	{Header: "Postal code", Kind: export.KindText},
+	{Header: "City", Kind: export.KindText},
+	{Header: "Municipality", Kind: export.KindText},
+	{Header: "County", Kind: export.KindText},
+	{Header: "Country", Kind: export.KindText},
+	{Header: "Contact person", Kind: export.KindText},
+	{Header: "Contact email", Kind: export.KindText},
+	{Header: "Contact phone", Kind: export.KindText},
+	{Header: "Status", Kind: export.KindText},
+	{Header: "Category", Kind: export.KindText},
+	{Header: "Payment terms", Kind: export.KindText},
+	{Header: "Created", Kind: export.KindDateTime},
+	{Header: "Updated", Kind: export.KindDateTime},
+}
+
+// ExportSuppliers writes all suppliers matching the list filters. Returns the number of rows written.
+func (s *ExportService) ExportSuppliers(ctx context.Context, w io.Writer, opts export.Options, filters *repository.SupplierFilters, sort repository.SortConfig) (int, error) {
+	return exportPages(w, supplierExportColumns, opts, func(page int) (*domain.PaginatedResponse, error) {
+		return s.supplierService.ListWithSort(ctx, page, exportPageSize, filters, sort)
+	}, func(out export.Writer, data interface{}) (int, error) {
+		suppliers, _ := data.([]domain.SupplierDTO)
+		for _, sp := range suppliers {
+			err := out.WriteRow([]interface{}{
+				sp.Name, sp.OrgNumber, sp.Email, sp.Phone,
+				sp.Address, sp.PostalCode, sp.City, sp.Municipality, sp.County, sp.Country,
+				sp.ContactPerson, sp.ContactEmail, sp.ContactPhone,
+				string(sp.Status), sp.Category, sp.PaymentTerms,
+				exportTime(sp.CreatedAt), exportTime(sp.UpdatedAt),
+			})
+			if err != nil {
+				return 0, err
+			}
+		}
+		return len(suppliers), nil
+	})
+}
+
+// activityExportColumns are the columns of an activity export
+var activityExportColumns = []export.Column{
+	{Header: "Occurred", Kind: export.KindDateTime},
+	{Header: "Type", Kind: export.KindText},
+	{Header: "Status", Kind: export.KindText},
+	{Header: "Title", Kind: export.KindText},
+	{Header: "Target type", Kind: export.KindText},
+	{Header: "Target", Kind: export.KindText},
+	{Header: "Creator", Kind: export.KindText},
+	{Header: "Assigned to", Kind: export.KindText},
+	{Header: "Due date", Kind: export.KindDate},
+	{Header: "Completed", Kind: export.KindDateTime},
+	{Header: "Duration (minutes)", Kind: export.KindInteger},
+	{Header: "Priority", Kind: export.KindInteger},
+	{Header: "Description", Kind: export.KindText},
+}
+
+// ExportActivities writes all activities matching the list filters. Returns the number of rows written.
+func (s *ExportService) ExportActivities(ctx context.Context, w io.Writer, opts export.Options, filters *domain.ActivityFilters) (int, error) {
+	return exportPages(w, activityExportColumns, opts, func(page int) (*domain.PaginatedResponse, error) {
+		return s.activityService.List(ctx, filters, page, exportPageSize)
+	}, func(out export.Writer, data interface{}) (int, error) {
+		activities, _ := data.([]domain.ActivityDTO)
+		for _, a := range activities {
+			var duration interface{}
+			if a.DurationMinutes != nil {
+				duration = *a.DurationMinutes
+			}
+			err := out.WriteRow([]interface{}{
+				exportTime(a.OccurredAt), string(a.ActivityType), string(a.Status), a.Title,
+				string(a.TargetType), a.TargetName, a.CreatorName, a.AssignedToID,
+				exportTime(a.DueDate), exportTime(a.CompletedAt),
+				duration, a.Priority, a.Body,
+			})
+			if err != nil {
+				return 0, err
+			}
+		}
+		return len(activities), nil
+	})
+}
+
+// exportPages fetches pages until the last page and writes each with writePage.
+// The writer is created after the first page is fetched, so errors such as a missing
+// user context are returned before anything is written to w.
+// Returns the total number of rows written.
+func exportPages(
+	w io.Writer,
+	columns []export.Column,
+	opts export.Options,
+	fetch func(page int) (*domain.PaginatedResponse, error),
+	writePage func(out export.Writer, data interface{}) (int, error),
+) (int, error) {
+	result, err := fetch(1)
+	if err != nil {
+		return 0, err
+	}
+
+	out, err := export.NewWriter(w, columns, opts)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create export writer: %w", err)
+	}
+
+	written := 0
+	for page := 1; ; page++ {
+		if page > 1 {
+			if result, err = fetch(page); err != nil {
+				return written, err
+			}
+		}
+
+		count, err := writePage(out, result.Data)
+		written += count
+		if err != nil {
+			return written, fmt.Errorf("failed to write export row: %w", err)
+		}
+
+		if count == 0 || page >= result.TotalPages {
+			break
+		}
+	}
+
+	if err := out.Close(); err != nil {
+		return written, fmt.Errorf("failed to write export: %w", err)
+	}
+	return written, nil
+}
+
+// exportTime parses an ISO 8601 timestamp or date from a DTO. Returns nil for empty or invalid values.
+func exportTime(value string) interface{} {
+	if value == "" {
+		return nil
+	}
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t
+		}
+	}
+	return nil
+}
+
+// exportTimePtr parses an optional ISO 8601 timestamp or date from a DTO
+func exportTimePtr(value *string) interface{} {
+	if value == nil {
+		return nil
+	}
+	return exportTime(*value)
+}
@@ -0,0 +1,409 @@
+package service
+
+// This file contains the field definitions and row parsing for spreadsheet imports.
+// These methods handle:
+// - The fields each entity type can import, with header aliases used by the ERP exports
+// - Suggesting a column mapping from header names
+// - Converting a mapped row to customer and offer requests
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/straye-as/relation-api/internal/domain"
+	"github.com/straye-as/relation-api/internal/importer"
+)
+
+// importField is a field that an import column can be mapped to.
+// Aliases are header names recognised when suggesting a mapping.
+type importField struct {
+	Field    string
+	Label    string
+	Required bool
+	Aliases  []string
+}
+
+// customerImportFields are the importable customer fields.
+// Aliases cover the column names of the ERP customer export.
+var customerImportFields = []importField{
+	{Field: "name", Label: "Navn", Required: true, Aliases: []string{"Kundenavn", "Navn", "Firmanavn", "Name"}},
+	{Field: "orgNumber", Label: "Organisasjonsnummer", Aliases: []string{"Org.nr.", "Orgnr", "Organisasjonsnummer", "Org number"}},
+	{Field: "email", Label: "E-post", Aliases: []string{"Epost", "E-post", "Email"}},
+	{Field: "phone", Label: "Telefon", Aliases: []string{"Telefon", "Phone"}},
+	{Field: "mobile", Label: "Mobil", Aliases: []string{"Mobil", "Mobile"}},
+	{Field: "address", Label: "Adresse", Aliases: []string{"Adresse", "Address"}},
+	{Field: "postalCode", Label: "Postnummer", Aliases: []string{"Postnr.", "Postnummer", "Postal code", "Zip"}},
+	{Field: "city", Label: "Poststed", Aliases: []string{"Poststed", "By", "City"}},
+	{Field: "country", Label: "Land", Aliases: []string{"Land", "Country"}},
+	{Field: "contactPerson", Label: "Kontaktperson", Aliases: []string{"Hovedkontakt", "Kontaktperson", "Contact person"}},
+	{Field: "contactEmail", Label: "Kontaktpersonens e-post", Aliases: []string{"Kontakt e-post", "Contact email"}},
+	{Field: "contactPhone", Label: "Kontaktpersonens telefon", Aliases: []string{"Kontakt telefon", "Contact phone"}},
+	{Field: "customerClass", Label: "Kundeklasse", Aliases: []string{"Kundeklasse", "Customer class"}},
+	{Field: "creditLimit", Label: "Kredittgrense", Aliases: []string{"Kredittgrense", "Credit limit"}},
+	{Field: "isInternal", Label: "Internkunde", Aliases: []string{"Internkunde", "Internal"}},
+	{Field: "inactive", Label: "Inaktiv", Aliases: []string{"Inaktiv", "Inactive"}},
+	{Field: "municipality", Label: "Kommune", Aliases: []string{"Kommune", "Municipality"}},
+	{Field: "county", Label: "Fylke", Aliases: []string{"Fylke", "County"}},
+	{Field: "website", Label: "Nettside", Aliases: []string{"Hjemmeside", "Nettside", "Website"}},
+	{Field: "notes", Label: "Notater", Aliases: []string{"Notater", "Merknad", "Notes"}},
+}
+
+// offerImportFields are the importable offer fields.
+// Aliases cover the column names of the offer tracking spreadsheets.
+var offerImportFields = []importField{
+	{Field: "title", Label: "Tittel", Required: true, Aliases: []string{"Prosjekt", "Tittel", "Title"}},
+	{Field: "customerName", Label: "Kunde", Aliases: []string{"Kunde / Byggherre", "Kunde", "Byggherre", "Customer"}},
+	{Field: "customerOrgNumber", Label: "Kundens organisasjonsnummer", Aliases: []string{"Org.nr.", "Kunde org.nr.", "Customer org number"}},
+	{Field: "value", Label: "Tilbudspris", Aliases: []string{"Tilbudspris", "Verdi", "Pris", "Value"}},
+	{Field: "cost", Label: "Kostnad", Aliases: []string{"Kostnad", "Cost"}},
+	{Field: "phase", Label: "Fase", Aliases: []string{"Fase", "Status", "Phase"}},
+	{Field: "probability", Label: "Sannsynlighet", Aliases: []string{"Sannsynlighet", "Probability"}},
+	{Field: "location", Label: "Beliggenhet", Aliases: []string{"Beliggenhet", "Lokasjon", "Location"}},
+	{Field: "description", Label: "Beskrivelse", Aliases: []string{"Beskrivelse", "Description"}},
+	{Field: "notes", Label: "Notater", Aliases: []string{"Beskrivelse / siste nytt", "Siste nytt", "Notater", "Notes"}},
+	{Field: "sentDate", Label: "Sendt dato", Aliases: []string{"Sendt", "Sendt dato", "Sent date"}},
+	{Field: "dueDate", Label: "Vedståelsesfrist", Aliases: []string{"Vedståelses frist", "Vedståelsesfrist", "Frist", "Due date"}},
+}
+
+// offerPhaseAliases maps Norwegian phase labels used in spreadsheets to offer phases
+var offerPhaseAliases = map[string]domain.OfferPhase{
+	"utkast":       domain.OfferPhaseDraft,
+	"under arbeid": domain.OfferPhaseInProgress,
+	"pågår":        domain.OfferPhaseInProgress,
+	"sendt":        domain.OfferPhaseSent,
+	"tilbud sendt": domain.OfferPhaseSent,
+	"ordre":        domain.OfferPhaseOrder,
+	"vunnet":       domain.OfferPhaseOrder,
+	"akseptert":    domain.OfferPhaseOrder,
+	"ferdig":       domain.OfferPhaseCompleted,
+	"fullført":     domain.OfferPhaseCompleted,
+	"tapt":         domain.OfferPhaseLost,
+	"utgått":       domain.OfferPhaseExpired,
+}
+
+// importFieldsFor returns the field definitions for an entity type
+func importFieldsFor(entityType domain.ImportEntityType) []importField {
+	if entityType == domain.ImportEntityOffer {
+		return offerImportFields
+	}
+	return customerImportFields
+}
+
+// importFieldDTOs converts field definitions to DTOs
+func importFieldDTOs(entityType domain.ImportEntityType) []domain.ImportFieldDTO {
+	fields := importFieldsFor(entityType)
+	dtos := make([]domain.ImportFieldDTO, len(fields))
+	for i, field := range fields {
+		dtos[i] = domain.ImportFieldDTO{Field: field.Field, Label: field.Label, Required: field.Required}
+	}
+	return dtos
+}
+
+// suggestImportMapping guesses a column mapping by comparing headers with field names and aliases.
+// Each field is suggested for at most one column.
+func suggestImportMapping(entityType domain.ImportEntityType, headers []string) map[string]string {
+	fields := importFieldsFor(entityType)
+	mapping := make(map[string]string)
+	used := make(map[string]bool)
+
+	for _, header := range headers {
+		normalized := importer.NormalizeHeader(header)
+		if normalized == "" {
+			continue
+		}
+		for _, field := range fields {
+			if used[field.Field] || !importFieldMatches(field, normalized) {
+				continue
+			}
+			mapping[header] = field.Field
+			used[field.Field] = true
+			break
+		}
+	}
+	return mapping
+}
+
+// importFieldMatches checks if a normalized header matches the field name or one of its aliases
+func importFieldMatches(field importField, normalizedHeader string) bool {
+	if importer.NormalizeHeader(field.Field) == normalizedHeader {
+		return true
+	}
+	for _, alias := range field.Aliases {
+		if importer.NormalizeHeader(alias) == normalizedHeader {
+			return true
+		}
+	}
+	return false
+}
+
+// validateImportMapping checks a mapping against the entity fields and, if given, the file headers.
+// Returns the column index for each mapped field.
+func validateImportMapping(entityType domain.ImportEntityType, mapping map[string]string, headers []string) (map[string]int, error) {
+	if len(mapping) == 0 {
+		return nil, ErrImportMappingRequired
+	}
+
+	known := make(map[string]bool)
+	for _, field := range importFieldsFor(entityType) {
+		known[field.Field] = true
+	}
+
+	headerIndex := make(map[string]int, len(headers))
+	for i, header := range headers {
+		headerIndex[header] = i
+	}
+
+	columns := make(map[string]int)
+	for header, field := range mapping {
+		if field == "" {
+			continue // Column explicitly ignored
+		}
+		if !known[field] {
+			return nil, fmt.Errorf("%w: unknown field %q", ErrInvalidImportMapping, field)
+		}
+		if _, duplicate := columns[field]; duplicate {
+			return nil, fmt.Errorf("%w: field %q is mapped more than once", ErrInvalidImportMapping, field)
+		}
+		index := -1
+		if headers != nil {
+			var ok bool
+			if index, ok = headerIndex[header]; !ok {
+				return nil, fmt.Errorf("%w: column %q not found in file", ErrInvalidImportMapping, header)
+			}
+		}
+		columns[field] = index
+	}
+
+	for _, field := range importFieldsFor(entityType) {
+		if _, ok := columns[field.Field]; field.Required && !ok {
+			return nil, fmt.Errorf("%w: required field %q is not mapped", ErrInvalidImportMapping, field.Field)
+		}
+	}
+	if entityType == domain.ImportEntityOffer {
+		_, hasName := columns["customerName"]
+		_, hasOrgNumber := columns["customerOrgNumber"]
+		if !hasName && !hasOrgNumber {
+			return nil, fmt.Errorf("%w: customerName or customerOrgNumber must be mapped", ErrInvalidImportMapping)
+		}
+	}
+
+	return columns, nil
+}
+
+// importRow gives access to the mapped cells of one data row
+type importRow struct {
+	number  int // Spreadsheet row number (header is row 1)
+	cells   []string
+	columns map[string]int
+}
+
+// get returns the trimmed cell value for a field, or "" if the field is not mapped
+func (r importRow) get(field string) string {
+	index, ok := r.columns[field]
+	if !ok || index < 0 || index >= len(r.cells) {
+		return ""
+	}
+	return strings.TrimSpace(r.cells[index])
+}
+
+// customerRequestFromRow converts a row to a create request.
+// Returns the request and the validation problems found in the row.
+func customerRequestFromRow(row importRow) (*domain.CreateCustomerRequest, []string) {
+	var problems []string
+
+	req := &domain.CreateCustomerRequest{
+		Name:          row.get("name"),
+		OrgNumber:     normalizeOrgNumber(importer.ParseOrgNumber(row.get("orgNumber"))),
+		Email:         row.get("email"),
+		Phone:         row.get("phone"),
+		Address:       row.get("address"),
+		City:          row.get("city"),
+		PostalCode:    importer.ParsePostalCode(row.get("postalCode")),
+		Country:       row.get("country"),
+		ContactPerson: row.get("contactPerson"),
+		ContactEmail:  row.get("contactEmail"),
+		ContactPhone:  row.get("contactPhone"),
+		CustomerClass: row.get("customerClass"),
+		Municipality:  row.get("municipality"),
+		County:        row.get("county"),
+		Website:       row.get("website"),
+		Notes:         row.get("notes"),
+	}
+
+	// Prefer mobile over landline, as in the ERP export
+	if mobile := row.get("mobile"); mobile != "" {
+		req.Phone = mobile
+	}
+	if req.Country == "" {
+		req.Country = "Norway"
+	}
+
+	if req.Name == "" {
+		problems = append(problems, "name is required")
+	}
+	if err := validateOrgNumber(req.OrgNumber); err != nil {
+		problems = append(problems, fmt.Sprintf("invalid organization number %q", req.OrgNumber))
+	}
+	if err := validateEmail(req.Email); err != nil {
+		problems = append(problems, fmt.Sprintf("invalid email %q", req.Email))
+	}
+	if err := validateEmail(req.ContactEmail); err != nil {
+		problems = append(problems, fmt.Sprintf("invalid contact email %q", req.ContactEmail))
+	}
+	if err := validatePhone(req.Phone); err != nil {
+		problems = append(problems, fmt.Sprintf("invalid phone %q", req.Phone))
+	}
+	if err := validatePhone(req.ContactPhone); err != nil {
+		problems = append(problems, fmt.Sprintf("invalid contact phone %q", req.ContactPhone))
+	}
+
+	if value := row.get("creditLimit"); value != "" {
+		creditLimit, err := importer.ParseNumber(value)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("invalid credit limit %q", value))
+		} else {
+			req.CreditLimit = &creditLimit
+		}
+	}
+	if value := row.get("isInternal"); value != "" {
+		isInternal, err := importer.ParseBool(value)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("invalid internal customer value %q", value))
+		}
+		req.IsInternal = isInternal
+	}
+	if value := row.get("inactive"); value != "" {
+		inactive, err := importer.ParseBool(value)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("invalid inactive value %q", value))
+		}
+		if inactive {
+			req.Status = domain.CustomerStatusInactive
+		}
+	}
+
+	return req, problems
+}
+
+// customerUpdateFromCreate converts a parsed create request to an update request.
+// Only non-empty values are applied by CustomerService.Update, so blank cells keep existing data.
+func customerUpdateFromCreate(req *domain.CreateCustomerRequest) *domain.UpdateCustomerRequest {
+	return &domain.UpdateCustomerRequest{
+		Name:          req.Name,
+		Email:         req.Email,
+		Phone:         req.Phone,
+		Address:       req.Address,
+		City:          req.City,
+		PostalCode:    req.PostalCode,
+		Country:       req.Country,
+		ContactPerson: req.ContactPerson,
+		ContactEmail:  req.ContactEmail,
+		ContactPhone:  req.ContactPhone,
+		Status:        req.Status,
+		Notes:         req.Notes,
+		CustomerClass: req.CustomerClass,
+		CreditLimit:   req.CreditLimit,
+		Municipality:  req.Municipality,
+		County:        req.County,
+		Website:       req.Website,
+	}
+}
+
+// offerRequestFromRow converts a row to a create request without a customer.
+// Returns the request and the validation problems found in the row.
+func offerRequestFromRow(row importRow, companyID domain.CompanyID) (*domain.CreateOfferRequest, []string) {
+	var problems []string
+
+	req := &domain.CreateOfferRequest{
+		Title:       row.get("title"),
+		CompanyID:   companyID,
+		Location:    row.get("location"),
+		Description: row.get("description"),
+		Notes:       row.get("notes"),
+	}
+	if req.Title == "" {
+		problems = append(problems, "title is required")
+	}
+	if len(req.Title) > 200 {
+		problems = append(problems, "title is longer than 200 characters")
+	}
+
+	var value, cost float64
+	if raw := row.get("value"); raw != "" {
+		parsed, err := importer.ParseNumber(raw)
+		if err != nil || parsed < 0 {
+			problems = append(problems, fmt.Sprintf("invalid value %q", raw))
+		}
+		value = parsed
+	}
+	if raw := row.get("cost"); raw != "" {
+		parsed, err := importer.ParseNumber(raw)
+		if err != nil || parsed < 0 {
+			problems = append(problems, fmt.Sprintf("invalid cost %q", raw))
+		}
+		cost = parsed
+	}
+	req.Cost = cost
+	if value > 0 || cost > 0 {
+		// Offer value is the sum of item revenue, so the imported price becomes a single item
+		req.Items = []domain.CreateOfferItemRequest{{
+			Discipline: "Importert",
+			Cost:       cost,
+			Revenue:    value,
+			Quantity:   1,
+		}}
+	}
+
+	if raw := row.get("phase"); raw != "" {
+		phase, ok := parseImportOfferPhase(raw)
+		if !ok {
+			problems = append(problems, fmt.Sprintf("unknown phase %q", raw))
+		}
+		req.Phase = phase
+	}
+
+	if raw := row.get("probability"); raw != "" {
+		probability, err := strconv.Atoi(strings.TrimSpace(strings.TrimSuffix(raw, "%")))
+		if err != nil || probability < 0 || probability > 100 {
+			problems = append(problems, fmt.Sprintf("invalid probability %q", raw))
+		} else {
+			req.Probability = &probability
+		}
+	}
+
+	if raw := row.get("sentDate"); raw != "" {
+		sentDate, err := importer.ParseDate(raw)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("invalid sent date %q", raw))
+		} else {
+			req.SentDate = &sentDate
+		}
+	}
+	if raw := row.get("dueDate"); raw != "" {
+		dueDate, err := importer.ParseDate(raw)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("invalid due date %q", raw))
+		} else {
+			req.DueDate = &dueDate
+		}
+	}
+
+	return req, problems
+}
+
+// parseImportOfferPhase accepts offer phase values and the Norwegian labels used in spreadsheets
+func parseImportOfferPhase(value string) (domain.OfferPhase, bool) {
+	normalized := strings.ToLower(strings.TrimSpace(value))
+	if phase, ok := offerPhaseAliases[normalized]; ok {
+		return phase, true
+	}
+	phase := domain.OfferPhase(strings.ReplaceAll(normalized, " ", "_"))
+	switch phase {
+	case domain.OfferPhaseDraft, domain.OfferPhaseInProgress, domain.OfferPhaseSent, domain.OfferPhaseOrder,
+		domain.OfferPhaseCompleted, domain.OfferPhaseLost, domain.OfferPhaseExpired:
+		return phase, true
+	default:
+		return "", false
+	}
+}
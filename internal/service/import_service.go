@@ -0,0 +1,795 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/straye-as/relation-api/internal/auth"
+	"github.com/straye-as/relation-api/internal/domain"
+	"github.com/straye-as/relation-api/internal/importer"
+	"github.com/straye-as/relation-api/internal/repository"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// Import service errors
+var (
+	// ErrImportJobNotFound is returned when an import job is not found
+	ErrImportJobNotFound = errors.New("import job not found")
+
+	// ErrImportMappingProfileNotFound is returned when a mapping profile is not found
+	ErrImportMappingProfileNotFound = errors.New("import mapping profile not found")
+
+	// ErrInvalidImportEntityType is returned for entity types that cannot be imported
+	ErrInvalidImportEntityType = errors.New("invalid import entity type: must be customer or offer")
+
+	// ErrDuplicateImportMappingProfile is returned when a profile name is already used for the entity type
+	ErrDuplicateImportMappingProfile = errors.New("a mapping profile with this name already exists")
+
+	// ErrImportMappingRequired is returned when neither a mapping nor a mapping profile is given
+	ErrImportMappingRequired = errors.New("column mapping is required")
+
+	// ErrInvalidImportMapping is returned when a mapping refers to unknown fields or columns
+	ErrInvalidImportMapping = errors.New("invalid column mapping")
+
+	// ErrImportNotValidated is returned when committing a job without a successful dry run
+	ErrImportNotValidated = errors.New("import must be validated with a dry run before commit")
+
+	// ErrImportAlreadyCommitted is returned when a job has already been committed
+	ErrImportAlreadyCommitted = errors.New("import has already been committed")
+)
+
+// defaultImportMatchThreshold is the minimum fuzzy name confidence for matching an existing customer
+const defaultImportMatchThreshold = 0.8
+
+// importSampleRows is the number of rows returned as mapping preview
+const importSampleRows = 5
+
+// importOptions are the dry run options stored on the job and reused on commit
+type importOptions struct {
+	UpdateExisting bool             `json:"updateExisting"`
+	MatchThreshold float64          `json:"matchThreshold"`
+	CompanyID      domain.CompanyID `json:"companyId,omitempty"`
+}
+
+// importPlanRow is the planned action for a row together with the request to execute
+type importPlanRow struct {
+	result         domain.ImportRowResultDTO
+	createCustomer *domain.CreateCustomerRequest
+	createOffer    *domain.CreateOfferRequest
+}
+
+// customerMatch is an existing customer matched by org number or name
+type customerMatch struct {
+	customer   *domain.Customer
+	matchedBy  string
+	confidence float64
+}
+
+// ImportService imports customers and offers from XLSX and CSV files.
+// Files are uploaded as jobs, validated with a dry run and then committed through
+// CustomerService and OfferService so numbering, activities and validation apply.
+type ImportService struct {
+	importRepo      *repository.ImportRepository
+	customerRepo    *repository.CustomerRepository
+	offerRepo       *repository.OfferRepository
+	customerService *CustomerService
+	offerService    *OfferService
+	logger          *zap.Logger
+}
+
+// NewImportService creates a new ImportService
+func NewImportService(
+	importRepo *repository.ImportRepository,
+	customerRepo *repository.CustomerRepository,
+	offerRepo *repository.OfferRepository,
+	customerService *CustomerService,
+	offerService *OfferService,
+	logger *zap.Logger,
+) *ImportService {
+	return &ImportService{
+		importRepo:      importRepo,
+		customerRepo:    customerRepo,
+		offerRepo:       offerRepo,
+		customerService: customerService,
+		offerService:    offerService,
+		logger:          logger,
+	}
+}
+
+// GetFields returns the importable fields for an entity type
+func (s *ImportService) GetFields(entityType domain.ImportEntityType) ([]domain.ImportFieldDTO, error) {
+	if !entityType.IsValid() {
+		return nil, ErrInvalidImportEntityType
+	}
+	return importFieldDTOs(entityType), nil
+}
+
+// Upload parses an import file and stores it as a new job.
+// The response includes sample rows and a suggested column mapping.
+func (s *ImportService) Upload(ctx context.Context, entityType domain.ImportEntityType, fileName string, r io.Reader) (*domain.ImportJobDTO, error) {
+	if !entityType.IsValid() {
+		return nil, ErrInvalidImportEntityType
+	}
+
+	sheet, err := importer.Read(fileName, r)
+	if err != nil {
+		return nil, err
+	}
+
+	headersJSON, err := json.Marshal(sheet.Headers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode headers: %w", err)
+	}
+	rowsJSON, err := json.Marshal(sheet.Rows)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode rows: %w", err)
+	}
+
+	job := &domain.ImportJob{
+		EntityType: entityType,
+		FileName:   fileName,
+		Status:     domain.ImportJobStatusUploaded,
+		Headers:    string(headersJSON),
+		Rows:       string(rowsJSON),
+		RowCount:   len(sheet.Rows),
+	}
+	if userCtx, ok := auth.FromContext(ctx); ok {
+		job.CreatedByID = userCtx.UserID.String()
+		job.CreatedByName = userCtx.DisplayName
+	}
+
+	if err := s.importRepo.CreateJob(ctx, job); err != nil {
+		return nil, fmt.Errorf("failed to create import job: %w", err)
+	}
+
+	s.logger.Info("import file uploaded",
+		zap.String("job_id", job.ID.String()),
+		zap.String("entity_type", string(entityType)),
+		zap.String("file_name", fileName),
+		zap.Int("rows", job.RowCount))
+
+	dto := s.toJobDTO(job)
+	end := importSampleRows
+	if end > len(sheet.Rows) {
+		end = len(sheet.Rows)
+	}
+	dto.SampleRows = sheet.Rows[:end]
+	dto.SuggestedMapping = suggestImportMapping(entityType, sheet.Headers)
+	dto.Fields = importFieldDTOs(entityType)
+	return dto, nil
+}
+
+// GetJob returns an import job with a mapping preview
+func (s *ImportService) GetJob(ctx context.Context, id uuid.UUID) (*domain.ImportJobDTO, error) {
+	job, err := s.getJob(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	dto := s.toJobDTO(job)
+	rows, err := decodeImportRows(job)
+	if err != nil {
+		return nil, err
+	}
+	end := importSampleRows
+	if end > len(rows) {
+		end = len(rows)
+	}
+	dto.SampleRows = rows[:end]
+	dto.SuggestedMapping = suggestImportMapping(job.EntityType, dto.Headers)
+	dto.Fields = importFieldDTOs(job.EntityType)
+	return dto, nil
+}
+
+// ListJobs returns a paginated list of import jobs, newest first
+func (s *ImportService) ListJobs(ctx context.Context, entityType *domain.ImportEntityType, page, pageSize int) (*domain.PaginatedResponse, error) {
+	if entityType != nil && !entityType.IsValid() {
+		return nil, ErrInvalidImportEntityType
+	}
+
+	// Clamp page size to prevent excessive queries
+	if pageSize < 1 {
+		pageSize = 20
+	}
+	if pageSize > 200 {
+		pageSize = 200
+	}
+	if page < 1 {
+		page = 1
+	}
+
+	jobs, total, err := s.importRepo.ListJobs(ctx, entityType, page, pageSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list import jobs: %w", err)
+	}
+
+	dtos := make([]domain.ImportJobDTO, len(jobs))
+	for i := range jobs {
+		dtos[i] = *s.toJobDTO(&jobs[i])
+	}
+
+	totalPages := int((total + int64(pageSize) - 1) / int64(pageSize))
+
+	return &domain.PaginatedResponse{
+		Data:       dtos,
+		Total:      total,
+		Page:       page,
+		PageSize:   pageSize,
+		TotalPages: totalPages,
+	}, nil
+}
+
+// GetReport returns the report from the last dry run or commit of a job
+func (s *ImportService) GetReport(ctx context.Context, id uuid.UUID) (*domain.ImportReportDTO, error) {
+	job, err := s.getJob(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if job.Report == "" {
+		return nil, ErrImportNotValidated
+	}
+
+	var report domain.ImportReportDTO
+	if err := json.Unmarshal([]byte(job.Report), &report); err != nil {
+		return nil, fmt.Errorf("failed to decode import report: %w", err)
+	}
+	return &report, nil
+}
+
+// DeleteJob deletes an import job. Records created by a committed import are kept.
+func (s *ImportService) DeleteJob(ctx context.Context, id uuid.UUID) error {
+	if _, err := s.getJob(ctx, id); err != nil {
+		return err
+	}
+	if err := s.importRepo.DeleteJob(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete import job: %w", err)
+	}
+	return nil
+}
+
+// DryRun validates every row of a job against the mapping and reports what a commit would do.
+// Nothing is written except the report and options stored on the job.
+func (s *ImportService) DryRun(ctx context.Context, id uuid.UUID, req *domain.RunImportRequest) (*domain.ImportReportDTO, error) {
+	job, err := s.getJob(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if job.Status == domain.ImportJobStatusCommitted {
+		return nil, ErrImportAlreadyCommitted
+	}
+
+	mapping := req.Mapping
+	if req.MappingProfileID != nil {
+		profile, err := s.importRepo.GetProfileByID(ctx, *req.MappingProfileID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, ErrImportMappingProfileNotFound
+			}
+			return nil, fmt.Errorf("failed to get mapping profile: %w", err)
+		}
+		if profile.EntityType != job.EntityType {
+			return nil, fmt.Errorf("%w: profile is for %s imports", ErrInvalidImportMapping, profile.EntityType)
+		}
+		if err := json.Unmarshal([]byte(profile.Mapping), &mapping); err != nil {
+			return nil, fmt.Errorf("failed to decode mapping profile: %w", err)
+		}
+	}
+
+	if req.CompanyID != "" && !domain.IsValidCompanyID(string(req.CompanyID)) {
+		return nil, fmt.Errorf("%w: invalid company ID %q", ErrInvalidImportMapping, req.CompanyID)
+	}
+	options := importOptions{
+		UpdateExisting: req.UpdateExisting,
+		MatchThreshold: req.MatchThreshold,
+		CompanyID:      req.CompanyID,
+	}
+	if options.MatchThreshold <= 0 || options.MatchThreshold > 1 {
+		options.MatchThreshold = defaultImportMatchThreshold
+	}
+
+	plan, err := s.plan(ctx, job, mapping, options)
+	if err != nil {
+		return nil, err
+	}
+
+	report := s.buildReport(job, plan, true)
+
+	mappingJSON, err := json.Marshal(mapping)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode mapping: %w", err)
+	}
+	optionsJSON, err := json.Marshal(options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode options: %w", err)
+	}
+	reportJSON, err := json.Marshal(report)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode report: %w", err)
+	}
+
+	job.Mapping = string(mappingJSON)
+	job.Options = string(optionsJSON)
+	job.Report = string(reportJSON)
+	job.Status = domain.ImportJobStatusValidated
+	if options.CompanyID != "" {
+		companyID := options.CompanyID
+		job.CompanyID = &companyID
+	}
+	if err := s.importRepo.UpdateJob(ctx, job); err != nil {
+		return nil, fmt.Errorf("failed to update import job: %w", err)
+	}
+
+	return report, nil
+}
+
+// Commit executes a validated import job through CustomerService or OfferService.
+// Rows are re-planned with the stored mapping so records created since the dry run are matched.
+// Rows that fail are reported as errors without aborting the remaining rows.
+func (s *ImportService) Commit(ctx context.Context, id uuid.UUID) (*domain.ImportReportDTO, error) {
+	job, err := s.getJob(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	switch job.Status {
+	case domain.ImportJobStatusCommitted:
+		return nil, ErrImportAlreadyCommitted
+	case domain.ImportJobStatusValidated:
+	default:
+		return nil, ErrImportNotValidated
+	}
+
+	var mapping map[string]string
+	if err := json.Unmarshal([]byte(job.Mapping), &mapping); err != nil {
+		return nil, fmt.Errorf("failed to decode import mapping: %w", err)
+	}
+	var options importOptions
+	if job.Options != "" {
+		if err := json.Unmarshal([]byte(job.Options), &options); err != nil {
+			return nil, fmt.Errorf("failed to decode import options: %w", err)
+		}
+	}
+
+	plan, err := s.plan(ctx, job, mapping, options)
+	if err != nil {
+		job.Status = domain.ImportJobStatusFailed
+		if updateErr := s.importRepo.UpdateJob(ctx, job); updateErr != nil {
+			s.logger.Warn("failed to mark import job as failed", zap.Error(updateErr))
+		}
+		return nil, err
+	}
+
+	for i := range plan {
+		s.execute(ctx, job.EntityType, &plan[i])
+	}
+
+	report := s.buildReport(job, plan, false)
+	reportJSON, err := json.Marshal(report)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode report: %w", err)
+	}
+
+	now := time.Now()
+	job.Report = string(reportJSON)
+	job.Status = domain.ImportJobStatusCommitted
+	job.CommittedAt = &now
+	if err := s.importRepo.UpdateJob(ctx, job); err != nil {
+		return nil, fmt.Errorf("failed to update import job: %w", err)
+	}
+
+	s.logger.Info("import committed",
+		zap.String("job_id", job.ID.String()),
+		zap.String("entity_type", string(job.EntityType)),
+		zap.Int("created", report.Summary.Create),
+		zap.Int("updated", report.Summary.Update),
+		zap.Int("skipped", report.Summary.Skip),
+		zap.Int("errors", report.Summary.Error))
+
+	return report, nil
+}
+
+// ListProfiles returns saved mapping profiles, optionally filtered by entity type
+func (s *ImportService) ListProfiles(ctx context.Context, entityType *domain.ImportEntityType) ([]domain.ImportMappingProfileDTO, error) {
+	if entityType != nil && !entityType.IsValid() {
+		return nil, ErrInvalidImportEntityType
+	}
+
+	profiles, err := s.importRepo.ListProfiles(ctx, entityType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list mapping profiles: %w", err)
+	}
+
+	dtos := make([]domain.ImportMappingProfileDTO, len(profiles))
+	for i := range profiles {
+		dtos[i] = toImportMappingProfileDTO(&profiles[i])
+	}
+	return dtos, nil
+}
+
+// CreateProfile saves a new mapping profile
+func (s *ImportService) CreateProfile(ctx context.Context, req *domain.SaveImportMappingProfileRequest) (*domain.ImportMappingProfileDTO, error) {
+	mappingJSON, err := validateImportProfile(req)
+	if err != nil {
+		return nil, err
+	}
+
+	profile := &domain.ImportMappingProfile{
+		Name:       strings.TrimSpace(req.Name),
+		EntityType: req.EntityType,
+		Mapping:    mappingJSON,
+	}
+	if userCtx, ok := auth.FromContext(ctx); ok {
+		profile.CreatedByID = userCtx.UserID.String()
+		profile.CreatedByName = userCtx.DisplayName
+	}
+
+	if err := s.importRepo.CreateProfile(ctx, profile); err != nil {
+		if strings.Contains(err.Error(), "duplicate key") || strings.Contains(err.Error(), "unique constraint") {
+			return nil, ErrDuplicateImportMappingProfile
+		}
+		return nil, fmt.Errorf("failed to create mapping profile: %w", err)
+	}
+
+	dto := toImportMappingProfileDTO(profile)
+	return &dto, nil
+}
+
+// UpdateProfile replaces the name and mapping of a mapping profile
+func (s *ImportService) UpdateProfile(ctx context.Context, id uuid.UUID, req *domain.SaveImportMappingProfileRequest) (*domain.ImportMappingProfileDTO, error) {
+	profile, err := s.importRepo.GetProfileByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrImportMappingProfileNotFound
+		}
+		return nil, fmt.Errorf("failed to get mapping profile: %w", err)
+	}
+
+	mappingJSON, err := validateImportProfile(req)
+	if err != nil {
+		return nil, err
+	}
+
+	profile.Name = strings.TrimSpace(req.Name)
+	profile.EntityType = req.EntityType
+	profile.Mapping = mappingJSON
+
+	if err := s.importRepo.UpdateProfile(ctx, profile); err != nil {
+		if strings.Contains(err.Error(), "duplicate key") || strings.Contains(err.Error(), "unique constraint") {
+			return nil, ErrDuplicateImportMappingProfile
+		}
+		return nil, fmt.Errorf("failed to update mapping profile: %w", err)
+	}
+
+	dto := toImportMappingProfileDTO(profile)
+	return &dto, nil
+}
+
+// DeleteProfile deletes a mapping profile
+func (s *ImportService) DeleteProfile(ctx context.Context, id uuid.UUID) error {
+	if _, err := s.importRepo.GetProfileByID(ctx, id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrImportMappingProfileNotFound
+		}
+		return fmt.Errorf("failed to get mapping profile: %w", err)
+	}
+	if err := s.importRepo.DeleteProfile(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete mapping profile: %w", err)
+	}
+	return nil
+}
+
+// plan decides the action for every row of a job
+func (s *ImportService) plan(ctx context.Context, job *domain.ImportJob, mapping map[string]string, options importOptions) ([]importPlanRow, error) {
+	var headers []string
+	if err := json.Unmarshal([]byte(job.Headers), &headers); err != nil {
+		return nil, fmt.Errorf("failed to decode import headers: %w", err)
+	}
+	rows, err := decodeImportRows(job)
+	if err != nil {
+		return nil, err
+	}
+
+	columns, err := validateImportMapping(job.EntityType, mapping, headers)
+	if err != nil {
+		return nil, err
+	}
+
+	// Remember keys seen earlier in the file so duplicates are skipped
+	seen := make(map[string]int)
+	plan := make([]importPlanRow, len(rows))
+	for i, cells := range rows {
+		row := importRow{number: i + 2, cells: cells, columns: columns}
+		var planned importPlanRow
+		if job.EntityType == domain.ImportEntityOffer {
+			planned, err = s.planOfferRow(ctx, row, options, seen)
+		} else {
+			planned, err = s.planCustomerRow(ctx, row, options, seen)
+		}
+		if err != nil {
+			return nil, err
+		}
+		plan[i] = planned
+	}
+	return plan, nil
+}
+
+// planCustomerRow matches a customer row against existing customers.
+// Matched rows are updated or skipped depending on UpdateExisting; others are created.
+func (s *ImportService) planCustomerRow(ctx context.Context, row importRow, options importOptions, seen map[string]int) (importPlanRow, error) {
+	req, problems := customerRequestFromRow(row)
+	planned := importPlanRow{result: domain.ImportRowResultDTO{Row: row.number, Name: req.Name}}
+	if len(problems) > 0 {
+		planned.result.Action = domain.ImportRowActionError
+		planned.result.Messages = problems
+		return planned, nil
+	}
+
+	key := "name:" + strings.ToLower(req.Name)
+	if req.OrgNumber != "" {
+		key = "org:" + req.OrgNumber
+	}
+	if first, ok := seen[key]; ok {
+		planned.result.Action = domain.ImportRowActionSkip
+		planned.result.Messages = []string{fmt.Sprintf("duplicate of row %d", first)}
+		return planned, nil
+	}
+	seen[key] = row.number
+
+	match, err := s.matchCustomer(ctx, req.Name, req.OrgNumber, options.MatchThreshold)
+	if err != nil {
+		return planned, err
+	}
+	if match == nil {
+		planned.result.Action = domain.ImportRowActionCreate
+		planned.createCustomer = req
+		return planned, nil
+	}
+
+	planned.result.EntityID = &match.customer.ID
+	planned.result.MatchedBy = match.matchedBy
+	planned.result.MatchedName = match.customer.Name
+	planned.result.MatchConfidence = match.confidence
+	if options.UpdateExisting {
+		planned.result.Action = domain.ImportRowActionUpdate
+		planned.createCustomer = req
+	} else {
+		planned.result.Action = domain.ImportRowActionSkip
+		planned.result.Messages = []string{"customer already exists"}
+	}
+	return planned, nil
+}
+
+// planOfferRow resolves the customer of an offer row.
+// Rows without a matching customer are errors; offers the customer already has are skipped.
+func (s *ImportService) planOfferRow(ctx context.Context, row importRow, options importOptions, seen map[string]int) (importPlanRow, error) {
+	req, problems := offerRequestFromRow(row, options.CompanyID)
+	planned := importPlanRow{result: domain.ImportRowResultDTO{Row: row.number, Name: req.Title}}
+
+	customerName := row.get("customerName")
+	orgNumber := normalizeOrgNumber(importer.ParseOrgNumber(row.get("customerOrgNumber")))
+	if customerName == "" && orgNumber == "" {
+		problems = append(problems, "customer name or organization number is required")
+	}
+	if len(problems) > 0 {
+		planned.result.Action = domain.ImportRowActionError
+		planned.result.Messages = problems
+		return planned, nil
+	}
+
+	match, err := s.matchCustomer(ctx, customerName, orgNumber, options.MatchThreshold)
+	if err != nil {
+		return planned, err
+	}
+	if match == nil {
+		planned.result.Action = domain.ImportRowActionError
+		planned.result.Messages = []string{fmt.Sprintf("no customer found for %q", strings.TrimSpace(customerName+" "+orgNumber))}
+		return planned, nil
+	}
+	planned.result.MatchedBy = match.matchedBy
+	planned.result.MatchedName = match.customer.Name
+	planned.result.MatchConfidence = match.confidence
+
+	key := match.customer.ID.String() + ":" + strings.ToLower(req.Title)
+	if first, ok := seen[key]; ok {
+		planned.result.Action = domain.ImportRowActionSkip
+		planned.result.Messages = []string{fmt.Sprintf("duplicate of row %d", first)}
+		return planned, nil
+	}
+	seen[key] = row.number
+
+	exists, err := s.offerRepo.ExistsForCustomerWithTitle(ctx, match.customer.ID, req.Title)
+	if err != nil {
+		return planned, fmt.Errorf("failed to check existing offers: %w", err)
+	}
+	if exists {
+		planned.result.Action = domain.ImportRowActionSkip
+		planned.result.Messages = []string{"customer already has an offer with this title"}
+		return planned, nil
+	}
+
+	customerID := match.customer.ID
+	req.CustomerID = &customerID
+	planned.result.Action = domain.ImportRowActionCreate
+	planned.createOffer = req
+	return planned, nil
+}
+
+// matchCustomer finds an existing customer by org number, then by fuzzy name.
+// A name match is ignored if the customer has a different org number than the row.
+func (s *ImportService) matchCustomer(ctx context.Context, name, orgNumber string, threshold float64) (*customerMatch, error) {
+	if orgNumber != "" {
+		customer, err := s.customerRepo.GetByOrgNumber(ctx, orgNumber)
+		if err == nil {
+			return &customerMatch{customer: customer, matchedBy: "org_number", confidence: 1}, nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("failed to look up customer by org number: %w", err)
+		}
+	}
+
+	// "all" lists every customer in FuzzySearchBestMatch and is never a real name
+	if name == "" || strings.EqualFold(name, "all") {
+		return nil, nil
+	}
+
+	result, err := s.customerService.FuzzySearchBestMatch(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if !result.Found || result.Customer == nil || result.Confidence < threshold {
+		return nil, nil
+	}
+
+	customer, err := s.customerRepo.GetByID(ctx, result.Customer.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get matched customer: %w", err)
+	}
+	if orgNumber != "" && customer.OrgNumber != "" && customer.OrgNumber != orgNumber {
+		return nil, nil
+	}
+	return &customerMatch{customer: customer, matchedBy: "name", confidence: result.Confidence}, nil
+}
+
+// execute writes a planned row through the services and records the outcome
+func (s *ImportService) execute(ctx context.Context, entityType domain.ImportEntityType, planned *importPlanRow) {
+	var err error
+	switch {
+	case entityType == domain.ImportEntityOffer && planned.result.Action == domain.ImportRowActionCreate:
+		var offer *domain.OfferDTO
+		if offer, err = s.offerService.Create(ctx, planned.createOffer); err == nil {
+			planned.result.EntityID = &offer.ID
+		}
+	case planned.result.Action == domain.ImportRowActionCreate:
+		var customer *domain.CustomerDTO
+		if customer, err = s.customerService.Create(ctx, planned.createCustomer); err == nil {
+			planned.result.EntityID = &customer.ID
+		}
+	case planned.result.Action == domain.ImportRowActionUpdate:
+		_, err = s.customerService.Update(ctx, *planned.result.EntityID, customerUpdateFromCreate(planned.createCustomer))
+	default:
+		return
+	}
+
+	if err != nil {
+		planned.result.Action = domain.ImportRowActionError
+		planned.result.Messages = append(planned.result.Messages, err.Error())
+	}
+}
+
+// buildReport summarizes a plan
+func (s *ImportService) buildReport(job *domain.ImportJob, plan []importPlanRow, dryRun bool) *domain.ImportReportDTO {
+	report := &domain.ImportReportDTO{
+		JobID:       job.ID,
+		EntityType:  job.EntityType,
+		DryRun:      dryRun,
+		Rows:        make([]domain.ImportRowResultDTO, len(plan)),
+		GeneratedAt: time.Now().UTC().Format(time.RFC3339),
+	}
+	for i, planned := range plan {
+		report.Rows[i] = planned.result
+		report.Summary.Total++
+		switch planned.result.Action {
+		case domain.ImportRowActionCreate:
+			report.Summary.Create++
+		case domain.ImportRowActionUpdate:
+			report.Summary.Update++
+		case domain.ImportRowActionSkip:
+			report.Summary.Skip++
+		case domain.ImportRowActionError:
+			report.Summary.Error++
+		}
+	}
+	return report
+}
+
+// getJob loads a job and maps not found errors
+func (s *ImportService) getJob(ctx context.Context, id uuid.UUID) (*domain.ImportJob, error) {
+	job, err := s.importRepo.GetJobByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrImportJobNotFound
+		}
+		return nil, fmt.Errorf("failed to get import job: %w", err)
+	}
+	return job, nil
+}
+
+// toJobDTO converts a job to a DTO without sample rows
+func (s *ImportService) toJobDTO(job *domain.ImportJob) *domain.ImportJobDTO {
+	dto := &domain.ImportJobDTO{
+		ID:            job.ID,
+		EntityType:    job.EntityType,
+		FileName:      job.FileName,
+		Status:        job.Status,
+		RowCount:      job.RowCount,
+		CompanyID:     job.CompanyID,
+		CreatedByName: job.CreatedByName,
+		CreatedAt:     job.CreatedAt.UTC().Format(time.RFC3339),
+	}
+	if err := json.Unmarshal([]byte(job.Headers), &dto.Headers); err != nil {
+		s.logger.Warn("failed to decode import headers", zap.String("job_id", job.ID.String()), zap.Error(err))
+	}
+	if job.Mapping != "" {
+		_ = json.Unmarshal([]byte(job.Mapping), &dto.Mapping)
+	}
+	if job.Report != "" {
+		var report domain.ImportReportDTO
+		if err := json.Unmarshal([]byte(job.Report), &report); err == nil {
+			dto.Summary = &report.Summary
+		}
+	}
+	if job.CommittedAt != nil {
+		committedAt := job.CommittedAt.UTC().Format(time.RFC3339)
+		dto.CommittedAt = &committedAt
+	}
+	return dto
+}
+
+// decodeImportRows decodes the rows stored on a job
+func decodeImportRows(job *domain.ImportJob) ([][]string, error) {
+	var rows [][]string
+	if err := json.Unmarshal([]byte(job.Rows), &rows); err != nil {
+		return nil, fmt.Errorf("failed to decode import rows: %w", err)
+	}
+	return rows, nil
+}
+
+// validateImportProfile validates a mapping profile request and returns the encoded mapping
+func validateImportProfile(req *domain.SaveImportMappingProfileRequest) (string, error) {
+	if !req.EntityType.IsValid() {
+		return "", ErrInvalidImportEntityType
+	}
+	if strings.TrimSpace(req.Name) == "" {
+		return "", fmt.Errorf("%w: name is required", ErrInvalidImportMapping)
+	}
+	// Headers are not known until a file is uploaded, so only fields are checked
+	if _, err := validateImportMapping(req.EntityType, req.Mapping, nil); err != nil {
+		return "", err
+	}
+
+	mappingJSON, err := json.Marshal(req.Mapping)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode mapping: %w", err)
+	}
+	return string(mappingJSON), nil
+}
+
+// toImportMappingProfileDTO converts a mapping profile to a DTO
+func toImportMappingProfileDTO(profile *domain.ImportMappingProfile) domain.ImportMappingProfileDTO {
+	dto := domain.ImportMappingProfileDTO{
+		ID:            profile.ID,
+		Name:          profile.Name,
+		EntityType:    profile.EntityType,
+		CreatedByName: profile.CreatedByName,
+		CreatedAt:     profile.CreatedAt.UTC().Format(time.RFC3339),
+		UpdatedAt:     profile.UpdatedAt.UTC().Format(time.RFC3339),
+	}
+	_ = json.Unmarshal([]byte(profile.Mapping), &dto.Mapping)
+	return dto
+}
@@ -0,0 +1,425 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/straye-as/relation-api/internal/auth"
+	"github.com/straye-as/relation-api/internal/domain"
+	"github.com/straye-as/relation-api/internal/mapper"
+	"github.com/straye-as/relation-api/internal/postal"
+	"github.com/straye-as/relation-api/internal/repository"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// InquiryAssignmentFacts are the properties of an inquiry that assignment rules match on
+type InquiryAssignmentFacts struct {
+	Industry   domain.CustomerIndustry
+	PostalCode string
+	// County is the county (fylke) of the postal code, empty when unknown
+	County string
+	Value  float64
+}
+
+// InquiryAssignment is the responsible picked for an inquiry and why
+type InquiryAssignment struct {
+	UserID   string
+	UserName string
+	// RuleID is the rule that matched, nil when the company's default responsible was used
+	RuleID *uuid.UUID
+	Reason string
+}
+
+// InquiryAssignmentService manages the per-company rules that pick the responsible for new
+// inquiries, and applies them
+type InquiryAssignmentService struct {
+	ruleRepo       *repository.InquiryAssignmentRuleRepository
+	userRepo       *repository.UserRepository
+	companyService *CompanyService
+	postalRegister *postal.Register
+	logger         *zap.Logger
+}
+
+// NewInquiryAssignmentService creates a new inquiry assignment service
+func NewInquiryAssignmentService(
+	ruleRepo *repository.InquiryAssignmentRuleRepository,
+	userRepo *repository.UserRepository,
+	companyService *CompanyService,
+	logger *zap.Logger,
+) *InquiryAssignmentService {
+	return &InquiryAssignmentService{
+		ruleRepo:       ruleRepo,
+		userRepo:       userRepo,
+		companyService: companyService,
+		postalRegister: postal.Default(),
+		logger:         logger,
+	}
+}
+
+// ============================================================================
+// Rules
+// ============================================================================
+
+// List returns the assignment rules visible to the user in evaluation order
+func (s *InquiryAssignmentService) List(ctx context.Context, companyID *domain.CompanyID) ([]domain.InquiryAssignmentRuleDTO, error) {
+	rules, err := s.ruleRepo.List(ctx, companyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list inquiry assignment rules: %w", err)
+	}
+
+	dtos := make([]domain.InquiryAssignmentRuleDTO, len(rules))
+	for i := range rules {
+		dtos[i] = mapper.ToInquiryAssignmentRuleDTO(&rules[i])
+	}
+	return dtos, nil
+}
+
+// GetByID returns an assignment rule
+func (s *InquiryAssignmentService) GetByID(ctx context.Context, id uuid.UUID) (*domain.InquiryAssignmentRuleDTO, error) {
+	rule, err := s.getRule(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	dto := mapper.ToInquiryAssignmentRuleDTO(rule)
+	return &dto, nil
+}
+
+// Create adds an assignment rule for a company
+func (s *InquiryAssignmentService) Create(ctx context.Context, req *domain.CreateInquiryAssignmentRuleRequest) (*domain.InquiryAssignmentRuleDTO, error) {
+	if err := s.checkManage(ctx, req.CompanyID); err != nil {
+		return nil, err
+	}
+	if !domain.IsValidCompanyID(string(req.CompanyID)) || req.CompanyID == domain.CompanyAll {
+		return nil, fmt.Errorf("%w: unknown company %q", ErrInvalidInquiryAssignmentRule, req.CompanyID)
+	}
+
+	rule := &domain.InquiryAssignmentRule{
+		CompanyID:          req.CompanyID,
+		Name:               req.Name,
+		RuleType:           req.RuleType,
+		Priority:           100,
+		Enabled:            true,
+		Industries:         trimmedStrings(req.Industries),
+		PostalCodePrefixes: trimmedStrings(req.PostalCodePrefixes),
+		Counties:           trimmedStrings(req.Counties),
+		MinValue:           req.MinValue,
+		MaxValue:           req.MaxValue,
+		AssigneeIDs:        trimmedStrings(req.AssigneeIDs),
+	}
+	if req.Priority != nil {
+		rule.Priority = *req.Priority
+	}
+	if err := s.validateRule(ctx, rule); err != nil {
+		return nil, err
+	}
+	if userCtx, ok := auth.FromContext(ctx); ok {
+		rule.CreatedByID = userCtx.UserID.String()
+		rule.CreatedByName = userCtx.DisplayName
+	}
+
+	if err := s.ruleRepo.Create(ctx, rule); err != nil {
+		return nil, fmt.Errorf("failed to create inquiry assignment rule: %w", err)
+	}
+
+	s.logger.Info("inquiry assignment rule created",
+		zap.String("rule_id", rule.ID.String()),
+		zap.String("company_id", string(rule.CompanyID)),
+		zap.String("rule_type", string(rule.RuleType)))
+
+	dto := mapper.ToInquiryAssignmentRuleDTO(rule)
+	return &dto, nil
+}
+
+// Update replaces the conditions and assignees of a rule
+func (s *InquiryAssignmentService) Update(ctx context.Context, id uuid.UUID, req *domain.UpdateInquiryAssignmentRuleRequest) (*domain.InquiryAssignmentRuleDTO, error) {
+	rule, err := s.getRule(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkManage(ctx, rule.CompanyID); err != nil {
+		return nil, err
+	}
+
+	assignees := trimmedStrings(req.AssigneeIDs)
+	if !equalStrings(assignees, rule.AssigneeIDs) {
+		rule.NextIndex = 0
+	}
+	rule.Name = req.Name
+	rule.RuleType = req.RuleType
+	rule.Priority = req.Priority
+	rule.Enabled = req.Enabled
+	rule.Industries = trimmedStrings(req.Industries)
+	rule.PostalCodePrefixes = trimmedStrings(req.PostalCodePrefixes)
+	rule.Counties = trimmedStrings(req.Counties)
+	rule.MinValue = req.MinValue
+	rule.MaxValue = req.MaxValue
+	rule.AssigneeIDs = assignees
+	if err := s.validateRule(ctx, rule); err != nil {
+		return nil, err
+	}
+
+	if err := s.ruleRepo.Update(ctx, rule); err != nil {
+		return nil, fmt.Errorf("failed to update inquiry assignment rule: %w", err)
+	}
+
+	dto := mapper.ToInquiryAssignmentRuleDTO(rule)
+	return &dto, nil
+}
+
+// Delete removes an assignment rule
+func (s *InquiryAssignmentService) Delete(ctx context.Context, id uuid.UUID) error {
+	rule, err := s.getRule(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.checkManage(ctx, rule.CompanyID); err != nil {
+		return err
+	}
+
+	if err := s.ruleRepo.Delete(ctx, id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInquiryAssignmentRuleNotFound
+		}
+		return fmt.Errorf("failed to delete inquiry assignment rule: %w", err)
+	}
+	return nil
+}
+
+// ============================================================================
+// Assignment
+// ============================================================================
+
+// Assign picks the responsible for a new inquiry of a company. The enabled rules are tried in
+// priority order and the first match assigns its next user in turn; when no rule matches, the
+// company's default offer responsible is used. Returns nil when neither gives a responsible.
+func (s *InquiryAssignmentService) Assign(ctx context.Context, companyID domain.CompanyID, customer *domain.Customer, value float64) *InquiryAssignment {
+	facts := InquiryAssignmentFacts{Value: value}
+	if customer != nil {
+		facts.Industry = customer.Industry
+		facts.PostalCode = strings.TrimSpace(customer.PostalCode)
+		if entry, ok := s.postalRegister.Lookup(facts.PostalCode); ok {
+			facts.County = entry.County
+		}
+	}
+
+	rules, err := s.ruleRepo.ListEnabledForCompany(ctx, companyID)
+	if err != nil {
+		s.logger.Warn("failed to load inquiry assignment rules, using default responsible",
+			zap.String("company_id", string(companyID)),
+			zap.Error(err))
+	}
+
+	for i := range rules {
+		rule := &rules[i]
+		if len(rule.AssigneeIDs) == 0 || !MatchesInquiryAssignmentRule(rule, facts) {
+			continue
+		}
+
+		index, err := s.ruleRepo.NextAssigneeIndex(ctx, rule.ID)
+		if err != nil {
+			s.logger.Warn("failed to advance inquiry assignment rotation",
+				zap.String("rule_id", rule.ID.String()),
+				zap.Error(err))
+			continue
+		}
+		turn := index % len(rule.AssigneeIDs)
+		userID := rule.AssigneeIDs[turn]
+		userName := s.userName(ctx, userID)
+
+		reason := fmt.Sprintf("Assigned to %s by rule '%s' (%s)", displayUser(userID, userName), rule.Name, describeRuleMatch(rule, facts))
+		if len(rule.AssigneeIDs) > 1 {
+			reason += fmt.Sprintf(", round-robin turn %d of %d", turn+1, len(rule.AssigneeIDs))
+		}
+		ruleID := rule.ID
+		return &InquiryAssignment{UserID: userID, UserName: userName, RuleID: &ruleID, Reason: reason}
+	}
+
+	if s.companyService == nil {
+		return nil
+	}
+	defaultResponsible := s.companyService.GetDefaultOfferResponsible(ctx, companyID)
+	if defaultResponsible == nil || *defaultResponsible == "" {
+		return nil
+	}
+	userName := s.userName(ctx, *defaultResponsible)
+	reason := fmt.Sprintf("Assigned to %s as the company's default responsible", displayUser(*defaultResponsible, userName))
+	if len(rules) > 0 {
+		reason += " (no assignment rule matched)"
+	}
+	return &InquiryAssignment{UserID: *defaultResponsible, UserName: userName, Reason: reason}
+}
+
+// MatchesInquiryAssignmentRule reports whether a rule's conditions hold for an inquiry.
+// Industry, region and value rules never match inquiries without the property they test.
+func MatchesInquiryAssignmentRule(rule *domain.InquiryAssignmentRule, facts InquiryAssignmentFacts) bool {
+	switch rule.RuleType {
+	case domain.InquiryAssignmentRoundRobin:
+		return true
+	case domain.InquiryAssignmentIndustry:
+		if facts.Industry == "" {
+			return false
+		}
+		for _, industry := range rule.Industries {
+			if strings.EqualFold(industry, string(facts.Industry)) {
+				return true
+			}
+		}
+		return false
+	case domain.InquiryAssignmentPostalRegion:
+		if facts.PostalCode != "" {
+			for _, prefix := range rule.PostalCodePrefixes {
+				if strings.HasPrefix(facts.PostalCode, prefix) {
+					return true
+				}
+			}
+		}
+		if facts.County != "" {
+			for _, county := range rule.Counties {
+				if strings.EqualFold(county, facts.County) {
+					return true
+				}
+			}
+		}
+		return false
+	case domain.InquiryAssignmentValue:
+		if facts.Value <= 0 {
+			return false
+		}
+		if rule.MinValue != nil && facts.Value < *rule.MinValue {
+			return false
+		}
+		if rule.MaxValue != nil && facts.Value >= *rule.MaxValue {
+			return false
+		}
+		return true
+	}
+	return false
+}
+
+// describeRuleMatch explains in the activity log why a rule matched
+func describeRuleMatch(rule *domain.InquiryAssignmentRule, facts InquiryAssignmentFacts) string {
+	switch rule.RuleType {
+	case domain.InquiryAssignmentIndustry:
+		return fmt.Sprintf("customer industry %s", facts.Industry)
+	case domain.InquiryAssignmentPostalRegion:
+		if facts.County != "" {
+			return fmt.Sprintf("customer postal code %s in %s", facts.PostalCode, facts.County)
+		}
+		return fmt.Sprintf("customer postal code %s", facts.PostalCode)
+	case domain.InquiryAssignmentValue:
+		return fmt.Sprintf("estimated value %.0f", facts.Value)
+	}
+	return "all inquiries"
+}
+
+// validateRule checks that a rule has the conditions its type needs and that its assignees exist
+func (s *InquiryAssignmentService) validateRule(ctx context.Context, rule *domain.InquiryAssignmentRule) error {
+	switch rule.RuleType {
+	case domain.InquiryAssignmentRoundRobin:
+	case domain.InquiryAssignmentIndustry:
+		if len(rule.Industries) == 0 {
+			return fmt.Errorf("%w: industry rules need at least one industry", ErrInvalidInquiryAssignmentRule)
+		}
+	case domain.InquiryAssignmentPostalRegion:
+		if len(rule.PostalCodePrefixes) == 0 && len(rule.Counties) == 0 {
+			return fmt.Errorf("%w: postal region rules need postal code prefixes or counties", ErrInvalidInquiryAssignmentRule)
+		}
+	case domain.InquiryAssignmentValue:
+		if rule.MinValue == nil && rule.MaxValue == nil {
+			return fmt.Errorf("%w: value rules need a minimum or maximum value", ErrInvalidInquiryAssignmentRule)
+		}
+		if rule.MinValue != nil && rule.MaxValue != nil && *rule.MinValue >= *rule.MaxValue {
+			return fmt.Errorf("%w: minValue must be less than maxValue", ErrInvalidInquiryAssignmentRule)
+		}
+	default:
+		return fmt.Errorf("%w: unknown rule type %q", ErrInvalidInquiryAssignmentRule, rule.RuleType)
+	}
+
+	if len(rule.AssigneeIDs) == 0 {
+		return fmt.Errorf("%w: at least one assignee is required", ErrInvalidInquiryAssignmentRule)
+	}
+	seen := make(map[string]bool, len(rule.AssigneeIDs))
+	for _, userID := range rule.AssigneeIDs {
+		if seen[userID] {
+			return fmt.Errorf("%w: user %s is listed twice", ErrInvalidInquiryAssignmentRule, userID)
+		}
+		seen[userID] = true
+		if _, err := s.userRepo.GetByStringID(ctx, userID); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("%w: unknown user %s", ErrInvalidInquiryAssignmentRule, userID)
+			}
+			return fmt.Errorf("failed to verify assignee: %w", err)
+		}
+	}
+	return nil
+}
+
+func (s *InquiryAssignmentService) getRule(ctx context.Context, id uuid.UUID) (*domain.InquiryAssignmentRule, error) {
+	rule, err := s.ruleRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInquiryAssignmentRuleNotFound
+		}
+		return nil, fmt.Errorf("failed to get inquiry assignment rule: %w", err)
+	}
+	return rule, nil
+}
+
+// checkManage verifies that the user may configure the inquiry assignment of a company
+func (s *InquiryAssignmentService) checkManage(ctx context.Context, companyID domain.CompanyID) error {
+	userCtx, ok := auth.FromContext(ctx)
+	if !ok {
+		return ErrUnauthorized
+	}
+	if !userCtx.HasAnyRole(domain.RoleCompanyAdmin, domain.RoleSuperAdmin) || !userCtx.CanAccessCompany(companyID) {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// userName returns the display name of a user, or empty when the user is unknown
+func (s *InquiryAssignmentService) userName(ctx context.Context, userID string) string {
+	if s.userRepo == nil {
+		return ""
+	}
+	user, err := s.userRepo.GetByStringID(ctx, userID)
+	if err != nil {
+		return ""
+	}
+	return user.DisplayName
+}
+
+func displayUser(userID, userName string) string {
+	if userName != "" {
+		return userName
+	}
+	return userID
+}
+
+// trimmedStrings trims the values and drops empty ones
+func trimmedStrings(values []string) pq.StringArray {
+	trimmed := pq.StringArray{}
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			trimmed = append(trimmed, value)
+		}
+	}
+	return trimmed
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,586 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/bits"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/straye-as/relation-api/internal/auth"
+	"github.com/straye-as/relation-api/internal/domain"
+	"github.com/straye-as/relation-api/internal/mail"
+	"github.com/straye-as/relation-api/internal/mapper"
+	"github.com/straye-as/relation-api/internal/repository"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// intakeMatchThreshold is the minimum fuzzy name confidence for linking a website inquiry to a customer
+const intakeMatchThreshold = 0.8
+
+// freeMailDomains are email domains that say nothing about the submitter's company
+var freeMailDomains = map[string]bool{
+	"gmail.com": true, "hotmail.com": true, "outlook.com": true, "live.com": true, "live.no": true,
+	"yahoo.com": true, "icloud.com": true, "me.com": true, "online.no": true, "hotmail.no": true,
+}
+
+// IntakeAttachment is a file uploaded with a website inquiry
+type IntakeAttachment struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+// PublicInquirySubmission is a website inquiry with the request details the spam checks need
+type PublicInquirySubmission struct {
+	Request *domain.PublicInquiryRequest
+	// Fields holds every submitted form field by name; the site's honeypot field is read from it
+	Fields      map[string]string
+	Origin      string
+	IPAddress   string
+	UserAgent   string
+	Attachments []IntakeAttachment
+}
+
+// InquiryIntakeOptions configures the public inquiry intake
+type InquiryIntakeOptions struct {
+	// ChallengeTTL is how long a proof-of-work challenge can be solved and submitted
+	ChallengeTTL time.Duration
+	// MaxAttachments is the maximum number of files attached to one submission
+	MaxAttachments int
+}
+
+// InquiryIntakeService receives inquiries from the public forms on the company websites and
+// files them as draft inquiries
+type InquiryIntakeService struct {
+	intakeRepo      *repository.InquiryIntakeRepository
+	inquiryService  *InquiryService
+	customerService *CustomerService
+	fileService     *FileService
+	mailSender      mail.Sender
+	options         InquiryIntakeOptions
+	logger          *zap.Logger
+}
+
+// NewInquiryIntakeService creates a new inquiry intake service
+func NewInquiryIntakeService(
+	intakeRepo *repository.InquiryIntakeRepository,
+	inquiryService *InquiryService,
+	customerService *CustomerService,
+	fileService *FileService,
+	mailSender mail.Sender,
+	options InquiryIntakeOptions,
+	logger *zap.Logger,
+) *InquiryIntakeService {
+	if options.ChallengeTTL <= 0 {
+		options.ChallengeTTL = 10 * time.Minute
+	}
+	if options.MaxAttachments <= 0 {
+		options.MaxAttachments = 5
+	}
+	return &InquiryIntakeService{
+		intakeRepo:      intakeRepo,
+		inquiryService:  inquiryService,
+		customerService: customerService,
+		fileService:     fileService,
+		mailSender:      mailSender,
+		options:         options,
+		logger:          logger,
+	}
+}
+
+// ============================================================================
+// Website forms
+// ============================================================================
+
+// ListSites returns the website forms visible to the user
+func (s *InquiryIntakeService) ListSites(ctx context.Context, companyID *domain.CompanyID) ([]domain.InquiryIntakeSiteDTO, error) {
+	sites, err := s.intakeRepo.ListSites(ctx, companyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list inquiry sites: %w", err)
+	}
+
+	dtos := make([]domain.InquiryIntakeSiteDTO, len(sites))
+	for i := range sites {
+		dtos[i] = mapper.ToInquiryIntakeSiteDTO(&sites[i])
+	}
+	return dtos, nil
+}
+
+// GetSite returns a website form
+func (s *InquiryIntakeService) GetSite(ctx context.Context, id uuid.UUID) (*domain.InquiryIntakeSiteDTO, error) {
+	site, err := s.getSite(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	dto := mapper.ToInquiryIntakeSiteDTO(site)
+	return &dto, nil
+}
+
+// CreateSite registers a website form for a company and generates its site key
+func (s *InquiryIntakeService) CreateSite(ctx context.Context, req *domain.CreateInquiryIntakeSiteRequest) (*domain.InquiryIntakeSiteDTO, error) {
+	if err := s.checkManage(ctx, req.CompanyID); err != nil {
+		return nil, err
+	}
+	if !domain.IsValidCompanyID(string(req.CompanyID)) || req.CompanyID == domain.CompanyAll {
+		return nil, fmt.Errorf("%w: unknown company %q", ErrInvalidInquirySite, req.CompanyID)
+	}
+
+	siteKey, err := randomHex(16)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := randomHex(32)
+	if err != nil {
+		return nil, err
+	}
+
+	site := &domain.InquiryIntakeSite{
+		CompanyID:        req.CompanyID,
+		Name:             req.Name,
+		SiteKey:          "site_" + siteKey,
+		AllowedOrigins:   normalizeOrigins(req.AllowedOrigins),
+		HoneypotField:    strings.TrimSpace(req.HoneypotField),
+		ProofOfWorkBits:  req.ProofOfWorkBits,
+		ChallengeSecret:  secret,
+		SendConfirmation: req.SendConfirmation == nil || *req.SendConfirmation,
+		Enabled:          true,
+	}
+	if userCtx, ok := auth.FromContext(ctx); ok {
+		site.CreatedByID = userCtx.UserID.String()
+		site.CreatedByName = userCtx.DisplayName
+	}
+
+	if err := s.intakeRepo.CreateSite(ctx, site); err != nil {
+		return nil, fmt.Errorf("failed to create inquiry site: %w", err)
+	}
+
+	s.logger.Info("inquiry site created",
+		zap.String("site_id", site.ID.String()),
+		zap.String("company_id", string(site.CompanyID)),
+		zap.String("name", site.Name))
+
+	dto := mapper.ToInquiryIntakeSiteDTO(site)
+	return &dto, nil
+}
+
+// UpdateSite changes the settings of a website form
+func (s *InquiryIntakeService) UpdateSite(ctx context.Context, id uuid.UUID, req *domain.UpdateInquiryIntakeSiteRequest) (*domain.InquiryIntakeSiteDTO, error) {
+	site, err := s.getSite(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkManage(ctx, site.CompanyID); err != nil {
+		return nil, err
+	}
+
+	site.Name = req.Name
+	site.AllowedOrigins = normalizeOrigins(req.AllowedOrigins)
+	site.HoneypotField = strings.TrimSpace(req.HoneypotField)
+	site.ProofOfWorkBits = req.ProofOfWorkBits
+	site.SendConfirmation = req.SendConfirmation
+	site.Enabled = req.Enabled
+	if err := s.intakeRepo.UpdateSite(ctx, site); err != nil {
+		return nil, fmt.Errorf("failed to update inquiry site: %w", err)
+	}
+
+	dto := mapper.ToInquiryIntakeSiteDTO(site)
+	return &dto, nil
+}
+
+// DeleteSite removes a website form; its site key stops working immediately
+func (s *InquiryIntakeService) DeleteSite(ctx context.Context, id uuid.UUID) error {
+	site, err := s.getSite(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.checkManage(ctx, site.CompanyID); err != nil {
+		return err
+	}
+
+	if err := s.intakeRepo.DeleteSite(ctx, id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInquirySiteNotFound
+		}
+		return fmt.Errorf("failed to delete inquiry site: %w", err)
+	}
+	return nil
+}
+
+// ListSubmissions returns the inquiries received through a website form, newest first
+func (s *InquiryIntakeService) ListSubmissions(ctx context.Context, siteID uuid.UUID, includeSpam bool, page, pageSize int) (*domain.PaginatedResponse, error) {
+	if _, err := s.getSite(ctx, siteID); err != nil {
+		return nil, err
+	}
+	if pageSize < 1 {
+		pageSize = 20
+	}
+	if pageSize > 200 {
+		pageSize = 200
+	}
+	if page < 1 {
+		page = 1
+	}
+
+	submissions, total, err := s.intakeRepo.ListSubmissions(ctx, siteID, includeSpam, page, pageSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list inquiry submissions: %w", err)
+	}
+
+	dtos := make([]domain.InquirySubmissionDTO, len(submissions))
+	for i := range submissions {
+		dtos[i] = mapper.ToInquirySubmissionDTO(&submissions[i])
+	}
+
+	totalPages := int((total + int64(pageSize) - 1) / int64(pageSize))
+	return &domain.PaginatedResponse{
+		Data:       dtos,
+		Total:      total,
+		Page:       page,
+		PageSize:   pageSize,
+		TotalPages: totalPages,
+	}, nil
+}
+
+// ============================================================================
+// Public intake
+// ============================================================================
+
+// Challenge returns a proof-of-work challenge for a website form
+func (s *InquiryIntakeService) Challenge(ctx context.Context, siteKey string) (*domain.InquiryChallengeDTO, error) {
+	site, err := s.siteByKey(ctx, siteKey)
+	if err != nil {
+		return nil, err
+	}
+
+	random, err := randomHex(8)
+	if err != nil {
+		return nil, err
+	}
+	expiresAt := time.Now().Add(s.options.ChallengeTTL)
+	payload := strconv.FormatInt(expiresAt.Unix(), 10) + "." + random
+
+	return &domain.InquiryChallengeDTO{
+		Challenge: payload + "." + signChallenge(site, payload),
+		Bits:      site.ProofOfWorkBits,
+		ExpiresAt: expiresAt.UTC().Format(time.RFC3339),
+	}, nil
+}
+
+// Submit files an inquiry from a website form as a draft inquiry for the site's company, linked to
+// the best matching customer, with the uploaded attachments, and sends the submitter a confirmation.
+// Submissions caught by the honeypot are recorded as spam and acknowledged the same way, so bots
+// cannot tell they were dropped.
+func (s *InquiryIntakeService) Submit(ctx context.Context, siteKey string, submission *PublicInquirySubmission) (*domain.PublicInquiryResponseDTO, error) {
+	site, err := s.siteByKey(ctx, siteKey)
+	if err != nil {
+		return nil, err
+	}
+	if !site.AllowsOrigin(submission.Origin) {
+		return nil, ErrInquiryOriginNotAllowed
+	}
+	if len(submission.Attachments) > s.options.MaxAttachments {
+		return nil, fmt.Errorf("%w: at most %d files can be attached", ErrTooManyInquiryAttachments, s.options.MaxAttachments)
+	}
+
+	req := submission.Request
+	record := &domain.InquirySubmission{
+		SiteID:      site.ID,
+		CompanyID:   site.CompanyID,
+		Name:        strings.TrimSpace(req.Name),
+		Email:       strings.TrimSpace(req.Email),
+		Phone:       strings.TrimSpace(req.Phone),
+		CompanyName: strings.TrimSpace(req.CompanyName),
+		OrgNumber:   strings.TrimSpace(req.OrgNumber),
+		Message:     req.Message,
+		IPAddress:   submission.IPAddress,
+		UserAgent:   truncateRunes(submission.UserAgent, 500),
+	}
+
+	// Proof of work is checked before anything is stored, so submissions cost work even when the
+	// honeypot catches them
+	if site.ProofOfWorkBits > 0 {
+		if err := s.verifyProofOfWork(site, req.Challenge, req.Nonce); err != nil {
+			return nil, err
+		}
+		record.PowChallenge = &req.Challenge
+	}
+	record.Spam = site.HoneypotField != "" && strings.TrimSpace(submission.Fields[site.HoneypotField]) != ""
+
+	// Storing the submission claims its challenge: the unique index on pow_challenge lets only
+	// one submission of a solved challenge in, so a replay is rejected before an inquiry is created
+	if err := s.intakeRepo.CreateSubmission(ctx, record); err != nil {
+		if record.PowChallenge != nil && (strings.Contains(err.Error(), "duplicate key") || strings.Contains(err.Error(), "unique constraint")) {
+			return nil, ErrInvalidProofOfWork
+		}
+		return nil, fmt.Errorf("failed to record inquiry submission: %w", err)
+	}
+	if record.Spam {
+		s.logger.Info("website inquiry dropped by honeypot",
+			zap.String("site_id", site.ID.String()),
+			zap.String("ip", submission.IPAddress))
+		return intakeResponse(record), nil
+	}
+
+	inquiry := &domain.Offer{
+		Title:       intakeTitle(req),
+		CompanyID:   site.CompanyID,
+		Description: intakeDescription(record, site),
+	}
+	if customer, confidence := s.matchCustomer(ctx, record); customer != nil {
+		inquiry.CustomerID = &customer.ID
+		inquiry.CustomerName = customer.Name
+		record.CustomerID = &customer.ID
+		record.MatchConfidence = &confidence
+	}
+
+	inquiry, err = s.inquiryService.CreateFromWebsite(ctx, inquiry, record.Name, site.Name)
+	if err != nil {
+		if deleteErr := s.intakeRepo.DeleteSubmission(ctx, record.ID); deleteErr != nil {
+			s.logger.Warn("failed to delete inquiry submission without inquiry",
+				zap.String("submission_id", record.ID.String()),
+				zap.Error(deleteErr))
+		}
+		return nil, err
+	}
+	record.OfferID = &inquiry.ID
+
+	for _, attachment := range submission.Attachments {
+		contentType := attachment.ContentType
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		if _, err := s.fileService.UploadToOffer(ctx, inquiry.ID, attachment.Filename, contentType, bytes.NewReader(attachment.Data), site.CompanyID); err != nil {
+			s.logger.Warn("failed to store website inquiry attachment",
+				zap.String("filename", attachment.Filename),
+				zap.Error(err))
+			continue
+		}
+		record.AttachmentCount++
+	}
+
+	if err := s.intakeRepo.CompleteSubmission(ctx, record); err != nil {
+		return nil, fmt.Errorf("failed to record inquiry submission: %w", err)
+	}
+
+	s.logger.Info("website inquiry received",
+		zap.String("site_id", site.ID.String()),
+		zap.String("offer_id", inquiry.ID.String()),
+		zap.Bool("customer_matched", record.CustomerID != nil),
+		zap.Int("attachments", record.AttachmentCount))
+
+	if site.SendConfirmation {
+		s.sendConfirmation(ctx, record, inquiry)
+	}
+
+	return intakeResponse(record), nil
+}
+
+// verifyProofOfWork checks that the challenge was issued for the site and has not expired,
+// and that SHA-256(challenge:nonce) starts with the required number of zero bits.
+// A challenge is claimed, so that it can only be used once, when the submission is stored.
+func (s *InquiryIntakeService) verifyProofOfWork(site *domain.InquiryIntakeSite, challenge, nonce string) error {
+	parts := strings.Split(challenge, ".")
+	if len(parts) != 3 || nonce == "" || len(nonce) > 64 {
+		return ErrInvalidProofOfWork
+	}
+	payload := parts[0] + "." + parts[1]
+	if !hmac.Equal([]byte(parts[2]), []byte(signChallenge(site, payload))) {
+		return ErrInvalidProofOfWork
+	}
+	expiresAt, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil || time.Now().Unix() > expiresAt {
+		return ErrInvalidProofOfWork
+	}
+	if LeadingZeroBits(sha256.Sum256([]byte(challenge+":"+nonce))) < site.ProofOfWorkBits {
+		return ErrInvalidProofOfWork
+	}
+	return nil
+}
+
+// matchCustomer finds the customer named in the submission, or by the domain of the submitter's
+// email address when no company is given
+func (s *InquiryIntakeService) matchCustomer(ctx context.Context, record *domain.InquirySubmission) (*domain.CustomerMinimalDTO, float64) {
+	query := record.CompanyName
+	if query == "" {
+		at := strings.LastIndex(record.Email, "@")
+		if at < 0 || freeMailDomains[strings.ToLower(record.Email[at+1:])] {
+			return nil, 0
+		}
+		query = record.Email
+	}
+	// "all" lists every customer in FuzzySearchBestMatch and is never a real name
+	if strings.EqualFold(strings.TrimSpace(query), "all") {
+		return nil, 0
+	}
+
+	result, err := s.customerService.FuzzySearchBestMatch(ctx, query)
+	if err != nil {
+		s.logger.Warn("failed to match website inquiry to a customer", zap.Error(err))
+		return nil, 0
+	}
+	if !result.Found || result.Customer == nil || result.Confidence < intakeMatchThreshold {
+		return nil, 0
+	}
+	return result.Customer, result.Confidence
+}
+
+// sendConfirmation emails the submitter that the inquiry was received. Failures are logged; the
+// inquiry is already filed.
+func (s *InquiryIntakeService) sendConfirmation(ctx context.Context, record *domain.InquirySubmission, inquiry *domain.Offer) {
+	if !s.mailSender.Enabled() {
+		return
+	}
+
+	// The address is not verified, so the message carries nothing the submitter wrote; otherwise the
+	// form could be used to send arbitrary text to arbitrary inboxes
+	body := fmt.Sprintf("Hei,\n\nTakk for henvendelsen. Vi har mottatt den og tar kontakt så snart som mulig.\n\n"+
+		"Referanse: %s\n\nMed vennlig hilsen\n%s",
+		record.ID.String(), companyDisplayName(inquiry.CompanyID))
+
+	err := s.mailSender.Send(ctx, &mail.Message{
+		To:      record.Email,
+		Subject: "Vi har mottatt henvendelsen din",
+		Body:    body,
+	})
+	if err != nil {
+		s.logger.Warn("failed to send website inquiry confirmation",
+			zap.String("submission_id", record.ID.String()),
+			zap.Error(err))
+		return
+	}
+
+	sentAt := time.Now()
+	if err := s.intakeRepo.MarkConfirmationSent(ctx, record.ID, sentAt); err != nil {
+		s.logger.Warn("failed to record website inquiry confirmation", zap.Error(err))
+	}
+	record.ConfirmationSentAt = &sentAt
+}
+
+func (s *InquiryIntakeService) siteByKey(ctx context.Context, siteKey string) (*domain.InquiryIntakeSite, error) {
+	site, err := s.intakeRepo.GetSiteByKey(ctx, siteKey)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInquirySiteNotFound
+		}
+		return nil, fmt.Errorf("failed to get inquiry site: %w", err)
+	}
+	return site, nil
+}
+
+func (s *InquiryIntakeService) getSite(ctx context.Context, id uuid.UUID) (*domain.InquiryIntakeSite, error) {
+	site, err := s.intakeRepo.GetSiteByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInquirySiteNotFound
+		}
+		return nil, fmt.Errorf("failed to get inquiry site: %w", err)
+	}
+	return site, nil
+}
+
+// checkManage verifies that the user may configure the website forms of a company
+func (s *InquiryIntakeService) checkManage(ctx context.Context, companyID domain.CompanyID) error {
+	userCtx, ok := auth.FromContext(ctx)
+	if !ok {
+		return ErrUnauthorized
+	}
+	if !userCtx.HasAnyRole(domain.RoleCompanyAdmin, domain.RoleSuperAdmin) || !userCtx.CanAccessCompany(companyID) {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// LeadingZeroBits counts the zero bits at the start of a hash
+func LeadingZeroBits(hash [32]byte) int {
+	count := 0
+	for _, b := range hash {
+		if b != 0 {
+			return count + bits.LeadingZeros8(b)
+		}
+		count += 8
+	}
+	return count
+}
+
+// signChallenge signs a challenge payload with the site's secret
+func signChallenge(site *domain.InquiryIntakeSite, payload string) string {
+	mac := hmac.New(sha256.New, []byte(site.ChallengeSecret))
+	mac.Write([]byte(site.SiteKey + "." + payload))
+	return hex.EncodeToString(mac.Sum(nil))[:32]
+}
+
+func intakeTitle(req *domain.PublicInquiryRequest) string {
+	if title := strings.TrimSpace(req.Title); title != "" {
+		return title
+	}
+	if req.CompanyName != "" {
+		return truncateRunes("Henvendelse fra "+strings.TrimSpace(req.CompanyName), 200)
+	}
+	return truncateRunes("Henvendelse fra "+strings.TrimSpace(req.Name), 200)
+}
+
+// intakeDescription is the inquiry description: the message followed by the submitter's contact details
+func intakeDescription(record *domain.InquirySubmission, site *domain.InquiryIntakeSite) string {
+	var b strings.Builder
+	b.WriteString(strings.TrimSpace(record.Message))
+	b.WriteString("\n\nKontaktinformasjon\n")
+	b.WriteString("Navn: " + record.Name + "\n")
+	b.WriteString("E-post: " + record.Email + "\n")
+	if record.Phone != "" {
+		b.WriteString("Telefon: " + record.Phone + "\n")
+	}
+	if record.CompanyName != "" {
+		b.WriteString("Firma: " + record.CompanyName + "\n")
+	}
+	if record.OrgNumber != "" {
+		b.WriteString("Org.nr.: " + record.OrgNumber + "\n")
+	}
+	b.WriteString("Sendt via: " + site.Name)
+	return b.String()
+}
+
+func intakeResponse(record *domain.InquirySubmission) *domain.PublicInquiryResponseDTO {
+	return &domain.PublicInquiryResponseDTO{
+		Reference: record.ID.String(),
+		Message:   "Takk for henvendelsen. Vi tar kontakt så snart som mulig.",
+	}
+}
+
+// companyDisplayName returns the name a company signs emails with
+func companyDisplayName(companyID domain.CompanyID) string {
+	if companyID == domain.CompanyGruppen {
+		return "Straye"
+	}
+	return "Straye " + strings.ToUpper(string(companyID[:1])) + string(companyID[1:])
+}
+
+// normalizeOrigins trims origins and their trailing slashes, dropping empty entries
+func normalizeOrigins(origins []string) pq.StringArray {
+	normalized := pq.StringArray{}
+	for _, origin := range origins {
+		if origin = strings.TrimSuffix(strings.TrimSpace(origin), "/"); origin != "" {
+			normalized = append(normalized, origin)
+		}
+	}
+	return normalized
+}
+
+// randomHex returns n random bytes as hex
+func randomHex(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate random value: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/straye-as/relation-api/internal/auth"
@@ -22,8 +23,10 @@ type InquiryService struct {
 	activityRepo   *repository.ActivityRepository
 	userRepo       *repository.UserRepository
 	companyService *CompanyService
-	logger         *zap.Logger
-	db             *gorm.DB
+	// assignmentService picks the responsible for new inquiries; nil assigns the company default
+	assignmentService *InquiryAssignmentService
+	logger            *zap.Logger
+	db                *gorm.DB
 }
 
 // NewInquiryService creates a new InquiryService
@@ -47,15 +50,22 @@ func NewInquiryService(
 	}
 }
 
+// SetAssignmentService sets the service that applies the companies' inquiry assignment rules
+func (s *InquiryService) SetAssignmentService(assignmentService *InquiryAssignmentService) {
+	s.assignmentService = assignmentService
+}
+
 // Create creates a new inquiry (offer in draft phase)
 func (s *InquiryService) Create(ctx context.Context, req *domain.CreateInquiryRequest) (*domain.OfferDTO, error) {
+	var customer *domain.Customer
 	var customerID *uuid.UUID
 	var customerName string
 	companyID := domain.CompanyGruppen
 
 	// If customerId is provided, verify customer exists
 	if req.CustomerID != nil {
-		customer, err := s.customerRepo.GetByID(ctx, *req.CustomerID)
+		var err error
+		customer, err = s.customerRepo.GetByID(ctx, *req.CustomerID)
 		if err != nil {
 			if errors.Is(err, gorm.ErrRecordNotFound) {
 				return nil, ErrCustomerNotFound
@@ -91,7 +101,11 @@ func (s *InquiryService) Create(ctx context.Context, req *domain.CreateInquiryRe
 		Status:       domain.OfferStatusActive,
 		Description:  req.Description,
 		DueDate:      req.DueDate,
-		// ResponsibleUserID and OfferNumber are NOT set - will be set on conversion
+		// OfferNumber is NOT set - will be set on conversion
+	}
+
+	if req.EstimatedValue != nil {
+		inquiry.Value = *req.EstimatedValue
 	}
 
 	// Set responsible if provided - resolve email to user ID and get display name
@@ -103,6 +117,18 @@ func (s *InquiryService) Create(ctx context.Context, req *domain.CreateInquiryRe
 		}
 	}
 
+	// Otherwise the company's assignment rules pick the responsible
+	var assignment *InquiryAssignment
+	if inquiry.ResponsibleUserID == "" && s.assignmentService != nil {
+		assignment = s.assignmentService.Assign(ctx, companyID, customer, inquiry.Value)
+		if assignment != nil {
+			inquiry.ResponsibleUserID = assignment.UserID
+			inquiry.ResponsibleUserName = assignment.UserName
+		}
+	}
+
+	inquiry.InquirySLADueAt = s.slaDueAt(ctx, companyID, time.Now())
+
 	if err := s.offerRepo.Create(ctx, inquiry); err != nil {
 		return nil, fmt.Errorf("failed to create inquiry: %w", err)
 	}
@@ -119,11 +145,65 @@ func (s *InquiryService) Create(ctx context.Context, req *domain.CreateInquiryRe
 		activityMessage = fmt.Sprintf("Inquiry '%s' was created for customer %s", inquiry.Title, customerName)
 	}
 	s.logActivity(ctx, inquiry.ID, "Inquiry created", activityMessage)
+	if assignment != nil {
+		s.logActivity(ctx, inquiry.ID, "Inquiry assigned", assignment.Reason)
+	}
 
 	dto := mapper.ToOfferDTO(inquiry)
 	return &dto, nil
 }
 
+// CreateFromWebsite creates an inquiry submitted through a public website form. There is no
+// signed-in user, so the company's default offer responsible is assigned and the activity is
+// logged on behalf of the submitter.
+func (s *InquiryService) CreateFromWebsite(ctx context.Context, inquiry *domain.Offer, submitterName, siteName string) (*domain.Offer, error) {
+	inquiry.Phase = domain.OfferPhaseDraft
+	inquiry.Status = domain.OfferStatusActive
+	inquiry.Probability = 0
+	inquiry.Value = 0
+
+	var assignmentReason string
+	if s.assignmentService != nil {
+		var customer *domain.Customer
+		if inquiry.CustomerID != nil {
+			customer, _ = s.customerRepo.GetByID(ctx, *inquiry.CustomerID)
+		}
+		if assignment := s.assignmentService.Assign(ctx, inquiry.CompanyID, customer, inquiry.Value); assignment != nil {
+			inquiry.ResponsibleUserID = assignment.UserID
+			inquiry.ResponsibleUserName = assignment.UserName
+			assignmentReason = assignment.Reason
+		}
+	} else if defaultResponsible := s.companyService.GetDefaultOfferResponsible(ctx, inquiry.CompanyID); defaultResponsible != nil {
+		userID, userName := s.resolveResponsible(ctx, *defaultResponsible)
+		inquiry.ResponsibleUserID = userID
+		inquiry.ResponsibleUserName = userName
+	}
+
+	inquiry.InquirySLADueAt = s.slaDueAt(ctx, inquiry.CompanyID, time.Now())
+
+	if err := s.offerRepo.Create(ctx, inquiry); err != nil {
+		return nil, fmt.Errorf("failed to create inquiry: %w", err)
+	}
+
+	body := fmt.Sprintf("Inquiry '%s' was submitted by %s through %s", inquiry.Title, submitterName, siteName)
+	if assignmentReason != "" {
+		body += ". " + assignmentReason
+	}
+	activity := &domain.Activity{
+		TargetType:  domain.ActivityTargetOffer,
+		TargetID:    inquiry.ID,
+		TargetName:  inquiry.Title,
+		Title:       "Inquiry received",
+		Body:        body,
+		CreatorName: submitterName,
+	}
+	if err := s.activityRepo.Create(ctx, activity); err != nil {
+		s.logger.Warn("failed to log activity", zap.Error(err))
+	}
+
+	return inquiry, nil
+}
+
 // GetByID retrieves an inquiry by ID
 func (s *InquiryService) GetByID(ctx context.Context, id uuid.UUID) (*domain.OfferDTO, error) {
 	offer, err := s.offerRepo.GetByID(ctx, id)
@@ -230,6 +310,12 @@ func (s *InquiryService) UpdateCompany(ctx context.Context, id uuid.UUID, req *d
 	updates := map[string]interface{}{
 		"company_id": req.CompanyID,
 	}
+	// The new company's SLA applies from when the inquiry was received
+	if inquiry.InquirySLADueAt != nil {
+		if dueAt := s.slaDueAt(ctx, req.CompanyID, inquiry.CreatedAt); dueAt != nil {
+			updates["inquiry_sla_due_at"] = *dueAt
+		}
+	}
 
 	if err := s.offerRepo.UpdateFields(ctx, id, updates); err != nil {
 		return nil, fmt.Errorf("failed to update inquiry company: %w", err)
@@ -252,7 +338,7 @@ func (s *InquiryService) UpdateCompany(ctx context.Context, id uuid.UUID, req *d
 // Convert converts an inquiry to an offer (phase=in_progress)
 // Logic:
 // - responsibleUserId only: infer company from user's department/companyId
-// - companyId only: use company's defaultOfferResponsibleId
+// - companyId only: keep the inquiry's responsible, or if it has none or the company changes use the company's assignment rules, falling back to its defaultOfferResponsibleId
 // - both provided: use both directly
 func (s *InquiryService) Convert(ctx context.Context, id uuid.UUID, req *domain.ConvertInquiryRequest) (*domain.ConvertInquiryResponse, error) {
 	inquiry, err := s.offerRepo.GetByID(ctx, id)
@@ -268,8 +354,9 @@ func (s *InquiryService) Convert(ctx context.Context, id uuid.UUID, req *domain.
 		return nil, ErrNotAnInquiry
 	}
 
-	var responsibleUserID string
+	var responsibleUserID, responsibleUserName string
 	var companyID domain.CompanyID
+	var assignment *InquiryAssignment
 
 	// Determine responsible user and company
 	if req.ResponsibleUserID != nil && *req.ResponsibleUserID != "" {
@@ -280,19 +367,24 @@ func (s *InquiryService) Convert(ctx context.Context, id uuid.UUID, req *domain.
 		} else {
 			companyID = inquiry.CompanyID
 		}
-	} else if req.CompanyID != nil && *req.CompanyID != "" {
-		companyID = *req.CompanyID
-		// Get default responsible user from company
-		if s.companyService != nil {
-			defaultResponsible := s.companyService.GetDefaultOfferResponsible(ctx, companyID)
-			if defaultResponsible != nil && *defaultResponsible != "" {
-				responsibleUserID = *defaultResponsible
-			}
-		}
 	} else {
-		// Neither provided - try to use existing company's default
-		companyID = inquiry.CompanyID
-		if s.companyService != nil {
+		// Use the given company, or the inquiry's company. The inquiry keeps its responsible unless
+		// it has none or moves to another company, in which case the company's assignment rules or
+		// default responsible pick one.
+		if req.CompanyID != nil && *req.CompanyID != "" {
+			companyID = *req.CompanyID
+		} else {
+			companyID = inquiry.CompanyID
+		}
+		if inquiry.ResponsibleUserID != "" && companyID == inquiry.CompanyID {
+			responsibleUserID = inquiry.ResponsibleUserID
+			responsibleUserName = inquiry.ResponsibleUserName
+		} else if s.assignmentService != nil {
+			assignment = s.assignmentService.Assign(ctx, companyID, inquiry.Customer, inquiry.Value)
+			if assignment != nil {
+				responsibleUserID = assignment.UserID
+			}
+		} else if s.companyService != nil {
 			defaultResponsible := s.companyService.GetDefaultOfferResponsible(ctx, companyID)
 			if defaultResponsible != nil && *defaultResponsible != "" {
 				responsibleUserID = *defaultResponsible
@@ -306,7 +398,6 @@ func (s *InquiryService) Convert(ctx context.Context, id uuid.UUID, req *domain.
 	}
 
 	// Get responsible user's display name
-	var responsibleUserName string
 	if s.userRepo != nil {
 		user, err := s.userRepo.GetByStringID(ctx, responsibleUserID)
 		if err == nil && user != nil {
@@ -341,6 +432,9 @@ func (s *InquiryService) Convert(ctx context.Context, id uuid.UUID, req *domain.
 		"responsible_user_name": responsibleUserName,
 		"offer_number":          offerNumber,
 	}
+	if inquiry.InquiryRespondedAt == nil {
+		updates["inquiry_responded_at"] = time.Now().UTC()
+	}
 
 	if err := s.offerRepo.UpdateFields(ctx, id, updates); err != nil {
 		return nil, fmt.Errorf("failed to convert inquiry: %w", err)
@@ -357,11 +451,15 @@ func (s *InquiryService) Convert(ctx context.Context, id uuid.UUID, req *domain.
 		fmt.Sprintf("Inquiry '%s' was converted to offer %s (responsible: %s)",
 			offer.Title, offerNumber, responsibleUserID))
 
+	response := &domain.ConvertInquiryResponse{OfferNumber: offerNumber}
+	if assignment != nil {
+		s.logActivity(ctx, offer.ID, "Inquiry assigned", assignment.Reason)
+		response.AssignmentReason = assignment.Reason
+	}
+
 	dto := mapper.ToOfferDTO(offer)
-	return &domain.ConvertInquiryResponse{
-		Offer:       &dto,
-		OfferNumber: offerNumber,
-	}, nil
+	response.Offer = &dto
+	return response, nil
 }
 
 // resolveResponsible resolves a responsible identifier to a user ID and display name.
@@ -414,6 +512,16 @@ func (s *InquiryService) resolveResponsible(ctx context.Context, responsible str
 	return "", ""
 }
 
+// slaDueAt returns the response deadline of an inquiry received at receivedAt under the company's SLA.
+// The deadline is computed in Oslo time but returned in UTC, as inquiry_sla_due_at has no time zone.
+func (s *InquiryService) slaDueAt(ctx context.Context, companyID domain.CompanyID, receivedAt time.Time) *time.Time {
+	if s.companyService == nil {
+		return nil
+	}
+	dueAt := s.companyService.GetInquirySLAPolicy(ctx, companyID).DueAt(receivedAt).UTC()
+	return &dueAt
+}
+
 // logActivity creates an activity log entry for an offer
 func (s *InquiryService) logActivity(ctx context.Context, offerID uuid.UUID, title, body string) {
 	s.logActivityOnTarget(ctx, domain.ActivityTargetOffer, offerID, title, body)
@@ -0,0 +1,342 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/straye-as/relation-api/internal/domain"
+	"github.com/straye-as/relation-api/internal/repository"
+	"github.com/straye-as/relation-api/internal/workcalendar"
+	"go.uber.org/zap"
+)
+
+// inquirySLADefaultReportDays is the period of the SLA report when no start date is given
+const inquirySLADefaultReportDays = 30
+
+// InquirySLAPolicy is a company's promised response time to inquiries and the business hours it is measured in
+type InquirySLAPolicy struct {
+	Calendar *workcalendar.Calendar
+	// Target is the business hours from an inquiry is received until the customer must have had a response
+	Target time.Duration
+	// Warning is the business hours before the deadline the responsible user is warned
+	Warning time.Duration
+}
+
+// DefaultInquirySLAPolicy is two working days of 08:00-16:00 with a warning half a day before the deadline
+func DefaultInquirySLAPolicy() InquirySLAPolicy {
+	calendar, _ := workcalendar.New("08:00", "16:00")
+	return InquirySLAPolicy{Calendar: calendar, Target: 16 * time.Hour, Warning: 4 * time.Hour}
+}
+
+// DueAt returns the response deadline of an inquiry received at the given time
+func (p InquirySLAPolicy) DueAt(receivedAt time.Time) time.Time {
+	return p.Calendar.Add(receivedAt, p.Target)
+}
+
+// InquirySLAService warns responsible users about inquiries approaching their response deadline
+// and reports SLA compliance per company and user
+type InquirySLAService struct {
+	offerRepo        *repository.OfferRepository
+	notificationRepo *repository.NotificationRepository
+	companyService   *CompanyService
+	logger           *zap.Logger
+	now              func() time.Time
+}
+
+// NewInquirySLAService creates a new inquiry SLA service
+func NewInquirySLAService(
+	offerRepo *repository.OfferRepository,
+	notificationRepo *repository.NotificationRepository,
+	companyService *CompanyService,
+	logger *zap.Logger,
+) *InquirySLAService {
+	return &InquirySLAService{
+		offerRepo:        offerRepo,
+		notificationRepo: notificationRepo,
+		companyService:   companyService,
+		logger:           logger,
+		now:              time.Now,
+	}
+}
+
+// inquirySLAPolicyCache loads each company's SLA policy once per run
+type inquirySLAPolicyCache struct {
+	companyService *CompanyService
+	policies       map[domain.CompanyID]InquirySLAPolicy
+}
+
+func (c *inquirySLAPolicyCache) get(ctx context.Context, companyID domain.CompanyID) InquirySLAPolicy {
+	if policy, ok := c.policies[companyID]; ok {
+		return policy
+	}
+	policy := c.companyService.GetInquirySLAPolicy(ctx, companyID)
+	c.policies[companyID] = policy
+	return policy
+}
+
+func (s *InquirySLAService) policies() *inquirySLAPolicyCache {
+	return &inquirySLAPolicyCache{companyService: s.companyService, policies: make(map[domain.CompanyID]InquirySLAPolicy)}
+}
+
+// SendWarnings notifies the responsible user of each unanswered inquiry that is within its company's
+// warning window of the deadline, or already past it. Each inquiry is warned about once.
+// Returns the number of unanswered inquiries checked and the number of warnings sent.
+func (s *InquirySLAService) SendWarnings(ctx context.Context) (checked int, warned int, err error) {
+	inquiries, err := s.offerRepo.ListUnansweredInquiries(ctx)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to list unanswered inquiries: %w", err)
+	}
+
+	now := s.now()
+	policies := s.policies()
+	for _, inquiry := range inquiries {
+		policy := policies.get(ctx, inquiry.CompanyID)
+		remaining := policy.Calendar.Between(now, *inquiry.InquirySLADueAt)
+		if remaining > policy.Warning {
+			continue
+		}
+
+		userID, err := uuid.Parse(inquiry.ResponsibleUserID)
+		if err != nil {
+			continue
+		}
+		inquiryID := inquiry.ID
+		notification := &domain.Notification{
+			UserID:     userID,
+			Type:       string(domain.NotificationTypeInquirySLA),
+			Title:      "Henvendelse må besvares",
+			Message:    inquirySLAWarningMessage(&inquiry, remaining),
+			EntityID:   &inquiryID,
+			EntityType: "offer",
+		}
+		if err := s.notificationRepo.Create(ctx, notification); err != nil {
+			s.logger.Warn("failed to create inquiry SLA warning",
+				zap.Error(err),
+				zap.String("offer_id", inquiry.ID.String()))
+			continue
+		}
+		if err := s.offerRepo.MarkInquirySLAWarned(ctx, inquiry.ID, now.UTC()); err != nil {
+			s.logger.Warn("failed to mark inquiry SLA warning as sent",
+				zap.Error(err),
+				zap.String("offer_id", inquiry.ID.String()))
+		}
+		warned++
+	}
+
+	return len(inquiries), warned, nil
+}
+
+// inquirySLAWarningMessage tells the responsible user when the customer must have had a response
+func inquirySLAWarningMessage(inquiry *domain.Offer, remaining time.Duration) string {
+	due := inquiry.InquirySLADueAt.In(workcalendar.Oslo).Format("02.01. kl. 15:04")
+	if remaining <= 0 {
+		return truncateRunes(fmt.Sprintf("Fristen for å svare på henvendelsen '%s' gikk ut %s", inquiry.Title, due), 500)
+	}
+	return truncateRunes(fmt.Sprintf("Henvendelsen '%s' må besvares innen %s (%s arbeidstid igjen)",
+		inquiry.Title, due, formatBusinessHours(remaining)), 500)
+}
+
+// formatBusinessHours formats a duration as hours and minutes, e.g. "3 t 30 min"
+func formatBusinessHours(d time.Duration) string {
+	d = d.Round(time.Minute)
+	hours := int(d / time.Hour)
+	minutes := int((d % time.Hour) / time.Minute)
+	switch {
+	case hours == 0:
+		return fmt.Sprintf("%d min", minutes)
+	case minutes == 0:
+		return fmt.Sprintf("%d t", hours)
+	default:
+		return fmt.Sprintf("%d t %d min", hours, minutes)
+	}
+}
+
+// InquirySLAOutcome is where an inquiry stands against its response deadline
+type InquirySLAOutcome string
+
+const (
+	// InquirySLAOpen is an unanswered inquiry whose deadline has not been reached
+	InquirySLAOpen InquirySLAOutcome = "open"
+	// InquirySLAMet is an inquiry answered before its deadline
+	InquirySLAMet InquirySLAOutcome = "met"
+	// InquirySLABreached is an inquiry answered after its deadline
+	InquirySLABreached InquirySLAOutcome = "breached"
+	// InquirySLAOverdue is an unanswered inquiry past its deadline
+	InquirySLAOverdue InquirySLAOutcome = "overdue"
+)
+
+// ClassifyInquirySLA returns the outcome at now of an inquiry answered at respondedAt (nil while unanswered)
+func ClassifyInquirySLA(dueAt time.Time, respondedAt *time.Time, now time.Time) InquirySLAOutcome {
+	switch {
+	case respondedAt != nil && !respondedAt.After(dueAt):
+		return InquirySLAMet
+	case respondedAt != nil:
+		return InquirySLABreached
+	case now.After(dueAt):
+		return InquirySLAOverdue
+	default:
+		return InquirySLAOpen
+	}
+}
+
+// inquirySLAStats accumulates SLA outcomes and response times for one group of inquiries
+type inquirySLAStats struct {
+	dto           domain.InquirySLAStatsDTO
+	responseHours []float64
+}
+
+func (a *inquirySLAStats) add(outcome InquirySLAOutcome, responseHours float64) {
+	a.dto.Total++
+	switch outcome {
+	case InquirySLAMet:
+		a.dto.Met++
+	case InquirySLABreached:
+		a.dto.Breached++
+	case InquirySLAOverdue:
+		a.dto.Breached++
+		a.dto.Overdue++
+	case InquirySLAOpen:
+		a.dto.Open++
+	}
+	if outcome == InquirySLAMet || outcome == InquirySLABreached {
+		a.responseHours = append(a.responseHours, responseHours)
+	}
+}
+
+func (a *inquirySLAStats) result() domain.InquirySLAStatsDTO {
+	dto := a.dto
+	if decided := dto.Met + dto.Breached; decided > 0 {
+		compliance := roundPercent(float64(dto.Met) / float64(decided))
+		dto.CompliancePercent = &compliance
+	}
+	if n := len(a.responseHours); n > 0 {
+		sort.Float64s(a.responseHours)
+		var sum float64
+		for _, h := range a.responseHours {
+			sum += h
+		}
+		avg := roundHours(sum / float64(n))
+		median := a.responseHours[n/2]
+		if n%2 == 0 {
+			median = (a.responseHours[n/2-1] + a.responseHours[n/2]) / 2
+		}
+		median = roundHours(median)
+		dto.AvgResponseHours = &avg
+		dto.MedianResponseHours = &median
+	}
+	return dto
+}
+
+// Report returns the SLA compliance of inquiries received between from and to (Oslo dates, inclusive),
+// overall, per company and per responsible user. Without from the report covers the last 30 days.
+func (s *InquirySLAService) Report(ctx context.Context, from, to *time.Time, companyID *domain.CompanyID) (*domain.InquirySLAReportDTO, error) {
+	now := s.now()
+	end := dateInOslo(now)
+	if to != nil {
+		end = dateInOslo(*to)
+	}
+	start := end.AddDate(0, 0, -inquirySLADefaultReportDays+1)
+	if from != nil {
+		start = dateInOslo(*from)
+	}
+	if end.Before(start) {
+		return nil, ErrInvalidDateRange
+	}
+
+	// created_at has no time zone and holds UTC, so the Oslo-midnight bounds are passed in UTC
+	records, err := s.offerRepo.ListInquirySLARecords(ctx, start.UTC(), end.AddDate(0, 0, 1).UTC(), companyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list inquiry SLA records: %w", err)
+	}
+
+	policies := s.policies()
+	overall := &inquirySLAStats{}
+	companies := make(map[domain.CompanyID]*inquirySLAStats)
+	users := make(map[string]*inquirySLAStats)
+	userNames := make(map[string]string)
+	overdue := make([]domain.InquirySLAItemDTO, 0)
+
+	for _, record := range records {
+		calendar := policies.get(ctx, record.CompanyID).Calendar
+		outcome := ClassifyInquirySLA(record.DueAt, record.RespondedAt, now)
+		var responseHours float64
+		if record.RespondedAt != nil {
+			responseHours = calendar.Between(record.CreatedAt, *record.RespondedAt).Hours()
+		}
+
+		overall.add(outcome, responseHours)
+		if companies[record.CompanyID] == nil {
+			companies[record.CompanyID] = &inquirySLAStats{}
+		}
+		companies[record.CompanyID].add(outcome, responseHours)
+		if users[record.ResponsibleUserID] == nil {
+			users[record.ResponsibleUserID] = &inquirySLAStats{}
+		}
+		users[record.ResponsibleUserID].add(outcome, responseHours)
+		if record.ResponsibleUserName != "" {
+			userNames[record.ResponsibleUserID] = record.ResponsibleUserName
+		}
+
+		if outcome == InquirySLAOverdue {
+			overdue = append(overdue, domain.InquirySLAItemDTO{
+				ID:                  record.ID,
+				Title:               record.Title,
+				CompanyID:           record.CompanyID,
+				ResponsibleUserID:   record.ResponsibleUserID,
+				ResponsibleUserName: record.ResponsibleUserName,
+				ReceivedAt:          record.CreatedAt.UTC().Format(time.RFC3339),
+				DueAt:               record.DueAt.UTC().Format(time.RFC3339),
+				OverdueHours:        roundHours(calendar.Between(record.DueAt, now).Hours()),
+			})
+		}
+	}
+
+	report := &domain.InquirySLAReportDTO{
+		From:        start.Format("2006-01-02"),
+		To:          end.Format("2006-01-02"),
+		GeneratedAt: now.UTC().Format(time.RFC3339),
+		Overall:     overall.result(),
+		Companies:   make([]domain.InquirySLACompanyDTO, 0, len(companies)),
+		Users:       make([]domain.InquirySLAUserDTO, 0, len(users)),
+		Overdue:     overdue,
+	}
+	for id, stats := range companies {
+		report.Companies = append(report.Companies, domain.InquirySLACompanyDTO{
+			CompanyID:          id,
+			SLAHours:           int(policies.get(ctx, id).Target.Hours()),
+			InquirySLAStatsDTO: stats.result(),
+		})
+	}
+	sort.Slice(report.Companies, func(i, j int) bool { return report.Companies[i].CompanyID < report.Companies[j].CompanyID })
+	for id, stats := range users {
+		report.Users = append(report.Users, domain.InquirySLAUserDTO{
+			UserID:             id,
+			UserName:           userNames[id],
+			InquirySLAStatsDTO: stats.result(),
+		})
+	}
+	sort.Slice(report.Users, func(i, j int) bool {
+		if report.Users[i].Total != report.Users[j].Total {
+			return report.Users[i].Total > report.Users[j].Total
+		}
+		return report.Users[i].UserName < report.Users[j].UserName
+	})
+	sort.Slice(report.Overdue, func(i, j int) bool { return report.Overdue[i].OverdueHours > report.Overdue[j].OverdueHours })
+
+	return report, nil
+}
+
+// dateInOslo returns midnight of the Oslo date of t
+func dateInOslo(t time.Time) time.Time {
+	y, m, d := t.In(workcalendar.Oslo).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, workcalendar.Oslo)
+}
+
+// roundHours rounds hours to one decimal
+func roundHours(hours float64) float64 {
+	return math.Round(hours*10) / 10
+}
@@ -78,6 +78,17 @@ func (s *OfferService) SendOffer(ctx context.Context, id uuid.UUID) (*domain.Off
 		fmt.Sprintf("Tilbudet '%s' ble sendt til kunde (fase: %s -> %s)", offer.Title, oldPhase, offer.Phase))
 
 	dto := mapper.ToOfferDTO(offer)
+
+	// Warn, without blocking the send, when nobody in the buying committee is a decision maker
+	if s.contactRepo != nil {
+		decisionMakers, err := s.contactRepo.CountByBuyingRole(ctx, domain.ContactEntityOffer, offer.ID, domain.BuyingRoleDecisionMaker)
+		if err != nil {
+			s.logger.Warn("failed to check decision maker of sent offer", zap.Error(err))
+		} else if decisionMakers == 0 {
+			dto.Warnings = append(dto.Warnings, domain.OfferWarningMissingDecisionMaker)
+		}
+	}
+
 	return &dto, nil
 }
 
@@ -94,8 +105,9 @@ func (s *OfferService) AcceptOffer(ctx context.Context, id uuid.UUID, req *domai
 	// If project creation is not requested, return without project
 	if !req.CreateProject {
 		return &domain.AcceptOfferResponse{
-			Offer:   result.Offer,
-			Project: nil,
+			Offer:       result.Offer,
+			Project:     nil,
+			CreditCheck: result.CreditCheck,
 		}, nil
 	}
 
@@ -146,6 +158,7 @@ func (s *OfferService) AcceptOffer(ctx context.Context, id uuid.UUID, req *domai
 	offerItems, err := s.budgetItemRepo.ListByParent(ctx, domain.BudgetParentOffer, id)
 	if err == nil && len(offerItems) > 0 {
 		for _, item := range offerItems {
+			sourceID := item.ID
 			cloned := domain.BudgetItem{
 				ParentType:     domain.BudgetParentProject,
 				ParentID:       project.ID,
@@ -153,7 +166,11 @@ func (s *OfferService) AcceptOffer(ctx context.Context, id uuid.UUID, req *domai
 				Description:    item.Description,
 				ExpectedCost:   item.ExpectedCost,
 				ExpectedMargin: item.ExpectedMargin,
+				Quantity:       item.Quantity,
+				PricePerItem:   item.PricePerItem,
 				DisplayOrder:   item.DisplayOrder,
+				CostCategory:   item.CostCategory,
+				SourceItemID:   &sourceID, // The project budget rebudgets the sold offer
 			}
 			if err := s.budgetItemRepo.Create(ctx, &cloned); err != nil {
 				s.logger.Warn("failed to clone budget item to project", zap.Error(err))
@@ -173,8 +190,9 @@ func (s *OfferService) AcceptOffer(ctx context.Context, id uuid.UUID, req *domai
 
 	projectDTO := mapper.ToProjectDTO(project)
 	return &domain.AcceptOfferResponse{
-		Offer:   result.Offer,
-		Project: &projectDTO,
+		Offer:       result.Offer,
+		Project:     &projectDTO,
+		CreditCheck: result.CreditCheck,
 	}, nil
 }
 
@@ -199,6 +217,15 @@ func (s *OfferService) AcceptOrder(ctx context.Context, id uuid.UUID, req *domai
 		return nil, ErrOfferAlreadyInOrder
 	}
 
+	// Check the customer's credit limit; companies that block orders over the limit stop here
+	var creditCheck *domain.CreditCheckDTO
+	if s.creditExposure != nil {
+		creditCheck, err = s.creditExposure.CheckOrder(ctx, offer)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	oldPhase := offer.Phase
 
 	// Transition to order phase
@@ -238,9 +265,27 @@ func (s *OfferService) AcceptOrder(ctx context.Context, id uuid.UUID, req *domai
 		}
 	}
 
+	// Warn, without blocking the order, when the manager is already booked beyond capacity in the order's window
+	var capacityWarning *domain.CapacityWarningDTO
+	if s.capacity != nil {
+		capacityWarning, err = s.capacity.CheckManagerCapacity(ctx, offer)
+		if err != nil {
+			s.logger.Warn("failed to check manager capacity after accept order",
+				zap.String("offerID", id.String()),
+				zap.Error(err))
+		} else if capacityWarning != nil {
+			s.logger.Info("order manager is over capacity",
+				zap.String("offerID", id.String()),
+				zap.String("managerID", capacityWarning.UserID),
+				zap.Int("overallocatedWeeks", len(capacityWarning.Weeks)))
+		}
+	}
+
 	offerDTO := mapper.ToOfferDTO(offer)
 	return &domain.AcceptOrderResponse{
-		Offer: &offerDTO,
+		Offer:           &offerDTO,
+		CreditCheck:     creditCheck,
+		CapacityWarning: capacityWarning,
 	}, nil
 }
 
@@ -683,6 +728,7 @@ func (s *OfferService) CloneOffer(ctx context.Context, id uuid.UUID, req *domain
 						PricePerItem:   item.PricePerItem,
 						Description:    item.Description,
 						DisplayOrder:   item.DisplayOrder,
+						CostCategory:   item.CostCategory,
 					}
 					if err := tx.Create(&cloned).Error; err != nil {
 						s.logger.Warn("failed to clone budget item",
@@ -787,6 +833,7 @@ func (s *OfferService) AdvanceWithProjectResponse(ctx context.Context, id uuid.U
 		if err := s.generateOfferNumberIfNeeded(ctx, offer); err != nil {
 			return nil, err
 		}
+		markInquiryResponded(offer)
 	}
 
 	// Track project creation result
@@ -29,6 +29,10 @@ type OfferService struct {
 	numberSeqService *NumberSequenceService
 	fileService      *FileService
 	dwClient         *datawarehouse.Client
+	creditExposure   *CreditExposureService
+	capacity         *ResourceCapacityService
+	contactRepo      *repository.ContactRepository
+	staleThresholds  repository.OfferStaleThresholds
 	logger           *zap.Logger
 	db               *gorm.DB
 }
@@ -71,6 +75,30 @@ func (s *OfferService) SetDataWarehouseClient(client *datawarehouse.Client) {
 	s.dwClient = client
 }
 
+// SetCreditExposureService sets the credit exposure service for checking credit limits on accepted orders.
+// Without it, orders are accepted without a credit check.
+func (s *OfferService) SetCreditExposureService(creditExposure *CreditExposureService) {
+	s.creditExposure = creditExposure
+}
+
+// SetResourceCapacityService sets the resource capacity service for warning about overbooked order managers.
+// Without it, accepted orders carry no capacity warning.
+func (s *OfferService) SetResourceCapacityService(capacity *ResourceCapacityService) {
+	s.capacity = capacity
+}
+
+// SetContactRepository sets the contact repository for checking the buying committee of sent offers.
+// Without it, offers are sent without a decision-maker check.
+func (s *OfferService) SetContactRepository(contactRepo *repository.ContactRepository) {
+	s.contactRepo = contactRepo
+}
+
+// SetStaleThresholds sets how many days offers in each phase can go without activity before they are stale.
+// Phases without a threshold never go stale.
+func (s *OfferService) SetStaleThresholds(thresholds repository.OfferStaleThresholds) {
+	s.staleThresholds = thresholds
+}
+
 // Create creates a new offer with initial items
 func (s *OfferService) Create(ctx context.Context, req *domain.CreateOfferRequest) (*domain.OfferDTO, error) {
 	resp, err := s.CreateWithProjectResponse(ctx, req)
@@ -354,6 +382,11 @@ func (s *OfferService) GetByIDWithBudgetItems(ctx context.Context, id uuid.UUID)
 
 	// Convert offer to DTO
 	offerDTO := mapper.ToOfferDTO(offer)
+	offerDTOs := []domain.OfferDTO{offerDTO}
+	if err := s.applyOfferStaleness(ctx, offerDTOs); err != nil {
+		return nil, err
+	}
+	offerDTO = offerDTOs[0]
 
 	// Convert budget items to DTOs
 	itemDTOs := make([]domain.BudgetItemDTO, len(items))
@@ -466,6 +499,7 @@ func (s *OfferService) Update(ctx context.Context, id uuid.UUID, req *domain.Upd
 		if err := s.generateOfferNumberIfNeeded(ctx, offer); err != nil {
 			return nil, err
 		}
+		markInquiryResponded(offer)
 
 		// Auto-create project if transitioning from draft and no project is linked
 		if offer.ProjectID == nil {
@@ -618,6 +652,16 @@ func (s *OfferService) List(ctx context.Context, page, pageSize int, customerID,
 
 // ListWithSort returns a paginated list of offers with custom sorting
 func (s *OfferService) ListWithSort(ctx context.Context, page, pageSize int, customerID, projectID *uuid.UUID, phase *domain.OfferPhase, sort repository.SortConfig) (*domain.PaginatedResponse, error) {
+	filters := &repository.OfferFilters{
+		CustomerID: customerID,
+		ProjectID:  projectID,
+		Phase:      phase,
+	}
+	return s.ListWithFilters(ctx, page, pageSize, filters, sort)
+}
+
+// ListWithFilters returns a paginated list of offers with filter and sort options
+func (s *OfferService) ListWithFilters(ctx context.Context, page, pageSize int, filters *repository.OfferFilters, sort repository.SortConfig) (*domain.PaginatedResponse, error) {
 	// Clamp page size
 	if pageSize < 1 {
 		pageSize = 20
@@ -629,10 +673,8 @@ func (s *OfferService) ListWithSort(ctx context.Context, page, pageSize int, cus
 		page = 1
 	}
 
-	filters := &repository.OfferFilters{
-		CustomerID: customerID,
-		ProjectID:  projectID,
-		Phase:      phase,
+	if filters != nil && filters.Stale != nil {
+		filters.StaleAfterDays = s.staleThresholds
 	}
 
 	offers, total, err := s.offerRepo.ListWithFilters(ctx, page, pageSize, filters, sort)
@@ -644,6 +686,9 @@ func (s *OfferService) ListWithSort(ctx context.Context, page, pageSize int, cus
 	for i, offer := range offers {
 		dtos[i] = mapper.ToOfferDTO(&offer)
 	}
+	if err := s.applyOfferStaleness(ctx, dtos); err != nil {
+		return nil, err
+	}
 
 	totalPages := int((total + int64(pageSize) - 1) / int64(pageSize))
 	return &domain.PaginatedResponse{
@@ -982,6 +1027,14 @@ func (s *OfferService) isDraftPhase(phase domain.OfferPhase) bool {
 	return phase == domain.OfferPhaseDraft
 }
 
+// markInquiryResponded stops the inquiry response SLA clock when an inquiry leaves the draft phase
+func markInquiryResponded(offer *domain.Offer) {
+	if offer.InquirySLADueAt != nil && offer.InquiryRespondedAt == nil {
+		now := time.Now().UTC()
+		offer.InquiryRespondedAt = &now
+	}
+}
+
 // generateOfferNumberIfNeeded generates an offer number for the offer if it doesn't have one.
 // This should be called when transitioning from draft to any other phase.
 // Returns an error if the offer number generation fails.
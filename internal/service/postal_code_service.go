@@ -0,0 +1,185 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/straye-as/relation-api/internal/domain"
+	"github.com/straye-as/relation-api/internal/postal"
+	"github.com/straye-as/relation-api/internal/repository"
+	"go.uber.org/zap"
+)
+
+// Postal code service errors
+var (
+	// ErrInvalidPostalCode is returned when a postal code is not four digits
+	ErrInvalidPostalCode = errors.New("postal code must be four digits")
+
+	// ErrPostalCodeNotFound is returned when a postal code is not in the register
+	ErrPostalCodeNotFound = errors.New("postal code not found")
+)
+
+// PostalCodeService handles lookups in the bundled Norwegian postal code register
+// and reports customers and suppliers whose city does not match their postal code
+type PostalCodeService struct {
+	register     *postal.Register
+	customerRepo *repository.CustomerRepository
+	supplierRepo *repository.SupplierRepository
+	logger       *zap.Logger
+}
+
+// NewPostalCodeService creates a new PostalCodeService using the bundled register
+func NewPostalCodeService(customerRepo *repository.CustomerRepository, supplierRepo *repository.SupplierRepository, logger *zap.Logger) *PostalCodeService {
+	return &PostalCodeService{
+		register:     postal.Default(),
+		customerRepo: customerRepo,
+		supplierRepo: supplierRepo,
+		logger:       logger,
+	}
+}
+
+// Lookup returns the city, municipality and county for a postal code
+func (s *PostalCodeService) Lookup(code string) (*domain.PostalCodeDTO, error) {
+	code = strings.TrimSpace(code)
+	if !postal.IsValidCode(code) {
+		return nil, ErrInvalidPostalCode
+	}
+
+	entry, ok := s.register.Lookup(code)
+	if !ok {
+		return nil, ErrPostalCodeNotFound
+	}
+
+	return &domain.PostalCodeDTO{
+		PostalCode:         entry.Code,
+		City:               entry.City,
+		MunicipalityNumber: entry.MunicipalityNumber,
+		Municipality:       entry.Municipality,
+		County:             entry.County,
+		Category:           string(entry.Category),
+	}, nil
+}
+
+// GetMismatchReport lists Norwegian customers and suppliers whose city does not match
+// the city of their postal code, or whose postal code is not in the register
+func (s *PostalCodeService) GetMismatchReport(ctx context.Context) (*domain.PostalCodeMismatchReportDTO, error) {
+	customers, err := s.customerRepo.ListWithPostalCode(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list customers: %w", err)
+	}
+
+	suppliers, err := s.supplierRepo.ListWithPostalCode(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list suppliers: %w", err)
+	}
+
+	report := &domain.PostalCodeMismatchReportDTO{
+		Mismatches:  []domain.PostalCodeMismatchDTO{},
+		GeneratedAt: time.Now().Format(time.RFC3339),
+	}
+
+	for _, customer := range customers {
+		if !isNorwegianCountry(customer.Country) {
+			continue
+		}
+		report.CustomersChecked++
+		if mismatch := s.checkPostalCode(customer.PostalCode, customer.City); mismatch != nil {
+			mismatch.EntityType = "customer"
+			mismatch.EntityID = customer.ID
+			mismatch.Name = customer.Name
+			report.Mismatches = append(report.Mismatches, *mismatch)
+		}
+	}
+
+	for _, supplier := range suppliers {
+		if !isNorwegianCountry(supplier.Country) {
+			continue
+		}
+		report.SuppliersChecked++
+		if mismatch := s.checkPostalCode(supplier.PostalCode, supplier.City); mismatch != nil {
+			mismatch.EntityType = "supplier"
+			mismatch.EntityID = supplier.ID
+			mismatch.Name = supplier.Name
+			report.Mismatches = append(report.Mismatches, *mismatch)
+		}
+	}
+
+	report.MismatchCount = len(report.Mismatches)
+	return report, nil
+}
+
+// RunPostalCodeCheck generates the mismatch report and logs the result.
+// This is called by the scheduled data quality job.
+func (s *PostalCodeService) RunPostalCodeCheck(ctx context.Context) (checked int, mismatches int, err error) {
+	report, err := s.GetMismatchReport(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	for _, mismatch := range report.Mismatches {
+		s.logger.Warn("postal code does not match city",
+			zap.String("entity_type", mismatch.EntityType),
+			zap.String("entity_id", mismatch.EntityID.String()),
+			zap.String("postal_code", mismatch.PostalCode),
+			zap.String("city", mismatch.City),
+			zap.String("expected_city", mismatch.ExpectedCity),
+			zap.String("reason", string(mismatch.Reason)))
+	}
+
+	return report.CustomersChecked + report.SuppliersChecked, report.MismatchCount, nil
+}
+
+// checkPostalCode returns a mismatch if the city does not match the postal code register
+func (s *PostalCodeService) checkPostalCode(postalCode, city string) *domain.PostalCodeMismatchDTO {
+	entry, ok := s.register.Lookup(postalCode)
+	if !ok {
+		return &domain.PostalCodeMismatchDTO{
+			PostalCode: postalCode,
+			City:       city,
+			Reason:     domain.PostalCodeMismatchUnknown,
+		}
+	}
+	if postal.SameCity(city, entry.City) {
+		return nil
+	}
+	return &domain.PostalCodeMismatchDTO{
+		PostalCode:   postalCode,
+		City:         city,
+		ExpectedCity: entry.City,
+		Reason:       domain.PostalCodeMismatchCity,
+	}
+}
+
+// derivePostalAddress fills in city, municipality and county from the postal code register.
+// Only applies to Norwegian addresses; unknown postal codes leave the fields untouched.
+// Returns true if the postal code was found in the register.
+func derivePostalAddress(country, postalCode string, city, municipality, county *string) bool {
+	if !isNorwegianCountry(country) {
+		return false
+	}
+
+	entry, ok := postal.Default().Lookup(postalCode)
+	if !ok {
+		return false
+	}
+
+	*city = entry.City
+	*municipality = entry.Municipality
+	if entry.County != "" {
+		*county = entry.County
+	}
+	return true
+}
+
+// isNorwegianCountry returns true for an empty country or any common spelling of Norway
+func isNorwegianCountry(country string) bool {
+	switch strings.ToLower(strings.TrimSpace(country)) {
+	case "", "norway", "norge", "no", "nor":
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,713 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/straye-as/relation-api/internal/auth"
+	"github.com/straye-as/relation-api/internal/domain"
+	"github.com/straye-as/relation-api/internal/mapper"
+	"github.com/straye-as/relation-api/internal/repository"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// WarningMilestoneAfterOfferEndDate flags a milestone forecast to be reached after the end date of its offer
+const WarningMilestoneAfterOfferEndDate = "milestone.after.offer.end_date"
+
+// ScheduledTask is a task or milestone placed in the schedule by the critical path method.
+// Dates are whole days: a task runs from the start of EarliestStart to the end of EarliestFinish,
+// a milestone is reached at the end of its date.
+type ScheduledTask struct {
+	Task *domain.ProjectTask
+	// DependsOn are the explicit dependencies; Predecessors also include the tasks of a milestone
+	DependsOn      []uuid.UUID
+	Predecessors   []uuid.UUID
+	EarliestStart  time.Time
+	EarliestFinish time.Time
+	LatestStart    time.Time
+	LatestFinish   time.Time
+	SlackDays      int
+	Critical       bool
+}
+
+// scheduleNode holds the critical path values of a task in days since the Unix epoch. A task
+// occupies [start, finish), a milestone is a point at the end of its date (start == finish).
+type scheduleNode struct {
+	task                         *domain.ProjectTask
+	plannedStart, duration       int
+	preds, succs                 []int
+	es, ef, ls, lf               int
+	dependsOn, predecessorTaskID []uuid.UUID
+}
+
+// ScheduleProjectTasks runs the critical path method over a project's tasks: items start on their
+// planned date or when their dependencies are finished, whichever is later, and a milestone is
+// reached when its tasks are finished. Items are returned in dependency order.
+// Returns ErrProjectTaskDependencyCycle when the dependencies form a cycle.
+func ScheduleProjectTasks(tasks []domain.ProjectTask, deps []domain.ProjectTaskDependency) ([]ScheduledTask, error) {
+	index := make(map[uuid.UUID]int, len(tasks))
+	nodes := make([]scheduleNode, len(tasks))
+	for i := range tasks {
+		task := &tasks[i]
+		index[task.ID] = i
+		start, end := dayNumber(task.StartDate), dayNumber(task.EndDate)
+		if task.Kind == domain.ProjectTaskKindMilestone {
+			nodes[i] = scheduleNode{task: task, plannedStart: end + 1}
+		} else {
+			nodes[i] = scheduleNode{task: task, plannedStart: start, duration: end - start + 1}
+		}
+	}
+
+	seen := make(map[[2]int]bool)
+	link := func(pred, succ int) {
+		if pred == succ || seen[[2]int{pred, succ}] {
+			return
+		}
+		seen[[2]int{pred, succ}] = true
+		nodes[succ].preds = append(nodes[succ].preds, pred)
+		nodes[pred].succs = append(nodes[pred].succs, succ)
+		nodes[succ].predecessorTaskID = append(nodes[succ].predecessorTaskID, nodes[pred].task.ID)
+	}
+	for _, dep := range deps {
+		succ, ok := index[dep.TaskID]
+		pred, ok2 := index[dep.DependsOnID]
+		if !ok || !ok2 {
+			continue
+		}
+		nodes[succ].dependsOn = append(nodes[succ].dependsOn, dep.DependsOnID)
+		link(pred, succ)
+	}
+	for i := range nodes {
+		if milestoneID := nodes[i].task.MilestoneID; milestoneID != nil {
+			if m, ok := index[*milestoneID]; ok && nodes[m].task.Kind == domain.ProjectTaskKindMilestone {
+				link(i, m)
+			}
+		}
+	}
+
+	// Topological order (Kahn), keeping the input order among independent items
+	order := make([]int, 0, len(nodes))
+	remaining := make([]int, len(nodes))
+	for i := range nodes {
+		remaining[i] = len(nodes[i].preds)
+	}
+	queue := make([]int, 0, len(nodes))
+	for i := range nodes {
+		if remaining[i] == 0 {
+			queue = append(queue, i)
+		}
+	}
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		order = append(order, n)
+		for _, s := range nodes[n].succs {
+			remaining[s]--
+			if remaining[s] == 0 {
+				queue = append(queue, s)
+			}
+		}
+	}
+	if len(order) != len(nodes) {
+		return nil, ErrProjectTaskDependencyCycle
+	}
+
+	// Forward pass
+	finish := 0
+	for k, n := range order {
+		node := &nodes[n]
+		node.es = node.plannedStart
+		for _, p := range node.preds {
+			if nodes[p].ef > node.es {
+				node.es = nodes[p].ef
+			}
+		}
+		node.ef = node.es + node.duration
+		if k == 0 || node.ef > finish {
+			finish = node.ef
+		}
+	}
+
+	// Backward pass
+	for k := len(order) - 1; k >= 0; k-- {
+		node := &nodes[order[k]]
+		node.lf = finish
+		for _, s := range node.succs {
+			if nodes[s].ls < node.lf {
+				node.lf = nodes[s].ls
+			}
+		}
+		node.ls = node.lf - node.duration
+	}
+
+	scheduled := make([]ScheduledTask, 0, len(order))
+	for _, n := range order {
+		node := nodes[n]
+		item := ScheduledTask{
+			Task:         node.task,
+			DependsOn:    node.dependsOn,
+			Predecessors: node.predecessorTaskID,
+			SlackDays:    node.ls - node.es,
+			Critical:     node.ls == node.es,
+		}
+		if node.task.Kind == domain.ProjectTaskKindMilestone {
+			item.EarliestStart = dayDate(node.es - 1)
+			item.EarliestFinish = item.EarliestStart
+			item.LatestStart = dayDate(node.ls - 1)
+			item.LatestFinish = item.LatestStart
+		} else {
+			item.EarliestStart = dayDate(node.es)
+			item.EarliestFinish = dayDate(node.ef - 1)
+			item.LatestStart = dayDate(node.ls)
+			item.LatestFinish = dayDate(node.lf - 1)
+		}
+		scheduled = append(scheduled, item)
+	}
+	return scheduled, nil
+}
+
+// dayNumber returns the calendar date of t as days since the Unix epoch
+func dayNumber(t time.Time) int {
+	return int(time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC).Unix() / 86400)
+}
+
+func dayDate(day int) time.Time {
+	return time.Unix(int64(day)*86400, 0).UTC()
+}
+
+// ProjectScheduleService manages the milestones and tasks of a project and computes its schedule
+type ProjectScheduleService struct {
+	projectRepo      *repository.ProjectRepository
+	taskRepo         *repository.ProjectTaskRepository
+	offerRepo        *repository.OfferRepository
+	userRepo         *repository.UserRepository
+	activityRepo     *repository.ActivityRepository
+	notificationRepo *repository.NotificationRepository
+	logger           *zap.Logger
+}
+
+// NewProjectScheduleService creates a new project schedule service
+func NewProjectScheduleService(
+	projectRepo *repository.ProjectRepository,
+	taskRepo *repository.ProjectTaskRepository,
+	offerRepo *repository.OfferRepository,
+	userRepo *repository.UserRepository,
+	activityRepo *repository.ActivityRepository,
+	notificationRepo *repository.NotificationRepository,
+	logger *zap.Logger,
+) *ProjectScheduleService {
+	return &ProjectScheduleService{
+		projectRepo:      projectRepo,
+		taskRepo:         taskRepo,
+		offerRepo:        offerRepo,
+		userRepo:         userRepo,
+		activityRepo:     activityRepo,
+		notificationRepo: notificationRepo,
+		logger:           logger,
+	}
+}
+
+// projectSchedule is the computed schedule of a project with the offers its milestones are measured against
+type projectSchedule struct {
+	project   *domain.Project
+	tasks     []domain.ProjectTask
+	deps      []domain.ProjectTaskDependency
+	items     []ScheduledTask
+	bestOffer *domain.Offer
+	offers    map[uuid.UUID]*domain.Offer
+	warnings  []domain.ProjectScheduleWarningDTO
+}
+
+// ListTasks returns the tasks and milestones of a project in display order
+func (s *ProjectScheduleService) ListTasks(ctx context.Context, projectID uuid.UUID) ([]domain.ProjectTaskDTO, error) {
+	if _, err := s.getProject(ctx, projectID); err != nil {
+		return nil, err
+	}
+	tasks, err := s.taskRepo.ListByProject(ctx, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list project tasks: %w", err)
+	}
+	deps, err := s.taskRepo.ListDependencies(ctx, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list project task dependencies: %w", err)
+	}
+	dependsOn := dependenciesByTask(deps)
+
+	dtos := make([]domain.ProjectTaskDTO, 0, len(tasks))
+	for i := range tasks {
+		dtos = append(dtos, mapper.ToProjectTaskDTO(&tasks[i], dependsOn[tasks[i].ID]))
+	}
+	return dtos, nil
+}
+
+// GetSchedule returns the project's schedule for a Gantt chart with the critical path and
+// warnings for milestones forecast after the end date of their offer
+func (s *ProjectScheduleService) GetSchedule(ctx context.Context, projectID uuid.UUID) (*domain.ProjectScheduleDTO, error) {
+	project, err := s.getProject(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	schedule, err := s.load(ctx, project)
+	if err != nil {
+		return nil, err
+	}
+
+	dto := &domain.ProjectScheduleDTO{
+		ProjectID:    project.ID,
+		ProjectName:  project.Name,
+		Items:        make([]domain.ProjectScheduleItemDTO, 0, len(schedule.items)),
+		Links:        []domain.ProjectScheduleLinkDTO{},
+		CriticalPath: []uuid.UUID{},
+		Warnings:     schedule.warnings,
+	}
+	if schedule.bestOffer != nil && schedule.bestOffer.EndDate != nil {
+		endDate := schedule.bestOffer.EndDate.Format("2006-01-02")
+		dto.OfferEndDate = &endDate
+	}
+
+	var start, end time.Time
+	for i, item := range schedule.items {
+		task := item.Task
+		if i == 0 || item.EarliestStart.Before(start) {
+			start = item.EarliestStart
+		}
+		if i == 0 || item.EarliestFinish.After(end) {
+			end = item.EarliestFinish
+		}
+		dependencies := item.DependsOn
+		if dependencies == nil {
+			dependencies = []uuid.UUID{}
+		}
+		dto.Items = append(dto.Items, domain.ProjectScheduleItemDTO{
+			ID:             task.ID,
+			Type:           task.Kind,
+			Name:           task.Name,
+			Start:          task.StartDate.Format("2006-01-02"),
+			End:            task.EndDate.Format("2006-01-02"),
+			Progress:       task.PercentComplete,
+			Parent:         task.MilestoneID,
+			Dependencies:   dependencies,
+			AssigneeID:     task.AssigneeID,
+			AssigneeName:   task.AssigneeName,
+			OfferID:        task.OfferID,
+			SortOrder:      task.SortOrder,
+			EarliestStart:  item.EarliestStart.Format("2006-01-02"),
+			EarliestFinish: item.EarliestFinish.Format("2006-01-02"),
+			LatestStart:    item.LatestStart.Format("2006-01-02"),
+			LatestFinish:   item.LatestFinish.Format("2006-01-02"),
+			SlackDays:      item.SlackDays,
+			Critical:       item.Critical,
+			Delayed:        dayNumber(item.EarliestFinish) > dayNumber(task.EndDate),
+		})
+		for _, pred := range item.Predecessors {
+			dto.Links = append(dto.Links, domain.ProjectScheduleLinkDTO{Source: pred, Target: task.ID, Type: "finish_to_start"})
+		}
+		if item.Critical {
+			dto.CriticalPath = append(dto.CriticalPath, task.ID)
+		}
+	}
+	if len(schedule.items) > 0 {
+		dto.StartDate = start.Format("2006-01-02")
+		dto.EndDate = end.Format("2006-01-02")
+	}
+
+	// Present items in display order; the critical path stays in dependency order
+	sort.SliceStable(dto.Items, func(i, j int) bool {
+		if dto.Items[i].SortOrder != dto.Items[j].SortOrder {
+			return dto.Items[i].SortOrder < dto.Items[j].SortOrder
+		}
+		return dto.Items[i].Start < dto.Items[j].Start
+	})
+	return dto, nil
+}
+
+// CreateTask adds a task or milestone to the project's schedule
+func (s *ProjectScheduleService) CreateTask(ctx context.Context, projectID uuid.UUID, req *domain.CreateProjectTaskRequest) (*domain.ProjectTaskDTO, error) {
+	project, err := s.getProject(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	before, err := s.load(ctx, project)
+	if err != nil {
+		return nil, err
+	}
+
+	kind := req.Kind
+	if kind == "" {
+		kind = domain.ProjectTaskKindTask
+	}
+	if !kind.IsValid() {
+		return nil, fmt.Errorf("%w: unknown kind %q", ErrInvalidProjectTask, kind)
+	}
+	task := &domain.ProjectTask{ProjectID: projectID, Kind: kind}
+	task.ID = uuid.New()
+	if userCtx, ok := auth.FromContext(ctx); ok {
+		task.CreatedByID = userCtx.UserID.String()
+		task.CreatedByName = userCtx.DisplayName
+		task.UpdatedByID = task.CreatedByID
+		task.UpdatedByName = task.CreatedByName
+	}
+	dependsOn, err := s.applyTaskRequest(ctx, before, task, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.taskRepo.Create(ctx, task, dependsOn); err != nil {
+		return nil, fmt.Errorf("failed to create project task: %w", err)
+	}
+	s.warnAboutSlippedMilestones(ctx, before)
+
+	dto := mapper.ToProjectTaskDTO(task, dependsOn)
+	return &dto, nil
+}
+
+// UpdateTask replaces the dates, progress, links and dependencies of a task or milestone
+func (s *ProjectScheduleService) UpdateTask(ctx context.Context, projectID, taskID uuid.UUID, req *domain.UpdateProjectTaskRequest) (*domain.ProjectTaskDTO, error) {
+	project, err := s.getProject(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	task, err := s.taskRepo.GetByID(ctx, projectID, taskID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrProjectTaskNotFound
+		}
+		return nil, fmt.Errorf("failed to get project task: %w", err)
+	}
+	before, err := s.load(ctx, project)
+	if err != nil {
+		return nil, err
+	}
+
+	if userCtx, ok := auth.FromContext(ctx); ok {
+		task.UpdatedByID = userCtx.UserID.String()
+		task.UpdatedByName = userCtx.DisplayName
+	}
+	dependsOn, err := s.applyTaskRequest(ctx, before, task, &domain.CreateProjectTaskRequest{
+		Kind:            task.Kind,
+		Name:            req.Name,
+		Description:     req.Description,
+		MilestoneID:     req.MilestoneID,
+		OfferID:         req.OfferID,
+		StartDate:       req.StartDate,
+		EndDate:         req.EndDate,
+		AssigneeID:      req.AssigneeID,
+		PercentComplete: req.PercentComplete,
+		SortOrder:       req.SortOrder,
+		DependsOn:       req.DependsOn,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.taskRepo.Update(ctx, task, dependsOn); err != nil {
+		return nil, fmt.Errorf("failed to update project task: %w", err)
+	}
+	s.warnAboutSlippedMilestones(ctx, before)
+
+	dto := mapper.ToProjectTaskDTO(task, dependsOn)
+	return &dto, nil
+}
+
+// DeleteTask removes a task or milestone from the project's schedule
+func (s *ProjectScheduleService) DeleteTask(ctx context.Context, projectID, taskID uuid.UUID) error {
+	project, err := s.getProject(ctx, projectID)
+	if err != nil {
+		return err
+	}
+	if _, err := s.taskRepo.GetByID(ctx, projectID, taskID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrProjectTaskNotFound
+		}
+		return fmt.Errorf("failed to get project task: %w", err)
+	}
+	before, err := s.load(ctx, project)
+	if err != nil {
+		return err
+	}
+	if err := s.taskRepo.Delete(ctx, taskID); err != nil {
+		return fmt.Errorf("failed to delete project task: %w", err)
+	}
+	s.warnAboutSlippedMilestones(ctx, before)
+	return nil
+}
+
+// applyTaskRequest validates the request against the rest of the schedule and copies it onto the task.
+// Returns the tasks the task depends on.
+func (s *ProjectScheduleService) applyTaskRequest(ctx context.Context, schedule *projectSchedule, task *domain.ProjectTask, req *domain.CreateProjectTaskRequest) ([]uuid.UUID, error) {
+	startDate, err := time.Parse("2006-01-02", req.StartDate)
+	if err != nil {
+		return nil, fmt.Errorf("%w: startDate must be a date (YYYY-MM-DD)", ErrInvalidProjectTask)
+	}
+	endDate := startDate
+	if task.Kind == domain.ProjectTaskKindMilestone {
+		if req.EndDate != "" && req.EndDate != req.StartDate {
+			return nil, fmt.Errorf("%w: a milestone has a single date", ErrInvalidProjectTask)
+		}
+	} else {
+		if endDate, err = time.Parse("2006-01-02", req.EndDate); err != nil {
+			return nil, fmt.Errorf("%w: endDate must be a date (YYYY-MM-DD)", ErrInvalidProjectTask)
+		}
+		if endDate.Before(startDate) {
+			return nil, fmt.Errorf("%w: endDate is before startDate", ErrInvalidProjectTask)
+		}
+	}
+
+	byID := make(map[uuid.UUID]*domain.ProjectTask, len(schedule.tasks))
+	for i := range schedule.tasks {
+		byID[schedule.tasks[i].ID] = &schedule.tasks[i]
+	}
+
+	if req.MilestoneID != nil {
+		milestone, ok := byID[*req.MilestoneID]
+		if task.Kind == domain.ProjectTaskKindMilestone {
+			return nil, fmt.Errorf("%w: only tasks can be part of a milestone", ErrInvalidProjectTask)
+		}
+		if !ok || milestone.Kind != domain.ProjectTaskKindMilestone {
+			return nil, fmt.Errorf("%w: milestoneId is not a milestone in this project", ErrInvalidProjectTask)
+		}
+	}
+
+	if req.OfferID != nil {
+		if task.Kind != domain.ProjectTaskKindMilestone {
+			return nil, fmt.Errorf("%w: only milestones can be linked to an offer", ErrInvalidProjectTask)
+		}
+		offer, err := s.offerRepo.GetByID(ctx, *req.OfferID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, fmt.Errorf("%w: offer not found", ErrInvalidProjectTask)
+			}
+			return nil, fmt.Errorf("failed to get offer: %w", err)
+		}
+		if offer.ProjectID == nil || *offer.ProjectID != task.ProjectID {
+			return nil, fmt.Errorf("%w: offer does not belong to this project", ErrInvalidProjectTask)
+		}
+	}
+
+	assigneeName := ""
+	if req.AssigneeID != "" {
+		user, err := s.userRepo.GetByStringID(ctx, req.AssigneeID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, fmt.Errorf("%w: unknown assignee %q", ErrInvalidProjectTask, req.AssigneeID)
+			}
+			return nil, fmt.Errorf("failed to get assignee: %w", err)
+		}
+		assigneeName = user.DisplayName
+	}
+
+	dependsOn := make([]uuid.UUID, 0, len(req.DependsOn))
+	seen := make(map[uuid.UUID]bool, len(req.DependsOn))
+	for _, id := range req.DependsOn {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		if id == task.ID {
+			return nil, fmt.Errorf("%w: a task cannot depend on itself", ErrInvalidProjectTask)
+		}
+		if _, ok := byID[id]; !ok {
+			return nil, fmt.Errorf("%w: dependency %s is not a task in this project", ErrInvalidProjectTask, id)
+		}
+		dependsOn = append(dependsOn, id)
+	}
+
+	task.Name = req.Name
+	task.Description = req.Description
+	task.MilestoneID = req.MilestoneID
+	task.OfferID = req.OfferID
+	task.StartDate = startDate
+	task.EndDate = endDate
+	task.AssigneeID = req.AssigneeID
+	task.AssigneeName = assigneeName
+	task.PercentComplete = req.PercentComplete
+	task.SortOrder = req.SortOrder
+
+	// Reject changes that would make a task wait for itself
+	tasks := make([]domain.ProjectTask, 0, len(schedule.tasks)+1)
+	for _, t := range schedule.tasks {
+		if t.ID != task.ID {
+			tasks = append(tasks, t)
+		}
+	}
+	tasks = append(tasks, *task)
+	deps := make([]domain.ProjectTaskDependency, 0, len(schedule.deps)+len(dependsOn))
+	for _, dep := range schedule.deps {
+		if dep.TaskID != task.ID {
+			deps = append(deps, dep)
+		}
+	}
+	for _, id := range dependsOn {
+		deps = append(deps, domain.ProjectTaskDependency{TaskID: task.ID, DependsOnID: id})
+	}
+	if _, err := ScheduleProjectTasks(tasks, deps); err != nil {
+		return nil, err
+	}
+	return dependsOn, nil
+}
+
+// warnAboutSlippedMilestones compares the schedule before a change with the current one and, for each
+// milestone that now slips past its offer's end date, logs it on the project and notifies the offer's
+// manager. Failures are logged and do not fail the change.
+func (s *ProjectScheduleService) warnAboutSlippedMilestones(ctx context.Context, before *projectSchedule) {
+	after, err := s.load(ctx, before.project)
+	if err != nil {
+		s.logger.Warn("failed to compute project schedule for slip warnings",
+			zap.String("project_id", before.project.ID.String()), zap.Error(err))
+		return
+	}
+	slipping := make(map[uuid.UUID]bool, len(before.warnings))
+	for _, w := range before.warnings {
+		slipping[w.TaskID] = true
+	}
+
+	for _, w := range after.warnings {
+		if slipping[w.TaskID] {
+			continue
+		}
+		s.logActivity(ctx, before.project, "Milepæl forsinket", w.Message)
+
+		offer := after.offers[w.OfferID]
+		if offer == nil {
+			continue
+		}
+		recipient := offer.ResponsibleUserID
+		if offer.ManagerID != nil && *offer.ManagerID != "" {
+			recipient = *offer.ManagerID
+		}
+		userID, err := uuid.Parse(recipient)
+		if err != nil {
+			continue
+		}
+		projectID := before.project.ID
+		notification := &domain.Notification{
+			UserID:     userID,
+			Type:       string(domain.NotificationTypeMilestoneSlipped),
+			Title:      "Milepæl forsinket",
+			Message:    truncateRunes(fmt.Sprintf("%s: %s", before.project.Name, w.Message), 500),
+			EntityID:   &projectID,
+			EntityType: "project",
+		}
+		if err := s.notificationRepo.Create(ctx, notification); err != nil {
+			s.logger.Warn("failed to notify about slipped milestone",
+				zap.String("task_id", w.TaskID.String()), zap.Error(err))
+		}
+	}
+}
+
+// load computes the project's schedule and checks its milestones against the offers' end dates
+func (s *ProjectScheduleService) load(ctx context.Context, project *domain.Project) (*projectSchedule, error) {
+	tasks, err := s.taskRepo.ListByProject(ctx, project.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list project tasks: %w", err)
+	}
+	deps, err := s.taskRepo.ListDependencies(ctx, project.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list project task dependencies: %w", err)
+	}
+	items, err := ScheduleProjectTasks(tasks, deps)
+	if err != nil {
+		return nil, err
+	}
+	schedule := &projectSchedule{
+		project:  project,
+		tasks:    tasks,
+		deps:     deps,
+		items:    items,
+		offers:   make(map[uuid.UUID]*domain.Offer),
+		warnings: []domain.ProjectScheduleWarningDTO{},
+	}
+
+	schedule.bestOffer, err = s.offerRepo.GetBestOfferForProject(ctx, project.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get project offer: %w", err)
+	}
+	if schedule.bestOffer != nil {
+		schedule.offers[schedule.bestOffer.ID] = schedule.bestOffer
+	}
+
+	for _, item := range items {
+		task := item.Task
+		if task.Kind != domain.ProjectTaskKindMilestone {
+			continue
+		}
+		offer := schedule.bestOffer
+		if task.OfferID != nil {
+			offer = schedule.offers[*task.OfferID]
+			if offer == nil {
+				if offer, err = s.offerRepo.GetByID(ctx, *task.OfferID); err != nil {
+					if errors.Is(err, gorm.ErrRecordNotFound) {
+						continue
+					}
+					return nil, fmt.Errorf("failed to get milestone offer: %w", err)
+				}
+				schedule.offers[offer.ID] = offer
+			}
+		}
+		if offer == nil || offer.EndDate == nil {
+			continue
+		}
+		daysLate := dayNumber(item.EarliestFinish) - dayNumber(*offer.EndDate)
+		if daysLate <= 0 {
+			continue
+		}
+		forecast := item.EarliestFinish.Format("2006-01-02")
+		offerEnd := offer.EndDate.Format("2006-01-02")
+		schedule.warnings = append(schedule.warnings, domain.ProjectScheduleWarningDTO{
+			Code:         WarningMilestoneAfterOfferEndDate,
+			TaskID:       task.ID,
+			TaskName:     task.Name,
+			OfferID:      offer.ID,
+			OfferEndDate: offerEnd,
+			ForecastDate: forecast,
+			DaysLate:     daysLate,
+			Message: fmt.Sprintf("Milepælen «%s» er beregnet nådd %s, %d dager etter sluttdatoen til tilbudet «%s» (%s)",
+				task.Name, forecast, daysLate, offer.Title, offerEnd),
+		})
+	}
+	return schedule, nil
+}
+
+func (s *ProjectScheduleService) getProject(ctx context.Context, projectID uuid.UUID) (*domain.Project, error) {
+	project, err := s.projectRepo.GetByID(ctx, projectID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrProjectNotFound
+		}
+		return nil, fmt.Errorf("failed to get project: %w", err)
+	}
+	return project, nil
+}
+
+// logActivity records a schedule event in the project's activity log
+func (s *ProjectScheduleService) logActivity(ctx context.Context, project *domain.Project, title, body string) {
+	activity := &domain.Activity{
+		TargetType: domain.ActivityTargetProject,
+		TargetID:   project.ID,
+		TargetName: project.Name,
+		Title:      title,
+		Body:       body,
+		OccurredAt: time.Now(),
+	}
+	if userCtx, ok := auth.FromContext(ctx); ok {
+		activity.CreatorName = userCtx.DisplayName
+		activity.CreatorID = userCtx.UserID.String()
+		activity.CompanyID = &userCtx.CompanyID
+	}
+	if err := s.activityRepo.Create(ctx, activity); err != nil {
+		s.logger.Warn("failed to log activity", zap.Error(err))
+	}
+}
+
+func dependenciesByTask(deps []domain.ProjectTaskDependency) map[uuid.UUID][]uuid.UUID {
+	byTask := make(map[uuid.UUID][]uuid.UUID)
+	for _, dep := range deps {
+		byTask[dep.TaskID] = append(byTask[dep.TaskID], dep.DependsOnID)
+	}
+	return byTask
+}
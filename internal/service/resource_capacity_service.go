@@ -0,0 +1,726 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/straye-as/relation-api/internal/auth"
+	"github.com/straye-as/relation-api/internal/domain"
+	"github.com/straye-as/relation-api/internal/mapper"
+	"github.com/straye-as/relation-api/internal/repository"
+	"github.com/straye-as/relation-api/internal/workcalendar"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	// capacityDefaultWeeks is the length of capacity calendars and reports without an end date,
+	// and of the window checked for orders without an end date
+	capacityDefaultWeeks = 12
+	// capacityMaxDays limits the period of a capacity calendar or report
+	capacityMaxDays = 366
+	// capacityTolerance keeps rounding from flagging a user who is booked exactly to capacity
+	capacityTolerance = 0.01
+
+	// WarningManagerOverCapacity is the code of the warning returned when an order's manager is already overbooked
+	WarningManagerOverCapacity = "manager.over.capacity"
+)
+
+// BuildCapacityWeeks computes a user's capacity and bookings per ISO week for the days from from to to
+// (both inclusive). Each working day - Monday to Friday except Norwegian public holidays - has a fifth
+// of the weekly hours. Absences take their percentage of the day away; allocations in hours per week
+// book a fifth of them each working day, and allocations in percent that share of the user's day.
+func BuildCapacityWeeks(hoursPerWeek float64, absences []domain.UserAbsence, allocations []domain.ResourceAllocation, from, to time.Time) []domain.CapacityWeekDTO {
+	from, to = dateInOslo(from), dateInOslo(to)
+	hoursPerDay := hoursPerWeek / 5
+
+	type week struct {
+		dto                          domain.CapacityWeekDTO
+		capacity, absence, allocated float64
+	}
+	var weeks []*week
+	var current *week
+
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		weekStart := day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7)).Format("2006-01-02")
+		if current == nil || current.dto.WeekStart != weekStart {
+			year, number := day.ISOWeek()
+			current = &week{dto: domain.CapacityWeekDTO{WeekStart: weekStart, Year: year, Week: number}}
+			weeks = append(weeks, current)
+		}
+		if day.Weekday() == time.Saturday || day.Weekday() == time.Sunday || workcalendar.IsPublicHoliday(day) {
+			continue
+		}
+
+		current.dto.WorkingDays++
+		current.capacity += hoursPerDay
+
+		away := 0
+		for _, absence := range absences {
+			if coversDay(absence.StartDate, absence.EndDate, day) && absence.Percent > away {
+				away = absence.Percent
+			}
+		}
+		if away > 100 {
+			away = 100
+		}
+		current.absence += hoursPerDay * float64(away) / 100
+
+		for _, allocation := range allocations {
+			if !coversDay(allocation.StartDate, allocation.EndDate, day) {
+				continue
+			}
+			if allocation.HoursPerWeek != nil {
+				current.allocated += *allocation.HoursPerWeek / 5
+			} else if allocation.Percent != nil {
+				current.allocated += hoursPerDay * *allocation.Percent / 100
+			}
+		}
+	}
+
+	result := make([]domain.CapacityWeekDTO, len(weeks))
+	for i, w := range weeks {
+		available := w.capacity - w.absence
+		dto := w.dto
+		dto.CapacityHours = roundHours(w.capacity)
+		dto.AbsenceHours = roundHours(w.absence)
+		dto.AvailableHours = roundHours(available)
+		dto.AllocatedHours = roundHours(w.allocated)
+		dto.FreeHours = roundHours(available - w.allocated)
+		dto.Overallocated = w.allocated > available+capacityTolerance
+		if available > 0 {
+			utilization := roundAmount(w.allocated / available * 100)
+			dto.UtilizationPercent = &utilization
+		}
+		result[i] = dto
+	}
+	return result
+}
+
+// coversDay reports whether the date range from start to end (both inclusive) contains day
+func coversDay(start, end, day time.Time) bool {
+	return !day.Before(dateInOslo(start)) && !day.After(dateInOslo(end))
+}
+
+// ResourceCapacityService manages users' weekly hours, absences and allocations to offers and projects,
+// and reports who is booked beyond their capacity
+type ResourceCapacityService struct {
+	capacityRepo *repository.ResourceCapacityRepository
+	offerRepo    *repository.OfferRepository
+	projectRepo  *repository.ProjectRepository
+	userRepo     *repository.UserRepository
+	logger       *zap.Logger
+	now          func() time.Time
+}
+
+// NewResourceCapacityService creates a new resource capacity service
+func NewResourceCapacityService(
+	capacityRepo *repository.ResourceCapacityRepository,
+	offerRepo *repository.OfferRepository,
+	projectRepo *repository.ProjectRepository,
+	userRepo *repository.UserRepository,
+	logger *zap.Logger,
+) *ResourceCapacityService {
+	return &ResourceCapacityService{
+		capacityRepo: capacityRepo,
+		offerRepo:    offerRepo,
+		projectRepo:  projectRepo,
+		userRepo:     userRepo,
+		logger:       logger,
+		now:          time.Now,
+	}
+}
+
+// ListAllocations returns the resource allocations visible to the user
+func (s *ResourceCapacityService) ListAllocations(ctx context.Context, filters *repository.ResourceAllocationFilters) ([]domain.ResourceAllocationDTO, error) {
+	allocations, err := s.capacityRepo.ListAllocations(ctx, filters)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list resource allocations: %w", err)
+	}
+
+	dtos := make([]domain.ResourceAllocationDTO, len(allocations))
+	for i := range allocations {
+		dtos[i] = mapper.ToResourceAllocationDTO(&allocations[i])
+	}
+	return dtos, nil
+}
+
+// GetAllocation returns a resource allocation
+func (s *ResourceCapacityService) GetAllocation(ctx context.Context, id uuid.UUID) (*domain.ResourceAllocationDTO, error) {
+	allocation, err := s.getAllocation(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	dto := mapper.ToResourceAllocationDTO(allocation)
+	return &dto, nil
+}
+
+// CreateAllocation books a user on an offer, a project or both. An allocation on an offer in a project
+// is also linked to the project. The company is the offer's, otherwise the requested or the user's company.
+func (s *ResourceCapacityService) CreateAllocation(ctx context.Context, req *domain.CreateResourceAllocationRequest) (*domain.ResourceAllocationDTO, error) {
+	if req.OfferID == nil && req.ProjectID == nil {
+		return nil, fmt.Errorf("%w: offerId or projectId is required", ErrInvalidResourceAllocation)
+	}
+
+	allocation := &domain.ResourceAllocation{
+		UserID:    req.UserID,
+		UserName:  req.UserName,
+		ProjectID: req.ProjectID,
+		Notes:     req.Notes,
+	}
+	if err := applyAllocationSize(allocation, req.StartDate, req.EndDate, req.HoursPerWeek, req.Percent); err != nil {
+		return nil, err
+	}
+
+	var companyID *domain.CompanyID
+	if req.OfferID != nil {
+		offer, err := s.offerRepo.GetByID(ctx, *req.OfferID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, ErrOfferNotFound
+			}
+			return nil, fmt.Errorf("failed to get offer: %w", err)
+		}
+		if offer.ProjectID != nil {
+			if req.ProjectID != nil && *req.ProjectID != *offer.ProjectID {
+				return nil, fmt.Errorf("%w: the offer belongs to another project", ErrInvalidResourceAllocation)
+			}
+			allocation.ProjectID = offer.ProjectID
+		}
+		allocation.OfferID = &offer.ID
+		companyID = &offer.CompanyID
+	}
+	if req.ProjectID != nil {
+		if _, err := s.projectRepo.GetByID(ctx, *req.ProjectID); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, ErrProjectNotFound
+			}
+			return nil, fmt.Errorf("failed to get project: %w", err)
+		}
+	}
+
+	user := s.lookupUser(ctx, req.UserID)
+	if user != nil {
+		allocation.UserName = user.DisplayName
+	}
+	if companyID == nil {
+		companyID = req.CompanyID
+	}
+	if companyID == nil && user != nil {
+		companyID = user.CompanyID
+	}
+	if companyID == nil {
+		return nil, fmt.Errorf("%w: companyId is required for project allocations", ErrInvalidResourceAllocation)
+	}
+	if !domain.IsValidCompanyID(string(*companyID)) || *companyID == domain.CompanyAll {
+		return nil, fmt.Errorf("%w: unknown company %q", ErrInvalidResourceAllocation, *companyID)
+	}
+	allocation.CompanyID = *companyID
+
+	if err := s.checkManage(ctx, allocation.CompanyID); err != nil {
+		return nil, err
+	}
+	if userCtx, ok := auth.FromContext(ctx); ok {
+		allocation.CreatedByID = userCtx.UserID.String()
+		allocation.CreatedByName = userCtx.DisplayName
+		allocation.UpdatedByID = userCtx.UserID.String()
+		allocation.UpdatedByName = userCtx.DisplayName
+	}
+
+	if err := s.capacityRepo.CreateAllocation(ctx, allocation); err != nil {
+		return nil, fmt.Errorf("failed to create resource allocation: %w", err)
+	}
+
+	s.logger.Info("resource allocation created",
+		zap.String("allocation_id", allocation.ID.String()),
+		zap.String("user_id", allocation.UserID),
+		zap.String("company_id", string(allocation.CompanyID)))
+
+	return s.GetAllocation(ctx, allocation.ID)
+}
+
+// UpdateAllocation changes the period, size and notes of a resource allocation
+func (s *ResourceCapacityService) UpdateAllocation(ctx context.Context, id uuid.UUID, req *domain.UpdateResourceAllocationRequest) (*domain.ResourceAllocationDTO, error) {
+	allocation, err := s.getAllocation(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkManage(ctx, allocation.CompanyID); err != nil {
+		return nil, err
+	}
+
+	if err := applyAllocationSize(allocation, req.StartDate, req.EndDate, req.HoursPerWeek, req.Percent); err != nil {
+		return nil, err
+	}
+	allocation.Notes = req.Notes
+	if userCtx, ok := auth.FromContext(ctx); ok {
+		allocation.UpdatedByID = userCtx.UserID.String()
+		allocation.UpdatedByName = userCtx.DisplayName
+	}
+
+	if err := s.capacityRepo.UpdateAllocation(ctx, allocation); err != nil {
+		return nil, fmt.Errorf("failed to update resource allocation: %w", err)
+	}
+
+	dto := mapper.ToResourceAllocationDTO(allocation)
+	return &dto, nil
+}
+
+// DeleteAllocation removes a resource allocation
+func (s *ResourceCapacityService) DeleteAllocation(ctx context.Context, id uuid.UUID) error {
+	allocation, err := s.getAllocation(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.checkManage(ctx, allocation.CompanyID); err != nil {
+		return err
+	}
+
+	if err := s.capacityRepo.DeleteAllocation(ctx, id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrResourceAllocationNotFound
+		}
+		return fmt.Errorf("failed to delete resource allocation: %w", err)
+	}
+	return nil
+}
+
+// GetCalendar returns a user's capacity, absences and allocations week by week. The period defaults to
+// the coming weeks from the Monday of the current week.
+func (s *ResourceCapacityService) GetCalendar(ctx context.Context, userID string, from, to *time.Time) (*domain.CapacityCalendarDTO, error) {
+	start, end, err := s.period(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	hours, err := s.hoursPerWeek(ctx, []string{userID})
+	if err != nil {
+		return nil, err
+	}
+	absences, err := s.capacityRepo.ListAbsences(ctx, []string{userID}, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list absences: %w", err)
+	}
+	allocations, err := s.capacityRepo.ListAllocationsByUsers(ctx, []string{userID}, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list resource allocations: %w", err)
+	}
+
+	calendar := &domain.CapacityCalendarDTO{
+		UserID:       userID,
+		HoursPerWeek: hours[userID],
+		From:         start.Format("2006-01-02"),
+		To:           end.Format("2006-01-02"),
+		Weeks:        BuildCapacityWeeks(hours[userID], absences, allocations, start, end),
+		Absences:     make([]domain.UserAbsenceDTO, len(absences)),
+		Allocations:  make([]domain.ResourceAllocationDTO, len(allocations)),
+	}
+	if user := s.lookupUser(ctx, userID); user != nil {
+		calendar.UserName = user.DisplayName
+	}
+	for i := range absences {
+		calendar.Absences[i] = mapper.ToUserAbsenceDTO(&absences[i])
+	}
+	for i := range allocations {
+		calendar.Allocations[i] = mapper.ToResourceAllocationDTO(&allocations[i])
+		if calendar.UserName == "" {
+			calendar.UserName = allocations[i].UserName
+		}
+	}
+	for _, week := range calendar.Weeks {
+		calendar.AvailableHours += week.AvailableHours
+		calendar.AllocatedHours += week.AllocatedHours
+		if week.Overallocated {
+			calendar.OverallocatedWeeks++
+		}
+	}
+	calendar.AvailableHours = roundHours(calendar.AvailableHours)
+	calendar.AllocatedHours = roundHours(calendar.AllocatedHours)
+
+	return calendar, nil
+}
+
+// SetHoursPerWeek sets a user's working hours per week. Requires manager or admin role.
+func (s *ResourceCapacityService) SetHoursPerWeek(ctx context.Context, userID string, req *domain.UpdateUserCapacityRequest) (*domain.CapacityCalendarDTO, error) {
+	user, err := s.checkManageUser(ctx, userID, false)
+	if err != nil {
+		return nil, err
+	}
+
+	capacity := &domain.UserCapacity{
+		UserID:       userID,
+		HoursPerWeek: req.HoursPerWeek,
+	}
+	if user != nil {
+		capacity.UserName = user.DisplayName
+	}
+	if userCtx, ok := auth.FromContext(ctx); ok {
+		capacity.UpdatedByID = userCtx.UserID.String()
+		capacity.UpdatedByName = userCtx.DisplayName
+	}
+	if err := s.capacityRepo.UpsertCapacity(ctx, capacity); err != nil {
+		return nil, fmt.Errorf("failed to set hours per week: %w", err)
+	}
+
+	return s.GetCalendar(ctx, userID, nil, nil)
+}
+
+// CreateAbsence registers an absence. Users can register their own; managers and admins anyone's.
+func (s *ResourceCapacityService) CreateAbsence(ctx context.Context, userID string, req *domain.CreateUserAbsenceRequest) (*domain.UserAbsenceDTO, error) {
+	user, err := s.checkManageUser(ctx, userID, true)
+	if err != nil {
+		return nil, err
+	}
+	if !req.Kind.IsValid() {
+		return nil, fmt.Errorf("%w: unknown kind %q", ErrInvalidUserAbsence, req.Kind)
+	}
+	start, end, err := parseDateRange(req.StartDate, req.EndDate)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidUserAbsence, err.Error())
+	}
+
+	absence := &domain.UserAbsence{
+		UserID:    userID,
+		Kind:      req.Kind,
+		StartDate: start,
+		EndDate:   end,
+		Percent:   req.Percent,
+		Notes:     req.Notes,
+	}
+	if absence.Percent == 0 {
+		absence.Percent = 100
+	}
+	if user != nil {
+		absence.UserName = user.DisplayName
+	}
+	if userCtx, ok := auth.FromContext(ctx); ok {
+		absence.CreatedByID = userCtx.UserID.String()
+		absence.CreatedByName = userCtx.DisplayName
+	}
+
+	if err := s.capacityRepo.CreateAbsence(ctx, absence); err != nil {
+		return nil, fmt.Errorf("failed to create absence: %w", err)
+	}
+
+	dto := mapper.ToUserAbsenceDTO(absence)
+	return &dto, nil
+}
+
+// DeleteAbsence removes an absence of a user
+func (s *ResourceCapacityService) DeleteAbsence(ctx context.Context, userID string, id uuid.UUID) error {
+	if _, err := s.checkManageUser(ctx, userID, true); err != nil {
+		return err
+	}
+	if _, err := s.capacityRepo.GetAbsence(ctx, userID, id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrUserAbsenceNotFound
+		}
+		return fmt.Errorf("failed to get absence: %w", err)
+	}
+
+	if err := s.capacityRepo.DeleteAbsence(ctx, id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrUserAbsenceNotFound
+		}
+		return fmt.Errorf("failed to delete absence: %w", err)
+	}
+	return nil
+}
+
+// GetOverallocationReport lists, per company, the users allocated to its offers and projects in the period
+// who are booked beyond their available hours. A user's load counts their allocations in every company.
+func (s *ResourceCapacityService) GetOverallocationReport(ctx context.Context, from, to *time.Time, companyID *domain.CompanyID) (*domain.CapacityOverallocationReportDTO, error) {
+	start, end, err := s.period(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	companyAllocations, err := s.capacityRepo.ListAllocations(ctx, &repository.ResourceAllocationFilters{
+		CompanyID: companyID,
+		From:      &start,
+		To:        &end,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list resource allocations: %w", err)
+	}
+
+	companyUsers := make(map[domain.CompanyID]map[string]bool)
+	var userIDs []string
+	seen := make(map[string]bool)
+	for _, allocation := range companyAllocations {
+		if companyUsers[allocation.CompanyID] == nil {
+			companyUsers[allocation.CompanyID] = make(map[string]bool)
+		}
+		companyUsers[allocation.CompanyID][allocation.UserID] = true
+		if !seen[allocation.UserID] {
+			seen[allocation.UserID] = true
+			userIDs = append(userIDs, allocation.UserID)
+		}
+	}
+
+	overallocated, err := s.overallocatedUsers(ctx, userIDs, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &domain.CapacityOverallocationReportDTO{
+		From:      start.Format("2006-01-02"),
+		To:        end.Format("2006-01-02"),
+		Companies: make([]domain.CapacityCompanyOverallocationDTO, 0, len(companyUsers)),
+	}
+	for company, users := range companyUsers {
+		entry := domain.CapacityCompanyOverallocationDTO{
+			CompanyID:      company,
+			AllocatedUsers: len(users),
+			Users:          []domain.CapacityOverallocatedUserDTO{},
+		}
+		for userID := range users {
+			if user, ok := overallocated[userID]; ok {
+				entry.Users = append(entry.Users, *user)
+			}
+		}
+		entry.OverallocatedUsers = len(entry.Users)
+		sort.Slice(entry.Users, func(i, j int) bool {
+			if entry.Users[i].ExcessHours != entry.Users[j].ExcessHours {
+				return entry.Users[i].ExcessHours > entry.Users[j].ExcessHours
+			}
+			return entry.Users[i].UserName < entry.Users[j].UserName
+		})
+		report.Companies = append(report.Companies, entry)
+	}
+	sort.Slice(report.Companies, func(i, j int) bool { return report.Companies[i].CompanyID < report.Companies[j].CompanyID })
+
+	return report, nil
+}
+
+// CheckManagerCapacity returns a warning when the offer's manager is already booked beyond capacity in
+// the offer's start-end window. Without a start date the window starts today, and without an end date
+// it covers the default number of weeks.
+func (s *ResourceCapacityService) CheckManagerCapacity(ctx context.Context, offer *domain.Offer) (*domain.CapacityWarningDTO, error) {
+	if offer.ManagerID == nil || *offer.ManagerID == "" {
+		return nil, nil
+	}
+
+	start := dateInOslo(s.now())
+	if offer.StartDate != nil {
+		start = dateInOslo(*offer.StartDate)
+	}
+	end := start.AddDate(0, 0, capacityDefaultWeeks*7-1)
+	if offer.EndDate != nil {
+		end = dateInOslo(*offer.EndDate)
+	}
+	if end.Before(start) {
+		return nil, nil
+	}
+
+	overallocated, err := s.overallocatedUsers(ctx, []string{*offer.ManagerID}, start, end)
+	if err != nil {
+		return nil, err
+	}
+	user, ok := overallocated[*offer.ManagerID]
+	if !ok {
+		return nil, nil
+	}
+
+	name := offer.ManagerName
+	if name == "" {
+		name = user.UserName
+	}
+	return &domain.CapacityWarningDTO{
+		Code:     WarningManagerOverCapacity,
+		UserID:   user.UserID,
+		UserName: name,
+		From:     start.Format("2006-01-02"),
+		To:       end.Format("2006-01-02"),
+		Weeks:    user.Weeks,
+		Message: fmt.Sprintf("%s er allerede overbooket i %d uke(r) mellom %s og %s (%.1f timer over kapasitet)",
+			name, len(user.Weeks), start.Format("2006-01-02"), end.Format("2006-01-02"), user.ExcessHours),
+	}, nil
+}
+
+// overallocatedUsers computes the load of the users in the period and returns those booked beyond their
+// available hours in at least one week, by user ID
+func (s *ResourceCapacityService) overallocatedUsers(ctx context.Context, userIDs []string, start, end time.Time) (map[string]*domain.CapacityOverallocatedUserDTO, error) {
+	result := make(map[string]*domain.CapacityOverallocatedUserDTO)
+	if len(userIDs) == 0 {
+		return result, nil
+	}
+
+	hours, err := s.hoursPerWeek(ctx, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	absences, err := s.capacityRepo.ListAbsences(ctx, userIDs, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list absences: %w", err)
+	}
+	allocations, err := s.capacityRepo.ListAllocationsByUsers(ctx, userIDs, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list resource allocations: %w", err)
+	}
+
+	absencesByUser := make(map[string][]domain.UserAbsence)
+	for _, absence := range absences {
+		absencesByUser[absence.UserID] = append(absencesByUser[absence.UserID], absence)
+	}
+	allocationsByUser := make(map[string][]domain.ResourceAllocation)
+	userNames := make(map[string]string)
+	for _, allocation := range allocations {
+		allocationsByUser[allocation.UserID] = append(allocationsByUser[allocation.UserID], allocation)
+		if allocation.UserName != "" {
+			userNames[allocation.UserID] = allocation.UserName
+		}
+	}
+
+	for _, userID := range userIDs {
+		weeks := BuildCapacityWeeks(hours[userID], absencesByUser[userID], allocationsByUser[userID], start, end)
+		user := &domain.CapacityOverallocatedUserDTO{
+			UserID:       userID,
+			UserName:     userNames[userID],
+			HoursPerWeek: hours[userID],
+			Weeks:        []domain.CapacityWeekDTO{},
+		}
+		for _, week := range weeks {
+			if !week.Overallocated {
+				continue
+			}
+			user.Weeks = append(user.Weeks, week)
+			user.ExcessHours -= week.FreeHours
+			if week.UtilizationPercent != nil && *week.UtilizationPercent > user.PeakUtilizationPercent {
+				user.PeakUtilizationPercent = *week.UtilizationPercent
+			}
+		}
+		if len(user.Weeks) > 0 {
+			user.ExcessHours = roundHours(user.ExcessHours)
+			result[userID] = user
+		}
+	}
+	return result, nil
+}
+
+// hoursPerWeek returns the weekly hours of the users, the standard week for users without their own
+func (s *ResourceCapacityService) hoursPerWeek(ctx context.Context, userIDs []string) (map[string]float64, error) {
+	capacities, err := s.capacityRepo.ListCapacities(ctx, userIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list user capacities: %w", err)
+	}
+	hours := make(map[string]float64, len(userIDs))
+	for _, userID := range userIDs {
+		hours[userID] = domain.StandardHoursPerWeek
+	}
+	for _, capacity := range capacities {
+		hours[capacity.UserID] = capacity.HoursPerWeek
+	}
+	return hours, nil
+}
+
+// period resolves the calendar and report period, by default the coming weeks from this week's Monday
+func (s *ResourceCapacityService) period(from, to *time.Time) (time.Time, time.Time, error) {
+	today := dateInOslo(s.now())
+	start := today.AddDate(0, 0, -((int(today.Weekday()) + 6) % 7))
+	if from != nil {
+		start = dateInOslo(*from)
+	}
+	end := start.AddDate(0, 0, capacityDefaultWeeks*7-1)
+	if to != nil {
+		end = dateInOslo(*to)
+	}
+	if end.Before(start) {
+		return time.Time{}, time.Time{}, ErrInvalidDateRange
+	}
+	if end.Sub(start) > capacityMaxDays*24*time.Hour {
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: the period cannot be longer than %d days", ErrInvalidDateRange, capacityMaxDays)
+	}
+	return start, end, nil
+}
+
+// applyAllocationSize sets the period and the size of an allocation from a request
+func applyAllocationSize(allocation *domain.ResourceAllocation, startDate, endDate string, hoursPerWeek, percent *float64) error {
+	start, end, err := parseDateRange(startDate, endDate)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidResourceAllocation, err.Error())
+	}
+	if (hoursPerWeek == nil) == (percent == nil) {
+		return fmt.Errorf("%w: set either hoursPerWeek or percent", ErrInvalidResourceAllocation)
+	}
+	allocation.StartDate = start
+	allocation.EndDate = end
+	allocation.HoursPerWeek = hoursPerWeek
+	allocation.Percent = percent
+	return nil
+}
+
+// parseDateRange parses a YYYY-MM-DD start and end date where the end is not before the start
+func parseDateRange(startDate, endDate string) (time.Time, time.Time, error) {
+	start, err := time.Parse("2006-01-02", startDate)
+	if err != nil {
+		return time.Time{}, time.Time{}, errors.New("startDate must be a date (YYYY-MM-DD)")
+	}
+	end, err := time.Parse("2006-01-02", endDate)
+	if err != nil {
+		return time.Time{}, time.Time{}, errors.New("endDate must be a date (YYYY-MM-DD)")
+	}
+	if end.Before(start) {
+		return time.Time{}, time.Time{}, errors.New("endDate must not be before startDate")
+	}
+	return start, end, nil
+}
+
+func (s *ResourceCapacityService) getAllocation(ctx context.Context, id uuid.UUID) (*domain.ResourceAllocation, error) {
+	allocation, err := s.capacityRepo.GetAllocation(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrResourceAllocationNotFound
+		}
+		return nil, fmt.Errorf("failed to get resource allocation: %w", err)
+	}
+	return allocation, nil
+}
+
+// lookupUser returns the user, or nil when the user has not signed in yet
+func (s *ResourceCapacityService) lookupUser(ctx context.Context, userID string) *domain.User {
+	user, err := s.userRepo.GetByStringID(ctx, userID)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			s.logger.Warn("failed to look up user", zap.String("user_id", userID), zap.Error(err))
+		}
+		return nil
+	}
+	return user
+}
+
+// checkManage requires a manager or admin role with access to the company
+func (s *ResourceCapacityService) checkManage(ctx context.Context, companyID domain.CompanyID) error {
+	userCtx, ok := auth.FromContext(ctx)
+	if !ok {
+		return ErrUnauthorized
+	}
+	if !userCtx.HasAnyRole(domain.RoleManager, domain.RoleCompanyAdmin, domain.RoleSuperAdmin) || !userCtx.CanAccessCompany(companyID) {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// checkManageUser requires a manager or admin role with access to the user's company, or with allowSelf
+// that the user manages their own capacity. Returns the user when they have signed in.
+func (s *ResourceCapacityService) checkManageUser(ctx context.Context, userID string, allowSelf bool) (*domain.User, error) {
+	userCtx, ok := auth.FromContext(ctx)
+	if !ok {
+		return nil, ErrUnauthorized
+	}
+	user := s.lookupUser(ctx, userID)
+	if allowSelf && userCtx.UserID.String() == userID {
+		return user, nil
+	}
+	if !userCtx.HasAnyRole(domain.RoleManager, domain.RoleCompanyAdmin, domain.RoleSuperAdmin) {
+		return nil, ErrForbidden
+	}
+	if user != nil && user.CompanyID != nil && !userCtx.CanAccessCompany(*user.CompanyID) {
+		return nil, ErrForbidden
+	}
+	return user, nil
+}
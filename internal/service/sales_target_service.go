@@ -0,0 +1,298 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/straye-as/relation-api/internal/auth"
+	"github.com/straye-as/relation-api/internal/domain"
+	"github.com/straye-as/relation-api/internal/mapper"
+	"github.com/straye-as/relation-api/internal/repository"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// SalesTargetService manages sales targets and measures attainment against won deals and offers
+type SalesTargetService struct {
+	targetRepo *repository.SalesTargetRepository
+	dealRepo   *repository.DealRepository
+	logger     *zap.Logger
+}
+
+// NewSalesTargetService creates a new sales target service
+func NewSalesTargetService(targetRepo *repository.SalesTargetRepository, dealRepo *repository.DealRepository, logger *zap.Logger) *SalesTargetService {
+	return &SalesTargetService{
+		targetRepo: targetRepo,
+		dealRepo:   dealRepo,
+		logger:     logger,
+	}
+}
+
+// List returns the sales targets visible to the user
+func (s *SalesTargetService) List(ctx context.Context, filters *repository.SalesTargetFilters) ([]domain.SalesTargetDTO, error) {
+	targets, err := s.targetRepo.List(ctx, filters)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sales targets: %w", err)
+	}
+
+	dtos := make([]domain.SalesTargetDTO, len(targets))
+	for i := range targets {
+		dtos[i] = mapper.ToSalesTargetDTO(&targets[i])
+	}
+	return dtos, nil
+}
+
+// GetByID returns a sales target
+func (s *SalesTargetService) GetByID(ctx context.Context, id uuid.UUID) (*domain.SalesTargetDTO, error) {
+	target, err := s.get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	dto := mapper.ToSalesTargetDTO(target)
+	return &dto, nil
+}
+
+// Create adds a sales target. The period start is moved to the first day of the period containing it.
+func (s *SalesTargetService) Create(ctx context.Context, req *domain.CreateSalesTargetRequest) (*domain.SalesTargetDTO, error) {
+	if err := s.checkManage(ctx, req.CompanyID); err != nil {
+		return nil, err
+	}
+	if !domain.IsValidCompanyID(string(req.CompanyID)) || req.CompanyID == domain.CompanyAll {
+		return nil, fmt.Errorf("%w: unknown company %q", ErrInvalidSalesTarget, req.CompanyID)
+	}
+	if !req.PeriodType.IsValid() {
+		return nil, fmt.Errorf("%w: unknown period type %q", ErrInvalidSalesTarget, req.PeriodType)
+	}
+	if !req.Metric.IsValid() {
+		return nil, fmt.Errorf("%w: unknown metric %q", ErrInvalidSalesTarget, req.Metric)
+	}
+	periodDate, err := time.Parse("2006-01-02", req.PeriodStart)
+	if err != nil {
+		return nil, fmt.Errorf("%w: periodStart must be a date (YYYY-MM-DD)", ErrInvalidSalesTarget)
+	}
+
+	target := &domain.SalesTarget{
+		CompanyID:   req.CompanyID,
+		PeriodType:  req.PeriodType,
+		PeriodStart: req.PeriodType.StartOf(periodDate),
+		Metric:      req.Metric,
+		TargetValue: req.TargetValue,
+		Notes:       req.Notes,
+	}
+	if req.UserID != nil && *req.UserID != "" {
+		target.UserID = req.UserID
+		target.UserName = req.UserName
+	}
+	if userCtx, ok := auth.FromContext(ctx); ok {
+		target.CreatedByID = userCtx.UserID.String()
+		target.CreatedByName = userCtx.DisplayName
+	}
+
+	exists, err := s.targetRepo.Exists(ctx, target)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check existing sales targets: %w", err)
+	}
+	if exists {
+		return nil, ErrSalesTargetExists
+	}
+
+	if err := s.targetRepo.Create(ctx, target); err != nil {
+		return nil, fmt.Errorf("failed to create sales target: %w", err)
+	}
+
+	s.logger.Info("sales target created",
+		zap.String("target_id", target.ID.String()),
+		zap.String("company_id", string(target.CompanyID)),
+		zap.String("period_type", string(target.PeriodType)),
+		zap.String("metric", string(target.Metric)))
+
+	dto := mapper.ToSalesTargetDTO(target)
+	return &dto, nil
+}
+
+// Update changes the target value and notes of a sales target
+func (s *SalesTargetService) Update(ctx context.Context, id uuid.UUID, req *domain.UpdateSalesTargetRequest) (*domain.SalesTargetDTO, error) {
+	target, err := s.get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkManage(ctx, target.CompanyID); err != nil {
+		return nil, err
+	}
+
+	target.TargetValue = req.TargetValue
+	target.Notes = req.Notes
+	if err := s.targetRepo.Update(ctx, target); err != nil {
+		return nil, fmt.Errorf("failed to update sales target: %w", err)
+	}
+
+	dto := mapper.ToSalesTargetDTO(target)
+	return &dto, nil
+}
+
+// Delete removes a sales target
+func (s *SalesTargetService) Delete(ctx context.Context, id uuid.UUID) error {
+	target, err := s.get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.checkManage(ctx, target.CompanyID); err != nil {
+		return err
+	}
+
+	if err := s.targetRepo.Delete(ctx, id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrSalesTargetNotFound
+		}
+		return fmt.Errorf("failed to delete sales target: %w", err)
+	}
+	return nil
+}
+
+// GetAttainment measures the targets whose period contains the given date (today when nil)
+// against won deals and offers, and the weighted pipeline expected to close before each period ends
+func (s *SalesTargetService) GetAttainment(ctx context.Context, filters *repository.SalesTargetFilters, date *time.Time) ([]domain.SalesTargetAttainmentDTO, error) {
+	now := time.Now()
+	on := now
+	if date != nil {
+		on = *date
+	}
+	if filters == nil {
+		filters = &repository.SalesTargetFilters{}
+	}
+	filters.ActiveOn = &on
+
+	targets, err := s.targetRepo.List(ctx, filters)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sales targets: %w", err)
+	}
+
+	results := make([]domain.SalesTargetAttainmentDTO, len(targets))
+	for i := range targets {
+		attainment, err := s.attainment(ctx, &targets[i], now)
+		if err != nil {
+			return nil, err
+		}
+		results[i] = *attainment
+	}
+	return results, nil
+}
+
+// attainment computes how far a target has come at the given time
+func (s *SalesTargetService) attainment(ctx context.Context, target *domain.SalesTarget, now time.Time) (*domain.SalesTargetAttainmentDTO, error) {
+	start := target.PeriodStart
+	end := target.PeriodEnd()
+
+	// Only count what has been won so far
+	until := end
+	if now.Before(until) {
+		until = now
+	}
+	actual, err := s.targetRepo.GetAchieved(ctx, target.Metric, target.CompanyID, target.UserID, start, until)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get achieved %s: %w", target.Metric, err)
+	}
+
+	pipeline, err := s.weightedPipeline(ctx, target, now)
+	if err != nil {
+		return nil, err
+	}
+
+	return buildSalesTargetAttainment(target, actual, pipeline, now), nil
+}
+
+// weightedPipeline returns the weighted value of open deals expected to close between now (or the
+// period start, if later) and the end of the target's period
+func (s *SalesTargetService) weightedPipeline(ctx context.Context, target *domain.SalesTarget, now time.Time) (float64, error) {
+	daysToEnd := daysUntil(now, target.PeriodEnd())
+	if daysToEnd <= 0 {
+		return 0, nil
+	}
+	companyID := target.CompanyID
+
+	forecast, err := s.dealRepo.GetRevenueForecastByDays(ctx, daysToEnd, &companyID, target.UserID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get revenue forecast: %w", err)
+	}
+	pipeline := forecast.WeightedValue
+
+	// Deals closing before a future period starts do not count towards it
+	if daysToStart := daysUntil(now, target.PeriodStart); daysToStart > 0 {
+		before, err := s.dealRepo.GetRevenueForecastByDays(ctx, daysToStart, &companyID, target.UserID)
+		if err != nil {
+			return 0, fmt.Errorf("failed to get revenue forecast: %w", err)
+		}
+		pipeline -= before.WeightedValue
+	}
+	return pipeline, nil
+}
+
+// buildSalesTargetAttainment derives pace, gap and forecast coverage from the achieved value and weighted pipeline
+func buildSalesTargetAttainment(target *domain.SalesTarget, actual, pipeline float64, now time.Time) *domain.SalesTargetAttainmentDTO {
+	start := target.PeriodStart
+	end := target.PeriodEnd()
+
+	elapsed := 0.0
+	switch {
+	case !now.Before(end):
+		elapsed = 1
+	case now.After(start):
+		elapsed = now.Sub(start).Seconds() / end.Sub(start).Seconds()
+	}
+
+	dto := &domain.SalesTargetAttainmentDTO{
+		Target:           mapper.ToSalesTargetDTO(target),
+		Actual:           roundAmount(actual),
+		ElapsedPercent:   math.Round(elapsed*1000) / 10,
+		ExpectedToDate:   roundAmount(target.TargetValue * elapsed),
+		Gap:              roundAmount(math.Max(target.TargetValue-actual, 0)),
+		WeightedPipeline: roundAmount(pipeline),
+		RemainingDays:    max(daysUntil(now, end), 0),
+	}
+	if target.TargetValue > 0 {
+		dto.AttainmentPercent = math.Round(actual/target.TargetValue*1000) / 10
+	}
+	if dto.ExpectedToDate > 0 {
+		dto.PacePercent = math.Round(actual/dto.ExpectedToDate*1000) / 10
+	}
+	if elapsed > 0 {
+		dto.ProjectedValue = roundAmount(actual / elapsed)
+	}
+	if dto.Gap > 0 {
+		coverage := math.Round(pipeline/dto.Gap*1000) / 10
+		dto.ForecastCoverage = &coverage
+	}
+	return dto
+}
+
+// daysUntil returns the number of days from now until t, rounded up
+func daysUntil(now, t time.Time) int {
+	return int(math.Ceil(t.Sub(now).Hours() / 24))
+}
+
+func (s *SalesTargetService) get(ctx context.Context, id uuid.UUID) (*domain.SalesTarget, error) {
+	target, err := s.targetRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSalesTargetNotFound
+		}
+		return nil, fmt.Errorf("failed to get sales target: %w", err)
+	}
+	return target, nil
+}
+
+// checkManage verifies that the user may set the sales targets of a company
+func (s *SalesTargetService) checkManage(ctx context.Context, companyID domain.CompanyID) error {
+	userCtx, ok := auth.FromContext(ctx)
+	if !ok {
+		return ErrUnauthorized
+	}
+	if !userCtx.HasAnyRole(domain.RoleManager, domain.RoleCompanyAdmin, domain.RoleSuperAdmin) || !userCtx.CanAccessCompany(companyID) {
+		return ErrForbidden
+	}
+	return nil
+}
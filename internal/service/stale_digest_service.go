@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/straye-as/relation-api/internal/domain"
+	"github.com/straye-as/relation-api/internal/repository"
+	"go.uber.org/zap"
+)
+
+// staleDigestMaxTitles is how many item titles are listed in a digest before it is summarized
+const staleDigestMaxTitles = 5
+
+// StaleDigestService sends the weekly digest of stale deals and offers to their owners and company managers
+type StaleDigestService struct {
+	dealRepo         *repository.DealRepository
+	offerRepo        *repository.OfferRepository
+	userRoleRepo     *repository.UserRoleRepository
+	notificationRepo *repository.NotificationRepository
+	offerThresholds  repository.OfferStaleThresholds
+	logger           *zap.Logger
+}
+
+// NewStaleDigestService creates a new stale digest service
+func NewStaleDigestService(
+	dealRepo *repository.DealRepository,
+	offerRepo *repository.OfferRepository,
+	userRoleRepo *repository.UserRoleRepository,
+	notificationRepo *repository.NotificationRepository,
+	offerThresholds repository.OfferStaleThresholds,
+	logger *zap.Logger,
+) *StaleDigestService {
+	return &StaleDigestService{
+		dealRepo:         dealRepo,
+		offerRepo:        offerRepo,
+		userRoleRepo:     userRoleRepo,
+		notificationRepo: notificationRepo,
+		offerThresholds:  offerThresholds,
+		logger:           logger,
+	}
+}
+
+// staleDigest collects the stale items for one recipient
+type staleDigest struct {
+	dealTitles  []string
+	offerTitles []string
+}
+
+// SendDigest notifies owners of their stale deals and offers, and company managers of all stale items in their company.
+// Returns the number of stale deals and offers found and the number of digests sent.
+func (s *StaleDigestService) SendDigest(ctx context.Context) (staleDeals int, staleOffers int, sent int, err error) {
+	deals, err := s.dealRepo.ListStale(ctx)
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("failed to list stale deals: %w", err)
+	}
+	offers, err := s.offerRepo.ListStale(ctx, s.offerThresholds)
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("failed to list stale offers: %w", err)
+	}
+
+	digests := make(map[uuid.UUID]*staleDigest)
+	digestFor := func(userID string) *staleDigest {
+		id, err := uuid.Parse(userID)
+		if err != nil {
+			return nil
+		}
+		if digests[id] == nil {
+			digests[id] = &staleDigest{}
+		}
+		return digests[id]
+	}
+
+	managers := make(map[domain.CompanyID][]string)
+	managersOf := func(companyID domain.CompanyID) []string {
+		if ids, ok := managers[companyID]; ok {
+			return ids
+		}
+		ids, err := s.userRoleRepo.ListUserIDsWithRoleInCompany(ctx, domain.RoleManager, companyID)
+		if err != nil {
+			s.logger.Warn("failed to list managers for stale digest",
+				zap.Error(err),
+				zap.String("company_id", string(companyID)))
+		}
+		managers[companyID] = ids
+		return ids
+	}
+
+	for _, deal := range deals {
+		recipients := append([]string{deal.OwnerID}, managersOf(deal.CompanyID)...)
+		for _, userID := range uniqueStrings(recipients) {
+			if d := digestFor(userID); d != nil {
+				d.dealTitles = append(d.dealTitles, deal.Title)
+			}
+		}
+	}
+	for _, offer := range offers {
+		recipients := append([]string{offer.ResponsibleUserID}, managersOf(offer.CompanyID)...)
+		for _, userID := range uniqueStrings(recipients) {
+			if d := digestFor(userID); d != nil {
+				d.offerTitles = append(d.offerTitles, offer.Title)
+			}
+		}
+	}
+
+	for userID, digest := range digests {
+		notification := &domain.Notification{
+			UserID:  userID,
+			Type:    string(domain.NotificationTypeStaleDigest),
+			Title:   "Ukentlig oversikt: saker uten aktivitet",
+			Message: staleDigestMessage(digest),
+		}
+		if err := s.notificationRepo.Create(ctx, notification); err != nil {
+			s.logger.Warn("failed to create stale digest notification",
+				zap.Error(err),
+				zap.String("user_id", userID.String()))
+			continue
+		}
+		sent++
+	}
+
+	return len(deals), len(offers), sent, nil
+}
+
+// staleDigestMessage summarizes a digest, listing the oldest items first and the rest as a count
+func staleDigestMessage(digest *staleDigest) string {
+	var parts []string
+	if len(digest.dealTitles) > 0 {
+		parts = append(parts, fmt.Sprintf("%d avtaler: %s", len(digest.dealTitles), summarizeTitles(digest.dealTitles)))
+	}
+	if len(digest.offerTitles) > 0 {
+		parts = append(parts, fmt.Sprintf("%d tilbud: %s", len(digest.offerTitles), summarizeTitles(digest.offerTitles)))
+	}
+	return truncateRunes("Uten aktivitet innenfor fristen - "+strings.Join(parts, ". "), 500)
+}
+
+// summarizeTitles lists up to staleDigestMaxTitles titles and counts the rest
+func summarizeTitles(titles []string) string {
+	if len(titles) <= staleDigestMaxTitles {
+		return strings.Join(titles, ", ")
+	}
+	return fmt.Sprintf("%s og %d til", strings.Join(titles[:staleDigestMaxTitles], ", "), len(titles)-staleDigestMaxTitles)
+}
+
+// uniqueStrings returns the non-empty values in sorted order without duplicates
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	result := make([]string, 0, len(values))
+	for _, v := range values {
+		if v == "" || seen[v] {
+			continue
+		}
+		seen[v] = true
+		result = append(result, v)
+	}
+	sort.Strings(result)
+	return result
+}
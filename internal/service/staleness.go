@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/straye-as/relation-api/internal/domain"
+	"github.com/straye-as/relation-api/internal/repository"
+)
+
+// staleness is the computed staleness of a deal or offer
+type staleness struct {
+	DaysInStage           int
+	DaysSinceLastActivity *int
+	IsStale               bool
+}
+
+// daysSince returns the number of whole days from t to now, never negative
+func daysSince(t, now time.Time) int {
+	days := int(now.Sub(t).Hours() / 24)
+	if days < 0 {
+		return 0
+	}
+	return days
+}
+
+// computeStaleness derives days in stage, days since last activity and staleness.
+// An item is stale when neither its stage change nor its latest activity is within the stage threshold.
+func computeStaleness(info repository.StalenessInfo, now time.Time) staleness {
+	result := staleness{DaysInStage: daysSince(info.StageEnteredAt, now)}
+
+	lastTouched := info.StageEnteredAt
+	if info.LastActivityAt != nil {
+		days := daysSince(*info.LastActivityAt, now)
+		result.DaysSinceLastActivity = &days
+		if info.LastActivityAt.After(lastTouched) {
+			lastTouched = *info.LastActivityAt
+		}
+	}
+
+	if info.StaleAfterDays != nil {
+		result.IsStale = lastTouched.Before(now.AddDate(0, 0, -*info.StaleAfterDays))
+	}
+	return result
+}
+
+// applyDealStaleness fills the staleness fields of deal DTOs
+func (s *DealService) applyDealStaleness(ctx context.Context, dtos []domain.DealDTO) error {
+	if len(dtos) == 0 {
+		return nil
+	}
+	ids := make([]uuid.UUID, len(dtos))
+	for i := range dtos {
+		ids[i] = dtos[i].ID
+	}
+
+	infos, err := s.dealRepo.GetStaleness(ctx, ids)
+	if err != nil {
+		return fmt.Errorf("failed to get deal staleness: %w", err)
+	}
+
+	now := time.Now()
+	byID := make(map[uuid.UUID]staleness, len(infos))
+	for _, info := range infos {
+		byID[info.ID] = computeStaleness(info, now)
+	}
+	for i := range dtos {
+		st := byID[dtos[i].ID]
+		dtos[i].DaysInStage = st.DaysInStage
+		dtos[i].DaysSinceLastActivity = st.DaysSinceLastActivity
+		dtos[i].IsStale = st.IsStale
+	}
+	return nil
+}
+
+// applyOfferStaleness fills the staleness fields of offer DTOs
+func (s *OfferService) applyOfferStaleness(ctx context.Context, dtos []domain.OfferDTO) error {
+	if len(dtos) == 0 {
+		return nil
+	}
+	ids := make([]uuid.UUID, len(dtos))
+	for i := range dtos {
+		ids[i] = dtos[i].ID
+	}
+
+	infos, err := s.offerRepo.GetStaleness(ctx, ids, s.staleThresholds)
+	if err != nil {
+		return fmt.Errorf("failed to get offer staleness: %w", err)
+	}
+
+	now := time.Now()
+	byID := make(map[uuid.UUID]staleness, len(infos))
+	for _, info := range infos {
+		byID[info.ID] = computeStaleness(info, now)
+	}
+	for i := range dtos {
+		st := byID[dtos[i].ID]
+		dtos[i].DaysInPhase = st.DaysInStage
+		dtos[i].DaysSinceLastActivity = st.DaysSinceLastActivity
+		dtos[i].IsStale = st.IsStale
+	}
+	return nil
+}
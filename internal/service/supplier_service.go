@@ -78,9 +78,15 @@ func (s *SupplierService) Create(ctx context.Context, req *domain.CreateSupplier
 		return nil, fmt.Errorf("%w: contactPhone", ErrInvalidPhoneFormat)
 	}
 
+	// Validate org number (MOD11) and store it without formatting
+	if err := validateOrgNumber(req.OrgNumber); err != nil {
+		return nil, fmt.Errorf("%w: orgNumber", err)
+	}
+	orgNumber := normalizeOrgNumber(req.OrgNumber)
+
 	// Check for duplicate org number
-	if req.OrgNumber != "" {
-		existing, err := s.supplierRepo.GetByOrgNumber(ctx, req.OrgNumber)
+	if orgNumber != "" {
+		existing, err := s.supplierRepo.GetByOrgNumber(ctx, orgNumber)
 		if err == nil && existing != nil {
 			return nil, ErrDuplicateSupplierOrgNumber
 		}
@@ -98,7 +104,7 @@ func (s *SupplierService) Create(ctx context.Context, req *domain.CreateSupplier
 
 	supplier := &domain.Supplier{
 		Name:          req.Name,
-		OrgNumber:     req.OrgNumber,
+		OrgNumber:     orgNumber,
 		Email:         req.Email,
 		Phone:         req.Phone,
 		Address:       req.Address,
@@ -117,6 +123,9 @@ func (s *SupplierService) Create(ctx context.Context, req *domain.CreateSupplier
 		Website:       req.Website,
 	}
 
+	// Derive city, municipality and county from the postal code register
+	derivePostalAddress(supplier.Country, supplier.PostalCode, &supplier.City, &supplier.Municipality, &supplier.County)
+
 	// Set user tracking fields on creation
 	if userCtx, ok := auth.FromContext(ctx); ok {
 		supplier.CreatedByID = userCtx.UserID.String()
@@ -293,6 +302,11 @@ func (s *SupplierService) Update(ctx context.Context, id uuid.UUID, req *domain.
 		supplier.Status = req.Status
 	}
 
+	// A changed postal code determines city, municipality and county
+	if req.PostalCode != "" {
+		derivePostalAddress(supplier.Country, supplier.PostalCode, &supplier.City, &supplier.Municipality, &supplier.County)
+	}
+
 	// Set updated by fields
 	if userCtx, ok := auth.FromContext(ctx); ok {
 		supplier.UpdatedByID = userCtx.UserID.String()
@@ -649,7 +663,8 @@ func (s *SupplierService) UpdateAddress(ctx context.Context, id uuid.UUID, addre
 	return &dto, nil
 }
 
-// UpdatePostalCode updates only the supplier postal code
+// UpdatePostalCode updates the supplier postal code.
+// City, municipality and county are derived from the postal code register when the code is known.
 func (s *SupplierService) UpdatePostalCode(ctx context.Context, id uuid.UUID, postalCode string) (*domain.SupplierDTO, error) {
 	supplier, err := s.supplierRepo.GetByID(ctx, id)
 	if err != nil {
@@ -661,6 +676,7 @@ func (s *SupplierService) UpdatePostalCode(ctx context.Context, id uuid.UUID, po
 
 	oldPostalCode := supplier.PostalCode
 	supplier.PostalCode = postalCode
+	derivePostalAddress(supplier.Country, supplier.PostalCode, &supplier.City, &supplier.Municipality, &supplier.County)
 
 	// Set updated by fields
 	if userCtx, ok := auth.FromContext(ctx); ok {
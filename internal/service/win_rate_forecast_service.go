@@ -0,0 +1,411 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/straye-as/relation-api/internal/domain"
+	"github.com/straye-as/relation-api/internal/repository"
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultWinRateLookbackMonths is how far back closed deals and offers are used when none is given
+	DefaultWinRateLookbackMonths = 24
+
+	// winRatePriorStrength is the number of pseudo-outcomes a win rate is pulled towards its parent
+	// rate with, so segments with few closed deals stay close to the stage average
+	winRatePriorStrength = 10.0
+
+	// winRateConfidenceLevel is the confidence band reported around the expected value, and
+	// winRateConfidenceZ the matching two-sided z-score
+	winRateConfidenceLevel = 80
+	winRateConfidenceZ     = 1.2816
+
+	minWinProbability = 0.01
+	maxWinProbability = 0.99
+)
+
+// Dimensions a stage win rate is adjusted by
+const (
+	winRateDimensionCompany = "company"
+	winRateDimensionValue   = "value"
+	winRateDimensionTier    = "tier"
+	winRateDimensionOwner   = "owner"
+)
+
+// WinRateForecastService forecasts the pipeline with win probabilities learned from closed deals and offers
+type WinRateForecastService struct {
+	winRateRepo *repository.WinRateRepository
+	logger      *zap.Logger
+}
+
+// NewWinRateForecastService creates a new win-rate forecast service
+func NewWinRateForecastService(winRateRepo *repository.WinRateRepository, logger *zap.Logger) *WinRateForecastService {
+	return &WinRateForecastService{
+		winRateRepo: winRateRepo,
+		logger:      logger,
+	}
+}
+
+// GetCalibratedForecast forecasts the open deals and offers expected to close in the coming months
+// using win rates from the deals and offers closed during the lookback period, and reports how well
+// the probabilities entered by each salesperson matched what was actually won
+func (s *WinRateForecastService) GetCalibratedForecast(ctx context.Context, months, lookbackMonths int, filters *repository.WinRateFilters) (*domain.CalibratedForecastDTO, error) {
+	if months < 1 {
+		months = 3
+	}
+	if lookbackMonths < 1 {
+		lookbackMonths = DefaultWinRateLookbackMonths
+	}
+	now := time.Now()
+
+	outcomes, err := s.winRateRepo.ListClosedOutcomes(ctx, now.AddDate(0, -lookbackMonths, 0), filters)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list closed deals and offers: %w", err)
+	}
+	model := NewWinRateModel(outcomes)
+
+	// Monthly periods starting with the current month, as in the deal forecast
+	start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, months, 0)
+	items, err := s.winRateRepo.ListOpenItems(ctx, start, end, filters)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list open deals and offers: %w", err)
+	}
+
+	forecast := &domain.CalibratedForecastDTO{
+		GeneratedAt:     now.UTC().Format(time.RFC3339),
+		LookbackMonths:  lookbackMonths,
+		ConfidenceLevel: winRateConfidenceLevel,
+		SampleSize:      len(outcomes),
+		Items:           make([]domain.CalibratedForecastItemDTO, 0, len(items)),
+		StageWinRates:   model.StageWinRates(),
+	}
+	forecast.Calibration, forecast.OverallCalibration = BuildSalespersonCalibration(outcomes)
+
+	periods := make([]forecastAccumulator, months)
+	var total forecastAccumulator
+	sort.Slice(items, func(i, j int) bool { return items[i].ExpectedDate.Before(items[j].ExpectedDate) })
+	for i := range items {
+		item := &items[i]
+		probability, sampleSize := model.Estimate(item)
+
+		index := (item.ExpectedDate.Year()-start.Year())*12 + int(item.ExpectedDate.Month()) - int(start.Month())
+		if index < 0 || index >= months {
+			continue
+		}
+		periods[index].add(item, probability)
+		total.add(item, probability)
+
+		forecast.Items = append(forecast.Items, domain.CalibratedForecastItemDTO{
+			Type:                  item.Kind,
+			ID:                    item.ID,
+			Title:                 item.Title,
+			Stage:                 item.Stage,
+			CompanyID:             item.CompanyID,
+			OwnerID:               item.OwnerID,
+			OwnerName:             item.OwnerName,
+			Value:                 item.Value,
+			ExpectedDate:          item.ExpectedDate.Format("2006-01-02"),
+			EnteredProbability:    item.Probability,
+			CalibratedProbability: roundPercent(probability),
+			StageSampleSize:       sampleSize,
+		})
+	}
+
+	forecast.Periods = make([]domain.CalibratedForecastPeriodDTO, months)
+	for i := range periods {
+		periodStart := start.AddDate(0, i, 0)
+		forecast.Periods[i] = periods[i].toDTO(periodStart, periodStart.AddDate(0, 1, -1))
+	}
+	forecast.Total = total.toDTO(start, end.AddDate(0, 0, -1))
+
+	return forecast, nil
+}
+
+// forecastAccumulator sums the open items of a forecast period. Each item is treated as an
+// independent win or loss, so the variance of the period's value is the sum of v²·p·(1-p).
+type forecastAccumulator struct {
+	count         int
+	totalValue    float64
+	enteredValue  float64
+	expectedValue float64
+	variance      float64
+}
+
+func (a *forecastAccumulator) add(item *repository.OpenForecastItem, probability float64) {
+	a.count++
+	a.totalValue += item.Value
+	a.enteredValue += item.Value * float64(item.Probability) / 100
+	a.expectedValue += item.Value * probability
+	a.variance += item.Value * item.Value * probability * (1 - probability)
+}
+
+func (a *forecastAccumulator) toDTO(periodStart, periodEnd time.Time) domain.CalibratedForecastPeriodDTO {
+	spread := winRateConfidenceZ * math.Sqrt(a.variance)
+	return domain.CalibratedForecastPeriodDTO{
+		PeriodStart:          periodStart.Format("2006-01-02"),
+		PeriodEnd:            periodEnd.Format("2006-01-02"),
+		ItemCount:            a.count,
+		TotalValue:           roundAmount(a.totalValue),
+		EnteredWeightedValue: roundAmount(a.enteredValue),
+		ExpectedValue:        roundAmount(a.expectedValue),
+		LowValue:             roundAmount(math.Max(a.expectedValue-spread, 0)),
+		HighValue:            roundAmount(math.Min(a.expectedValue+spread, a.totalValue)),
+	}
+}
+
+// winRateCount counts closed outcomes
+type winRateCount struct {
+	closed int
+	won    int
+}
+
+// WinRateModel estimates the probability that an open deal or offer is won from the empirical win rate
+// of closed deals that reached the same stage (or offers that reached the same phase). The stage win
+// rate is adjusted by the win rates of the same company, value band, customer tier and owner at that
+// stage. Every rate is shrunk towards its parent rate, so thin segments barely move the estimate.
+type WinRateModel struct {
+	counts map[string]*winRateCount
+	stages map[string][]string
+}
+
+// NewWinRateModel learns win rates from closed deals and offers
+func NewWinRateModel(outcomes []repository.ClosedOutcome) *WinRateModel {
+	m := &WinRateModel{
+		counts: make(map[string]*winRateCount),
+		stages: make(map[string][]string),
+	}
+	for i := range outcomes {
+		outcome := &outcomes[i]
+		m.count(outcome.Won, outcome.Kind)
+		for _, stage := range outcome.Stages {
+			if m.counts[winRateKey(outcome.Kind, stage)] == nil {
+				m.stages[outcome.Kind] = append(m.stages[outcome.Kind], stage)
+			}
+			m.count(outcome.Won, outcome.Kind, stage)
+			for _, segment := range winRateSegments(outcome.CompanyID, outcome.Value, outcome.CustomerTier, outcome.OwnerID) {
+				m.count(outcome.Won, outcome.Kind, stage, segment.dimension, segment.value)
+			}
+		}
+	}
+	return m
+}
+
+// Estimate returns the calibrated win probability (0-1) of an open deal or offer and the number of
+// closed outcomes behind its stage's win rate
+func (m *WinRateModel) Estimate(item *repository.OpenForecastItem) (float64, int) {
+	overall := m.rate(0.5, item.Kind)
+	stageRate := m.rate(overall, item.Kind, item.Stage)
+
+	// Segments are compared with the stage's own rate shrunk the same way, so a segment
+	// covering every closed deal at the stage does not move the estimate
+	reference := winRateLogit(m.rate(stageRate, item.Kind, item.Stage))
+	logit := winRateLogit(stageRate)
+	for _, segment := range winRateSegments(item.CompanyID, item.Value, item.CustomerTier, item.OwnerID) {
+		logit += winRateLogit(m.rate(stageRate, item.Kind, item.Stage, segment.dimension, segment.value)) - reference
+	}
+	probability := math.Min(math.Max(1/(1+math.Exp(-logit)), minWinProbability), maxWinProbability)
+
+	sampleSize := 0
+	if c := m.counts[winRateKey(item.Kind, item.Stage)]; c != nil {
+		sampleSize = c.closed
+	}
+	return probability, sampleSize
+}
+
+// StageWinRates returns the raw win rate of each deal stage and offer phase seen in the closed outcomes.
+// Within deals and offers, stages reached by the most closed outcomes come first.
+func (m *WinRateModel) StageWinRates() []domain.StageWinRateDTO {
+	rates := []domain.StageWinRateDTO{}
+	for _, kind := range []string{repository.WinRateKindDeal, repository.WinRateKindOffer} {
+		stages := append([]string(nil), m.stages[kind]...)
+		sort.SliceStable(stages, func(i, j int) bool {
+			ci, cj := m.counts[winRateKey(kind, stages[i])], m.counts[winRateKey(kind, stages[j])]
+			if ci.closed != cj.closed {
+				return ci.closed > cj.closed
+			}
+			return stages[i] < stages[j]
+		})
+		for _, stage := range stages {
+			c := m.counts[winRateKey(kind, stage)]
+			rates = append(rates, domain.StageWinRateDTO{
+				Type:    kind,
+				Stage:   stage,
+				Closed:  c.closed,
+				Won:     c.won,
+				WinRate: roundPercent(float64(c.won) / float64(c.closed)),
+			})
+		}
+	}
+	return rates
+}
+
+func (m *WinRateModel) count(won bool, parts ...string) {
+	key := winRateKey(parts...)
+	c := m.counts[key]
+	if c == nil {
+		c = &winRateCount{}
+		m.counts[key] = c
+	}
+	c.closed++
+	if won {
+		c.won++
+	}
+}
+
+// rate returns the win rate of a segment shrunk towards the prior rate
+func (m *WinRateModel) rate(prior float64, parts ...string) float64 {
+	c := m.counts[winRateKey(parts...)]
+	if c == nil {
+		return prior
+	}
+	return (float64(c.won) + winRatePriorStrength*prior) / (float64(c.closed) + winRatePriorStrength)
+}
+
+func winRateKey(parts ...string) string {
+	return strings.Join(parts, "|")
+}
+
+// winRateSegment is the value of a deal or offer in one dimension the stage win rate is adjusted by
+type winRateSegment struct {
+	dimension string
+	value     string
+}
+
+func winRateSegments(companyID domain.CompanyID, value float64, tier domain.CustomerTier, ownerID string) []winRateSegment {
+	segments := []winRateSegment{
+		{winRateDimensionCompany, string(companyID)},
+		{winRateDimensionValue, winRateValueBand(value)},
+		{winRateDimensionTier, string(tier)},
+	}
+	if ownerID != "" {
+		segments = append(segments, winRateSegment{winRateDimensionOwner, ownerID})
+	}
+	return segments
+}
+
+// winRateValueBand groups deal and offer values in NOK
+func winRateValueBand(value float64) string {
+	switch {
+	case value < 100_000:
+		return "under_100k"
+	case value < 500_000:
+		return "100k_500k"
+	case value < 2_000_000:
+		return "500k_2m"
+	default:
+		return "over_2m"
+	}
+}
+
+func winRateLogit(p float64) float64 {
+	p = math.Min(math.Max(p, minWinProbability), maxWinProbability)
+	return math.Log(p / (1 - p))
+}
+
+// calibrationBuckets are the entered probability ranges outcomes are grouped by
+var calibrationBuckets = []struct {
+	label string
+	max   int
+}{
+	{"0-19", 19},
+	{"20-39", 39},
+	{"40-59", 59},
+	{"60-79", 79},
+	{"80-100", 100},
+}
+
+// BuildSalespersonCalibration compares the probabilities entered on closed deals and offers with their
+// outcomes, per salesperson (most closed first) and overall. Outcomes without an entered probability are skipped.
+func BuildSalespersonCalibration(outcomes []repository.ClosedOutcome) ([]domain.SalespersonCalibrationDTO, domain.SalespersonCalibrationDTO) {
+	byOwner := make(map[string][]*repository.ClosedOutcome)
+	var all []*repository.ClosedOutcome
+	for i := range outcomes {
+		outcome := &outcomes[i]
+		if outcome.EnteredProbability == nil {
+			continue
+		}
+		all = append(all, outcome)
+		byOwner[outcome.OwnerID] = append(byOwner[outcome.OwnerID], outcome)
+	}
+
+	calibration := make([]domain.SalespersonCalibrationDTO, 0, len(byOwner))
+	for ownerID, owned := range byOwner {
+		dto := buildCalibration(owned)
+		dto.OwnerID = ownerID
+		dto.OwnerName = owned[len(owned)-1].OwnerName
+		calibration = append(calibration, dto)
+	}
+	sort.Slice(calibration, func(i, j int) bool {
+		if calibration[i].Closed != calibration[j].Closed {
+			return calibration[i].Closed > calibration[j].Closed
+		}
+		return calibration[i].OwnerName < calibration[j].OwnerName
+	})
+
+	return calibration, buildCalibration(all)
+}
+
+func buildCalibration(outcomes []*repository.ClosedOutcome) domain.SalespersonCalibrationDTO {
+	type bucketSum struct {
+		closed, won int
+		entered     float64
+	}
+	buckets := make([]bucketSum, len(calibrationBuckets))
+
+	var won int
+	var enteredSum, squaredError float64
+	for _, outcome := range outcomes {
+		entered := float64(*outcome.EnteredProbability) / 100
+		actual := 0.0
+		if outcome.Won {
+			actual = 1
+			won++
+		}
+		enteredSum += entered
+		squaredError += (entered - actual) * (entered - actual)
+
+		for i, bucket := range calibrationBuckets {
+			if *outcome.EnteredProbability <= bucket.max || i == len(calibrationBuckets)-1 {
+				buckets[i].closed++
+				buckets[i].entered += entered
+				if outcome.Won {
+					buckets[i].won++
+				}
+				break
+			}
+		}
+	}
+
+	dto := domain.SalespersonCalibrationDTO{
+		Closed:  len(outcomes),
+		Won:     won,
+		Buckets: make([]domain.CalibrationBucketDTO, len(calibrationBuckets)),
+	}
+	for i, bucket := range calibrationBuckets {
+		dto.Buckets[i] = domain.CalibrationBucketDTO{Range: bucket.label, Closed: buckets[i].closed}
+		if buckets[i].closed > 0 {
+			dto.Buckets[i].AvgEnteredProbability = roundPercent(buckets[i].entered / float64(buckets[i].closed))
+			dto.Buckets[i].ActualWinRate = roundPercent(float64(buckets[i].won) / float64(buckets[i].closed))
+		}
+	}
+	if len(outcomes) > 0 {
+		n := float64(len(outcomes))
+		dto.AvgEnteredProbability = roundPercent(enteredSum / n)
+		dto.ActualWinRate = roundPercent(float64(won) / n)
+		dto.Bias = roundPercent(enteredSum/n - float64(won)/n)
+		dto.BrierScore = math.Round(squaredError/n*1000) / 1000
+	}
+	return dto
+}
+
+// roundPercent converts a 0-1 fraction to a percentage with one decimal
+func roundPercent(fraction float64) float64 {
+	return math.Round(fraction*1000) / 10
+}
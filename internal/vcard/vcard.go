@@ -0,0 +1,479 @@
+// Package vcard writes contacts as vCard 4.0 (RFC 6350) and reads vCard 2.1, 3.0 and 4.0 files
+// as exported by phones, Outlook and other address books.
+package vcard
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"mime/quotedprintable"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// MaxCards is the maximum number of cards accepted in a single file
+const MaxCards = 5000
+
+// maxLineOctets is the line length after which content lines are folded
+const maxLineOctets = 75
+
+var (
+	// ErrNoCards is returned when a file contains no vCards
+	ErrNoCards = errors.New("file contains no vCards")
+
+	// ErrTooManyCards is returned when a file contains more than MaxCards cards
+	ErrTooManyCards = fmt.Errorf("file contains more than %d vCards", MaxCards)
+
+	// ErrUnterminatedCard is returned when a card has no END:VCARD line
+	ErrUnterminatedCard = errors.New("vCard is missing END:VCARD")
+)
+
+// Address is the postal address of a card
+type Address struct {
+	Street     string
+	City       string
+	PostalCode string
+	Country    string
+}
+
+// IsZero reports whether the address is empty
+func (a Address) IsZero() bool {
+	return a.Street == "" && a.City == "" && a.PostalCode == "" && a.Country == ""
+}
+
+// Card is the subset of a vCard stored on a contact
+type Card struct {
+	UID          string
+	FullName     string // FN
+	GivenName    string
+	FamilyName   string
+	Organization string
+	Department   string
+	Title        string
+	Email        string
+	Phone        string // Work or voice phone
+	Mobile       string // Cell phone
+	Address      Address
+	URL          string
+	Note         string
+	Revision     time.Time
+}
+
+// Name returns the given and family name, derived from FN when the card has no N property
+func (c *Card) Name() (given, family string) {
+	if c.GivenName != "" || c.FamilyName != "" {
+		return c.GivenName, c.FamilyName
+	}
+	fullName := strings.TrimSpace(c.FullName)
+	if i := strings.LastIndex(fullName, " "); i > 0 {
+		return strings.TrimSpace(fullName[:i]), strings.TrimSpace(fullName[i+1:])
+	}
+	return fullName, ""
+}
+
+// ============================================================================
+// Writing
+// ============================================================================
+
+// Write writes the cards as vCard 4.0
+func Write(w io.Writer, cards ...Card) error {
+	bw := bufio.NewWriter(w)
+	for i := range cards {
+		for _, line := range cards[i].lines() {
+			if _, err := bw.WriteString(fold(line)); err != nil {
+				return err
+			}
+		}
+	}
+	return bw.Flush()
+}
+
+// lines returns the unfolded content lines of the card
+func (c *Card) lines() []string {
+	given, family := c.Name()
+	fullName := c.FullName
+	if fullName == "" {
+		fullName = strings.TrimSpace(given + " " + family)
+	}
+
+	lines := []string{"BEGIN:VCARD", "VERSION:4.0"}
+	if c.UID != "" {
+		lines = append(lines, "UID:"+c.UID)
+	}
+	lines = append(lines,
+		"FN:"+escape(fullName),
+		"N:"+structured(family, given, "", "", ""),
+	)
+	if c.Organization != "" || c.Department != "" {
+		lines = append(lines, "ORG:"+structured(c.Organization, c.Department))
+	}
+	if c.Title != "" {
+		lines = append(lines, "TITLE:"+escape(c.Title))
+	}
+	if c.Email != "" {
+		lines = append(lines, "EMAIL;TYPE=work:"+escape(c.Email))
+	}
+	if c.Phone != "" {
+		lines = append(lines, "TEL;VALUE=text;TYPE=work,voice:"+escape(c.Phone))
+	}
+	if c.Mobile != "" {
+		lines = append(lines, "TEL;VALUE=text;TYPE=cell:"+escape(c.Mobile))
+	}
+	if !c.Address.IsZero() {
+		lines = append(lines, "ADR;TYPE=work:"+structured("", "", c.Address.Street, c.Address.City, "", c.Address.PostalCode, c.Address.Country))
+	}
+	if c.URL != "" {
+		lines = append(lines, "URL:"+escape(c.URL))
+	}
+	if c.Note != "" {
+		lines = append(lines, "NOTE:"+escape(c.Note))
+	}
+	if !c.Revision.IsZero() {
+		lines = append(lines, "REV:"+c.Revision.UTC().Format("20060102T150405Z"))
+	}
+	return append(lines, "END:VCARD")
+}
+
+// escape escapes a text value
+func escape(value string) string {
+	return strings.NewReplacer(`\`, `\\`, ",", `\,`, ";", `\;`, "\r\n", `\n`, "\n", `\n`, "\r", `\n`).Replace(value)
+}
+
+// structured joins escaped components of a structured value such as N, ADR and ORG
+func structured(components ...string) string {
+	escaped := make([]string, len(components))
+	for i, component := range components {
+		escaped[i] = escape(component)
+	}
+	return strings.Join(escaped, ";")
+}
+
+// fold splits a content line into lines of at most 75 octets without splitting characters
+func fold(line string) string {
+	var b strings.Builder
+	limit := maxLineOctets
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		// Continuation lines start with a space
+		limit = maxLineOctets - 1
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+	return b.String()
+}
+
+// ============================================================================
+// Reading
+// ============================================================================
+
+// property is a parsed content line
+type property struct {
+	name   string
+	params map[string][]string
+	value  string
+}
+
+// hasType reports whether the property has one of the types (TYPE=cell or the vCard 2.1 form TEL;CELL)
+func (p *property) hasType(types ...string) bool {
+	for _, value := range p.params["TYPE"] {
+		for _, t := range types {
+			if strings.EqualFold(value, t) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// preferred reports whether the property is marked as the preferred value
+func (p *property) preferred() bool {
+	return p.hasType("pref") || len(p.params["PREF"]) > 0
+}
+
+// Parse reads all cards from a vCard file
+func Parse(r io.Reader) ([]Card, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	data = bytes.TrimPrefix(data, []byte("\ufeff"))
+
+	var cards []Card
+	var current []property
+	inCard := false
+	for _, line := range unfold(string(data)) {
+		prop, ok := parseLine(line)
+		if !ok {
+			continue
+		}
+		switch {
+		case prop.name == "BEGIN" && strings.EqualFold(strings.TrimSpace(prop.value), "VCARD"):
+			if inCard {
+				return nil, ErrUnterminatedCard
+			}
+			inCard = true
+			current = nil
+		case prop.name == "END" && strings.EqualFold(strings.TrimSpace(prop.value), "VCARD"):
+			if !inCard {
+				continue
+			}
+			if len(cards) == MaxCards {
+				return nil, ErrTooManyCards
+			}
+			cards = append(cards, newCard(current))
+			inCard = false
+		case inCard:
+			current = append(current, prop)
+		}
+	}
+
+	if inCard {
+		return nil, ErrUnterminatedCard
+	}
+	if len(cards) == 0 {
+		return nil, ErrNoCards
+	}
+	return cards, nil
+}
+
+// unfold joins folded content lines. Quoted-printable values (vCard 2.1) continue on the
+// next line when they end with a soft line break.
+func unfold(data string) []string {
+	data = strings.ReplaceAll(data, "\r\n", "\n")
+	data = strings.ReplaceAll(data, "\r", "\n")
+
+	var lines []string
+	for _, line := range strings.Split(data, "\n") {
+		n := len(lines)
+		switch {
+		case n > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")):
+			lines[n-1] += line[1:]
+		case n > 0 && strings.HasSuffix(lines[n-1], "=") && isQuotedPrintable(lines[n-1]):
+			lines[n-1] += "\n" + line
+		default:
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// isQuotedPrintable reports whether the line is a quoted-printable encoded property
+func isQuotedPrintable(line string) bool {
+	colon := strings.Index(line, ":")
+	return colon > 0 && strings.Contains(strings.ToUpper(line[:colon]), "QUOTED-PRINTABLE")
+}
+
+// parseLine parses "group.NAME;param=value:value" into a property
+func parseLine(line string) (property, bool) {
+	if strings.TrimSpace(line) == "" {
+		return property{}, false
+	}
+
+	// The value starts at the first colon outside a quoted parameter value
+	colon := -1
+	quoted := false
+	for i, r := range line {
+		if r == '"' {
+			quoted = !quoted
+		} else if r == ':' && !quoted {
+			colon = i
+			break
+		}
+	}
+	if colon < 0 {
+		return property{}, false
+	}
+
+	parts := splitUnquoted(line[:colon], ';')
+	name := strings.ToUpper(strings.TrimSpace(parts[0]))
+	if dot := strings.LastIndex(name, "."); dot >= 0 {
+		name = name[dot+1:]
+	}
+
+	prop := property{name: name, params: map[string][]string{}, value: line[colon+1:]}
+	for _, param := range parts[1:] {
+		key, value, found := strings.Cut(param, "=")
+		if !found {
+			// vCard 2.1 bare parameters such as TEL;CELL or ADR;WORK
+			key, value = "TYPE", param
+			if upper := strings.ToUpper(param); upper == "QUOTED-PRINTABLE" || upper == "BASE64" {
+				key = "ENCODING"
+			}
+		}
+		key = strings.ToUpper(strings.TrimSpace(key))
+		for _, v := range strings.Split(strings.Trim(strings.TrimSpace(value), `"`), ",") {
+			prop.params[key] = append(prop.params[key], strings.TrimSpace(v))
+		}
+	}
+
+	for _, encoding := range prop.params["ENCODING"] {
+		if strings.EqualFold(encoding, "QUOTED-PRINTABLE") {
+			decoded, err := io.ReadAll(quotedprintable.NewReader(strings.NewReader(prop.value)))
+			if err == nil {
+				prop.value = string(decoded)
+			}
+		}
+	}
+	return prop, true
+}
+
+// splitUnquoted splits s at sep outside double quotes
+func splitUnquoted(s string, sep rune) []string {
+	var parts []string
+	start := 0
+	quoted := false
+	for i, r := range s {
+		if r == '"' {
+			quoted = !quoted
+		} else if r == sep && !quoted {
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// unescape decodes a text value
+func unescape(value string) string {
+	var b strings.Builder
+	escaped := false
+	for _, r := range value {
+		switch {
+		case escaped:
+			if r == 'n' || r == 'N' {
+				b.WriteRune('\n')
+			} else {
+				b.WriteRune(r)
+			}
+			escaped = false
+		case r == '\\':
+			escaped = true
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// components splits a structured value at unescaped semicolons and unescapes each component
+func components(value string) []string {
+	var parts []string
+	var b strings.Builder
+	escaped := false
+	for _, r := range value {
+		switch {
+		case escaped:
+			b.WriteRune('\\')
+			b.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == ';':
+			parts = append(parts, unescape(b.String()))
+			b.Reset()
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return append(parts, unescape(b.String()))
+}
+
+// component returns the i-th component, or an empty string
+func component(parts []string, i int) string {
+	if i < len(parts) {
+		return parts[i]
+	}
+	return ""
+}
+
+// newCard builds a card from its properties. When a property occurs more than once, the
+// preferred or work value wins over the first one.
+func newCard(props []property) Card {
+	var card Card
+	var emailPreferred, phonePreferred, addressPreferred bool
+
+	for i := range props {
+		prop := &props[i]
+		switch prop.name {
+		case "UID":
+			card.UID = unescape(prop.value)
+		case "FN":
+			card.FullName = unescape(prop.value)
+		case "N":
+			parts := components(prop.value)
+			card.FamilyName = component(parts, 0)
+			card.GivenName = component(parts, 1)
+			if middle := component(parts, 2); middle != "" {
+				card.GivenName = strings.TrimSpace(card.GivenName + " " + middle)
+			}
+		case "ORG":
+			parts := components(prop.value)
+			card.Organization = component(parts, 0)
+			card.Department = component(parts, 1)
+		case "TITLE":
+			card.Title = unescape(prop.value)
+		case "EMAIL":
+			if card.Email == "" || (!emailPreferred && prop.preferred()) {
+				card.Email = unescape(prop.value)
+				emailPreferred = prop.preferred()
+			}
+		case "TEL":
+			number := strings.TrimPrefix(unescape(prop.value), "tel:")
+			if prop.hasType("cell") {
+				if card.Mobile == "" {
+					card.Mobile = number
+				}
+			} else if !prop.hasType("fax", "pager") {
+				if card.Phone == "" || (!phonePreferred && (prop.preferred() || prop.hasType("work"))) {
+					card.Phone = number
+					phonePreferred = prop.preferred() || prop.hasType("work")
+				}
+			}
+		case "ADR":
+			if card.Address.IsZero() || (!addressPreferred && prop.hasType("work", "pref")) {
+				parts := components(prop.value)
+				card.Address = Address{
+					Street:     strings.TrimSpace(strings.Join(nonEmpty(component(parts, 1), component(parts, 2)), " ")),
+					City:       component(parts, 3),
+					PostalCode: component(parts, 5),
+					Country:    component(parts, 6),
+				}
+				addressPreferred = prop.hasType("work", "pref")
+			}
+		case "URL":
+			if card.URL == "" {
+				card.URL = unescape(prop.value)
+			}
+		case "NOTE":
+			card.Note = unescape(prop.value)
+		case "REV":
+			for _, layout := range []string{"20060102T150405Z", "2006-01-02T15:04:05Z", time.RFC3339} {
+				if rev, err := time.Parse(layout, strings.TrimSpace(prop.value)); err == nil {
+					card.Revision = rev
+					break
+				}
+			}
+		}
+	}
+	return card
+}
+
+// nonEmpty returns the non-empty values
+func nonEmpty(values ...string) []string {
+	var result []string
+	for _, value := range values {
+		if value != "" {
+			result = append(result, value)
+		}
+	}
+	return result
+}
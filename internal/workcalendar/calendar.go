@@ -0,0 +1,128 @@
+// Package workcalendar measures time in Norwegian business hours. Working days are Monday to
+// Friday except the Norwegian public holidays (offentlige høytidsdager), and working time is
+// counted within a daily window such as 08:00-16:00 in the Europe/Oslo time zone.
+package workcalendar
+
+import (
+	"fmt"
+	"time"
+	_ "time/tzdata" // Europe/Oslo must be available in minimal container images
+)
+
+// Oslo is the time zone business hours are expressed in
+var Oslo = mustLoadLocation("Europe/Oslo")
+
+func mustLoadLocation(name string) *time.Location {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		panic(fmt.Sprintf("workcalendar: failed to load location %s: %v", name, err))
+	}
+	return loc
+}
+
+// Calendar counts working time within a daily business hours window on Norwegian working days
+type Calendar struct {
+	// startMinute and endMinute are the business hours window as minutes after midnight
+	startMinute int
+	endMinute   int
+}
+
+// New creates a calendar with business hours from dayStart to dayEnd, both given as HH:MM
+func New(dayStart, dayEnd string) (*Calendar, error) {
+	start, err := ParseClock(dayStart)
+	if err != nil {
+		return nil, err
+	}
+	end, err := ParseClock(dayEnd)
+	if err != nil {
+		return nil, err
+	}
+	if end <= start {
+		return nil, fmt.Errorf("business hours must end after they start: %s-%s", dayStart, dayEnd)
+	}
+	return &Calendar{startMinute: start, endMinute: end}, nil
+}
+
+// ParseClock parses a HH:MM time of day into minutes after midnight
+func ParseClock(value string) (int, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of day %q: must be HH:MM", value)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// HoursPerDay returns the length of the business hours window
+func (c *Calendar) HoursPerDay() time.Duration {
+	return time.Duration(c.endMinute-c.startMinute) * time.Minute
+}
+
+// IsWorkingDay returns true if the Oslo date of t is a weekday that is not a public holiday
+func (c *Calendar) IsWorkingDay(t time.Time) bool {
+	t = t.In(Oslo)
+	if t.Weekday() == time.Saturday || t.Weekday() == time.Sunday {
+		return false
+	}
+	return !IsPublicHoliday(t)
+}
+
+// window returns the business hours of the Oslo date of t
+func (c *Calendar) window(t time.Time) (time.Time, time.Time) {
+	y, m, d := t.In(Oslo).Date()
+	return time.Date(y, m, d, 0, c.startMinute, 0, 0, Oslo), time.Date(y, m, d, 0, c.endMinute, 0, 0, Oslo)
+}
+
+// nextDay returns midnight of the Oslo day after t
+func nextDay(t time.Time) time.Time {
+	y, m, d := t.In(Oslo).Date()
+	return time.Date(y, m, d+1, 0, 0, 0, 0, Oslo)
+}
+
+// Add returns the moment d of working time after from. Time outside business hours does not count,
+// so a duration that ends exactly at closing time returns closing time rather than the next opening.
+func (c *Calendar) Add(from time.Time, d time.Duration) time.Time {
+	if d <= 0 {
+		return from
+	}
+	t := from
+	for {
+		if c.IsWorkingDay(t) {
+			start, end := c.window(t)
+			if t.Before(start) {
+				t = start
+			}
+			if t.Before(end) {
+				available := end.Sub(t)
+				if d <= available {
+					return t.Add(d)
+				}
+				d -= available
+			}
+		}
+		t = nextDay(t)
+	}
+}
+
+// Between returns the working time from from to to, negative if to is before from
+func (c *Calendar) Between(from, to time.Time) time.Duration {
+	if to.Before(from) {
+		return -c.Between(to, from)
+	}
+	var total time.Duration
+	for t := from; t.Before(to); t = nextDay(t) {
+		if !c.IsWorkingDay(t) {
+			continue
+		}
+		start, end := c.window(t)
+		if start.Before(from) {
+			start = from
+		}
+		if end.After(to) {
+			end = to
+		}
+		if end.After(start) {
+			total += end.Sub(start)
+		}
+	}
+	return total
+}
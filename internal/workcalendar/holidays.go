@@ -0,0 +1,61 @@
+package workcalendar
+
+import "time"
+
+// fixedHolidays are the Norwegian public holidays on the same date every year
+var fixedHolidays = map[[2]int]string{
+	{1, 1}:   "Første nyttårsdag",
+	{5, 1}:   "Arbeidernes dag",
+	{5, 17}:  "Grunnlovsdag",
+	{12, 25}: "Første juledag",
+	{12, 26}: "Andre juledag",
+}
+
+// easterHolidays are the Norwegian public holidays relative to Easter Sunday (days)
+var easterHolidays = map[int]string{
+	-3: "Skjærtorsdag",
+	-2: "Langfredag",
+	0:  "Første påskedag",
+	1:  "Andre påskedag",
+	39: "Kristi himmelfartsdag",
+	49: "Første pinsedag",
+	50: "Andre pinsedag",
+}
+
+// EasterSunday returns the date of Easter Sunday in the given year (Gregorian calendar)
+func EasterSunday(year int) time.Time {
+	// Anonymous Gregorian algorithm (Meeus/Jones/Butcher)
+	a := year % 19
+	b := year / 100
+	c := year % 100
+	d := b / 4
+	e := b % 4
+	f := (b + 8) / 25
+	g := (b - f + 1) / 3
+	h := (19*a + b - d - g + 15) % 30
+	i := c / 4
+	k := c % 4
+	l := (32 + 2*e + 2*i - h - k) % 7
+	m := (a + 11*h + 22*l) / 451
+	month := (h + l - 7*m + 114) / 31
+	day := (h+l-7*m+114)%31 + 1
+	return time.Date(year, time.Month(month), day, 0, 0, 0, 0, Oslo)
+}
+
+// HolidayName returns the name of the Norwegian public holiday on the Oslo date of t,
+// or an empty string if it is not a public holiday
+func HolidayName(t time.Time) string {
+	y, m, d := t.In(Oslo).Date()
+	if name, ok := fixedHolidays[[2]int{int(m), d}]; ok {
+		return name
+	}
+	// Compare calendar dates in UTC so daylight saving changes do not skew the day count
+	easter := EasterSunday(y)
+	days := time.Date(y, m, d, 0, 0, 0, 0, time.UTC).Sub(time.Date(y, easter.Month(), easter.Day(), 0, 0, 0, 0, time.UTC))
+	return easterHolidays[int(days/(24*time.Hour))]
+}
+
+// IsPublicHoliday returns true if the Oslo date of t is a Norwegian public holiday
+func IsPublicHoliday(t time.Time) bool {
+	return HolidayName(t) != ""
+}
@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Add Enhetsregisteret (Brønnøysund) enrichment fields to customers
+ALTER TABLE customers ADD COLUMN IF NOT EXISTS industry_code VARCHAR(20);
+ALTER TABLE customers ADD COLUMN IF NOT EXISTS registry_status VARCHAR(50);
+ALTER TABLE customers ADD COLUMN IF NOT EXISTS registry_checked_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_customers_registry_status ON customers(registry_status);
+
+COMMENT ON COLUMN customers.industry_code IS 'NACE industry code (naeringskode1) from Enhetsregisteret';
+COMMENT ON COLUMN customers.registry_status IS 'Status in Enhetsregisteret: active, under_liquidation, bankrupt, deleted or not_found';
+COMMENT ON COLUMN customers.registry_checked_at IS 'When the customer was last checked against Enhetsregisteret';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_customers_registry_status;
+ALTER TABLE customers DROP COLUMN IF EXISTS registry_checked_at;
+ALTER TABLE customers DROP COLUMN IF EXISTS registry_status;
+ALTER TABLE customers DROP COLUMN IF EXISTS industry_code;
+-- +goose StatementEnd
@@ -0,0 +1,52 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Spreadsheet imports of customers and offers (upload -> dry run -> commit)
+CREATE TABLE IF NOT EXISTS import_jobs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    entity_type VARCHAR(50) NOT NULL,
+    file_name VARCHAR(255) NOT NULL,
+    status VARCHAR(50) NOT NULL DEFAULT 'uploaded',
+    headers JSONB NOT NULL,
+    rows JSONB NOT NULL,
+    row_count INTEGER NOT NULL DEFAULT 0,
+    mapping JSONB,
+    options JSONB,
+    report JSONB,
+    company_id VARCHAR(50) REFERENCES companies(id),
+    committed_at TIMESTAMP WITH TIME ZONE,
+    created_by_id VARCHAR(100),
+    created_by_name VARCHAR(200),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_import_jobs_entity_type ON import_jobs(entity_type);
+CREATE INDEX IF NOT EXISTS idx_import_jobs_status ON import_jobs(status);
+CREATE INDEX IF NOT EXISTS idx_import_jobs_created_by_id ON import_jobs(created_by_id);
+
+COMMENT ON TABLE import_jobs IS 'Uploaded XLSX/CSV files imported as customers or offers';
+COMMENT ON COLUMN import_jobs.rows IS 'Parsed data rows, kept so dry run and commit use the same data';
+COMMENT ON COLUMN import_jobs.mapping IS 'Column header to field mapping used for the last dry run';
+COMMENT ON COLUMN import_jobs.report IS 'Row-by-row report from the last dry run or commit';
+
+CREATE TABLE IF NOT EXISTS import_mapping_profiles (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(200) NOT NULL,
+    entity_type VARCHAR(50) NOT NULL,
+    mapping JSONB NOT NULL,
+    created_by_id VARCHAR(100),
+    created_by_name VARCHAR(200),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_import_mapping_profiles_name ON import_mapping_profiles(entity_type, LOWER(name));
+
+COMMENT ON TABLE import_mapping_profiles IS 'Saved column mappings for recurring import file layouts';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS import_mapping_profiles;
+DROP TABLE IF EXISTS import_jobs;
+-- +goose StatementEnd
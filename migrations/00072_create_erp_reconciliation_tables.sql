@@ -0,0 +1,95 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Scheduled reconciliation of ERP customers (dbo.Kunde) against CRM customers
+CREATE TABLE IF NOT EXISTS erp_reconciliation_runs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    trigger VARCHAR(50) NOT NULL,
+    status VARCHAR(50) NOT NULL DEFAULT 'running',
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    finished_at TIMESTAMP WITH TIME ZONE,
+    erp_customer_count INTEGER NOT NULL DEFAULT 0,
+    local_customer_count INTEGER NOT NULL DEFAULT 0,
+    matched_count INTEGER NOT NULL DEFAULT 0,
+    customers_created INTEGER NOT NULL DEFAULT 0,
+    creates_queued INTEGER NOT NULL DEFAULT 0,
+    fields_updated INTEGER NOT NULL DEFAULT 0,
+    drifts_queued INTEGER NOT NULL DEFAULT 0,
+    drifts_kept INTEGER NOT NULL DEFAULT 0,
+    errors INTEGER NOT NULL DEFAULT 0,
+    error_message TEXT,
+    created_by_id VARCHAR(100),
+    created_by_name VARCHAR(200),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_erp_reconciliation_runs_status ON erp_reconciliation_runs(status);
+CREATE INDEX IF NOT EXISTS idx_erp_reconciliation_runs_started_at ON erp_reconciliation_runs(started_at DESC);
+
+COMMENT ON TABLE erp_reconciliation_runs IS 'History of ERP customer reconciliation runs';
+
+CREATE TABLE IF NOT EXISTS erp_reconciliation_decisions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    run_id UUID NOT NULL REFERENCES erp_reconciliation_runs(id) ON DELETE CASCADE,
+    type VARCHAR(50) NOT NULL,
+    status VARCHAR(50) NOT NULL DEFAULT 'pending',
+    key VARCHAR(300) NOT NULL,
+    customer_id UUID REFERENCES customers(id) ON DELETE SET NULL,
+    customer_name VARCHAR(200),
+    field VARCHAR(50),
+    crm_value VARCHAR(500),
+    erp_value VARCHAR(500),
+    erp_firmanr INTEGER,
+    erp_customer_number VARCHAR(50),
+    erp_org_number VARCHAR(20),
+    erp_name VARCHAR(200),
+    erp_address VARCHAR(500),
+    erp_postal_code VARCHAR(20),
+    erp_city VARCHAR(100),
+    error TEXT,
+    resolved_at TIMESTAMP WITH TIME ZONE,
+    resolved_by_id VARCHAR(100),
+    resolved_by_name VARCHAR(200),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_erp_reconciliation_decisions_run_id ON erp_reconciliation_decisions(run_id);
+CREATE INDEX IF NOT EXISTS idx_erp_reconciliation_decisions_type ON erp_reconciliation_decisions(type);
+CREATE INDEX IF NOT EXISTS idx_erp_reconciliation_decisions_status ON erp_reconciliation_decisions(status);
+CREATE INDEX IF NOT EXISTS idx_erp_reconciliation_decisions_key ON erp_reconciliation_decisions(key);
+CREATE INDEX IF NOT EXISTS idx_erp_reconciliation_decisions_customer_id ON erp_reconciliation_decisions(customer_id);
+-- Only one open decision per difference; later runs refresh it instead of queueing duplicates
+CREATE UNIQUE INDEX IF NOT EXISTS idx_erp_reconciliation_decisions_pending_key
+    ON erp_reconciliation_decisions(key) WHERE status = 'pending';
+
+COMMENT ON TABLE erp_reconciliation_decisions IS 'Missing customers and field drift found by ERP reconciliation, pending or resolved';
+COMMENT ON COLUMN erp_reconciliation_decisions.key IS 'Identifies the same difference across runs (ERP customer or customer + field)';
+COMMENT ON COLUMN erp_reconciliation_decisions.status IS 'pending, approved, rejected, applied (automatically) or obsolete';
+
+CREATE TABLE IF NOT EXISTS erp_reconciliation_policies (
+    field VARCHAR(50) PRIMARY KEY,
+    policy VARCHAR(50) NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_by_id VARCHAR(100),
+    updated_by_name VARCHAR(200)
+);
+
+COMMENT ON TABLE erp_reconciliation_policies IS 'Per-field policy for ERP/CRM drift: erp_wins, crm_wins or review';
+
+-- The ERP is the source of truth for postal addresses; names and org numbers are reviewed
+INSERT INTO erp_reconciliation_policies (field, policy) VALUES
+    ('name', 'review'),
+    ('orgNumber', 'review'),
+    ('address', 'erp_wins'),
+    ('postalCode', 'erp_wins'),
+    ('city', 'erp_wins')
+ON CONFLICT (field) DO NOTHING;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS erp_reconciliation_policies;
+DROP TABLE IF EXISTS erp_reconciliation_decisions;
+DROP TABLE IF EXISTS erp_reconciliation_runs;
+-- +goose StatementEnd
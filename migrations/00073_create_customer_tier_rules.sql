@@ -0,0 +1,67 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Configurable customer tier rules, manual tier overrides and tier change history
+CREATE TABLE IF NOT EXISTS customer_tier_rules (
+    company_id VARCHAR(50) PRIMARY KEY,
+    metric VARCHAR(50) NOT NULL,
+    window_years INTEGER NOT NULL DEFAULT 0,
+    silver_threshold DECIMAL(15,2) NOT NULL,
+    gold_threshold DECIMAL(15,2) NOT NULL,
+    platinum_threshold DECIMAL(15,2) NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_by_id VARCHAR(100),
+    updated_by_name VARCHAR(200),
+    CONSTRAINT chk_customer_tier_rules_window CHECK (window_years >= 0),
+    CONSTRAINT chk_customer_tier_rules_thresholds CHECK (silver_threshold <= gold_threshold AND gold_threshold <= platinum_threshold)
+);
+
+COMMENT ON TABLE customer_tier_rules IS 'Tier thresholds per company; the rule for company "all" applies to customers without a company-specific rule';
+COMMENT ON COLUMN customer_tier_rules.metric IS 'won_value (sum of won offer values) or order_count (number of won offers)';
+COMMENT ON COLUMN customer_tier_rules.window_years IS 'Rolling window in years for won offers; 0 counts all time';
+
+-- Keep the thresholds that were previously hard-coded as the default rule
+INSERT INTO customer_tier_rules (company_id, metric, window_years, silver_threshold, gold_threshold, platinum_threshold) VALUES
+    ('all', 'won_value', 0, 100000, 1000000, 10000000)
+ON CONFLICT (company_id) DO NOTHING;
+
+ALTER TABLE customers ADD COLUMN IF NOT EXISTS tier_locked BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE customers ADD COLUMN IF NOT EXISTS tier_locked_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE customers ADD COLUMN IF NOT EXISTS tier_locked_by_name VARCHAR(200);
+ALTER TABLE customers ADD COLUMN IF NOT EXISTS tier_calculated_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_customers_tier_locked ON customers(tier_locked);
+
+COMMENT ON COLUMN customers.tier_locked IS 'Manual tier override; locked tiers are skipped by tier recalculation';
+COMMENT ON COLUMN customers.tier_calculated_at IS 'When the tier was last evaluated by tier recalculation';
+
+CREATE TABLE IF NOT EXISTS customer_tier_history (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    customer_id UUID NOT NULL REFERENCES customers(id) ON DELETE CASCADE,
+    previous_tier VARCHAR(50) NOT NULL,
+    new_tier VARCHAR(50) NOT NULL,
+    source VARCHAR(50) NOT NULL,
+    metric VARCHAR(50),
+    metric_value DECIMAL(15,2),
+    window_years INTEGER,
+    rule_company_id VARCHAR(50),
+    changed_by_id VARCHAR(100),
+    changed_by_name VARCHAR(200),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_customer_tier_history_customer_id ON customer_tier_history(customer_id);
+CREATE INDEX IF NOT EXISTS idx_customer_tier_history_created_at ON customer_tier_history(created_at DESC);
+
+COMMENT ON TABLE customer_tier_history IS 'Customer tier changes, from recalculation (calculated) or manual overrides (manual)';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS customer_tier_history;
+DROP INDEX IF EXISTS idx_customers_tier_locked;
+ALTER TABLE customers DROP COLUMN IF EXISTS tier_calculated_at;
+ALTER TABLE customers DROP COLUMN IF EXISTS tier_locked_by_name;
+ALTER TABLE customers DROP COLUMN IF EXISTS tier_locked_at;
+ALTER TABLE customers DROP COLUMN IF EXISTS tier_locked;
+DROP TABLE IF EXISTS customer_tier_rules;
+-- +goose StatementEnd
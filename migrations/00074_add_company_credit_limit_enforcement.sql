@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Per-company handling of orders that push a customer over its credit limit
+ALTER TABLE companies ADD COLUMN IF NOT EXISTS credit_limit_enforcement VARCHAR(20) NOT NULL DEFAULT 'warn';
+
+ALTER TABLE companies ADD CONSTRAINT chk_companies_credit_limit_enforcement
+    CHECK (credit_limit_enforcement IN ('warn', 'block'));
+
+COMMENT ON COLUMN companies.credit_limit_enforcement IS 'warn accepts orders over the customer credit limit with a warning, block rejects them';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE companies DROP CONSTRAINT IF EXISTS chk_companies_credit_limit_enforcement;
+ALTER TABLE companies DROP COLUMN IF EXISTS credit_limit_enforcement;
+-- +goose StatementEnd
@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Customer groups (konsern): subsidiaries point to their parent customer
+ALTER TABLE customers ADD COLUMN IF NOT EXISTS parent_customer_id UUID REFERENCES customers(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_customers_parent_customer_id ON customers(parent_customer_id);
+
+ALTER TABLE customers DROP CONSTRAINT IF EXISTS chk_customers_parent_not_self;
+ALTER TABLE customers ADD CONSTRAINT chk_customers_parent_not_self CHECK (parent_customer_id IS NULL OR parent_customer_id <> id);
+
+COMMENT ON COLUMN customers.parent_customer_id IS 'Parent customer in a customer group; longer cycles are rejected by the API';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE customers DROP CONSTRAINT IF EXISTS chk_customers_parent_not_self;
+DROP INDEX IF EXISTS idx_customers_parent_customer_id;
+ALTER TABLE customers DROP COLUMN IF EXISTS parent_customer_id;
+-- +goose StatementEnd
@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+-- GDPR tooling: anonymization and retention review of contacts
+ALTER TABLE contacts ADD COLUMN IF NOT EXISTS anonymized_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE contacts ADD COLUMN IF NOT EXISTS retention_flagged_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE contacts ADD COLUMN IF NOT EXISTS retention_reviewed_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_contacts_retention_flagged_at ON contacts(retention_flagged_at) WHERE retention_flagged_at IS NOT NULL;
+
+COMMENT ON COLUMN contacts.anonymized_at IS 'When the personal data of the contact and its denormalized copies was scrubbed';
+COMMENT ON COLUMN contacts.retention_flagged_at IS 'When the retention job flagged the contact as inactive for review';
+COMMENT ON COLUMN contacts.retention_reviewed_at IS 'When a flagged contact was last reviewed and kept; restarts the retention period';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_contacts_retention_flagged_at;
+ALTER TABLE contacts DROP COLUMN IF EXISTS retention_reviewed_at;
+ALTER TABLE contacts DROP COLUMN IF EXISTS retention_flagged_at;
+ALTER TABLE contacts DROP COLUMN IF EXISTS anonymized_at;
+-- +goose StatementEnd
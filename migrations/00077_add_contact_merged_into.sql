@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Contact deduplication: merged duplicates point at the surviving contact
+ALTER TABLE contacts ADD COLUMN IF NOT EXISTS merged_into_id UUID REFERENCES contacts(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_contacts_merged_into_id ON contacts(merged_into_id) WHERE merged_into_id IS NOT NULL;
+
+COMMENT ON COLUMN contacts.merged_into_id IS 'Surviving contact this duplicate was merged into; merged contacts are deactivated';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_contacts_merged_into_id;
+ALTER TABLE contacts DROP COLUMN IF EXISTS merged_into_id;
+-- +goose StatementEnd
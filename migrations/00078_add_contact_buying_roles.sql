@@ -0,0 +1,33 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Contacts on offers with buying-committee roles and sentiment
+ALTER TYPE contact_entity_type ADD VALUE IF NOT EXISTS 'offer';
+
+ALTER TABLE contact_relationships ADD COLUMN IF NOT EXISTS buying_role VARCHAR(50);
+ALTER TABLE contact_relationships ADD COLUMN IF NOT EXISTS sentiment VARCHAR(20);
+
+ALTER TABLE contact_relationships DROP CONSTRAINT IF EXISTS chk_contact_rel_buying_role;
+ALTER TABLE contact_relationships ADD CONSTRAINT chk_contact_rel_buying_role CHECK (
+    buying_role IS NULL OR buying_role IN ('decision_maker', 'economic_buyer', 'technical_evaluator', 'influencer', 'blocker')
+);
+ALTER TABLE contact_relationships DROP CONSTRAINT IF EXISTS chk_contact_rel_sentiment;
+ALTER TABLE contact_relationships ADD CONSTRAINT chk_contact_rel_sentiment CHECK (
+    sentiment IS NULL OR sentiment IN ('positive', 'neutral', 'negative')
+);
+
+CREATE INDEX IF NOT EXISTS idx_contact_rel_buying_role ON contact_relationships(entity_type, entity_id, buying_role) WHERE buying_role IS NOT NULL;
+
+COMMENT ON COLUMN contact_relationships.buying_role IS 'Role in the buying committee of an offer: decision_maker, economic_buyer, technical_evaluator, influencer or blocker';
+COMMENT ON COLUMN contact_relationships.sentiment IS 'How the contact feels about the offer: positive, neutral or negative';
+-- +goose StatementEnd
+
+-- +goose Down
+-- Note: PostgreSQL does not support removing enum values directly.
+-- The 'offer' entity type will remain in the enum but will not be used if this migration is rolled back.
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_contact_rel_buying_role;
+ALTER TABLE contact_relationships DROP CONSTRAINT IF EXISTS chk_contact_rel_sentiment;
+ALTER TABLE contact_relationships DROP CONSTRAINT IF EXISTS chk_contact_rel_buying_role;
+ALTER TABLE contact_relationships DROP COLUMN IF EXISTS sentiment;
+ALTER TABLE contact_relationships DROP COLUMN IF EXISTS buying_role;
+-- +goose StatementEnd
@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Secret per-user tokens for iCalendar subscription feeds of scheduled activities and offer deadlines
+CREATE TABLE IF NOT EXISTS calendar_feed_tokens (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id VARCHAR(100) NOT NULL,
+    user_name VARCHAR(200),
+    token_hash VARCHAR(64) NOT NULL,
+    last_accessed_at TIMESTAMP WITH TIME ZONE,
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_calendar_feed_tokens_token_hash ON calendar_feed_tokens(token_hash);
+CREATE INDEX IF NOT EXISTS idx_calendar_feed_tokens_user_id ON calendar_feed_tokens(user_id) WHERE revoked_at IS NULL;
+
+COMMENT ON TABLE calendar_feed_tokens IS 'Calendar subscription tokens; each user has at most one active (non-revoked) token';
+COMMENT ON COLUMN calendar_feed_tokens.token_hash IS 'SHA-256 hex digest of the token; the token itself is only shown when it is created';
+COMMENT ON COLUMN calendar_feed_tokens.last_accessed_at IS 'When a calendar client last fetched the feed';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS calendar_feed_tokens;
+-- +goose StatementEnd
@@ -0,0 +1,34 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Recurring activities. The first occurrence of a series (the master) holds the RFC 5545 rule and
+-- the template new occurrences are generated from; occurrences are stored as ordinary activities
+-- linked to the master through series_id.
+ALTER TABLE activities ADD COLUMN IF NOT EXISTS recurrence_rule VARCHAR(500);
+ALTER TABLE activities ADD COLUMN IF NOT EXISTS recurrence_template JSONB;
+ALTER TABLE activities ADD COLUMN IF NOT EXISTS recurrence_expanded_until TIMESTAMP WITH TIME ZONE;
+ALTER TABLE activities ADD COLUMN IF NOT EXISTS series_id UUID REFERENCES activities(id) ON DELETE SET NULL;
+ALTER TABLE activities ADD COLUMN IF NOT EXISTS occurrence_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE activities ADD COLUMN IF NOT EXISTS is_recurrence_exception BOOLEAN NOT NULL DEFAULT false;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_activities_series_occurrence ON activities(series_id, occurrence_at) WHERE series_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_activities_recurring ON activities(recurrence_expanded_until) WHERE recurrence_rule <> '';
+
+COMMENT ON COLUMN activities.recurrence_rule IS 'RFC 5545 RRULE of a recurring series, set on the series master only';
+COMMENT ON COLUMN activities.recurrence_template IS 'Fields new occurrences of the series are generated from, set on the series master only';
+COMMENT ON COLUMN activities.recurrence_expanded_until IS 'Occurrences of the series have been generated up to this time';
+COMMENT ON COLUMN activities.series_id IS 'Master activity of the recurring series; the master references itself';
+COMMENT ON COLUMN activities.occurrence_at IS 'Original start of the occurrence in the series (RECURRENCE-ID), unchanged when the occurrence is moved';
+COMMENT ON COLUMN activities.is_recurrence_exception IS 'Occurrence was edited on its own and is no longer regenerated from the series';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_activities_recurring;
+DROP INDEX IF EXISTS idx_activities_series_occurrence;
+ALTER TABLE activities DROP COLUMN IF EXISTS is_recurrence_exception;
+ALTER TABLE activities DROP COLUMN IF EXISTS occurrence_at;
+ALTER TABLE activities DROP COLUMN IF EXISTS series_id;
+ALTER TABLE activities DROP COLUMN IF EXISTS recurrence_expanded_until;
+ALTER TABLE activities DROP COLUMN IF EXISTS recurrence_template;
+ALTER TABLE activities DROP COLUMN IF EXISTS recurrence_rule;
+-- +goose StatementEnd
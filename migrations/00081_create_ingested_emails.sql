@@ -0,0 +1,41 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Emails ingested from .eml uploads and the drop mailbox, with the activity they were filed as
+CREATE TABLE IF NOT EXISTS ingested_emails (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    message_id VARCHAR(998) NOT NULL,
+    subject VARCHAR(500),
+    from_address VARCHAR(255),
+    sent_at TIMESTAMP WITH TIME ZONE,
+    source VARCHAR(20) NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    matched_by VARCHAR(50),
+    target_type VARCHAR(50),
+    target_id UUID,
+    target_name VARCHAR(255),
+    activity_id UUID REFERENCES activities(id) ON DELETE SET NULL,
+    attachment_count INTEGER NOT NULL DEFAULT 0,
+    company_id VARCHAR(50) REFERENCES companies(id),
+    ingested_by_id VARCHAR(100),
+    ingested_by_name VARCHAR(200),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_ingested_emails_source CHECK (source IN ('upload', 'mailbox')),
+    CONSTRAINT chk_ingested_emails_status CHECK (status IN ('matched', 'unmatched'))
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_ingested_emails_message_id ON ingested_emails(message_id);
+CREATE INDEX IF NOT EXISTS idx_ingested_emails_status ON ingested_emails(status, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_ingested_emails_target ON ingested_emails(target_type, target_id);
+CREATE INDEX IF NOT EXISTS idx_supplier_contacts_email ON supplier_contacts(LOWER(email));
+
+COMMENT ON TABLE ingested_emails IS 'Emails filed as email activities on customers, offers or suppliers; unmatched emails are kept for review';
+COMMENT ON COLUMN ingested_emails.message_id IS 'Message-ID header, or a hash of the message when it has none; prevents ingesting the same email twice';
+COMMENT ON COLUMN ingested_emails.matched_by IS 'How the target was found: offer_number, contact or supplier_contact';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_supplier_contacts_email;
+DROP TABLE IF EXISTS ingested_emails;
+-- +goose StatementEnd
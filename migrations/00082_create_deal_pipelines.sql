@@ -0,0 +1,293 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Deal pipelines and stages defined per company instead of the fixed deal_stage enum
+CREATE TABLE IF NOT EXISTS deal_pipelines (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    company_id VARCHAR(50) NOT NULL REFERENCES companies(id),
+    name VARCHAR(100) NOT NULL,
+    description TEXT,
+    is_default BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_deal_pipelines_company_id ON deal_pipelines(company_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_deal_pipelines_company_default ON deal_pipelines(company_id) WHERE is_default;
+
+COMMENT ON TABLE deal_pipelines IS 'Sales processes per company; new deals use the company default pipeline unless another is chosen';
+
+CREATE TABLE IF NOT EXISTS deal_pipeline_stages (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    pipeline_id UUID NOT NULL REFERENCES deal_pipelines(id) ON DELETE CASCADE,
+    key VARCHAR(50) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    sort_order INTEGER NOT NULL DEFAULT 0,
+    kind VARCHAR(20) NOT NULL DEFAULT 'open',
+    probability INTEGER NOT NULL DEFAULT 0,
+    required_fields TEXT[] NOT NULL DEFAULT '{}',
+    allowed_transitions TEXT[] NOT NULL DEFAULT '{}',
+    CONSTRAINT uq_deal_pipeline_stages_key UNIQUE (pipeline_id, key),
+    CONSTRAINT chk_deal_pipeline_stages_kind CHECK (kind IN ('open', 'won', 'lost')),
+    CONSTRAINT chk_deal_pipeline_stages_probability CHECK (probability >= 0 AND probability <= 100)
+);
+
+COMMENT ON COLUMN deal_pipeline_stages.key IS 'Stage stored on deals.stage; the won and lost stages always use the keys won and lost';
+COMMENT ON COLUMN deal_pipeline_stages.probability IS 'Default probability (percent) given to deals entering the stage';
+COMMENT ON COLUMN deal_pipeline_stages.required_fields IS 'Deal fields that must be set before a deal can enter the stage';
+COMMENT ON COLUMN deal_pipeline_stages.allowed_transitions IS 'Stage keys a deal in this stage can move to';
+
+-- Every company gets a default pipeline with the stages and transitions that were previously hard-coded
+INSERT INTO deal_pipelines (company_id, name, is_default)
+SELECT c.id, 'Standard', true
+FROM companies c
+WHERE NOT EXISTS (SELECT 1 FROM deal_pipelines p WHERE p.company_id = c.id AND p.is_default);
+
+INSERT INTO deal_pipeline_stages (pipeline_id, key, name, sort_order, kind, probability, allowed_transitions)
+SELECT p.id, s.key, s.name, s.sort_order, s.kind, s.probability, s.allowed_transitions
+FROM deal_pipelines p
+CROSS JOIN (VALUES
+    ('lead', 'Lead', 1, 'open', 10, ARRAY['qualified', 'lost']),
+    ('qualified', 'Kvalifisert', 2, 'open', 25, ARRAY['proposal', 'lead', 'lost']),
+    ('proposal', 'Tilbud', 3, 'open', 50, ARRAY['negotiation', 'qualified', 'lost']),
+    ('negotiation', 'Forhandling', 4, 'open', 75, ARRAY['won', 'proposal', 'lost']),
+    ('won', 'Vunnet', 5, 'won', 100, ARRAY[]::TEXT[]),
+    ('lost', 'Tapt', 6, 'lost', 0, ARRAY['lead'])
+) AS s(key, name, sort_order, kind, probability, allowed_transitions)
+WHERE p.is_default
+ON CONFLICT (pipeline_id, key) DO NOTHING;
+
+-- Stages are now free-form keys, so the enum columns become varchar. Views reading deals.stage
+-- must be dropped while the column type changes and are recreated unchanged below.
+DROP VIEW IF EXISTS v_sales_pipeline_summary;
+DROP VIEW IF EXISTS v_monthly_sales_trends;
+DROP VIEW IF EXISTS v_customer_lifetime_value;
+
+ALTER TABLE deals ALTER COLUMN stage DROP DEFAULT;
+ALTER TABLE deals ALTER COLUMN stage TYPE VARCHAR(50) USING stage::TEXT;
+ALTER TABLE deals ALTER COLUMN stage SET DEFAULT 'lead';
+ALTER TABLE deal_stage_history ALTER COLUMN from_stage TYPE VARCHAR(50) USING from_stage::TEXT;
+ALTER TABLE deal_stage_history ALTER COLUMN to_stage TYPE VARCHAR(50) USING to_stage::TEXT;
+DROP TYPE IF EXISTS deal_stage;
+
+ALTER TABLE deals ADD COLUMN IF NOT EXISTS pipeline_id UUID REFERENCES deal_pipelines(id);
+CREATE INDEX IF NOT EXISTS idx_deals_pipeline_stage ON deals(pipeline_id, stage);
+
+COMMENT ON COLUMN deals.pipeline_id IS 'Pipeline whose stages the deal moves through';
+
+UPDATE deals d
+SET pipeline_id = p.id
+FROM deal_pipelines p
+WHERE p.company_id = d.company_id AND p.is_default AND d.pipeline_id IS NULL;
+
+CREATE VIEW v_sales_pipeline_summary AS
+SELECT
+    d.company_id,
+    c.name as company_name,
+    d.stage,
+    COUNT(d.id) as deal_count,
+    SUM(d.value) as total_value,
+    SUM(d.weighted_value) as total_weighted_value,
+    AVG(d.probability) as avg_probability,
+    AVG(d.value) as avg_deal_value,
+    MIN(d.expected_close_date) as earliest_close_date,
+    MAX(d.expected_close_date) as latest_close_date,
+    COUNT(CASE WHEN d.expected_close_date < CURRENT_DATE AND d.stage NOT IN ('won', 'lost') THEN 1 END) as overdue_count
+FROM deals d
+JOIN companies c ON d.company_id = c.id
+GROUP BY d.company_id, c.name, d.stage;
+
+CREATE VIEW v_monthly_sales_trends AS
+SELECT
+    d.company_id,
+    co.name as company_name,
+    DATE_TRUNC('month', d.created_at) as month,
+    COUNT(*) as deals_created,
+    COUNT(CASE WHEN d.stage = 'won' THEN 1 END) as deals_won,
+    COUNT(CASE WHEN d.stage = 'lost' THEN 1 END) as deals_lost,
+    SUM(d.value) as total_value,
+    SUM(CASE WHEN d.stage = 'won' THEN d.value ELSE 0 END) as won_value,
+    AVG(d.value) as avg_deal_value,
+    AVG(CASE WHEN d.stage = 'won' THEN d.value END) as avg_won_deal_value
+FROM deals d
+JOIN companies co ON d.company_id = co.id
+GROUP BY d.company_id, co.name, DATE_TRUNC('month', d.created_at)
+ORDER BY month DESC;
+
+CREATE VIEW v_customer_lifetime_value AS
+SELECT
+    cu.id AS customer_id,
+    cu.name AS customer_name,
+    cu.company_id,
+    co.name AS company_name,
+    cu.org_number,
+    cu.created_at AS customer_since,
+    COALESCE(dm.total_deals, 0::bigint) AS total_deals,
+    COALESCE(dm.won_deals, 0::bigint) AS won_deals,
+    COALESCE(dm.lost_deals, 0::bigint) AS lost_deals,
+    COALESCE(dm.active_deals, 0::bigint) AS active_deals,
+    COALESCE(dm.total_deal_value, 0::numeric) AS total_deal_value,
+    COALESCE(dm.won_deal_value, 0::numeric) AS won_deal_value,
+    CASE
+        WHEN COALESCE(dm.total_deals, 0::bigint) > 0 THEN COALESCE(dm.won_deals, 0::bigint)::numeric / dm.total_deals::numeric * 100::numeric
+        ELSE 0::numeric
+    END AS win_rate_percent,
+    COALESCE(om.total_offers, 0::bigint) AS total_offers,
+    COALESCE(om.active_offers, 0::bigint) AS active_offers,
+    COALESCE(om.completed_offers, 0::bigint) AS completed_offers,
+    COALESCE(om.total_offer_value, 0::numeric) AS total_offer_value,
+    COALESCE(om.total_offer_spent, 0::numeric) AS total_offer_spent,
+    COALESCE(dm.won_deal_value, 0::numeric) + COALESCE(om.total_offer_value, 0::numeric) AS lifetime_value,
+    COALESCE(am.total_activities, 0::bigint) AS total_activities,
+    am.last_activity_date,
+    CASE
+        WHEN COALESCE(am.total_activities, 0::bigint) >= 20 AND COALESCE(dm.active_deals, 0::bigint) > 0 THEN 'high'
+        WHEN COALESCE(am.total_activities, 0::bigint) >= 5 OR COALESCE(dm.active_deals, 0::bigint) > 0 THEN 'medium'
+        ELSE 'low'
+    END AS engagement_level
+FROM customers cu
+LEFT JOIN companies co ON cu.company_id = co.id
+LEFT JOIN (
+    SELECT customer_id,
+        COUNT(*) AS total_deals,
+        COUNT(CASE WHEN stage = 'won' THEN 1 END) AS won_deals,
+        COUNT(CASE WHEN stage = 'lost' THEN 1 END) AS lost_deals,
+        COUNT(CASE WHEN stage NOT IN ('won', 'lost') THEN 1 END) AS active_deals,
+        SUM(value) AS total_deal_value,
+        SUM(CASE WHEN stage = 'won' THEN value ELSE 0 END) AS won_deal_value
+    FROM deals GROUP BY customer_id
+) dm ON dm.customer_id = cu.id
+LEFT JOIN (
+    SELECT customer_id,
+        COUNT(*) AS total_offers,
+        COUNT(CASE WHEN phase IN ('order', 'in_progress', 'sent') THEN 1 END) AS active_offers,
+        COUNT(CASE WHEN phase = 'completed' THEN 1 END) AS completed_offers,
+        SUM(value) AS total_offer_value,
+        SUM(spent) AS total_offer_spent
+    FROM offers GROUP BY customer_id
+) om ON om.customer_id = cu.id
+LEFT JOIN (
+    SELECT target_id AS customer_id, COUNT(*) AS total_activities, MAX(occurred_at) AS last_activity_date
+    FROM activities WHERE target_type = 'Customer' GROUP BY target_id
+) am ON am.customer_id = cu.id;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_deals_pipeline_stage;
+ALTER TABLE deals DROP COLUMN IF EXISTS pipeline_id;
+
+-- Deals in custom stages fall back to the nearest fixed stage by outcome
+UPDATE deals d
+SET stage = CASE s.kind WHEN 'won' THEN 'won' WHEN 'lost' THEN 'lost' ELSE 'lead' END
+FROM deal_pipeline_stages s
+WHERE s.key = d.stage AND d.stage NOT IN ('lead', 'qualified', 'proposal', 'negotiation', 'won', 'lost');
+UPDATE deals SET stage = 'lead' WHERE stage NOT IN ('lead', 'qualified', 'proposal', 'negotiation', 'won', 'lost');
+DELETE FROM deal_stage_history
+WHERE to_stage NOT IN ('lead', 'qualified', 'proposal', 'negotiation', 'won', 'lost')
+   OR from_stage NOT IN ('lead', 'qualified', 'proposal', 'negotiation', 'won', 'lost');
+
+DROP VIEW IF EXISTS v_sales_pipeline_summary;
+DROP VIEW IF EXISTS v_monthly_sales_trends;
+DROP VIEW IF EXISTS v_customer_lifetime_value;
+
+CREATE TYPE deal_stage AS ENUM ('lead', 'qualified', 'proposal', 'negotiation', 'won', 'lost');
+ALTER TABLE deals ALTER COLUMN stage DROP DEFAULT;
+ALTER TABLE deals ALTER COLUMN stage TYPE deal_stage USING stage::deal_stage;
+ALTER TABLE deals ALTER COLUMN stage SET DEFAULT 'lead';
+ALTER TABLE deal_stage_history ALTER COLUMN from_stage TYPE deal_stage USING from_stage::deal_stage;
+ALTER TABLE deal_stage_history ALTER COLUMN to_stage TYPE deal_stage USING to_stage::deal_stage;
+
+CREATE VIEW v_sales_pipeline_summary AS
+SELECT
+    d.company_id,
+    c.name as company_name,
+    d.stage,
+    COUNT(d.id) as deal_count,
+    SUM(d.value) as total_value,
+    SUM(d.weighted_value) as total_weighted_value,
+    AVG(d.probability) as avg_probability,
+    AVG(d.value) as avg_deal_value,
+    MIN(d.expected_close_date) as earliest_close_date,
+    MAX(d.expected_close_date) as latest_close_date,
+    COUNT(CASE WHEN d.expected_close_date < CURRENT_DATE AND d.stage NOT IN ('won', 'lost') THEN 1 END) as overdue_count
+FROM deals d
+JOIN companies c ON d.company_id = c.id
+GROUP BY d.company_id, c.name, d.stage;
+
+CREATE VIEW v_monthly_sales_trends AS
+SELECT
+    d.company_id,
+    co.name as company_name,
+    DATE_TRUNC('month', d.created_at) as month,
+    COUNT(*) as deals_created,
+    COUNT(CASE WHEN d.stage = 'won' THEN 1 END) as deals_won,
+    COUNT(CASE WHEN d.stage = 'lost' THEN 1 END) as deals_lost,
+    SUM(d.value) as total_value,
+    SUM(CASE WHEN d.stage = 'won' THEN d.value ELSE 0 END) as won_value,
+    AVG(d.value) as avg_deal_value,
+    AVG(CASE WHEN d.stage = 'won' THEN d.value END) as avg_won_deal_value
+FROM deals d
+JOIN companies co ON d.company_id = co.id
+GROUP BY d.company_id, co.name, DATE_TRUNC('month', d.created_at)
+ORDER BY month DESC;
+
+CREATE VIEW v_customer_lifetime_value AS
+SELECT
+    cu.id AS customer_id,
+    cu.name AS customer_name,
+    cu.company_id,
+    co.name AS company_name,
+    cu.org_number,
+    cu.created_at AS customer_since,
+    COALESCE(dm.total_deals, 0::bigint) AS total_deals,
+    COALESCE(dm.won_deals, 0::bigint) AS won_deals,
+    COALESCE(dm.lost_deals, 0::bigint) AS lost_deals,
+    COALESCE(dm.active_deals, 0::bigint) AS active_deals,
+    COALESCE(dm.total_deal_value, 0::numeric) AS total_deal_value,
+    COALESCE(dm.won_deal_value, 0::numeric) AS won_deal_value,
+    CASE
+        WHEN COALESCE(dm.total_deals, 0::bigint) > 0 THEN COALESCE(dm.won_deals, 0::bigint)::numeric / dm.total_deals::numeric * 100::numeric
+        ELSE 0::numeric
+    END AS win_rate_percent,
+    COALESCE(om.total_offers, 0::bigint) AS total_offers,
+    COALESCE(om.active_offers, 0::bigint) AS active_offers,
+    COALESCE(om.completed_offers, 0::bigint) AS completed_offers,
+    COALESCE(om.total_offer_value, 0::numeric) AS total_offer_value,
+    COALESCE(om.total_offer_spent, 0::numeric) AS total_offer_spent,
+    COALESCE(dm.won_deal_value, 0::numeric) + COALESCE(om.total_offer_value, 0::numeric) AS lifetime_value,
+    COALESCE(am.total_activities, 0::bigint) AS total_activities,
+    am.last_activity_date,
+    CASE
+        WHEN COALESCE(am.total_activities, 0::bigint) >= 20 AND COALESCE(dm.active_deals, 0::bigint) > 0 THEN 'high'
+        WHEN COALESCE(am.total_activities, 0::bigint) >= 5 OR COALESCE(dm.active_deals, 0::bigint) > 0 THEN 'medium'
+        ELSE 'low'
+    END AS engagement_level
+FROM customers cu
+LEFT JOIN companies co ON cu.company_id = co.id
+LEFT JOIN (
+    SELECT customer_id,
+        COUNT(*) AS total_deals,
+        COUNT(CASE WHEN stage = 'won' THEN 1 END) AS won_deals,
+        COUNT(CASE WHEN stage = 'lost' THEN 1 END) AS lost_deals,
+        COUNT(CASE WHEN stage NOT IN ('won', 'lost') THEN 1 END) AS active_deals,
+        SUM(value) AS total_deal_value,
+        SUM(CASE WHEN stage = 'won' THEN value ELSE 0 END) AS won_deal_value
+    FROM deals GROUP BY customer_id
+) dm ON dm.customer_id = cu.id
+LEFT JOIN (
+    SELECT customer_id,
+        COUNT(*) AS total_offers,
+        COUNT(CASE WHEN phase IN ('order', 'in_progress', 'sent') THEN 1 END) AS active_offers,
+        COUNT(CASE WHEN phase = 'completed' THEN 1 END) AS completed_offers,
+        SUM(value) AS total_offer_value,
+        SUM(spent) AS total_offer_spent
+    FROM offers GROUP BY customer_id
+) om ON om.customer_id = cu.id
+LEFT JOIN (
+    SELECT target_id AS customer_id, COUNT(*) AS total_activities, MAX(occurred_at) AS last_activity_date
+    FROM activities WHERE target_type = 'Customer' GROUP BY target_id
+) am ON am.customer_id = cu.id;
+
+DROP TABLE IF EXISTS deal_pipeline_stages;
+DROP TABLE IF EXISTS deal_pipelines;
+-- +goose StatementEnd
@@ -0,0 +1,56 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Days without stage change or activity before an open deal in the stage is stale (NULL = never stale)
+ALTER TABLE deal_pipeline_stages ADD COLUMN IF NOT EXISTS stale_after_days INTEGER
+    CHECK (stale_after_days IS NULL OR stale_after_days > 0);
+
+COMMENT ON COLUMN deal_pipeline_stages.stale_after_days IS 'Days without stage change or activity before an open deal in this stage is stale';
+
+UPDATE deal_pipeline_stages s
+SET stale_after_days = t.days
+FROM (VALUES ('lead', 14), ('qualified', 21), ('proposal', 30), ('negotiation', 30)) AS t(key, days),
+     deal_pipelines p
+WHERE s.key = t.key AND s.pipeline_id = p.id AND p.is_default AND s.stale_after_days IS NULL;
+
+-- When the offer entered its current phase, used for "days in phase" and staleness
+ALTER TABLE offers ADD COLUMN IF NOT EXISTS phase_changed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP;
+
+COMMENT ON COLUMN offers.phase_changed_at IS 'When the offer entered its current phase (maintained by trigger)';
+
+-- Backfill without touching updated_at (see 00060)
+SET LOCAL app.skip_updated_at = 'true';
+UPDATE offers
+SET phase_changed_at = CASE
+    WHEN phase = 'sent' AND sent_date IS NOT NULL THEN sent_date
+    ELSE COALESCE(updated_at, created_at)
+END;
+SET LOCAL app.skip_updated_at = 'false';
+
+CREATE OR REPLACE FUNCTION set_offer_phase_changed_at()
+RETURNS TRIGGER AS $$
+BEGIN
+    NEW.phase_changed_at := CURRENT_TIMESTAMP;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trigger_set_offer_phase_changed_at
+    BEFORE UPDATE OF phase ON offers
+    FOR EACH ROW
+    WHEN (OLD.phase IS DISTINCT FROM NEW.phase)
+    EXECUTE FUNCTION set_offer_phase_changed_at();
+
+-- Last activity lookups per deal and offer
+CREATE INDEX IF NOT EXISTS idx_activities_target_occurred ON activities(target_type, target_id, occurred_at DESC);
+CREATE INDEX IF NOT EXISTS idx_deal_stage_history_deal_stage ON deal_stage_history(deal_id, to_stage, changed_at DESC);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_deal_stage_history_deal_stage;
+DROP INDEX IF EXISTS idx_activities_target_occurred;
+DROP TRIGGER IF EXISTS trigger_set_offer_phase_changed_at ON offers;
+DROP FUNCTION IF EXISTS set_offer_phase_changed_at();
+ALTER TABLE offers DROP COLUMN IF EXISTS phase_changed_at;
+ALTER TABLE deal_pipeline_stages DROP COLUMN IF EXISTS stale_after_days;
+-- +goose StatementEnd
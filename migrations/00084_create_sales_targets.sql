@@ -0,0 +1,75 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Sales targets per company, optionally per user, for a month, quarter or year
+CREATE TABLE IF NOT EXISTS sales_targets (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    company_id VARCHAR(50) NOT NULL REFERENCES companies(id),
+    user_id VARCHAR(100),
+    user_name VARCHAR(200),
+    period_type VARCHAR(20) NOT NULL,
+    period_start DATE NOT NULL,
+    metric VARCHAR(50) NOT NULL,
+    target_value DECIMAL(15,2) NOT NULL,
+    notes TEXT,
+    created_by_id VARCHAR(100),
+    created_by_name VARCHAR(200),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_sales_targets_period_type CHECK (period_type IN ('month', 'quarter', 'year')),
+    CONSTRAINT chk_sales_targets_metric CHECK (metric IN ('won_value', 'order_intake', 'margin')),
+    CONSTRAINT chk_sales_targets_target_value CHECK (target_value > 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_sales_targets_company_period ON sales_targets(company_id, period_start);
+CREATE INDEX IF NOT EXISTS idx_sales_targets_user_id ON sales_targets(user_id);
+-- One target per company/user, period and metric; company-wide targets have no user
+CREATE UNIQUE INDEX IF NOT EXISTS idx_sales_targets_unique
+    ON sales_targets(company_id, COALESCE(user_id, ''), period_type, period_start, metric);
+
+COMMENT ON TABLE sales_targets IS 'Sales budgets per company or user and period, compared with won deals and offers';
+COMMENT ON COLUMN sales_targets.user_id IS 'Target owner; NULL for a company-wide target';
+COMMENT ON COLUMN sales_targets.period_start IS 'First day of the month, quarter or year';
+COMMENT ON COLUMN sales_targets.metric IS 'won_value: won deals and offers, order_intake: offers accepted as orders, margin: value minus cost of orders';
+
+-- When an offer was accepted as an order, used to attribute order intake to a period
+ALTER TABLE offers ADD COLUMN IF NOT EXISTS won_at TIMESTAMP WITH TIME ZONE;
+
+COMMENT ON COLUMN offers.won_at IS 'When the offer was accepted as an order (maintained by trigger)';
+
+-- Backfill without touching updated_at (see 00060), dated like customer tier won value
+SET LOCAL app.skip_updated_at = 'true';
+UPDATE offers
+SET won_at = COALESCE(sent_date, created_at)
+WHERE phase IN ('order', 'completed') AND won_at IS NULL;
+SET LOCAL app.skip_updated_at = 'false';
+
+CREATE OR REPLACE FUNCTION set_offer_won_at()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW.phase IN ('order', 'completed') AND NEW.won_at IS NULL THEN
+        NEW.won_at := CURRENT_TIMESTAMP;
+    ELSIF NEW.phase NOT IN ('order', 'completed') THEN
+        NEW.won_at := NULL;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trigger_set_offer_won_at
+    BEFORE INSERT OR UPDATE OF phase ON offers
+    FOR EACH ROW
+    EXECUTE FUNCTION set_offer_won_at();
+
+CREATE INDEX IF NOT EXISTS idx_offers_won_at ON offers(won_at) WHERE won_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_deals_actual_close_date ON deals(actual_close_date) WHERE stage = 'won';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_deals_actual_close_date;
+DROP INDEX IF EXISTS idx_offers_won_at;
+DROP TRIGGER IF EXISTS trigger_set_offer_won_at ON offers;
+DROP FUNCTION IF EXISTS set_offer_won_at();
+ALTER TABLE offers DROP COLUMN IF EXISTS won_at;
+DROP TABLE IF EXISTS sales_targets;
+-- +goose StatementEnd
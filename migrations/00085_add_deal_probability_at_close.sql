@@ -0,0 +1,33 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Winning or losing a deal overwrites its probability with 100 or 0. Keep the probability the
+-- salesperson had entered so forecasts can be calibrated against what actually happened.
+ALTER TABLE deals ADD COLUMN IF NOT EXISTS probability_at_close INTEGER;
+
+COMMENT ON COLUMN deals.probability_at_close IS 'Probability (percent) entered on the deal right before it was won or lost';
+
+-- Best estimate for deals closed before the column existed: the default probability of the
+-- stage the deal left when it was closed
+UPDATE deals d
+SET probability_at_close = s.probability
+FROM deal_stage_history h, deal_pipeline_stages s
+WHERE d.stage IN ('won', 'lost')
+  AND d.probability_at_close IS NULL
+  AND h.deal_id = d.id
+  AND h.to_stage = d.stage
+  AND h.from_stage IS NOT NULL
+  AND h.changed_at = (
+      SELECT MAX(h2.changed_at) FROM deal_stage_history h2
+      WHERE h2.deal_id = d.id AND h2.to_stage = d.stage
+  )
+  AND s.pipeline_id = d.pipeline_id
+  AND s.key = h.from_stage;
+
+CREATE INDEX IF NOT EXISTS idx_deals_closed_outcomes ON deals(company_id, actual_close_date) WHERE stage IN ('won', 'lost');
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_deals_closed_outcomes;
+ALTER TABLE deals DROP COLUMN IF EXISTS probability_at_close;
+-- +goose StatementEnd
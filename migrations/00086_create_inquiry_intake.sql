@@ -0,0 +1,64 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Company websites that may submit inquiries through the public intake endpoint
+CREATE TABLE IF NOT EXISTS inquiry_intake_sites (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    company_id VARCHAR(50) NOT NULL REFERENCES companies(id),
+    name VARCHAR(200) NOT NULL,
+    site_key VARCHAR(64) NOT NULL,
+    allowed_origins TEXT[] NOT NULL DEFAULT '{}',
+    honeypot_field VARCHAR(100),
+    proof_of_work_bits INTEGER NOT NULL DEFAULT 0,
+    challenge_secret VARCHAR(64) NOT NULL,
+    send_confirmation BOOLEAN NOT NULL DEFAULT true,
+    enabled BOOLEAN NOT NULL DEFAULT true,
+    created_by_id VARCHAR(100),
+    created_by_name VARCHAR(200),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_inquiry_intake_sites_pow CHECK (proof_of_work_bits >= 0 AND proof_of_work_bits <= 24)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_inquiry_intake_sites_site_key ON inquiry_intake_sites(site_key);
+CREATE INDEX IF NOT EXISTS idx_inquiry_intake_sites_company_id ON inquiry_intake_sites(company_id);
+
+COMMENT ON COLUMN inquiry_intake_sites.site_key IS 'Public key embedded in the website form; identifies the site and its company';
+COMMENT ON COLUMN inquiry_intake_sites.allowed_origins IS 'Origins the form may be posted from; empty allows any origin';
+COMMENT ON COLUMN inquiry_intake_sites.honeypot_field IS 'Hidden form field that must stay empty; submissions filling it are dropped as spam';
+COMMENT ON COLUMN inquiry_intake_sites.proof_of_work_bits IS 'Leading zero bits required in the proof-of-work hash; 0 disables the check';
+
+-- Inquiries received through the public intake endpoint, including the ones dropped as spam
+CREATE TABLE IF NOT EXISTS inquiry_submissions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    site_id UUID NOT NULL REFERENCES inquiry_intake_sites(id) ON DELETE CASCADE,
+    company_id VARCHAR(50) NOT NULL REFERENCES companies(id),
+    offer_id UUID REFERENCES offers(id) ON DELETE SET NULL,
+    customer_id UUID REFERENCES customers(id) ON DELETE SET NULL,
+    match_confidence DECIMAL(5,4),
+    name VARCHAR(200) NOT NULL,
+    email VARCHAR(255) NOT NULL,
+    phone VARCHAR(50),
+    company_name VARCHAR(200),
+    org_number VARCHAR(20),
+    message TEXT,
+    attachment_count INTEGER NOT NULL DEFAULT 0,
+    spam BOOLEAN NOT NULL DEFAULT false,
+    pow_challenge VARCHAR(100),
+    ip_address VARCHAR(64),
+    user_agent VARCHAR(500),
+    confirmation_sent_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_inquiry_submissions_site_created ON inquiry_submissions(site_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_inquiry_submissions_offer_id ON inquiry_submissions(offer_id);
+-- A solved proof-of-work challenge can only be used once
+CREATE UNIQUE INDEX IF NOT EXISTS idx_inquiry_submissions_pow_challenge ON inquiry_submissions(pow_challenge) WHERE pow_challenge IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS inquiry_submissions;
+DROP TABLE IF EXISTS inquiry_intake_sites;
+-- +goose StatementEnd
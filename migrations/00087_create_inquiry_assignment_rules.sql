@@ -0,0 +1,37 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Rules that pick the responsible user for new inquiries, evaluated per company in priority order
+CREATE TABLE IF NOT EXISTS inquiry_assignment_rules (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    company_id VARCHAR(50) NOT NULL REFERENCES companies(id),
+    name VARCHAR(200) NOT NULL,
+    rule_type VARCHAR(50) NOT NULL,
+    priority INTEGER NOT NULL DEFAULT 100,
+    enabled BOOLEAN NOT NULL DEFAULT true,
+    industries TEXT[] NOT NULL DEFAULT '{}',
+    postal_code_prefixes TEXT[] NOT NULL DEFAULT '{}',
+    counties TEXT[] NOT NULL DEFAULT '{}',
+    min_value DECIMAL(15,2),
+    max_value DECIMAL(15,2),
+    assignee_ids TEXT[] NOT NULL DEFAULT '{}',
+    next_index INTEGER NOT NULL DEFAULT 0,
+    created_by_id VARCHAR(100),
+    created_by_name VARCHAR(200),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_inquiry_assignment_rules_type CHECK (rule_type IN ('round_robin', 'industry', 'postal_region', 'value'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_inquiry_assignment_rules_company ON inquiry_assignment_rules(company_id, priority) WHERE enabled = true;
+
+COMMENT ON COLUMN inquiry_assignment_rules.priority IS 'Rules are tried in ascending priority; the first matching rule assigns the inquiry';
+COMMENT ON COLUMN inquiry_assignment_rules.postal_code_prefixes IS 'Postal code prefixes of the customer (e.g. 40 for Stavanger) matched by postal_region rules';
+COMMENT ON COLUMN inquiry_assignment_rules.counties IS 'Counties (fylker) of the customer postal code matched by postal_region rules';
+COMMENT ON COLUMN inquiry_assignment_rules.assignee_ids IS 'Users the rule assigns in turn';
+COMMENT ON COLUMN inquiry_assignment_rules.next_index IS 'Round-robin cursor into assignee_ids';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS inquiry_assignment_rules;
+-- +goose StatementEnd
@@ -0,0 +1,45 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Per-company inquiry response SLA, measured in business hours
+ALTER TABLE companies ADD COLUMN IF NOT EXISTS inquiry_sla_hours INTEGER NOT NULL DEFAULT 16;
+ALTER TABLE companies ADD COLUMN IF NOT EXISTS inquiry_sla_warning_hours INTEGER NOT NULL DEFAULT 4;
+ALTER TABLE companies ADD COLUMN IF NOT EXISTS business_day_start VARCHAR(5) NOT NULL DEFAULT '08:00';
+ALTER TABLE companies ADD COLUMN IF NOT EXISTS business_day_end VARCHAR(5) NOT NULL DEFAULT '16:00';
+
+ALTER TABLE companies ADD CONSTRAINT chk_companies_inquiry_sla_hours
+    CHECK (inquiry_sla_hours > 0 AND inquiry_sla_warning_hours >= 0 AND inquiry_sla_warning_hours < inquiry_sla_hours);
+ALTER TABLE companies ADD CONSTRAINT chk_companies_business_day
+    CHECK (business_day_start ~ '^[0-2][0-9]:[0-5][0-9]$' AND business_day_end ~ '^[0-2][0-9]:[0-5][0-9]$' AND business_day_start < business_day_end);
+
+COMMENT ON COLUMN companies.inquiry_sla_hours IS 'Business hours from an inquiry is received until the customer must have had a response';
+COMMENT ON COLUMN companies.inquiry_sla_warning_hours IS 'Business hours before the SLA deadline the responsible user is warned';
+COMMENT ON COLUMN companies.business_day_start IS 'Start of business hours (HH:MM, Europe/Oslo)';
+COMMENT ON COLUMN companies.business_day_end IS 'End of business hours (HH:MM, Europe/Oslo)';
+
+-- SLA clock on inquiries (offers in draft phase)
+ALTER TABLE offers ADD COLUMN IF NOT EXISTS inquiry_sla_due_at TIMESTAMP;
+ALTER TABLE offers ADD COLUMN IF NOT EXISTS inquiry_responded_at TIMESTAMP;
+ALTER TABLE offers ADD COLUMN IF NOT EXISTS inquiry_sla_warned_at TIMESTAMP;
+
+COMMENT ON COLUMN offers.inquiry_sla_due_at IS 'When the inquiry must have had a customer-facing response, set when the inquiry is created';
+COMMENT ON COLUMN offers.inquiry_responded_at IS 'When the inquiry was converted to an offer';
+COMMENT ON COLUMN offers.inquiry_sla_warned_at IS 'When the responsible user was warned about the approaching SLA deadline';
+
+CREATE INDEX IF NOT EXISTS idx_offers_inquiry_sla_open ON offers(inquiry_sla_due_at)
+    WHERE inquiry_sla_due_at IS NOT NULL AND inquiry_responded_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_offers_inquiry_sla_open;
+ALTER TABLE offers DROP COLUMN IF EXISTS inquiry_sla_warned_at;
+ALTER TABLE offers DROP COLUMN IF EXISTS inquiry_responded_at;
+ALTER TABLE offers DROP COLUMN IF EXISTS inquiry_sla_due_at;
+
+ALTER TABLE companies DROP CONSTRAINT IF EXISTS chk_companies_business_day;
+ALTER TABLE companies DROP CONSTRAINT IF EXISTS chk_companies_inquiry_sla_hours;
+ALTER TABLE companies DROP COLUMN IF EXISTS business_day_end;
+ALTER TABLE companies DROP COLUMN IF EXISTS business_day_start;
+ALTER TABLE companies DROP COLUMN IF EXISTS inquiry_sla_warning_hours;
+ALTER TABLE companies DROP COLUMN IF EXISTS inquiry_sla_hours;
+-- +goose StatementEnd
@@ -0,0 +1,52 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Milestones and tasks that break a project down for execution
+CREATE TABLE IF NOT EXISTS project_tasks (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    kind VARCHAR(20) NOT NULL DEFAULT 'task',
+    name VARCHAR(200) NOT NULL,
+    description TEXT,
+    milestone_id UUID REFERENCES project_tasks(id) ON DELETE SET NULL,
+    offer_id UUID REFERENCES offers(id) ON DELETE SET NULL,
+    start_date DATE NOT NULL,
+    end_date DATE NOT NULL,
+    assignee_id VARCHAR(100),
+    assignee_name VARCHAR(200),
+    percent_complete INTEGER NOT NULL DEFAULT 0,
+    sort_order INTEGER NOT NULL DEFAULT 0,
+    created_by_id VARCHAR(100),
+    created_by_name VARCHAR(200),
+    updated_by_id VARCHAR(100),
+    updated_by_name VARCHAR(200),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_project_tasks_kind CHECK (kind IN ('task', 'milestone')),
+    CONSTRAINT chk_project_tasks_dates CHECK (end_date >= start_date),
+    CONSTRAINT chk_project_tasks_milestone_date CHECK (kind = 'task' OR start_date = end_date),
+    CONSTRAINT chk_project_tasks_percent_complete CHECK (percent_complete BETWEEN 0 AND 100)
+);
+
+CREATE INDEX IF NOT EXISTS idx_project_tasks_project ON project_tasks(project_id, sort_order);
+CREATE INDEX IF NOT EXISTS idx_project_tasks_milestone ON project_tasks(milestone_id) WHERE milestone_id IS NOT NULL;
+
+COMMENT ON COLUMN project_tasks.kind IS 'task has a duration, milestone is a single date';
+COMMENT ON COLUMN project_tasks.milestone_id IS 'Milestone the task is part of; the milestone is reached when its tasks are finished';
+COMMENT ON COLUMN project_tasks.offer_id IS 'Offer whose end date the milestone must be reached by; defaults to the project''s won offer';
+
+-- Finish-to-start dependencies: a task cannot start before the tasks it depends on are finished
+CREATE TABLE IF NOT EXISTS project_task_dependencies (
+    task_id UUID NOT NULL REFERENCES project_tasks(id) ON DELETE CASCADE,
+    depends_on_id UUID NOT NULL REFERENCES project_tasks(id) ON DELETE CASCADE,
+    PRIMARY KEY (task_id, depends_on_id),
+    CONSTRAINT chk_project_task_dependencies_self CHECK (task_id <> depends_on_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_project_task_dependencies_depends_on ON project_task_dependencies(depends_on_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS project_task_dependencies;
+DROP TABLE IF EXISTS project_tasks;
+-- +goose StatementEnd
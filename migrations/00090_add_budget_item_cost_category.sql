@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Data warehouse cost category a budget dimension is measured against
+ALTER TABLE budget_items ADD COLUMN IF NOT EXISTS cost_category VARCHAR(20);
+ALTER TABLE budget_items ADD CONSTRAINT chk_budget_items_cost_category
+    CHECK (cost_category IS NULL OR cost_category IN ('material', 'employee', 'other'));
+
+-- Project dimensions rebudgeted from a sold offer dimension
+ALTER TABLE budget_items ADD COLUMN IF NOT EXISTS source_item_id UUID REFERENCES budget_items(id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS idx_budget_items_source_item ON budget_items(source_item_id) WHERE source_item_id IS NOT NULL;
+
+COMMENT ON COLUMN budget_items.cost_category IS 'material (accounts 4000-4999), employee (5000-5999) or other (6000+) costs in the data warehouse';
+COMMENT ON COLUMN budget_items.source_item_id IS 'Offer dimension a project dimension was rebudgeted from';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_budget_items_source_item;
+ALTER TABLE budget_items DROP COLUMN IF EXISTS source_item_id;
+ALTER TABLE budget_items DROP CONSTRAINT IF EXISTS chk_budget_items_cost_category;
+ALTER TABLE budget_items DROP COLUMN IF EXISTS cost_category;
+-- +goose StatementEnd
@@ -0,0 +1,81 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Working hours per week for users who do not work the standard 37.5 hours
+CREATE TABLE IF NOT EXISTS user_capacities (
+    user_id VARCHAR(100) PRIMARY KEY,
+    user_name VARCHAR(200),
+    hours_per_week DECIMAL(5,2) NOT NULL,
+    updated_by_id VARCHAR(100),
+    updated_by_name VARCHAR(200),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_user_capacities_hours_per_week CHECK (hours_per_week >= 0 AND hours_per_week <= 100)
+);
+
+COMMENT ON TABLE user_capacities IS 'Weekly working hours per user; users without a row work the standard week';
+
+-- Vacation, sick leave and other days users are not available for project work
+CREATE TABLE IF NOT EXISTS user_absences (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id VARCHAR(100) NOT NULL,
+    user_name VARCHAR(200),
+    kind VARCHAR(20) NOT NULL,
+    start_date DATE NOT NULL,
+    end_date DATE NOT NULL,
+    percent INTEGER NOT NULL DEFAULT 100,
+    notes TEXT,
+    created_by_id VARCHAR(100),
+    created_by_name VARCHAR(200),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_user_absences_kind CHECK (kind IN ('vacation', 'sick_leave', 'leave', 'other')),
+    CONSTRAINT chk_user_absences_dates CHECK (end_date >= start_date),
+    CONSTRAINT chk_user_absences_percent CHECK (percent BETWEEN 1 AND 100)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_absences_user_dates ON user_absences(user_id, start_date, end_date);
+
+COMMENT ON COLUMN user_absences.percent IS 'Share of the working day the user is away, e.g. 50 for graded sick leave';
+
+-- Users booked on offers in order and projects, in hours per week or a percentage of their capacity
+CREATE TABLE IF NOT EXISTS resource_allocations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    company_id VARCHAR(50) NOT NULL REFERENCES companies(id),
+    user_id VARCHAR(100) NOT NULL,
+    user_name VARCHAR(200),
+    offer_id UUID REFERENCES offers(id) ON DELETE CASCADE,
+    project_id UUID REFERENCES projects(id) ON DELETE CASCADE,
+    start_date DATE NOT NULL,
+    end_date DATE NOT NULL,
+    hours_per_week DECIMAL(5,2),
+    percent DECIMAL(5,2),
+    notes TEXT,
+    created_by_id VARCHAR(100),
+    created_by_name VARCHAR(200),
+    updated_by_id VARCHAR(100),
+    updated_by_name VARCHAR(200),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_resource_allocations_target CHECK (offer_id IS NOT NULL OR project_id IS NOT NULL),
+    CONSTRAINT chk_resource_allocations_dates CHECK (end_date >= start_date),
+    CONSTRAINT chk_resource_allocations_amount CHECK ((hours_per_week IS NULL) <> (percent IS NULL)),
+    CONSTRAINT chk_resource_allocations_hours_per_week CHECK (hours_per_week IS NULL OR hours_per_week > 0),
+    CONSTRAINT chk_resource_allocations_percent CHECK (percent IS NULL OR (percent > 0 AND percent <= 100))
+);
+
+CREATE INDEX IF NOT EXISTS idx_resource_allocations_user_dates ON resource_allocations(user_id, start_date, end_date);
+CREATE INDEX IF NOT EXISTS idx_resource_allocations_company_dates ON resource_allocations(company_id, start_date, end_date);
+CREATE INDEX IF NOT EXISTS idx_resource_allocations_offer ON resource_allocations(offer_id) WHERE offer_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_resource_allocations_project ON resource_allocations(project_id) WHERE project_id IS NOT NULL;
+
+COMMENT ON COLUMN resource_allocations.company_id IS 'Company of the offer, or the company the project work is done for';
+COMMENT ON COLUMN resource_allocations.hours_per_week IS 'Booked hours per full working week; set either this or percent';
+COMMENT ON COLUMN resource_allocations.percent IS 'Booked share of the user''s weekly hours; set either this or hours_per_week';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS resource_allocations;
+DROP TABLE IF EXISTS user_absences;
+DROP TABLE IF EXISTS user_capacities;
+-- +goose StatementEnd
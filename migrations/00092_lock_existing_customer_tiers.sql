@@ -0,0 +1,44 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Before tier rules, tiers were only ever upgraded automatically. A tier above what the seeded
+-- default rule (won_value, all time: silver 100 000, gold 1 000 000, platinum 10 000 000) gives
+-- was therefore set by hand; lock it so the first tier recalculation does not downgrade it.
+-- Tiers below the calculated one are stale automatic tiers and are left to the recalculation.
+UPDATE customers c
+SET tier_locked = true,
+    tier_locked_at = CURRENT_TIMESTAMP,
+    tier_locked_by_name = 'System'
+FROM (
+    SELECT cu.id,
+        CASE
+            WHEN COALESCE(SUM(o.value), 0) >= 10000000 THEN 4
+            WHEN COALESCE(SUM(o.value), 0) >= 1000000 THEN 3
+            WHEN COALESCE(SUM(o.value), 0) >= 100000 THEN 2
+            ELSE 1
+        END AS calculated_rank
+    FROM customers cu
+    LEFT JOIN offers o ON o.customer_id = cu.id AND o.phase IN ('order', 'completed')
+    GROUP BY cu.id
+) calculated
+WHERE c.id = calculated.id
+    AND c.deleted_at IS NULL
+    AND c.tier_locked = false
+    AND c.tier_calculated_at IS NULL
+    AND CASE c.tier
+        WHEN 'platinum' THEN 4
+        WHEN 'gold' THEN 3
+        WHEN 'silver' THEN 2
+        ELSE 1
+    END > calculated.calculated_rank;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+UPDATE customers
+SET tier_locked = false,
+    tier_locked_at = NULL,
+    tier_locked_by_name = NULL
+WHERE tier_locked = true
+    AND tier_locked_by_name = 'System'
+    AND tier_calculated_at IS NULL;
+-- +goose StatementEnd
@@ -0,0 +1,170 @@
+package brreg_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/straye-as/relation-api/internal/brreg"
+	"github.com/straye-as/relation-api/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+const activeEntityJSON = `{
+	"organisasjonsnummer": "923609016",
+	"navn": "EQUINOR ASA",
+	"organisasjonsform": {"kode": "ASA", "beskrivelse": "Allmennaksjeselskap"},
+	"naeringskode1": {"kode": "06.100", "beskrivelse": "Utvinning av råolje"},
+	"hjemmeside": "www.equinor.com",
+	"forretningsadresse": {
+		"land": "Norge",
+		"landkode": "NO",
+		"postnummer": "4035",
+		"poststed": "STAVANGER",
+		"adresse": ["Forusbeen 50"],
+		"kommune": "STAVANGER",
+		"kommunenummer": "1103"
+	},
+	"konkurs": false,
+	"underAvvikling": false,
+	"underTvangsavviklingEllerTvangsopplosning": false
+}`
+
+const bankruptEntityJSON = `{
+	"organisasjonsnummer": "974760673",
+	"navn": "KONKURS AS",
+	"postadresse": {"postnummer": "0150", "poststed": "OSLO", "adresse": ["Postboks 1"], "kommune": "OSLO", "kommunenummer": "0301"},
+	"konkurs": true,
+	"underAvvikling": false,
+	"underTvangsavviklingEllerTvangsopplosning": false
+}`
+
+const deletedEntityJSON = `{
+	"organisasjonsnummer": "333444550",
+	"slettedato": "2023-05-01"
+}`
+
+// newStubRegistry starts a local stub of the Enhetsregisteret API
+func newStubRegistry(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/enheter/923609016", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(activeEntityJSON))
+	})
+	mux.HandleFunc("/enheter/974760673", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(bankruptEntityJSON))
+	})
+	mux.HandleFunc("/enheter/333444550", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusGone)
+		_, _ = w.Write([]byte(deletedEntityJSON))
+	})
+	mux.HandleFunc("/enheter/555666772", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newTestClient(t *testing.T, baseURL string) *brreg.Client {
+	client, err := brreg.NewClient(&config.BrregConfig{
+		Enabled: true,
+		BaseURL: baseURL,
+		Timeout: 5,
+	}, zap.NewNop())
+	require.NoError(t, err)
+	require.NotNil(t, client)
+	return client
+}
+
+func TestNewClient_Disabled(t *testing.T) {
+	client, err := brreg.NewClient(nil, zap.NewNop())
+	assert.NoError(t, err)
+	assert.Nil(t, client)
+
+	client, err = brreg.NewClient(&config.BrregConfig{Enabled: false, BaseURL: "http://localhost"}, zap.NewNop())
+	assert.NoError(t, err)
+	assert.Nil(t, client)
+}
+
+func TestNewClient_MissingBaseURL(t *testing.T) {
+	client, err := brreg.NewClient(&config.BrregConfig{Enabled: true}, zap.NewNop())
+	assert.Error(t, err)
+	assert.Nil(t, client)
+}
+
+func TestClient_GetEntity(t *testing.T) {
+	server := newStubRegistry(t)
+	client := newTestClient(t, server.URL+"/")
+	ctx := context.Background()
+
+	t.Run("active entity", func(t *testing.T) {
+		entity, err := client.GetEntity(ctx, "923609016")
+		require.NoError(t, err)
+
+		assert.Equal(t, "923609016", entity.OrganizationNumber)
+		assert.Equal(t, "EQUINOR ASA", entity.Name)
+		assert.Equal(t, "www.equinor.com", entity.Website)
+		require.NotNil(t, entity.IndustryCode)
+		assert.Equal(t, "06.100", entity.IndustryCode.Code)
+		assert.False(t, entity.Bankrupt)
+		assert.False(t, entity.IsDeleted())
+
+		address := entity.PreferredAddress()
+		require.NotNil(t, address)
+		assert.Equal(t, "4035", address.PostalCode)
+		assert.Equal(t, "STAVANGER", address.City)
+		assert.Equal(t, []string{"Forusbeen 50"}, address.Lines)
+		assert.Equal(t, "Rogaland", brreg.CountyForMunicipalityNumber(address.MunicipalityNumber))
+	})
+
+	t.Run("bankrupt entity falls back to postal address", func(t *testing.T) {
+		entity, err := client.GetEntity(ctx, "974760673")
+		require.NoError(t, err)
+
+		assert.True(t, entity.Bankrupt)
+		assert.False(t, entity.IsDeleted())
+		address := entity.PreferredAddress()
+		require.NotNil(t, address)
+		assert.Equal(t, "0150", address.PostalCode)
+		assert.Equal(t, "Oslo", brreg.CountyForMunicipalityNumber(address.MunicipalityNumber))
+	})
+
+	t.Run("deleted entity returns 410", func(t *testing.T) {
+		entity, err := client.GetEntity(ctx, "333444550")
+		require.NoError(t, err)
+
+		assert.True(t, entity.IsDeleted())
+		assert.Equal(t, "2023-05-01", entity.DeletedDate)
+	})
+
+	t.Run("unknown org number", func(t *testing.T) {
+		entity, err := client.GetEntity(ctx, "123456785")
+		assert.ErrorIs(t, err, brreg.ErrNotFound)
+		assert.Nil(t, entity)
+	})
+
+	t.Run("server error", func(t *testing.T) {
+		entity, err := client.GetEntity(ctx, "555666772")
+		assert.Error(t, err)
+		assert.NotErrorIs(t, err, brreg.ErrNotFound)
+		assert.Nil(t, entity)
+	})
+}
+
+func TestCountyForMunicipalityNumber(t *testing.T) {
+	assert.Equal(t, "Oslo", brreg.CountyForMunicipalityNumber("0301"))
+	assert.Equal(t, "Vestland", brreg.CountyForMunicipalityNumber("4601"))
+	assert.Equal(t, "Trøndelag", brreg.CountyForMunicipalityNumber("5001"))
+	assert.Equal(t, "", brreg.CountyForMunicipalityNumber("9999"))
+	assert.Equal(t, "", brreg.CountyForMunicipalityNumber(""))
+}
@@ -0,0 +1,112 @@
+package domain_test
+
+import (
+	"testing"
+
+	"github.com/straye-as/relation-api/internal/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+// =============================================================================
+// Organization Number Tests
+// =============================================================================
+
+func TestIsValidOrgNumber(t *testing.T) {
+	tests := []struct {
+		name      string
+		orgNumber string
+		expected  bool
+	}{
+		{"valid - Equinor", "923609016", true},
+		{"valid - Brønnøysundregistrene", "974760673", true},
+		{"valid - check digit 0 from remainder 0", "333444550", true},
+		{"valid with spaces", "923 609 016", true},
+		{"valid with dashes", "923-609-016", true},
+		{"valid with surrounding whitespace", " 923609016 ", true},
+		{"wrong check digit", "923609017", false},
+		{"remainder 1 gives check digit 10 - never issued", "222333440", false},
+		{"too short", "12345", false},
+		{"too long", "9236090160", false},
+		{"contains letters", "92360901A", false},
+		{"empty", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, domain.IsValidOrgNumber(tt.orgNumber))
+		})
+	}
+}
+
+// =============================================================================
+// CustomerRegistryStatus Tests
+// =============================================================================
+
+func TestCustomerRegistryStatus_IsValid(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   domain.CustomerRegistryStatus
+		expected bool
+	}{
+		{"active is valid", domain.CustomerRegistryStatusActive, true},
+		{"under_liquidation is valid", domain.CustomerRegistryStatusUnderLiquidation, true},
+		{"bankrupt is valid", domain.CustomerRegistryStatusBankrupt, true},
+		{"deleted is valid", domain.CustomerRegistryStatusDeleted, true},
+		{"not_found is valid", domain.CustomerRegistryStatusNotFound, true},
+		{"empty is invalid", domain.CustomerRegistryStatus(""), false},
+		{"unknown is invalid", domain.CustomerRegistryStatus("dissolved"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.status.IsValid())
+		})
+	}
+}
+
+func TestCustomerRegistryStatus_IsFlagged(t *testing.T) {
+	assert.True(t, domain.CustomerRegistryStatusBankrupt.IsFlagged())
+	assert.True(t, domain.CustomerRegistryStatusDeleted.IsFlagged())
+	assert.True(t, domain.CustomerRegistryStatusUnderLiquidation.IsFlagged())
+	assert.False(t, domain.CustomerRegistryStatusActive.IsFlagged())
+	assert.False(t, domain.CustomerRegistryStatusNotFound.IsFlagged())
+	assert.False(t, domain.CustomerRegistryStatus("").IsFlagged())
+}
+
+// =============================================================================
+// Customer Tier Rule Tests
+// =============================================================================
+
+func TestCustomerTierRule_TierFor(t *testing.T) {
+	rule := domain.CustomerTierRule{
+		Metric:            domain.CustomerTierMetricWonValue,
+		SilverThreshold:   100_000,
+		GoldThreshold:     1_000_000,
+		PlatinumThreshold: 10_000_000,
+	}
+
+	tests := []struct {
+		name     string
+		value    float64
+		expected domain.CustomerTier
+	}{
+		{"nothing won", 0, domain.CustomerTierBronze},
+		{"just below silver", 99_999, domain.CustomerTierBronze},
+		{"silver threshold is inclusive", 100_000, domain.CustomerTierSilver},
+		{"gold", 2_500_000, domain.CustomerTierGold},
+		{"platinum", 10_000_000, domain.CustomerTierPlatinum},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, rule.TierFor(tt.value))
+		})
+	}
+}
+
+func TestCustomerTierMetric_IsValid(t *testing.T) {
+	assert.True(t, domain.CustomerTierMetricWonValue.IsValid())
+	assert.True(t, domain.CustomerTierMetricOrderCount.IsValid())
+	assert.False(t, domain.CustomerTierMetric("revenue").IsValid())
+	assert.False(t, domain.CustomerTierMetric("").IsValid())
+}
@@ -0,0 +1,37 @@
+package domain_test
+
+import (
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/straye-as/relation-api/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// =============================================================================
+// DealPipeline Tests
+// =============================================================================
+
+func TestDealPipeline_Stages(t *testing.T) {
+	pipeline := &domain.DealPipeline{
+		Stages: []domain.DealPipelineStage{
+			{Key: "lead", Kind: domain.DealStageKindOpen, AllowedTransitions: pq.StringArray{"befaring", "lost"}},
+			{Key: "befaring", Kind: domain.DealStageKindOpen, AllowedTransitions: pq.StringArray{"won", "lead", "lost"}},
+			{Key: domain.DealStageWon, Kind: domain.DealStageKindWon},
+			{Key: domain.DealStageLost, Kind: domain.DealStageKindLost, AllowedTransitions: pq.StringArray{"lead"}},
+		},
+	}
+
+	first := pipeline.FirstStage()
+	require.NotNil(t, first)
+	assert.Equal(t, domain.DealStage("lead"), first.Key)
+	assert.Equal(t, []domain.DealStage{"lead", "befaring"}, pipeline.OpenStages())
+	assert.Nil(t, pipeline.Stage(domain.DealStageQualified))
+
+	befaring := pipeline.Stage("befaring")
+	require.NotNil(t, befaring)
+	assert.True(t, befaring.CanTransitionTo(domain.DealStageWon))
+	assert.False(t, befaring.CanTransitionTo(domain.DealStageQualified))
+	assert.False(t, pipeline.Stage(domain.DealStageWon).CanTransitionTo("lead"))
+}
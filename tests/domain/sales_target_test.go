@@ -0,0 +1,40 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/straye-as/relation-api/internal/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+// =============================================================================
+// SalesTargetPeriod Tests
+// =============================================================================
+
+func TestSalesTargetPeriod_StartAndEnd(t *testing.T) {
+	date := time.Date(2026, time.November, 17, 15, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		period domain.SalesTargetPeriod
+		start  time.Time
+		end    time.Time
+	}{
+		{domain.SalesTargetPeriodMonth, time.Date(2026, time.November, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, time.December, 1, 0, 0, 0, 0, time.UTC)},
+		{domain.SalesTargetPeriodQuarter, time.Date(2026, time.October, 1, 0, 0, 0, 0, time.UTC), time.Date(2027, time.January, 1, 0, 0, 0, 0, time.UTC)},
+		{domain.SalesTargetPeriodYear, time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC), time.Date(2027, time.January, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.period), func(t *testing.T) {
+			start := tt.period.StartOf(date)
+			assert.Equal(t, tt.start, start)
+			assert.Equal(t, tt.end, tt.period.EndOf(start))
+		})
+	}
+
+	assert.Equal(t, time.Date(2026, time.April, 1, 0, 0, 0, 0, time.UTC),
+		domain.SalesTargetPeriodQuarter.StartOf(time.Date(2026, time.June, 30, 0, 0, 0, 0, time.UTC)))
+	assert.False(t, domain.SalesTargetPeriod("week").IsValid())
+	assert.False(t, domain.SalesTargetMetric("revenue").IsValid())
+}
@@ -0,0 +1,139 @@
+package eml_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/straye-as/relation-api/internal/eml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func crlf(s string) string {
+	return strings.ReplaceAll(s, "\n", "\r\n")
+}
+
+func TestParse(t *testing.T) {
+	t.Run("multipart with attachment and encoded headers", func(t *testing.T) {
+		raw := crlf(`Message-ID: <abc123@mail.example.com>
+Date: Tue, 14 Jan 2025 09:30:00 +0100
+From: =?UTF-8?Q?Kari_Nordmann?= <Kari.Nordmann@Example.com>
+To: Ola Hansen <ola@straye.no>, post@straye.no
+Cc: "Per, Prosjekt" <per@example.com>
+Subject: =?UTF-8?B?U3ZhcjogVEstMjAyNS0wMDEgc3TDpWxrb25zdHJ1a3Nqb24=?=
+MIME-Version: 1.0
+Content-Type: multipart/mixed; boundary="outer"
+
+--outer
+Content-Type: multipart/alternative; boundary="inner"
+
+--inner
+Content-Type: text/plain; charset=iso-8859-1
+Content-Transfer-Encoding: quoted-printable
+
+Hei, vedlagt er tegningene for st=E5lkonstruksjonen.
+--inner
+Content-Type: text/html; charset=utf-8
+
+<p>Hei, vedlagt er tegningene</p>
+--inner--
+--outer
+Content-Type: application/pdf; name="tegning.pdf"
+Content-Disposition: attachment; filename="tegning.pdf"
+Content-Transfer-Encoding: base64
+
+JVBERi0xLjQK
+--outer--
+`)
+
+		msg, err := eml.Parse(strings.NewReader(raw))
+		require.NoError(t, err)
+
+		assert.Equal(t, "abc123@mail.example.com", msg.MessageID)
+		assert.Equal(t, "Svar: TK-2025-001 stålkonstruksjon", msg.Subject)
+		require.NotNil(t, msg.From)
+		assert.Equal(t, "Kari Nordmann", msg.From.Name)
+		assert.Equal(t, "kari.nordmann@example.com", msg.From.Email)
+		assert.Equal(t, "Kari Nordmann <kari.nordmann@example.com>", msg.From.String())
+		require.Len(t, msg.To, 2)
+		assert.Equal(t, "post@straye.no", msg.To[1].String())
+		require.Len(t, msg.Cc, 1)
+		assert.Equal(t, "Per, Prosjekt", msg.Cc[0].Name)
+		assert.True(t, msg.Date.Equal(time.Date(2025, 1, 14, 8, 30, 0, 0, time.UTC)))
+		assert.Equal(t, "Hei, vedlagt er tegningene for stålkonstruksjonen.", msg.Text)
+
+		require.Len(t, msg.Attachments, 1)
+		assert.Equal(t, "tegning.pdf", msg.Attachments[0].Filename)
+		assert.Equal(t, "application/pdf", msg.Attachments[0].ContentType)
+		assert.Equal(t, "%PDF-1.4\n", string(msg.Attachments[0].Data))
+	})
+
+	t.Run("html only body is converted to text", func(t *testing.T) {
+		raw := crlf(`From: ola@example.com
+Subject: Tilbud
+Content-Type: text/html; charset=utf-8
+
+<html><head><style>p { color: red; }</style></head><body><p>Hei&nbsp;Ola,</p><p>Takk for m&oslash;tet.</p></body></html>
+`)
+
+		msg, err := eml.Parse(strings.NewReader(raw))
+		require.NoError(t, err)
+		assert.Empty(t, msg.MessageID)
+		assert.True(t, msg.Date.IsZero())
+		assert.Equal(t, "Hei Ola,\nTakk for møtet.", msg.Text)
+		assert.Empty(t, msg.Attachments)
+	})
+
+	t.Run("inline images are skipped", func(t *testing.T) {
+		raw := crlf(`From: ola@example.com
+Subject: Signatur
+Content-Type: multipart/related; boundary="b"
+
+--b
+Content-Type: text/plain
+
+Med vennlig hilsen
+--b
+Content-Type: image/png; name="logo.png"
+Content-Disposition: inline; filename="logo.png"
+Content-Transfer-Encoding: base64
+
+iVBORw0KGgo=
+--b--
+`)
+
+		msg, err := eml.Parse(strings.NewReader(raw))
+		require.NoError(t, err)
+		assert.Equal(t, "Med vennlig hilsen", msg.Text)
+		assert.Empty(t, msg.Attachments)
+	})
+
+	t.Run("addresses are deduplicated", func(t *testing.T) {
+		raw := crlf(`From: ola@example.com
+To: kari@example.com, OLA@example.com
+Cc: kari@example.com
+Subject: Hei
+
+Tekst
+`)
+
+		msg, err := eml.Parse(strings.NewReader(raw))
+		require.NoError(t, err)
+		addresses := msg.Addresses()
+		require.Len(t, addresses, 2)
+		assert.Equal(t, "ola@example.com", addresses[0].Email)
+		assert.Equal(t, "kari@example.com", addresses[1].Email)
+	})
+
+	t.Run("invalid messages", func(t *testing.T) {
+		_, err := eml.Parse(strings.NewReader("this is not an email"))
+		assert.ErrorIs(t, err, eml.ErrInvalidMessage)
+
+		_, err = eml.Parse(strings.NewReader(crlf("X-Custom: value\n\nbody\n")))
+		assert.ErrorIs(t, err, eml.ErrInvalidMessage)
+
+		_, err = eml.Parse(strings.NewReader(crlf("From: ola@example.com\nContent-Type: multipart/mixed\n\nbody\n")))
+		assert.ErrorIs(t, err, eml.ErrInvalidMessage)
+	})
+}
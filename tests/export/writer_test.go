@@ -0,0 +1,112 @@
+package export_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/straye-as/relation-api/internal/export"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+)
+
+var testColumns = []export.Column{
+	{Header: "Title", Kind: export.KindText},
+	{Header: "Value", Kind: export.KindNumber},
+	{Header: "Count", Kind: export.KindInteger},
+	{Header: "Due date", Kind: export.KindDate},
+	{Header: "Updated", Kind: export.KindDateTime},
+	{Header: "Won", Kind: export.KindBool},
+}
+
+const bom = "\ufeff"
+
+func testRow() []interface{} {
+	return []interface{}{
+		"Tak, Bryggen",
+		1234.5,
+		3,
+		time.Date(2025, 3, 14, 0, 0, 0, 0, time.UTC),
+		time.Date(2025, 3, 14, 22, 30, 0, 0, time.UTC),
+		true,
+	}
+}
+
+func writeExport(t *testing.T, opts export.Options, rows ...[]interface{}) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	writer, err := export.NewWriter(&buf, testColumns, opts)
+	require.NoError(t, err)
+	for _, row := range rows {
+		require.NoError(t, writer.WriteRow(row))
+	}
+	require.NoError(t, writer.Close())
+	return buf.Bytes()
+}
+
+func TestParseFormat(t *testing.T) {
+	format, err := export.ParseFormat("")
+	require.NoError(t, err)
+	assert.Equal(t, export.FormatCSV, format)
+
+	format, err = export.ParseFormat("XLSX")
+	require.NoError(t, err)
+	assert.Equal(t, export.FormatXLSX, format)
+	assert.Equal(t, ".xlsx", format.Extension())
+
+	_, err = export.ParseFormat("pdf")
+	assert.ErrorIs(t, err, export.ErrUnsupportedFormat)
+}
+
+func TestCSVWriter(t *testing.T) {
+	output := string(writeExport(t, export.Options{Format: export.FormatCSV}, testRow()))
+
+	require.True(t, strings.HasPrefix(output, bom), "CSV should start with a UTF-8 BOM")
+	lines := strings.Split(strings.TrimSpace(strings.TrimPrefix(output, bom)), "\n")
+	require.Len(t, lines, 2)
+	assert.Equal(t, "Title,Value,Count,Due date,Updated,Won", lines[0])
+	assert.Equal(t, `"Tak, Bryggen",1234.50,3,2025-03-14,2025-03-14 22:30,Yes`, lines[1])
+}
+
+func TestCSVWriter_Norwegian(t *testing.T) {
+	output := string(writeExport(t, export.Options{Format: export.FormatCSV, Norwegian: true}, testRow()))
+
+	lines := strings.Split(strings.TrimSpace(strings.TrimPrefix(output, bom)), "\n")
+	require.Len(t, lines, 2)
+	assert.Equal(t, "Title;Value;Count;Due date;Updated;Won", lines[0])
+	// 22:30 UTC is 23:30 in Oslo (CET)
+	assert.Equal(t, "Tak, Bryggen;1234,50;3;14.03.2025;14.03.2025 23:30;Ja", lines[1])
+}
+
+func TestCSVWriter_EmptyValues(t *testing.T) {
+	output := string(writeExport(t, export.Options{Format: export.FormatCSV}, []interface{}{"Only title", nil, nil, time.Time{}}))
+
+	lines := strings.Split(strings.TrimSpace(strings.TrimPrefix(output, bom)), "\n")
+	require.Len(t, lines, 2)
+	assert.Equal(t, "Only title,,,,,", lines[1])
+}
+
+func TestXLSXWriter(t *testing.T) {
+	output := writeExport(t, export.Options{Format: export.FormatXLSX, Norwegian: true}, testRow(), testRow())
+
+	file, err := excelize.OpenReader(bytes.NewReader(output))
+	require.NoError(t, err)
+	defer file.Close()
+
+	rows, err := file.GetRows("Export")
+	require.NoError(t, err)
+	require.Len(t, rows, 3)
+	assert.Equal(t, []string{"Title", "Value", "Count", "Due date", "Updated", "Won"}, rows[0])
+	assert.Equal(t, "Tak, Bryggen", rows[1][0])
+	assert.Equal(t, "Ja", rows[1][5])
+
+	// Numbers are stored as numeric cells, not text
+	cellType, err := file.GetCellType("Export", "B2")
+	require.NoError(t, err)
+	assert.NotEqual(t, excelize.CellTypeSharedString, cellType)
+	raw, err := file.GetCellValue("Export", "B2", excelize.Options{RawCellValue: true})
+	require.NoError(t, err)
+	assert.Equal(t, "1234.5", raw)
+}
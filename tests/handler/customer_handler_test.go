@@ -279,7 +279,7 @@ func TestCustomerHandler_Create(t *testing.T) {
 	t.Run("create valid customer", func(t *testing.T) {
 		reqBody := domain.CreateCustomerRequest{
 			Name:       "New Customer AS",
-			OrgNumber:  "123456789",
+			OrgNumber:  "123456793",
 			Email:      "contact@newcustomer.no",
 			Phone:      "+47 12345678",
 			Address:    "Test Street 1",
@@ -303,7 +303,7 @@ func TestCustomerHandler_Create(t *testing.T) {
 		err := json.Unmarshal(rr.Body.Bytes(), &result)
 		assert.NoError(t, err)
 		assert.Equal(t, "New Customer AS", result.Name)
-		assert.Equal(t, "123456789", result.OrgNumber)
+		assert.Equal(t, "123456793", result.OrgNumber)
 		assert.NotEqual(t, uuid.Nil, result.ID)
 	})
 
@@ -339,7 +339,7 @@ func TestCustomerHandler_Create(t *testing.T) {
 		// Create first customer
 		reqBody := domain.CreateCustomerRequest{
 			Name:      "First Customer",
-			OrgNumber: "999888777",
+			OrgNumber: "999888771",
 			Email:     "first@example.com",
 			Phone:     "12345678",
 			Country:   "Norway",
@@ -357,7 +357,7 @@ func TestCustomerHandler_Create(t *testing.T) {
 		// Try to create second customer with same org number
 		reqBody2 := domain.CreateCustomerRequest{
 			Name:      "Second Customer",
-			OrgNumber: "999888777", // Same org number
+			OrgNumber: "999888771", // Same org number
 			Email:     "second@example.com",
 			Phone:     "87654321",
 			Country:   "Norway",
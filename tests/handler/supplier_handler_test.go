@@ -279,7 +279,7 @@ func TestSupplierHandler_Create(t *testing.T) {
 	t.Run("create valid supplier", func(t *testing.T) {
 		reqBody := domain.CreateSupplierRequest{
 			Name:         "New Supplier AS",
-			OrgNumber:    "123456789",
+			OrgNumber:    "123456793",
 			Email:        "contact@newsupplier.no",
 			Phone:        "+47 12345678",
 			Address:      "Supplier Street 1",
@@ -305,7 +305,7 @@ func TestSupplierHandler_Create(t *testing.T) {
 		err := json.Unmarshal(rr.Body.Bytes(), &result)
 		assert.NoError(t, err)
 		assert.Equal(t, "New Supplier AS", result.Name)
-		assert.Equal(t, "123456789", result.OrgNumber)
+		assert.Equal(t, "123456793", result.OrgNumber)
 		assert.Equal(t, domain.SupplierStatusActive, result.Status) // Default status
 		assert.NotEqual(t, uuid.Nil, result.ID)
 	})
@@ -342,7 +342,7 @@ func TestSupplierHandler_Create(t *testing.T) {
 		// Create first supplier
 		reqBody := domain.CreateSupplierRequest{
 			Name:      "First Supplier",
-			OrgNumber: "999888777",
+			OrgNumber: "999888771",
 			Country:   "Norway",
 		}
 		body, _ := json.Marshal(reqBody)
@@ -358,7 +358,7 @@ func TestSupplierHandler_Create(t *testing.T) {
 		// Try to create second supplier with same org number
 		reqBody2 := domain.CreateSupplierRequest{
 			Name:      "Second Supplier",
-			OrgNumber: "999888777", // Same org number
+			OrgNumber: "999888771", // Same org number
 			Country:   "Norway",
 		}
 		body2, _ := json.Marshal(reqBody2)
@@ -0,0 +1,59 @@
+package ical_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/straye-as/relation-api/internal/ical"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWrite(t *testing.T) {
+	start := time.Date(2026, 3, 2, 9, 30, 0, 0, time.UTC)
+	modified := time.Date(2026, 2, 20, 12, 0, 0, 0, time.UTC)
+	cal := &ical.Calendar{
+		ProductID:       "-//Straye//Relation API//NO",
+		Name:            "Straye CRM - Ola",
+		RefreshInterval: 90 * time.Minute,
+		Events: []ical.Event{
+			{
+				UID:          "activity-1@example",
+				Summary:      "Møte med kunde; befaring, tak",
+				Description:  "Linje 1\nLinje 2",
+				Categories:   []string{"meeting"},
+				Status:       ical.StatusConfirmed,
+				Start:        start,
+				End:          start.Add(45 * time.Minute),
+				LastModified: modified,
+			},
+			{
+				UID:     "offer-2-due@example",
+				Summary: "Tilbudsfrist: " + strings.Repeat("Lang tittel ", 10),
+				Start:   time.Date(2026, 3, 31, 0, 0, 0, 0, time.UTC),
+				AllDay:  true,
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, ical.Write(&buf, cal))
+	out := buf.String()
+
+	assert.True(t, strings.HasPrefix(out, "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n"))
+	assert.True(t, strings.HasSuffix(out, "END:VCALENDAR\r\n"))
+	assert.Contains(t, out, "REFRESH-INTERVAL;VALUE=DURATION:PT1H30M\r\n")
+	assert.Contains(t, out, "SUMMARY:Møte med kunde\\; befaring\\, tak\r\n")
+	assert.Contains(t, out, "DESCRIPTION:Linje 1\\nLinje 2\r\n")
+	assert.Contains(t, out, "DTSTAMP:20260220T120000Z\r\n")
+	assert.Contains(t, out, "DTSTART:20260302T093000Z\r\nDTEND:20260302T101500Z\r\n")
+	assert.Contains(t, out, "DTSTART;VALUE=DATE:20260331\r\nDTEND;VALUE=DATE:20260401\r\n")
+	assert.Equal(t, 2, strings.Count(out, "BEGIN:VEVENT"))
+
+	for _, line := range strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n") {
+		assert.LessOrEqual(t, len(line), 75, line)
+	}
+	assert.Contains(t, strings.ReplaceAll(out, "\r\n ", ""), strings.Repeat("Lang tittel ", 10))
+}
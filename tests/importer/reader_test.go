@@ -0,0 +1,92 @@
+package importer_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/straye-as/relation-api/internal/importer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+)
+
+func TestRead_CSV(t *testing.T) {
+	t.Run("semicolon separated with BOM", func(t *testing.T) {
+		input := "\xef\xbb\xbfKundenavn;Org.nr.;Poststed\r\nBygg AS;923609016;Bergen\r\n;;\r\nVei AS;;Oslo\r\n"
+
+		sheet, err := importer.Read("kunder.csv", strings.NewReader(input))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Kundenavn", "Org.nr.", "Poststed"}, sheet.Headers)
+		require.Len(t, sheet.Rows, 2)
+		assert.Equal(t, []string{"Bygg AS", "923609016", "Bergen"}, sheet.Rows[0])
+		assert.Equal(t, []string{"Vei AS", "", "Oslo"}, sheet.Rows[1])
+	})
+
+	t.Run("comma separated with short rows", func(t *testing.T) {
+		input := "name,city,\nBygg AS\n"
+
+		sheet, err := importer.Read("customers.CSV", strings.NewReader(input))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"name", "city"}, sheet.Headers)
+		require.Len(t, sheet.Rows, 1)
+		assert.Equal(t, []string{"Bygg AS", ""}, sheet.Rows[0])
+	})
+
+	t.Run("latin-1 input", func(t *testing.T) {
+		input := []byte("Kundenavn;Poststed\nBr\xf8drene AS;Troms\xf8\n")
+
+		sheet, err := importer.Read("kunder.csv", bytes.NewReader(input))
+		require.NoError(t, err)
+		require.Len(t, sheet.Rows, 1)
+		assert.Equal(t, []string{"Brødrene AS", "Tromsø"}, sheet.Rows[0])
+	})
+
+	t.Run("empty file", func(t *testing.T) {
+		_, err := importer.Read("empty.csv", strings.NewReader("\n\n"))
+		assert.ErrorIs(t, err, importer.ErrEmptyFile)
+	})
+
+	t.Run("too many rows", func(t *testing.T) {
+		var b strings.Builder
+		b.WriteString("name\n")
+		for i := 0; i <= importer.MaxRows; i++ {
+			b.WriteString("x\n")
+		}
+
+		_, err := importer.Read("large.csv", strings.NewReader(b.String()))
+		assert.ErrorIs(t, err, importer.ErrTooManyRows)
+	})
+}
+
+func TestRead_XLSX(t *testing.T) {
+	file := excelize.NewFile()
+	defer file.Close()
+
+	sheet := file.GetSheetName(0)
+	require.NoError(t, file.SetSheetRow(sheet, "A1", &[]interface{}{"Prosjekt", "Tilbudspris", "Sendt"}))
+	require.NoError(t, file.SetSheetRow(sheet, "A2", &[]interface{}{"Ny skole", 1250000.5, time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)}))
+
+	var buf bytes.Buffer
+	require.NoError(t, file.Write(&buf))
+
+	result, err := importer.Read("tilbud.xlsx", &buf)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Prosjekt", "Tilbudspris", "Sendt"}, result.Headers)
+	require.Len(t, result.Rows, 1)
+	assert.Equal(t, "Ny skole", result.Rows[0][0])
+
+	value, err := importer.ParseNumber(result.Rows[0][1])
+	require.NoError(t, err)
+	assert.Equal(t, 1250000.5, value)
+
+	sent, err := importer.ParseDate(result.Rows[0][2])
+	require.NoError(t, err)
+	assert.Equal(t, "2024-03-15", sent.Format("2006-01-02"))
+}
+
+func TestRead_UnsupportedFormat(t *testing.T) {
+	_, err := importer.Read("kunder.xls", strings.NewReader("data"))
+	assert.ErrorIs(t, err, importer.ErrUnsupportedFormat)
+}
@@ -0,0 +1,103 @@
+package importer_test
+
+import (
+	"testing"
+
+	"github.com/straye-as/relation-api/internal/importer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseNumber(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected float64
+	}{
+		{"1234", 1234},
+		{"1 234,50", 1234.5},
+		{"1.234,50", 1234.5},
+		{"1,234.50", 1234.5},
+		{"1234.5", 1234.5},
+		{"12 500 kr", 12500},
+		{"12500 NOK", 12500},
+		{"12 500,-", 12500},
+		{"-350,25", -350.25},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result, err := importer.ParseNumber(tt.input)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+
+	for _, input := range []string{"", "abc", "12,5,3x"} {
+		_, err := importer.ParseNumber(input)
+		assert.Error(t, err, "input %q", input)
+	}
+}
+
+func TestParseDate(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"2024-03-15", "2024-03-15"},
+		{"15.03.2024", "2024-03-15"},
+		{"5.3.2024", "2024-03-05"},
+		{"15.03.24", "2024-03-15"},
+		{"45366", "2024-03-15"},
+		{"45366.5", "2024-03-15"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result, err := importer.ParseDate(tt.input)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result.Format("2006-01-02"))
+		})
+	}
+
+	for _, input := range []string{"", "12", "next week"} {
+		_, err := importer.ParseDate(input)
+		assert.Error(t, err, "input %q", input)
+	}
+}
+
+func TestParseBool(t *testing.T) {
+	for _, input := range []string{"ja", "Ja", "x", "1", "true", "yes"} {
+		result, err := importer.ParseBool(input)
+		require.NoError(t, err)
+		assert.True(t, result, "input %q", input)
+	}
+	for _, input := range []string{"", "nei", "0", "false"} {
+		result, err := importer.ParseBool(input)
+		require.NoError(t, err)
+		assert.False(t, result, "input %q", input)
+	}
+
+	_, err := importer.ParseBool("kanskje")
+	assert.Error(t, err)
+}
+
+func TestParseOrgNumber(t *testing.T) {
+	assert.Equal(t, "923609016", importer.ParseOrgNumber("923609016"))
+	assert.Equal(t, "923609016", importer.ParseOrgNumber("923609016.0"))
+	assert.Equal(t, "923 609 016", importer.ParseOrgNumber(" 923 609 016 "))
+	assert.Equal(t, "", importer.ParseOrgNumber(""))
+}
+
+func TestParsePostalCode(t *testing.T) {
+	assert.Equal(t, "0150", importer.ParsePostalCode("150"))
+	assert.Equal(t, "0150", importer.ParsePostalCode("150.0"))
+	assert.Equal(t, "5003", importer.ParsePostalCode("5003"))
+	assert.Equal(t, "", importer.ParsePostalCode(""))
+}
+
+func TestNormalizeHeader(t *testing.T) {
+	assert.Equal(t, "orgnr", importer.NormalizeHeader("Org.nr."))
+	assert.Equal(t, "orgnr", importer.NormalizeHeader(" org nr "))
+	assert.Equal(t, "vedståelsesfrist", importer.NormalizeHeader("Vedståelses frist"))
+	assert.Equal(t, "kundebyggherre", importer.NormalizeHeader("Kunde / Byggherre"))
+}
@@ -0,0 +1,62 @@
+package mail_test
+
+import (
+	"context"
+	netmail "net/mail"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/straye-as/relation-api/internal/mail"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompose(t *testing.T) {
+	from := &netmail.Address{Name: "Straye Stålbygg", Address: "post@straye.no"}
+	date := time.Date(2025, 3, 4, 10, 0, 0, 0, time.UTC)
+
+	data, err := mail.Compose(from, &mail.Message{
+		To:      "ola@example.com",
+		ToName:  "Ola Nordmann",
+		Subject: "Vi har mottatt henvendelsen din",
+		Body:    "Hei Ola,\n\nTakk for henvendelsen.",
+		ReplyTo: "selger@straye.no",
+	}, date)
+	require.NoError(t, err)
+
+	msg, err := netmail.ReadMessage(strings.NewReader(string(data)))
+	require.NoError(t, err)
+	assert.Equal(t, `"Ola Nordmann" <ola@example.com>`, msg.Header.Get("To"))
+	assert.Equal(t, "<selger@straye.no>", msg.Header.Get("Reply-To"))
+	assert.Equal(t, "quoted-printable", msg.Header.Get("Content-Transfer-Encoding"))
+	assert.True(t, strings.HasSuffix(msg.Header.Get("Message-Id"), "@straye.no>"))
+
+	parsedFrom, err := msg.Header.AddressList("From")
+	require.NoError(t, err)
+	assert.Equal(t, "Straye Stålbygg", parsedFrom[0].Name)
+
+	sentAt, err := msg.Header.Date()
+	require.NoError(t, err)
+	assert.True(t, sentAt.Equal(date))
+}
+
+func TestCompose_RejectsInvalidRecipient(t *testing.T) {
+	_, err := mail.Compose(&netmail.Address{Address: "post@straye.no"}, &mail.Message{To: "not an address"}, time.Now())
+	assert.ErrorIs(t, err, mail.ErrInvalidAddress)
+}
+
+func TestCompose_StripsHeaderInjection(t *testing.T) {
+	data, err := mail.Compose(&netmail.Address{Address: "post@straye.no"}, &mail.Message{
+		To:      "ola@example.com",
+		Subject: "Hei\r\nBcc: victim@example.com",
+	}, time.Now())
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "\r\nBcc:")
+}
+
+func TestNewSender_DisabledWithoutHost(t *testing.T) {
+	sender := mail.NewSender(mail.Config{})
+	assert.False(t, sender.Enabled())
+	assert.NoError(t, sender.Send(context.Background(), &mail.Message{To: "ola@example.com"}))
+}
@@ -265,6 +265,41 @@ func TestRateLimiter_XRealIP(t *testing.T) {
 	assert.Equal(t, 20, handlerCalled)
 }
 
+func TestRateLimiter_ClientIP(t *testing.T) {
+	rl := createTestRateLimiter(&config.RateLimitConfig{
+		Enabled:        true,
+		TrustedProxies: []string{"192.168.1.1"},
+	})
+
+	tests := []struct {
+		name          string
+		remoteAddr    string
+		xForwardedFor string
+		xRealIP       string
+		expected      string
+	}{
+		{"direct client", "203.0.113.5:4000", "", "", "203.0.113.5"},
+		{"direct client cannot spoof X-Forwarded-For", "203.0.113.5:4000", "10.0.0.1", "", "203.0.113.5"},
+		{"direct client cannot spoof X-Real-IP", "203.0.113.5:4000", "", "10.0.0.1", "203.0.113.5"},
+		{"trusted proxy", "192.168.1.1:4000", "198.51.100.7", "", "198.51.100.7"},
+		{"prepended hops are ignored", "192.168.1.1:4000", "10.0.0.1, 198.51.100.7", "", "198.51.100.7"},
+		{"trusted proxy with X-Real-IP", "192.168.1.1:4000", "", "198.51.100.7", "198.51.100.7"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/test", nil)
+			req.RemoteAddr = tt.remoteAddr
+			if tt.xForwardedFor != "" {
+				req.Header.Set("X-Forwarded-For", tt.xForwardedFor)
+			}
+			if tt.xRealIP != "" {
+				req.Header.Set("X-Real-IP", tt.xRealIP)
+			}
+			assert.Equal(t, tt.expected, rl.ClientIP(req))
+		})
+	}
+}
+
 func TestRateLimiter_AuthenticatedUserLimit(t *testing.T) {
 	cfg := &config.RateLimitConfig{
 		Enabled:               true,
@@ -0,0 +1,133 @@
+package postal_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/straye-as/relation-api/internal/postal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefault_BundledRegister(t *testing.T) {
+	register := postal.Default()
+	require.NotNil(t, register)
+	assert.Greater(t, register.Len(), 0)
+
+	entry, ok := register.Lookup("5003")
+	require.True(t, ok)
+	assert.Equal(t, "5003", entry.Code)
+	assert.Equal(t, "Bergen", entry.City)
+	assert.Equal(t, "4601", entry.MunicipalityNumber)
+	assert.Equal(t, "Bergen", entry.Municipality)
+	assert.Equal(t, "Vestland", entry.County)
+	assert.Equal(t, postal.CategoryStreet, entry.Category)
+
+	_, ok = register.Lookup("0000")
+	assert.False(t, ok)
+}
+
+func TestParse(t *testing.T) {
+	t.Run("utf-8 input", func(t *testing.T) {
+		input := "3800\tBØ I TELEMARK\t4020\tMIDT-TELEMARK\tG\r\n9170\tLONGYEARBYEN\t2100\tSVALBARD\tG\r\n"
+
+		register, err := postal.Parse(strings.NewReader(input))
+		require.NoError(t, err)
+		assert.Equal(t, 2, register.Len())
+
+		entry, ok := register.Lookup("3800")
+		require.True(t, ok)
+		assert.Equal(t, "Bø i Telemark", entry.City)
+		assert.Equal(t, "Midt-Telemark", entry.Municipality)
+		assert.Equal(t, "Telemark", entry.County)
+
+		entry, ok = register.Lookup("9170")
+		require.True(t, ok)
+		assert.Equal(t, "", entry.County, "Svalbard has no county")
+	})
+
+	t.Run("latin-1 input as published by Posten", func(t *testing.T) {
+		// "TROMSØ" with Ø encoded as the single byte 0xD8
+		input := []byte("9008\tTROMS\xd8\t5501\tTROMS\xd8\tG\n")
+
+		register, err := postal.Parse(bytes.NewReader(input))
+		require.NoError(t, err)
+
+		entry, ok := register.Lookup("9008")
+		require.True(t, ok)
+		assert.Equal(t, "Tromsø", entry.City)
+		assert.Equal(t, "Troms", entry.County)
+	})
+
+	t.Run("rejects malformed lines", func(t *testing.T) {
+		_, err := postal.Parse(strings.NewReader("0150\tOSLO\n"))
+		assert.Error(t, err)
+
+		_, err = postal.Parse(strings.NewReader("150\tOSLO\t0301\tOSLO\tG\n"))
+		assert.Error(t, err)
+	})
+
+	t.Run("rejects empty register", func(t *testing.T) {
+		_, err := postal.Parse(strings.NewReader("\n"))
+		assert.Error(t, err)
+	})
+}
+
+func TestRegister_WriteTo(t *testing.T) {
+	input := []byte("5003\tBERGEN\t4601\tBERGEN\tG\n0150\tOSLO\t0301\tOSLO\tG\n")
+	register, err := postal.Parse(bytes.NewReader(input))
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	_, err = register.WriteTo(&out)
+	require.NoError(t, err)
+	assert.Equal(t, "0150\tOSLO\t0301\tOSLO\tG\n5003\tBERGEN\t4601\tBERGEN\tG\n", out.String())
+
+	// The written file can be parsed again
+	reparsed, err := postal.Parse(&out)
+	require.NoError(t, err)
+	assert.Equal(t, register.Len(), reparsed.Len())
+}
+
+func TestFormatName(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"OSLO", "Oslo"},
+		{"BØ I TELEMARK", "Bø i Telemark"},
+		{"MO I RANA", "Mo i Rana"},
+		{"KRISTIANSAND S", "Kristiansand S"},
+		{"SØR-VARANGER", "Sør-Varanger"},
+		{"ÅLESUND", "Ålesund"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			assert.Equal(t, tt.expected, postal.FormatName(tt.input))
+		})
+	}
+}
+
+func TestSameCity(t *testing.T) {
+	assert.True(t, postal.SameCity("Oslo", "OSLO"))
+	assert.True(t, postal.SameCity(" Mo  i Rana ", "Mo i Rana"))
+	assert.True(t, postal.SameCity("Tromsø", "TROMSØ"))
+	assert.False(t, postal.SameCity("Bergen", "Oslo"))
+}
+
+func TestIsValidCode(t *testing.T) {
+	assert.True(t, postal.IsValidCode("0150"))
+	assert.False(t, postal.IsValidCode("150"))
+	assert.False(t, postal.IsValidCode("01500"))
+	assert.False(t, postal.IsValidCode("01A0"))
+}
+
+func TestCountyForMunicipalityNumber(t *testing.T) {
+	assert.Equal(t, "Oslo", postal.CountyForMunicipalityNumber("0301"))
+	assert.Equal(t, "Møre og Romsdal", postal.CountyForMunicipalityNumber("1508"))
+	assert.Equal(t, "", postal.CountyForMunicipalityNumber("2100"))
+	assert.Equal(t, "", postal.CountyForMunicipalityNumber(""))
+}
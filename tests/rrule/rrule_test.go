@@ -0,0 +1,112 @@
+package rrule_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/straye-as/relation-api/internal/rrule"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var oslo = func() *time.Location {
+	loc, err := time.LoadLocation("Europe/Oslo")
+	if err != nil {
+		panic(err)
+	}
+	return loc
+}()
+
+func dates(times []time.Time) []string {
+	out := make([]string, len(times))
+	for i, t := range times {
+		out[i] = t.Format("2006-01-02 15:04 MST")
+	}
+	return out
+}
+
+func TestOccurrences(t *testing.T) {
+	start := time.Date(2026, 1, 15, 10, 0, 0, 0, oslo) // Thursday
+	end := start.AddDate(2, 0, 0)
+
+	tests := []struct {
+		name string
+		rule string
+		want []string
+	}{
+		{
+			name: "quarterly with count keeps wall clock time across DST",
+			rule: "RRULE:FREQ=MONTHLY;INTERVAL=3;COUNT=4",
+			want: []string{"2026-01-15 10:00 CET", "2026-04-15 10:00 CEST", "2026-07-15 10:00 CEST", "2026-10-15 10:00 CEST"},
+		},
+		{
+			name: "weekly on several days until a date",
+			rule: "FREQ=WEEKLY;BYDAY=MO,TH;UNTIL=20260126",
+			want: []string{"2026-01-15 10:00 CET", "2026-01-19 10:00 CET", "2026-01-22 10:00 CET", "2026-01-26 10:00 CET"},
+		},
+		{
+			name: "first monday of every other month",
+			rule: "FREQ=MONTHLY;INTERVAL=2;BYDAY=1MO;COUNT=3",
+			want: []string{"2026-01-15 10:00 CET", "2026-03-02 10:00 CET", "2026-05-04 10:00 CEST"},
+		},
+		{
+			name: "last weekday of the month",
+			rule: "FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1;COUNT=3",
+			want: []string{"2026-01-15 10:00 CET", "2026-01-30 10:00 CET", "2026-02-27 10:00 CET"},
+		},
+		{
+			name: "monthly on the 31st skips short months",
+			rule: "FREQ=MONTHLY;BYMONTHDAY=31;COUNT=3",
+			want: []string{"2026-01-15 10:00 CET", "2026-01-31 10:00 CET", "2026-03-31 10:00 CEST"},
+		},
+		{
+			name: "yearly in march and september",
+			rule: "FREQ=YEARLY;BYMONTH=3,9;COUNT=4",
+			want: []string{"2026-01-15 10:00 CET", "2026-03-15 10:00 CET", "2026-09-15 10:00 CEST", "2027-03-15 10:00 CET"},
+		},
+		{
+			name: "every third day",
+			rule: "FREQ=DAILY;INTERVAL=3;COUNT=3",
+			want: []string{"2026-01-15 10:00 CET", "2026-01-18 10:00 CET", "2026-01-21 10:00 CET"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := rrule.Parse(tt.rule)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, dates(rule.Occurrences(start, end)))
+		})
+	}
+
+	t.Run("end bounds open-ended rules", func(t *testing.T) {
+		rule, err := rrule.Parse("FREQ=WEEKLY")
+		require.NoError(t, err)
+		assert.Len(t, rule.Occurrences(start, start.AddDate(0, 0, 28)), 5)
+	})
+
+	t.Run("between counts from the start of the series", func(t *testing.T) {
+		rule, err := rrule.Parse("FREQ=MONTHLY;COUNT=4")
+		require.NoError(t, err)
+		got := rule.Between(start, start.AddDate(0, 1, 1), end)
+		assert.Equal(t, []string{"2026-03-15 10:00 CET", "2026-04-15 10:00 CEST"}, dates(got))
+	})
+}
+
+func TestParse(t *testing.T) {
+	rule, err := rrule.Parse("freq=monthly;interval=3;byday=-1fr")
+	require.NoError(t, err)
+	assert.Equal(t, "FREQ=MONTHLY;INTERVAL=3;BYDAY=-1FR", rule.String())
+
+	until := time.Date(2026, 6, 30, 21, 59, 59, 0, time.UTC)
+	assert.Equal(t, "FREQ=MONTHLY;INTERVAL=3;UNTIL=20260630T215959Z;BYDAY=-1FR", rule.WithUntil(until).String())
+	assert.Equal(t, "FREQ=MONTHLY;INTERVAL=3;COUNT=2;BYDAY=-1FR", rule.WithCount(2).String())
+
+	for _, invalid := range []string{"", "INTERVAL=2", "FREQ=SOMETIMES", "FREQ=DAILY;COUNT=2;UNTIL=20260101", "FREQ=WEEKLY;BYDAY=1MO", "FREQ=MONTHLY;BYMONTHDAY=32"} {
+		_, err := rrule.Parse(invalid)
+		assert.ErrorIs(t, err, rrule.ErrInvalidRule, invalid)
+	}
+	for _, unsupported := range []string{"FREQ=HOURLY", "FREQ=DAILY;BYHOUR=9", "FREQ=YEARLY;BYDAY=MO"} {
+		_, err := rrule.Parse(unsupported)
+		assert.ErrorIs(t, err, rrule.ErrUnsupportedRule, unsupported)
+	}
+}
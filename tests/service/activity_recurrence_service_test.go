@@ -0,0 +1,237 @@
+package service_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/straye-as/relation-api/internal/domain"
+	"github.com/straye-as/relation-api/internal/repository"
+	"github.com/straye-as/relation-api/internal/service"
+	"github.com/straye-as/relation-api/internal/workcalendar"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func listSeries(t *testing.T, db *gorm.DB, seriesID uuid.UUID) []domain.Activity {
+	var activities []domain.Activity
+	require.NoError(t, db.Where("series_id = ?", seriesID).Order("occurrence_at ASC").Find(&activities).Error)
+	return activities
+}
+
+func TestActivityService_Recurrence(t *testing.T) {
+	db := setupActivityServiceTestDB(t)
+	svc := createActivityService(t, db)
+	customer := createActivityServiceTestCustomer(t, db)
+	userID := uuid.New()
+	ctx := createActivityTestContextWithUser(userID, "Account Manager", []domain.UserRoleType{domain.RoleMarket})
+
+	start := time.Now().AddDate(0, 0, 7).Truncate(time.Hour)
+	createSeries := func(t *testing.T, rule string) *domain.ActivityDTO {
+		activity, err := svc.Create(ctx, &domain.CreateActivityRequest{
+			TargetType:     domain.ActivityTargetCustomer,
+			TargetID:       customer.ID,
+			Title:          "Kvartalsmøte",
+			ActivityType:   domain.ActivityTypeMeeting,
+			ScheduledAt:    &start,
+			AssignedToID:   userID.String(),
+			RecurrenceRule: rule,
+		})
+		require.NoError(t, err)
+		return activity
+	}
+
+	t.Run("validation", func(t *testing.T) {
+		_, err := svc.Create(ctx, &domain.CreateActivityRequest{
+			TargetType:     domain.ActivityTargetCustomer,
+			TargetID:       customer.ID,
+			Title:          "Ugyldig",
+			ActivityType:   domain.ActivityTypeTask,
+			DueDate:        &start,
+			RecurrenceRule: "FREQ=SOMETIMES",
+		})
+		assert.ErrorIs(t, err, service.ErrInvalidRecurrenceRule)
+
+		_, err = svc.Create(ctx, &domain.CreateActivityRequest{
+			TargetType:     domain.ActivityTargetCustomer,
+			TargetID:       customer.ID,
+			Title:          "Uten tidspunkt",
+			ActivityType:   domain.ActivityTypeTask,
+			RecurrenceRule: "FREQ=WEEKLY",
+		})
+		assert.ErrorIs(t, err, service.ErrRecurrenceRequiresSchedule)
+	})
+
+	t.Run("create expands occurrences", func(t *testing.T) {
+		master := createSeries(t, "FREQ=MONTHLY;INTERVAL=3;COUNT=2")
+		assert.Equal(t, "FREQ=MONTHLY;INTERVAL=3;COUNT=2", master.RecurrenceRule)
+		require.NotNil(t, master.SeriesID)
+		assert.Equal(t, master.ID, *master.SeriesID)
+
+		series := listSeries(t, db, master.ID)
+		require.Len(t, series, 2)
+		assert.Equal(t, master.ID, series[0].ID)
+		assert.Equal(t, start.In(workcalendar.Oslo).AddDate(0, 3, 0).Unix(), series[1].ScheduledAt.Unix())
+		assert.Equal(t, "Kvartalsmøte", series[1].Title)
+
+		// Expanding again creates nothing new
+		_, created, err := svc.ExpandRecurringActivities(ctx)
+		require.NoError(t, err)
+		assert.Zero(t, created)
+		assert.Len(t, listSeries(t, db, master.ID), 2)
+	})
+
+	t.Run("occurrences keep the master's time of day after a database round trip", func(t *testing.T) {
+		master := createSeries(t, "FREQ=MONTHLY;INTERVAL=3;COUNT=2")
+		series := listSeries(t, db, master.ID)
+		require.Len(t, series, 2)
+
+		repo := repository.NewActivityRepository(db)
+		storedMaster, err := repo.GetByID(ctx, master.ID)
+		require.NoError(t, err)
+		occurrence, err := repo.GetByID(ctx, series[1].ID)
+		require.NoError(t, err)
+		require.NotNil(t, storedMaster.ScheduledAt)
+		require.NotNil(t, occurrence.ScheduledAt)
+
+		// The rule is expanded in Oslo time, so the wall clock is kept across daylight saving changes
+		expected := storedMaster.ScheduledAt.In(workcalendar.Oslo).AddDate(0, 3, 0)
+		assert.True(t, expected.Equal(*occurrence.ScheduledAt), "expected %s, got %s", expected, occurrence.ScheduledAt)
+		assert.Equal(t, storedMaster.ScheduledAt.In(workcalendar.Oslo).Format("15:04"), occurrence.ScheduledAt.In(workcalendar.Oslo).Format("15:04"))
+	})
+
+	t.Run("edit this occurrence only", func(t *testing.T) {
+		master := createSeries(t, "FREQ=WEEKLY;COUNT=3")
+		series := listSeries(t, db, master.ID)
+		require.Len(t, series, 3)
+
+		updated, err := svc.Update(ctx, series[1].ID, &domain.UpdateActivityRequest{
+			Title:        "Flyttet møte",
+			ScheduledAt:  series[1].ScheduledAt,
+			AssignedToID: userID.String(),
+		})
+		require.NoError(t, err)
+		assert.True(t, updated.IsRecurrenceException)
+
+		series = listSeries(t, db, master.ID)
+		assert.Equal(t, "Kvartalsmøte", series[2].Title)
+
+		_, err = svc.Update(ctx, series[1].ID, &domain.UpdateActivityRequest{
+			Title:          "Ny regel",
+			RecurrenceRule: func() *string { r := "FREQ=DAILY"; return &r }(),
+		})
+		assert.ErrorIs(t, err, service.ErrRecurrenceRuleRequiresScope)
+	})
+
+	t.Run("edit this and following splits the series", func(t *testing.T) {
+		master := createSeries(t, "FREQ=WEEKLY;COUNT=4")
+		series := listSeries(t, db, master.ID)
+		require.Len(t, series, 4)
+
+		updated, err := svc.Update(ctx, series[2].ID, &domain.UpdateActivityRequest{
+			Title:           "Nytt innhold",
+			ScheduledAt:     series[2].ScheduledAt,
+			AssignedToID:    userID.String(),
+			RecurrenceScope: domain.RecurrenceScopeFollowing,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, series[2].ID, *updated.SeriesID)
+		assert.Equal(t, "FREQ=WEEKLY;COUNT=2", updated.RecurrenceRule)
+
+		old := listSeries(t, db, master.ID)
+		require.Len(t, old, 2)
+		assert.Contains(t, old[0].RecurrenceRule, "UNTIL=")
+
+		following := listSeries(t, db, series[2].ID)
+		require.Len(t, following, 2)
+		assert.Equal(t, "Nytt innhold", following[1].Title)
+	})
+
+	t.Run("edit all occurrences", func(t *testing.T) {
+		master := createSeries(t, "FREQ=WEEKLY;COUNT=3")
+		series := listSeries(t, db, master.ID)
+
+		_, err := svc.Update(ctx, series[1].ID, &domain.UpdateActivityRequest{
+			Title:           "Nytt navn",
+			ScheduledAt:     series[1].ScheduledAt,
+			AssignedToID:    userID.String(),
+			RecurrenceScope: domain.RecurrenceScopeAll,
+		})
+		require.NoError(t, err)
+
+		series = listSeries(t, db, master.ID)
+		require.Len(t, series, 3)
+		for _, activity := range series {
+			assert.Equal(t, "Nytt navn", activity.Title)
+		}
+	})
+
+	t.Run("complete keeps the occurrence in the series", func(t *testing.T) {
+		master := createSeries(t, "FREQ=WEEKLY;COUNT=2")
+		series := listSeries(t, db, master.ID)
+
+		completed, err := svc.Complete(ctx, series[1].ID, "Gjennomført")
+		require.NoError(t, err)
+		assert.Equal(t, master.ID, *completed.SeriesID)
+		assert.Equal(t, domain.ActivityStatusCompleted, completed.Status)
+	})
+
+	t.Run("my tasks include the next occurrence of a series", func(t *testing.T) {
+		taskUser := uuid.New()
+		taskCtx := createActivityTestContextWithUser(taskUser, "Task User", []domain.UserRoleType{domain.RoleMarket})
+		due := time.Now().AddDate(0, 0, 1)
+		_, err := svc.Create(taskCtx, &domain.CreateActivityRequest{
+			TargetType:     domain.ActivityTargetCustomer,
+			TargetID:       customer.ID,
+			Title:          "Daglig oppfølging",
+			ActivityType:   domain.ActivityTypeTask,
+			DueDate:        &due,
+			AssignedToID:   taskUser.String(),
+			RecurrenceRule: "FREQ=DAILY;COUNT=10",
+		})
+		require.NoError(t, err)
+
+		tasks, err := svc.GetMyTasks(taskCtx, 1, 20)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), tasks.Total)
+
+		upcoming, err := svc.GetUpcoming(ctx, 90, 100)
+		require.NoError(t, err)
+		assert.NotEmpty(t, upcoming)
+	})
+
+	t.Run("delete this and following", func(t *testing.T) {
+		master := createSeries(t, "FREQ=WEEKLY;COUNT=4")
+		series := listSeries(t, db, master.ID)
+
+		require.NoError(t, svc.DeleteWithScope(ctx, series[2].ID, domain.RecurrenceScopeFollowing))
+		remaining := listSeries(t, db, master.ID)
+		assert.Len(t, remaining, 2)
+
+		// Deleted occurrences are not generated again
+		_, _, err := svc.ExpandRecurringActivities(ctx)
+		require.NoError(t, err)
+		assert.Len(t, listSeries(t, db, master.ID), 2)
+	})
+
+	t.Run("delete the master promotes the next occurrence", func(t *testing.T) {
+		master := createSeries(t, "FREQ=WEEKLY;COUNT=3")
+		series := listSeries(t, db, master.ID)
+
+		require.NoError(t, svc.Delete(ctx, master.ID))
+		promoted, err := svc.GetByID(ctx, series[1].ID)
+		require.NoError(t, err)
+		assert.Equal(t, series[1].ID, *promoted.SeriesID)
+		assert.Equal(t, "FREQ=WEEKLY;COUNT=2", promoted.RecurrenceRule)
+		assert.Len(t, listSeries(t, db, series[1].ID), 2)
+	})
+
+	t.Run("delete all", func(t *testing.T) {
+		master := createSeries(t, "FREQ=WEEKLY;COUNT=3")
+		require.NoError(t, svc.DeleteWithScope(ctx, master.ID, domain.RecurrenceScopeAll))
+		assert.Empty(t, listSeries(t, db, master.ID))
+
+		assert.ErrorIs(t, svc.DeleteWithScope(ctx, master.ID, "sometimes"), service.ErrInvalidRecurrenceScope)
+	})
+}
@@ -0,0 +1,173 @@
+package service_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/straye-as/relation-api/internal/domain"
+	"github.com/straye-as/relation-api/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func rollupTestItem(parentType domain.BudgetParentType, parentID uuid.UUID, name string, cost, revenue float64, category domain.BudgetCostCategory) domain.BudgetItem {
+	item := domain.BudgetItem{
+		ID:              uuid.New(),
+		ParentType:      parentType,
+		ParentID:        parentID,
+		Name:            name,
+		ExpectedCost:    cost,
+		ExpectedRevenue: revenue,
+	}
+	if category != "" {
+		item.CostCategory = &category
+	}
+	return item
+}
+
+func TestBuildProjectBudgetRollup(t *testing.T) {
+	project := &domain.Project{Name: "Test Rollup"}
+	project.ID = uuid.New()
+	offer := domain.Offer{Title: "Test Tak", Phase: domain.OfferPhaseOrder, DWMaterialCosts: 60000, DWEmployeeCosts: 30000, DWOtherCosts: 5000, DWTotalIncome: 150000}
+	offer.ID = uuid.New()
+
+	roofing := rollupTestItem(domain.BudgetParentOffer, offer.ID, "Taktekking", 100000, 125000, domain.BudgetCostCategoryMaterial)
+	labour := rollupTestItem(domain.BudgetParentOffer, offer.ID, "Montasje", 50000, 62500, domain.BudgetCostCategoryEmployee)
+	soldItems := []domain.BudgetItem{roofing, labour}
+
+	t.Run("sold items are the budget until the project has its own", func(t *testing.T) {
+		rollup := service.BuildProjectBudgetRollup(project, []domain.Offer{offer}, soldItems, nil)
+
+		assert.Equal(t, domain.BudgetParentOffer, rollup.BudgetSource)
+		require.Len(t, rollup.Lines, 3)
+		assert.Equal(t, 100000.0, rollup.Lines[0].BudgetCost)
+		assert.Equal(t, 60000.0, rollup.Lines[0].ActualCost)
+		assert.Equal(t, 30000.0, rollup.Lines[1].ActualCost)
+		assert.True(t, rollup.Lines[2].Unallocated)
+		assert.Equal(t, domain.BudgetCostCategoryOther, rollup.Lines[2].CostCategory)
+		assert.Equal(t, 5000.0, rollup.Lines[2].ActualCost)
+		assert.Equal(t, 95000.0, rollup.Totals.ActualCost)
+		assert.Equal(t, 150000.0, rollup.Totals.ActualIncome)
+		assert.Equal(t, 0.0, rollup.Totals.Rebudgeted)
+	})
+
+	t.Run("project items rebudget their sold item and share its actuals", func(t *testing.T) {
+		roofingA := rollupTestItem(domain.BudgetParentProject, project.ID, "Taktekking A", 90000, 112500, domain.BudgetCostCategoryMaterial)
+		roofingA.SourceItemID = &roofing.ID
+		scaffold := rollupTestItem(domain.BudgetParentProject, project.ID, "Stillas", 30000, 37500, domain.BudgetCostCategoryMaterial)
+		labourP := rollupTestItem(domain.BudgetParentProject, project.ID, "Montasje", 50000, 62500, domain.BudgetCostCategoryEmployee)
+		labourP.SourceItemID = &labour.ID
+
+		rollup := service.BuildProjectBudgetRollup(project, []domain.Offer{offer}, soldItems, []domain.BudgetItem{roofingA, scaffold, labourP})
+
+		assert.Equal(t, domain.BudgetParentProject, rollup.BudgetSource)
+		require.Len(t, rollup.Lines, 4)
+
+		roofingLine := rollup.Lines[0]
+		assert.Equal(t, &roofing.ID, roofingLine.SoldItemID)
+		assert.Equal(t, []uuid.UUID{roofingA.ID}, roofingLine.BudgetItemIDs)
+		assert.Equal(t, 100000.0, roofingLine.SoldCost)
+		assert.Equal(t, 90000.0, roofingLine.BudgetCost)
+		assert.Equal(t, -10000.0, roofingLine.Rebudgeted)
+		assert.Equal(t, 45000.0, roofingLine.ActualCost) // 60000 split 90000:30000
+		require.NotNil(t, roofingLine.PercentSpent)
+		assert.Equal(t, 50.0, *roofingLine.PercentSpent)
+
+		scaffoldLine := rollup.Lines[2]
+		assert.Nil(t, scaffoldLine.SoldItemID)
+		assert.Equal(t, 15000.0, scaffoldLine.ActualCost)
+		assert.Equal(t, 15000.0, scaffoldLine.RemainingCost)
+
+		assert.Equal(t, 170000.0, rollup.Totals.BudgetCost)
+		assert.Equal(t, 20000.0, rollup.Totals.Rebudgeted)
+	})
+}
+
+func TestBudgetItemService_ProjectBudget(t *testing.T) {
+	db := setupProjectTestDB(t)
+	_, fixtures := setupProjectTestService(t, db)
+	t.Cleanup(func() { fixtures.cleanup(t) })
+	ctx := createProjectTestContext()
+
+	svc := service.NewBudgetItemService(fixtures.budgetItemRepo, fixtures.offerRepo, fixtures.projectRepo, zap.NewNop())
+	project, customer := fixtures.createTestProject(t, ctx, "Test Budget Project", domain.ProjectPhaseWorking)
+
+	createOffer := func(title string, phase domain.OfferPhase) *domain.Offer {
+		offer := &domain.Offer{
+			Title:        title,
+			OfferNumber:  fmt.Sprintf("BUDGET-%d", time.Now().UnixNano()),
+			CustomerID:   &customer.ID,
+			CustomerName: customer.Name,
+			CompanyID:    domain.CompanyStalbygg,
+			Phase:        phase,
+			Status:       domain.OfferStatusActive,
+			ProjectID:    &project.ID,
+		}
+		require.NoError(t, db.Create(offer).Error)
+		return offer
+	}
+
+	t.Run("requires a won offer to initialize", func(t *testing.T) {
+		_, err := svc.InitializeProjectBudget(ctx, project.ID)
+		assert.ErrorIs(t, err, service.ErrProjectHasNoWonOffer)
+	})
+
+	won := createOffer("Test Won Offer", domain.OfferPhaseOrder)
+	sold, err := svc.AddToOffer(ctx, won.ID, domain.AddOfferBudgetItemRequest{
+		Name: "Stål", ExpectedCost: 200000, ExpectedMargin: 20, CostCategory: domain.BudgetCostCategoryMaterial,
+	})
+	require.NoError(t, err)
+	lost := createOffer("Test Lost Offer", domain.OfferPhaseLost)
+	lostItem, err := svc.AddToOffer(ctx, lost.ID, domain.AddOfferBudgetItemRequest{Name: "Betong", ExpectedCost: 1000})
+	require.NoError(t, err)
+
+	t.Run("initializes from the won offer without touching it", func(t *testing.T) {
+		items, err := svc.InitializeProjectBudget(ctx, project.ID)
+		require.NoError(t, err)
+		require.Len(t, items, 1)
+		assert.Equal(t, domain.BudgetParentProject, items[0].ParentType)
+		assert.Equal(t, &sold.ID, items[0].SourceItemID)
+		assert.Equal(t, domain.BudgetCostCategoryMaterial, items[0].CostCategory)
+
+		_, err = svc.UpdateProjectDimension(ctx, project.ID, items[0].ID, domain.UpdateBudgetItemRequest{
+			Name: "Stål", ExpectedCost: 230000, ExpectedMargin: 15, CostCategory: domain.BudgetCostCategoryMaterial,
+		})
+		require.NoError(t, err)
+
+		offerItems, err := svc.ListByOffer(ctx, won.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 200000.0, offerItems[0].ExpectedCost)
+
+		_, err = svc.InitializeProjectBudget(ctx, project.ID)
+		assert.ErrorIs(t, err, service.ErrProjectBudgetNotEmpty)
+	})
+
+	t.Run("only rebudgets items of won offers", func(t *testing.T) {
+		_, err := svc.AddToProject(ctx, project.ID, domain.AddProjectBudgetItemRequest{Name: "Betong", ExpectedCost: 1000, SourceItemID: &lostItem.ID})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "does not belong")
+	})
+
+	t.Run("rejects offer items on project routes", func(t *testing.T) {
+		err := svc.DeleteProjectDimension(ctx, project.ID, sold.ID)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "does not belong to this project")
+	})
+
+	t.Run("rolls up the rebudget against actuals", func(t *testing.T) {
+		require.NoError(t, db.Model(&domain.Offer{}).Where("id = ?", won.ID).Update("dw_material_costs", 115000).Error)
+
+		rollup, err := svc.GetProjectBudgetRollup(ctx, project.ID)
+		require.NoError(t, err)
+		assert.Equal(t, domain.BudgetParentProject, rollup.BudgetSource)
+		require.Len(t, rollup.Lines, 1)
+		assert.Equal(t, 200000.0, rollup.Lines[0].SoldCost)
+		assert.Equal(t, 230000.0, rollup.Lines[0].BudgetCost)
+		assert.Equal(t, 115000.0, rollup.Lines[0].ActualCost)
+		require.NotNil(t, rollup.Lines[0].PercentSpent)
+		assert.Equal(t, 50.0, *rollup.Lines[0].PercentSpent)
+	})
+}
@@ -0,0 +1,126 @@
+package service_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/straye-as/relation-api/internal/domain"
+	"github.com/straye-as/relation-api/internal/repository"
+	"github.com/straye-as/relation-api/internal/service"
+	"github.com/straye-as/relation-api/tests/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestCalendarFeedService(t *testing.T) {
+	db := testutil.SetupCleanTestDB(t)
+	svc := service.NewCalendarFeedService(
+		repository.NewCalendarFeedTokenRepository(db),
+		repository.NewActivityRepository(db),
+		repository.NewOfferRepository(db),
+		repository.NewUserRepository(db),
+		zap.NewNop(),
+	)
+
+	userID := uuid.New()
+	ctx := createActivityTestContextWithUser(userID, "Kalender Bruker", []domain.UserRoleType{domain.RoleMarket})
+	customer := testutil.CreateTestCustomer(t, db, "Kalender Kunde AS")
+
+	inTwoDays := time.Now().AddDate(0, 0, 2).Truncate(time.Minute)
+	duration := 60
+	meeting := &domain.Activity{
+		TargetType:      domain.ActivityTargetCustomer,
+		TargetID:        customer.ID,
+		TargetName:      customer.Name,
+		Title:           "Befaring",
+		ActivityType:    domain.ActivityTypeMeeting,
+		Status:          domain.ActivityStatusPlanned,
+		ScheduledAt:     &inTwoDays,
+		DurationMinutes: &duration,
+		Attendees:       pq.StringArray{userID.String()},
+	}
+	dueDate := time.Now().AddDate(0, 0, 5)
+	task := &domain.Activity{
+		TargetType:   domain.ActivityTargetCustomer,
+		TargetID:     customer.ID,
+		Title:        "Følg opp",
+		ActivityType: domain.ActivityTypeTask,
+		Status:       domain.ActivityStatusPlanned,
+		DueDate:      &dueDate,
+		AssignedToID: userID.String(),
+	}
+	otherUsers := &domain.Activity{
+		TargetType:   domain.ActivityTargetCustomer,
+		TargetID:     customer.ID,
+		Title:        "Ikke min",
+		ActivityType: domain.ActivityTypeCall,
+		ScheduledAt:  &inTwoDays,
+		AssignedToID: uuid.NewString(),
+	}
+	for _, activity := range []*domain.Activity{meeting, task, otherUsers} {
+		require.NoError(t, db.Create(activity).Error)
+	}
+
+	expiration := time.Now().AddDate(0, 0, 30)
+	offer := &domain.Offer{
+		Title:             "Nytt tak",
+		OfferNumber:       fmt.Sprintf("CAL-%d", time.Now().UnixNano()),
+		CustomerID:        &customer.ID,
+		CustomerName:      customer.Name,
+		CompanyID:         domain.CompanyStalbygg,
+		Phase:             domain.OfferPhaseSent,
+		Status:            domain.OfferStatusActive,
+		ResponsibleUserID: userID.String(),
+		DueDate:           &dueDate,
+		ExpirationDate:    &expiration,
+	}
+	require.NoError(t, db.Create(offer).Error)
+
+	feed, err := svc.GetFeed(ctx)
+	require.NoError(t, err)
+	assert.False(t, feed.Active)
+	assert.ErrorIs(t, svc.RevokeToken(ctx), service.ErrCalendarFeedNotFound)
+
+	first, err := svc.CreateToken(ctx)
+	require.NoError(t, err)
+	token, err := svc.CreateToken(ctx)
+	require.NoError(t, err)
+	assert.NotEqual(t, first.Token, token.Token)
+
+	t.Run("feed contains assigned and attended activities and offer deadlines", func(t *testing.T) {
+		_, err := svc.Feed(ctx, first.Token)
+		assert.ErrorIs(t, err, service.ErrCalendarFeedNotFound)
+
+		cal, err := svc.Feed(ctx, token.Token)
+		require.NoError(t, err)
+
+		uids := map[string]bool{}
+		for _, event := range cal.Events {
+			uids[event.UID] = true
+			if event.UID == fmt.Sprintf("activity-%s@relation-api.straye", meeting.ID) {
+				assert.Equal(t, time.Hour, event.End.Sub(event.Start))
+				assert.False(t, event.AllDay)
+			}
+		}
+		assert.Len(t, cal.Events, 4)
+		assert.True(t, uids[fmt.Sprintf("activity-%s@relation-api.straye", task.ID)])
+		assert.True(t, uids[fmt.Sprintf("offer-%s-due@relation-api.straye", offer.ID)])
+		assert.True(t, uids[fmt.Sprintf("offer-%s-expiration@relation-api.straye", offer.ID)])
+		assert.False(t, uids[fmt.Sprintf("activity-%s@relation-api.straye", otherUsers.ID)])
+
+		feed, err := svc.GetFeed(ctx)
+		require.NoError(t, err)
+		assert.True(t, feed.Active)
+		assert.NotNil(t, feed.LastAccessedAt)
+	})
+
+	t.Run("revoked token stops the feed", func(t *testing.T) {
+		require.NoError(t, svc.RevokeToken(ctx))
+		_, err := svc.Feed(ctx, token.Token)
+		assert.ErrorIs(t, err, service.ErrCalendarFeedNotFound)
+	})
+}
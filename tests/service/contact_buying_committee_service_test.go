@@ -0,0 +1,92 @@
+package service_test
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/straye-as/relation-api/internal/domain"
+	"github.com/straye-as/relation-api/internal/service"
+	"github.com/straye-as/relation-api/tests/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContactService_BuyingCommittee(t *testing.T) {
+	db := setupContactServiceTestDB(t)
+	svc := createContactService(db)
+	ctx := createContactTestContext()
+
+	customer := testutil.CreateTestCustomer(t, db, "Innkjøp Kunde AS")
+	offerID := uuid.New()
+
+	role := func(r domain.BuyingRole) *domain.BuyingRole { return &r }
+	sentiment := func(s domain.ContactSentiment) *domain.ContactSentiment { return &s }
+
+	addMember := func(firstName string, buyingRole *domain.BuyingRole, feeling *domain.ContactSentiment) (*domain.ContactDTO, *domain.ContactRelationshipDTO) {
+		contact, err := svc.Create(ctx, &domain.CreateContactRequest{FirstName: firstName, LastName: "Komité"})
+		require.NoError(t, err)
+		rel, err := svc.AddRelationship(ctx, contact.ID, &domain.AddContactRelationshipRequest{
+			EntityType: domain.ContactEntityOffer,
+			EntityID:   offerID,
+			BuyingRole: buyingRole,
+			Sentiment:  feeling,
+		})
+		require.NoError(t, err)
+		return contact, rel
+	}
+
+	_, influencerRel := addMember("Ida", role(domain.BuyingRoleInfluencer), sentiment(domain.ContactSentimentNegative))
+	evaluator, _ := addMember("Tor", role(domain.BuyingRoleTechnicalEvaluator), sentiment(domain.ContactSentimentPositive))
+	addMember("Uten", nil, nil)
+
+	t.Run("validation", func(t *testing.T) {
+		_, err := svc.AddRelationship(ctx, evaluator.ID, &domain.AddContactRelationshipRequest{
+			EntityType: domain.ContactEntityCustomer,
+			EntityID:   customer.ID,
+			BuyingRole: role(domain.BuyingRoleDecisionMaker),
+		})
+		assert.ErrorIs(t, err, service.ErrBuyingRoleRequiresOffer)
+
+		_, err = svc.AddRelationship(ctx, evaluator.ID, &domain.AddContactRelationshipRequest{
+			EntityType: domain.ContactEntityOffer,
+			EntityID:   uuid.New(),
+			Sentiment:  sentiment("ecstatic"),
+		})
+		assert.ErrorIs(t, err, service.ErrInvalidContactSentiment)
+	})
+
+	t.Run("committee without decision maker", func(t *testing.T) {
+		committee, err := svc.GetBuyingCommittee(ctx, offerID)
+		require.NoError(t, err)
+		require.Len(t, committee.Members, 3)
+		assert.Equal(t, domain.BuyingRoleTechnicalEvaluator, *committee.Members[0].BuyingRole)
+		assert.Equal(t, domain.BuyingRoleInfluencer, *committee.Members[1].BuyingRole)
+		assert.Nil(t, committee.Members[2].BuyingRole)
+		assert.Equal(t, []domain.BuyingRole{domain.BuyingRoleDecisionMaker, domain.BuyingRoleEconomicBuyer}, committee.MissingRoles)
+		assert.False(t, committee.HasDecisionMaker)
+		assert.Equal(t, 1, committee.PositiveCount)
+		assert.Equal(t, 1, committee.NegativeCount)
+		assert.Contains(t, committee.Warnings, domain.OfferWarningMissingDecisionMaker)
+	})
+
+	t.Run("update relationship to decision maker", func(t *testing.T) {
+		_, err := svc.UpdateRelationship(ctx, evaluator.ID, influencerRel.ID, &domain.UpdateContactRelationshipRequest{})
+		assert.ErrorIs(t, err, service.ErrContactRelationshipNotFound)
+
+		updated, err := svc.UpdateRelationship(ctx, influencerRel.ContactID, influencerRel.ID, &domain.UpdateContactRelationshipRequest{
+			Role:       "Daglig leder",
+			BuyingRole: role(domain.BuyingRoleDecisionMaker),
+			Sentiment:  sentiment(domain.ContactSentimentNeutral),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "Daglig leder", updated.Role)
+
+		committee, err := svc.GetBuyingCommittee(ctx, offerID)
+		require.NoError(t, err)
+		assert.True(t, committee.HasDecisionMaker)
+		assert.Equal(t, influencerRel.ID, committee.Members[0].RelationshipID)
+		assert.Equal(t, []domain.BuyingRole{domain.BuyingRoleEconomicBuyer, domain.BuyingRoleInfluencer}, committee.MissingRoles)
+		assert.Equal(t, 1, committee.NeutralCount)
+		assert.Empty(t, committee.Warnings)
+	})
+}
@@ -0,0 +1,184 @@
+package service_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/straye-as/relation-api/internal/domain"
+	"github.com/straye-as/relation-api/internal/service"
+	"github.com/straye-as/relation-api/internal/vcard"
+	"github.com/straye-as/relation-api/tests/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContactService_FindDuplicatesAndMerge(t *testing.T) {
+	db := setupContactServiceTestDB(t)
+	svc := createContactService(db)
+	ctx := createContactTestContext()
+
+	customer := testutil.CreateTestCustomer(t, db, "Duplikat Kunde AS")
+	other := testutil.CreateTestCustomer(t, db, "Annen Kunde AS")
+
+	original, err := svc.Create(ctx, &domain.CreateContactRequest{
+		FirstName:         "Per",
+		LastName:          "Hansen",
+		Phone:             "+47 22 33 44 55",
+		PrimaryCustomerID: &customer.ID,
+	})
+	require.NoError(t, err)
+	_, err = svc.AddRelationship(ctx, original.ID, &domain.AddContactRelationshipRequest{
+		EntityType: domain.ContactEntityCustomer,
+		EntityID:   customer.ID,
+	})
+	require.NoError(t, err)
+
+	// Same name and customer, different email
+	byName, err := svc.Create(ctx, &domain.CreateContactRequest{
+		FirstName:         "per",
+		LastName:          "hansen ",
+		Email:             "per.hansen@example.com",
+		Title:             "Innkjøper",
+		PrimaryCustomerID: &customer.ID,
+	})
+	require.NoError(t, err)
+	_, err = svc.AddRelationship(ctx, byName.ID, &domain.AddContactRelationshipRequest{
+		EntityType: domain.ContactEntityCustomer,
+		EntityID:   customer.ID,
+		IsPrimary:  true,
+	})
+	require.NoError(t, err)
+	_, err = svc.AddRelationship(ctx, byName.ID, &domain.AddContactRelationshipRequest{
+		EntityType: domain.ContactEntityProject,
+		EntityID:   uuid.New(),
+	})
+	require.NoError(t, err)
+
+	// Same phone number, formatted differently
+	byPhone, err := svc.Create(ctx, &domain.CreateContactRequest{FirstName: "P.", LastName: "Hansen", Mobile: "0047 22334455"})
+	require.NoError(t, err)
+
+	// Same name at another customer is a different person
+	namesake, err := svc.Create(ctx, &domain.CreateContactRequest{FirstName: "Per", LastName: "Hansen", PrimaryCustomerID: &other.ID})
+	require.NoError(t, err)
+
+	t.Run("find duplicates", func(t *testing.T) {
+		groups, err := svc.FindDuplicates(ctx, &customer.ID)
+		require.NoError(t, err)
+		require.Len(t, groups, 1)
+		assert.ElementsMatch(t, []string{"name", "customer"}, groups[0].MatchedOn)
+		assert.Len(t, groups[0].Contacts, 2)
+		assert.Equal(t, byName.ID, groups[0].SuggestedSurvivorID)
+
+		groups, err = svc.FindDuplicates(ctx, nil)
+		require.NoError(t, err)
+		var found bool
+		for _, group := range groups {
+			ids := make([]uuid.UUID, len(group.Contacts))
+			for i, contact := range group.Contacts {
+				ids[i] = contact.ID
+			}
+			if assert.NotContains(t, ids, namesake.ID) && len(ids) == 3 {
+				found = true
+				assert.ElementsMatch(t, []uuid.UUID{original.ID, byName.ID, byPhone.ID}, ids)
+				assert.Contains(t, group.MatchedOn, "phone")
+			}
+		}
+		assert.True(t, found)
+	})
+
+	t.Run("merge moves relationships and activities", func(t *testing.T) {
+		_, err := svc.Merge(ctx, original.ID, &domain.MergeContactsRequest{DuplicateIDs: []uuid.UUID{original.ID}})
+		assert.ErrorIs(t, err, service.ErrMergeIntoSelf)
+
+		result, err := svc.Merge(ctx, original.ID, &domain.MergeContactsRequest{DuplicateIDs: []uuid.UUID{byName.ID, byPhone.ID}})
+		require.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{byName.ID, byPhone.ID}, result.MergedContactIDs)
+		assert.Equal(t, int64(1), result.RelationshipsMoved)
+		assert.Positive(t, result.ActivitiesMoved)
+
+		merged := result.Contact
+		assert.Equal(t, "per.hansen@example.com", merged.Email)
+		assert.Equal(t, "Innkjøper", merged.Title)
+		assert.Equal(t, "0047 22334455", merged.Mobile)
+		require.Len(t, merged.Relationships, 2)
+		for _, rel := range merged.Relationships {
+			if rel.EntityID == customer.ID {
+				assert.True(t, rel.IsPrimary)
+			}
+		}
+
+		var duplicate domain.Contact
+		require.NoError(t, db.First(&duplicate, "id = ?", byName.ID).Error)
+		assert.False(t, duplicate.IsActive)
+		assert.Empty(t, duplicate.Email)
+		assert.Equal(t, &original.ID, duplicate.MergedIntoID)
+
+		var moved int64
+		require.NoError(t, db.Model(&domain.Activity{}).
+			Where("target_type = ? AND target_id IN ?", domain.ActivityTargetContact, []uuid.UUID{byName.ID, byPhone.ID}).
+			Count(&moved).Error)
+		assert.Zero(t, moved)
+
+		_, err = svc.Merge(ctx, original.ID, &domain.MergeContactsRequest{DuplicateIDs: []uuid.UUID{byName.ID}})
+		assert.ErrorIs(t, err, service.ErrDuplicateContactNotFound)
+	})
+}
+
+func TestContactService_VCardImportExport(t *testing.T) {
+	db := setupContactServiceTestDB(t)
+	svc := createContactService(db)
+	ctx := createContactTestContext()
+
+	customer := testutil.CreateTestCustomer(t, db, "vCard Kunde AS")
+	existing, err := svc.Create(ctx, &domain.CreateContactRequest{
+		FirstName: "Kari",
+		LastName:  "Nordmann",
+		Email:     "kari@example.com",
+		Title:     "Daglig leder",
+	})
+	require.NoError(t, err)
+
+	input := "BEGIN:VCARD\r\nVERSION:3.0\r\nN:Nordmann;Kari;;;\r\nEMAIL;TYPE=WORK:KARI@example.com\r\nTEL;TYPE=CELL:+47 900 00 000\r\nEND:VCARD\r\n" +
+		"BEGIN:VCARD\r\nVERSION:4.0\r\nFN:Ola Nordmann\r\nEMAIL:ola@example.com\r\nEND:VCARD\r\n" +
+		"BEGIN:VCARD\r\nVERSION:4.0\r\nEMAIL:ugyldig\r\nEND:VCARD\r\n" +
+		"BEGIN:VCARD\r\nVERSION:4.0\r\nTEL:12345678\r\nEND:VCARD\r\n"
+
+	t.Run("import upserts by email", func(t *testing.T) {
+		result, err := svc.ImportVCards(ctx, strings.NewReader(input), &customer.ID)
+		require.NoError(t, err)
+		assert.Equal(t, domain.ImportSummaryDTO{Total: 4, Create: 1, Update: 1, Skip: 1, Error: 1}, result.Summary)
+		assert.Equal(t, domain.ImportRowActionUpdate, result.Cards[0].Action)
+		assert.Equal(t, &existing.ID, result.Cards[0].ContactID)
+
+		updated, err := svc.GetByID(ctx, existing.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "+47 900 00 000", updated.Mobile)
+		assert.Equal(t, "Daglig leder", updated.Title)
+		assert.Equal(t, &customer.ID, updated.PrimaryCustomerID)
+
+		_, err = svc.ImportVCards(ctx, strings.NewReader("ikke et vcard"), nil)
+		assert.ErrorIs(t, err, service.ErrInvalidVCard)
+	})
+
+	t.Run("export customer contacts", func(t *testing.T) {
+		exported, cards, err := svc.ExportCustomerVCards(ctx, customer.ID)
+		require.NoError(t, err)
+		assert.Equal(t, customer.ID, exported.ID)
+		require.Len(t, cards, 2)
+
+		var buf bytes.Buffer
+		require.NoError(t, vcard.Write(&buf, cards...))
+		parsed, err := vcard.Parse(&buf)
+		require.NoError(t, err)
+		require.Len(t, parsed, 2)
+		assert.Equal(t, customer.Name, parsed[0].Organization)
+
+		card, err := svc.ExportVCard(ctx, existing.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "urn:uuid:"+existing.ID.String(), card.UID)
+		assert.Equal(t, "kari@example.com", card.Email)
+	})
+}
@@ -0,0 +1,145 @@
+package service_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/straye-as/relation-api/internal/domain"
+	"github.com/straye-as/relation-api/internal/service"
+	"github.com/straye-as/relation-api/tests/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContactService_DataSubjectExportAndAnonymize(t *testing.T) {
+	db := setupContactServiceTestDB(t)
+	svc := createContactService(db)
+	ctx := createContactTestContext()
+
+	customer := testutil.CreateTestCustomer(t, db, "Personvern Kunde AS")
+	contact, err := svc.Create(ctx, &domain.CreateContactRequest{
+		FirstName:         "Kari",
+		LastName:          "Nordmann",
+		Email:             "kari.nordmann@example.com",
+		Phone:             "+47 41234567",
+		PrimaryCustomerID: &customer.ID,
+	})
+	require.NoError(t, err)
+
+	// Denormalized copies of the contact person on the customer and in free text
+	require.NoError(t, db.Model(&domain.Customer{}).Where("id = ?", customer.ID).Updates(map[string]interface{}{
+		"contact_person": "Kari Nordmann",
+		"contact_email":  "KARI.NORDMANN@example.com",
+	}).Error)
+	note := &domain.Activity{
+		TargetType: domain.ActivityTargetCustomer,
+		TargetID:   customer.ID,
+		TargetName: customer.Name,
+		Title:      "Møte",
+		Body:       "Ringte Kari Nordmann om befaring",
+	}
+	require.NoError(t, db.Create(note).Error)
+
+	t.Run("export gathers contact, activities and references", func(t *testing.T) {
+		export, err := svc.ExportDataSubject(ctx, contact.ID)
+		require.NoError(t, err)
+		require.Len(t, export.Contacts, 1)
+		assert.Equal(t, "kari.nordmann@example.com", export.Contacts[0].Email)
+		require.Len(t, export.References, 1)
+		assert.Equal(t, "customer", export.References[0].EntityType)
+		assert.Equal(t, customer.ID, export.References[0].EntityID)
+
+		var mentioned bool
+		for _, activity := range export.Activities {
+			if activity.ID == note.ID {
+				mentioned = true
+			}
+		}
+		assert.True(t, mentioned)
+
+		var buf bytes.Buffer
+		require.NoError(t, service.WriteDataSubjectArchive(&buf, export))
+		archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+		require.NoError(t, err)
+		assert.Len(t, archive.File, 5)
+	})
+
+	t.Run("export by email finds the contact", func(t *testing.T) {
+		export, err := svc.ExportDataSubjectByEmail(ctx, "Kari.Nordmann@example.com")
+		require.NoError(t, err)
+		require.Len(t, export.Contacts, 1)
+		assert.Equal(t, contact.ID, export.Contacts[0].ID)
+
+		_, err = svc.ExportDataSubjectByEmail(ctx, "")
+		assert.ErrorIs(t, err, service.ErrDataSubjectRequired)
+	})
+
+	t.Run("anonymize scrubs contact and copies", func(t *testing.T) {
+		result, err := svc.Anonymize(ctx, contact.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Anonymisert kontakt", result.Contact.FullName)
+		assert.Empty(t, result.Contact.Email)
+		assert.Empty(t, result.Contact.Phone)
+		assert.False(t, result.Contact.IsActive)
+		assert.NotNil(t, result.Contact.AnonymizedAt)
+		assert.Equal(t, int64(1), result.ReferencesUpdated)
+
+		// Relationships are kept
+		var reloaded domain.Contact
+		require.NoError(t, db.First(&reloaded, "id = ?", contact.ID).Error)
+		assert.Equal(t, &customer.ID, reloaded.PrimaryCustomerID)
+
+		var reloadedCustomer domain.Customer
+		require.NoError(t, db.First(&reloadedCustomer, "id = ?", customer.ID).Error)
+		assert.Empty(t, reloadedCustomer.ContactPerson)
+		assert.Empty(t, reloadedCustomer.ContactEmail)
+
+		var reloadedNote domain.Activity
+		require.NoError(t, db.First(&reloadedNote, "id = ?", note.ID).Error)
+		assert.Equal(t, "Ringte [anonymisert] om befaring", reloadedNote.Body)
+
+		_, err = svc.Anonymize(ctx, contact.ID)
+		assert.ErrorIs(t, err, service.ErrContactAlreadyAnonymized)
+	})
+}
+
+func TestContactService_RetentionReview(t *testing.T) {
+	db := setupContactServiceTestDB(t)
+	svc := createContactService(db)
+	ctx := createContactTestContext()
+
+	stale, err := svc.Create(ctx, &domain.CreateContactRequest{FirstName: "Gammel", LastName: "Kontakt"})
+	require.NoError(t, err)
+	recent, err := svc.Create(ctx, &domain.CreateContactRequest{FirstName: "Ny", LastName: "Kontakt"})
+	require.NoError(t, err)
+
+	// Backdate the stale contact and its activities beyond the retention period
+	old := time.Now().AddDate(-4, 0, 0)
+	require.NoError(t, db.Model(&domain.Contact{}).Where("id = ?", stale.ID).UpdateColumn("updated_at", old).Error)
+	require.NoError(t, db.Model(&domain.Activity{}).Where("target_id = ?", stale.ID).UpdateColumn("occurred_at", old).Error)
+
+	flagged, pending, err := svc.FlagInactiveContacts(ctx, 3)
+	require.NoError(t, err)
+	assert.Equal(t, 1, flagged)
+	assert.Equal(t, 1, pending)
+
+	review, err := svc.ListRetentionReview(ctx, 1, 20)
+	require.NoError(t, err)
+	contacts := review.Data.([]domain.ContactDTO)
+	require.Len(t, contacts, 1)
+	assert.Equal(t, stale.ID, contacts[0].ID)
+	assert.NotNil(t, contacts[0].RetentionFlaggedAt)
+	assert.NotEqual(t, recent.ID, contacts[0].ID)
+
+	kept, err := svc.KeepAfterRetentionReview(ctx, stale.ID)
+	require.NoError(t, err)
+	assert.Nil(t, kept.RetentionFlaggedAt)
+	assert.NotNil(t, kept.RetentionReviewedAt)
+
+	// The review restarts the retention period
+	flagged, _, err = svc.FlagInactiveContacts(ctx, 3)
+	require.NoError(t, err)
+	assert.Equal(t, 0, flagged)
+}
@@ -0,0 +1,145 @@
+package service_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/straye-as/relation-api/internal/domain"
+	"github.com/straye-as/relation-api/internal/repository"
+	"github.com/straye-as/relation-api/internal/service"
+	"github.com/straye-as/relation-api/tests/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+func createCreditExposureService(db *gorm.DB) *service.CreditExposureService {
+	log := zap.NewNop()
+	return service.NewCreditExposureService(
+		repository.NewCustomerRepository(db),
+		repository.NewUserRoleRepository(db, log),
+		repository.NewNotificationRepository(db),
+		repository.NewActivityRepository(db),
+		service.NewCompanyServiceWithRepo(repository.NewCompanyRepository(db), repository.NewUserRepository(db), log),
+		log,
+	)
+}
+
+// createExposureOffer creates an offer for the customer in the given phase
+func createExposureOffer(t *testing.T, db *gorm.DB, customer *domain.CustomerDTO, phase domain.OfferPhase, value, invoiced float64, probability int) *domain.Offer {
+	offer := &domain.Offer{
+		Title:        "Exposure offer",
+		CustomerID:   &customer.ID,
+		CustomerName: customer.Name,
+		CompanyID:    domain.CompanyTak,
+		Phase:        phase,
+		Status:       domain.OfferStatusActive,
+		Probability:  probability,
+		Value:        value,
+		Invoiced:     invoiced,
+		OfferNumber:  fmt.Sprintf("CREDIT-%d", time.Now().UnixNano()),
+	}
+	require.NoError(t, db.Create(offer).Error)
+	return offer
+}
+
+func setCreditLimitEnforcement(t *testing.T, db *gorm.DB, enforcement domain.CreditLimitEnforcement) {
+	require.NoError(t, db.Model(&domain.Company{}).
+		Where("id = ?", domain.CompanyTak).
+		Update("credit_limit_enforcement", enforcement).Error)
+}
+
+func TestCreditExposureService_GetExposure(t *testing.T) {
+	db := setupCustomerServiceTestDB(t)
+	defer testutil.CleanupTestData(t, db)
+	customerSvc := createCustomerService(db)
+	exposureSvc := createCreditExposureService(db)
+	ctx := createCustomerTestContext()
+
+	limit := 500_000.0
+	customer, err := customerSvc.Create(ctx, &domain.CreateCustomerRequest{
+		Name:        "Kreditt Eksponering AS",
+		OrgNumber:   testutil.ValidOrgNumber(31400100),
+		Country:     "Norway",
+		CreditLimit: &limit,
+	})
+	require.NoError(t, err)
+
+	// Order reserve is value minus invoiced; sent offers count by probability
+	createExposureOffer(t, db, customer, domain.OfferPhaseOrder, 300_000, 100_000, 100)
+	createExposureOffer(t, db, customer, domain.OfferPhaseSent, 100_000, 0, 50)
+	createExposureOffer(t, db, customer, domain.OfferPhaseDraft, 900_000, 0, 50)
+
+	exposure, err := exposureSvc.GetExposure(ctx, customer.ID)
+	require.NoError(t, err)
+
+	assert.Equal(t, 200_000.0, exposure.OpenOrderReserve)
+	assert.Equal(t, 50_000.0, exposure.WeightedSentValue)
+	assert.Equal(t, 250_000.0, exposure.Exposure)
+	assert.Equal(t, 1, exposure.OpenOrders)
+	assert.Equal(t, 1, exposure.SentOffers)
+	assert.Nil(t, exposure.UnpaidAmount)
+	require.NotNil(t, exposure.Available)
+	assert.Equal(t, 250_000.0, *exposure.Available)
+	require.NotNil(t, exposure.UtilizationPct)
+	assert.Equal(t, 50.0, *exposure.UtilizationPct)
+	assert.False(t, exposure.OverLimit)
+}
+
+func TestCreditExposureService_CheckOrder(t *testing.T) {
+	db := setupCustomerServiceTestDB(t)
+	defer testutil.CleanupTestData(t, db)
+	customerSvc := createCustomerService(db)
+	exposureSvc := createCreditExposureService(db)
+	offerSvc, _ := setupOfferTestService(t, db)
+	offerSvc.SetCreditExposureService(exposureSvc)
+	ctx := createCustomerTestContext()
+	defer setCreditLimitEnforcement(t, db, domain.CreditLimitEnforcementWarn)
+
+	limit := 500_000.0
+	customer, err := customerSvc.Create(ctx, &domain.CreateCustomerRequest{
+		Name:        "Kreditt Kontroll AS",
+		OrgNumber:   testutil.ValidOrgNumber(31400110),
+		Country:     "Norway",
+		CreditLimit: &limit,
+	})
+	require.NoError(t, err)
+	createExposureOffer(t, db, customer, domain.OfferPhaseOrder, 300_000, 100_000, 100)
+
+	t.Run("within limit", func(t *testing.T) {
+		offer := createExposureOffer(t, db, customer, domain.OfferPhaseSent, 100_000, 0, 50)
+
+		result, err := offerSvc.AcceptOrder(ctx, offer.ID, &domain.AcceptOrderRequest{})
+		require.NoError(t, err)
+		require.NotNil(t, result.CreditCheck)
+		assert.False(t, result.CreditCheck.Exceeded)
+		assert.Equal(t, 300_000.0, result.CreditCheck.ExposureAfter)
+	})
+
+	t.Run("block stops the order", func(t *testing.T) {
+		setCreditLimitEnforcement(t, db, domain.CreditLimitEnforcementBlock)
+		offer := createExposureOffer(t, db, customer, domain.OfferPhaseSent, 400_000, 0, 50)
+
+		_, err := offerSvc.AcceptOrder(ctx, offer.ID, &domain.AcceptOrderRequest{})
+		assert.ErrorIs(t, err, service.ErrCreditLimitExceeded)
+
+		var reloaded domain.Offer
+		require.NoError(t, db.First(&reloaded, "id = ?", offer.ID).Error)
+		assert.Equal(t, domain.OfferPhaseSent, reloaded.Phase)
+	})
+
+	t.Run("warn accepts the order", func(t *testing.T) {
+		setCreditLimitEnforcement(t, db, domain.CreditLimitEnforcementWarn)
+		offer := createExposureOffer(t, db, customer, domain.OfferPhaseSent, 400_000, 0, 50)
+
+		result, err := offerSvc.AcceptOrder(ctx, offer.ID, &domain.AcceptOrderRequest{})
+		require.NoError(t, err)
+		require.NotNil(t, result.CreditCheck)
+		assert.True(t, result.CreditCheck.Exceeded)
+		assert.Equal(t, domain.CreditLimitEnforcementWarn, result.CreditCheck.Enforcement)
+		assert.Equal(t, 900_000.0, result.CreditCheck.ExposureAfter) // Includes the blocked offer, still sent
+		assert.Equal(t, domain.OfferPhaseOrder, result.Offer.Phase)
+	})
+}
@@ -0,0 +1,101 @@
+package service_test
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/straye-as/relation-api/internal/domain"
+	"github.com/straye-as/relation-api/internal/repository"
+	"github.com/straye-as/relation-api/internal/service"
+	"github.com/straye-as/relation-api/tests/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCustomerService_CustomerGroup(t *testing.T) {
+	db := setupCustomerServiceTestDB(t)
+	defer testutil.CleanupTestData(t, db)
+	svc := createCustomerService(db)
+	ctx := createCustomerTestContext()
+
+	createCustomer := func(name string, seed int64) *domain.CustomerDTO {
+		customer, err := svc.Create(ctx, &domain.CreateCustomerRequest{
+			Name:      name,
+			OrgNumber: testutil.ValidOrgNumber(seed),
+			Country:   "Norway",
+		})
+		require.NoError(t, err)
+		return customer
+	}
+
+	// Konsern with a holding company, a property company and a building company below it
+	holding := createCustomer("Eiendom Holding AS", 31400200)
+	property := createCustomer("Eiendom Drift AS", 31400210)
+	building := createCustomer("Storgata 1 AS", 31400220)
+
+	updated, err := svc.UpdateParent(ctx, property.ID, &holding.ID)
+	require.NoError(t, err)
+	assert.Equal(t, &holding.ID, updated.ParentCustomerID)
+	_, err = svc.UpdateParent(ctx, building.ID, &property.ID)
+	require.NoError(t, err)
+
+	t.Run("rejects cycles", func(t *testing.T) {
+		_, err := svc.UpdateParent(ctx, holding.ID, &building.ID)
+		assert.ErrorIs(t, err, service.ErrCustomerGroupCycle)
+
+		_, err = svc.UpdateParent(ctx, holding.ID, &holding.ID)
+		assert.ErrorIs(t, err, service.ErrCustomerGroupCycle)
+	})
+
+	t.Run("rejects unknown parent", func(t *testing.T) {
+		unknown := uuid.New()
+		_, err := svc.UpdateParent(ctx, holding.ID, &unknown)
+		assert.ErrorIs(t, err, service.ErrParentCustomerNotFound)
+	})
+
+	t.Run("rolls up group statistics", func(t *testing.T) {
+		createExposureOffer(t, db, holding, domain.OfferPhaseOrder, 100_000, 0, 100)
+		createExposureOffer(t, db, building, domain.OfferPhaseOrder, 50_000, 0, 100)
+		createExposureOffer(t, db, building, domain.OfferPhaseCompleted, 25_000, 25_000, 100)
+
+		group, err := svc.GetGroup(ctx, holding.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 3, group.MemberCount)
+		assert.Empty(t, group.Ancestors)
+		assert.Equal(t, holding.ID, group.Members[0].ID)
+		assert.Equal(t, 0, group.Members[0].Depth)
+		assert.Equal(t, building.ID, group.Members[2].ID)
+		assert.Equal(t, 2, group.Members[2].Depth)
+		assert.Equal(t, 150_000.0, group.Stats.TotalValueActive)
+		assert.Equal(t, 175_000.0, group.Stats.TotalValueWon)
+		assert.Equal(t, 3, group.Stats.TotalOffers)
+
+		// A subsidiary's group only covers itself and its own subsidiaries
+		group, err = svc.GetGroup(ctx, property.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 2, group.MemberCount)
+		require.Len(t, group.Ancestors, 1)
+		assert.Equal(t, holding.ID, group.Ancestors[0].ID)
+		assert.Equal(t, 50_000.0, group.Stats.TotalValueActive)
+	})
+
+	t.Run("filters offers by customer group", func(t *testing.T) {
+		offers, total, err := repository.NewOfferRepository(db).ListWithFilters(ctx, 1, 50,
+			&repository.OfferFilters{CustomerGroupID: &property.ID}, repository.DefaultSortConfig())
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), total)
+		for _, offer := range offers {
+			assert.Equal(t, building.ID, *offer.CustomerID)
+		}
+	})
+
+	t.Run("removes customer from group", func(t *testing.T) {
+		updated, err := svc.UpdateParent(ctx, building.ID, nil)
+		require.NoError(t, err)
+		assert.Nil(t, updated.ParentCustomerID)
+
+		group, err := svc.GetGroup(ctx, holding.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 2, group.MemberCount)
+	})
+}
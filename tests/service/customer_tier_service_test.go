@@ -0,0 +1,245 @@
+package service_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/straye-as/relation-api/internal/domain"
+	"github.com/straye-as/relation-api/internal/repository"
+	"github.com/straye-as/relation-api/internal/service"
+	"github.com/straye-as/relation-api/tests/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+func createCustomerTierService(db *gorm.DB) *service.CustomerTierService {
+	return service.NewCustomerTierService(
+		repository.NewCustomerTierRuleRepository(db),
+		repository.NewCustomerRepository(db),
+		repository.NewActivityRepository(db),
+		zap.NewNop(),
+	)
+}
+
+// createWonOffer creates an offer in the order phase, sent at the given time
+func createWonOffer(t *testing.T, db *gorm.DB, customer *domain.CustomerDTO, value float64, sentDate time.Time) {
+	offer := &domain.Offer{
+		Title:        "Won offer",
+		CustomerID:   &customer.ID,
+		CustomerName: customer.Name,
+		CompanyID:    domain.CompanyTak,
+		Phase:        domain.OfferPhaseOrder,
+		Status:       domain.OfferStatusActive,
+		Probability:  100,
+		Value:        value,
+		SentDate:     &sentDate,
+		OfferNumber:  fmt.Sprintf("TIER-%d", time.Now().UnixNano()),
+	}
+	require.NoError(t, db.Create(offer).Error)
+}
+
+func TestCustomerTierService_RecalculateAll(t *testing.T) {
+	db := setupCustomerServiceTestDB(t)
+	defer testutil.CleanupTestData(t, db)
+	customerSvc := createCustomerService(db)
+	tierSvc := createCustomerTierService(db)
+	ctx := createCustomerTestContext()
+
+	tak := domain.CompanyTak
+	customerWithCompany := func(name string, seed int64) *domain.CustomerDTO {
+		customer, err := customerSvc.Create(ctx, &domain.CreateCustomerRequest{
+			Name:      name,
+			OrgNumber: testutil.ValidOrgNumber(seed),
+			Country:   "Norway",
+		})
+		require.NoError(t, err)
+		customer, err = customerSvc.UpdateCompanyID(ctx, customer.ID, &tak)
+		require.NoError(t, err)
+		return customer
+	}
+
+	// Tak counts orders in the last 2 years: 2 orders is silver, 3 gold, 5 platinum
+	_, err := tierSvc.UpdateRule(ctx, domain.CompanyTak, &domain.UpdateCustomerTierRuleRequest{
+		Metric:            domain.CustomerTierMetricOrderCount,
+		WindowYears:       2,
+		SilverThreshold:   2,
+		GoldThreshold:     3,
+		PlatinumThreshold: 5,
+	})
+	require.NoError(t, err)
+	defer func() { _ = tierSvc.DeleteRule(ctx, domain.CompanyTak) }()
+
+	now := time.Now()
+	upgraded := customerWithCompany("Tier Oppgradering AS", 31300100)
+	for i := 0; i < 3; i++ {
+		createWonOffer(t, db, upgraded, 10_000, now.AddDate(0, -i, 0))
+	}
+	// Old orders fall outside the rolling window
+	createWonOffer(t, db, upgraded, 10_000, now.AddDate(-3, 0, 0))
+
+	downgraded := customerWithCompany("Tier Nedgradering AS", 31300110)
+	require.NoError(t, db.Model(&domain.Customer{}).Where("id = ?", downgraded.ID).Update("tier", domain.CustomerTierGold).Error)
+
+	locked := customerWithCompany("Tier Låst AS", 31300120)
+	_, err = customerSvc.UpdateTier(ctx, locked.ID, domain.CustomerTierPlatinum)
+	require.NoError(t, err)
+
+	result, err := tierSvc.RecalculateAll(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Upgraded)
+	assert.Equal(t, 1, result.Downgraded)
+	assert.Equal(t, 1, result.SkippedLocked)
+	assert.Equal(t, 0, result.Errors)
+
+	got, err := customerSvc.GetByID(ctx, upgraded.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.CustomerTierGold, got.Tier, "3 orders within 2 years")
+	assert.NotNil(t, got.TierCalculatedAt)
+
+	got, err = customerSvc.GetByID(ctx, downgraded.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.CustomerTierBronze, got.Tier)
+
+	got, err = customerSvc.GetByID(ctx, locked.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.CustomerTierPlatinum, got.Tier, "locked tier is left alone")
+	assert.True(t, got.TierLocked)
+
+	history, err := tierSvc.GetHistory(ctx, upgraded.ID, 1, 20)
+	require.NoError(t, err)
+	entries := history.Data.([]domain.CustomerTierHistoryDTO)
+	require.Len(t, entries, 1)
+	assert.Equal(t, domain.CustomerTierBronze, entries[0].PreviousTier)
+	assert.Equal(t, domain.CustomerTierGold, entries[0].NewTier)
+	assert.Equal(t, domain.CustomerTierChangeCalculated, entries[0].Source)
+	require.NotNil(t, entries[0].MetricValue)
+	assert.Equal(t, 3.0, *entries[0].MetricValue)
+
+	var activities int64
+	require.NoError(t, db.Model(&domain.Activity{}).
+		Where("target_id = ? AND title = ?", upgraded.ID, "Kundenivå beregnet").
+		Count(&activities).Error)
+	assert.Equal(t, int64(1), activities)
+
+	// A second run changes nothing
+	result, err = tierSvc.RecalculateAll(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, result.Upgraded+result.Downgraded)
+}
+
+func TestCustomerTierService_LockAndUnlock(t *testing.T) {
+	db := setupCustomerServiceTestDB(t)
+	defer testutil.CleanupTestData(t, db)
+	customerSvc := createCustomerService(db)
+	tierSvc := createCustomerTierService(db)
+	ctx := createCustomerTestContext()
+
+	customer, err := customerSvc.Create(ctx, &domain.CreateCustomerRequest{
+		Name:      "Tier Overstyring AS",
+		OrgNumber: testutil.ValidOrgNumber(31300130),
+		Country:   "Norway",
+	})
+	require.NoError(t, err)
+	assert.False(t, customer.TierLocked)
+
+	updated, err := customerSvc.UpdateTier(ctx, customer.ID, domain.CustomerTierGold)
+	require.NoError(t, err)
+	assert.True(t, updated.TierLocked)
+	assert.Equal(t, "Test User", updated.TierLockedByName)
+
+	_, err = tierSvc.RecalculateCustomer(ctx, customer.ID)
+	assert.ErrorIs(t, err, service.ErrCustomerTierLocked)
+
+	// Unlocking recalculates from the default rule; no won offers means bronze
+	unlocked, err := tierSvc.UnlockTier(ctx, customer.ID)
+	require.NoError(t, err)
+	assert.False(t, unlocked.TierLocked)
+	assert.Equal(t, domain.CustomerTierBronze, unlocked.Tier)
+
+	history, err := tierSvc.GetHistory(ctx, customer.ID, 1, 20)
+	require.NoError(t, err)
+	entries := history.Data.([]domain.CustomerTierHistoryDTO)
+	require.Len(t, entries, 2)
+	assert.Equal(t, domain.CustomerTierChangeCalculated, entries[0].Source)
+	assert.Equal(t, domain.CustomerTierChangeManual, entries[1].Source)
+	assert.Equal(t, domain.CustomerTierGold, entries[1].NewTier)
+}
+
+func TestCustomerTierService_ExistingHandSetTier(t *testing.T) {
+	db := setupCustomerServiceTestDB(t)
+	defer testutil.CleanupTestData(t, db)
+	customerSvc := createCustomerService(db)
+	tierSvc := createCustomerTierService(db)
+	ctx := createCustomerTestContext()
+
+	// Tiers set by hand before tier rules existed are neither locked nor calculated
+	handSet, err := customerSvc.Create(ctx, &domain.CreateCustomerRequest{
+		Name:      "Tier Eksisterende AS",
+		OrgNumber: testutil.ValidOrgNumber(31300140),
+		Country:   "Norway",
+	})
+	require.NoError(t, err)
+	require.NoError(t, db.Model(&domain.Customer{}).Where("id = ?", handSet.ID).Update("tier", domain.CustomerTierGold).Error)
+
+	matching, err := customerSvc.Create(ctx, &domain.CreateCustomerRequest{
+		Name:      "Tier Uendret AS",
+		OrgNumber: testutil.ValidOrgNumber(31300150),
+		Country:   "Norway",
+	})
+	require.NoError(t, err)
+
+	// Tiers below the calculated one were never upgraded and are not hand-set
+	stale, err := customerSvc.Create(ctx, &domain.CreateCustomerRequest{
+		Name:      "Tier Utdatert AS",
+		OrgNumber: testutil.ValidOrgNumber(31300160),
+		Country:   "Norway",
+	})
+	require.NoError(t, err)
+	createWonOffer(t, db, stale, 250_000, time.Now())
+
+	testutil.ApplyMigrationUp(t, db, "00092_lock_existing_customer_tiers.sql")
+
+	result, err := tierSvc.RecalculateAll(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, result.Downgraded)
+	assert.Equal(t, 1, result.Upgraded)
+
+	got, err := customerSvc.GetByID(ctx, handSet.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.CustomerTierGold, got.Tier, "hand-set tier survives the first recalculation")
+	assert.True(t, got.TierLocked)
+	assert.Equal(t, "System", got.TierLockedByName)
+
+	got, err = customerSvc.GetByID(ctx, matching.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.CustomerTierBronze, got.Tier)
+	assert.False(t, got.TierLocked, "tiers the default rule agrees with are not locked")
+
+	got, err = customerSvc.GetByID(ctx, stale.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.CustomerTierSilver, got.Tier, "stale tiers below the calculated one are upgraded")
+	assert.False(t, got.TierLocked)
+}
+
+func TestCustomerTierService_UpdateRuleValidation(t *testing.T) {
+	db := setupCustomerServiceTestDB(t)
+	tierSvc := createCustomerTierService(db)
+	ctx := context.Background()
+
+	_, err := tierSvc.UpdateRule(ctx, domain.CompanyID("unknown"), &domain.UpdateCustomerTierRuleRequest{
+		Metric: domain.CustomerTierMetricWonValue, SilverThreshold: 1, GoldThreshold: 2, PlatinumThreshold: 3,
+	})
+	assert.ErrorIs(t, err, service.ErrInvalidCustomerTierRule)
+
+	_, err = tierSvc.UpdateRule(ctx, domain.CompanyTak, &domain.UpdateCustomerTierRuleRequest{
+		Metric: domain.CustomerTierMetricWonValue, SilverThreshold: 5, GoldThreshold: 2, PlatinumThreshold: 3,
+	})
+	assert.ErrorIs(t, err, service.ErrInvalidCustomerTierRule)
+
+	err = tierSvc.DeleteRule(ctx, domain.CompanyAll)
+	assert.ErrorIs(t, err, service.ErrDefaultCustomerTierRuleRequired)
+}
@@ -0,0 +1,104 @@
+package service_test
+
+import (
+	"testing"
+
+	"github.com/straye-as/relation-api/internal/domain"
+	"github.com/straye-as/relation-api/internal/repository"
+	"github.com/straye-as/relation-api/internal/service"
+	"github.com/straye-as/relation-api/tests/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+func createDashboardService(db *gorm.DB) *service.DashboardService {
+	return service.NewDashboardService(
+		repository.NewCustomerRepository(db),
+		repository.NewProjectRepository(db),
+		repository.NewOfferRepository(db),
+		repository.NewActivityRepository(db),
+		repository.NewNotificationRepository(db),
+		repository.NewSupplierRepository(db),
+		zap.NewNop(),
+	)
+}
+
+func TestDashboardService_IntercompanyViews(t *testing.T) {
+	db := setupCustomerServiceTestDB(t)
+	defer testutil.CleanupTestData(t, db)
+	customerSvc := createCustomerService(db)
+	dashboardSvc := createDashboardService(db)
+	ctx := createCustomerTestContext()
+
+	external, err := customerSvc.Create(ctx, &domain.CreateCustomerRequest{
+		Name:      "Ekstern Kunde AS",
+		OrgNumber: testutil.ValidOrgNumber(31400300),
+		Country:   "Norway",
+	})
+	require.NoError(t, err)
+	internal, err := customerSvc.Create(ctx, &domain.CreateCustomerRequest{
+		Name:       "Straye Intern AS",
+		OrgNumber:  testutil.ValidOrgNumber(31400310),
+		Country:    "Norway",
+		IsInternal: true,
+	})
+	require.NoError(t, err)
+
+	createExposureOffer(t, db, external, domain.OfferPhaseOrder, 300_000, 100_000, 100)
+	createExposureOffer(t, db, internal, domain.OfferPhaseOrder, 200_000, 50_000, 100)
+	createExposureOffer(t, db, internal, domain.OfferPhaseSent, 80_000, 0, 50)
+
+	t.Run("all counts internal offers", func(t *testing.T) {
+		metrics, err := dashboardSvc.GetMetrics(ctx, domain.TimeRangeAllTime, nil, domain.IntercompanyViewAll)
+		require.NoError(t, err)
+		assert.Equal(t, domain.IntercompanyViewAll, metrics.View)
+		assert.Equal(t, 2, metrics.ActiveOrderCount)
+		assert.Equal(t, 500_000.0, metrics.OrderValue)
+		assert.Equal(t, 80_000.0, metrics.OfferReserve)
+		assert.Nil(t, metrics.Intercompany)
+	})
+
+	t.Run("consolidated eliminates internal offers", func(t *testing.T) {
+		metrics, err := dashboardSvc.GetMetrics(ctx, domain.TimeRangeAllTime, nil, domain.IntercompanyViewConsolidated)
+		require.NoError(t, err)
+		assert.Equal(t, 1, metrics.ActiveOrderCount)
+		assert.Equal(t, 300_000.0, metrics.OrderValue)
+		assert.Equal(t, 200_000.0, metrics.OrderReserve)
+		assert.Equal(t, 0.0, metrics.OfferReserve)
+		assert.Equal(t, 1, metrics.WinRateMetrics.WonCount)
+		for _, customer := range metrics.TopCustomers {
+			assert.NotEqual(t, internal.ID, customer.ID)
+		}
+	})
+
+	t.Run("split reports internal and external separately", func(t *testing.T) {
+		metrics, err := dashboardSvc.GetMetrics(ctx, domain.TimeRangeAllTime, nil, domain.IntercompanyViewSplit)
+		require.NoError(t, err)
+		require.NotNil(t, metrics.Intercompany)
+		assert.Equal(t, 500_000.0, metrics.OrderValue)
+
+		assert.Equal(t, 1, metrics.Intercompany.Internal.ActiveOrderCount)
+		assert.Equal(t, 200_000.0, metrics.Intercompany.Internal.OrderValue)
+		assert.Equal(t, 150_000.0, metrics.Intercompany.Internal.OrderReserve)
+		assert.Equal(t, 80_000.0, metrics.Intercompany.Internal.OfferReserve)
+		assert.Equal(t, 40_000.0, metrics.Intercompany.Internal.WeightedOfferReserve)
+
+		assert.Equal(t, 1, metrics.Intercompany.External.ActiveOrderCount)
+		assert.Equal(t, 300_000.0, metrics.Intercompany.External.OrderValue)
+		assert.Equal(t, 0.0, metrics.Intercompany.External.OfferReserve)
+	})
+
+	t.Run("matrix lists sales to internal customers", func(t *testing.T) {
+		matrix, err := dashboardSvc.GetIntercompanyMatrix(ctx, domain.TimeRangeAllTime, nil)
+		require.NoError(t, err)
+		require.Len(t, matrix.Cells, 1)
+		assert.Equal(t, domain.CompanyTak, matrix.Cells[0].SellerCompanyID)
+		assert.Equal(t, internal.ID, matrix.Cells[0].BuyerCustomerID)
+		assert.Equal(t, 1, matrix.Cells[0].WonCount)
+		assert.Equal(t, 200_000.0, matrix.Cells[0].WonValue)
+		assert.Equal(t, 150_000.0, matrix.Cells[0].OrderReserve)
+		assert.Equal(t, 200_000.0, matrix.TotalWonValue)
+	})
+}
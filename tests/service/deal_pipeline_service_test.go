@@ -0,0 +1,165 @@
+package service_test
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/straye-as/relation-api/internal/auth"
+	"github.com/straye-as/relation-api/internal/domain"
+	"github.com/straye-as/relation-api/internal/repository"
+	"github.com/straye-as/relation-api/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// createTakPipeline creates a non-default pipeline with a site inspection stage that requires a value
+func createTakPipeline(t *testing.T, db *gorm.DB, svc *service.DealPipelineService) *domain.DealPipelineDTO {
+	ctx := createTestContext()
+	pipeline, err := svc.Create(ctx, &domain.CreateDealPipelineRequest{
+		CompanyID: domain.CompanyTak,
+		Name:      "Tak befaring " + uuid.New().String()[:8],
+		Stages: []domain.DealPipelineStageInput{
+			{Key: "lead", Name: "Lead", Probability: 10},
+			{Key: "befaring", Name: "Befaring", Probability: 30, RequiredFields: []string{"value", "expectedCloseDate"}},
+			{Key: "proposal", Name: "Tilbud", Probability: 60},
+		},
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		db.Exec("DELETE FROM deal_stage_history WHERE deal_id IN (SELECT id FROM deals WHERE pipeline_id = ?)", pipeline.ID)
+		db.Exec("DELETE FROM deals WHERE pipeline_id = ?", pipeline.ID)
+		db.Exec("DELETE FROM deal_pipelines WHERE id = ?", pipeline.ID)
+	})
+	return pipeline
+}
+
+func TestDealPipelineService_Create(t *testing.T) {
+	db := setupDealServiceTestDB(t)
+	svc := service.NewDealPipelineService(repository.NewDealPipelineRepository(db), zap.NewNop())
+	ctx := createTestContext()
+
+	t.Run("adds closing stages and default transitions", func(t *testing.T) {
+		pipeline := createTakPipeline(t, db, svc)
+
+		assert.False(t, pipeline.IsDefault)
+		require.Len(t, pipeline.Stages, 5)
+		keys := make([]domain.DealStage, len(pipeline.Stages))
+		for i, stage := range pipeline.Stages {
+			keys[i] = stage.Key
+			assert.Equal(t, i+1, stage.SortOrder)
+		}
+		assert.Equal(t, []domain.DealStage{"lead", "befaring", "proposal", "won", "lost"}, keys)
+
+		assert.Equal(t, []domain.DealStage{"befaring", "lost"}, pipeline.Stages[0].AllowedTransitions)
+		assert.Equal(t, []domain.DealStage{"proposal", "lead", "lost"}, pipeline.Stages[1].AllowedTransitions)
+		assert.Equal(t, []domain.DealStage{"won", "befaring", "lost"}, pipeline.Stages[2].AllowedTransitions)
+		assert.Empty(t, pipeline.Stages[3].AllowedTransitions)
+		assert.Equal(t, []domain.DealStage{"lead"}, pipeline.Stages[4].AllowedTransitions)
+		assert.Equal(t, domain.DealStageKindWon, pipeline.Stages[3].Kind)
+		assert.Equal(t, 100, pipeline.Stages[3].Probability)
+	})
+
+	t.Run("rejects invalid definitions", func(t *testing.T) {
+		cases := map[string][]domain.DealPipelineStageInput{
+			"duplicate key":      {{Key: "lead", Name: "Lead"}, {Key: "lead", Name: "Lead 2"}},
+			"invalid key":        {{Key: "Site Visit", Name: "Befaring"}},
+			"no open stage":      {{Key: "won", Name: "Vunnet"}},
+			"unknown field":      {{Key: "lead", Name: "Lead", RequiredFields: []string{"budget"}}},
+			"unknown transition": {{Key: "lead", Name: "Lead", AllowedTransitions: []domain.DealStage{"befaring"}}},
+		}
+		for name, stages := range cases {
+			_, err := svc.Create(ctx, &domain.CreateDealPipelineRequest{
+				CompanyID: domain.CompanyTak,
+				Name:      name,
+				Stages:    stages,
+			})
+			assert.ErrorIs(t, err, service.ErrInvalidDealPipeline, name)
+		}
+	})
+
+	t.Run("requires manager role", func(t *testing.T) {
+		userCtx := &auth.UserContext{
+			UserID:    uuid.New(),
+			Roles:     []domain.UserRoleType{domain.RoleMarket},
+			CompanyID: domain.CompanyTak,
+		}
+		_, err := svc.Create(auth.WithUserContext(ctx, userCtx), &domain.CreateDealPipelineRequest{
+			CompanyID: domain.CompanyTak,
+			Name:      "Tak",
+			Stages:    []domain.DealPipelineStageInput{{Key: "lead", Name: "Lead"}},
+		})
+		assert.ErrorIs(t, err, service.ErrForbidden)
+	})
+}
+
+func TestDealService_CustomPipeline(t *testing.T) {
+	db := setupDealServiceTestDB(t)
+	pipelineRepo := repository.NewDealPipelineRepository(db)
+	pipelineSvc := service.NewDealPipelineService(pipelineRepo, zap.NewNop())
+	svc := createDealService(t, db)
+	svc.SetPipelineRepository(pipelineRepo)
+	customer := createDealServiceTestCustomer(t, db)
+	ctx := createTestContext()
+	userCtx, _ := auth.FromContext(ctx)
+
+	pipeline := createTakPipeline(t, db, pipelineSvc)
+
+	deal, err := svc.Create(ctx, &domain.CreateDealRequest{
+		Title:      "Takrehabilitering",
+		CustomerID: customer.ID,
+		CompanyID:  domain.CompanyTak,
+		OwnerID:    userCtx.UserID.String(),
+		PipelineID: &pipeline.ID,
+	})
+	require.NoError(t, err)
+	require.NotNil(t, deal.PipelineID)
+	assert.Equal(t, pipeline.ID, *deal.PipelineID)
+	assert.Equal(t, domain.DealStage("lead"), deal.Stage)
+
+	t.Run("stage outside the pipeline is rejected", func(t *testing.T) {
+		_, err := svc.AdvanceStage(ctx, deal.ID, &domain.UpdateDealStageRequest{Stage: domain.DealStageQualified})
+		assert.ErrorIs(t, err, service.ErrInvalidDealStage)
+	})
+
+	t.Run("transition not allowed", func(t *testing.T) {
+		_, err := svc.AdvanceStage(ctx, deal.ID, &domain.UpdateDealStageRequest{Stage: domain.DealStageProposal})
+		assert.ErrorIs(t, err, service.ErrInvalidDealStageTransition)
+	})
+
+	t.Run("required fields are enforced", func(t *testing.T) {
+		_, err := svc.AdvanceStage(ctx, deal.ID, &domain.UpdateDealStageRequest{Stage: "befaring"})
+		assert.ErrorIs(t, err, service.ErrDealStageRequirementsNotMet)
+
+		require.NoError(t, db.Model(&domain.Deal{}).Where("id = ?", deal.ID).
+			Updates(map[string]interface{}{"value": 250000, "expected_close_date": "2030-01-01"}).Error)
+
+		advanced, err := svc.AdvanceStage(ctx, deal.ID, &domain.UpdateDealStageRequest{Stage: "befaring"})
+		require.NoError(t, err)
+		assert.Equal(t, domain.DealStage("befaring"), advanced.Stage)
+		assert.Equal(t, 30, advanced.Probability)
+	})
+
+	t.Run("overview includes empty stages", func(t *testing.T) {
+		overview, err := svc.GetPipelineOverviewByPipeline(ctx, pipeline.ID)
+		require.NoError(t, err)
+		assert.Len(t, overview, 3)
+		assert.Empty(t, overview["lead"])
+		assert.Len(t, overview["befaring"], 1)
+		assert.Empty(t, overview["proposal"])
+	})
+
+	t.Run("stages in use cannot be removed", func(t *testing.T) {
+		_, err := pipelineSvc.Update(ctx, pipeline.ID, &domain.UpdateDealPipelineRequest{
+			Name:   pipeline.Name,
+			Stages: []domain.DealPipelineStageInput{{Key: "lead", Name: "Lead"}, {Key: "proposal", Name: "Tilbud"}},
+		})
+		assert.ErrorIs(t, err, service.ErrInvalidDealPipeline)
+	})
+
+	t.Run("pipeline with deals cannot be deleted", func(t *testing.T) {
+		err := pipelineSvc.Delete(ctx, pipeline.ID)
+		assert.ErrorIs(t, err, service.ErrDealPipelineInUse)
+	})
+}
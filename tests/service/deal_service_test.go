@@ -404,6 +404,8 @@ func TestDealService_LoseDeal(t *testing.T) {
 		assert.NotNil(t, lostDeal)
 		assert.Equal(t, domain.DealStageLost, lostDeal.Stage)
 		assert.Equal(t, 0, lostDeal.Probability)
+		require.NotNil(t, lostDeal.ProbabilityAtClose, "entered probability should be kept for forecast calibration")
+		assert.Equal(t, deal.Probability, *lostDeal.ProbabilityAtClose)
 		assert.Equal(t, loseReq.Notes, lostDeal.LostReason)
 		assert.NotNil(t, lostDeal.LossReasonCategory)
 		assert.Equal(t, domain.LossReasonCompetitor, *lostDeal.LossReasonCategory)
@@ -0,0 +1,152 @@
+package service_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/straye-as/relation-api/internal/config"
+	"github.com/straye-as/relation-api/internal/domain"
+	"github.com/straye-as/relation-api/internal/repository"
+	"github.com/straye-as/relation-api/internal/service"
+	"github.com/straye-as/relation-api/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+func createEmailIngestionService(t *testing.T, db *gorm.DB) *service.EmailIngestionService {
+	log := zap.NewNop()
+	offerRepo := repository.NewOfferRepository(db)
+	customerRepo := repository.NewCustomerRepository(db)
+	supplierRepo := repository.NewSupplierRepository(db)
+	activityRepo := repository.NewActivityRepository(db)
+
+	fileStorage, err := storage.NewStorage(&config.StorageConfig{Mode: "disk", LocalBasePath: t.TempDir()}, log)
+	require.NoError(t, err)
+	fileService := service.NewFileService(
+		repository.NewFileRepository(db),
+		offerRepo,
+		customerRepo,
+		repository.NewProjectRepository(db),
+		supplierRepo,
+		activityRepo,
+		fileStorage,
+		log,
+	)
+
+	return service.NewEmailIngestionService(
+		repository.NewIngestedEmailRepository(db),
+		offerRepo,
+		repository.NewContactRepository(db),
+		customerRepo,
+		supplierRepo,
+		activityRepo,
+		fileService,
+		log,
+	)
+}
+
+func testEmail(messageID, from, subject string) []byte {
+	return []byte(strings.ReplaceAll(`Message-ID: <`+messageID+`>
+Date: Tue, 14 Jan 2025 09:30:00 +0100
+From: `+from+`
+To: selger@straye.no
+Subject: `+subject+`
+Content-Type: multipart/mixed; boundary="b"
+
+--b
+Content-Type: text/plain; charset=utf-8
+
+Hei, se vedlagt.
+--b
+Content-Type: application/pdf
+Content-Disposition: attachment; filename="tilbud.pdf"
+Content-Transfer-Encoding: base64
+
+JVBERi0xLjQK
+--b--
+`, "\n", "\r\n"))
+}
+
+func TestEmailIngestionService_Ingest(t *testing.T) {
+	db := setupActivityServiceTestDB(t)
+	svc := createEmailIngestionService(t, db)
+	ctx := createActivityTestContext()
+	customer := createActivityServiceTestCustomer(t, db)
+
+	contactEmail := "kari." + uuid.New().String()[:8] + "@example.com"
+	require.NoError(t, db.Create(&domain.Contact{
+		FirstName:         "Kari",
+		LastName:          "Nordmann",
+		Email:             contactEmail,
+		PrimaryCustomerID: &customer.ID,
+	}).Error)
+
+	t.Run("files email on the customer of a contact", func(t *testing.T) {
+		messageID := uuid.New().String() + "@example.com"
+		result, err := svc.Ingest(ctx, testEmail(messageID, "Kari Nordmann <"+strings.ToUpper(contactEmail)+">", "Tegninger"), domain.EmailSourceUpload)
+		require.NoError(t, err)
+
+		assert.Equal(t, domain.EmailIngestionMatched, result.Email.Status)
+		assert.Equal(t, domain.EmailMatchContact, result.Email.MatchedBy)
+		assert.Equal(t, domain.ActivityTargetCustomer, result.Email.TargetType)
+		require.NotNil(t, result.Email.TargetID)
+		assert.Equal(t, customer.ID, *result.Email.TargetID)
+		require.Len(t, result.Files, 1)
+		assert.Equal(t, "tilbud.pdf", result.Files[0].Filename)
+
+		var activity domain.Activity
+		require.NoError(t, db.First(&activity, "id = ?", *result.Email.ActivityID).Error)
+		assert.Equal(t, domain.ActivityTypeEmail, activity.ActivityType)
+		assert.Equal(t, domain.ActivityStatusCompleted, activity.Status)
+		assert.Equal(t, "Tegninger", activity.Title)
+		assert.Contains(t, activity.Body, "Fra: Kari Nordmann <"+contactEmail+">")
+		assert.Contains(t, activity.Body, "Hei, se vedlagt.")
+
+		// The same message is not filed twice
+		again, err := svc.Ingest(ctx, testEmail(messageID, contactEmail, "Tegninger"), domain.EmailSourceUpload)
+		require.NoError(t, err)
+		assert.Equal(t, domain.EmailIngestionDuplicate, again.Email.Status)
+		assert.Equal(t, result.Email.ID, again.Email.ID)
+	})
+
+	t.Run("unknown sender is recorded as unmatched", func(t *testing.T) {
+		result, err := svc.Ingest(ctx, testEmail(uuid.New().String()+"@example.com", "ukjent@example.org", "Hei"), domain.EmailSourceUpload)
+		require.NoError(t, err)
+		assert.Equal(t, domain.EmailIngestionUnmatched, result.Email.Status)
+		assert.Nil(t, result.Email.ActivityID)
+		assert.Empty(t, result.Files)
+
+		status := domain.EmailIngestionUnmatched
+		list, err := svc.List(ctx, &status, 1, 20)
+		require.NoError(t, err)
+		assert.GreaterOrEqual(t, list.Total, int64(1))
+	})
+
+	t.Run("invalid message", func(t *testing.T) {
+		_, err := svc.Ingest(ctx, []byte("not an email"), domain.EmailSourceUpload)
+		assert.ErrorIs(t, err, service.ErrInvalidEmailMessage)
+	})
+
+	t.Run("maildir", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, "new"), 0o750))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "new", "1700000000.1.host"), testEmail(uuid.New().String()+"@example.com", contactEmail, "Fra postkassen"), 0o600))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "new", "1700000001.1.host"), []byte("not an email"), 0o600))
+
+		ingested, matched, err := svc.IngestMaildir(ctx, dir)
+		require.NoError(t, err)
+		assert.Equal(t, 1, ingested)
+		assert.Equal(t, 1, matched)
+
+		assert.FileExists(t, filepath.Join(dir, "cur", "1700000000.1.host:2,S"))
+		assert.FileExists(t, filepath.Join(dir, "cur", "1700000001.1.host:2,T"))
+		remaining, err := os.ReadDir(filepath.Join(dir, "new"))
+		require.NoError(t, err)
+		assert.Empty(t, remaining)
+	})
+}
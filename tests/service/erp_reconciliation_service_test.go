@@ -0,0 +1,193 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/straye-as/relation-api/internal/domain"
+	"github.com/straye-as/relation-api/internal/repository"
+	"github.com/straye-as/relation-api/internal/service"
+	"github.com/straye-as/relation-api/tests/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// fakeERPCustomers is a DataWarehouseClient returning a fixed list of ERP customers
+type fakeERPCustomers struct {
+	customers []service.DataWarehouseERPCustomer
+}
+
+func (f *fakeERPCustomers) IsEnabled() bool { return true }
+
+func (f *fakeERPCustomers) GetERPCustomers(ctx context.Context) ([]service.DataWarehouseERPCustomer, error) {
+	return f.customers, nil
+}
+
+func createERPReconciliationService(db *gorm.DB, erp *fakeERPCustomers, autoCreate bool) *service.ERPReconciliationService {
+	svc := service.NewERPReconciliationService(
+		repository.NewERPReconciliationRepository(db),
+		repository.NewCustomerRepository(db),
+		repository.NewActivityRepository(db),
+		createCustomerService(db),
+		autoCreate,
+		zap.NewNop(),
+	)
+	svc.SetDataWarehouseClient(erp)
+	return svc
+}
+
+func pendingDecisions(t *testing.T, svc *service.ERPReconciliationService, ctx context.Context, decisionType domain.ERPReconciliationDecisionType) []domain.ERPReconciliationDecisionDTO {
+	status := domain.ERPReconciliationDecisionPending
+	result, err := svc.ListDecisions(ctx, &repository.ERPReconciliationDecisionFilters{Status: &status, Type: &decisionType}, 1, 200)
+	require.NoError(t, err)
+	return result.Data.([]domain.ERPReconciliationDecisionDTO)
+}
+
+func TestERPReconciliationService_Run(t *testing.T) {
+	db := setupCustomerServiceTestDB(t)
+	defer testutil.CleanupTestData(t, db)
+	customerSvc := createCustomerService(db)
+	ctx := createCustomerTestContext()
+
+	existingOrg := testutil.ValidOrgNumber(30000100)
+	missingOrg := testutil.ValidOrgNumber(30000110)
+
+	existing, err := customerSvc.Create(ctx, &domain.CreateCustomerRequest{
+		Name:       "Bryggen Tak AS",
+		OrgNumber:  existingOrg,
+		Address:    "Gamle vei 1",
+		PostalCode: "5003",
+		Country:    "Norway",
+	})
+	require.NoError(t, err)
+
+	erp := &fakeERPCustomers{customers: []service.DataWarehouseERPCustomer{
+		// Same customer registered in two companies, with a new name and address in the ERP
+		{Firmanr: 3, CustomerNumber: "1001", OrganizationNumber: existingOrg, Name: "Bryggen Tak og Bygg AS", Address: "Ny vei 2", PostalCode: "5003"},
+		{Firmanr: 6, CustomerNumber: "2001", OrganizationNumber: existingOrg, Name: "Bryggen Tak og Bygg AS"},
+		// Missing in the CRM
+		{Firmanr: 3, CustomerNumber: "1002", OrganizationNumber: missingOrg, Name: "Nytt Byggfirma AS", Address: "Industriveien 5", PostalCode: "150"},
+	}}
+	svc := createERPReconciliationService(db, erp, false)
+
+	run, err := svc.Run(ctx, domain.ERPReconciliationTriggerManual)
+	require.NoError(t, err)
+	assert.Equal(t, domain.ERPReconciliationRunStatusCompleted, run.Status)
+	assert.Equal(t, 2, run.ERPCustomerCount, "duplicate ERP rows are merged")
+	assert.Equal(t, 1, run.MatchedCount)
+	assert.Equal(t, 1, run.CreatesQueued)
+	assert.Equal(t, 1, run.FieldsUpdated, "address is ERP wins by default")
+	assert.Equal(t, 1, run.DriftsQueued, "name is reviewed by default")
+	assert.Equal(t, 0, run.Errors)
+
+	updated, err := customerSvc.GetByID(ctx, existing.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Ny vei 2", updated.Address)
+	assert.Equal(t, "Bryggen Tak AS", updated.Name)
+
+	creates := pendingDecisions(t, svc, ctx, domain.ERPReconciliationDecisionCreateCustomer)
+	require.Len(t, creates, 1)
+	assert.Equal(t, "Nytt Byggfirma AS", creates[0].ERPName)
+
+	drifts := pendingDecisions(t, svc, ctx, domain.ERPReconciliationDecisionFieldDrift)
+	require.Len(t, drifts, 1)
+	assert.Equal(t, domain.ERPReconciliationFieldName, drifts[0].Field)
+	assert.Equal(t, "Bryggen Tak AS", drifts[0].CRMValue)
+	assert.Equal(t, "Bryggen Tak og Bygg AS", drifts[0].ERPValue)
+
+	// A second run refreshes the pending decisions instead of queueing duplicates
+	_, err = svc.Run(ctx, domain.ERPReconciliationTriggerManual)
+	require.NoError(t, err)
+	assert.Len(t, pendingDecisions(t, svc, ctx, domain.ERPReconciliationDecisionCreateCustomer), 1)
+	assert.Len(t, pendingDecisions(t, svc, ctx, domain.ERPReconciliationDecisionFieldDrift), 1)
+
+	// Approving the create decision creates the customer with the ERP data
+	approved, err := svc.ApproveDecision(ctx, creates[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.ERPReconciliationDecisionApproved, approved.Status)
+	require.NotNil(t, approved.CustomerID)
+	created, err := customerSvc.GetByID(ctx, *approved.CustomerID)
+	require.NoError(t, err)
+	assert.Equal(t, missingOrg, created.OrgNumber)
+	assert.Equal(t, "0150", created.PostalCode)
+
+	_, err = svc.ApproveDecision(ctx, creates[0].ID)
+	assert.ErrorIs(t, err, service.ErrERPReconciliationDecisionResolved)
+
+	// A rejected drift is not queued again while the ERP value is unchanged
+	_, err = svc.RejectDecision(ctx, drifts[0].ID)
+	require.NoError(t, err)
+
+	run, err = svc.Run(ctx, domain.ERPReconciliationTriggerManual)
+	require.NoError(t, err)
+	assert.Equal(t, 2, run.MatchedCount)
+	assert.Equal(t, 0, run.CreatesQueued)
+	assert.Equal(t, 0, run.DriftsQueued)
+	assert.Empty(t, pendingDecisions(t, svc, ctx, domain.ERPReconciliationDecisionFieldDrift))
+
+	runs, err := svc.ListRuns(ctx, 1, 20)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), runs.Total)
+}
+
+func TestERPReconciliationService_AutoCreateAndPolicies(t *testing.T) {
+	db := setupCustomerServiceTestDB(t)
+	defer testutil.CleanupTestData(t, db)
+	customerSvc := createCustomerService(db)
+	ctx := createCustomerTestContext()
+
+	existingOrg := testutil.ValidOrgNumber(30000120)
+	missingOrg := testutil.ValidOrgNumber(30000130)
+
+	existing, err := customerSvc.Create(ctx, &domain.CreateCustomerRequest{
+		Name:      "Fjordbygg AS",
+		OrgNumber: existingOrg,
+		Country:   "Norway",
+	})
+	require.NoError(t, err)
+
+	erp := &fakeERPCustomers{customers: []service.DataWarehouseERPCustomer{
+		{Firmanr: 3, OrganizationNumber: existingOrg, Name: "Fjordbygg Entreprenør AS"},
+		{Firmanr: 3, OrganizationNumber: missingOrg, Name: "Automatisk Opprettet AS"},
+	}}
+	svc := createERPReconciliationService(db, erp, true)
+
+	// Restore the default policy for other tests
+	defer func() {
+		_, _ = svc.UpdatePolicies(ctx, &domain.UpdateERPReconciliationPoliciesRequest{
+			Policies: map[string]domain.ERPReconciliationPolicy{domain.ERPReconciliationFieldName: domain.ERPReconciliationPolicyReview},
+		})
+	}()
+
+	_, err = svc.UpdatePolicies(ctx, &domain.UpdateERPReconciliationPoliciesRequest{
+		Policies: map[string]domain.ERPReconciliationPolicy{"email": domain.ERPReconciliationPolicyERPWins},
+	})
+	assert.ErrorIs(t, err, service.ErrInvalidERPReconciliationPolicy)
+
+	policies, err := svc.UpdatePolicies(ctx, &domain.UpdateERPReconciliationPoliciesRequest{
+		Policies: map[string]domain.ERPReconciliationPolicy{domain.ERPReconciliationFieldName: domain.ERPReconciliationPolicyCRMWins},
+	})
+	require.NoError(t, err)
+	require.Len(t, policies, len(domain.ERPReconciliationFields))
+	assert.Equal(t, domain.ERPReconciliationPolicyCRMWins, policies[0].Policy)
+
+	run, err := svc.Run(ctx, domain.ERPReconciliationTriggerScheduled)
+	require.NoError(t, err)
+	assert.Equal(t, 1, run.CustomersCreated)
+	assert.Equal(t, 0, run.CreatesQueued)
+	assert.Equal(t, 1, run.DriftsKept)
+	assert.Equal(t, 0, run.DriftsQueued)
+
+	kept, err := customerSvc.GetByID(ctx, existing.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Fjordbygg AS", kept.Name)
+
+	overview, err := svc.GetOverview(ctx)
+	require.NoError(t, err)
+	assert.True(t, overview.AutoCreate)
+	require.NotNil(t, overview.LastRun)
+	assert.Equal(t, run.ID, overview.LastRun.ID)
+	assert.Equal(t, 0, overview.PendingCreates)
+}
@@ -0,0 +1,162 @@
+package service_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/straye-as/relation-api/internal/domain"
+	"github.com/straye-as/relation-api/internal/repository"
+	"github.com/straye-as/relation-api/internal/service"
+	"github.com/straye-as/relation-api/tests/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+func createImportService(db *gorm.DB) *service.ImportService {
+	return service.NewImportService(
+		repository.NewImportRepository(db),
+		repository.NewCustomerRepository(db),
+		repository.NewOfferRepository(db),
+		createCustomerService(db),
+		nil, // Offer service is only used when committing offer imports
+		zap.NewNop(),
+	)
+}
+
+func TestImportService_Customers(t *testing.T) {
+	db := setupCustomerServiceTestDB(t)
+	defer testutil.CleanupTestData(t, db)
+	svc := createImportService(db)
+	customerSvc := createCustomerService(db)
+	ctx := createCustomerTestContext()
+
+	existingOrg := testutil.ValidOrgNumber(28000000)
+	newOrg := testutil.ValidOrgNumber(28000010)
+
+	existing, err := customerSvc.Create(ctx, &domain.CreateCustomerRequest{
+		Name:      "Eksisterende Bygg AS",
+		OrgNumber: existingOrg,
+		Country:   "Norway",
+	})
+	require.NoError(t, err)
+
+	csv := strings.Join([]string{
+		"Kundenavn;Org.nr.;Epost;Postnr.;Kredittgrense",
+		fmt.Sprintf("Eksisterende Bygg AS;%s;post@eksisterende.no;5003;100 000", existingOrg),
+		fmt.Sprintf("Nytt Firma AS;%s;post@nytt.no;150;", newOrg),
+		fmt.Sprintf("Nytt Firma AS Duplikat;%s;;;", newOrg),
+		";;;;",
+		"Uten Navn;;ikke-en-epost;;",
+		";123;;;",
+	}, "\n")
+
+	job, err := svc.Upload(ctx, domain.ImportEntityCustomer, "kunder.csv", strings.NewReader(csv))
+	require.NoError(t, err)
+	assert.Equal(t, domain.ImportJobStatusUploaded, job.Status)
+	assert.Equal(t, 5, job.RowCount)
+	assert.Equal(t, "name", job.SuggestedMapping["Kundenavn"])
+	assert.Equal(t, "orgNumber", job.SuggestedMapping["Org.nr."])
+	assert.Equal(t, "postalCode", job.SuggestedMapping["Postnr."])
+
+	t.Run("commit requires dry run", func(t *testing.T) {
+		_, err := svc.Commit(ctx, job.ID)
+		assert.ErrorIs(t, err, service.ErrImportNotValidated)
+	})
+
+	t.Run("dry run rejects unknown fields", func(t *testing.T) {
+		_, err := svc.DryRun(ctx, job.ID, &domain.RunImportRequest{
+			Mapping: map[string]string{"Kundenavn": "name", "Epost": "unknown"},
+		})
+		assert.ErrorIs(t, err, service.ErrInvalidImportMapping)
+	})
+
+	report, err := svc.DryRun(ctx, job.ID, &domain.RunImportRequest{
+		Mapping:        job.SuggestedMapping,
+		UpdateExisting: true,
+	})
+	require.NoError(t, err)
+	assert.True(t, report.DryRun)
+	assert.Equal(t, domain.ImportSummaryDTO{Total: 5, Create: 1, Update: 1, Skip: 1, Error: 2}, report.Summary)
+
+	require.Len(t, report.Rows, 5)
+	assert.Equal(t, 2, report.Rows[0].Row)
+	assert.Equal(t, domain.ImportRowActionUpdate, report.Rows[0].Action)
+	assert.Equal(t, "org_number", report.Rows[0].MatchedBy)
+	assert.Equal(t, existing.ID, *report.Rows[0].EntityID)
+	assert.Equal(t, domain.ImportRowActionCreate, report.Rows[1].Action)
+	assert.Equal(t, domain.ImportRowActionSkip, report.Rows[2].Action)
+	assert.Contains(t, report.Rows[2].Messages, "duplicate of row 3")
+	assert.Equal(t, domain.ImportRowActionError, report.Rows[3].Action)
+	assert.Equal(t, domain.ImportRowActionError, report.Rows[4].Action)
+
+	// Nothing is written by the dry run
+	var count int64
+	db.Model(&domain.Customer{}).Count(&count)
+	assert.Equal(t, int64(1), count)
+
+	committed, err := svc.Commit(ctx, job.ID)
+	require.NoError(t, err)
+	assert.False(t, committed.DryRun)
+	assert.Equal(t, 1, committed.Summary.Create)
+	assert.Equal(t, 1, committed.Summary.Update)
+	require.NotNil(t, committed.Rows[1].EntityID)
+
+	created, err := customerSvc.GetByID(ctx, *committed.Rows[1].EntityID)
+	require.NoError(t, err)
+	assert.Equal(t, "Nytt Firma AS", created.Name)
+	assert.Equal(t, "0150", created.PostalCode)
+
+	updated, err := customerSvc.GetByID(ctx, existing.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "post@eksisterende.no", updated.Email)
+	assert.Equal(t, "Bergen", updated.City)
+	require.NotNil(t, updated.CreditLimit)
+	assert.Equal(t, 100000.0, *updated.CreditLimit)
+
+	_, err = svc.Commit(ctx, job.ID)
+	assert.ErrorIs(t, err, service.ErrImportAlreadyCommitted)
+}
+
+func TestImportService_MappingProfiles(t *testing.T) {
+	db := setupCustomerServiceTestDB(t)
+	defer testutil.CleanupTestData(t, db)
+	svc := createImportService(db)
+	ctx := createCustomerTestContext()
+
+	profile, err := svc.CreateProfile(ctx, &domain.SaveImportMappingProfileRequest{
+		Name:       "ERP kundeeksport",
+		EntityType: domain.ImportEntityCustomer,
+		Mapping:    map[string]string{"Kundenavn": "name", "Org.nr.": "orgNumber"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "ERP kundeeksport", profile.Name)
+
+	_, err = svc.CreateProfile(ctx, &domain.SaveImportMappingProfileRequest{
+		Name:       "erp KUNDEEKSPORT",
+		EntityType: domain.ImportEntityCustomer,
+		Mapping:    map[string]string{"Navn": "name"},
+	})
+	assert.ErrorIs(t, err, service.ErrDuplicateImportMappingProfile)
+
+	_, err = svc.CreateProfile(ctx, &domain.SaveImportMappingProfileRequest{
+		Name:       "Mangler navn",
+		EntityType: domain.ImportEntityCustomer,
+		Mapping:    map[string]string{"Org.nr.": "orgNumber"},
+	})
+	assert.ErrorIs(t, err, service.ErrInvalidImportMapping)
+
+	job, err := svc.Upload(ctx, domain.ImportEntityCustomer, "kunder.csv", strings.NewReader("Kundenavn;Org.nr.\nProfil Test AS;\n"))
+	require.NoError(t, err)
+
+	report, err := svc.DryRun(ctx, job.ID, &domain.RunImportRequest{MappingProfileID: &profile.ID})
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Summary.Create)
+
+	require.NoError(t, svc.DeleteProfile(ctx, profile.ID))
+	profiles, err := svc.ListProfiles(ctx, nil)
+	require.NoError(t, err)
+	assert.Empty(t, profiles)
+}
@@ -0,0 +1,181 @@
+package service_test
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/straye-as/relation-api/internal/domain"
+	"github.com/straye-as/relation-api/internal/repository"
+	"github.com/straye-as/relation-api/internal/service"
+	"github.com/straye-as/relation-api/tests/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+func floatPtr(v float64) *float64 { return &v }
+
+func intPtr(v int) *int { return &v }
+
+func TestMatchesInquiryAssignmentRule(t *testing.T) {
+	construction := service.InquiryAssignmentFacts{
+		Industry:   domain.CustomerIndustryConstruction,
+		PostalCode: "5003",
+		County:     "Vestland",
+		Value:      750000,
+	}
+
+	tests := []struct {
+		name  string
+		rule  domain.InquiryAssignmentRule
+		facts service.InquiryAssignmentFacts
+		want  bool
+	}{
+		{"round robin matches everything", domain.InquiryAssignmentRule{RuleType: domain.InquiryAssignmentRoundRobin}, service.InquiryAssignmentFacts{}, true},
+		{"industry match", domain.InquiryAssignmentRule{RuleType: domain.InquiryAssignmentIndustry, Industries: pq.StringArray{"retail", "construction"}}, construction, true},
+		{"industry mismatch", domain.InquiryAssignmentRule{RuleType: domain.InquiryAssignmentIndustry, Industries: pq.StringArray{"retail"}}, construction, false},
+		{"industry unknown", domain.InquiryAssignmentRule{RuleType: domain.InquiryAssignmentIndustry, Industries: pq.StringArray{"retail"}}, service.InquiryAssignmentFacts{}, false},
+		{"postal code prefix", domain.InquiryAssignmentRule{RuleType: domain.InquiryAssignmentPostalRegion, PostalCodePrefixes: pq.StringArray{"50", "51"}}, construction, true},
+		{"county", domain.InquiryAssignmentRule{RuleType: domain.InquiryAssignmentPostalRegion, Counties: pq.StringArray{"vestland"}}, construction, true},
+		{"other region", domain.InquiryAssignmentRule{RuleType: domain.InquiryAssignmentPostalRegion, PostalCodePrefixes: pq.StringArray{"0"}, Counties: pq.StringArray{"Oslo"}}, construction, false},
+		{"value within range", domain.InquiryAssignmentRule{RuleType: domain.InquiryAssignmentValue, MinValue: floatPtr(500000), MaxValue: floatPtr(1000000)}, construction, true},
+		{"value at max is excluded", domain.InquiryAssignmentRule{RuleType: domain.InquiryAssignmentValue, MaxValue: floatPtr(750000)}, construction, false},
+		{"value below min", domain.InquiryAssignmentRule{RuleType: domain.InquiryAssignmentValue, MinValue: floatPtr(1000000)}, construction, false},
+		{"value unknown", domain.InquiryAssignmentRule{RuleType: domain.InquiryAssignmentValue, MaxValue: floatPtr(1000000)}, service.InquiryAssignmentFacts{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, service.MatchesInquiryAssignmentRule(&tt.rule, tt.facts))
+		})
+	}
+}
+
+func createAssignmentTestUser(t *testing.T, db *gorm.DB, name string) *domain.User {
+	user := &domain.User{
+		ID:          "assign-" + uuid.New().String()[:8],
+		Email:       "assign." + uuid.New().String()[:8] + "@straye.no",
+		DisplayName: name,
+		Roles:       pq.StringArray{"sales"},
+	}
+	require.NoError(t, db.Create(user).Error)
+	t.Cleanup(func() { db.Delete(&domain.User{}, "id = ?", user.ID) })
+	return user
+}
+
+func TestInquiryService_CreateAppliesAssignmentRules(t *testing.T) {
+	db := setupInquiryTestDB(t)
+	defer testutil.CleanupTestData(t, db)
+	svc, fixtures := setupInquiryTestService(t, db)
+	defer fixtures.cleanup(t)
+	ctx := createActivityTestContext()
+
+	log := zap.NewNop()
+	userRepo := repository.NewUserRepository(db)
+	assignmentService := service.NewInquiryAssignmentService(
+		repository.NewInquiryAssignmentRuleRepository(db),
+		userRepo,
+		service.NewCompanyServiceWithRepo(repository.NewCompanyRepository(db), userRepo, log),
+		log,
+	)
+	svc.SetAssignmentService(assignmentService)
+
+	kari := createAssignmentTestUser(t, db, "Kari Vest")
+	per := createAssignmentTestUser(t, db, "Per Vest")
+	big := createAssignmentTestUser(t, db, "Stor Kunde")
+
+	_, err := assignmentService.Create(ctx, &domain.CreateInquiryAssignmentRuleRequest{
+		CompanyID:   domain.CompanyStalbygg,
+		Name:        "Store prosjekter",
+		RuleType:    domain.InquiryAssignmentValue,
+		Priority:    intPtr(10),
+		MinValue:    floatPtr(5000000),
+		AssigneeIDs: []string{big.ID},
+	})
+	require.NoError(t, err)
+	roundRobin, err := assignmentService.Create(ctx, &domain.CreateInquiryAssignmentRuleRequest{
+		CompanyID:   domain.CompanyStalbygg,
+		Name:        "Alle",
+		RuleType:    domain.InquiryAssignmentRoundRobin,
+		AssigneeIDs: []string{kari.ID, per.ID},
+	})
+	require.NoError(t, err)
+
+	companyID := domain.CompanyStalbygg
+	create := func(title string, value float64) *domain.OfferDTO {
+		inquiry, err := svc.Create(ctx, &domain.CreateInquiryRequest{
+			Title:          title,
+			CompanyID:      &companyID,
+			EstimatedValue: &value,
+		})
+		require.NoError(t, err)
+		return inquiry
+	}
+
+	t.Run("value rule takes precedence", func(t *testing.T) {
+		inquiry := create("Test stor hall", 8000000)
+		assert.Equal(t, big.ID, inquiry.ResponsibleUserID)
+
+		var activity domain.Activity
+		require.NoError(t, db.Where("target_id = ? AND title = ?", inquiry.ID, "Inquiry assigned").First(&activity).Error)
+		assert.Contains(t, activity.Body, "rule 'Store prosjekter'")
+		assert.Contains(t, activity.Body, "estimated value 8000000")
+	})
+
+	t.Run("round robin rotates among assignees", func(t *testing.T) {
+		first := create("Test carport", 100000)
+		second := create("Test garasje", 100000)
+		third := create("Test lager", 100000)
+
+		assert.ElementsMatch(t, []string{kari.ID, per.ID}, []string{first.ResponsibleUserID, second.ResponsibleUserID})
+		assert.Equal(t, first.ResponsibleUserID, third.ResponsibleUserID)
+	})
+
+	t.Run("conversion keeps the responsible and the rotation", func(t *testing.T) {
+		inquiry := create("Test konvertering", 100000)
+		require.NotEmpty(t, inquiry.ResponsibleUserID)
+
+		nextIndex := func() int {
+			var rule domain.InquiryAssignmentRule
+			require.NoError(t, db.First(&rule, "id = ?", roundRobin.ID).Error)
+			return rule.NextIndex
+		}
+		before := nextIndex()
+
+		converted, err := svc.Convert(ctx, inquiry.ID, &domain.ConvertInquiryRequest{})
+		require.NoError(t, err)
+		assert.Equal(t, inquiry.ResponsibleUserID, converted.Offer.ResponsibleUserID)
+		assert.Empty(t, converted.AssignmentReason)
+		assert.Equal(t, before, nextIndex())
+	})
+
+	t.Run("explicit responsible is kept", func(t *testing.T) {
+		inquiry, err := svc.Create(ctx, &domain.CreateInquiryRequest{
+			Title:       "Test manuell",
+			CompanyID:   &companyID,
+			Responsible: big.ID,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, big.ID, inquiry.ResponsibleUserID)
+	})
+
+	t.Run("rejects rules without conditions or with unknown users", func(t *testing.T) {
+		_, err := assignmentService.Create(ctx, &domain.CreateInquiryAssignmentRuleRequest{
+			CompanyID:   domain.CompanyStalbygg,
+			Name:        "Uten fylker",
+			RuleType:    domain.InquiryAssignmentPostalRegion,
+			AssigneeIDs: []string{kari.ID},
+		})
+		assert.ErrorIs(t, err, service.ErrInvalidInquiryAssignmentRule)
+
+		_, err = assignmentService.Create(ctx, &domain.CreateInquiryAssignmentRuleRequest{
+			CompanyID:   domain.CompanyStalbygg,
+			Name:        "Ukjent",
+			RuleType:    domain.InquiryAssignmentRoundRobin,
+			AssigneeIDs: []string{"no-such-user"},
+		})
+		assert.ErrorIs(t, err, service.ErrInvalidInquiryAssignmentRule)
+	})
+}
@@ -0,0 +1,260 @@
+package service_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/straye-as/relation-api/internal/config"
+	"github.com/straye-as/relation-api/internal/domain"
+	"github.com/straye-as/relation-api/internal/mail"
+	"github.com/straye-as/relation-api/internal/repository"
+	"github.com/straye-as/relation-api/internal/service"
+	"github.com/straye-as/relation-api/internal/storage"
+	"github.com/straye-as/relation-api/tests/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// recordingSender collects the messages it is asked to send
+type recordingSender struct {
+	mu   sync.Mutex
+	sent []*mail.Message
+}
+
+func (s *recordingSender) Enabled() bool { return true }
+
+func (s *recordingSender) Send(_ context.Context, msg *mail.Message) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sent = append(s.sent, msg)
+	return nil
+}
+
+func createInquiryIntakeService(t *testing.T, db *gorm.DB, sender mail.Sender) *service.InquiryIntakeService {
+	log := zap.NewNop()
+	offerRepo := repository.NewOfferRepository(db)
+	customerRepo := repository.NewCustomerRepository(db)
+	activityRepo := repository.NewActivityRepository(db)
+	userRepo := repository.NewUserRepository(db)
+
+	fileStorage, err := storage.NewStorage(&config.StorageConfig{Mode: "disk", LocalBasePath: t.TempDir()}, log)
+	require.NoError(t, err)
+	fileService := service.NewFileService(
+		repository.NewFileRepository(db),
+		offerRepo,
+		customerRepo,
+		repository.NewProjectRepository(db),
+		repository.NewSupplierRepository(db),
+		activityRepo,
+		fileStorage,
+		log,
+	)
+
+	companyService := service.NewCompanyServiceWithRepo(repository.NewCompanyRepository(db), userRepo, log)
+	inquiryService := service.NewInquiryService(offerRepo, customerRepo, activityRepo, userRepo, companyService, log, db)
+
+	return service.NewInquiryIntakeService(
+		repository.NewInquiryIntakeRepository(db),
+		inquiryService,
+		service.NewCustomerService(customerRepo, activityRepo, log),
+		fileService,
+		sender,
+		service.InquiryIntakeOptions{MaxAttachments: 2},
+		log,
+	)
+}
+
+// solveChallenge finds a nonce that satisfies a proof-of-work challenge
+func solveChallenge(challenge string, bits int) string {
+	for i := 0; ; i++ {
+		nonce := strconv.Itoa(i)
+		if service.LeadingZeroBits(sha256.Sum256([]byte(challenge+":"+nonce))) >= bits {
+			return nonce
+		}
+	}
+}
+
+func TestLeadingZeroBits(t *testing.T) {
+	var hash [32]byte
+	assert.Equal(t, 256, service.LeadingZeroBits(hash))
+
+	hash[0] = 0x01
+	assert.Equal(t, 7, service.LeadingZeroBits(hash))
+
+	hash[0] = 0x00
+	hash[1] = 0x20
+	assert.Equal(t, 10, service.LeadingZeroBits(hash))
+}
+
+func TestInquiryIntakeService_Submit(t *testing.T) {
+	db := testutil.SetupTestDB(t)
+	t.Cleanup(func() { testutil.CleanupTestData(t, db) })
+
+	sender := &recordingSender{}
+	svc := createInquiryIntakeService(t, db, sender)
+	ctx := createActivityTestContext()
+	public := context.Background()
+
+	site, err := svc.CreateSite(ctx, &domain.CreateInquiryIntakeSiteRequest{
+		CompanyID:       domain.CompanyStalbygg,
+		Name:            "stalbygg.no",
+		AllowedOrigins:  []string{"https://stalbygg.no/"},
+		HoneypotField:   "website",
+		ProofOfWorkBits: 8,
+	})
+	require.NoError(t, err)
+	assert.NotEmpty(t, site.SiteKey)
+	assert.Equal(t, []string{"https://stalbygg.no"}, site.AllowedOrigins)
+	assert.True(t, site.SendConfirmation)
+
+	newSubmission := func(challenge *domain.InquiryChallengeDTO) *service.PublicInquirySubmission {
+		req := &domain.PublicInquiryRequest{
+			Name:    "Ola Nordmann",
+			Email:   "ola@example.com",
+			Message: "Vi trenger en ny lagerhall på 800 m2.",
+		}
+		if challenge != nil {
+			req.Challenge = challenge.Challenge
+			req.Nonce = solveChallenge(challenge.Challenge, challenge.Bits)
+		}
+		return &service.PublicInquirySubmission{
+			Request: req,
+			Fields:  map[string]string{},
+			Origin:  "https://stalbygg.no",
+			Attachments: []service.IntakeAttachment{
+				{Filename: "skisse.pdf", ContentType: "application/pdf", Data: []byte("%PDF-1.4")},
+			},
+		}
+	}
+
+	t.Run("files a draft inquiry and sends a confirmation", func(t *testing.T) {
+		challenge, err := svc.Challenge(public, site.SiteKey)
+		require.NoError(t, err)
+		assert.Equal(t, 8, challenge.Bits)
+
+		submission := newSubmission(challenge)
+		result, err := svc.Submit(public, site.SiteKey, submission)
+		require.NoError(t, err)
+		require.NotEmpty(t, result.Reference)
+
+		var record domain.InquirySubmission
+		require.NoError(t, db.First(&record, "id = ?", result.Reference).Error)
+		require.NotNil(t, record.OfferID)
+		assert.False(t, record.Spam)
+		assert.Equal(t, 1, record.AttachmentCount)
+		assert.NotNil(t, record.ConfirmationSentAt)
+
+		var offer domain.Offer
+		require.NoError(t, db.First(&offer, "id = ?", *record.OfferID).Error)
+		assert.Equal(t, domain.OfferPhaseDraft, offer.Phase)
+		assert.Equal(t, domain.CompanyStalbygg, offer.CompanyID)
+		assert.Equal(t, "Henvendelse fra Ola Nordmann", offer.Title)
+		assert.Contains(t, offer.Description, "E-post: ola@example.com")
+
+		require.Len(t, sender.sent, 1)
+		assert.Equal(t, "ola@example.com", sender.sent[0].To)
+		// The confirmation carries nothing the submitter wrote
+		assert.NotContains(t, sender.sent[0].Body, "Ola Nordmann")
+		assert.NotContains(t, sender.sent[0].Body, "lagerhall")
+		assert.Contains(t, sender.sent[0].Body, result.Reference)
+
+		// A solved challenge can only be used once
+		_, err = svc.Submit(public, site.SiteKey, submission)
+		assert.ErrorIs(t, err, service.ErrInvalidProofOfWork)
+	})
+
+	t.Run("concurrent replays of a solved challenge file one inquiry", func(t *testing.T) {
+		challenge, err := svc.Challenge(public, site.SiteKey)
+		require.NoError(t, err)
+		submission := newSubmission(challenge)
+		submission.Attachments = nil
+
+		const replays = 5
+		errs := make(chan error, replays)
+		var wg sync.WaitGroup
+		for i := 0; i < replays; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := svc.Submit(public, site.SiteKey, submission)
+				errs <- err
+			}()
+		}
+		wg.Wait()
+		close(errs)
+
+		accepted := 0
+		for err := range errs {
+			if err == nil {
+				accepted++
+				continue
+			}
+			assert.ErrorIs(t, err, service.ErrInvalidProofOfWork)
+		}
+		assert.Equal(t, 1, accepted)
+
+		var submissions int64
+		require.NoError(t, db.Model(&domain.InquirySubmission{}).Where("pow_challenge = ?", challenge.Challenge).Count(&submissions).Error)
+		assert.Equal(t, int64(1), submissions)
+	})
+
+	t.Run("rejects missing proof of work", func(t *testing.T) {
+		_, err := svc.Submit(public, site.SiteKey, newSubmission(nil))
+		assert.ErrorIs(t, err, service.ErrInvalidProofOfWork)
+	})
+
+	t.Run("rejects other origins", func(t *testing.T) {
+		challenge, err := svc.Challenge(public, site.SiteKey)
+		require.NoError(t, err)
+		submission := newSubmission(challenge)
+		submission.Origin = "https://example.org"
+
+		_, err = svc.Submit(public, site.SiteKey, submission)
+		assert.ErrorIs(t, err, service.ErrInquiryOriginNotAllowed)
+	})
+
+	t.Run("records honeypot submissions as spam without an inquiry", func(t *testing.T) {
+		// Without proof of work nothing is stored
+		var before int64
+		require.NoError(t, db.Model(&domain.InquirySubmission{}).Where("site_id = ?", site.ID).Count(&before).Error)
+		submission := newSubmission(nil)
+		submission.Fields["website"] = "http://spam.example"
+		_, err := svc.Submit(public, site.SiteKey, submission)
+		assert.ErrorIs(t, err, service.ErrInvalidProofOfWork)
+		var after int64
+		require.NoError(t, db.Model(&domain.InquirySubmission{}).Where("site_id = ?", site.ID).Count(&after).Error)
+		assert.Equal(t, before, after)
+
+		challenge, err := svc.Challenge(public, site.SiteKey)
+		require.NoError(t, err)
+		submission = newSubmission(challenge)
+		submission.Fields["website"] = "http://spam.example"
+
+		result, err := svc.Submit(public, site.SiteKey, submission)
+		require.NoError(t, err)
+
+		var record domain.InquirySubmission
+		require.NoError(t, db.First(&record, "id = ?", result.Reference).Error)
+		assert.True(t, record.Spam)
+		assert.Nil(t, record.OfferID)
+	})
+
+	t.Run("rejects too many attachments", func(t *testing.T) {
+		submission := newSubmission(nil)
+		submission.Attachments = append(submission.Attachments, submission.Attachments[0], submission.Attachments[0])
+
+		_, err := svc.Submit(public, site.SiteKey, submission)
+		assert.ErrorIs(t, err, service.ErrTooManyInquiryAttachments)
+	})
+
+	t.Run("unknown site key", func(t *testing.T) {
+		_, err := svc.Challenge(public, "site_"+uuid.New().String())
+		assert.ErrorIs(t, err, service.ErrInquirySiteNotFound)
+	})
+}
@@ -0,0 +1,150 @@
+package service_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/straye-as/relation-api/internal/domain"
+	"github.com/straye-as/relation-api/internal/repository"
+	"github.com/straye-as/relation-api/internal/service"
+	"github.com/straye-as/relation-api/tests/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestClassifyInquirySLA(t *testing.T) {
+	due := time.Date(2026, time.March, 12, 10, 0, 0, 0, time.UTC)
+	before := due.Add(-time.Hour)
+	after := due.Add(time.Hour)
+
+	assert.Equal(t, service.InquirySLAMet, service.ClassifyInquirySLA(due, &before, after))
+	assert.Equal(t, service.InquirySLAMet, service.ClassifyInquirySLA(due, &due, after))
+	assert.Equal(t, service.InquirySLABreached, service.ClassifyInquirySLA(due, &after, after))
+	assert.Equal(t, service.InquirySLAOverdue, service.ClassifyInquirySLA(due, nil, after))
+	assert.Equal(t, service.InquirySLAOpen, service.ClassifyInquirySLA(due, nil, before))
+}
+
+func TestInquirySLAService(t *testing.T) {
+	db := setupInquiryTestDB(t)
+	defer testutil.CleanupTestData(t, db)
+	svc, fixtures := setupInquiryTestService(t, db)
+	defer fixtures.cleanup(t)
+	ctx := createActivityTestContext()
+
+	log := zap.NewNop()
+	companyService := service.NewCompanyServiceWithRepo(repository.NewCompanyRepository(db), repository.NewUserRepository(db), log)
+	notificationRepo := repository.NewNotificationRepository(db)
+	slaService := service.NewInquirySLAService(repository.NewOfferRepository(db), notificationRepo, companyService, log)
+
+	responsible := uuid.New().String()
+	companyID := domain.CompanyStalbygg
+	now := time.Now()
+
+	create := func(title string, dueAt time.Time) *domain.OfferDTO {
+		inquiry, err := svc.Create(ctx, &domain.CreateInquiryRequest{Title: title, CompanyID: &companyID})
+		require.NoError(t, err)
+		require.NoError(t, db.Model(&domain.Offer{}).Where("id = ?", inquiry.ID).Updates(map[string]interface{}{
+			"inquiry_sla_due_at":  dueAt.UTC(),
+			"responsible_user_id": responsible,
+		}).Error)
+		return inquiry
+	}
+	logActivity := func(inquiryID uuid.UUID, activityType domain.ActivityType, creatorID string) {
+		require.NoError(t, db.Create(&domain.Activity{
+			TargetType:   domain.ActivityTargetOffer,
+			TargetID:     inquiryID,
+			Title:        "Svar til kunde",
+			OccurredAt:   now,
+			ActivityType: activityType,
+			Status:       domain.ActivityStatusCompleted,
+			CreatorID:    creatorID,
+		}).Error)
+	}
+
+	t.Run("new inquiries get a deadline in business hours", func(t *testing.T) {
+		inquiry, err := svc.Create(ctx, &domain.CreateInquiryRequest{Title: "Test frist", CompanyID: &companyID})
+		require.NoError(t, err)
+		require.NotNil(t, inquiry.InquirySLADueAt)
+
+		created, err := time.Parse(time.RFC3339, inquiry.CreatedAt)
+		require.NoError(t, err)
+		due, err := time.Parse(time.RFC3339, *inquiry.InquirySLADueAt)
+		require.NoError(t, err)
+		policy := companyService.GetInquirySLAPolicy(ctx, companyID)
+		assert.InDelta(t, policy.Target.Hours(), policy.Calendar.Between(created, due).Hours(), 0.05)
+
+		// The deadline survives a round trip through the database
+		var stored domain.Offer
+		require.NoError(t, db.First(&stored, "id = ?", inquiry.ID).Error)
+		require.NotNil(t, stored.InquirySLADueAt)
+		assert.WithinDuration(t, due, *stored.InquirySLADueAt, time.Second)
+	})
+
+	overdue := create("Test ubesvart", now.Add(-time.Hour))
+	answered := create("Test ringt opp", now.Add(72*time.Hour))
+	logActivity(answered.ID, domain.ActivityTypeCall, "user-1")
+	late := create("Test sent svar", now.Add(-48*time.Hour))
+	open := create("Test innkommende e-post", now.Add(72*time.Hour))
+	logActivity(open.ID, domain.ActivityTypeEmail, "")
+
+	_, err := svc.Convert(ctx, late.ID, &domain.ConvertInquiryRequest{ResponsibleUserID: &responsible})
+	require.NoError(t, err)
+
+	t.Run("report counts outcomes per responsible user", func(t *testing.T) {
+		report, err := slaService.Report(ctx, nil, nil, &companyID)
+		require.NoError(t, err)
+
+		var stats *domain.InquirySLAUserDTO
+		for i := range report.Users {
+			if report.Users[i].UserID == responsible {
+				stats = &report.Users[i]
+			}
+		}
+		require.NotNil(t, stats)
+		assert.Equal(t, 4, stats.Total)
+		assert.Equal(t, 1, stats.Met)
+		assert.Equal(t, 2, stats.Breached)
+		assert.Equal(t, 1, stats.Overdue)
+		assert.Equal(t, 1, stats.Open)
+		require.NotNil(t, stats.CompliancePercent)
+		assert.Equal(t, 33.3, *stats.CompliancePercent)
+
+		var overdueIDs []uuid.UUID
+		for _, item := range report.Overdue {
+			overdueIDs = append(overdueIDs, item.ID)
+		}
+		assert.Contains(t, overdueIDs, overdue.ID)
+		assert.NotContains(t, overdueIDs, late.ID)
+	})
+
+	t.Run("rejects a period that ends before it starts", func(t *testing.T) {
+		from := now
+		to := now.AddDate(0, 0, -1)
+		_, err := slaService.Report(ctx, &from, &to, nil)
+		assert.ErrorIs(t, err, service.ErrInvalidDateRange)
+	})
+
+	t.Run("warns the responsible user once before the deadline", func(t *testing.T) {
+		soon := create("Test snart frist", now.Add(time.Minute))
+
+		_, warned, err := slaService.SendWarnings(ctx)
+		require.NoError(t, err)
+		assert.GreaterOrEqual(t, warned, 2)
+
+		var notifications []domain.Notification
+		require.NoError(t, db.Where("user_id = ? AND type = ?", responsible, domain.NotificationTypeInquirySLA).Find(&notifications).Error)
+		var entityIDs []uuid.UUID
+		for _, n := range notifications {
+			entityIDs = append(entityIDs, *n.EntityID)
+		}
+		assert.ElementsMatch(t, []uuid.UUID{overdue.ID, soon.ID}, entityIDs)
+
+		_, _, err = slaService.SendWarnings(ctx)
+		require.NoError(t, err)
+		var count int64
+		require.NoError(t, db.Model(&domain.Notification{}).Where("user_id = ? AND type = ?", responsible, domain.NotificationTypeInquirySLA).Count(&count).Error)
+		assert.Equal(t, int64(2), count)
+	})
+}
@@ -0,0 +1,182 @@
+package service_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/straye-as/relation-api/internal/domain"
+	"github.com/straye-as/relation-api/internal/repository"
+	"github.com/straye-as/relation-api/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func scheduleTestTask(kind domain.ProjectTaskKind, start, end string) domain.ProjectTask {
+	startDate, _ := time.Parse("2006-01-02", start)
+	endDate, _ := time.Parse("2006-01-02", end)
+	task := domain.ProjectTask{Kind: kind, StartDate: startDate, EndDate: endDate}
+	task.ID = uuid.New()
+	return task
+}
+
+func TestScheduleProjectTasks(t *testing.T) {
+	foundation := scheduleTestTask(domain.ProjectTaskKindTask, "2026-05-04", "2026-05-08")
+	walls := scheduleTestTask(domain.ProjectTaskKindTask, "2026-05-04", "2026-05-06")
+	permits := scheduleTestTask(domain.ProjectTaskKindTask, "2026-05-04", "2026-05-05")
+	handover := scheduleTestTask(domain.ProjectTaskKindMilestone, "2026-05-08", "2026-05-08")
+	permits.MilestoneID = &handover.ID
+
+	tasks := []domain.ProjectTask{foundation, walls, permits, handover}
+	deps := []domain.ProjectTaskDependency{
+		{TaskID: walls.ID, DependsOnID: foundation.ID},
+		{TaskID: handover.ID, DependsOnID: walls.ID},
+	}
+
+	t.Run("pushes dependent items and computes slack", func(t *testing.T) {
+		scheduled, err := service.ScheduleProjectTasks(tasks, deps)
+		require.NoError(t, err)
+		require.Len(t, scheduled, 4)
+
+		byID := make(map[uuid.UUID]service.ScheduledTask)
+		var critical []uuid.UUID
+		for _, item := range scheduled {
+			byID[item.Task.ID] = item
+			if item.Critical {
+				critical = append(critical, item.Task.ID)
+			}
+		}
+
+		assert.Equal(t, "2026-05-09", byID[walls.ID].EarliestStart.Format("2006-01-02"))
+		assert.Equal(t, "2026-05-11", byID[walls.ID].EarliestFinish.Format("2006-01-02"))
+		assert.Equal(t, "2026-05-11", byID[handover.ID].EarliestFinish.Format("2006-01-02"))
+		assert.Equal(t, 6, byID[permits.ID].SlackDays)
+		assert.Equal(t, "2026-05-11", byID[permits.ID].LatestFinish.Format("2006-01-02"))
+		assert.ElementsMatch(t, []uuid.UUID{walls.ID}, byID[handover.ID].DependsOn)
+		assert.ElementsMatch(t, []uuid.UUID{walls.ID, permits.ID}, byID[handover.ID].Predecessors)
+		assert.Equal(t, []uuid.UUID{foundation.ID, walls.ID, handover.ID}, critical)
+	})
+
+	t.Run("rejects cycles", func(t *testing.T) {
+		_, err := service.ScheduleProjectTasks(tasks, append(deps, domain.ProjectTaskDependency{TaskID: foundation.ID, DependsOnID: walls.ID}))
+		assert.ErrorIs(t, err, service.ErrProjectTaskDependencyCycle)
+	})
+
+	t.Run("rejects a task waiting for its own milestone", func(t *testing.T) {
+		_, err := service.ScheduleProjectTasks(tasks, append(deps, domain.ProjectTaskDependency{TaskID: permits.ID, DependsOnID: handover.ID}))
+		assert.ErrorIs(t, err, service.ErrProjectTaskDependencyCycle)
+	})
+}
+
+func TestProjectScheduleService(t *testing.T) {
+	db := setupProjectTestDB(t)
+	_, fixtures := setupProjectTestService(t, db)
+	t.Cleanup(func() { fixtures.cleanup(t) })
+	ctx := createProjectTestContext()
+
+	notificationRepo := repository.NewNotificationRepository(db)
+	svc := service.NewProjectScheduleService(
+		fixtures.projectRepo,
+		repository.NewProjectTaskRepository(db),
+		fixtures.offerRepo,
+		repository.NewUserRepository(db),
+		repository.NewActivityRepository(db),
+		notificationRepo,
+		zap.NewNop(),
+	)
+
+	project, customer := fixtures.createTestProject(t, ctx, "Test Schedule Project", domain.ProjectPhaseWorking)
+	managerID := uuid.New().String()
+	offerEnd := time.Date(2026, time.May, 8, 0, 0, 0, 0, time.UTC)
+	offer := &domain.Offer{
+		Title:        "Test Schedule Offer",
+		OfferNumber:  fmt.Sprintf("SCHED-%d", time.Now().UnixNano()),
+		CustomerID:   &customer.ID,
+		CustomerName: customer.Name,
+		CompanyID:    domain.CompanyStalbygg,
+		Phase:        domain.OfferPhaseOrder,
+		Status:       domain.OfferStatusActive,
+		ProjectID:    &project.ID,
+		EndDate:      &offerEnd,
+		ManagerID:    &managerID,
+	}
+	require.NoError(t, db.Create(offer).Error)
+	t.Cleanup(func() { db.Exec("DELETE FROM notifications WHERE user_id = ?", managerID) })
+
+	foundation, err := svc.CreateTask(ctx, project.ID, &domain.CreateProjectTaskRequest{
+		Name: "Fundamentering", StartDate: "2026-05-04", EndDate: "2026-05-07",
+	})
+	require.NoError(t, err)
+	handover, err := svc.CreateTask(ctx, project.ID, &domain.CreateProjectTaskRequest{
+		Kind: domain.ProjectTaskKindMilestone, Name: "Overlevering", StartDate: "2026-05-08",
+		DependsOn: []uuid.UUID{foundation.ID},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "2026-05-08", handover.EndDate)
+
+	t.Run("schedule without slip has no warnings", func(t *testing.T) {
+		schedule, err := svc.GetSchedule(ctx, project.ID)
+		require.NoError(t, err)
+		assert.Len(t, schedule.Items, 2)
+		assert.Equal(t, []domain.ProjectScheduleLinkDTO{{Source: foundation.ID, Target: handover.ID, Type: "finish_to_start"}}, schedule.Links)
+		assert.Empty(t, schedule.Warnings)
+		require.NotNil(t, schedule.OfferEndDate)
+		assert.Equal(t, "2026-05-08", *schedule.OfferEndDate)
+	})
+
+	t.Run("slipping milestone warns the offer manager once", func(t *testing.T) {
+		update := &domain.UpdateProjectTaskRequest{
+			Name: "Fundamentering", StartDate: "2026-05-04", EndDate: "2026-05-11", PercentComplete: 40,
+		}
+		_, err := svc.UpdateTask(ctx, project.ID, foundation.ID, update)
+		require.NoError(t, err)
+
+		schedule, err := svc.GetSchedule(ctx, project.ID)
+		require.NoError(t, err)
+		require.Len(t, schedule.Warnings, 1)
+		warning := schedule.Warnings[0]
+		assert.Equal(t, service.WarningMilestoneAfterOfferEndDate, warning.Code)
+		assert.Equal(t, handover.ID, warning.TaskID)
+		assert.Equal(t, "2026-05-11", warning.ForecastDate)
+		assert.Equal(t, 3, warning.DaysLate)
+
+		update.PercentComplete = 60
+		_, err = svc.UpdateTask(ctx, project.ID, foundation.ID, update)
+		require.NoError(t, err)
+
+		var count int64
+		require.NoError(t, db.Model(&domain.Notification{}).
+			Where("user_id = ? AND type = ?", managerID, domain.NotificationTypeMilestoneSlipped).
+			Count(&count).Error)
+		assert.Equal(t, int64(1), count)
+	})
+
+	t.Run("rejects dependency cycles", func(t *testing.T) {
+		_, err := svc.UpdateTask(ctx, project.ID, foundation.ID, &domain.UpdateProjectTaskRequest{
+			Name: "Fundamentering", StartDate: "2026-05-04", EndDate: "2026-05-11",
+			DependsOn: []uuid.UUID{handover.ID},
+		})
+		assert.ErrorIs(t, err, service.ErrProjectTaskDependencyCycle)
+	})
+
+	t.Run("rejects a milestone spanning several days", func(t *testing.T) {
+		_, err := svc.CreateTask(ctx, project.ID, &domain.CreateProjectTaskRequest{
+			Kind: domain.ProjectTaskKindMilestone, Name: "Ferdigbefaring", StartDate: "2026-05-12", EndDate: "2026-05-14",
+		})
+		assert.ErrorIs(t, err, service.ErrInvalidProjectTask)
+	})
+
+	t.Run("deleting a task removes its links", func(t *testing.T) {
+		require.NoError(t, svc.DeleteTask(ctx, project.ID, foundation.ID))
+
+		schedule, err := svc.GetSchedule(ctx, project.ID)
+		require.NoError(t, err)
+		assert.Len(t, schedule.Items, 1)
+		assert.Empty(t, schedule.Links)
+		assert.Empty(t, schedule.Warnings)
+
+		assert.ErrorIs(t, svc.DeleteTask(ctx, project.ID, foundation.ID), service.ErrProjectTaskNotFound)
+	})
+}
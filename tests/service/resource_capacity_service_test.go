@@ -0,0 +1,190 @@
+package service_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/straye-as/relation-api/internal/domain"
+	"github.com/straye-as/relation-api/internal/repository"
+	"github.com/straye-as/relation-api/internal/service"
+	"github.com/straye-as/relation-api/tests/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func capacityDate(value string) time.Time {
+	date, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		panic(err)
+	}
+	return date
+}
+
+func capacityHours(hours float64) *float64 {
+	return &hours
+}
+
+func TestBuildCapacityWeeks(t *testing.T) {
+	t.Run("public holidays reduce capacity", func(t *testing.T) {
+		// Ascension Day is Thursday 14 May 2026
+		allocations := []domain.ResourceAllocation{{
+			StartDate: capacityDate("2026-05-01"), EndDate: capacityDate("2026-05-31"), HoursPerWeek: capacityHours(37.5),
+		}}
+
+		weeks := service.BuildCapacityWeeks(37.5, nil, allocations, capacityDate("2026-05-11"), capacityDate("2026-05-17"))
+
+		require.Len(t, weeks, 1)
+		assert.Equal(t, "2026-05-11", weeks[0].WeekStart)
+		assert.Equal(t, 20, weeks[0].Week)
+		assert.Equal(t, 4, weeks[0].WorkingDays)
+		assert.Equal(t, 30.0, weeks[0].AvailableHours)
+		assert.Equal(t, 30.0, weeks[0].AllocatedHours)
+		assert.False(t, weeks[0].Overallocated)
+	})
+
+	t.Run("absences make allocations overbook", func(t *testing.T) {
+		absences := []domain.UserAbsence{{
+			Kind: domain.UserAbsenceKindVacation, StartDate: capacityDate("2026-06-01"), EndDate: capacityDate("2026-06-02"), Percent: 100,
+		}}
+		allocations := []domain.ResourceAllocation{{
+			StartDate: capacityDate("2026-06-01"), EndDate: capacityDate("2026-06-30"), HoursPerWeek: capacityHours(30),
+		}}
+
+		weeks := service.BuildCapacityWeeks(37.5, absences, allocations, capacityDate("2026-06-01"), capacityDate("2026-06-14"))
+
+		require.Len(t, weeks, 2)
+		assert.Equal(t, 15.0, weeks[0].AbsenceHours)
+		assert.Equal(t, 22.5, weeks[0].AvailableHours)
+		assert.Equal(t, -7.5, weeks[0].FreeHours)
+		assert.True(t, weeks[0].Overallocated)
+		require.NotNil(t, weeks[0].UtilizationPercent)
+		assert.Equal(t, 133.33, *weeks[0].UtilizationPercent)
+		assert.False(t, weeks[1].Overallocated)
+	})
+
+	t.Run("percent allocations follow the user's weekly hours", func(t *testing.T) {
+		percent := 100.0
+		allocations := []domain.ResourceAllocation{{
+			StartDate: capacityDate("2026-09-01"), EndDate: capacityDate("2026-09-30"), Percent: &percent,
+		}}
+
+		// Starts on a Wednesday, so the first week only counts three days
+		weeks := service.BuildCapacityWeeks(20, nil, allocations, capacityDate("2026-09-02"), capacityDate("2026-09-13"))
+
+		require.Len(t, weeks, 2)
+		assert.Equal(t, "2026-08-31", weeks[0].WeekStart)
+		assert.Equal(t, 3, weeks[0].WorkingDays)
+		assert.Equal(t, 12.0, weeks[0].AllocatedHours)
+		assert.Equal(t, 20.0, weeks[1].AllocatedHours)
+		assert.False(t, weeks[1].Overallocated)
+	})
+
+	t.Run("partial absences such as graded sick leave", func(t *testing.T) {
+		absences := []domain.UserAbsence{{
+			Kind: domain.UserAbsenceKindSickLeave, StartDate: capacityDate("2026-10-05"), EndDate: capacityDate("2026-10-09"), Percent: 40,
+		}}
+
+		weeks := service.BuildCapacityWeeks(37.5, absences, nil, capacityDate("2026-10-05"), capacityDate("2026-10-11"))
+
+		require.Len(t, weeks, 1)
+		assert.Equal(t, 15.0, weeks[0].AbsenceHours)
+		assert.Equal(t, 22.5, weeks[0].FreeHours)
+		require.NotNil(t, weeks[0].UtilizationPercent)
+		assert.Equal(t, 0.0, *weeks[0].UtilizationPercent)
+	})
+}
+
+func TestResourceCapacityService_ManagerOverCapacity(t *testing.T) {
+	db := setupProjectTestDB(t)
+	_, fixtures := setupProjectTestService(t, db)
+	t.Cleanup(func() { testutil.CleanupTestData(t, db) })
+	ctx := createProjectTestContext()
+
+	svc := service.NewResourceCapacityService(
+		repository.NewResourceCapacityRepository(db),
+		fixtures.offerRepo,
+		fixtures.projectRepo,
+		repository.NewUserRepository(db),
+		zap.NewNop(),
+	)
+	_, customer := fixtures.createTestProject(t, ctx, "Test Capacity Project", domain.ProjectPhaseWorking)
+
+	managerID := uuid.New().String()
+	start, end := capacityDate("2026-11-02"), capacityDate("2026-11-27")
+	createOffer := func(title string) *domain.Offer {
+		offer := &domain.Offer{
+			Title:        title,
+			OfferNumber:  fmt.Sprintf("CAPACITY-%d", time.Now().UnixNano()),
+			CustomerID:   &customer.ID,
+			CustomerName: customer.Name,
+			CompanyID:    domain.CompanyStalbygg,
+			Phase:        domain.OfferPhaseOrder,
+			Status:       domain.OfferStatusActive,
+			ManagerID:    &managerID,
+			ManagerName:  "Test Manager",
+			StartDate:    &start,
+			EndDate:      &end,
+		}
+		require.NoError(t, db.Create(offer).Error)
+		return offer
+	}
+
+	running := createOffer("Test Running Order")
+	next := createOffer("Test Next Order")
+
+	t.Run("no warning while the manager has free capacity", func(t *testing.T) {
+		warning, err := svc.CheckManagerCapacity(ctx, next)
+		require.NoError(t, err)
+		assert.Nil(t, warning)
+	})
+
+	_, err := svc.CreateAllocation(ctx, &domain.CreateResourceAllocationRequest{
+		UserID:       managerID,
+		UserName:     "Test Manager",
+		OfferID:      &running.ID,
+		StartDate:    "2026-11-02",
+		EndDate:      "2026-11-27",
+		HoursPerWeek: capacityHours(30),
+	})
+	require.NoError(t, err)
+	_, err = svc.CreateAbsence(ctx, managerID, &domain.CreateUserAbsenceRequest{
+		Kind: domain.UserAbsenceKindVacation, StartDate: "2026-11-16", EndDate: "2026-11-20",
+	})
+	require.NoError(t, err)
+
+	t.Run("warns when the manager is already overbooked in the order's window", func(t *testing.T) {
+		warning, err := svc.CheckManagerCapacity(ctx, next)
+		require.NoError(t, err)
+		require.NotNil(t, warning)
+		assert.Equal(t, service.WarningManagerOverCapacity, warning.Code)
+		require.Len(t, warning.Weeks, 1)
+		assert.Equal(t, "2026-11-16", warning.Weeks[0].WeekStart)
+	})
+
+	t.Run("reports the manager per company", func(t *testing.T) {
+		from, to := capacityDate("2026-11-02"), capacityDate("2026-11-29")
+		company := domain.CompanyStalbygg
+		report, err := svc.GetOverallocationReport(ctx, &from, &to, &company)
+		require.NoError(t, err)
+		require.Len(t, report.Companies, 1)
+		require.NotEmpty(t, report.Companies[0].Users)
+		assert.Equal(t, managerID, report.Companies[0].Users[0].UserID)
+		assert.Equal(t, 30.0, report.Companies[0].Users[0].ExcessHours)
+	})
+
+	t.Run("rejects allocations with both hours and percent", func(t *testing.T) {
+		percent := 50.0
+		_, err := svc.CreateAllocation(ctx, &domain.CreateResourceAllocationRequest{
+			UserID:       managerID,
+			OfferID:      &next.ID,
+			StartDate:    "2026-11-02",
+			EndDate:      "2026-11-27",
+			HoursPerWeek: capacityHours(10),
+			Percent:      &percent,
+		})
+		assert.ErrorIs(t, err, service.ErrInvalidResourceAllocation)
+	})
+}
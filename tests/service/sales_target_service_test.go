@@ -0,0 +1,133 @@
+package service_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/straye-as/relation-api/internal/domain"
+	"github.com/straye-as/relation-api/internal/repository"
+	"github.com/straye-as/relation-api/internal/service"
+	"github.com/straye-as/relation-api/tests/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+func createSalesTargetService(db *gorm.DB) *service.SalesTargetService {
+	return service.NewSalesTargetService(
+		repository.NewSalesTargetRepository(db),
+		repository.NewDealRepository(db),
+		zap.NewNop(),
+	)
+}
+
+func TestSalesTargetService_Create(t *testing.T) {
+	db := setupCustomerServiceTestDB(t)
+	defer testutil.CleanupTestData(t, db)
+	svc := createSalesTargetService(db)
+	ctx := createCustomerTestContext()
+
+	t.Run("moves period start to the start of the quarter", func(t *testing.T) {
+		target, err := svc.Create(ctx, &domain.CreateSalesTargetRequest{
+			CompanyID:   domain.CompanyTak,
+			PeriodType:  domain.SalesTargetPeriodQuarter,
+			PeriodStart: "2026-05-17",
+			Metric:      domain.SalesTargetMetricOrderIntake,
+			TargetValue: 1_000_000,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "2026-04-01", target.PeriodStart)
+		assert.Equal(t, "2026-06-30", target.PeriodEnd)
+		assert.Nil(t, target.UserID)
+	})
+
+	t.Run("rejects a second target for the same period and metric", func(t *testing.T) {
+		_, err := svc.Create(ctx, &domain.CreateSalesTargetRequest{
+			CompanyID:   domain.CompanyTak,
+			PeriodType:  domain.SalesTargetPeriodQuarter,
+			PeriodStart: "2026-04-01",
+			Metric:      domain.SalesTargetMetricOrderIntake,
+			TargetValue: 2_000_000,
+		})
+		assert.ErrorIs(t, err, service.ErrSalesTargetExists)
+	})
+
+	t.Run("rejects unknown companies", func(t *testing.T) {
+		_, err := svc.Create(ctx, &domain.CreateSalesTargetRequest{
+			CompanyID:   domain.CompanyAll,
+			PeriodType:  domain.SalesTargetPeriodYear,
+			PeriodStart: "2026-01-01",
+			Metric:      domain.SalesTargetMetricWonValue,
+			TargetValue: 1_000_000,
+		})
+		assert.ErrorIs(t, err, service.ErrInvalidSalesTarget)
+	})
+}
+
+func TestSalesTargetService_GetAttainment(t *testing.T) {
+	db := setupCustomerServiceTestDB(t)
+	defer testutil.CleanupTestData(t, db)
+	svc := createSalesTargetService(db)
+	customerSvc := createCustomerService(db)
+	ctx := createCustomerTestContext()
+
+	now := time.Now()
+	_, err := svc.Create(ctx, &domain.CreateSalesTargetRequest{
+		CompanyID:   domain.CompanyTak,
+		PeriodType:  domain.SalesTargetPeriodYear,
+		PeriodStart: now.Format("2006-01-02"),
+		Metric:      domain.SalesTargetMetricOrderIntake,
+		TargetValue: 1_000_000,
+	})
+	require.NoError(t, err)
+
+	customer, err := customerSvc.Create(ctx, &domain.CreateCustomerRequest{
+		Name:      "Budsjett Kunde AS",
+		OrgNumber: testutil.ValidOrgNumber(43300100),
+		Country:   "Norway",
+	})
+	require.NoError(t, err)
+
+	// Accepted as an order now, so it counts towards this year's order intake
+	createWonOffer(t, db, customer, 250_000, now)
+
+	// An open deal expected to close before the year ends, weighted at 50%
+	yearEnd := domain.SalesTargetPeriodYear.EndOf(domain.SalesTargetPeriodYear.StartOf(now))
+	closeDate := now.Add(yearEnd.Sub(now) / 2)
+	deal := &domain.Deal{
+		Title:             "Takprosjekt",
+		CustomerID:        customer.ID,
+		CustomerName:      customer.Name,
+		CompanyID:         domain.CompanyTak,
+		Stage:             domain.DealStageProposal,
+		Probability:       50,
+		Value:             400_000,
+		Currency:          "NOK",
+		ExpectedCloseDate: &closeDate,
+		OwnerID:           "owner-1",
+	}
+	require.NoError(t, db.Create(deal).Error)
+
+	companyID := domain.CompanyTak
+	results, err := svc.GetAttainment(ctx, &repository.SalesTargetFilters{CompanyID: &companyID}, nil)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+
+	attainment := results[0]
+	assert.Equal(t, 250_000.0, attainment.Actual)
+	assert.Equal(t, 25.0, attainment.AttainmentPercent)
+	assert.Equal(t, 750_000.0, attainment.Gap)
+	assert.Equal(t, 200_000.0, attainment.WeightedPipeline)
+	require.NotNil(t, attainment.ForecastCoverage)
+	assert.InDelta(t, 26.7, *attainment.ForecastCoverage, 0.05)
+	assert.Greater(t, attainment.RemainingDays, 0)
+	assert.GreaterOrEqual(t, attainment.ProjectedValue, attainment.Actual)
+
+	t.Run("periods not containing the date are not reported", func(t *testing.T) {
+		lastYear := now.AddDate(-1, 0, 0)
+		results, err := svc.GetAttainment(ctx, &repository.SalesTargetFilters{CompanyID: &companyID}, &lastYear)
+		require.NoError(t, err)
+		assert.Empty(t, results)
+	})
+}
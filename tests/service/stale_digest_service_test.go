@@ -0,0 +1,112 @@
+package service_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/straye-as/relation-api/internal/auth"
+	"github.com/straye-as/relation-api/internal/domain"
+	"github.com/straye-as/relation-api/internal/repository"
+	"github.com/straye-as/relation-api/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// backdateDeal moves a deal's creation, stage history and activities the given number of days into the past
+func backdateDeal(t *testing.T, db *gorm.DB, dealID uuid.UUID, days int) {
+	require.NoError(t, db.Exec("UPDATE deals SET created_at = created_at - make_interval(days => ?) WHERE id = ?", days, dealID).Error)
+	require.NoError(t, db.Exec("UPDATE deal_stage_history SET changed_at = changed_at - make_interval(days => ?) WHERE deal_id = ?", days, dealID).Error)
+	require.NoError(t, db.Exec("UPDATE activities SET occurred_at = occurred_at - make_interval(days => ?) WHERE target_type = 'Deal' AND target_id = ?", days, dealID).Error)
+}
+
+func TestDealService_Staleness(t *testing.T) {
+	db := setupDealServiceTestDB(t)
+	svc := createDealService(t, db)
+	customer := createDealServiceTestCustomer(t, db)
+	ctx := createTestContext()
+	userCtx, _ := auth.FromContext(ctx)
+
+	stale, err := svc.Create(ctx, &domain.CreateDealRequest{
+		Title:      "Rotting deal",
+		CustomerID: customer.ID,
+		CompanyID:  domain.CompanyStalbygg,
+		OwnerID:    userCtx.UserID.String(),
+	})
+	require.NoError(t, err)
+	fresh, err := svc.Create(ctx, &domain.CreateDealRequest{
+		Title:      "Fresh deal",
+		CustomerID: customer.ID,
+		CompanyID:  domain.CompanyStalbygg,
+		OwnerID:    userCtx.UserID.String(),
+	})
+	require.NoError(t, err)
+
+	// Leads go stale after 14 days in the default pipeline
+	backdateDeal(t, db, stale.ID, 60)
+
+	t.Run("computes days in stage and staleness", func(t *testing.T) {
+		dto, err := svc.GetByID(ctx, stale.ID)
+		require.NoError(t, err)
+		assert.True(t, dto.IsStale)
+		assert.GreaterOrEqual(t, dto.DaysInStage, 59)
+
+		dto, err = svc.GetByID(ctx, fresh.ID)
+		require.NoError(t, err)
+		assert.False(t, dto.IsStale)
+		assert.Equal(t, 0, dto.DaysInStage)
+	})
+
+	t.Run("filters list on staleness", func(t *testing.T) {
+		isStale := true
+		result, err := svc.List(ctx, 1, 10, &repository.DealFilters{Stale: &isStale}, repository.DealSortByCreatedDesc)
+		require.NoError(t, err)
+		require.Equal(t, int64(1), result.Total)
+		assert.Equal(t, stale.ID, result.Data.([]domain.DealDTO)[0].ID)
+
+		notStale := false
+		result, err = svc.List(ctx, 1, 10, &repository.DealFilters{Stale: &notStale}, repository.DealSortByCreatedDesc)
+		require.NoError(t, err)
+		require.Equal(t, int64(1), result.Total)
+		assert.Equal(t, fresh.ID, result.Data.([]domain.DealDTO)[0].ID)
+	})
+
+	t.Run("sends digest to the owner", func(t *testing.T) {
+		digestSvc := service.NewStaleDigestService(
+			repository.NewDealRepository(db),
+			repository.NewOfferRepository(db),
+			repository.NewUserRoleRepository(db, zap.NewNop()),
+			repository.NewNotificationRepository(db),
+			repository.OfferStaleThresholds{domain.OfferPhaseSent: 30},
+			zap.NewNop(),
+		)
+		staleDeals, _, sent, err := digestSvc.SendDigest(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 1, staleDeals)
+		assert.Equal(t, 1, sent)
+
+		var notification domain.Notification
+		require.NoError(t, db.Where("user_id = ? AND type = ?", userCtx.UserID, domain.NotificationTypeStaleDigest).First(&notification).Error)
+		assert.Contains(t, notification.Message, "Rotting deal")
+		assert.NotContains(t, notification.Message, "Fresh deal")
+	})
+
+	t.Run("activity makes the deal fresh again", func(t *testing.T) {
+		activityRepo := repository.NewActivityRepository(db)
+		require.NoError(t, activityRepo.Create(ctx, &domain.Activity{
+			TargetType: domain.ActivityTargetDeal,
+			TargetID:   stale.ID,
+			Title:      "Called customer",
+			OccurredAt: time.Now().Add(-time.Hour),
+		}))
+
+		dto, err := svc.GetByID(ctx, stale.ID)
+		require.NoError(t, err)
+		assert.False(t, dto.IsStale)
+		require.NotNil(t, dto.DaysSinceLastActivity)
+		assert.Equal(t, 0, *dto.DaysSinceLastActivity)
+		assert.GreaterOrEqual(t, dto.DaysInStage, 59)
+	})
+}
@@ -0,0 +1,140 @@
+package service_test
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/straye-as/relation-api/internal/domain"
+	"github.com/straye-as/relation-api/internal/repository"
+	"github.com/straye-as/relation-api/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func closedDeal(ownerID string, value float64, entered int, won bool, stages ...string) repository.ClosedOutcome {
+	return repository.ClosedOutcome{
+		Kind:               repository.WinRateKindDeal,
+		ID:                 uuid.New(),
+		CompanyID:          domain.CompanyStalbygg,
+		OwnerID:            ownerID,
+		OwnerName:          "Selger " + ownerID,
+		Value:              value,
+		CustomerTier:       domain.CustomerTierBronze,
+		Won:                won,
+		EnteredProbability: &entered,
+		Stages:             stages,
+	}
+}
+
+func TestWinRateModel_Estimate(t *testing.T) {
+	var outcomes []repository.ClosedOutcome
+	// 40 deals reached proposal and 10 of them were won; all of them passed through lead
+	for i := 0; i < 40; i++ {
+		outcomes = append(outcomes, closedDeal("owner-1", 200_000, 50, i < 10, "lead", "proposal"))
+	}
+	// 60 deals were lost straight from lead
+	for i := 0; i < 60; i++ {
+		outcomes = append(outcomes, closedDeal("owner-1", 200_000, 10, false, "lead"))
+	}
+	model := service.NewWinRateModel(outcomes)
+
+	estimate := func(stage string) (float64, int) {
+		return model.Estimate(&repository.OpenForecastItem{
+			Kind:         repository.WinRateKindDeal,
+			Stage:        stage,
+			CompanyID:    domain.CompanyStalbygg,
+			OwnerID:      "owner-1",
+			Value:        200_000,
+			Probability:  50,
+			CustomerTier: domain.CustomerTierBronze,
+		})
+	}
+
+	t.Run("uses the win rate of deals that reached the stage", func(t *testing.T) {
+		probability, sampleSize := estimate("proposal")
+		assert.Equal(t, 40, sampleSize)
+		assert.InDelta(t, 0.25, probability, 0.03)
+	})
+
+	t.Run("earlier stages have lower win rates", func(t *testing.T) {
+		lead, sampleSize := estimate("lead")
+		proposal, _ := estimate("proposal")
+		assert.Equal(t, 100, sampleSize)
+		assert.Less(t, lead, proposal)
+	})
+
+	t.Run("falls back to the overall win rate for unknown stages", func(t *testing.T) {
+		probability, sampleSize := estimate("site_visit")
+		assert.Equal(t, 0, sampleSize)
+		assert.InDelta(t, 0.1, probability, 0.04)
+	})
+
+	t.Run("reports raw stage win rates", func(t *testing.T) {
+		rates := model.StageWinRates()
+		require.Len(t, rates, 2)
+		assert.Equal(t, "lead", rates[0].Stage)
+		assert.Equal(t, 100, rates[0].Closed)
+		assert.Equal(t, 10.0, rates[0].WinRate)
+		assert.Equal(t, "proposal", rates[1].Stage)
+		assert.Equal(t, 25.0, rates[1].WinRate)
+	})
+}
+
+func TestWinRateModel_SegmentsAreShrunk(t *testing.T) {
+	var outcomes []repository.ClosedOutcome
+	for i := 0; i < 50; i++ {
+		outcomes = append(outcomes, closedDeal("owner-1", 200_000, 50, i%2 == 0, "proposal"))
+	}
+	// A single won deal for another owner should not make their deals near certain
+	outcomes = append(outcomes, closedDeal("owner-2", 200_000, 50, true, "proposal"))
+	model := service.NewWinRateModel(outcomes)
+
+	probability, _ := model.Estimate(&repository.OpenForecastItem{
+		Kind:         repository.WinRateKindDeal,
+		Stage:        "proposal",
+		CompanyID:    domain.CompanyStalbygg,
+		OwnerID:      "owner-2",
+		Value:        200_000,
+		CustomerTier: domain.CustomerTierBronze,
+	})
+	assert.Greater(t, probability, 0.5)
+	assert.Less(t, probability, 0.65)
+}
+
+func TestBuildSalespersonCalibration(t *testing.T) {
+	var outcomes []repository.ClosedOutcome
+	// owner-1 enters 80% but wins only 1 in 4
+	for i := 0; i < 4; i++ {
+		outcomes = append(outcomes, closedDeal("owner-1", 100_000, 80, i == 0, "negotiation"))
+	}
+	// owner-2 enters 50% and wins half
+	for i := 0; i < 2; i++ {
+		outcomes = append(outcomes, closedDeal("owner-2", 100_000, 50, i == 0, "proposal"))
+	}
+	// Outcomes without an entered probability are skipped
+	unknown := closedDeal("owner-2", 100_000, 0, true, "proposal")
+	unknown.EnteredProbability = nil
+	outcomes = append(outcomes, unknown)
+
+	calibration, overall := service.BuildSalespersonCalibration(outcomes)
+	require.Len(t, calibration, 2)
+
+	optimist := calibration[0]
+	assert.Equal(t, "owner-1", optimist.OwnerID)
+	assert.Equal(t, 4, optimist.Closed)
+	assert.Equal(t, 1, optimist.Won)
+	assert.Equal(t, 80.0, optimist.AvgEnteredProbability)
+	assert.Equal(t, 25.0, optimist.ActualWinRate)
+	assert.Equal(t, 55.0, optimist.Bias)
+	require.Len(t, optimist.Buckets, 5)
+	assert.Equal(t, "80-100", optimist.Buckets[4].Range)
+	assert.Equal(t, 4, optimist.Buckets[4].Closed)
+
+	realist := calibration[1]
+	assert.Equal(t, "owner-2", realist.OwnerID)
+	assert.Equal(t, 0.0, realist.Bias)
+	assert.Equal(t, 0.25, realist.BrierScore)
+
+	assert.Equal(t, 6, overall.Closed)
+	assert.Equal(t, 2, overall.Won)
+}
@@ -3,6 +3,9 @@ package testutil
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
 	"testing"
 	"time"
 
@@ -46,56 +49,53 @@ func SetupCleanTestDB(t *testing.T) *gorm.DB {
 	return db
 }
 
+// testDataTables lists the tables cleaned between tests, in order to respect foreign key constraints
+var testDataTables = []string{
+	"resource_allocations",
+	"user_absences",
+	"user_capacities",
+	"project_task_dependencies",
+	"project_tasks",
+	"inquiry_assignment_rules",
+	"inquiry_submissions",
+	"inquiry_intake_sites",
+	"sales_targets",
+	"ingested_emails",
+	"calendar_feed_tokens",
+	"erp_reconciliation_decisions",
+	"erp_reconciliation_runs",
+	"import_jobs",
+	"import_mapping_profiles",
+	"customer_tier_history",
+	"deal_stage_history",
+	"deals",
+	"notifications",
+	"activities",
+	"offer_suppliers",
+	"offer_items",
+	"files",
+	"offers",
+	"projects",
+	"contact_relationships",
+	"supplier_contacts",
+	"suppliers",
+	"contacts",
+	"customers",
+	"number_sequences",
+}
+
 // cleanupAllTestData cleans up test data from all tables (internal helper)
 func cleanupAllTestData(db *gorm.DB) {
-	// Delete in order to respect foreign key constraints
-	tables := []string{
-		"deal_stage_history",
-		"deals",
-		"notifications",
-		"activities",
-		"offer_suppliers",
-		"offer_items",
-		"files",
-		"offers",
-		"projects",
-		"contact_relationships",
-		"supplier_contacts",
-		"suppliers",
-		"contacts",
-		"customers",
-		"number_sequences",
-	}
-
-	for _, table := range tables {
-		db.Exec(fmt.Sprintf("DELETE FROM %s WHERE id IS NOT NULL", table))
+	for _, table := range testDataTables {
+		db.Exec(fmt.Sprintf("DELETE FROM %s", table))
 	}
 }
 
 // CleanupTestData cleans up test data from all tables
 // This should be called after tests to ensure a clean state
 func CleanupTestData(t *testing.T, db *gorm.DB) {
-	// Delete in order to respect foreign key constraints
-	tables := []string{
-		"deal_stage_history",
-		"deals",
-		"notifications",
-		"activities",
-		"offer_suppliers",
-		"offer_items",
-		"files",
-		"offers",
-		"projects",
-		"contact_relationships",
-		"supplier_contacts",
-		"suppliers",
-		"contacts",
-		"customers",
-		"number_sequences",
-	}
-
-	for _, table := range tables {
-		err := db.Exec(fmt.Sprintf("DELETE FROM %s WHERE id IS NOT NULL", table)).Error
+	for _, table := range testDataTables {
+		err := db.Exec(fmt.Sprintf("DELETE FROM %s", table)).Error
 		if err != nil {
 			// Table might not exist, that's ok
 			t.Logf("Note: Could not clean table %s: %v", table, err)
@@ -121,6 +121,26 @@ func CreateTestCustomer(t *testing.T, db *gorm.DB, name string) *domain.Customer
 	return customer
 }
 
+// ValidOrgNumber returns a valid Norwegian organization number (MOD11) derived from seed.
+// A seed whose check digit would be 10 moves on to the next base, so callers generating
+// several unique numbers should space their seeds apart (e.g. by 10).
+func ValidOrgNumber(seed int64) string {
+	base := seed % 100000000
+	if base < 0 {
+		base = -base
+	}
+	for {
+		for check := 0; check <= 9; check++ {
+			orgNum := fmt.Sprintf("%08d%d", base, check)
+			if domain.IsValidOrgNumber(orgNum) {
+				return orgNum
+			}
+		}
+		// Bases whose check digit would be 10 are never issued; try the next one
+		base = (base + 1) % 100000000
+	}
+}
+
 // randomInt returns a unique integer for test data
 func randomInt() int64 {
 	return time.Now().UnixNano()
@@ -183,3 +203,22 @@ func CreateTestSupplierWithCategory(t *testing.T, db *gorm.DB, name string, cate
 	require.NoError(t, err)
 	return supplier
 }
+
+// ApplyMigrationUp runs the Up section of a migration in migrations/ against the test database,
+// for tests of data migrations whose effect is not covered by the schema alone
+func ApplyMigrationUp(t *testing.T, db *gorm.DB, name string) {
+	_, file, _, _ := runtime.Caller(0)
+	content, err := os.ReadFile(filepath.Join(filepath.Dir(file), "..", "..", "migrations", name))
+	require.NoError(t, err)
+
+	up, _, found := strings.Cut(string(content), "-- +goose Down")
+	require.True(t, found, "migration %s has no Down section", name)
+	var statements strings.Builder
+	for _, line := range strings.Split(up, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "-- +goose") {
+			continue
+		}
+		statements.WriteString(line + "\n")
+	}
+	require.NoError(t, db.Exec(statements.String()).Error)
+}
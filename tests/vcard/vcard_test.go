@@ -0,0 +1,113 @@
+package vcard_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/straye-as/relation-api/internal/vcard"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteAndParse(t *testing.T) {
+	card := vcard.Card{
+		UID:          "urn:uuid:5f0e2c1a-7b3d-4c8e-9a1f-2d6b8e4c0a11",
+		FullName:     "Kari Nordmann",
+		GivenName:    "Kari",
+		FamilyName:   "Nordmann",
+		Organization: "Bygg; og Anlegg AS",
+		Department:   "Innkjøp",
+		Title:        "Prosjektleder, tak",
+		Email:        "kari.nordmann@example.com",
+		Phone:        "+47 22 33 44 55",
+		Mobile:       "+47 412 34 567",
+		Address:      vcard.Address{Street: "Storgata 1", City: "Oslo", PostalCode: "0155", Country: "Norway"},
+		URL:          "https://www.linkedin.com/in/kari",
+		Note:         "Foretrekker telefon.\nTreffes best før lunsj, og aldri på fredager etter klokken tolv. Dette er en lang linje.",
+		Revision:     time.Date(2024, 3, 15, 12, 30, 0, 0, time.UTC),
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, vcard.Write(&buf, card))
+	output := buf.String()
+
+	assert.True(t, strings.HasPrefix(output, "BEGIN:VCARD\r\nVERSION:4.0\r\n"))
+	assert.Contains(t, output, "FN:Kari Nordmann\r\n")
+	assert.Contains(t, output, "N:Nordmann;Kari;;;\r\n")
+	assert.Contains(t, output, `ORG:Bygg\; og Anlegg AS;Innkjøp`)
+	assert.Contains(t, output, "REV:20240315T123000Z\r\n")
+	for _, line := range strings.Split(output, "\r\n") {
+		assert.LessOrEqual(t, len(line), 75, "line %q", line)
+	}
+
+	cards, err := vcard.Parse(&buf)
+	require.NoError(t, err)
+	require.Len(t, cards, 1)
+	assert.Equal(t, card, cards[0])
+}
+
+func TestParse_VCard3(t *testing.T) {
+	input := "BEGIN:VCARD\r\n" +
+		"VERSION:3.0\r\n" +
+		"FN:Ola Nordmann\r\n" +
+		"N:Nordmann;Ola;Johan;;\r\n" +
+		"EMAIL;TYPE=INTERNET,HOME:ola@privat.no\r\n" +
+		"EMAIL;TYPE=INTERNET,WORK,pref:ola@firma.no\r\n" +
+		"TEL;TYPE=HOME,VOICE:22 11 11 11\r\n" +
+		"TEL;TYPE=\"WORK,VOICE\":22 22 22 22\r\n" +
+		"TEL;TYPE=CELL:900 00 000\r\n" +
+		"item1.ADR;TYPE=WORK:;;Kongens gate 2;Trond\r\n" +
+		" heim;;7011;Norge\r\n" +
+		"END:VCARD\r\n" +
+		"BEGIN:VCARD\r\n" +
+		"VERSION:3.0\r\n" +
+		"FN:Kun Fornavn\r\n" +
+		"END:VCARD\r\n"
+
+	cards, err := vcard.Parse(strings.NewReader(input))
+	require.NoError(t, err)
+	require.Len(t, cards, 2)
+
+	ola := cards[0]
+	given, family := ola.Name()
+	assert.Equal(t, "Ola Johan", given)
+	assert.Equal(t, "Nordmann", family)
+	assert.Equal(t, "ola@firma.no", ola.Email)
+	assert.Equal(t, "22 22 22 22", ola.Phone)
+	assert.Equal(t, "900 00 000", ola.Mobile)
+	assert.Equal(t, vcard.Address{Street: "Kongens gate 2", City: "Trondheim", PostalCode: "7011", Country: "Norge"}, ola.Address)
+
+	given, family = cards[1].Name()
+	assert.Equal(t, "Kun", given)
+	assert.Equal(t, "Fornavn", family)
+}
+
+func TestParse_VCard21QuotedPrintable(t *testing.T) {
+	input := "BEGIN:VCARD\n" +
+		"VERSION:2.1\n" +
+		"N;CHARSET=UTF-8;ENCODING=QUOTED-PRINTABLE:=C3=98deg=C3=A5rd;P=C3=A5l;;;\n" +
+		"FN;CHARSET=UTF-8;ENCODING=QUOTED-PRINTABLE:P=C3=A5l =C3=98deg=\n" +
+		"=C3=A5rd\n" +
+		"TEL;CELL:+4791234567\n" +
+		"TEL;WORK;VOICE:+4722334455\n" +
+		"END:VCARD\n"
+
+	cards, err := vcard.Parse(strings.NewReader(input))
+	require.NoError(t, err)
+	require.Len(t, cards, 1)
+	assert.Equal(t, "Pål Ødegård", cards[0].FullName)
+	assert.Equal(t, "Pål", cards[0].GivenName)
+	assert.Equal(t, "Ødegård", cards[0].FamilyName)
+	assert.Equal(t, "+4791234567", cards[0].Mobile)
+	assert.Equal(t, "+4722334455", cards[0].Phone)
+}
+
+func TestParse_Errors(t *testing.T) {
+	_, err := vcard.Parse(strings.NewReader("not a vcard"))
+	assert.ErrorIs(t, err, vcard.ErrNoCards)
+
+	_, err = vcard.Parse(strings.NewReader("BEGIN:VCARD\nVERSION:4.0\nFN:Halv\n"))
+	assert.ErrorIs(t, err, vcard.ErrUnterminatedCard)
+}
@@ -0,0 +1,106 @@
+package workcalendar_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/straye-as/relation-api/internal/workcalendar"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func oslo(year int, month time.Month, day, hour, minute int) time.Time {
+	return time.Date(year, month, day, hour, minute, 0, 0, workcalendar.Oslo)
+}
+
+func TestEasterSunday(t *testing.T) {
+	tests := map[int]string{
+		2024: "2024-03-31",
+		2025: "2025-04-20",
+		2026: "2026-04-05",
+		2027: "2027-03-28",
+		2038: "2038-04-25",
+	}
+	for year, want := range tests {
+		assert.Equal(t, want, workcalendar.EasterSunday(year).Format("2006-01-02"))
+	}
+}
+
+func TestHolidayName(t *testing.T) {
+	tests := []struct {
+		date time.Time
+		want string
+	}{
+		{oslo(2026, time.January, 1, 12, 0), "Første nyttårsdag"},
+		{oslo(2026, time.April, 2, 12, 0), "Skjærtorsdag"},
+		{oslo(2026, time.April, 3, 12, 0), "Langfredag"},
+		{oslo(2026, time.April, 6, 12, 0), "Andre påskedag"},
+		{oslo(2026, time.May, 1, 12, 0), "Arbeidernes dag"},
+		{oslo(2026, time.May, 14, 12, 0), "Kristi himmelfartsdag"},
+		{oslo(2026, time.May, 17, 12, 0), "Grunnlovsdag"},
+		{oslo(2026, time.May, 25, 12, 0), "Andre pinsedag"},
+		{oslo(2026, time.December, 26, 12, 0), "Andre juledag"},
+		{oslo(2026, time.April, 7, 12, 0), ""},
+		{oslo(2026, time.December, 24, 12, 0), ""},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, workcalendar.HolidayName(tt.date), tt.date.Format("2006-01-02"))
+	}
+
+	// Late evening UTC on the day before is already the holiday in Oslo
+	assert.True(t, workcalendar.IsPublicHoliday(time.Date(2026, time.May, 16, 23, 30, 0, 0, time.UTC)))
+}
+
+func TestNew(t *testing.T) {
+	calendar, err := workcalendar.New("08:00", "16:00")
+	require.NoError(t, err)
+	assert.Equal(t, 8*time.Hour, calendar.HoursPerDay())
+
+	_, err = workcalendar.New("16:00", "08:00")
+	assert.Error(t, err)
+	_, err = workcalendar.New("8", "16:00")
+	assert.Error(t, err)
+}
+
+func TestCalendar_Add(t *testing.T) {
+	calendar, err := workcalendar.New("08:00", "16:00")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		from time.Time
+		add  time.Duration
+		want time.Time
+	}{
+		{"within the same day", oslo(2026, time.March, 10, 9, 0), 4 * time.Hour, oslo(2026, time.March, 10, 13, 0)},
+		{"ends exactly at closing", oslo(2026, time.March, 10, 8, 0), 8 * time.Hour, oslo(2026, time.March, 10, 16, 0)},
+		{"two working days", oslo(2026, time.March, 10, 10, 30), 16 * time.Hour, oslo(2026, time.March, 12, 10, 30)},
+		{"before opening starts at opening", oslo(2026, time.March, 10, 6, 0), 2 * time.Hour, oslo(2026, time.March, 10, 10, 0)},
+		{"friday afternoon continues monday", oslo(2026, time.March, 13, 15, 0), 2 * time.Hour, oslo(2026, time.March, 16, 9, 0)},
+		{"saturday starts monday", oslo(2026, time.March, 14, 11, 0), 8 * time.Hour, oslo(2026, time.March, 16, 16, 0)},
+		{"skips easter", oslo(2026, time.April, 1, 14, 0), 16 * time.Hour, oslo(2026, time.April, 8, 14, 0)},
+		{"across daylight saving change", oslo(2026, time.March, 27, 12, 0), 8 * time.Hour, oslo(2026, time.March, 30, 12, 0)},
+		{"zero duration", oslo(2026, time.March, 14, 11, 0), 0, oslo(2026, time.March, 14, 11, 0)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := calendar.Add(tt.from, tt.add)
+			assert.True(t, tt.want.Equal(got), "want %s, got %s", tt.want, got.In(workcalendar.Oslo))
+		})
+	}
+}
+
+func TestCalendar_Between(t *testing.T) {
+	calendar, err := workcalendar.New("08:00", "16:00")
+	require.NoError(t, err)
+
+	assert.Equal(t, 3*time.Hour, calendar.Between(oslo(2026, time.March, 10, 9, 0), oslo(2026, time.March, 10, 12, 0)))
+	assert.Equal(t, 2*time.Hour, calendar.Between(oslo(2026, time.March, 13, 15, 0), oslo(2026, time.March, 16, 9, 0)))
+	assert.Equal(t, time.Duration(0), calendar.Between(oslo(2026, time.March, 14, 9, 0), oslo(2026, time.March, 15, 18, 0)))
+	assert.Equal(t, 16*time.Hour, calendar.Between(oslo(2026, time.April, 1, 14, 0), oslo(2026, time.April, 8, 14, 0)))
+	assert.Equal(t, -2*time.Hour, calendar.Between(oslo(2026, time.March, 16, 9, 0), oslo(2026, time.March, 13, 15, 0)))
+
+	// Between is the inverse of Add
+	from := oslo(2026, time.May, 12, 13, 15)
+	assert.Equal(t, 20*time.Hour, calendar.Between(from, calendar.Add(from, 20*time.Hour)))
+}